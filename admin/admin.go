@@ -0,0 +1,209 @@
+// Package admin serves read-only inspection endpoints for a running query
+// service, so operators can see what it has loaded without redeploying:
+//
+//	mux.Handle("/admin/", http.StripPrefix("/admin", admin.Handler(admin.Config{
+//	    Schema:    v,
+//	    Catalog:   queries,
+//	    Renderers: []vectql.Renderer{qdrant.New(), pinecone.New()},
+//	    Latency:   tracker,
+//	})))
+//
+// The handler answers GET requests on /schema, /catalog, /capabilities, and
+// /slow-queries with JSON. Endpoints whose source is not configured answer
+// 404. The handler performs no authentication; mount it behind whatever
+// guards the service's other administrative routes.
+package admin
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/catalog"
+)
+
+// Config names the state the admin endpoints expose. Nil fields disable
+// their endpoints.
+type Config struct {
+	// Schema is the loaded schema, served on /schema.
+	Schema *vectql.VECTQL
+
+	// Catalog holds the saved queries served on /catalog. It must not be
+	// modified while the handler serves requests.
+	Catalog *catalog.Catalog
+
+	// Renderers are the providers whose capabilities are served on
+	// /capabilities.
+	Renderers []vectql.Renderer
+
+	// Latency supplies the recent slow queries served on /slow-queries;
+	// configure it with vectql.WithSlowQueryHistory.
+	Latency *vectql.LatencyTracker
+
+	// ErrorLog logs responses that could not be written to the client. Nil
+	// uses the log package's standard logger, as http.Server does.
+	ErrorLog *log.Logger
+}
+
+// Collection describes a schema collection.
+type Collection struct {
+	Name       string      `json:"name"`
+	Embeddings []Embedding `json:"embeddings"`
+	Fields     []Field     `json:"fields"`
+}
+
+// Embedding describes an embedding field.
+type Embedding struct {
+	Name         string                `json:"name"`
+	Dimensions   int                   `json:"dimensions,omitempty"`
+	Metric       vectql.DistanceMetric `json:"metric,omitempty"`
+	Model        string                `json:"model,omitempty"`
+	ModelVersion string                `json:"model_version,omitempty"`
+}
+
+// Field describes a metadata field.
+type Field struct {
+	Name            string `json:"name"`
+	Type            string `json:"type,omitempty"`
+	Index           string `json:"index,omitempty"`
+	CaseInsensitive bool   `json:"case_insensitive,omitempty"`
+	Language        string `json:"language,omitempty"`
+}
+
+// Capabilities describes what a provider's renderer supports.
+type Capabilities struct {
+	Provider         string                  `json:"provider"`
+	Operations       []vectql.Operation      `json:"operations"`
+	Filters          []vectql.FilterOperator `json:"filters"`
+	Metrics          []vectql.DistanceMetric `json:"metrics"`
+	Modalities       []vectql.Modality       `json:"modalities"`
+	VectorEncodings  []vectql.VectorEncoding `json:"vector_encodings"`
+	ContentEncodings []string                `json:"content_encodings"`
+	Snapshots        bool                    `json:"snapshots"`
+}
+
+// Handler returns the admin endpoints for cfg.
+func Handler(cfg Config) http.Handler {
+	logf := log.Printf
+	if cfg.ErrorLog != nil {
+		logf = cfg.ErrorLog.Printf
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/schema", get(logf, cfg.Schema != nil, func() (interface{}, error) {
+		return Schema(cfg.Schema)
+	}))
+	mux.HandleFunc("/catalog", get(logf, cfg.Catalog != nil, func() (interface{}, error) {
+		queries := cfg.Catalog.List()
+		if queries == nil {
+			queries = []catalog.Query{}
+		}
+		return queries, nil
+	}))
+	mux.HandleFunc("/capabilities", get(logf, len(cfg.Renderers) > 0, func() (interface{}, error) {
+		return CapabilityMatrix(cfg.Renderers), nil
+	}))
+	mux.HandleFunc("/slow-queries", get(logf, cfg.Latency != nil, func() (interface{}, error) {
+		return cfg.Latency.SlowQueries(), nil
+	}))
+	return mux
+}
+
+// Schema describes every collection of v, sorted by name, with fields in
+// declaration order.
+func Schema(v *vectql.VECTQL) ([]Collection, error) {
+	names := v.Collections()
+	out := make([]Collection, 0, len(names))
+	for _, name := range names {
+		coll := Collection{Name: name, Embeddings: []Embedding{}, Fields: []Field{}}
+
+		embeddings, err := v.Embeddings(name, vectql.WithOrder(vectql.OrderDeclared))
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range embeddings {
+			emb, err := v.TryE(name, e)
+			if err != nil {
+				return nil, err
+			}
+			coll.Embeddings = append(coll.Embeddings, Embedding{
+				Name:         emb.Name,
+				Dimensions:   emb.Dimensions,
+				Metric:       emb.Metric,
+				Model:        emb.Model.Name,
+				ModelVersion: emb.Model.Version,
+			})
+		}
+
+		fields, err := v.MetadataFields(name, vectql.WithOrder(vectql.OrderDeclared))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			meta, err := v.TryM(name, f)
+			if err != nil {
+				return nil, err
+			}
+			coll.Fields = append(coll.Fields, Field{
+				Name:            meta.Name,
+				Type:            meta.Type,
+				Index:           string(meta.Index),
+				CaseInsensitive: meta.CaseInsensitive,
+				Language:        meta.Language,
+			})
+		}
+		out = append(out, coll)
+	}
+	return out, nil
+}
+
+// CapabilityMatrix describes what each renderer supports, in the given
+// order.
+func CapabilityMatrix(renderers []vectql.Renderer) []Capabilities {
+	out := make([]Capabilities, 0, len(renderers))
+	for _, r := range renderers {
+		caps := vectql.UpgradeRenderer(r).Capabilities()
+		out = append(out, Capabilities{
+			Provider:         caps.Provider,
+			Operations:       append([]vectql.Operation{}, caps.Operations...),
+			Filters:          append([]vectql.FilterOperator{}, caps.Filters...),
+			Metrics:          append([]vectql.DistanceMetric{}, caps.Metrics...),
+			Modalities:       append([]vectql.Modality{}, caps.Modalities...),
+			VectorEncodings:  append([]vectql.VectorEncoding{}, caps.VectorEncodings...),
+			ContentEncodings: append([]string{}, caps.ContentEncodings...),
+			Snapshots:        caps.Snapshots,
+		})
+	}
+	return out
+}
+
+// get serves the value load returns as JSON to GET and HEAD requests, or
+// 404 when the endpoint is not configured. Values that cannot be encoded
+// answer 500; failures to write the response are passed to logf.
+func get(logf func(format string, args ...interface{}), enabled bool, load func() (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if !enabled {
+			http.NotFound(w, r)
+			return
+		}
+		value, err := load()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		body, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(append(body, '\n')); err != nil {
+			logf("admin: failed to write %s response: %v", r.URL.Path, err)
+		}
+	}
+}
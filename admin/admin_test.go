@@ -0,0 +1,151 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/catalog"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func testConfig(t *testing.T) Config {
+	t.Helper()
+	v, err := vectql.NewFromVDML(vdml.NewSchema("shop").
+		AddCollection(vdml.NewCollection("products").
+			AddEmbedding(vdml.NewEmbedding("embedding", 3)).
+			AddMetadata(vdml.NewMetadataField("category", vdml.TypeString)).
+			AddMetadata(vdml.NewMetadataField("price", vdml.TypeFloat))))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	q, err := catalog.NewQuery("product_search", 1, vectql.Search(v.C("products")).
+		Vector(vectql.Vec(v.P("query_vec"))).
+		TopK(10),
+		catalog.ParamSpec{Name: "query_vec", Type: catalog.TypeVector})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	queries := catalog.New()
+	if err := queries.Add(q); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tracker := vectql.NewLatencyTracker(vectql.WithSlowQueryHistory(0, 10))
+	exec := tracker.Wrap(vectql.ExecutorFunc(func(_ context.Context, _ *vectql.Request) (*vectql.Response, error) {
+		return &vectql.Response{}, nil
+	}))
+	_, _ = exec.Execute(context.Background(), &vectql.Request{Provider: "qdrant", Operation: vectql.OpSearch, Collection: "products"})
+
+	return Config{Schema: v, Catalog: queries, Renderers: []vectql.Renderer{qdrant.New()}, Latency: tracker}
+}
+
+func serve(t *testing.T, h http.Handler, method, path string, out interface{}) int {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	if out != nil && rec.Code == http.StatusOK {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", path, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestHandler(t *testing.T) {
+	h := Handler(testConfig(t))
+
+	var schema []Collection
+	if code := serve(t, h, http.MethodGet, "/schema", &schema); code != http.StatusOK {
+		t.Fatalf("/schema: expected 200, got %d", code)
+	}
+	if len(schema) != 1 || schema[0].Name != "products" || len(schema[0].Fields) != 2 ||
+		schema[0].Fields[0].Name != "category" || schema[0].Fields[0].Type != "string" {
+		t.Errorf("unexpected schema: %+v", schema)
+	}
+	if len(schema[0].Embeddings) != 1 || schema[0].Embeddings[0].Dimensions != 3 {
+		t.Errorf("unexpected embeddings: %+v", schema[0].Embeddings)
+	}
+
+	var queries []catalog.Query
+	if code := serve(t, h, http.MethodGet, "/catalog", &queries); code != http.StatusOK {
+		t.Fatalf("/catalog: expected 200, got %d", code)
+	}
+	if len(queries) != 1 || queries[0].ID() != "product_search@1" {
+		t.Errorf("unexpected catalog: %+v", queries)
+	}
+
+	var caps []Capabilities
+	if code := serve(t, h, http.MethodGet, "/capabilities", &caps); code != http.StatusOK {
+		t.Fatalf("/capabilities: expected 200, got %d", code)
+	}
+	if len(caps) != 1 || caps[0].Provider != "qdrant" || len(caps[0].Operations) == 0 || len(caps[0].ContentEncodings) == 0 {
+		t.Errorf("unexpected capabilities: %+v", caps)
+	}
+
+	var slow []vectql.SlowQuery
+	if code := serve(t, h, http.MethodGet, "/slow-queries", &slow); code != http.StatusOK {
+		t.Fatalf("/slow-queries: expected 200, got %d", code)
+	}
+	if len(slow) != 1 || slow[0].Collection != "products" {
+		t.Errorf("unexpected slow queries: %+v", slow)
+	}
+}
+
+func TestHandler_ReadOnly(t *testing.T) {
+	h := Handler(testConfig(t))
+	if code := serve(t, h, http.MethodPost, "/schema", nil); code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", code)
+	}
+}
+
+func TestHandler_Unconfigured(t *testing.T) {
+	h := Handler(Config{})
+	for _, path := range []string{"/schema", "/catalog", "/capabilities", "/slow-queries"} {
+		if code := serve(t, h, http.MethodGet, path, nil); code != http.StatusNotFound {
+			t.Errorf("%s: expected 404, got %d", path, code)
+		}
+	}
+}
+
+// failingWriter is a ResponseWriter whose client has gone away.
+type failingWriter struct {
+	httptest.ResponseRecorder
+}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func TestHandler_WriteError(t *testing.T) {
+	var logged bytes.Buffer
+	cfg := testConfig(t)
+	cfg.ErrorLog = log.New(&logged, "", 0)
+
+	w := &failingWriter{ResponseRecorder: *httptest.NewRecorder()}
+	Handler(cfg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/schema", nil))
+	if !strings.Contains(logged.String(), "failed to write /schema response: connection reset") {
+		t.Errorf("expected the write error to be logged, got %q", logged.String())
+	}
+}
+
+func TestHandler_EncodeError(t *testing.T) {
+	h := get(t.Logf, true, func() (interface{}, error) {
+		return map[string]interface{}{"latency": math.Inf(1)}, nil
+	})
+	rec := httptest.NewRecorder()
+	h(rec, httptest.NewRequest(http.MethodGet, "/slow-queries", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for a value JSON cannot encode, got %d", rec.Code)
+	}
+}
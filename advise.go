@@ -0,0 +1,135 @@
+package vectql
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Advice codes reported by AdviseSearch. The warnings APIs report them as
+// WarningCodes; see Builder.WithStats.
+const (
+	AdviceEmptyCollection     = "empty_collection"
+	AdviceTopKExceedsCount    = "topk_exceeds_collection"
+	AdviceMinScoreUnreachable = "min_score_unreachable"
+	AdviceMinScoreStrict      = "min_score_strict"
+)
+
+// strictCosineThreshold is the cosine similarity above which a MinScore is
+// likely to filter out every result for typical embedding models.
+const strictCosineThreshold = 0.95
+
+// CollectionStats describes the state of a collection as reported by its provider.
+type CollectionStats struct {
+	// VectorCount is the number of vectors stored in the collection.
+	VectorCount int64
+
+	// Metric is the distance metric the collection's index was created with.
+	Metric types.DistanceMetric
+}
+
+// Advice is a non-fatal observation about a query.
+type Advice struct {
+	// Code identifies the kind of advice for programmatic handling.
+	Code string
+
+	// Message describes the issue in human-readable form.
+	Message string
+}
+
+// AdviseSearch inspects a SEARCH against collection statistics and reports
+// settings that are likely to waste work or return no results. Parameterized
+// TopK and MinScore values are resolved from params when present; unresolved
+// parameters are skipped.
+func AdviseSearch(ast *types.VectorAST, stats CollectionStats, params map[string]interface{}) []Advice {
+	if ast == nil || ast.Operation != types.OpSearch {
+		return nil
+	}
+
+	var advice []Advice
+
+	if stats.VectorCount == 0 {
+		advice = append(advice, Advice{
+			Code:    AdviceEmptyCollection,
+			Message: fmt.Sprintf("collection '%s' is empty; SEARCH will return no results", ast.Target.Name),
+		})
+	} else if k, ok := resolveTopK(ast.TopK, params); ok && int64(k) > stats.VectorCount {
+		advice = append(advice, Advice{
+			Code:    AdviceTopKExceedsCount,
+			Message: fmt.Sprintf("TopK %d exceeds the %d vectors in collection '%s'", k, stats.VectorCount, ast.Target.Name),
+		})
+	}
+
+	if ast.MinScore != nil {
+		if score, ok := toFloat(params[ast.MinScore.Name]); ok {
+			advice = append(advice, adviseMinScore(score, stats.Metric)...)
+		}
+	}
+
+	return advice
+}
+
+func adviseMinScore(score float64, metric types.DistanceMetric) []Advice {
+	switch metric {
+	case types.Cosine:
+		if score > 1 {
+			return []Advice{{
+				Code:    AdviceMinScoreUnreachable,
+				Message: fmt.Sprintf("MinScore %g exceeds the maximum cosine similarity of 1", score),
+			}}
+		}
+		if score >= strictCosineThreshold {
+			return []Advice{{
+				Code:    AdviceMinScoreStrict,
+				Message: fmt.Sprintf("MinScore %g is near the cosine maximum and will likely return no results", score),
+			}}
+		}
+	case types.Euclidean, types.Manhattan:
+		if score < 0 {
+			return []Advice{{
+				Code:    AdviceMinScoreUnreachable,
+				Message: fmt.Sprintf("MinScore %g is negative but %s distances are never negative", score, metric),
+			}}
+		}
+	}
+	return nil
+}
+
+// resolveTopK returns the static TopK or its bound parameter value.
+func resolveTopK(topK *types.PaginationValue, params map[string]interface{}) (int, bool) {
+	if topK == nil {
+		return 0, false
+	}
+	if topK.Static != nil {
+		return *topK.Static, true
+	}
+	if topK.Param != nil {
+		if k, ok := toFloat(params[topK.Param.Name]); ok {
+			return int(k), true
+		}
+	}
+	return 0, false
+}
+
+// WithStats makes BuildWithWarnings and RenderWithWarnings include the
+// advice of AdviseSearch against stats, one Warning per Advice with the
+// advice code as its WarningCode. params resolves parameterized TopK and
+// MinScore values; with nil params only static values are checked.
+func (b *Builder) WithStats(stats CollectionStats, params map[string]interface{}) *Builder {
+	b.stats = &stats
+	b.statsParams = params
+	return b
+}
+
+// adviceWarnings converts the advice for ast into warnings. It returns nil
+// unless WithStats was called.
+func (b *Builder) adviceWarnings(ast *types.VectorAST) []Warning {
+	if b.stats == nil {
+		return nil
+	}
+	var warnings []Warning
+	for _, a := range AdviseSearch(ast, *b.stats, b.statsParams) {
+		warnings = append(warnings, Warning{Code: WarningCode(a.Code), Message: a.Message})
+	}
+	return warnings
+}
@@ -0,0 +1,170 @@
+package vectql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func adviceCodes(advice []Advice) []string {
+	codes := make([]string, len(advice))
+	for i, a := range advice {
+		codes[i] = a.Code
+	}
+	return codes
+}
+
+func TestAdviseSearch(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+	minScore := types.Param{Name: "min_score"}
+
+	tests := []struct {
+		name    string
+		builder *Builder
+		stats   CollectionStats
+		params  map[string]interface{}
+		want    []string
+	}{
+		{
+			name:    "healthy query",
+			builder: Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(10),
+			stats:   CollectionStats{VectorCount: 1000, Metric: types.Cosine},
+			want:    nil,
+		},
+		{
+			name:    "empty collection",
+			builder: Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(10),
+			stats:   CollectionStats{VectorCount: 0},
+			want:    []string{AdviceEmptyCollection},
+		},
+		{
+			name:    "topK exceeds count",
+			builder: Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(100),
+			stats:   CollectionStats{VectorCount: 50},
+			want:    []string{AdviceTopKExceedsCount},
+		},
+		{
+			name:    "parameterized topK exceeds count",
+			builder: Search(coll).Vector(Vec(types.Param{Name: "v"})).TopKParam(types.Param{Name: "k"}),
+			stats:   CollectionStats{VectorCount: 50},
+			params:  map[string]interface{}{"k": 500},
+			want:    []string{AdviceTopKExceedsCount},
+		},
+		{
+			name:    "cosine min score above one",
+			builder: Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(10).MinScore(minScore),
+			stats:   CollectionStats{VectorCount: 1000, Metric: types.Cosine},
+			params:  map[string]interface{}{"min_score": 1.2},
+			want:    []string{AdviceMinScoreUnreachable},
+		},
+		{
+			name:    "cosine min score strict",
+			builder: Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(10).MinScore(minScore),
+			stats:   CollectionStats{VectorCount: 1000, Metric: types.Cosine},
+			params:  map[string]interface{}{"min_score": 0.98},
+			want:    []string{AdviceMinScoreStrict},
+		},
+		{
+			name:    "euclidean negative threshold",
+			builder: Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(10).MinScore(minScore),
+			stats:   CollectionStats{VectorCount: 1000, Metric: types.Euclidean},
+			params:  map[string]interface{}{"min_score": -0.5},
+			want:    []string{AdviceMinScoreUnreachable},
+		},
+		{
+			name:    "dot product unbounded",
+			builder: Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(10).MinScore(minScore),
+			stats:   CollectionStats{VectorCount: 1000, Metric: types.DotProduct},
+			params:  map[string]interface{}{"min_score": 42},
+			want:    nil,
+		},
+		{
+			name:    "unbound min score skipped",
+			builder: Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(10).MinScore(minScore),
+			stats:   CollectionStats{VectorCount: 1000, Metric: types.Cosine},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast, err := tt.builder.Build()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := adviceCodes(AdviseSearch(ast, tt.stats, tt.params))
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("expected %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAdviseSearch_NonSearch(t *testing.T) {
+	ast, err := Fetch(types.Collection{Name: "products"}).IDs(types.Param{Name: "id"}).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if advice := AdviseSearch(ast, CollectionStats{}, nil); advice != nil {
+		t.Errorf("expected no advice for FETCH, got %v", advice)
+	}
+}
+
+func TestRenderWithWarnings_Advice(t *testing.T) {
+	query := Search(types.Collection{Name: "products", DefaultEmbedding: "description"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(50).
+		MinScore(types.Param{Name: "min_score"}).
+		WithStats(CollectionStats{VectorCount: 10, Metric: types.Cosine}, map[string]interface{}{"min_score": 0.99})
+
+	_, warnings, err := query.RenderWithWarnings(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	codes := make(map[WarningCode]string)
+	for _, w := range warnings {
+		codes[w.Code] = w.Message
+	}
+	for _, code := range []string{AdviceTopKExceedsCount, AdviceMinScoreStrict} {
+		if _, ok := codes[WarningCode(code)]; !ok {
+			t.Errorf("expected a %s warning, got %v", code, warnings)
+		}
+	}
+	if msg := codes[WarningCode(AdviceTopKExceedsCount)]; !strings.Contains(msg, "TopK 50 exceeds the 10 vectors") {
+		t.Errorf("unexpected message: %s", msg)
+	}
+
+	_, warnings, err = Search(types.Collection{Name: "products", DefaultEmbedding: "description"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(50).
+		RenderWithWarnings(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, w := range warnings {
+		if w.Code == WarningCode(AdviceTopKExceedsCount) {
+			t.Errorf("expected no advice without stats, got %v", w)
+		}
+	}
+}
+
+func TestBuildWithWarnings_Advice(t *testing.T) {
+	_, warnings, err := Search(types.Collection{Name: "products", DefaultEmbedding: "description"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		WithStats(CollectionStats{}, nil).
+		BuildWithWarnings()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 2 || warnings[1].Code != WarningCode(AdviceEmptyCollection) {
+		t.Errorf("expected an empty collection warning, got %v", warnings)
+	}
+}
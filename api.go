@@ -47,6 +47,45 @@ type (
 
 	// QueryResult represents the result of rendering a query.
 	QueryResult = types.QueryResult
+
+	// Capabilities describes what a renderer supports.
+	Capabilities = types.Capabilities
+
+	// Endpoint describes the provider API call for a rendered query.
+	Endpoint = types.Endpoint
+
+	// VectorParam describes a parameter that binds a dense vector.
+	VectorParam = types.VectorParam
+
+	// FieldParam describes a parameter that binds a metadata field value.
+	FieldParam = types.FieldParam
+
+	// ParamSpec describes a required parameter for request validation.
+	ParamSpec = types.ParamSpec
+
+	// EmbeddingModel identifies the model behind an embedding field.
+	EmbeddingModel = types.EmbeddingModel
+
+	// FeatureNotice records a deprecated or experimental renderer mapping.
+	FeatureNotice = types.FeatureNotice
+
+	// Arg is a positional argument of a rendered SQL statement or command.
+	Arg = types.Arg
+
+	// Command is a command of a rendered command plan.
+	Command = types.Command
+
+	// FilterHint describes a filter condition to provider query planners.
+	FilterHint = types.FilterHint
+
+	// TextProperty is a field a keyword search covers, with an optional boost.
+	TextProperty = types.TextProperty
+
+	// BindTransform normalizes a parameter value at bind time.
+	BindTransform = types.BindTransform
+
+	// TransformOp names a bind transform.
+	TransformOp = types.TransformOp
 )
 
 // Re-export interface types for type assertions and polymorphism.
@@ -56,6 +95,15 @@ type (
 	FilterItem = types.FilterItem
 )
 
+// Re-export extension nodes so external renderers can consume them.
+type (
+	// ExtensionFilter is a provider-specific filter node. Use Extension() to create one.
+	ExtensionFilter = types.ExtensionFilter
+
+	// ScoreExpression is a provider-specific scoring expression. Use Scoring() to create one.
+	ScoreExpression = types.ScoreExpression
+)
+
 // Re-export enum types - these are safe as they're just type-safe constants.
 type (
 	// Operation represents a vector database operation type.
@@ -69,6 +117,89 @@ type (
 
 	// DistanceMetric represents a distance metric for similarity.
 	DistanceMetric = types.DistanceMetric
+
+	// Format identifies the wire format of a rendered query.
+	Format = types.Format
+
+	// Modality identifies the kind of search input a SEARCH uses.
+	Modality = types.Modality
+
+	// VectorEncoding identifies how dense vectors are written into a bound body.
+	VectorEncoding = types.VectorEncoding
+
+	// Quantization identifies how an embedding's vectors are compressed.
+	Quantization = types.Quantization
+
+	// ParamType is the inferred JSON type of a parameter value.
+	ParamType = types.ParamType
+
+	// ParamSource identifies the part of a query that uses a parameter.
+	ParamSource = types.ParamSource
+
+	// Stability marks a renderer mapping whose output is expected to change.
+	Stability = types.Stability
+
+	// ArgKind describes how a positional argument is encoded.
+	ArgKind = types.ArgKind
+
+	// ExpressionStyle selects how bound values are written into expression
+	// strings.
+	ExpressionStyle = types.ExpressionStyle
+
+	// SchemaNames lists the names a schema declares.
+	SchemaNames = types.SchemaNames
+
+	// CollectionNames lists the names a collection declares.
+	CollectionNames = types.CollectionNames
+
+	// NameValidator is implemented by renderers for providers that restrict
+	// names.
+	NameValidator = types.NameValidator
+
+	// FieldNames maps logical metadata field names to physical names.
+	FieldNames = types.FieldNames
+
+	// FetchKey is a FETCH by a unique metadata field.
+	FetchKey = types.FetchKey
+
+	// FetchByRenderer is implemented by renderers that render FETCH by key.
+	FetchByRenderer = types.FetchByRenderer
+
+	// ExistsRenderer is implemented by renderers that render existence
+	// checks by filter.
+	ExistsRenderer = types.ExistsRenderer
+
+	// VersionRange is a range of provider versions.
+	VersionRange = types.VersionRange
+
+	// VersionRenderer is implemented by renderers that need a range of
+	// provider versions.
+	VersionRenderer = types.VersionRenderer
+
+	// PartitionOp is a partition lifecycle operation.
+	PartitionOp = types.PartitionOp
+
+	// IndexKind is the kind of a payload index on a metadata field.
+	IndexKind = types.IndexKind
+
+	// Freshness is the consistency a read asks for.
+	Freshness = types.Freshness
+
+	// FreshnessLevel is how current the data a read sees must be.
+	FreshnessLevel = types.FreshnessLevel
+
+	// Snapshot pins a read to a point-in-time view of the index.
+	Snapshot = types.Snapshot
+
+	// PageToken is an opaque cursor for resuming a paginated read.
+	PageToken = types.PageToken
+
+	// Page is a paginated listing of a collection's records.
+	Page = types.Page
+
+	// ListRenderer is implemented by renderers that render paginated
+	// listings.
+	ListRenderer = types.ListRenderer
 )
 
 // Internal types are intentionally NOT re-exported to prevent validation bypass:
@@ -89,6 +220,7 @@ const (
 // Filter operator constants.
 const (
 	OpEQ               = types.EQ
+	OpEqFold           = types.EqFold
 	OpNE               = types.NE
 	OpGT               = types.GT
 	OpGE               = types.GE
@@ -122,6 +254,121 @@ const (
 	MetricManhattan  = types.Manhattan
 )
 
+// Search input modality constants.
+const (
+	ModalityVector = types.ModalityVector
+	ModalityText   = types.ModalityText
+	ModalityImage  = types.ModalityImage
+
+	ModalityKeyword = types.ModalityKeyword
+	ModalityHybrid  = types.ModalityHybrid
+)
+
+// Vector encoding constants.
+const (
+	VectorEncodingJSON          = types.VectorEncodingJSON
+	VectorEncodingFloat32Base64 = types.VectorEncodingFloat32Base64
+	VectorEncodingFloat16Base64 = types.VectorEncodingFloat16Base64
+)
+
+// Quantization constants.
+const (
+	QuantizationNone    = types.QuantizationNone
+	QuantizationScalar  = types.QuantizationScalar
+	QuantizationProduct = types.QuantizationProduct
+	QuantizationBinary  = types.QuantizationBinary
+)
+
+// Parameter type constants.
+const (
+	ParamAny     = types.ParamAny
+	ParamVector  = types.ParamVector
+	ParamString  = types.ParamString
+	ParamNumber  = types.ParamNumber
+	ParamInteger = types.ParamInteger
+	ParamBoolean = types.ParamBoolean
+	ParamList    = types.ParamList
+)
+
+// Parameter source constants.
+const (
+	SourceQueryVector    = types.SourceQueryVector
+	SourceNearText       = types.SourceNearText
+	SourceNearImage      = types.SourceNearImage
+	SourceTextQuery      = types.SourceTextQuery
+	SourceTopK           = types.SourceTopK
+	SourceMinScore       = types.SourceMinScore
+	SourceNamespace      = types.SourceNamespace
+	SourceSnapshot       = types.SourceSnapshot
+	SourceID             = types.SourceID
+	SourceKey            = types.SourceKey
+	SourceRecordID       = types.SourceRecordID
+	SourceRecordVector   = types.SourceRecordVector
+	SourceSparseVector   = types.SourceSparseVector
+	SourceRecordMetadata = types.SourceRecordMetadata
+	SourceUpdate         = types.SourceUpdate
+	SourceFilter         = types.SourceFilter
+	SourceScoring        = types.SourceScoring
+)
+
+// Stability constants.
+const (
+	StabilityDeprecated   = types.StabilityDeprecated
+	StabilityExperimental = types.StabilityExperimental
+)
+
+// Argument kind constants.
+const (
+	ArgScalar = types.ArgScalar
+	ArgVector = types.ArgVector
+	ArgJSON   = types.ArgJSON
+	ArgBlob   = types.ArgBlob
+)
+
+// Bind transform constants.
+const (
+	TransformLower    = types.TransformLower
+	TransformTrim     = types.TransformTrim
+	TransformHash     = types.TransformHash
+	TransformTruncate = types.TransformTruncate
+)
+
+// Expression style constants.
+const (
+	ExpressionJSON     = types.ExpressionJSON
+	ExpressionBacktick = types.ExpressionBacktick
+	ExpressionEscaped  = types.ExpressionEscaped
+)
+
+// Freshness level constants.
+const (
+	FreshnessStrong   = types.FreshnessStrong
+	FreshnessBounded  = types.FreshnessBounded
+	FreshnessEventual = types.FreshnessEventual
+)
+
+// Partition operation constants.
+const (
+	PartitionCreate = types.PartitionCreate
+	PartitionDrop   = types.PartitionDrop
+	PartitionLoad   = types.PartitionLoad
+)
+
+// Payload index kind constants.
+const (
+	IndexKeyword = types.IndexKeyword
+	IndexInteger = types.IndexInteger
+	IndexFloat   = types.IndexFloat
+	IndexBool    = types.IndexBool
+	IndexGeo     = types.IndexGeo
+	IndexText    = types.IndexText
+)
+
+// Format constants.
+const (
+	FormatJSON = types.FormatJSON
+)
+
 // Complexity limit constants.
 const (
 	MaxFilterDepth    = types.MaxFilterDepth
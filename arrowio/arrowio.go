@@ -0,0 +1,320 @@
+// Package arrowio reads and writes vector records as Arrow IPC streams and
+// Parquet files, so exports from one provider can be stored in a columnar
+// format and re-ingested later by VECTQL or by data-engineering tools.
+//
+// The layout comes from VECTQL.RecordColumns: an id column, one
+// fixed_size_list<float> column per embedding, and one nullable column per
+// metadata field:
+//
+//	columns, err := v.RecordColumns("products")
+//	w, err := arrowio.NewParquetWriter(file, columns)
+//	err = w.Write(records...)
+//	err = w.Close()
+//
+// The package is a separate module so the core does not depend on Arrow.
+package arrowio
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/zoobzio/vectql"
+)
+
+// Record is one vector record: its ID, one vector per embedding keyed by
+// embedding name, and metadata keyed by field name.
+type Record struct {
+	ID       string
+	Vectors  map[string][]float32
+	Metadata map[string]interface{}
+}
+
+// metadataTypes maps the Arrow types RecordColumns reports for metadata
+// fields to Arrow data types.
+var metadataTypes = map[string]arrow.DataType{
+	"utf8":               arrow.BinaryTypes.String,
+	"int64":              arrow.PrimitiveTypes.Int64,
+	"double":             arrow.PrimitiveTypes.Float64,
+	"bool":               arrow.FixedWidthTypes.Boolean,
+	"list<item: utf8>":   arrow.ListOf(arrow.BinaryTypes.String),
+	"list<item: int64>":  arrow.ListOf(arrow.PrimitiveTypes.Int64),
+	"list<item: double>": arrow.ListOf(arrow.PrimitiveTypes.Float64),
+}
+
+// Schema returns the Arrow schema of columns.
+func Schema(columns []vectql.Column) (*arrow.Schema, error) {
+	fields := make([]arrow.Field, len(columns))
+	for i, c := range columns {
+		var dt arrow.DataType
+		switch c.Kind {
+		case vectql.ColumnID:
+			dt = arrow.BinaryTypes.String
+		case vectql.ColumnVector:
+			if c.Dimensions <= 0 {
+				return nil, fmt.Errorf("vector column '%s' has no dimensions", c.Name)
+			}
+			dt = arrow.FixedSizeListOf(int32(c.Dimensions), arrow.PrimitiveTypes.Float32)
+		case vectql.ColumnMetadata:
+			t, ok := metadataTypes[c.ArrowType]
+			if !ok {
+				return nil, fmt.Errorf("metadata column '%s' has unsupported type %s", c.Name, c.ArrowType)
+			}
+			dt = t
+		default:
+			return nil, fmt.Errorf("column '%s' has unknown kind %s", c.Name, c.Kind)
+		}
+		fields[i] = arrow.Field{Name: c.Name, Type: dt, Nullable: c.Nullable}
+	}
+	return arrow.NewSchema(fields, nil), nil
+}
+
+// batch converts records into one Arrow record batch.
+func batch(schema *arrow.Schema, columns []vectql.Column, records []Record) (arrow.RecordBatch, error) {
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	for _, r := range records {
+		for i, c := range columns {
+			if err := appendValue(b.Field(i), c, r); err != nil {
+				return nil, fmt.Errorf("record '%s': column '%s': %w", r.ID, c.Name, err)
+			}
+		}
+	}
+	return b.NewRecordBatch(), nil
+}
+
+func appendValue(b array.Builder, c vectql.Column, r Record) error {
+	switch c.Kind {
+	case vectql.ColumnID:
+		if r.ID == "" {
+			return fmt.Errorf("missing id")
+		}
+		b.(*array.StringBuilder).Append(r.ID)
+		return nil
+	case vectql.ColumnVector:
+		vec, ok := r.Vectors[c.Name]
+		if !ok {
+			return fmt.Errorf("missing vector")
+		}
+		if len(vec) != c.Dimensions {
+			return fmt.Errorf("expected %d dimensions, got %d", c.Dimensions, len(vec))
+		}
+		lb := b.(*array.FixedSizeListBuilder)
+		lb.Append(true)
+		lb.ValueBuilder().(*array.Float32Builder).AppendValues(vec, nil)
+		return nil
+	}
+
+	value, ok := r.Metadata[c.Name]
+	if !ok || value == nil {
+		b.AppendNull()
+		return nil
+	}
+	if lb, isList := b.(*array.ListBuilder); isList {
+		items, ok := listItems(value)
+		if !ok {
+			return fmt.Errorf("expected a list, got %T", value)
+		}
+		lb.Append(true)
+		for _, item := range items {
+			if err := appendScalar(lb.ValueBuilder(), item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return appendScalar(b, value)
+}
+
+func appendScalar(b array.Builder, value interface{}) error {
+	switch sb := b.(type) {
+	case *array.StringBuilder:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %T", value)
+		}
+		sb.Append(s)
+	case *array.Int64Builder:
+		n, ok := toInt64(value)
+		if !ok {
+			return fmt.Errorf("expected an integer, got %v (%T)", value, value)
+		}
+		sb.Append(n)
+	case *array.Float64Builder:
+		f, ok := toFloat64(value)
+		if !ok {
+			return fmt.Errorf("expected a number, got %T", value)
+		}
+		sb.Append(f)
+	case *array.BooleanBuilder:
+		v, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("expected a bool, got %T", value)
+		}
+		sb.Append(v)
+	default:
+		return fmt.Errorf("unsupported builder %T", b)
+	}
+	return nil
+}
+
+// listItems returns the elements of a list value: []interface{} as decoded
+// from JSON, or a typed slice.
+func listItems(value interface{}) ([]interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		return v, true
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items, true
+	case []int64:
+		items := make([]interface{}, len(v))
+		for i, n := range v {
+			items[i] = n
+		}
+		return items, true
+	case []int:
+		items := make([]interface{}, len(v))
+		for i, n := range v {
+			items[i] = n
+		}
+		return items, true
+	case []float64:
+		items := make([]interface{}, len(v))
+		for i, f := range v {
+			items[i] = f
+		}
+		return items, true
+	case []float32:
+		items := make([]interface{}, len(v))
+		for i, f := range v {
+			items[i] = f
+		}
+		return items, true
+	}
+	return nil, false
+}
+
+// toInt64 converts integer values, including integral float64 values and
+// json.Number as decoded from JSON.
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		if v != math.Trunc(v) || math.Abs(v) > 1<<53 {
+			return 0, false
+		}
+		return int64(v), true
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// checkSchema verifies that a stream or file has the layout of columns.
+func checkSchema(got *arrow.Schema, columns []vectql.Column) error {
+	want, err := Schema(columns)
+	if err != nil {
+		return err
+	}
+	if got.NumFields() != want.NumFields() {
+		return fmt.Errorf("expected %d columns, got %d", want.NumFields(), got.NumFields())
+	}
+	for i, f := range want.Fields() {
+		g := got.Field(i)
+		if g.Name != f.Name || !arrow.TypeEqual(g.Type, f.Type) {
+			return fmt.Errorf("column %d: expected %s %s, got %s %s", i, f.Name, f.Type, g.Name, g.Type)
+		}
+	}
+	return nil
+}
+
+// records converts an Arrow record batch whose schema passed checkSchema.
+func records(rec arrow.RecordBatch, columns []vectql.Column) []Record {
+	out := make([]Record, rec.NumRows())
+	for row := range out {
+		out[row] = Record{Vectors: make(map[string][]float32)}
+	}
+	for i, c := range columns {
+		col := rec.Column(i)
+		for row := range out {
+			if col.IsNull(row) {
+				continue
+			}
+			switch c.Kind {
+			case vectql.ColumnID:
+				out[row].ID = col.(*array.String).Value(row)
+			case vectql.ColumnVector:
+				list := col.(*array.FixedSizeList)
+				values := list.ListValues().(*array.Float32).Float32Values()
+				start, end := list.ValueOffsets(row)
+				out[row].Vectors[c.Name] = append([]float32(nil), values[start:end]...)
+			default:
+				if out[row].Metadata == nil {
+					out[row].Metadata = make(map[string]interface{})
+				}
+				out[row].Metadata[c.Name] = value(col, row)
+			}
+		}
+	}
+	return out
+}
+
+// value returns a metadata value as a string, int64, float64, bool, or a
+// slice of one of them.
+func value(col arrow.Array, row int) interface{} {
+	switch a := col.(type) {
+	case *array.String:
+		return a.Value(row)
+	case *array.Int64:
+		return a.Value(row)
+	case *array.Float64:
+		return a.Value(row)
+	case *array.Boolean:
+		return a.Value(row)
+	case *array.List:
+		start, end := a.ValueOffsets(row)
+		switch items := a.ListValues().(type) {
+		case *array.String:
+			out := make([]string, 0, end-start)
+			for j := start; j < end; j++ {
+				out = append(out, items.Value(int(j)))
+			}
+			return out
+		case *array.Int64:
+			return append(make([]int64, 0, end-start), items.Int64Values()[start:end]...)
+		case *array.Float64:
+			return append(make([]float64, 0, end-start), items.Float64Values()[start:end]...)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package arrowio
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql"
+)
+
+func testColumns(t *testing.T) []vectql.Column {
+	t.Helper()
+	v, err := vectql.NewFromVDML(&vdml.Schema{
+		Collections: map[string]*vdml.Collection{
+			"products": {
+				Name: "products",
+				Embeddings: []*vdml.Embedding{
+					{Name: "description", Dimensions: 3, Metric: vdml.Cosine},
+					{Name: "image", Dimensions: 2, Metric: vdml.Euclidean},
+				},
+				Metadata: []*vdml.MetadataField{
+					{Name: "category", Type: vdml.TypeString},
+					{Name: "stock", Type: vdml.TypeInt},
+					{Name: "price", Type: vdml.TypeFloat},
+					{Name: "active", Type: vdml.TypeBool},
+					{Name: "tags", Type: vdml.TypeStringArray},
+					{Name: "sizes", Type: vdml.TypeIntArray},
+					{Name: "ratings", Type: vdml.TypeFloatArray},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	columns, err := v.RecordColumns("products")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return columns
+}
+
+// testRecords uses the Go types records are read back as, so round trips
+// compare equal.
+func testRecords() []Record {
+	return []Record{
+		{
+			ID: "p1",
+			Vectors: map[string][]float32{
+				"description": {0.1, 0.2, 0.3},
+				"image":       {1, -1},
+			},
+			Metadata: map[string]interface{}{
+				"category": "shoes",
+				"stock":    int64(12),
+				"price":    59.99,
+				"active":   true,
+				"tags":     []string{"running", "outdoor"},
+				"sizes":    []int64{40, 41, 42},
+				"ratings":  []float64{4.5, 3.9},
+			},
+		},
+		{
+			// Missing fields are written as nulls and read back absent
+			ID: "p2",
+			Vectors: map[string][]float32{
+				"description": {-0.5, 0, 0.5},
+				"image":       {0, 0},
+			},
+			Metadata: map[string]interface{}{
+				"category": "hats",
+				"tags":     []string{},
+			},
+		},
+		{
+			ID: "p3",
+			Vectors: map[string][]float32{
+				"description": {1, 1, 1},
+				"image":       {2, 3},
+			},
+		},
+	}
+}
+
+func TestIPCRoundTrip(t *testing.T) {
+	columns := testColumns(t)
+	records := testRecords()
+
+	var buf bytes.Buffer
+	w, err := NewIPCWriter(&buf, columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Write(records[:2]...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Write(records[2:]...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := ReadIPC(&buf, columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("expected %+v, got %+v", records, got)
+	}
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	columns := testColumns(t)
+	records := testRecords()
+
+	var buf bytes.Buffer
+	w, err := NewParquetWriter(&buf, columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Write(records[:2]...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Write(records[2:]...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := ReadParquet(bytes.NewReader(buf.Bytes()), columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, records) {
+		t.Errorf("expected %+v, got %+v", records, got)
+	}
+}
+
+func TestWrite_Validation(t *testing.T) {
+	columns := testColumns(t)
+
+	tests := []struct {
+		name   string
+		record Record
+		want   string
+	}{
+		{"missing id", Record{Vectors: testRecords()[2].Vectors}, "missing id"},
+		{"missing vector", Record{ID: "p1", Vectors: map[string][]float32{"description": {1, 2, 3}}}, "missing vector"},
+		{"wrong dimensions", Record{ID: "p1", Vectors: map[string][]float32{"description": {1}, "image": {1, 2}}}, "expected 3 dimensions"},
+		{"wrong type", Record{ID: "p1", Vectors: testRecords()[2].Vectors, Metadata: map[string]interface{}{"stock": "many"}}, "expected an integer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewIPCWriter(&buf, columns)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer w.Close()
+			if err := w.Write(tt.record); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRead_SchemaMismatch(t *testing.T) {
+	columns := testColumns(t)
+
+	var buf bytes.Buffer
+	w, err := NewIPCWriter(&buf, columns)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Write(testRecords()...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := ReadIPC(&buf, columns[:2]); err == nil {
+		t.Error("expected an error for a stream with a different layout")
+	}
+}
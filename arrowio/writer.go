@@ -0,0 +1,119 @@
+package arrowio
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/apache/arrow-go/v18/arrow"
+	"github.com/apache/arrow-go/v18/arrow/array"
+	"github.com/apache/arrow-go/v18/arrow/ipc"
+	"github.com/apache/arrow-go/v18/arrow/memory"
+	"github.com/apache/arrow-go/v18/parquet"
+	"github.com/apache/arrow-go/v18/parquet/file"
+	"github.com/apache/arrow-go/v18/parquet/pqarrow"
+	"github.com/zoobzio/vectql"
+)
+
+// batchWriter is the Arrow IPC or Parquet writer behind a Writer.
+type batchWriter interface {
+	Write(rec arrow.RecordBatch) error
+	Close() error
+}
+
+// Writer writes records in batches. Each Write call adds one record batch to
+// an IPC stream, or one row group to a Parquet file. Close finishes the
+// stream or file; it does not close the underlying io.Writer.
+type Writer struct {
+	columns []vectql.Column
+	schema  *arrow.Schema
+	out     batchWriter
+}
+
+// NewIPCWriter writes an Arrow IPC stream to w.
+func NewIPCWriter(w io.Writer, columns []vectql.Column) (*Writer, error) {
+	schema, err := Schema(columns)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{columns: columns, schema: schema, out: ipc.NewWriter(w, ipc.WithSchema(schema))}, nil
+}
+
+// NewParquetWriter writes a Parquet file to w. The Arrow schema is stored in
+// the file metadata so readers restore the fixed-size vector columns.
+func NewParquetWriter(w io.Writer, columns []vectql.Column) (*Writer, error) {
+	schema, err := Schema(columns)
+	if err != nil {
+		return nil, err
+	}
+	out, err := pqarrow.NewFileWriter(schema, w, parquet.NewWriterProperties(), pqarrow.NewArrowWriterProperties(pqarrow.WithStoreSchema()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+	return &Writer{columns: columns, schema: schema, out: out}, nil
+}
+
+// Write writes records as one batch. Every record needs an ID and a vector
+// of the declared dimensions for each embedding; missing metadata fields are
+// written as nulls.
+func (w *Writer) Write(records ...Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+	rec, err := batch(w.schema, w.columns, records)
+	if err != nil {
+		return err
+	}
+	defer rec.Release()
+	return w.out.Write(rec)
+}
+
+// Close finishes the stream or file.
+func (w *Writer) Close() error {
+	return w.out.Close()
+}
+
+// ReadIPC reads every record of an Arrow IPC stream. The stream must have
+// the layout of columns.
+func ReadIPC(r io.Reader, columns []vectql.Column) ([]Record, error) {
+	reader, err := ipc.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid arrow stream: %w", err)
+	}
+	defer reader.Release()
+	return readAll(reader, columns)
+}
+
+// ReadParquet reads every record of a Parquet file. The file must have the
+// layout of columns.
+func ReadParquet(r parquet.ReaderAtSeeker, columns []vectql.Column) ([]Record, error) {
+	pf, err := file.NewParquetReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parquet file: %w", err)
+	}
+	defer pf.Close()
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{BatchSize: 1024}, memory.DefaultAllocator)
+	if err != nil {
+		return nil, fmt.Errorf("invalid parquet file: %w", err)
+	}
+	reader, err := fr.GetRecordReader(context.Background(), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parquet file: %w", err)
+	}
+	defer reader.Release()
+	return readAll(reader, columns)
+}
+
+func readAll(reader array.RecordReader, columns []vectql.Column) ([]Record, error) {
+	if err := checkSchema(reader.Schema(), columns); err != nil {
+		return nil, err
+	}
+	var out []Record
+	for reader.Next() {
+		out = append(out, records(reader.RecordBatch(), columns)...)
+	}
+	if err := reader.Err(); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to read records: %w", err)
+	}
+	return out, nil
+}
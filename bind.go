@@ -0,0 +1,410 @@
+package vectql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// ModelVector is a dense vector tagged with the model that produced it.
+// Binding a ModelVector to an embedding that declares a different model fails.
+type ModelVector struct {
+	// Model is the producing model, as "name" or "name@version".
+	Model string
+
+	// Values holds the vector components.
+	Values []float32
+}
+
+// ModelCheck validates a value bound to a vector parameter. It is called for
+// every vector parameter whose value is supplied, before the value is written.
+type ModelCheck func(vp types.VectorParam, value interface{}) error
+
+// BindOption configures Bind.
+type BindOption func(*bindConfig)
+
+type bindConfig struct {
+	modelCheck ModelCheck
+	normalize  bool
+	transforms *FieldTransforms
+	encoding   VectorEncoding
+
+	classify    ParamClassifier
+	classPolicy ClassPolicy
+
+	tenantSetup bool
+}
+
+// WithModelCheck registers a hook that validates vector parameters, e.g. by
+// looking up the model a cached vector was produced by.
+func WithModelCheck(check ModelCheck) BindOption {
+	return func(c *bindConfig) {
+		c.modelCheck = check
+	}
+}
+
+// WithNormalization L2-normalizes vectors bound to embeddings whose metric is
+// cosine or dot product, for providers that expect unit-length inputs.
+// Embeddings configured with NormalizeNever are left untouched; embeddings
+// configured with NormalizeAlways are normalized even without this option.
+func WithNormalization() BindOption {
+	return func(c *bindConfig) {
+		c.normalize = true
+	}
+}
+
+// placeholderPattern matches ":name" placeholders embedded in expression strings.
+var placeholderPattern = regexp.MustCompile(`:([A-Za-z_][A-Za-z0-9_]*)`)
+
+// Bind substitutes parameter values into a rendered query and returns the
+// provider-ready JSON body. Placeholders that make up a whole JSON string are
+// replaced by the value itself; placeholders embedded in filter expressions
+// are replaced by the value's JSON literal. Every parameter in
+// RequiredParams must be supplied; extra parameters are ignored.
+func Bind(result *types.QueryResult, params map[string]interface{}, opts ...BindOption) (string, error) {
+	var cfg bindConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if _, err := classifyParams(result, params, &cfg); err != nil {
+		return "", err
+	}
+	return bind(result, params, &cfg)
+}
+
+// bind substitutes params into result once any classification has passed.
+func bind(result *types.QueryResult, params map[string]interface{}, cfg *bindConfig) (string, error) {
+	params = deriveParams(result.Derived, params)
+	required := make(map[string]bool, len(result.RequiredParams))
+	for _, name := range result.RequiredParams {
+		if _, ok := params[name]; !ok {
+			return "", fmt.Errorf("missing parameter: %s", name)
+		}
+		required[name] = true
+	}
+
+	values, err := bindParams(result, params, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader([]byte(result.JSON)))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return "", fmt.Errorf("failed to parse query: %w", err)
+	}
+
+	bound, err := bindValue(tree, values, required, result.Expressions)
+	if err != nil {
+		return "", err
+	}
+
+	out, err := marshalJSON(bound)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize query: %w", err)
+	}
+	return string(out), nil
+}
+
+// bindParams checks and transforms parameter values for result: vectors,
+// then bind transforms, then field transforms, then joined lists. The
+// caller's map is not modified.
+func bindParams(result *types.QueryResult, params map[string]interface{}, cfg *bindConfig) (map[string]interface{}, error) {
+	values, err := bindVectors(result.Vectors, params, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyBindTransforms(result.Transforms, values); err != nil {
+		return nil, err
+	}
+	if cfg.transforms != nil {
+		if err := transformFields(result.Fields, values, cfg.transforms); err != nil {
+			return nil, err
+		}
+	}
+	if err := joinParams(result.Joined, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// joinParams replaces the list values of joined parameters with their
+// elements joined by the separator. Other values bind as they are.
+func joinParams(joined map[string]string, values map[string]interface{}) error {
+	for name, sep := range joined {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		if _, isString := value.(string); isString {
+			continue
+		}
+		items, isList := toSlice(value)
+		if !isList {
+			continue
+		}
+		elems := make([]string, len(items))
+		for i, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("parameter %s: expected a list of strings, got element %T", name, item)
+			}
+			elems[i] = s
+		}
+		values[name] = strings.Join(elems, sep)
+	}
+	return nil
+}
+
+// marshalJSON encodes v without escaping HTML characters, which would
+// otherwise corrupt comparison operators inside expression strings.
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// bindVectors checks and transforms vector parameters, returning params with
+// tagged vectors unwrapped. The caller's map is not modified.
+func bindVectors(vectors []types.VectorParam, params map[string]interface{}, cfg *bindConfig) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		if mv, ok := value.(ModelVector); ok {
+			value = mv.Values
+		}
+		values[name] = value
+	}
+
+	encoded := make(map[string]bool)
+	for _, vp := range vectors {
+		value, ok := params[vp.Param]
+		if !ok || encoded[vp.Param] {
+			continue
+		}
+		if cfg.modelCheck != nil {
+			if err := cfg.modelCheck(vp, value); err != nil {
+				return nil, fmt.Errorf("parameter %s: %w", vp.Param, err)
+			}
+		}
+		if mv, ok := value.(ModelVector); ok {
+			if model := vp.Embedding.Model; !model.IsZero() && !modelMatches(mv.Model, model) {
+				return nil, fmt.Errorf("parameter %s: vector produced by model %s but embedding '%s' expects %s",
+					vp.Param, mv.Model, vp.Embedding.Name, model)
+			}
+		}
+		if shouldNormalize(vp.Embedding, cfg) {
+			normalized, err := normalizeVector(values[vp.Param])
+			if err != nil {
+				return nil, fmt.Errorf("parameter %s: %w", vp.Param, err)
+			}
+			values[vp.Param] = normalized
+		}
+		if cfg.encoding != VectorEncodingJSON {
+			enc, err := encodeVector(values[vp.Param], cfg.encoding)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %s: %w", vp.Param, err)
+			}
+			values[vp.Param] = enc
+			encoded[vp.Param] = true
+		}
+	}
+	return values, nil
+}
+
+func shouldNormalize(e types.EmbeddingField, cfg *bindConfig) bool {
+	switch e.Normalization {
+	case types.NormalizeAlways:
+		return true
+	case types.NormalizeNever:
+		return false
+	default:
+		return cfg.normalize && (e.Metric == types.Cosine || e.Metric == types.DotProduct)
+	}
+}
+
+// normalizeVector scales a dense vector to unit L2 length, preserving its
+// element type.
+func normalizeVector(v interface{}) (interface{}, error) {
+	switch vec := v.(type) {
+	case []float32:
+		var sum float64
+		for _, x := range vec {
+			sum += float64(x) * float64(x)
+		}
+		if sum == 0 {
+			return nil, fmt.Errorf("cannot normalize a zero vector")
+		}
+		norm := math.Sqrt(sum)
+		out := make([]float32, len(vec))
+		for i, x := range vec {
+			out[i] = float32(float64(x) / norm)
+		}
+		return out, nil
+
+	case []float64:
+		var sum float64
+		for _, x := range vec {
+			sum += x * x
+		}
+		if sum == 0 {
+			return nil, fmt.Errorf("cannot normalize a zero vector")
+		}
+		norm := math.Sqrt(sum)
+		out := make([]float64, len(vec))
+		for i, x := range vec {
+			out[i] = x / norm
+		}
+		return out, nil
+
+	case []interface{}:
+		floats := make([]float64, len(vec))
+		for i, x := range vec {
+			f, ok := toFloat(x)
+			if !ok {
+				return nil, fmt.Errorf("cannot normalize vector element of type %T", x)
+			}
+			floats[i] = f
+		}
+		return normalizeVector(floats)
+
+	default:
+		return nil, fmt.Errorf("cannot normalize %T", v)
+	}
+}
+
+// modelMatches compares a vector's model tag against a declared model. A tag
+// without a version matches any version of the same model.
+func modelMatches(tag string, model types.EmbeddingModel) bool {
+	if tag == model.String() {
+		return true
+	}
+	return tag == model.Name
+}
+
+func bindValue(v interface{}, params map[string]interface{}, required map[string]bool, style types.ExpressionStyle) (interface{}, error) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			bound, err := bindValue(child, params, required, style)
+			if err != nil {
+				return nil, err
+			}
+			value[key] = bound
+		}
+		return value, nil
+
+	case []interface{}:
+		for i, child := range value {
+			bound, err := bindValue(child, params, required, style)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = bound
+		}
+		return value, nil
+
+	case string:
+		if len(value) > 1 && value[0] == ':' && required[value[1:]] {
+			return params[value[1:]], nil
+		}
+		return bindExpression(value, params, required, style)
+
+	default:
+		return v, nil
+	}
+}
+
+// bindExpression replaces placeholders embedded in an expression string, such
+// as a Milvus filter, with literals in the given style.
+func bindExpression(expr string, params map[string]interface{}, required map[string]bool, style types.ExpressionStyle) (string, error) {
+	var bindErr error
+	bound := placeholderPattern.ReplaceAllStringFunc(expr, func(match string) string {
+		name := match[1:]
+		if !required[name] || bindErr != nil {
+			return match
+		}
+		literal, err := expressionLiteral(params[name], style)
+		if err != nil {
+			bindErr = fmt.Errorf("parameter %s: %w", name, err)
+			return match
+		}
+		return literal
+	})
+	return bound, bindErr
+}
+
+// expressionLiteral formats v for an expression string. Backtick style
+// wraps strings in backticks and writes lists as [a,b], as Typesense
+// filter_by expects. Escaped style backslash-escapes whitespace and syntax
+// characters in strings and writes lists as (a, b), as Marqo filter strings
+// expect. Everything else is written as JSON.
+func expressionLiteral(v interface{}, style types.ExpressionStyle) (string, error) {
+	if style != types.ExpressionJSON {
+		if s, ok := v.(string); ok {
+			return stringLiteral(s, style)
+		}
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+			elems := make([]string, rv.Len())
+			for i := range elems {
+				elem, err := expressionLiteral(rv.Index(i).Interface(), style)
+				if err != nil {
+					return "", err
+				}
+				elems[i] = elem
+			}
+			if style == types.ExpressionEscaped {
+				return "(" + strings.Join(elems, ", ") + ")", nil
+			}
+			return "[" + strings.Join(elems, ",") + "]", nil
+		}
+	}
+	literal, err := marshalJSON(v)
+	if err != nil {
+		return "", err
+	}
+	return string(literal), nil
+}
+
+// filterEscaper escapes the characters Marqo filter strings give meaning to.
+var filterEscaper = strings.NewReplacer(
+	`\`, `\\`, " ", `\ `, "\t", `\`+"\t", "\n", `\`+"\n",
+	"(", `\(`, ")", `\)`, "[", `\[`, "]", `\]`, "{", `\{`, "}", `\}`, ":", `\:`, ",", `\,`,
+)
+
+// stringLiteral writes a string in a non-JSON expression style.
+func stringLiteral(s string, style types.ExpressionStyle) (string, error) {
+	if style == types.ExpressionEscaped {
+		return filterEscaper.Replace(s), nil
+	}
+	if strings.Contains(s, "`") {
+		return "", fmt.Errorf("value %q contains a backtick", s)
+	}
+	return "`" + s + "`", nil
+}
+
+// vectorParams lists the dense vector parameters of a query with the
+// embedding they target.
+func vectorParams(ast *types.VectorAST) []types.VectorParam {
+	var vectors []types.VectorParam
+	embedding := ast.TargetEmbedding()
+	if ast.QueryVector != nil && ast.QueryVector.Param != nil {
+		vectors = append(vectors, types.VectorParam{Param: ast.QueryVector.Param.Name, Embedding: embedding})
+	}
+	for _, record := range ast.Vectors {
+		if record.Vector.Param != nil {
+			vectors = append(vectors, types.VectorParam{Param: record.Vector.Param.Name, Embedding: embedding})
+		}
+	}
+	return vectors
+}
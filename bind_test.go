@@ -0,0 +1,366 @@
+package vectql
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/weaviate"
+)
+
+func TestBind_WholeValues(t *testing.T) {
+	result := &types.QueryResult{
+		JSON:           `{"filter":{"category":{"$eq":":cat"}},"topK":10,"vector":":query_vec"}`,
+		RequiredParams: []string{"query_vec", "cat"},
+	}
+
+	got, err := Bind(result, map[string]interface{}{
+		"query_vec": []float32{0.5, 0.25},
+		"cat":       "shoes",
+		"unused":    1,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"filter":{"category":{"$eq":"shoes"}},"topK":10,"vector":[0.5,0.25]}`
+	if got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestBind_EmbeddedExpression(t *testing.T) {
+	result := &types.QueryResult{
+		JSON:           `{"filter":"(category == :cat and price >= :min_price)","limit":5}`,
+		RequiredParams: []string{"cat", "min_price"},
+	}
+
+	got, err := Bind(result, map[string]interface{}{"cat": `say "hi"`, "min_price": 9.5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"filter":"(category == \"say \\\"hi\\\"\" and price >= 9.5)","limit":5}`
+	if got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestBind_BacktickExpression(t *testing.T) {
+	result := &types.QueryResult{
+		JSON:           `{"filter_by":"(color:=:color && tags:=:tags && price:>=:min)"}`,
+		RequiredParams: []string{"color", "tags", "min"},
+		Expressions:    types.ExpressionBacktick,
+	}
+
+	params := map[string]interface{}{"color": `a "red"`, "tags": []string{"x", "y"}, "min": 9.5}
+	got, err := Bind(result, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "{\"filter_by\":\"(color:=`a \\\"red\\\"` && tags:=[`x`,`y`] && price:>=9.5)\"}"
+	if got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	params["color"] = "a`b"
+	if _, err := Bind(result, params); err == nil {
+		t.Error("expected error for a value containing a backtick")
+	}
+}
+
+func TestBind_EscapedExpression(t *testing.T) {
+	result := &types.QueryResult{
+		JSON:           `{"filter":"(color:(:color) AND brand IN :brands AND price:[:min TO *])"}`,
+		RequiredParams: []string{"color", "brands", "min"},
+		Expressions:    types.ExpressionEscaped,
+	}
+
+	params := map[string]interface{}{"color": "dark (red)", "brands": []string{"a:b", "c,d"}, "min": 9.5}
+	got, err := Bind(result, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"filter":"(color:(dark\\ \\(red\\)) AND brand IN (a\\:b, c\\,d) AND price:[9.5 TO *])"}`
+	if got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestBind_JoinedList(t *testing.T) {
+	result := &types.QueryResult{
+		JSON:           `{"q":":concepts","searchMethod":"TENSOR"}`,
+		RequiredParams: []string{"concepts"},
+		Joined:         map[string]string{"concepts": ", "},
+	}
+
+	got, err := Bind(result, map[string]interface{}{"concepts": []string{"red shoes", "running"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"q":"red shoes, running","searchMethod":"TENSOR"}`; got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+
+	got, err = Bind(result, map[string]interface{}{"concepts": "boots"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"q":"boots","searchMethod":"TENSOR"}`; got != expected {
+		t.Errorf("expected a single string to bind as is, got %s", got)
+	}
+
+	if _, err := Bind(result, map[string]interface{}{"concepts": []int{1, 2}}); err == nil {
+		t.Error("expected error for a list of non-strings")
+	}
+}
+
+func TestBind_WeaviateGraphQLVariables(t *testing.T) {
+	topK := 5
+	result, err := weaviate.NewGraphQL().Render(&types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterCondition{
+			Field:    types.MetadataField{Name: "category", Type: "string"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "cat"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := Bind(result, map[string]interface{}{"query_vec": []float32{0.5, 0.25}, "cat": "shoes"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var body struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.Unmarshal([]byte(got), &body); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if !strings.Contains(body.Query, "vector: $query_vec") || !strings.Contains(body.Query, "valueText: $cat") {
+		t.Errorf("expected variable references to survive binding: %s", body.Query)
+	}
+	if body.Variables["cat"] != "shoes" {
+		t.Errorf("expected cat=shoes, got %v", body.Variables["cat"])
+	}
+	if vec, ok := body.Variables["query_vec"].([]interface{}); !ok || len(vec) != 2 {
+		t.Errorf("expected bound query_vec, got %v", body.Variables["query_vec"])
+	}
+}
+
+func TestBind_MissingParam(t *testing.T) {
+	result := &types.QueryResult{JSON: `{"vector":":v"}`, RequiredParams: []string{"v"}}
+
+	if _, err := Bind(result, nil); err == nil || !strings.Contains(err.Error(), "missing parameter: v") {
+		t.Errorf("expected missing parameter error, got %v", err)
+	}
+}
+
+func TestBind_ModelVector(t *testing.T) {
+	schema := testSchema()
+	schema.Collections["products"].Settings = map[string]string{
+		"description" + SettingModelSuffix:        "text-embedding-3-small",
+		"description" + SettingModelVersionSuffix: "1",
+	}
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	emb := v.E("products", "description")
+	if emb.Model.String() != "text-embedding-3-small@1" {
+		t.Fatalf("expected model from settings, got %q", emb.Model)
+	}
+
+	result, err := Search(v.C("products")).
+		Vector(Vec(v.P("query_vec"))).
+		Embedding(emb).
+		TopK(10).
+		Render(newStubRenderer())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result.JSON = `{"vector":":query_vec"}`
+	result.RequiredParams = []string{"query_vec"}
+
+	tests := []struct {
+		name    string
+		model   string
+		wantErr bool
+	}{
+		{"exact version", "text-embedding-3-small@1", false},
+		{"unversioned tag", "text-embedding-3-small", false},
+		{"other version", "text-embedding-3-small@2", true},
+		{"other model", "text-embedding-ada-002", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Bind(result, map[string]interface{}{
+				"query_vec": ModelVector{Model: tt.model, Values: []float32{1, 0}},
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected model mismatch error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != `{"vector":[1,0]}` {
+				t.Errorf("expected unwrapped vector, got %s", got)
+			}
+		})
+	}
+}
+
+func TestBind_ModelCheckHook(t *testing.T) {
+	result := &types.QueryResult{
+		JSON:           `{"vector":":v"}`,
+		RequiredParams: []string{"v"},
+		Vectors:        []types.VectorParam{{Param: "v"}},
+	}
+	rejected := errors.New("stale vector")
+
+	var seen string
+	_, err := Bind(result, map[string]interface{}{"v": []float32{1}}, WithModelCheck(func(vp VectorParam, _ interface{}) error {
+		seen = vp.Param
+		return rejected
+	}))
+	if !errors.Is(err, rejected) {
+		t.Errorf("expected hook error, got %v", err)
+	}
+	if seen != "v" {
+		t.Errorf("expected hook to see parameter v, got %q", seen)
+	}
+}
+
+func TestGetEmbeddingModel(t *testing.T) {
+	v, err := NewFromVDML(vdml.NewSchema("test").AddCollection(
+		vdml.NewCollection("docs").
+			WithSetting("content"+SettingModelSuffix, "bge-small").
+			AddEmbedding(vdml.NewEmbedding("content", 384)),
+	))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	model, err := v.GetEmbeddingModel("docs", "content")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if model.Name != "bge-small" || model.Version != "" {
+		t.Errorf("unexpected model: %#v", model)
+	}
+	if _, err := v.GetEmbeddingModel("docs", "missing"); err == nil {
+		t.Error("expected error for unknown embedding")
+	}
+}
+
+func TestBind_Normalization(t *testing.T) {
+	vectorResult := func(e types.EmbeddingField) *types.QueryResult {
+		return &types.QueryResult{
+			JSON:           `{"vector":":v"}`,
+			RequiredParams: []string{"v"},
+			Vectors:        []types.VectorParam{{Param: "v", Embedding: e}},
+		}
+	}
+	params := map[string]interface{}{"v": []float32{3, 4}}
+
+	tests := []struct {
+		name      string
+		embedding types.EmbeddingField
+		opts      []BindOption
+		expected  string
+	}{
+		{"cosine with option", types.EmbeddingField{Metric: types.Cosine}, []BindOption{WithNormalization()}, `{"vector":[0.6,0.8]}`},
+		{"cosine without option", types.EmbeddingField{Metric: types.Cosine}, nil, `{"vector":[3,4]}`},
+		{"euclidean with option", types.EmbeddingField{Metric: types.Euclidean}, []BindOption{WithNormalization()}, `{"vector":[3,4]}`},
+		{"never overrides option", types.EmbeddingField{Metric: types.DotProduct, Normalization: types.NormalizeNever}, []BindOption{WithNormalization()}, `{"vector":[3,4]}`},
+		{"always without option", types.EmbeddingField{Metric: types.Euclidean, Normalization: types.NormalizeAlways}, nil, `{"vector":[0.6,0.8]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Bind(vectorResult(tt.embedding), params, tt.opts...)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+
+	zero := map[string]interface{}{"v": []float64{0, 0}}
+	if _, err := Bind(vectorResult(types.EmbeddingField{Metric: types.Cosine}), zero, WithNormalization()); err == nil {
+		t.Error("expected error normalizing a zero vector")
+	}
+	if params["v"].([]float32)[0] != 3 {
+		t.Error("expected caller's vector to be left unmodified")
+	}
+}
+
+func TestBind_TargetEmbedding(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	products := v.C("products")
+
+	tests := []struct {
+		name    string
+		builder *Builder
+	}{
+		{"search without embedding", Search(products).Vector(Vec(v.P("v"))).TopK(10)},
+		{"upsert record", Upsert(products).AddVector(NewRecord(v.P("id"), Vec(v.P("v"))).Build())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.builder.Render(newStubRenderer())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(result.Vectors) != 1 || result.Vectors[0].Embedding.Name != "description" {
+				t.Fatalf("expected the only embedding to be targeted, got %+v", result.Vectors)
+			}
+			result.JSON = `{"vector":":v"}`
+			result.RequiredParams = []string{"v"}
+
+			got, err := Bind(result, map[string]interface{}{"v": []float32{3, 4}}, WithNormalization())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != `{"vector":[0.6,0.8]}` {
+				t.Errorf("expected a normalized vector, got %s", got)
+			}
+		})
+	}
+}
+
+func TestEmbeddingNormalizationSetting(t *testing.T) {
+	schema := testSchema()
+	schema.Collections["products"].Settings = map[string]string{"description" + SettingNormalizeSuffix: "false"}
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	emb := v.E("products", "description")
+	if emb.Metric != types.Cosine {
+		t.Errorf("expected cosine metric, got %s", emb.Metric)
+	}
+	if emb.Normalization != types.NormalizeNever {
+		t.Errorf("expected normalization disabled, got %q", emb.Normalization)
+	}
+}
@@ -0,0 +1,298 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned by a CircuitBreaker that rejects a request
+// without a fallback.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// BreakerState is the state of a CircuitBreaker.
+type BreakerState int
+
+// Circuit breaker states.
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+// String returns the state name.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Breaker defaults.
+const (
+	DefaultBreakerWindow       = 20
+	DefaultBreakerMinRequests  = 10
+	DefaultBreakerFailureRate  = 0.5
+	DefaultBreakerOpenDuration = 30 * time.Second
+)
+
+// Fallback handles a request the breaker rejected, e.g. by sending it to a
+// secondary provider or serving cached results. err is ErrCircuitOpen.
+type Fallback func(ctx context.Context, req *Request, err error) (*Response, error)
+
+// BreakerOption configures a CircuitBreaker.
+type BreakerOption func(*CircuitBreaker)
+
+// WithFailureRate opens the breaker when at least minRequests outcomes are
+// in the window and the fraction of failures reaches rate.
+func WithFailureRate(rate float64, minRequests int) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.failureRate = rate
+		b.minRequests = minRequests
+	}
+}
+
+// WithBreakerWindow sets how many recent outcomes the failure rate covers.
+func WithBreakerWindow(size int) BreakerOption {
+	return func(b *CircuitBreaker) {
+		if size > 0 {
+			b.window = make([]bool, size)
+		}
+	}
+}
+
+// WithSlowCallThreshold counts successful requests that take at least
+// threshold as failures.
+func WithSlowCallThreshold(threshold time.Duration) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.slowThreshold = threshold
+	}
+}
+
+// WithOpenDuration sets how long the breaker stays open before probing.
+func WithOpenDuration(d time.Duration) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.openDuration = d
+	}
+}
+
+// WithHalfOpenProbes sets how many consecutive successful probes close a
+// half-open breaker. Only that many probes run at once.
+func WithHalfOpenProbes(n int) BreakerOption {
+	return func(b *CircuitBreaker) {
+		if n > 0 {
+			b.probes = n
+		}
+	}
+}
+
+// WithFallback handles requests rejected while the breaker is open.
+func WithFallback(fallback Fallback) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.fallback = fallback
+	}
+}
+
+// WithStateChange registers a hook called on every state transition. It is
+// called with the breaker's lock held and must not call back into it.
+func WithStateChange(hook func(from, to BreakerState)) BreakerOption {
+	return func(b *CircuitBreaker) {
+		b.onChange = hook
+	}
+}
+
+// CircuitBreaker is an Executor that stops sending requests to a failing
+// provider. It opens when the failure rate over recent requests crosses a
+// threshold, rejects requests while open, and after the open duration lets
+// probe requests through to decide whether to close again. Requests
+// cancelled by the caller are not counted.
+type CircuitBreaker struct {
+	next Executor
+
+	failureRate   float64
+	minRequests   int
+	slowThreshold time.Duration
+	openDuration  time.Duration
+	probes        int
+	fallback      Fallback
+	onChange      func(from, to BreakerState)
+	now           func() time.Time
+
+	mu        sync.Mutex
+	state     BreakerState
+	window    []bool // true marks a failure
+	cursor    int
+	recorded  int
+	openedAt  time.Time
+	inFlight  int
+	successes int
+}
+
+// NewCircuitBreaker wraps next with a circuit breaker. It fails if the
+// failure rate is outside (0, 1], if the minimum number of requests is less
+// than one, or if the failure rate needs more outcomes than the window
+// holds, since the breaker could then never open.
+func NewCircuitBreaker(next Executor, opts ...BreakerOption) (*CircuitBreaker, error) {
+	b := &CircuitBreaker{
+		next:         next,
+		failureRate:  DefaultBreakerFailureRate,
+		minRequests:  DefaultBreakerMinRequests,
+		openDuration: DefaultBreakerOpenDuration,
+		probes:       1,
+		window:       make([]bool, DefaultBreakerWindow),
+		now:          time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.failureRate <= 0 || b.failureRate > 1 {
+		return nil, fmt.Errorf("failure rate must be in (0, 1]: %g", b.failureRate)
+	}
+	if b.minRequests < 1 {
+		return nil, fmt.Errorf("minimum requests must be at least 1: %d", b.minRequests)
+	}
+	if b.minRequests > len(b.window) {
+		return nil, fmt.Errorf("minimum requests %d exceed the breaker window of %d", b.minRequests, len(b.window))
+	}
+	return b, nil
+}
+
+// State returns the current state, moving an expired open breaker to
+// half-open.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expireOpen()
+	return b.state
+}
+
+// Execute sends req through the breaker.
+func (b *CircuitBreaker) Execute(ctx context.Context, req *Request) (*Response, error) {
+	probe, ok := b.admit()
+	if !ok {
+		if b.fallback != nil {
+			return b.fallback(ctx, req, ErrCircuitOpen)
+		}
+		return nil, ErrCircuitOpen
+	}
+
+	start := b.now()
+	resp, err := b.next.Execute(ctx, req)
+	elapsed := b.now().Sub(start)
+
+	if err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		b.release(probe)
+		return resp, err
+	}
+	failed := err != nil || (b.slowThreshold > 0 && elapsed >= b.slowThreshold)
+	b.record(probe, failed)
+	return resp, err
+}
+
+// admit decides whether a request may proceed and whether it is a probe.
+func (b *CircuitBreaker) admit() (probe, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.expireOpen()
+	switch b.state {
+	case BreakerClosed:
+		return false, true
+	case BreakerHalfOpen:
+		if b.inFlight+b.successes >= b.probes {
+			return false, false
+		}
+		b.inFlight++
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+func (b *CircuitBreaker) release(probe bool) {
+	if !probe {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight--
+}
+
+func (b *CircuitBreaker) record(probe, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.inFlight--
+		if b.state != BreakerHalfOpen {
+			return
+		}
+		if failed {
+			b.open()
+			return
+		}
+		b.successes++
+		if b.successes >= b.probes {
+			b.transition(BreakerClosed)
+			b.resetWindow()
+		}
+		return
+	}
+
+	if b.state != BreakerClosed {
+		return
+	}
+	b.window[b.cursor] = failed
+	b.cursor = (b.cursor + 1) % len(b.window)
+	if b.recorded < len(b.window) {
+		b.recorded++
+	}
+	if b.recorded < b.minRequests {
+		return
+	}
+	failures := 0
+	for i := 0; i < b.recorded; i++ {
+		if b.window[i] {
+			failures++
+		}
+	}
+	if float64(failures)/float64(b.recorded) >= b.failureRate {
+		b.open()
+	}
+}
+
+func (b *CircuitBreaker) expireOpen() {
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.openDuration {
+		b.transition(BreakerHalfOpen)
+		b.successes = 0
+	}
+}
+
+func (b *CircuitBreaker) open() {
+	b.transition(BreakerOpen)
+	b.openedAt = b.now()
+	b.resetWindow()
+}
+
+func (b *CircuitBreaker) resetWindow() {
+	for i := range b.window {
+		b.window[i] = false
+	}
+	b.cursor = 0
+	b.recorded = 0
+}
+
+func (b *CircuitBreaker) transition(to BreakerState) {
+	from := b.state
+	b.state = to
+	if b.onChange != nil && from != to {
+		b.onChange(from, to)
+	}
+}
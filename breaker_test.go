@@ -0,0 +1,175 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for breaker tests.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func failingExecutor(fail *bool) Executor {
+	return ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+		if *fail {
+			return nil, errors.New("unavailable")
+		}
+		return &Response{}, nil
+	})
+}
+
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	fail := true
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	var transitions []BreakerState
+	breaker, err := NewCircuitBreaker(failingExecutor(&fail),
+		WithFailureRate(0.5, 4),
+		WithBreakerWindow(4),
+		WithOpenDuration(time.Minute),
+		WithStateChange(func(_, to BreakerState) { transitions = append(transitions, to) }),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	breaker.now = clock.now
+	ctx := context.Background()
+	req := &Request{}
+
+	for i := 0; i < 4; i++ {
+		_, _ = breaker.Execute(ctx, req)
+	}
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("expected open breaker, got %s", breaker.State())
+	}
+	if _, err := breaker.Execute(ctx, req); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	if breaker.State() != BreakerHalfOpen {
+		t.Fatalf("expected half-open breaker, got %s", breaker.State())
+	}
+	fail = false
+	if _, err := breaker.Execute(ctx, req); err != nil {
+		t.Fatalf("unexpected probe error: %v", err)
+	}
+	if breaker.State() != BreakerClosed {
+		t.Errorf("expected closed breaker after successful probe, got %s", breaker.State())
+	}
+
+	expected := []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerClosed}
+	if len(transitions) != len(expected) {
+		t.Fatalf("expected transitions %v, got %v", expected, transitions)
+	}
+	for i := range expected {
+		if transitions[i] != expected[i] {
+			t.Errorf("transition %d: expected %s, got %s", i, expected[i], transitions[i])
+		}
+	}
+}
+
+func TestCircuitBreaker_FailedProbeReopens(t *testing.T) {
+	fail := true
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	breaker, err := NewCircuitBreaker(failingExecutor(&fail), WithFailureRate(1, 1), WithOpenDuration(time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	breaker.now = clock.now
+	ctx := context.Background()
+
+	_, _ = breaker.Execute(ctx, &Request{})
+	clock.t = clock.t.Add(time.Second)
+	_, _ = breaker.Execute(ctx, &Request{})
+	if breaker.State() != BreakerOpen {
+		t.Errorf("expected failed probe to reopen the breaker, got %s", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_SlowCalls(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	slow := ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+		clock.t = clock.t.Add(2 * time.Second)
+		return &Response{}, nil
+	})
+	breaker, err := NewCircuitBreaker(slow, WithFailureRate(1, 2), WithSlowCallThreshold(time.Second))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	breaker.now = clock.now
+
+	for i := 0; i < 2; i++ {
+		if _, err := breaker.Execute(context.Background(), &Request{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if breaker.State() != BreakerOpen {
+		t.Errorf("expected slow calls to open the breaker, got %s", breaker.State())
+	}
+}
+
+func TestCircuitBreaker_Fallback(t *testing.T) {
+	fail := true
+	cached := &Response{Matches: []Match{{ID: "cached"}}}
+	breaker, err := NewCircuitBreaker(failingExecutor(&fail),
+		WithFailureRate(1, 1),
+		WithFallback(func(_ context.Context, _ *Request, err error) (*Response, error) {
+			if !errors.Is(err, ErrCircuitOpen) {
+				t.Errorf("expected ErrCircuitOpen, got %v", err)
+			}
+			return cached, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, _ = breaker.Execute(context.Background(), &Request{})
+	resp, err := breaker.Execute(context.Background(), &Request{})
+	if err != nil || resp != cached {
+		t.Errorf("expected cached fallback response, got %v, %v", resp, err)
+	}
+}
+
+func TestNewCircuitBreaker_MinRequestsExceedWindow(t *testing.T) {
+	fail := true
+	if _, err := NewCircuitBreaker(failingExecutor(&fail), WithFailureRate(0.5, 5), WithBreakerWindow(4)); err == nil {
+		t.Error("expected error for minimum requests larger than the window")
+	}
+	if _, err := NewCircuitBreaker(failingExecutor(&fail), WithFailureRate(0.5, DefaultBreakerWindow+1)); err == nil {
+		t.Error("expected error for minimum requests larger than the default window")
+	}
+	if _, err := NewCircuitBreaker(failingExecutor(&fail), WithFailureRate(0.5, 4), WithBreakerWindow(4)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNewCircuitBreaker_InvalidFailureRate(t *testing.T) {
+	fail := true
+	tests := []struct {
+		name        string
+		rate        float64
+		minRequests int
+	}{
+		{"zero rate", 0, 5},
+		{"negative rate", -0.5, 5},
+		{"rate above one", 1.5, 5},
+		{"zero minimum requests", 0.5, 0},
+		{"negative minimum requests", 0.5, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCircuitBreaker(failingExecutor(&fail), WithFailureRate(tt.rate, tt.minRequests)); err == nil {
+				t.Errorf("expected error for rate %g and minimum requests %d", tt.rate, tt.minRequests)
+			}
+		})
+	}
+	if _, err := NewCircuitBreaker(failingExecutor(&fail), WithFailureRate(1, 1)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -2,15 +2,60 @@
 package vectql
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/zoobzio/vectql/internal/types"
 )
 
 // Builder provides a fluent API for constructing vector queries.
 type Builder struct {
-	ast *types.VectorAST
-	err error
+	ast        *types.VectorAST
+	err        error
+	postFilter int
+	ctx        context.Context
+	strict     bool
+
+	// safe is the accessor whose recorded errors fail Build; see
+	// WithAccessor.
+	safe *SafeAccessor
+
+	// stats and statsParams feed AdviseSearch for the warnings APIs; see
+	// WithStats.
+	stats       *CollectionStats
+	statsParams map[string]interface{}
+
+	// collect records every error instead of stopping at the first.
+	collect bool
+	errs    []error
+}
+
+// CollectErrors makes the builder record every error in the chain instead of
+// ignoring calls after the first one, so Build reports them all at once.
+// Calls that fail leave the query unchanged. Call it first in the chain.
+func (b *Builder) CollectErrors() *Builder {
+	if !b.collect && b.err != nil {
+		b.errs = append(b.errs, b.err)
+	}
+	b.collect = true
+	return b
+}
+
+// halted reports whether chained calls should be ignored.
+func (b *Builder) halted() bool {
+	return b.err != nil && !b.collect
+}
+
+// fail records an error from a chained call.
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+	if b.collect {
+		b.errs = append(b.errs, err)
+	}
 }
 
 // Search creates a new similarity search query builder.
@@ -56,6 +101,26 @@ func Fetch(c types.Collection) *Builder {
 	}
 }
 
+// List creates a paginated listing of a collection's records, size at a
+// time, in provider order. Chain Filter to list the matching records only,
+// and After to resume from the NextPage token of the previous response. The
+// listing renders as a FETCH for renderers that implement ListRenderer.
+//
+//	page := func(token vectql.PageToken) (*vectql.Request, error) {
+//	    return vectql.Prepare(vectql.List(products, 100).After(token), r, params)
+//	}
+func List(c types.Collection, size int) *Builder {
+	return &Builder{
+		ast: &types.VectorAST{
+			Operation:       types.OpFetch,
+			Target:          c,
+			IncludeMetadata: true,
+			IncludeVectors:  true,
+			Page:            &types.Page{Size: size},
+		},
+	}
+}
+
 // Update creates a new metadata update query builder.
 func Update(c types.Collection) *Builder {
 	return &Builder{
@@ -67,26 +132,102 @@ func Update(c types.Collection) *Builder {
 	}
 }
 
+// CheckExists creates an existence check builder. Chain IDs to check given
+// records, FetchBy to check keys, or Filter to check whether any record
+// matches. The check renders as a FETCH reading IDs only, or the key field
+// for a check by key, or as a read of
+// at most one record matching the filter for renderers that implement
+// ExistsRenderer. Run it with ExecuteExists.
+func CheckExists(c types.Collection) *Builder {
+	return &Builder{
+		ast: &types.VectorAST{
+			Operation: types.OpFetch,
+			Target:    c,
+			Exists:    true,
+		},
+	}
+}
+
 // Vector sets the query vector for similarity search.
 func (b *Builder) Vector(v types.VectorValue) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	if b.ast.Operation != types.OpSearch {
-		b.err = fmt.Errorf("Vector() can only be used with SEARCH")
+		b.fail(fmt.Errorf("Vector() can only be used with SEARCH"))
 		return b
 	}
 	b.ast.QueryVector = &v
 	return b
 }
 
+// NearText searches with text the provider vectorizes, such as Weaviate's
+// text2vec modules. Concepts holds the list of query strings. Renderers
+// without text vectorization reject the query.
+func (b *Builder) NearText(concepts types.Param) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.Operation != types.OpSearch {
+		b.fail(fmt.Errorf("NearText() can only be used with SEARCH"))
+		return b
+	}
+	b.ast.NearText = &types.NearText{Concepts: concepts}
+	return b
+}
+
+// NearImage searches with a base64-encoded image the provider vectorizes,
+// such as Weaviate's img2vec and multi2vec modules. Renderers without image
+// vectorization reject the query.
+func (b *Builder) NearImage(image types.Param) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.Operation != types.OpSearch {
+		b.fail(fmt.Errorf("NearImage() can only be used with SEARCH"))
+		return b
+	}
+	b.ast.NearImage = &types.NearImage{Image: image}
+	return b
+}
+
+// Text adds a keyword (BM25) query over the given properties. Without a query
+// vector the search is a keyword search; with one, a hybrid search. Renderers
+// without keyword search reject the query.
+func (b *Builder) Text(query types.Param, properties ...types.TextProperty) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.Operation != types.OpSearch {
+		b.fail(fmt.Errorf("Text() can only be used with SEARCH"))
+		return b
+	}
+	b.ast.TextQuery = &types.TextQuery{Query: query, Properties: properties}
+	return b
+}
+
+// TextLanguage declares the language of the text query as an ISO 639-1
+// code. Build fails when a searched property is analyzed in another
+// language.
+func (b *Builder) TextLanguage(lang string) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.TextQuery == nil {
+		b.fail(fmt.Errorf("TextLanguage() requires Text()"))
+		return b
+	}
+	b.ast.TextQuery.Language = lang
+	return b
+}
+
 // Embedding specifies which embedding field to search against.
 func (b *Builder) Embedding(e types.EmbeddingField) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	if b.ast.Operation != types.OpSearch {
-		b.err = fmt.Errorf("Embedding() can only be used with SEARCH")
+		b.fail(fmt.Errorf("Embedding() can only be used with SEARCH"))
 		return b
 	}
 	b.ast.QueryEmbedding = &e
@@ -95,19 +236,19 @@ func (b *Builder) Embedding(e types.EmbeddingField) *Builder {
 
 // TopK sets the number of results to return.
 func (b *Builder) TopK(k int) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	if b.ast.Operation != types.OpSearch {
-		b.err = fmt.Errorf("TopK() can only be used with SEARCH")
+		b.fail(fmt.Errorf("TopK() can only be used with SEARCH"))
 		return b
 	}
 	if k > types.MaxTopK {
-		b.err = fmt.Errorf("topK exceeds maximum: %d > %d", k, types.MaxTopK)
+		b.fail(fmt.Errorf("topK exceeds maximum: %d > %d", k, types.MaxTopK))
 		return b
 	}
 	if k <= 0 {
-		b.err = fmt.Errorf("topK must be positive: %d", k)
+		b.fail(fmt.Errorf("topK must be positive: %d", k))
 		return b
 	}
 	b.ast.TopK = &types.PaginationValue{Static: &k}
@@ -116,11 +257,11 @@ func (b *Builder) TopK(k int) *Builder {
 
 // TopKParam sets topK from a parameter.
 func (b *Builder) TopKParam(p types.Param) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	if b.ast.Operation != types.OpSearch {
-		b.err = fmt.Errorf("TopKParam() can only be used with SEARCH")
+		b.fail(fmt.Errorf("TopKParam() can only be used with SEARCH"))
 		return b
 	}
 	b.ast.TopK = &types.PaginationValue{Param: &p}
@@ -129,20 +270,101 @@ func (b *Builder) TopKParam(p types.Param) *Builder {
 
 // MinScore sets a minimum similarity threshold.
 func (b *Builder) MinScore(p types.Param) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	if b.ast.Operation != types.OpSearch {
-		b.err = fmt.Errorf("MinScore() can only be used with SEARCH")
+		b.fail(fmt.Errorf("MinScore() can only be used with SEARCH"))
 		return b
 	}
 	b.ast.MinScore = &p
 	return b
 }
 
+// Rescore searches the quantized index and re-ranks candidates with the
+// original vectors, fetching oversampling x TopK candidates first. An
+// oversampling of zero uses the provider default.
+func (b *Builder) Rescore(oversampling float64) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.Operation != types.OpSearch {
+		b.fail(fmt.Errorf("Rescore() can only be used with SEARCH"))
+		return b
+	}
+	if b.ast.Quantization == nil {
+		b.ast.Quantization = &types.QuantizationParams{}
+	}
+	b.ast.Quantization.Rescore = true
+	b.ast.Quantization.Oversampling = oversampling
+	return b
+}
+
+// IgnoreQuantization searches the original vectors rather than the quantized index.
+func (b *Builder) IgnoreQuantization() *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.Operation != types.OpSearch {
+		b.fail(fmt.Errorf("IgnoreQuantization() can only be used with SEARCH"))
+		return b
+	}
+	if b.ast.Quantization == nil {
+		b.ast.Quantization = &types.QuantizationParams{}
+	}
+	b.ast.Quantization.Ignore = true
+	return b
+}
+
+// Freshness sets the consistency a SEARCH or FETCH reads at. Renderers map
+// it to the provider's consistency setting; providers without one ignore it,
+// which RenderWithWarnings reports.
+func (b *Builder) Freshness(f types.Freshness) *Builder {
+	if b.halted() {
+		return b
+	}
+	if AccessOf(b.ast.Operation) != AccessRead {
+		b.fail(fmt.Errorf("Freshness() can only be used with SEARCH or FETCH"))
+		return b
+	}
+	switch f.Level {
+	case types.FreshnessStrong, types.FreshnessEventual:
+	case types.FreshnessBounded:
+		if f.MaxStaleness <= 0 {
+			b.fail(fmt.Errorf("bounded freshness requires a positive staleness"))
+			return b
+		}
+	default:
+		b.fail(fmt.Errorf("unknown freshness level: %q", f.Level))
+		return b
+	}
+	b.ast.Freshness = &f
+	return b
+}
+
+// Snapshot pins a SEARCH or FETCH to the point-in-time view the provider
+// handle in id refers to, extending its lifetime by keepAlive. Rendering
+// fails with ErrSnapshotUnsupported for providers without point-in-time
+// reads.
+func (b *Builder) Snapshot(id types.Param, keepAlive time.Duration) *Builder {
+	if b.halted() {
+		return b
+	}
+	if AccessOf(b.ast.Operation) != AccessRead {
+		b.fail(fmt.Errorf("Snapshot() can only be used with SEARCH or FETCH"))
+		return b
+	}
+	if keepAlive < 0 {
+		b.fail(fmt.Errorf("snapshot keep-alive cannot be negative: %s", keepAlive))
+		return b
+	}
+	b.ast.Snapshot = &types.Snapshot{ID: id, KeepAlive: keepAlive}
+	return b
+}
+
 // IncludeVectors specifies whether to return vectors in results.
 func (b *Builder) IncludeVectors(include bool) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	b.ast.IncludeVectors = include
@@ -151,7 +373,7 @@ func (b *Builder) IncludeVectors(include bool) *Builder {
 
 // IncludeMetadata specifies whether to return metadata in results.
 func (b *Builder) IncludeMetadata(include bool) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	b.ast.IncludeMetadata = include
@@ -160,7 +382,7 @@ func (b *Builder) IncludeMetadata(include bool) *Builder {
 
 // Filter sets or adds filter conditions.
 func (b *Builder) Filter(f types.FilterItem) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	if b.ast.FilterClause == nil {
@@ -174,6 +396,63 @@ func (b *Builder) Filter(f types.FilterItem) *Builder {
 	return b
 }
 
+// Boost adds weight to the score of search results matching f without
+// excluding the rest. Renderers without scoring boosts ignore it and report
+// it in QueryResult.Ignored.
+func (b *Builder) Boost(f types.FilterItem, weight float64) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.Operation != types.OpSearch {
+		b.fail(fmt.Errorf("Boost() can only be used with SEARCH"))
+		return b
+	}
+	b.ast.Boosts = append(b.ast.Boosts, types.Boost{Filter: f, Weight: weight})
+	return b
+}
+
+// BoostField adds a numeric field's value, multiplied by weight, to the
+// score of search results, such as a popularity or margin field.
+func (b *Builder) BoostField(field types.MetadataField, weight float64) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.Operation != types.OpSearch {
+		b.fail(fmt.Errorf("BoostField() can only be used with SEARCH"))
+		return b
+	}
+	b.ast.Boosts = append(b.ast.Boosts, types.Boost{Field: &field, Weight: weight})
+	return b
+}
+
+// Score attaches a provider-specific scoring expression to a search. Only
+// the renderer for the expression's provider accepts the query.
+func (b *Builder) Score(expr types.ScoreExpression) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.Operation != types.OpSearch {
+		b.fail(fmt.Errorf("Score() can only be used with SEARCH"))
+		return b
+	}
+	b.ast.Scoring = &expr
+	return b
+}
+
+// Transform normalizes the value bound to p, applying transforms in order.
+// Transforms are stored in the query, so every caller's values are
+// normalized the same way.
+func (b *Builder) Transform(p types.Param, transforms ...types.BindTransform) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.BindTransforms == nil {
+		b.ast.BindTransforms = make(map[string][]types.BindTransform)
+	}
+	b.ast.BindTransforms[p.Name] = append(b.ast.BindTransforms[p.Name], transforms...)
+	return b
+}
+
 // Where is an alias for Filter.
 func (b *Builder) Where(f types.FilterItem) *Builder {
 	return b.Filter(f)
@@ -181,7 +460,7 @@ func (b *Builder) Where(f types.FilterItem) *Builder {
 
 // SelectMetadata specifies which metadata fields to return.
 func (b *Builder) SelectMetadata(fields ...types.MetadataField) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	b.ast.MetadataFields = fields
@@ -190,7 +469,7 @@ func (b *Builder) SelectMetadata(fields ...types.MetadataField) *Builder {
 
 // Namespace sets the namespace/partition for the query.
 func (b *Builder) Namespace(ns types.Param) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	b.ast.Namespace = &ns
@@ -199,15 +478,15 @@ func (b *Builder) Namespace(ns types.Param) *Builder {
 
 // AddVector adds a vector record for upsert.
 func (b *Builder) AddVector(record types.VectorRecord) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	if b.ast.Operation != types.OpUpsert {
-		b.err = fmt.Errorf("AddVector() can only be used with UPSERT")
+		b.fail(fmt.Errorf("AddVector() can only be used with UPSERT"))
 		return b
 	}
 	if len(b.ast.Vectors) >= types.MaxBatchSize {
-		b.err = fmt.Errorf("batch size exceeds maximum: %d", types.MaxBatchSize)
+		b.fail(fmt.Errorf("batch size exceeds maximum: %d", types.MaxBatchSize))
 		return b
 	}
 	b.ast.Vectors = append(b.ast.Vectors, record)
@@ -216,15 +495,15 @@ func (b *Builder) AddVector(record types.VectorRecord) *Builder {
 
 // Vectors sets multiple vector records for batch upsert.
 func (b *Builder) Vectors(records []types.VectorRecord) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	if b.ast.Operation != types.OpUpsert {
-		b.err = fmt.Errorf("Vectors() can only be used with UPSERT")
+		b.fail(fmt.Errorf("Vectors() can only be used with UPSERT"))
 		return b
 	}
 	if len(records) > types.MaxBatchSize {
-		b.err = fmt.Errorf("batch size exceeds maximum: %d > %d", len(records), types.MaxBatchSize)
+		b.fail(fmt.Errorf("batch size exceeds maximum: %d > %d", len(records), types.MaxBatchSize))
 		return b
 	}
 	b.ast.Vectors = records
@@ -233,11 +512,11 @@ func (b *Builder) Vectors(records []types.VectorRecord) *Builder {
 
 // Set adds a metadata field update.
 func (b *Builder) Set(field types.MetadataField, value types.Param) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	if b.ast.Operation != types.OpUpdate {
-		b.err = fmt.Errorf("Set() can only be used with UPDATE")
+		b.fail(fmt.Errorf("Set() can only be used with UPDATE"))
 		return b
 	}
 	if b.ast.Updates == nil {
@@ -249,36 +528,133 @@ func (b *Builder) Set(field types.MetadataField, value types.Param) *Builder {
 
 // IDs specifies vector IDs for fetch, delete, or update operations.
 func (b *Builder) IDs(ids ...types.Param) *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	if b.ast.Operation != types.OpDelete && b.ast.Operation != types.OpFetch && b.ast.Operation != types.OpUpdate {
-		b.err = fmt.Errorf("IDs() can only be used with DELETE, FETCH, or UPDATE")
+		b.fail(fmt.Errorf("IDs() can only be used with DELETE, FETCH, or UPDATE"))
 		return b
 	}
 	if len(ids) > types.MaxIDsPerFetch {
-		b.err = fmt.Errorf("too many IDs: %d > %d", len(ids), types.MaxIDsPerFetch)
+		b.fail(fmt.Errorf("too many IDs: %d > %d", len(ids), types.MaxIDsPerFetch))
 		return b
 	}
 	b.ast.IDs = ids
 	return b
 }
 
+// FetchBy fetches the records whose unique field equals one of keys, so
+// callers can address records by a business key instead of storing provider
+// IDs. The field must be declared unique; see SettingUniqueSuffix. The query
+// renders as a filtered read returning at most one record per key, for
+// renderers that implement FetchByRenderer. In an existence check the query
+// reads the key field, so ExecuteExists can report each key.
+func (b *Builder) FetchBy(field types.MetadataField, keys ...types.Param) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.Operation != types.OpFetch {
+		b.fail(fmt.Errorf("FetchBy() can only be used with FETCH"))
+		return b
+	}
+	if !field.Unique {
+		b.fail(fmt.Errorf("FetchBy() requires a unique field: '%s' is not declared unique", field.Name))
+		return b
+	}
+	if len(keys) > types.MaxIDsPerFetch {
+		b.fail(fmt.Errorf("too many keys: %d > %d", len(keys), types.MaxIDsPerFetch))
+		return b
+	}
+	b.ast.FetchKey = &types.FetchKey{Field: field, Keys: keys}
+	if b.ast.Exists {
+		b.ast.IncludeMetadata = true
+		b.ast.MetadataFields = []types.MetadataField{field}
+	}
+	return b
+}
+
+// After resumes a listing from token, the NextPage token of the previous
+// response. The zero token reads the first page. Rendering fails for a
+// token issued by another provider.
+func (b *Builder) After(token types.PageToken) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.Page == nil {
+		b.fail(fmt.Errorf("After() can only be used with List"))
+		return b
+	}
+	page := *b.ast.Page
+	page.After = token
+	b.ast.Page = &page
+	return b
+}
+
 // DeleteAll enables deletion of all vectors matching the filter.
 func (b *Builder) DeleteAll() *Builder {
-	if b.err != nil {
+	if b.halted() {
 		return b
 	}
 	if b.ast.Operation != types.OpDelete {
-		b.err = fmt.Errorf("DeleteAll() can only be used with DELETE")
+		b.fail(fmt.Errorf("DeleteAll() can only be used with DELETE"))
 		return b
 	}
 	b.ast.DeleteAll = true
 	return b
 }
 
-// Build returns the constructed AST or an error.
+// PostFilter enables client-side evaluation of filter predicates the renderer
+// cannot express. The rendered query over-fetches by multiplier times TopK and
+// carries the residual predicates for ApplyPostFilter. A multiplier of zero
+// uses DefaultPostFilterMultiplier.
+func (b *Builder) PostFilter(multiplier int) *Builder {
+	if b.halted() {
+		return b
+	}
+	if b.ast.Operation != types.OpSearch {
+		b.fail(fmt.Errorf("PostFilter() can only be used with SEARCH"))
+		return b
+	}
+	if multiplier < 0 {
+		b.fail(fmt.Errorf("post-filter multiplier must not be negative: %d", multiplier))
+		return b
+	}
+	if multiplier == 0 {
+		multiplier = DefaultPostFilterMultiplier
+	}
+	b.postFilter = multiplier
+	return b
+}
+
+// Rewrite returns a copy of b whose AST is rewrite applied to a shallow copy
+// of b's AST. rewrite may replace fields but must not modify the slices and
+// maps it shares with b. The result is validated again at Build.
+func (b *Builder) Rewrite(rewrite func(ast *types.VectorAST)) *Builder {
+	ast := *b.ast
+	rewrite(&ast)
+	return b.withAST(&ast)
+}
+
+// Build returns the constructed AST or an error. With CollectErrors, the
+// error joins every chained error and the first validation failure.
 func (b *Builder) Build() (*types.VectorAST, error) {
+	if b.safe != nil {
+		// Accessor errors come first: the zero values they left in the
+		// query cause the errors that follow
+		if err := b.safe.Err(); err != nil {
+			return nil, err
+		}
+	}
+	if b.collect {
+		errs := append([]error(nil), b.errs...)
+		if err := b.ast.Validate(); err != nil {
+			errs = append(errs, err)
+		}
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		return b.ast, nil
+	}
 	if b.err != nil {
 		return nil, b.err
 	}
@@ -298,12 +674,104 @@ func (b *Builder) MustBuild() *types.VectorAST {
 }
 
 // Render builds the AST and renders it using the provided renderer.
+// Case-insensitive comparisons the renderer cannot express natively are
+// rewritten to compare lowercased shadow fields; see EqFold. A degradation
+// profile attached to the builder's context rewrites searches first; see
+// ContextWithDegradation. Metadata fields are renamed to the physical names
+// configured on renderers that implement FieldNamer.
 func (b *Builder) Render(renderer Renderer) (*types.QueryResult, error) {
+	result, _, err := b.render(renderer)
+	return result, err
+}
+
+// rewrittenAST is a built query after the rewrites Render applies for a
+// renderer.
+type rewrittenAST struct {
+	// logical is degraded and case-folded, with logical field names.
+	logical *types.VectorAST
+
+	// physical is logical with the renderer's physical field names.
+	physical *types.VectorAST
+
+	// renderer renders the query: the given renderer, or the renderer a
+	// Router selected for it.
+	renderer Renderer
+
+	// derived maps the parameters case folding added to the parameters
+	// they copy.
+	derived map[string]string
+
+	degradation string
+}
+
+// rewrite builds the AST, checks that renderer can render it, and applies
+// the degradation, case folding and field renaming rewrites.
+func (b *Builder) rewrite(renderer Renderer) (*rewrittenAST, error) {
 	ast, err := b.Build()
 	if err != nil {
 		return nil, err
 	}
-	return renderer.Render(ast)
+	renderer, err = selectRenderer(renderer, ast)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFetchBy(ast, renderer); err != nil {
+		return nil, err
+	}
+	if err := checkExists(ast, renderer); err != nil {
+		return nil, err
+	}
+	if err := checkList(ast, renderer); err != nil {
+		return nil, err
+	}
+	ast, degradation := degrade(b.Context(), ast)
+	ast, derived := foldCase(ast, renderer)
+	return &rewrittenAST{
+		logical:     ast,
+		physical:    renameFields(ast, renderer),
+		renderer:    renderer,
+		derived:     derived,
+		degradation: degradation,
+	}, nil
+}
+
+// render renders the query and also returns the rewritten AST the result
+// was rendered from.
+func (b *Builder) render(renderer Renderer) (*types.QueryResult, *rewrittenAST, error) {
+	rw, err := b.rewrite(renderer)
+	if err != nil {
+		return nil, nil, err
+	}
+	var result *types.QueryResult
+	if b.postFilter > 0 {
+		result, err = renderWithPostFilter(b.Context(), rw.physical, rw.renderer, b.postFilter)
+	} else {
+		result, err = renderContext(b.Context(), rw.renderer, rw.physical)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if b.strict {
+		for _, f := range result.Features {
+			if f.Stability == types.StabilityExperimental {
+				return nil, nil, fmt.Errorf("%w: %s: %s", ErrExperimentalFeature, f.Feature, f.Message)
+			}
+		}
+	}
+	ast := rw.logical
+	result.Vectors = vectorParams(ast)
+	result.Fields = fieldParams(ast)
+	result.ParamSpecs = derivedSpecs(ast.ParamSpecs(), rw.derived)
+	result.Transforms = ast.BindTransforms
+	if len(rw.derived) > 0 {
+		result.Derived = rw.derived
+		result.RequiredParams = requireSources(result.RequiredParams, rw.derived)
+	}
+	for i := range result.Commands {
+		describeArgs(result.Commands[i].Args, ast, rw.derived)
+	}
+	result.Degradation = rw.degradation
+	return result, rw, nil
 }
 
 // MustRender renders the query or panics on error.
@@ -1,9 +1,12 @@
 package vectql
 
 import (
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
 )
 
 func TestSearch(t *testing.T) {
@@ -126,6 +129,266 @@ func TestSearch_RequiresVector(t *testing.T) {
 	}
 }
 
+func TestSearch_NearText(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+
+	ast, err := Search(coll).
+		NearText(types.Param{Name: "concepts"}).
+		TopK(10).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ast.NearText == nil || ast.NearText.Concepts.Name != "concepts" {
+		t.Errorf("expected NearText concepts, got %#v", ast.NearText)
+	}
+	if ast.Modality() != types.ModalityText {
+		t.Errorf("expected text modality, got %s", ast.Modality())
+	}
+}
+
+func TestSearch_Text(t *testing.T) {
+	coll := types.Collection{Name: "articles"}
+	title := types.TextProperty{Field: types.MetadataField{Name: "title"}, Boost: 2}
+
+	ast, err := Search(coll).
+		Text(types.Param{Name: "q"}, title).
+		TopK(10).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ast.TextQuery == nil || len(ast.TextQuery.Properties) != 1 {
+		t.Fatalf("expected a text query with one property, got %#v", ast.TextQuery)
+	}
+	if ast.Modality() != types.ModalityKeyword {
+		t.Errorf("expected keyword modality, got %s", ast.Modality())
+	}
+
+	ast, err = Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		Text(types.Param{Name: "q"}).
+		TopK(10).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ast.Modality() != types.ModalityHybrid {
+		t.Errorf("expected hybrid modality, got %s", ast.Modality())
+	}
+
+	_, err = Search(coll).
+		NearText(types.Param{Name: "concepts"}).
+		Text(types.Param{Name: "q"}).
+		TopK(10).
+		Build()
+	if err == nil {
+		t.Error("expected error for a text query with NearText")
+	}
+
+	title.Boost = -1
+	_, err = Search(coll).
+		Text(types.Param{Name: "q"}, title).
+		TopK(10).
+		Build()
+	if err == nil {
+		t.Error("expected error for a negative boost")
+	}
+
+	_, err = Fetch(coll).Text(types.Param{Name: "q"}).Build()
+	if err == nil {
+		t.Error("expected error for Text on FETCH")
+	}
+}
+
+func TestSearch_TextLanguage(t *testing.T) {
+	coll := types.Collection{Name: "articles"}
+	titleDE := types.TextProperty{Field: types.MetadataField{Name: "title_de", Language: "de"}}
+	body := types.TextProperty{Field: types.MetadataField{Name: "body"}}
+
+	if _, err := Search(coll).Text(types.Param{Name: "q"}, titleDE, body).TextLanguage("de").TopK(10).Build(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := Search(coll).Text(types.Param{Name: "q"}, titleDE).TextLanguage("en").TopK(10).Build(); err == nil {
+		t.Error("expected error for a property in another language")
+	}
+	if _, err := Search(coll).Text(types.Param{Name: "q"}).TextLanguage("xx").TopK(10).Build(); err == nil {
+		t.Error("expected error for an unknown language")
+	}
+	if _, err := Search(coll).Vector(Vec(types.Param{Name: "v"})).TextLanguage("de").TopK(10).Build(); err == nil {
+		t.Error("expected error for TextLanguage without Text")
+	}
+}
+
+func TestSearch_Boost(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+	inStock := types.FilterCondition{Field: types.MetadataField{Name: "in_stock"}, Operator: types.EQ, Value: types.Param{Name: "in_stock"}}
+
+	ast, err := Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		Boost(inStock, 2).
+		BoostField(types.MetadataField{Name: "popularity", Type: "float"}, 0.5).
+		TopK(10).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ast.Boosts) != 2 || ast.Boosts[0].Filter == nil || ast.Boosts[1].Field == nil {
+		t.Fatalf("expected a filter and a field boost, got %#v", ast.Boosts)
+	}
+	if specs := ast.ParamSpecs(); len(specs) != 2 || specs[1].Name != "in_stock" {
+		t.Errorf("expected the boost filter parameter, got %v", specs)
+	}
+
+	_, err = Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		Boost(inStock, 0).
+		TopK(10).
+		Build()
+	if err == nil {
+		t.Error("expected error for a zero weight")
+	}
+
+	_, err = Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		BoostField(types.MetadataField{Name: "title", Type: "string"}, 1).
+		TopK(10).
+		Build()
+	if err == nil {
+		t.Error("expected error for a non-numeric field boost")
+	}
+
+	_, err = Fetch(coll).Boost(inStock, 1).Build()
+	if err == nil {
+		t.Error("expected error for Boost on FETCH")
+	}
+}
+
+func TestSearch_Score(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+	expr := Scoring("elasticsearch", "script_score", "_score * params.factor", types.Param{Name: "factor"})
+
+	ast, err := Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		Score(expr).
+		TopK(10).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ast.Scoring == nil || ast.Scoring.Kind != "script_score" {
+		t.Fatalf("expected a scoring expression, got %#v", ast.Scoring)
+	}
+	specs := ast.ParamSpecs()
+	if len(specs) != 2 || specs[1].Name != "factor" || specs[1].Source != SourceScoring {
+		t.Errorf("expected the scoring parameter, got %v", specs)
+	}
+
+	_, err = Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		Score(Scoring("elasticsearch", "", "_score")).
+		TopK(10).
+		Build()
+	if err == nil {
+		t.Error("expected error for a scoring expression without a kind")
+	}
+
+	_, err = Fetch(coll).Score(expr).Build()
+	if err == nil {
+		t.Error("expected error for Score on FETCH")
+	}
+}
+
+func TestSearch_SingleSearchInput(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+
+	_, err := Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		NearImage(types.Param{Name: "image"}).
+		TopK(10).
+		Build()
+
+	if err == nil {
+		t.Fatal("expected error for both vector and NearImage")
+	}
+}
+
+func TestSearch_Quantization(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+
+	ast, err := Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		Rescore(2).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q := ast.Quantization; q == nil || !q.Rescore || q.Oversampling != 2 {
+		t.Errorf("expected rescore with oversampling 2, got %#v", ast.Quantization)
+	}
+
+	_, err = Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		Rescore(0).
+		IgnoreQuantization().
+		Build()
+	if err == nil {
+		t.Error("expected error for rescoring an ignored quantization")
+	}
+
+	_, err = Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		Rescore(0.5).
+		Build()
+	if err == nil {
+		t.Error("expected error for oversampling below 1")
+	}
+}
+
+func TestSearch_RequiresPartitionKeyFilter(t *testing.T) {
+	coll := types.Collection{Name: "events", PartitionKey: "tenant", PartitionKeyRequired: true}
+	tenant := types.MetadataField{Name: "tenant"}
+	kind := types.MetadataField{Name: "kind"}
+
+	tests := []struct {
+		name    string
+		filter  types.FilterItem
+		wantErr bool
+	}{
+		{"no filter", nil, true},
+		{"equality", Eq(tenant, types.Param{Name: "t"}), false},
+		{"in", In(tenant, types.Param{Name: "ts"}), false},
+		{"and with key", And(Eq(kind, types.Param{Name: "k"}), Eq(tenant, types.Param{Name: "t"})), false},
+		{"or with key", Or(Eq(kind, types.Param{Name: "k"}), Eq(tenant, types.Param{Name: "t"})), true},
+		{"inequality", Ne(tenant, types.Param{Name: "t"}), true},
+		{"other field", Eq(kind, types.Param{Name: "k"}), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(10)
+			if tt.filter != nil {
+				b = b.Filter(tt.filter)
+			}
+			_, err := b.Build()
+			if tt.wantErr && err == nil {
+				t.Error("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	coll.PartitionKeyRequired = false
+	if _, err := Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(10).Build(); err != nil {
+		t.Errorf("expected the partition key filter to be optional, got %v", err)
+	}
+}
+
 func TestSearch_RequiresTopK(t *testing.T) {
 	coll := types.Collection{Name: "products"}
 
@@ -367,3 +630,176 @@ func TestIncludeOptions(t *testing.T) {
 		t.Error("expected IncludeMetadata to be false")
 	}
 }
+
+func TestParamScope(t *testing.T) {
+	tenant := types.Param{Name: "tenant"}
+	scoped := tenant.WithScope("policy")
+	if scoped.Name != "policy_tenant" || scoped.Scope != "policy" {
+		t.Errorf("expected policy_tenant in scope policy, got %+v", scoped)
+	}
+	nested := scoped.WithScope("acl")
+	if nested.Name != "acl_policy_tenant" || nested.Scope != "acl.policy" {
+		t.Errorf("expected acl_policy_tenant in scope acl.policy, got %+v", nested)
+	}
+
+	field := types.MetadataField{Name: "tenant_id", Collection: "products"}
+	_, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(10).
+		Filter(And(Eq(field, tenant), Eq(field, scoped))).
+		Build()
+	if err != nil {
+		t.Fatalf("expected distinct scoped params to build, got %v", err)
+	}
+}
+
+func TestParamScope_Collision(t *testing.T) {
+	field := types.MetadataField{Name: "tenant_id", Collection: "products"}
+	_, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(10).
+		Namespace(types.Param{Name: "policy_tenant"}).
+		Filter(Eq(field, types.Param{Name: "tenant"}.WithScope("policy"))).
+		Build()
+	if err == nil {
+		t.Fatal("expected collision error")
+	}
+	want := "parameter 'policy_tenant' defined twice: unscoped at namespace and scope 'policy' at filter on 'tenant_id'"
+	if err.Error() != want {
+		t.Errorf("expected %q, got %q", want, err.Error())
+	}
+}
+
+func TestParamScope_InvalidScope(t *testing.T) {
+	_, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"}.WithScope("bad scope"))).
+		TopK(10).
+		Build()
+	if err == nil {
+		t.Fatal("expected invalid scope error")
+	}
+}
+
+func TestRender_ParamSpec(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := Search(v.C("products")).
+		Vector(Vec(v.P("query_vec"))).
+		Embedding(v.E("products", "description")).
+		TopKParam(v.P("limit")).
+		Filter(v.And(
+			v.In(v.M("products", "category"), v.P("categories")),
+			v.Range(v.M("products", "price"), ptrParam(v.P("price")), nil),
+			v.Ne(v.M("products", "location"), v.P("location")),
+			v.Gt(v.M("products", "price"), v.P("price")),
+		)).
+		Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []types.ParamSpec{
+		{Name: "query_vec", Type: types.ParamVector, Dimensions: 384, Source: types.SourceQueryVector, Sources: []types.ParamSource{types.SourceQueryVector}},
+		{Name: "limit", Type: types.ParamInteger, Source: types.SourceTopK, Sources: []types.ParamSource{types.SourceTopK}},
+		{Name: "categories", Type: types.ParamList, Items: types.ParamString, Source: types.SourceFilter, Field: "category", Sources: []types.ParamSource{types.SourceFilter}},
+		{Name: "price", Type: types.ParamNumber, Source: types.SourceFilter, Field: "price", Repeats: true, Sources: []types.ParamSource{types.SourceFilter}},
+		{Name: "location", Type: types.ParamString, Source: types.SourceFilter, Field: "location", Sources: []types.ParamSource{types.SourceFilter}},
+	}
+	if got := result.ParamSpec(); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected param spec:\n got  %+v\n want %+v", got, want)
+	}
+}
+
+func TestRender_ParamSpecTargetEmbedding(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	products := v.C("products")
+
+	search, err := Search(products).Vector(Vec(v.P("v"))).TopK(10).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if specs := search.ParamSpecs(); len(specs) != 1 || specs[0].Dimensions != 384 {
+		t.Errorf("expected 384 dimensions from the only embedding, got %+v", specs)
+	}
+
+	upsert, err := Upsert(products).AddVector(NewRecord(v.P("id"), Vec(v.P("v"))).Build()).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, spec := range upsert.ParamSpecs() {
+		if spec.Name == "v" && spec.Dimensions != 384 {
+			t.Errorf("expected 384 dimensions for the record vector, got %+v", spec)
+		}
+	}
+}
+
+func TestQueryResult_ParamSpecWithoutBuilder(t *testing.T) {
+	result := &types.QueryResult{RequiredParams: []string{"a", "b", "a"}}
+	want := []types.ParamSpec{{Name: "a"}, {Name: "b"}}
+	if got := result.ParamSpec(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
+
+func ptrParam(p types.Param) *types.Param {
+	return &p
+}
+
+func TestCollectErrors(t *testing.T) {
+	_, err := Upsert(types.Collection{Name: "products"}).
+		CollectErrors().
+		TopK(0).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		DeleteAll().
+		Build()
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		"TopK() can only be used with SEARCH",
+		"Vector() can only be used with SEARCH",
+		"DeleteAll() can only be used with DELETE",
+		"UPSERT requires at least one vector",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
+
+func TestCollectErrors_StopsAtFirstByDefault(t *testing.T) {
+	_, err := Upsert(types.Collection{Name: "products"}).
+		TopK(0).
+		DeleteAll().
+		Build()
+	if err == nil || strings.Contains(err.Error(), "DeleteAll") {
+		t.Errorf("expected only the first error, got %v", err)
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	query := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(10)
+
+	huge := types.MaxTopK + 1
+	rewritten := query.Rewrite(func(ast *types.VectorAST) {
+		ast.TopK = &types.PaginationValue{Static: &huge}
+	})
+	if _, err := rewritten.Build(); err == nil {
+		t.Error("expected the rewritten AST to be validated")
+	}
+	ast, err := query.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *ast.TopK.Static != 10 {
+		t.Errorf("expected the original AST to be unchanged, got TopK %d", *ast.TopK.Static)
+	}
+}
@@ -0,0 +1,323 @@
+// Package catalog stores named, versioned query definitions as reviewable
+// JSON artifacts. A definition records the target collection, the query
+// shape, and the parameters callers must supply; it is validated against a
+// VDML schema by rebuilding it through a VECTQL instance:
+//
+//	q, err := catalog.NewQuery("product_search", 1, vectql.Search(v.C("products")).
+//	    Vector(vectql.Vec(v.P("query_vec"))).
+//	    TopK(10),
+//	    catalog.ParamSpec{Name: "query_vec", Type: catalog.TypeVector})
+//
+//	c := catalog.New()
+//	err = c.Add(q)
+//
+//	latest, ok := c.Latest("product_search")
+//	builder, err := latest.Builder(v)
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Parameter types. They match the types inferred by QueryResult.ParamSpec.
+const (
+	TypeVector  = string(types.ParamVector)
+	TypeString  = string(types.ParamString)
+	TypeNumber  = string(types.ParamNumber)
+	TypeInteger = string(types.ParamInteger)
+	TypeBoolean = string(types.ParamBoolean)
+	TypeList    = string(types.ParamList)
+)
+
+var paramTypes = map[string]bool{
+	TypeVector: true, TypeString: true, TypeNumber: true,
+	TypeInteger: true, TypeBoolean: true, TypeList: true,
+}
+
+// ParamSpec declares a parameter callers of a saved query must supply.
+type ParamSpec struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// Query is a named, versioned query definition.
+type Query struct {
+	Name        string `json:"name"`
+	Version     int    `json:"version"`
+	Description string `json:"description,omitempty"`
+
+	Collection string          `json:"collection"`
+	Operation  types.Operation `json:"operation"`
+	Params     []ParamSpec     `json:"params"`
+
+	Search    *Search           `json:"search,omitempty"`
+	Fetch     *Fetch            `json:"fetch,omitempty"`
+	Filter    *Filter           `json:"filter,omitempty"`
+	Namespace string            `json:"namespace,omitempty"`
+	IDs       []string          `json:"ids,omitempty"`
+	FetchBy   *FetchKey         `json:"fetch_by,omitempty"`
+	DeleteAll bool              `json:"delete_all,omitempty"`
+	Exists    bool              `json:"exists,omitempty"`
+	Set       map[string]string `json:"set,omitempty"`
+
+	// Transforms lists the bind transforms of parameters, by name.
+	Transforms map[string][]Transform `json:"transforms,omitempty"`
+
+	// Freshness is a freshness level; MaxStaleness is a Go duration string
+	// for bounded reads.
+	Freshness    types.FreshnessLevel `json:"freshness,omitempty"`
+	MaxStaleness string               `json:"max_staleness,omitempty"`
+
+	// Snapshot names the snapshot ID parameter of a point-in-time read;
+	// SnapshotKeepAlive is a Go duration string.
+	Snapshot          string `json:"snapshot,omitempty"`
+	SnapshotKeepAlive string `json:"snapshot_keep_alive,omitempty"`
+}
+
+// NewQuery creates a definition from a builder. Every parameter the query
+// uses must be declared in params.
+func NewQuery(name string, version int, b *vectql.Builder, params ...ParamSpec) (Query, error) {
+	ast, err := b.Build()
+	if err != nil {
+		return Query{}, err
+	}
+	q := Query{Name: name, Version: version, Params: params}
+	if err := q.encode(ast); err != nil {
+		return Query{}, err
+	}
+	if err := q.check(); err != nil {
+		return Query{}, err
+	}
+	return q, nil
+}
+
+// check validates the definition without a schema.
+func (q *Query) check() error {
+	if q.Name == "" {
+		return fmt.Errorf("query name is required")
+	}
+	if q.Version < 1 {
+		return fmt.Errorf("query %s: version must be positive: %d", q.Name, q.Version)
+	}
+
+	declared := make(map[string]bool, len(q.Params))
+	for _, p := range q.Params {
+		if !paramTypes[p.Type] {
+			return fmt.Errorf("query %s: parameter %s has unknown type %q", q.ID(), p.Name, p.Type)
+		}
+		if declared[p.Name] {
+			return fmt.Errorf("query %s: parameter %s is declared twice", q.ID(), p.Name)
+		}
+		declared[p.Name] = true
+	}
+	used := q.paramNames()
+	for name := range used {
+		if !declared[name] {
+			return fmt.Errorf("query %s: parameter %s is used but not declared", q.ID(), name)
+		}
+	}
+	for name := range declared {
+		if !used[name] {
+			return fmt.Errorf("query %s: parameter %s is declared but not used", q.ID(), name)
+		}
+	}
+	return nil
+}
+
+// ID returns "name@version".
+func (q *Query) ID() string {
+	return fmt.Sprintf("%s@%d", q.Name, q.Version)
+}
+
+// Validate checks the definition and rebuilds it against schema.
+func (q *Query) Validate(v *vectql.VECTQL) error {
+	if err := q.check(); err != nil {
+		return err
+	}
+	b, err := q.Builder(v)
+	if err != nil {
+		return fmt.Errorf("query %s: %w", q.ID(), err)
+	}
+	if _, err := b.Build(); err != nil {
+		return fmt.Errorf("query %s: %w", q.ID(), err)
+	}
+	return nil
+}
+
+// paramNames collects the parameters the definition references.
+func (q *Query) paramNames() map[string]bool {
+	names := make(map[string]bool)
+	add := func(name string) {
+		if name != "" {
+			names[name] = true
+		}
+	}
+	if s := q.Search; s != nil {
+		add(s.Vector)
+		add(s.NearText)
+		add(s.NearImage)
+		add(s.TopKParam)
+		add(s.MinScore)
+	}
+	add(q.Namespace)
+	add(q.Snapshot)
+	for _, id := range q.IDs {
+		add(id)
+	}
+	if q.FetchBy != nil {
+		for _, key := range q.FetchBy.Keys {
+			add(key)
+		}
+	}
+	for _, p := range q.Set {
+		add(p)
+	}
+	var walk func(Filter)
+	walk = func(f Filter) {
+		for _, name := range []string{f.Param, f.Min, f.Max, f.Lat, f.Lon, f.Radius} {
+			add(name)
+		}
+		for _, c := range f.Conditions {
+			walk(c)
+		}
+	}
+	if q.Filter != nil {
+		walk(*q.Filter)
+	}
+	return names
+}
+
+// Catalog holds query definitions keyed by name and version. It is not safe
+// for concurrent modification.
+type Catalog struct {
+	queries map[string]map[int]Query
+}
+
+// New creates an empty catalog.
+func New() *Catalog {
+	return &Catalog{queries: make(map[string]map[int]Query)}
+}
+
+// Add stores a definition. Versions are immutable: adding an existing
+// name and version fails.
+func (c *Catalog) Add(q Query) error {
+	if err := q.check(); err != nil {
+		return err
+	}
+	versions := c.queries[q.Name]
+	if versions == nil {
+		versions = make(map[int]Query)
+		c.queries[q.Name] = versions
+	}
+	if _, exists := versions[q.Version]; exists {
+		return fmt.Errorf("query %s already exists", q.ID())
+	}
+	versions[q.Version] = q
+	return nil
+}
+
+// Get returns a specific version of a query.
+func (c *Catalog) Get(name string, version int) (Query, bool) {
+	q, ok := c.queries[name][version]
+	return q, ok
+}
+
+// Latest returns the highest version of a query.
+func (c *Catalog) Latest(name string) (Query, bool) {
+	versions := c.Versions(name)
+	if len(versions) == 0 {
+		return Query{}, false
+	}
+	return c.Get(name, versions[len(versions)-1])
+}
+
+// Versions returns the stored versions of a query in ascending order.
+func (c *Catalog) Versions(name string) []int {
+	versions := make([]int, 0, len(c.queries[name]))
+	for version := range c.queries[name] {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// List returns every definition sorted by name, then version.
+func (c *Catalog) List() []Query {
+	var out []Query
+	for _, versions := range c.queries {
+		for _, q := range versions {
+			out = append(out, q)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Name != out[j].Name {
+			return out[i].Name < out[j].Name
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out
+}
+
+// Validate checks every definition against schema and reports all failures.
+func (c *Catalog) Validate(schema *vdml.Schema) error {
+	v, err := vectql.NewFromVDML(schema)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, q := range c.List() {
+		if err := q.Validate(v); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Load reads one JSON definition from r and adds it.
+func (c *Catalog) Load(r io.Reader) error {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	var q Query
+	if err := decoder.Decode(&q); err != nil {
+		return fmt.Errorf("failed to parse query definition: %w", err)
+	}
+	return c.Add(q)
+}
+
+// LoadFS adds every definition in fsys whose path matches pattern, e.g.
+// "queries/*.json".
+func (c *Catalog) LoadFS(fsys fs.FS, pattern string) error {
+	paths, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		f, err := fsys.Open(path)
+		if err != nil {
+			return err
+		}
+		err = c.Load(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Write encodes a definition as indented JSON, the format Load reads.
+func Write(w io.Writer, q Query) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(q)
+}
@@ -0,0 +1,436 @@
+package catalog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func testSchema() *vdml.Schema {
+	return vdml.NewSchema("shop").
+		AddCollection(vdml.NewCollection("products").
+			AddEmbedding(vdml.NewEmbedding("embedding", 3)).
+			AddMetadata(vdml.NewMetadataField("category", vdml.TypeString)).
+			AddMetadata(vdml.NewMetadataField("price", vdml.TypeFloat)))
+}
+
+func testInstance(t *testing.T) *vectql.VECTQL {
+	t.Helper()
+	v, err := vectql.NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return v
+}
+
+func productSearch(t *testing.T, v *vectql.VECTQL, version int) Query {
+	t.Helper()
+	minPrice := v.P("min_price")
+	q, err := NewQuery("product_search", version, vectql.Search(v.C("products")).
+		Vector(vectql.Vec(v.P("query_vec"))).
+		Embedding(v.E("products", "embedding")).
+		TopK(10).
+		SelectMetadata(v.M("products", "category")).
+		Filter(v.And(
+			v.Eq(v.M("products", "category"), v.P("category")),
+			v.Range(v.M("products", "price"), &minPrice, nil),
+		)),
+		ParamSpec{Name: "query_vec", Type: TypeVector},
+		ParamSpec{Name: "category", Type: TypeString},
+		ParamSpec{Name: "min_price", Type: TypeNumber},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return q
+}
+
+func TestRoundTrip(t *testing.T) {
+	v := testInstance(t)
+	original := productSearch(t, v, 1)
+
+	var buf bytes.Buffer
+	if err := Write(&buf, original); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := New()
+	if err := c.Load(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, ok := c.Get("product_search", 1)
+	if !ok {
+		t.Fatal("expected loaded query")
+	}
+	b, err := loaded.Builder(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := b.Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	direct, err := original.Builder(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := direct.MustRender(qdrant.New())
+	if got.JSON != expected.JSON {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected.JSON, got.JSON)
+	}
+}
+
+func TestRoundTrip_Freshness(t *testing.T) {
+	v := testInstance(t)
+	q, err := NewQuery("recent_products", 1, vectql.Search(v.C("products")).
+		Vector(vectql.Vec(v.P("query_vec"))).
+		TopK(10).
+		Freshness(vectql.Bounded(2*time.Second)),
+		ParamSpec{Name: "query_vec", Type: TypeVector},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.Freshness != vectql.FreshnessBounded || q.MaxStaleness != "2s" {
+		t.Errorf("unexpected freshness: %s %s", q.Freshness, q.MaxStaleness)
+	}
+
+	b, err := q.Builder(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ast, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f := ast.Freshness; f == nil || f.MaxStaleness != 2*time.Second {
+		t.Errorf("expected bounded freshness, got %#v", ast.Freshness)
+	}
+}
+
+func TestRoundTrip_Hints(t *testing.T) {
+	v := testInstance(t)
+	q, err := NewQuery("hinted_search", 1, vectql.Search(v.C("products")).
+		Vector(vectql.Vec(v.P("query_vec"))).
+		TopK(10).
+		Filter(v.And(
+			vectql.Hint(v.Eq(v.M("products", "category"), v.P("category")), vectql.Indexed, vectql.Selectivity(0.01)),
+			vectql.Hint(vectql.Exists(v.M("products", "price")), vectql.Indexed),
+		)),
+		ParamSpec{Name: "query_vec", Type: TypeVector},
+		ParamSpec{Name: "category", Type: TypeString},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	b, err := q.Builder(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ast, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	group := ast.FilterClause.(types.FilterGroup)
+	eq := group.Conditions[0].(types.FilterCondition)
+	if eq.Hint == nil || !eq.Hint.Indexed || eq.Hint.Selectivity != 0.01 {
+		t.Errorf("expected the equality hints to be restored, got %#v", eq.Hint)
+	}
+	exists := group.Conditions[1].(types.FilterCondition)
+	if exists.Hint == nil || !exists.Hint.Indexed || exists.Hint.Selectivity != 0 {
+		t.Errorf("expected the exists hint to be restored, got %#v", exists.Hint)
+	}
+}
+
+func TestRoundTrip_Snapshot(t *testing.T) {
+	v := testInstance(t)
+	q, err := NewQuery("snapshot_search", 1, vectql.Search(v.C("products")).
+		Vector(vectql.Vec(v.P("query_vec"))).
+		TopK(10).
+		Snapshot(v.P("pit"), time.Minute),
+		ParamSpec{Name: "query_vec", Type: TypeVector},
+		ParamSpec{Name: "pit", Type: TypeString},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.Snapshot != "pit" || q.SnapshotKeepAlive != "1m0s" {
+		t.Errorf("unexpected snapshot: %s %s", q.Snapshot, q.SnapshotKeepAlive)
+	}
+
+	b, err := q.Builder(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ast, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := ast.Snapshot; s == nil || s.ID.Name != "pit" || s.KeepAlive != time.Minute {
+		t.Errorf("expected the snapshot to be restored, got %#v", ast.Snapshot)
+	}
+
+	q.SnapshotKeepAlive = "soon"
+	if _, err := q.Builder(v); err == nil {
+		t.Error("expected an error for an invalid keep-alive")
+	}
+}
+
+func TestRoundTrip_Exists(t *testing.T) {
+	v := testInstance(t)
+	q, err := NewQuery("product_exists", 1, vectql.CheckExists(v.C("products")).
+		IDs(v.P("id")),
+		ParamSpec{Name: "id", Type: TypeString},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, q); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"exists": true`) {
+		t.Errorf("expected a serialized exists flag, got %s", buf.String())
+	}
+	c := New()
+	if err := c.Load(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, _ := c.Get("product_exists", 1)
+	b, err := loaded.Builder(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ast, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ast.Exists || ast.Operation != vectql.OpFetch {
+		t.Errorf("expected an existence check, got %s exists=%v", ast.Operation, ast.Exists)
+	}
+
+	loaded.Operation = vectql.OpDelete
+	if _, err := loaded.Builder(v); err == nil {
+		t.Error("expected an error for exists on a DELETE query")
+	}
+}
+
+func TestRoundTrip_FetchSettings(t *testing.T) {
+	v := testInstance(t)
+	q, err := NewQuery("product_ids", 1, vectql.Fetch(v.C("products")).
+		IDs(v.P("id")).
+		IncludeVectors(false).
+		SelectMetadata(v.M("products", "category")),
+		ParamSpec{Name: "id", Type: TypeString},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, q); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"include_vectors": false`) {
+		t.Errorf("expected the disabled vectors flag to be serialized, got %s", buf.String())
+	}
+	c := New()
+	if err := c.Load(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, _ := c.Get("product_ids", 1)
+	b, err := loaded.Builder(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ast, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ast.IncludeVectors || !ast.IncludeMetadata || len(ast.MetadataFields) != 1 || ast.MetadataFields[0].Name != "category" {
+		t.Errorf("expected the fetch settings to be restored, got vectors=%v metadata=%v select=%v",
+			ast.IncludeVectors, ast.IncludeMetadata, ast.MetadataFields)
+	}
+
+	loaded.Operation = vectql.OpDelete
+	if _, err := loaded.Builder(v); err == nil || !strings.Contains(err.Error(), "fetch settings") {
+		t.Errorf("expected an error for fetch settings on a DELETE query, got %v", err)
+	}
+}
+
+func TestRoundTrip_FetchBy(t *testing.T) {
+	schema := testSchema()
+	schema.Collections["products"].Settings = map[string]string{"category" + vectql.SettingUniqueSuffix: "true"}
+	v, err := vectql.NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q, err := NewQuery("products_by_category", 1, vectql.Fetch(v.C("products")).
+		FetchBy(v.M("products", "category"), v.P("first"), v.P("second")),
+		ParamSpec{Name: "first", Type: TypeString},
+		ParamSpec{Name: "second", Type: TypeString},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q.FetchBy == nil || q.FetchBy.Field != "category" || len(q.FetchBy.Keys) != 2 {
+		t.Fatalf("unexpected fetch key: %#v", q.FetchBy)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, q); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := New()
+	if err := c.Load(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, _ := c.Get("products_by_category", 1)
+	b, err := loaded.Builder(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ast, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	k := ast.FetchKey
+	if k == nil || k.Field.Name != "category" || len(k.Keys) != 2 || k.Keys[1].Name != "second" {
+		t.Errorf("expected the fetch key to be restored, got %#v", k)
+	}
+
+	if _, err := NewQuery("products_by_category", 2, vectql.Fetch(v.C("products")).
+		FetchBy(v.M("products", "category"), v.P("first")),
+	); err == nil || !strings.Contains(err.Error(), "first") {
+		t.Errorf("expected an undeclared key parameter error, got %v", err)
+	}
+
+	b, err = loaded.Builder(testInstance(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := b.Build(); err == nil || !strings.Contains(err.Error(), "unique") {
+		t.Errorf("expected an error when the field is not unique in the schema, got %v", err)
+	}
+}
+
+func TestRoundTrip_Transforms(t *testing.T) {
+	v := testInstance(t)
+	q, err := NewQuery("category_search", 1, vectql.Search(v.C("products")).
+		Vector(vectql.Vec(v.P("query_vec"))).
+		TopK(10).
+		Filter(v.Eq(v.M("products", "category"), v.P("category"))).
+		Transform(v.P("category"), vectql.TrimSpace(), vectql.Lowercase()),
+		ParamSpec{Name: "query_vec", Type: TypeVector},
+		ParamSpec{Name: "category", Type: TypeString},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, q); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"transforms"`) {
+		t.Errorf("expected serialized transforms, got %s", buf.String())
+	}
+	c := New()
+	if err := c.Load(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	loaded, _ := c.Get("category_search", 1)
+	b, err := loaded.Builder(v)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ast, err := b.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	chain := ast.BindTransforms["category"]
+	if len(chain) != 2 || chain[0].Op != vectql.TransformTrim || chain[1].Op != vectql.TransformLower {
+		t.Errorf("unexpected transforms %v", chain)
+	}
+}
+
+func TestCatalog_Versions(t *testing.T) {
+	v := testInstance(t)
+	c := New()
+	for _, version := range []int{2, 1} {
+		if err := c.Add(productSearch(t, v, version)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := c.Add(productSearch(t, v, 2)); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("expected duplicate version error, got %v", err)
+	}
+
+	latest, ok := c.Latest("product_search")
+	if !ok || latest.Version != 2 {
+		t.Errorf("expected latest version 2, got %d", latest.Version)
+	}
+	list := c.List()
+	if len(list) != 2 || list[0].Version != 1 || list[1].Version != 2 {
+		t.Errorf("expected versions listed in order, got %v", list)
+	}
+	if _, ok := c.Latest("missing"); ok {
+		t.Error("expected no latest version for an unknown query")
+	}
+}
+
+func TestNewQuery_UndeclaredParam(t *testing.T) {
+	v := testInstance(t)
+	_, err := NewQuery("q", 1, vectql.Search(v.C("products")).Vector(vectql.Vec(v.P("query_vec"))).TopK(5))
+	if err == nil || !strings.Contains(err.Error(), "query_vec is used but not declared") {
+		t.Errorf("expected undeclared parameter error, got %v", err)
+	}
+}
+
+func TestCatalog_Validate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"queries/stale.json": {Data: []byte(`{
+  "name": "stale",
+  "version": 1,
+  "collection": "products",
+  "operation": "SEARCH",
+  "params": [{"name": "query_vec", "type": "vector"}, {"name": "brand", "type": "string"}],
+  "search": {"vector": "query_vec", "top_k": 5},
+  "filter": {"field": "brand", "op": "=", "param": "brand"}
+}`)},
+		"queries/fetch.json": {Data: []byte(`{
+  "name": "fetch",
+  "version": 1,
+  "collection": "products",
+  "operation": "FETCH",
+  "params": [{"name": "id", "type": "string"}],
+  "ids": ["id"]
+}`)},
+	}
+
+	c := New()
+	if err := c.LoadFS(fsys, "queries/*.json"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.List()) != 2 {
+		t.Fatalf("expected 2 queries, got %d", len(c.List()))
+	}
+
+	err := c.Validate(testSchema())
+	if err == nil || !strings.Contains(err.Error(), "stale@1") || !strings.Contains(err.Error(), "brand") {
+		t.Errorf("expected validation error for stale@1, got %v", err)
+	}
+	if strings.Contains(err.Error(), "fetch@1") {
+		t.Errorf("expected fetch@1 to validate, got %v", err)
+	}
+}
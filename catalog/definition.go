@@ -0,0 +1,590 @@
+package catalog
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Filter operators that are not comparison operators.
+const (
+	opRange = "RANGE"
+	opGeo   = "GEO"
+)
+
+// FetchKey holds a FETCH by a unique metadata field: the field name and the
+// key parameters.
+type FetchKey struct {
+	Field string   `json:"field"`
+	Keys  []string `json:"keys"`
+}
+
+// Search holds the SEARCH-specific parts of a definition.
+type Search struct {
+	// Exactly one of Vector, NearText, and NearImage names the search input
+	// parameter.
+	Vector    string `json:"vector,omitempty"`
+	NearText  string `json:"near_text,omitempty"`
+	NearImage string `json:"near_image,omitempty"`
+
+	Embedding string `json:"embedding,omitempty"`
+
+	// TopK is static; TopKParam names a parameter instead.
+	TopK      int    `json:"top_k,omitempty"`
+	TopKParam string `json:"top_k_param,omitempty"`
+
+	MinScore        string   `json:"min_score,omitempty"`
+	IncludeVectors  bool     `json:"include_vectors,omitempty"`
+	IncludeMetadata bool     `json:"include_metadata,omitempty"`
+	Select          []string `json:"select,omitempty"`
+
+	Rescore            bool    `json:"rescore,omitempty"`
+	Oversampling       float64 `json:"oversampling,omitempty"`
+	IgnoreQuantization bool    `json:"ignore_quantization,omitempty"`
+}
+
+// Fetch holds the FETCH-specific parts of a definition. The flags are
+// stored even when false, since fetches include vectors and metadata by
+// default.
+type Fetch struct {
+	IncludeVectors  bool     `json:"include_vectors"`
+	IncludeMetadata bool     `json:"include_metadata"`
+	Select          []string `json:"select,omitempty"`
+}
+
+// Transform is the serialized form of a bind transform.
+type Transform struct {
+	Op   types.TransformOp `json:"op"`
+	Unit string            `json:"unit,omitempty"`
+}
+
+// Filter is the serialized form of a filter tree. A node is a group when
+// Logic is set, and a condition on Field otherwise.
+type Filter struct {
+	Logic      string   `json:"logic,omitempty"`
+	Conditions []Filter `json:"conditions,omitempty"`
+
+	Field string `json:"field,omitempty"`
+
+	// Op is a filter operator, "RANGE", or "GEO".
+	Op    string `json:"op,omitempty"`
+	Param string `json:"param,omitempty"`
+
+	// Range bounds.
+	Min          string `json:"min,omitempty"`
+	Max          string `json:"max,omitempty"`
+	MinExclusive bool   `json:"min_exclusive,omitempty"`
+	MaxExclusive bool   `json:"max_exclusive,omitempty"`
+
+	// Geo center and radius.
+	Lat    string `json:"lat,omitempty"`
+	Lon    string `json:"lon,omitempty"`
+	Radius string `json:"radius,omitempty"`
+
+	// Planner hints of a condition.
+	Indexed     bool    `json:"indexed,omitempty"`
+	Selectivity float64 `json:"selectivity,omitempty"`
+}
+
+// encode converts an AST into the definition fields of q.
+func (q *Query) encode(ast *types.VectorAST) error {
+	q.Collection = ast.Target.Name
+	q.Operation = ast.Operation
+	if ast.Namespace != nil {
+		q.Namespace = ast.Namespace.Name
+	}
+
+	switch ast.Operation {
+	case types.OpSearch:
+		s, err := encodeSearch(ast)
+		if err != nil {
+			return err
+		}
+		q.Search = s
+	case types.OpUpsert:
+		return fmt.Errorf("catalog does not store UPSERT queries")
+	case types.OpFetch:
+		if ast.Page != nil {
+			return fmt.Errorf("catalog does not store listings")
+		}
+		// Existence checks fix their own selection
+		if !ast.Exists {
+			q.Fetch = &Fetch{
+				IncludeVectors:  ast.IncludeVectors,
+				IncludeMetadata: ast.IncludeMetadata,
+			}
+			for _, f := range ast.MetadataFields {
+				q.Fetch.Select = append(q.Fetch.Select, f.Name)
+			}
+		}
+	}
+
+	for _, id := range ast.IDs {
+		q.IDs = append(q.IDs, id.Name)
+	}
+	if k := ast.FetchKey; k != nil {
+		q.FetchBy = &FetchKey{Field: k.Field.Name}
+		for _, key := range k.Keys {
+			q.FetchBy.Keys = append(q.FetchBy.Keys, key.Name)
+		}
+	}
+	q.DeleteAll = ast.DeleteAll
+	q.Exists = ast.Exists
+	if f := ast.Freshness; f != nil {
+		q.Freshness = f.Level
+		if f.MaxStaleness > 0 {
+			q.MaxStaleness = f.MaxStaleness.String()
+		}
+	}
+	if snap := ast.Snapshot; snap != nil {
+		q.Snapshot = snap.ID.Name
+		if snap.KeepAlive > 0 {
+			q.SnapshotKeepAlive = snap.KeepAlive.String()
+		}
+	}
+	if len(ast.Updates) > 0 {
+		q.Set = make(map[string]string, len(ast.Updates))
+		for field, p := range ast.Updates {
+			q.Set[field.Name] = p.Name
+		}
+	}
+	if ast.FilterClause != nil {
+		f, err := encodeFilter(ast.FilterClause)
+		if err != nil {
+			return err
+		}
+		q.Filter = &f
+	}
+	if len(ast.BindTransforms) > 0 {
+		q.Transforms = make(map[string][]Transform, len(ast.BindTransforms))
+		for name, chain := range ast.BindTransforms {
+			ts := make([]Transform, len(chain))
+			for i, t := range chain {
+				ts[i] = Transform{Op: t.Op, Unit: t.Unit}
+			}
+			q.Transforms[name] = ts
+		}
+	}
+	return nil
+}
+
+func encodeSearch(ast *types.VectorAST) (*Search, error) {
+	s := &Search{
+		IncludeVectors:  ast.IncludeVectors,
+		IncludeMetadata: ast.IncludeMetadata,
+	}
+	if ast.TextQuery != nil {
+		return nil, fmt.Errorf("catalog queries do not support text queries")
+	}
+	if len(ast.Boosts) > 0 {
+		return nil, fmt.Errorf("catalog queries do not support boosts")
+	}
+	if ast.Scoring != nil {
+		return nil, fmt.Errorf("catalog queries do not support scoring expressions")
+	}
+	switch {
+	case ast.QueryVector != nil:
+		if ast.QueryVector.Param == nil {
+			return nil, fmt.Errorf("catalog queries must bind vectors through parameters")
+		}
+		s.Vector = ast.QueryVector.Param.Name
+	case ast.NearText != nil:
+		s.NearText = ast.NearText.Concepts.Name
+	case ast.NearImage != nil:
+		s.NearImage = ast.NearImage.Image.Name
+	}
+	if ast.QueryEmbedding != nil {
+		s.Embedding = ast.QueryEmbedding.Name
+	}
+	if ast.TopK != nil {
+		if ast.TopK.Static != nil {
+			s.TopK = *ast.TopK.Static
+		} else if ast.TopK.Param != nil {
+			s.TopKParam = ast.TopK.Param.Name
+		}
+	}
+	if ast.MinScore != nil {
+		s.MinScore = ast.MinScore.Name
+	}
+	for _, f := range ast.MetadataFields {
+		s.Select = append(s.Select, f.Name)
+	}
+	if qp := ast.Quantization; qp != nil {
+		s.Rescore = qp.Rescore
+		s.Oversampling = qp.Oversampling
+		s.IgnoreQuantization = qp.Ignore
+	}
+	return s, nil
+}
+
+func encodeFilter(item types.FilterItem) (Filter, error) {
+	switch f := item.(type) {
+	case types.FilterCondition:
+		c := Filter{Field: f.Field.Name, Op: string(f.Operator), Param: f.Value.Name}
+		if f.Hint != nil {
+			c.Indexed, c.Selectivity = f.Hint.Indexed, f.Hint.Selectivity
+		}
+		return c, nil
+	case types.FilterGroup:
+		group := Filter{Logic: string(f.Logic)}
+		for _, c := range f.Conditions {
+			encoded, err := encodeFilter(c)
+			if err != nil {
+				return Filter{}, err
+			}
+			group.Conditions = append(group.Conditions, encoded)
+		}
+		return group, nil
+	case types.RangeFilter:
+		r := Filter{Field: f.Field.Name, Op: opRange, MinExclusive: f.MinExclusive, MaxExclusive: f.MaxExclusive}
+		if f.Min != nil {
+			r.Min = f.Min.Name
+		}
+		if f.Max != nil {
+			r.Max = f.Max.Name
+		}
+		return r, nil
+	case types.GeoFilter:
+		return Filter{Field: f.Field.Name, Op: opGeo, Lat: f.Center.Lat.Name, Lon: f.Center.Lon.Name, Radius: f.Radius.Name}, nil
+	default:
+		return Filter{}, fmt.Errorf("catalog cannot store %T filters", item)
+	}
+}
+
+// decoder rebuilds a query through a VECTQL instance so every collection,
+// field, and parameter is validated against the schema.
+type decoder struct {
+	v          *vectql.VECTQL
+	collection string
+}
+
+// Builder reconstructs the query against the schema behind v. It fails if a
+// collection, field, or parameter does not validate.
+func (q *Query) Builder(v *vectql.VECTQL) (*vectql.Builder, error) {
+	d := decoder{v: v, collection: q.Collection}
+	coll, err := v.TryC(q.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	var b *vectql.Builder
+	switch q.Operation {
+	case types.OpSearch:
+		b = vectql.Search(coll)
+	case types.OpDelete:
+		b = vectql.Delete(coll)
+	case types.OpFetch:
+		if q.Exists {
+			b = vectql.CheckExists(coll)
+		} else {
+			b = vectql.Fetch(coll)
+		}
+	case types.OpUpdate:
+		b = vectql.Update(coll)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", q.Operation)
+	}
+	if q.Exists && q.Operation != types.OpFetch {
+		return nil, fmt.Errorf("exists requires a FETCH query")
+	}
+
+	if q.Search != nil {
+		if q.Operation != types.OpSearch {
+			return nil, fmt.Errorf("search settings require a SEARCH query")
+		}
+		if err := d.search(b, q.Search); err != nil {
+			return nil, err
+		}
+	}
+	if q.Fetch != nil {
+		if q.Operation != types.OpFetch || q.Exists {
+			return nil, fmt.Errorf("fetch settings require a FETCH query that is not an existence check")
+		}
+		b.IncludeVectors(q.Fetch.IncludeVectors)
+		b.IncludeMetadata(q.Fetch.IncludeMetadata)
+		if len(q.Fetch.Select) > 0 {
+			fields, err := d.fields(q.Fetch.Select)
+			if err != nil {
+				return nil, err
+			}
+			b.SelectMetadata(fields...)
+		}
+	}
+	if q.Namespace != "" {
+		p, err := v.TryP(q.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		b.Namespace(p)
+	}
+	if len(q.IDs) > 0 {
+		ids := make([]types.Param, len(q.IDs))
+		for i, name := range q.IDs {
+			if ids[i], err = v.TryP(name); err != nil {
+				return nil, err
+			}
+		}
+		b.IDs(ids...)
+	}
+	if q.FetchBy != nil {
+		field, err := v.TryM(q.Collection, q.FetchBy.Field)
+		if err != nil {
+			return nil, err
+		}
+		keys := make([]types.Param, len(q.FetchBy.Keys))
+		for i, name := range q.FetchBy.Keys {
+			if keys[i], err = v.TryP(name); err != nil {
+				return nil, err
+			}
+		}
+		b.FetchBy(field, keys...)
+	}
+	if q.DeleteAll {
+		b.DeleteAll()
+	}
+	if q.Freshness != "" {
+		f := types.Freshness{Level: q.Freshness}
+		if q.MaxStaleness != "" {
+			if f.MaxStaleness, err = time.ParseDuration(q.MaxStaleness); err != nil {
+				return nil, fmt.Errorf("invalid max_staleness: %w", err)
+			}
+		}
+		b.Freshness(f)
+	}
+	if q.Snapshot != "" {
+		p, err := v.TryP(q.Snapshot)
+		if err != nil {
+			return nil, err
+		}
+		var keepAlive time.Duration
+		if q.SnapshotKeepAlive != "" {
+			if keepAlive, err = time.ParseDuration(q.SnapshotKeepAlive); err != nil {
+				return nil, fmt.Errorf("invalid snapshot_keep_alive: %w", err)
+			}
+		}
+		b.Snapshot(p, keepAlive)
+	}
+	fields := make([]string, 0, len(q.Set))
+	for field := range q.Set {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, name := range fields {
+		field, err := v.TryM(q.Collection, name)
+		if err != nil {
+			return nil, err
+		}
+		p, err := v.TryP(q.Set[name])
+		if err != nil {
+			return nil, err
+		}
+		b.Set(field, p)
+	}
+	if q.Filter != nil {
+		f, err := d.filter(*q.Filter)
+		if err != nil {
+			return nil, err
+		}
+		b.Filter(f)
+	}
+	names := make([]string, 0, len(q.Transforms))
+	for name := range q.Transforms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		p, err := v.TryP(name)
+		if err != nil {
+			return nil, err
+		}
+		chain := make([]types.BindTransform, len(q.Transforms[name]))
+		for i, t := range q.Transforms[name] {
+			chain[i] = types.BindTransform{Op: t.Op, Unit: t.Unit}
+		}
+		b.Transform(p, chain...)
+	}
+	return b, nil
+}
+
+// fields resolves selected metadata field names.
+func (d decoder) fields(names []string) ([]types.MetadataField, error) {
+	fields := make([]types.MetadataField, len(names))
+	for i, name := range names {
+		field, err := d.v.TryM(d.collection, name)
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = field
+	}
+	return fields, nil
+}
+
+func (d decoder) search(b *vectql.Builder, s *Search) error {
+	switch {
+	case s.Vector != "":
+		p, err := d.v.TryP(s.Vector)
+		if err != nil {
+			return err
+		}
+		b.Vector(vectql.Vec(p))
+	case s.NearText != "":
+		p, err := d.v.TryP(s.NearText)
+		if err != nil {
+			return err
+		}
+		b.NearText(p)
+	case s.NearImage != "":
+		p, err := d.v.TryP(s.NearImage)
+		if err != nil {
+			return err
+		}
+		b.NearImage(p)
+	}
+	if s.Embedding != "" {
+		e, err := d.v.TryE(d.collection, s.Embedding)
+		if err != nil {
+			return err
+		}
+		b.Embedding(e)
+	}
+	if s.TopKParam != "" {
+		p, err := d.v.TryP(s.TopKParam)
+		if err != nil {
+			return err
+		}
+		b.TopKParam(p)
+	} else {
+		b.TopK(s.TopK)
+	}
+	if s.MinScore != "" {
+		p, err := d.v.TryP(s.MinScore)
+		if err != nil {
+			return err
+		}
+		b.MinScore(p)
+	}
+	b.IncludeVectors(s.IncludeVectors)
+	b.IncludeMetadata(s.IncludeMetadata)
+	if len(s.Select) > 0 {
+		fields, err := d.fields(s.Select)
+		if err != nil {
+			return err
+		}
+		b.SelectMetadata(fields...)
+	}
+	if s.Rescore {
+		b.Rescore(s.Oversampling)
+	}
+	if s.IgnoreQuantization {
+		b.IgnoreQuantization()
+	}
+	return nil
+}
+
+func (d decoder) filter(f Filter) (types.FilterItem, error) {
+	if f.Logic != "" {
+		conditions := make([]types.FilterItem, len(f.Conditions))
+		for i, c := range f.Conditions {
+			item, err := d.filter(c)
+			if err != nil {
+				return nil, err
+			}
+			conditions[i] = item
+		}
+		switch types.LogicOperator(f.Logic) {
+		case types.AND:
+			return d.v.TryAnd(conditions...)
+		case types.OR:
+			return d.v.TryOr(conditions...)
+		case types.NOT:
+			if len(conditions) != 1 {
+				return nil, fmt.Errorf("NOT requires exactly one condition, got %d", len(conditions))
+			}
+			return d.v.TryNot(conditions[0])
+		default:
+			return nil, fmt.Errorf("unknown logic operator: %s", f.Logic)
+		}
+	}
+
+	field, err := d.v.TryM(d.collection, f.Field)
+	if err != nil {
+		return nil, err
+	}
+	optional := func(name string) (*types.Param, error) {
+		if name == "" {
+			return nil, nil
+		}
+		p, err := d.v.TryP(name)
+		if err != nil {
+			return nil, err
+		}
+		return &p, nil
+	}
+
+	switch f.Op {
+	case opRange:
+		minVal, err := optional(f.Min)
+		if err != nil {
+			return nil, err
+		}
+		maxVal, err := optional(f.Max)
+		if err != nil {
+			return nil, err
+		}
+		r, err := d.v.TryRange(field, minVal, maxVal)
+		if err != nil {
+			return nil, err
+		}
+		r.MinExclusive, r.MaxExclusive = f.MinExclusive, f.MaxExclusive
+		return r, nil
+	case opGeo:
+		lat, err := d.v.TryP(f.Lat)
+		if err != nil {
+			return nil, err
+		}
+		lon, err := d.v.TryP(f.Lon)
+		if err != nil {
+			return nil, err
+		}
+		radius, err := d.v.TryP(f.Radius)
+		if err != nil {
+			return nil, err
+		}
+		return d.v.TryGeo(field, lat, lon, radius)
+	}
+
+	var c types.FilterCondition
+	switch f.Op {
+	case string(types.Exists):
+		c, err = d.v.TryExists(field)
+	case string(types.NotExists):
+		c, err = d.v.TryNotExists(field)
+	default:
+		if !slices.Contains(types.FilterOperators, types.FilterOperator(f.Op)) {
+			return nil, fmt.Errorf("unknown filter operator: %s", f.Op)
+		}
+		var p types.Param
+		if p, err = d.v.TryP(f.Param); err != nil {
+			return nil, err
+		}
+		c, err = d.v.TryF(field, types.FilterOperator(f.Op), p)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var hints []vectql.HintOption
+	if f.Indexed {
+		hints = append(hints, vectql.Indexed)
+	}
+	if f.Selectivity != 0 {
+		hints = append(hints, vectql.Selectivity(f.Selectivity))
+	}
+	if len(hints) > 0 {
+		c = vectql.Hint(c, hints...)
+	}
+	return c, nil
+}
@@ -0,0 +1,313 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ChangeKind classifies a Change.
+type ChangeKind string
+
+// Change kinds.
+const (
+	Added   ChangeKind = "+"
+	Removed ChangeKind = "-"
+	Changed ChangeKind = "~"
+)
+
+// Change is one structural difference between two query definitions.
+type Change struct {
+	Kind ChangeKind
+
+	// Path names the changed part, e.g. "search.top_k" or "filter".
+	Path string
+
+	// Old and New hold the values before and after. Old is empty for
+	// additions and New for removals.
+	Old string
+	New string
+}
+
+// String formats the change as a single diff line.
+func (c Change) String() string {
+	switch c.Kind {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, c.New)
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", c.Path, c.Old, c.New)
+	}
+}
+
+// Changes is a list of differences in definition order.
+type Changes []Change
+
+// String formats the changes one per line.
+func (cs Changes) String() string {
+	lines := make([]string, len(cs))
+	for i, c := range cs {
+		lines[i] = c.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Diff reports the structural differences between two versions of a query:
+// changed settings, parameters added or removed, selected fields, and filter
+// conditions. Top-level AND conditions are compared as a set, so reordering
+// them is not a change.
+func Diff(from, to Query) Changes {
+	var d differ
+	d.value("name", from.Name, to.Name)
+	d.value("description", from.Description, to.Description)
+	d.value("collection", from.Collection, to.Collection)
+	d.value("operation", string(from.Operation), string(to.Operation))
+
+	d.set("params", paramStrings(from.Params), paramStrings(to.Params))
+
+	fs, ts := from.Search, to.Search
+	if fs == nil {
+		fs = &Search{}
+	}
+	if ts == nil {
+		ts = &Search{}
+	}
+	d.value("search.vector", fs.Vector, ts.Vector)
+	d.value("search.near_text", fs.NearText, ts.NearText)
+	d.value("search.near_image", fs.NearImage, ts.NearImage)
+	d.value("search.embedding", fs.Embedding, ts.Embedding)
+	d.value("search.top_k", topK(fs), topK(ts))
+	d.value("search.min_score", param(fs.MinScore), param(ts.MinScore))
+	d.value("search.include_vectors", flag(fs.IncludeVectors), flag(ts.IncludeVectors))
+	d.value("search.include_metadata", flag(fs.IncludeMetadata), flag(ts.IncludeMetadata))
+	d.set("search.select", fs.Select, ts.Select)
+	d.value("search.rescore", rescore(fs), rescore(ts))
+	d.value("search.ignore_quantization", flag(fs.IgnoreQuantization), flag(ts.IgnoreQuantization))
+
+	fetchFrom, fetchTo := fetchSettings(from), fetchSettings(to)
+	d.value("fetch.include_vectors", flag(fetchFrom.IncludeVectors), flag(fetchTo.IncludeVectors))
+	d.value("fetch.include_metadata", flag(fetchFrom.IncludeMetadata), flag(fetchTo.IncludeMetadata))
+	d.set("fetch.select", fetchFrom.Select, fetchTo.Select)
+
+	d.value("namespace", param(from.Namespace), param(to.Namespace))
+	d.set("ids", paramList(from.IDs), paramList(to.IDs))
+	ff, tf := from.FetchBy, to.FetchBy
+	if ff == nil {
+		ff = &FetchKey{}
+	}
+	if tf == nil {
+		tf = &FetchKey{}
+	}
+	d.value("fetch_by.field", ff.Field, tf.Field)
+	d.set("fetch_by.keys", paramList(ff.Keys), paramList(tf.Keys))
+	d.value("delete_all", flag(from.DeleteAll), flag(to.DeleteAll))
+	d.value("exists", flag(from.Exists), flag(to.Exists))
+	d.value("freshness", freshness(from), freshness(to))
+	d.value("snapshot", snapshot(from), snapshot(to))
+	d.set("set", setStrings(from.Set), setStrings(to.Set))
+	d.set("filter", conjuncts(from.Filter), conjuncts(to.Filter))
+	d.set("transforms", transformStrings(from.Transforms), transformStrings(to.Transforms))
+	return d.changes
+}
+
+type differ struct {
+	changes Changes
+}
+
+func (d *differ) value(path, old, new string) {
+	switch {
+	case old == new:
+	case old == "":
+		d.changes = append(d.changes, Change{Kind: Added, Path: path, New: new})
+	case new == "":
+		d.changes = append(d.changes, Change{Kind: Removed, Path: path, Old: old})
+	default:
+		d.changes = append(d.changes, Change{Kind: Changed, Path: path, Old: old, New: new})
+	}
+}
+
+// set reports items removed from and added to an unordered collection.
+func (d *differ) set(path string, old, new []string) {
+	inOld := make(map[string]bool, len(old))
+	for _, s := range old {
+		inOld[s] = true
+	}
+	inNew := make(map[string]bool, len(new))
+	for _, s := range new {
+		inNew[s] = true
+	}
+	for _, s := range sorted(old) {
+		if !inNew[s] {
+			d.changes = append(d.changes, Change{Kind: Removed, Path: path, Old: s})
+		}
+	}
+	for _, s := range sorted(new) {
+		if !inOld[s] {
+			d.changes = append(d.changes, Change{Kind: Added, Path: path, New: s})
+		}
+	}
+}
+
+func sorted(items []string) []string {
+	out := append([]string(nil), items...)
+	sort.Strings(out)
+	return out
+}
+
+func flag(b bool) string {
+	if b {
+		return "true"
+	}
+	return ""
+}
+
+// fetchSettings returns the fetch settings of q. Definitions without them
+// include vectors and metadata, as Fetch does.
+func fetchSettings(q Query) *Fetch {
+	if q.Fetch == nil {
+		return &Fetch{IncludeVectors: true, IncludeMetadata: true}
+	}
+	return q.Fetch
+}
+
+func freshness(q Query) string {
+	if q.MaxStaleness == "" {
+		return string(q.Freshness)
+	}
+	return string(q.Freshness) + " " + q.MaxStaleness
+}
+
+func snapshot(q Query) string {
+	if q.SnapshotKeepAlive == "" {
+		return param(q.Snapshot)
+	}
+	return param(q.Snapshot) + " " + q.SnapshotKeepAlive
+}
+
+func param(name string) string {
+	if name == "" {
+		return ""
+	}
+	return ":" + name
+}
+
+func paramList(names []string) []string {
+	out := make([]string, len(names))
+	for i, name := range names {
+		out[i] = param(name)
+	}
+	return out
+}
+
+func topK(s *Search) string {
+	if s.TopKParam != "" {
+		return param(s.TopKParam)
+	}
+	if s.TopK == 0 {
+		return ""
+	}
+	return strconv.Itoa(s.TopK)
+}
+
+func rescore(s *Search) string {
+	if !s.Rescore {
+		return ""
+	}
+	if s.Oversampling == 0 {
+		return "true"
+	}
+	return fmt.Sprintf("oversampling %g", s.Oversampling)
+}
+
+func paramStrings(params []ParamSpec) []string {
+	out := make([]string, len(params))
+	for i, p := range params {
+		out[i] = p.Name + " " + p.Type
+	}
+	return out
+}
+
+func setStrings(set map[string]string) []string {
+	out := make([]string, 0, len(set))
+	for field, p := range set {
+		out = append(out, field+" = "+param(p))
+	}
+	return out
+}
+
+func transformStrings(transforms map[string][]Transform) []string {
+	out := make([]string, 0, len(transforms))
+	for name, chain := range transforms {
+		ops := make([]string, len(chain))
+		for i, t := range chain {
+			ops[i] = string(t.Op)
+			if t.Unit != "" {
+				ops[i] += "(" + t.Unit + ")"
+			}
+		}
+		out = append(out, param(name)+" | "+strings.Join(ops, " | "))
+	}
+	return out
+}
+
+// conjuncts splits a filter into its top-level AND conditions.
+func conjuncts(f *Filter) []string {
+	if f == nil {
+		return nil
+	}
+	if f.Logic == "AND" {
+		out := make([]string, len(f.Conditions))
+		for i, c := range f.Conditions {
+			out[i] = formatFilter(c)
+		}
+		return out
+	}
+	return []string{formatFilter(*f)}
+}
+
+// formatFilter renders a filter node in a compact readable form.
+func formatFilter(f Filter) string {
+	if f.Logic != "" {
+		parts := make([]string, len(f.Conditions))
+		for i, c := range f.Conditions {
+			parts[i] = formatFilter(c)
+		}
+		return f.Logic + "(" + strings.Join(parts, ", ") + ")"
+	}
+	switch f.Op {
+	case opRange:
+		lower, upper := "[", "]"
+		if f.MinExclusive {
+			lower = "("
+		}
+		if f.MaxExclusive {
+			upper = ")"
+		}
+		return fmt.Sprintf("%s in %s%s, %s%s", f.Field, lower, param(f.Min), param(f.Max), upper)
+	case opGeo:
+		return fmt.Sprintf("%s within %s of (%s, %s)", f.Field, param(f.Radius), param(f.Lat), param(f.Lon))
+	default:
+		c := fmt.Sprintf("%s %s", f.Field, f.Op)
+		if f.Param != "" {
+			c += " " + param(f.Param)
+		}
+		return c + hints(f)
+	}
+}
+
+// hints formats the planner hints of a condition, e.g. " [indexed]".
+func hints(f Filter) string {
+	var hs []string
+	if f.Indexed {
+		hs = append(hs, "indexed")
+	}
+	if f.Selectivity != 0 {
+		hs = append(hs, fmt.Sprintf("selectivity %g", f.Selectivity))
+	}
+	if len(hs) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(hs, ", ") + "]"
+}
@@ -0,0 +1,85 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql"
+)
+
+func TestDiff(t *testing.T) {
+	v := testInstance(t)
+	from := productSearch(t, v, 1)
+
+	to, err := NewQuery("product_search", 2, vectql.Search(v.C("products")).
+		Vector(vectql.Vec(v.P("query_vec"))).
+		Embedding(v.E("products", "embedding")).
+		TopK(20).
+		SelectMetadata(v.M("products", "category"), v.M("products", "price")).
+		Filter(v.And(
+			v.Eq(v.M("products", "category"), v.P("category")),
+			vectql.Exists(v.M("products", "price")),
+		)),
+		ParamSpec{Name: "query_vec", Type: TypeVector},
+		ParamSpec{Name: "category", Type: TypeString},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `- params: min_price number
+~ search.top_k: 10 -> 20
++ search.select: price
+- filter: price in [:min_price, ]
++ filter: price EXISTS`
+	if got := Diff(from, to).String(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestDiff_Identical(t *testing.T) {
+	v := testInstance(t)
+	q := productSearch(t, v, 1)
+	if changes := Diff(q, q); len(changes) != 0 {
+		t.Errorf("expected no changes, got:\n%s", changes)
+	}
+}
+
+func TestDiff_Hints(t *testing.T) {
+	v := testInstance(t)
+	from := productSearch(t, v, 1)
+	to := from
+	filter := *from.Filter
+	filter.Conditions = append([]Filter(nil), filter.Conditions...)
+	filter.Conditions[0].Indexed = true
+	filter.Conditions[0].Selectivity = 0.01
+	to.Filter = &filter
+
+	expected := `- filter: category = :category
++ filter: category = :category [indexed, selectivity 0.01]`
+	if got := Diff(from, to).String(); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestDiff_FetchSettings(t *testing.T) {
+	v := testInstance(t)
+	from, err := NewQuery("product_ids", 1, vectql.Fetch(v.C("products")).IDs(v.P("id")),
+		ParamSpec{Name: "id", Type: TypeString},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Definitions written before fetch settings were stored use the defaults
+	legacy := from
+	legacy.Fetch = nil
+	if changes := Diff(legacy, from); len(changes) != 0 {
+		t.Errorf("expected no changes, got:\n%s", changes)
+	}
+
+	to := from
+	to.Fetch = &Fetch{IncludeMetadata: true}
+	if got, expected := Diff(from, to).String(), "- fetch.include_vectors: true"; got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
@@ -0,0 +1,79 @@
+package vectql
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WriteEvent describes a write a provider has applied.
+type WriteEvent struct {
+	Operation  Operation
+	Provider   string
+	Collection string
+	Namespace  string
+
+	// IDs lists the records written. It is empty when Bulk is set.
+	IDs []string
+
+	// Bulk reports a delete by filter or of a whole namespace, whose affected
+	// records are not known to the client.
+	Bulk bool
+
+	// Timestamp is when the provider acknowledged the write.
+	Timestamp time.Time
+}
+
+// WriteHook receives write events.
+type WriteHook func(ctx context.Context, event WriteEvent)
+
+// CaptureWrites returns an executor that calls hook after every UPSERT,
+// UPDATE, and DELETE that next executes successfully, so caches and index
+// mirrors can follow the vector store's mutations. Failed writes and reads
+// emit nothing. The hook runs synchronously before Execute returns; hooks
+// that publish to a broker should hand events off rather than block.
+func CaptureWrites(next Executor, hook WriteHook) Executor {
+	return ExecutorFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		resp, err := next.Execute(ctx, req)
+		if err != nil || AccessOf(req.Operation) != AccessWrite {
+			return resp, err
+		}
+		hook(ctx, WriteEvent{
+			Operation:  req.Operation,
+			Provider:   req.Provider,
+			Collection: req.Collection,
+			Namespace:  req.Namespace,
+			IDs:        req.IDs,
+			Bulk:       req.Operation == OpDelete && len(req.IDs) == 0,
+			Timestamp:  time.Now(),
+		})
+		return resp, nil
+	})
+}
+
+// boundNamespace returns the namespace value bound for ast.
+func boundNamespace(ast *VectorAST, params map[string]interface{}) string {
+	if ast.Namespace == nil {
+		return ""
+	}
+	if value, ok := params[ast.Namespace.Name]; ok {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
+
+// boundIDs returns the record ID values bound for ast.
+func boundIDs(ast *VectorAST, params map[string]interface{}) []string {
+	var ids []string
+	for _, record := range ast.Vectors {
+		if value, ok := params[record.ID.Name]; ok {
+			ids = append(ids, fmt.Sprint(value))
+		}
+	}
+	for _, id := range ast.IDs {
+		if value, ok := params[id.Name]; ok {
+			ids = append(ids, fmt.Sprint(value))
+		}
+	}
+	return ids
+}
@@ -0,0 +1,84 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestPrepare_IDsAndNamespace(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+	query := Upsert(coll).
+		AddVector(NewRecord(types.Param{Name: "id1"}, Vec(types.Param{Name: "vec1"})).Build()).
+		AddVector(NewRecord(types.Param{Name: "id2"}, Vec(types.Param{Name: "vec2"})).Build()).
+		Namespace(types.Param{Name: "ns"})
+	params := map[string]interface{}{
+		"id1": "a", "vec1": []float32{1, 0},
+		"id2": 7, "vec2": []float32{0, 1},
+		"ns": "tenant-1",
+	}
+
+	req, err := Prepare(query, qdrant.New(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(req.IDs, []string{"a", "7"}) {
+		t.Errorf("expected IDs [a 7], got %v", req.IDs)
+	}
+	if req.Namespace != "tenant-1" {
+		t.Errorf("expected namespace tenant-1, got %q", req.Namespace)
+	}
+}
+
+func TestCaptureWrites(t *testing.T) {
+	var events []WriteEvent
+	exec := CaptureWrites(&captureExecutor{}, func(_ context.Context, e WriteEvent) {
+		events = append(events, e)
+	})
+
+	requests := []*Request{
+		{Provider: "qdrant", Operation: OpUpsert, Collection: "products", Namespace: "ns", IDs: []string{"a", "b"}},
+		{Provider: "qdrant", Operation: OpSearch, Collection: "products"},
+		{Provider: "qdrant", Operation: OpFetch, Collection: "products", IDs: []string{"a"}},
+		{Provider: "qdrant", Operation: OpDelete, Collection: "products"},
+	}
+	for _, req := range requests {
+		if _, err := exec.Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 write events, got %d: %+v", len(events), events)
+	}
+	upsert := events[0]
+	if upsert.Operation != OpUpsert || upsert.Provider != "qdrant" || upsert.Collection != "products" ||
+		upsert.Namespace != "ns" || !reflect.DeepEqual(upsert.IDs, []string{"a", "b"}) || upsert.Bulk {
+		t.Errorf("unexpected upsert event: %+v", upsert)
+	}
+	if upsert.Timestamp.IsZero() {
+		t.Error("expected event timestamp")
+	}
+	if events[1].Operation != OpDelete || !events[1].Bulk {
+		t.Errorf("expected bulk delete event, got %+v", events[1])
+	}
+}
+
+func TestCaptureWrites_SkipsFailures(t *testing.T) {
+	called := false
+	failing := ExecutorFunc(func(context.Context, *Request) (*Response, error) {
+		return nil, errors.New("unavailable")
+	})
+	exec := CaptureWrites(failing, func(context.Context, WriteEvent) { called = true })
+
+	if _, err := exec.Execute(context.Background(), &Request{Operation: OpUpsert, IDs: []string{"a"}}); err == nil {
+		t.Fatal("expected error")
+	}
+	if called {
+		t.Error("expected no event for a failed write")
+	}
+}
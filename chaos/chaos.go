@@ -0,0 +1,244 @@
+// Package chaos perturbs valid queries within validation limits and runs
+// them against a staging provider, reporting panics and requests slower than
+// a latency bound. Use it to harden a service before traffic spikes:
+//
+//	report, err := chaos.Run(ctx, executor, qdrant.New(), []chaos.Query{
+//	    {Builder: productSearch, Params: sampleParams},
+//	}, chaos.Config{Samples: 500, Seed: 1, MaxLatency: time.Second})
+//	if !report.OK() {
+//	    t.Fatal(report.Failures)
+//	}
+//
+// Runs are reproducible: the same seed perturbs the same queries the same
+// way.
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Perturbation rewrites a query. Apply receives a shallow copy of the AST
+// and a copy of the parameters; it may replace AST fields and parameter
+// values, but must not modify slices and maps shared with the original AST.
+// It reports false when the perturbation does not apply to the query.
+type Perturbation struct {
+	Name  string
+	Apply func(rng *rand.Rand, ast *vectql.VectorAST, params map[string]interface{}) bool
+}
+
+// DropFilter removes the filter of a query.
+var DropFilter = Perturbation{
+	Name: "drop_filter",
+	Apply: func(_ *rand.Rand, ast *vectql.VectorAST, _ map[string]interface{}) bool {
+		if ast.FilterClause == nil {
+			return false
+		}
+		ast.FilterClause = nil
+		return true
+	},
+}
+
+// ExtremeTopK sets the TopK of a search to 1 or to MaxTopK.
+var ExtremeTopK = Perturbation{
+	Name: "extreme_topk",
+	Apply: func(rng *rand.Rand, ast *vectql.VectorAST, params map[string]interface{}) bool {
+		if ast.Operation != types.OpSearch || ast.TopK == nil {
+			return false
+		}
+		k := 1
+		if rng.Intn(2) == 0 {
+			k = vectql.MaxTopK
+		}
+		if ast.TopK.Param != nil {
+			params[ast.TopK.Param.Name] = k
+			return true
+		}
+		ast.TopK = &types.PaginationValue{Static: &k}
+		return true
+	},
+}
+
+// EmptyNamespace binds the namespace of a query to the empty string.
+var EmptyNamespace = Perturbation{
+	Name: "empty_namespace",
+	Apply: func(_ *rand.Rand, ast *vectql.VectorAST, params map[string]interface{}) bool {
+		if ast.Namespace == nil {
+			return false
+		}
+		params[ast.Namespace.Name] = ""
+		return true
+	},
+}
+
+// IncludeEverything asks a search or fetch to return vectors and every
+// metadata field.
+var IncludeEverything = Perturbation{
+	Name: "include_everything",
+	Apply: func(_ *rand.Rand, ast *vectql.VectorAST, _ map[string]interface{}) bool {
+		if ast.Operation != types.OpSearch && ast.Operation != types.OpFetch {
+			return false
+		}
+		ast.IncludeVectors = true
+		ast.IncludeMetadata = true
+		ast.MetadataFields = nil
+		return true
+	},
+}
+
+// DefaultPerturbations are used when Config.Perturbations is empty.
+var DefaultPerturbations = []Perturbation{DropFilter, ExtremeTopK, EmptyNamespace, IncludeEverything}
+
+// Query is a valid query and parameters to perturb.
+type Query struct {
+	Builder *vectql.Builder
+	Params  map[string]interface{}
+}
+
+// Config controls a chaos run.
+type Config struct {
+	// Samples is the number of perturbed queries to run.
+	Samples int
+
+	// Seed seeds the random choice of queries and perturbations.
+	Seed int64
+
+	// MaxLatency bounds the duration of each request. Zero disables the
+	// bound.
+	MaxLatency time.Duration
+
+	// Perturbations to choose from; DefaultPerturbations when empty.
+	Perturbations []Perturbation
+}
+
+// Failure is a sample that panicked or exceeded the latency bound.
+type Failure struct {
+	Perturbation string
+	Fingerprint  string
+	Elapsed      time.Duration
+
+	// Panic holds the recovered value when the sample panicked.
+	Panic interface{}
+}
+
+// String describes the failure.
+func (f Failure) String() string {
+	if f.Panic != nil {
+		return fmt.Sprintf("%s (%s): panic: %v", f.Perturbation, f.Fingerprint, f.Panic)
+	}
+	return fmt.Sprintf("%s (%s): took %v", f.Perturbation, f.Fingerprint, f.Elapsed)
+}
+
+// Report summarizes a chaos run.
+type Report struct {
+	// Executed counts samples sent to the executor.
+	Executed int
+
+	// Rejected counts samples that failed validation or rendering, which
+	// is an acceptable outcome for a perturbed query.
+	Rejected int
+
+	// Skipped counts samples to which no perturbation applied.
+	Skipped int
+
+	// Errors counts executor errors by message. Provider errors are
+	// reported but are not failures.
+	Errors map[string]int
+
+	Failures []Failure
+	Max      time.Duration
+}
+
+// OK reports whether the run had no failures.
+func (r *Report) OK() bool {
+	return len(r.Failures) == 0
+}
+
+// Run perturbs queries and executes them with executor, rendering with r.
+func Run(ctx context.Context, executor vectql.Executor, r vectql.Renderer, queries []Query, cfg Config) (*Report, error) {
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("at least one query is required")
+	}
+	if cfg.Samples <= 0 {
+		return nil, fmt.Errorf("samples must be positive: %d", cfg.Samples)
+	}
+	perturbations := cfg.Perturbations
+	if len(perturbations) == 0 {
+		perturbations = DefaultPerturbations
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	report := &Report{Errors: make(map[string]int)}
+	for i := 0; i < cfg.Samples; i++ {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		query := queries[rng.Intn(len(queries))]
+		builder, params, name, ok := perturb(rng, query, perturbations)
+		if !ok {
+			report.Skipped++
+			continue
+		}
+		sample(ctx, executor, r, builder, params, name, cfg.MaxLatency, report)
+	}
+	return report, nil
+}
+
+// perturb applies the first applicable perturbation, in random order.
+func perturb(rng *rand.Rand, query Query, perturbations []Perturbation) (*vectql.Builder, map[string]interface{}, string, bool) {
+	for _, i := range rng.Perm(len(perturbations)) {
+		p := perturbations[i]
+		params := make(map[string]interface{}, len(query.Params))
+		for name, value := range query.Params {
+			params[name] = value
+		}
+		applied := false
+		builder := query.Builder.Rewrite(func(ast *vectql.VectorAST) {
+			applied = p.Apply(rng, ast, params)
+		})
+		if applied {
+			return builder, params, p.Name, true
+		}
+	}
+	return nil, nil, "", false
+}
+
+// sample prepares and executes one perturbed query, recording panics as
+// failures.
+func sample(ctx context.Context, executor vectql.Executor, r vectql.Renderer, builder *vectql.Builder, params map[string]interface{}, name string, maxLatency time.Duration, report *Report) {
+	failure := Failure{Perturbation: name}
+	defer func() {
+		if v := recover(); v != nil {
+			failure.Panic = v
+			report.Failures = append(report.Failures, failure)
+		}
+	}()
+
+	if ast, err := builder.Build(); err == nil {
+		failure.Fingerprint = vectql.Fingerprint(ast)
+	}
+	req, err := vectql.Prepare(builder, r, params)
+	if err != nil {
+		report.Rejected++
+		return
+	}
+
+	report.Executed++
+	start := time.Now()
+	_, err = executor.Execute(ctx, req)
+	failure.Elapsed = time.Since(start)
+	if failure.Elapsed > report.Max {
+		report.Max = failure.Elapsed
+	}
+	if err != nil {
+		report.Errors[err.Error()]++
+	}
+	if maxLatency > 0 && failure.Elapsed > maxLatency {
+		report.Failures = append(report.Failures, failure)
+	}
+}
@@ -0,0 +1,106 @@
+package chaos
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func testQuery() Query {
+	category := types.MetadataField{Name: "category", Type: "string"}
+	return Query{
+		Builder: vectql.Search(types.Collection{Name: "products"}).
+			Vector(vectql.Vec(types.Param{Name: "query_vec"})).
+			Filter(vectql.Eq(category, types.Param{Name: "category"})).
+			Namespace(types.Param{Name: "ns"}).
+			TopK(10),
+		Params: map[string]interface{}{"query_vec": []float32{1, 0}, "category": "shoes", "ns": "tenant-1"},
+	}
+}
+
+func TestRun(t *testing.T) {
+	var bodies []string
+	exec := vectql.ExecutorFunc(func(_ context.Context, req *vectql.Request) (*vectql.Response, error) {
+		bodies = append(bodies, req.Body)
+		return &vectql.Response{}, nil
+	})
+	query := testQuery()
+	report, err := Run(context.Background(), exec, qdrant.New(), []Query{query}, Config{Samples: 40, Seed: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.OK() || report.Executed+report.Rejected != 40 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	var dropped, extreme bool
+	for _, body := range bodies {
+		dropped = dropped || !strings.Contains(body, "shoes")
+		extreme = extreme || strings.Contains(body, `"limit":10000`) || strings.Contains(body, `"limit":1,`)
+	}
+	if !dropped || !extreme {
+		t.Errorf("expected dropped filters and extreme TopK values among %d samples", len(bodies))
+	}
+
+	ast, err := query.Builder.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ast.FilterClause == nil || *ast.TopK.Static != 10 || query.Params["ns"] != "tenant-1" {
+		t.Error("expected the original query to be unchanged")
+	}
+
+	again, _ := Run(context.Background(), exec, qdrant.New(), []Query{query}, Config{Samples: 40, Seed: 1})
+	if again.Executed != report.Executed || again.Rejected != report.Rejected {
+		t.Errorf("expected the same seed to reproduce the run, got %+v and %+v", report, again)
+	}
+}
+
+func TestRun_Failures(t *testing.T) {
+	exec := vectql.ExecutorFunc(func(_ context.Context, req *vectql.Request) (*vectql.Response, error) {
+		if strings.Contains(req.Body, `"limit":10000`) {
+			panic("result buffer overflow")
+		}
+		time.Sleep(2 * time.Millisecond)
+		return &vectql.Response{}, nil
+	})
+	report, err := Run(context.Background(), exec, qdrant.New(), []Query{testQuery()}, Config{
+		Samples:       20,
+		Seed:          3,
+		MaxLatency:    time.Millisecond,
+		Perturbations: []Perturbation{ExtremeTopK},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var panics, slow int
+	for _, f := range report.Failures {
+		if f.Panic != nil {
+			panics++
+		} else {
+			slow++
+		}
+		if f.Perturbation != "extreme_topk" || f.Fingerprint == "" {
+			t.Errorf("unexpected failure: %s", f)
+		}
+	}
+	if panics == 0 || slow == 0 || report.OK() {
+		t.Errorf("expected panics and slow requests, got %d and %d", panics, slow)
+	}
+}
+
+func TestRun_Validation(t *testing.T) {
+	exec := vectql.ExecutorFunc(func(_ context.Context, _ *vectql.Request) (*vectql.Response, error) {
+		return &vectql.Response{}, nil
+	})
+	if _, err := Run(context.Background(), exec, qdrant.New(), nil, Config{Samples: 1}); err == nil {
+		t.Error("expected an error without queries")
+	}
+	if _, err := Run(context.Background(), exec, qdrant.New(), []Query{testQuery()}, Config{}); err == nil {
+		t.Error("expected an error without samples")
+	}
+}
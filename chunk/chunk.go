@@ -0,0 +1,221 @@
+// Package chunk splits documents into overlapping chunks for embedding and
+// turns the embedded chunks into records with standard metadata:
+//
+//	chunker, err := chunk.Sentences(5, 1)
+//	chunks := chunker(document)
+//	vectors, err := embedder.Embed(ctx, chunk.Texts(chunks))
+//	records, err := chunk.Records("docs/handbook.md", chunks, vectors)
+//
+// Records carry the source, chunk index, byte offsets, and text under the
+// field names declared by Schema, so every pipeline writes the same
+// metadata and queries can filter or regroup chunks by source.
+package chunk
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql/ingest"
+)
+
+// Standard metadata fields of chunk records.
+const (
+	FieldSource     = "source"
+	FieldChunkIndex = "chunk_index"
+	FieldStart      = "start_offset"
+	FieldEnd        = "end_offset"
+	FieldText       = "text"
+)
+
+// Chunk is one piece of a document. Start and End are byte offsets into the
+// document, so Text == document[Start:End].
+type Chunk struct {
+	Text  string
+	Index int
+	Start int
+	End   int
+}
+
+// Chunker splits a document into chunks, in document order.
+type Chunker func(text string) []Chunk
+
+// Window returns a Chunker of size characters, each sharing overlap
+// characters with the previous chunk.
+func Window(size, overlap int) (Chunker, error) {
+	if err := validate(size, overlap); err != nil {
+		return nil, err
+	}
+	return func(text string) []Chunk {
+		var spans []span
+		for i, r := range text {
+			spans = append(spans, span{i, i + utf8.RuneLen(r)})
+		}
+		return group(text, spans, size, overlap)
+	}, nil
+}
+
+// Tokens returns a Chunker of size whitespace-separated tokens, each
+// sharing overlap tokens with the previous chunk. Whitespace tokens
+// approximate model tokens; size chunks well below the model's limit.
+func Tokens(size, overlap int) (Chunker, error) {
+	if err := validate(size, overlap); err != nil {
+		return nil, err
+	}
+	return func(text string) []Chunk {
+		return group(text, words(text), size, overlap)
+	}, nil
+}
+
+// Sentences returns a Chunker of size sentences, each sharing overlap
+// sentences with the previous chunk. A sentence ends at '.', '!', or '?'
+// followed by whitespace, or at a blank line.
+func Sentences(size, overlap int) (Chunker, error) {
+	if err := validate(size, overlap); err != nil {
+		return nil, err
+	}
+	return func(text string) []Chunk {
+		return group(text, sentences(text), size, overlap)
+	}, nil
+}
+
+// Texts returns the text of each chunk, for embedding.
+func Texts(chunks []Chunk) []string {
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.Text
+	}
+	return texts
+}
+
+// ID returns the record ID of a chunk: the source and the chunk index.
+func ID(source string, index int) string {
+	return source + "#" + strconv.Itoa(index)
+}
+
+// Records pairs chunks of source with their vectors as ingestion records,
+// with IDs from ID and the standard metadata fields.
+func Records(source string, chunks []Chunk, vectors [][]float32) ([]ingest.Record, error) {
+	if len(vectors) != len(chunks) {
+		return nil, fmt.Errorf("got %d vectors for %d chunks", len(vectors), len(chunks))
+	}
+	records := make([]ingest.Record, len(chunks))
+	for i, c := range chunks {
+		records[i] = ingest.Record{
+			ID:     ID(source, c.Index),
+			Vector: vectors[i],
+			Metadata: map[string]interface{}{
+				FieldSource:     source,
+				FieldChunkIndex: c.Index,
+				FieldStart:      c.Start,
+				FieldEnd:        c.End,
+				FieldText:       c.Text,
+			},
+		}
+	}
+	return records, nil
+}
+
+// Schema returns the VDML metadata fields chunk records carry, to add to a
+// collection's schema. The source is indexed for filtering.
+func Schema() []*vdml.MetadataField {
+	return []*vdml.MetadataField{
+		{Name: FieldSource, Type: vdml.TypeString, Indexed: true, Required: true},
+		{Name: FieldChunkIndex, Type: vdml.TypeInt, Required: true},
+		{Name: FieldStart, Type: vdml.TypeInt},
+		{Name: FieldEnd, Type: vdml.TypeInt},
+		{Name: FieldText, Type: vdml.TypeString},
+	}
+}
+
+func validate(size, overlap int) error {
+	if size < 1 {
+		return fmt.Errorf("chunk size must be positive: %d", size)
+	}
+	if overlap < 0 || overlap >= size {
+		return fmt.Errorf("overlap must be between 0 and %d: %d", size-1, overlap)
+	}
+	return nil
+}
+
+// span is a unit of text, as byte offsets.
+type span struct {
+	start, end int
+}
+
+// group joins consecutive units into chunks of size units, advancing by
+// size-overlap units.
+func group(text string, units []span, size, overlap int) []Chunk {
+	var chunks []Chunk
+	for i := 0; i < len(units); i += size - overlap {
+		j := min(i+size, len(units))
+		start, end := units[i].start, units[j-1].end
+		chunks = append(chunks, Chunk{Text: text[start:end], Index: len(chunks), Start: start, End: end})
+		if j == len(units) {
+			break
+		}
+	}
+	return chunks
+}
+
+// words returns the whitespace-separated tokens of text.
+func words(text string) []span {
+	var spans []span
+	start := -1
+	for i, r := range text {
+		switch {
+		case unicode.IsSpace(r) && start >= 0:
+			spans = append(spans, span{start, i})
+			start = -1
+		case !unicode.IsSpace(r) && start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, len(text)})
+	}
+	return spans
+}
+
+// sentences returns the sentences of text, without surrounding whitespace.
+func sentences(text string) []span {
+	var spans []span
+	start := -1
+	newlines := 0
+	terminated := false
+	for i, r := range text {
+		if unicode.IsSpace(r) {
+			if r == '\n' {
+				newlines++
+			}
+			if start >= 0 && (terminated || newlines >= 2) {
+				spans = append(spans, span{start, trimEnd(text, start, i)})
+				start = -1
+			}
+			continue
+		}
+		newlines = 0
+		if start < 0 {
+			start = i
+		}
+		terminated = r == '.' || r == '!' || r == '?'
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, trimEnd(text, start, len(text))})
+	}
+	return spans
+}
+
+// trimEnd returns end moved back over trailing whitespace, not before start.
+func trimEnd(text string, start, end int) int {
+	for end > start {
+		r, size := utf8.DecodeLastRuneInString(text[:end])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		end -= size
+	}
+	return end
+}
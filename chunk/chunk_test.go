@@ -0,0 +1,107 @@
+package chunk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokens(t *testing.T) {
+	chunker, err := Tokens(3, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc := "one two  three four\nfive six"
+	chunks := chunker(doc)
+
+	want := []string{"one two  three", "three four\nfive", "five six"}
+	if got := Texts(chunks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	for i, c := range chunks {
+		if c.Index != i || doc[c.Start:c.End] != c.Text {
+			t.Errorf("chunk %d has inconsistent offsets: %+v", i, c)
+		}
+	}
+}
+
+func TestWindow(t *testing.T) {
+	chunker, err := Window(4, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc := "héllo!"
+	chunks := chunker(doc)
+
+	want := []string{"héll", "llo!"}
+	if got := Texts(chunks); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if chunks[1].Start != 3 || chunks[1].End != len(doc) {
+		t.Errorf("expected byte offsets, got %+v", chunks[1])
+	}
+}
+
+func TestSentences(t *testing.T) {
+	chunker, err := Sentences(2, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc := "Vectors are dense. Filters narrow results!  Is 3.5 a float?\n\nHeading without stop\n\nLast one."
+	want := []string{
+		"Vectors are dense. Filters narrow results!",
+		"Filters narrow results!  Is 3.5 a float?",
+		"Is 3.5 a float?\n\nHeading without stop",
+		"Heading without stop\n\nLast one.",
+	}
+	if got := Texts(chunker(doc)); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	if chunks := chunker("  "); len(chunks) != 0 {
+		t.Errorf("expected no chunks for blank text, got %v", chunks)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if _, err := Tokens(0, 0); err == nil {
+		t.Error("expected an error for a zero size")
+	}
+	if _, err := Sentences(3, 3); err == nil {
+		t.Error("expected an error for an overlap as large as the size")
+	}
+	if _, err := Window(3, -1); err == nil {
+		t.Error("expected an error for a negative overlap")
+	}
+}
+
+func TestRecords(t *testing.T) {
+	chunks := []Chunk{{Text: "a b", Index: 0, Start: 0, End: 3}, {Text: "b c", Index: 1, Start: 2, End: 5}}
+	records, err := Records("handbook.md", chunks, [][]float32{{1}, {2}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if records[1].ID != "handbook.md#1" || records[1].Vector[0] != 2 {
+		t.Errorf("unexpected record: %+v", records[1])
+	}
+	want := map[string]interface{}{
+		FieldSource: "handbook.md", FieldChunkIndex: 1, FieldStart: 2, FieldEnd: 5, FieldText: "b c",
+	}
+	if !reflect.DeepEqual(records[1].Metadata, want) {
+		t.Errorf("expected %v, got %v", want, records[1].Metadata)
+	}
+
+	if _, err := Records("x", chunks, [][]float32{{1}}); err == nil {
+		t.Error("expected an error for a vector count mismatch")
+	}
+
+	fields := Schema()
+	for _, name := range []string{FieldSource, FieldChunkIndex, FieldStart, FieldEnd, FieldText} {
+		found := false
+		for _, f := range fields {
+			found = found || f.Name == name
+		}
+		if !found {
+			t.Errorf("expected the schema to declare %s", name)
+		}
+	}
+}
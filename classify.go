@@ -0,0 +1,105 @@
+package vectql
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// ErrForbiddenParamClass is returned when a parameter's class is not allowed
+// where the query uses it.
+var ErrForbiddenParamClass = errors.New("parameter class is forbidden here")
+
+// ParamClass records who controls a parameter value, so a gateway serving
+// language-model or end-user input can keep untrusted text out of positions
+// that select data, such as the namespace.
+type ParamClass string
+
+// Parameter classes. ClassUnclassified is the class of parameters the
+// classifier does not recognize.
+const (
+	ClassUnclassified ParamClass = ""
+	ClassUserText     ParamClass = "user_text"
+	ClassInternalID   ParamClass = "internal_id"
+	ClassVector       ParamClass = "vector"
+	ClassTrusted      ParamClass = "trusted"
+)
+
+// ParamClassifier classifies a parameter at bind time from its spec and the
+// supplied value.
+type ParamClassifier func(spec ParamSpec, value interface{}) ParamClass
+
+// ClassifyByName returns a classifier that looks parameters up in classes.
+// Unlisted vector parameters are ClassVector; other unlisted parameters are
+// ClassUnclassified.
+func ClassifyByName(classes map[string]ParamClass) ParamClassifier {
+	return func(spec ParamSpec, _ interface{}) ParamClass {
+		if class, ok := classes[spec.Name]; ok {
+			return class
+		}
+		if spec.Type == types.ParamVector {
+			return ClassVector
+		}
+		return ClassUnclassified
+	}
+}
+
+// ClassPolicy lists the parameter classes forbidden at each query position.
+// A position is a ParamSource; a parameter used at several positions must be
+// allowed at all of them.
+type ClassPolicy map[ParamSource][]ParamClass
+
+// DefaultClassPolicy keeps user-controlled text, and parameters nobody
+// classified, out of the namespace and out of the IDs a query addresses.
+var DefaultClassPolicy = ClassPolicy{
+	types.SourceNamespace: {ClassUserText, ClassUnclassified},
+	types.SourceID:        {ClassUserText, ClassUnclassified},
+	types.SourceRecordID:  {ClassUserText},
+}
+
+// WithParamClassifier classifies every supplied parameter before it is bound
+// and fails with ErrForbiddenParamClass if policy forbids a parameter's class
+// at the position it is used. Prepare records the classes on
+// Request.ParamClasses for audits. A nil policy only classifies.
+func WithParamClassifier(classify ParamClassifier, policy ClassPolicy) BindOption {
+	return func(c *bindConfig) {
+		c.classify = classify
+		c.classPolicy = policy
+	}
+}
+
+// classifyParams classifies the supplied parameters of result and enforces
+// the configured policy. It returns nil when no classifier is configured.
+func classifyParams(result *types.QueryResult, params map[string]interface{}, cfg *bindConfig) (map[string]ParamClass, error) {
+	if cfg.classify == nil {
+		return nil, nil
+	}
+	classes := make(map[string]ParamClass)
+	for _, spec := range result.ParamSpec() {
+		value, ok := params[spec.Name]
+		if !ok {
+			continue
+		}
+		class := cfg.classify(spec, value)
+		sources := spec.Sources
+		if len(sources) == 0 {
+			sources = []ParamSource{spec.Source}
+		}
+		for _, source := range sources {
+			if slices.Contains(cfg.classPolicy[source], class) {
+				return nil, fmt.Errorf("%w: parameter %s is %s, used as %s", ErrForbiddenParamClass, spec.Name, classLabel(class), source)
+			}
+		}
+		classes[spec.Name] = class
+	}
+	return classes, nil
+}
+
+func classLabel(class ParamClass) string {
+	if class == ClassUnclassified {
+		return "unclassified"
+	}
+	return string(class)
+}
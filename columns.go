@@ -0,0 +1,100 @@
+package vectql
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zoobzio/vdml"
+)
+
+// ColumnKind identifies what a record column holds.
+type ColumnKind string
+
+// Column kinds.
+const (
+	ColumnID       ColumnKind = "id"
+	ColumnVector   ColumnKind = "vector"
+	ColumnMetadata ColumnKind = "metadata"
+)
+
+// IDColumn is the name of the record ID column.
+const IDColumn = "id"
+
+// Column describes one column of a collection's records in a columnar layout,
+// such as an Arrow IPC stream or a Parquet file.
+type Column struct {
+	// Name is "id", the embedding name, or the metadata field name.
+	Name string
+	Kind ColumnKind
+
+	// ArrowType is the Arrow logical type in Arrow's own notation, e.g.
+	// "utf8", "int64", or "fixed_size_list<item: float>[384]".
+	ArrowType string
+
+	// Dimensions is the vector size for vector columns.
+	Dimensions int
+
+	// Nullable reports whether records may omit the column. Only metadata
+	// columns are nullable.
+	Nullable bool
+}
+
+// arrowTypes maps VDML metadata types to Arrow logical types.
+var arrowTypes = map[vdml.MetadataType]string{
+	vdml.TypeString:      "utf8",
+	vdml.TypeInt:         "int64",
+	vdml.TypeFloat:       "double",
+	vdml.TypeBool:        "bool",
+	vdml.TypeStringArray: "list<item: utf8>",
+	vdml.TypeIntArray:    "list<item: int64>",
+	vdml.TypeFloatArray:  "list<item: double>",
+}
+
+// RecordColumns returns the columnar layout of a collection's records: the ID
+// column, one vector column per embedding, and one column per metadata field.
+// Embeddings and metadata fields are sorted by name so the layout is stable
+// across exports.
+func (v *VECTQL) RecordColumns(collectionName string) ([]Column, error) {
+	collEmbs, ok := v.embeddings[collectionName]
+	if !ok {
+		return nil, fmt.Errorf("collection '%s' not found", collectionName)
+	}
+	collMeta := v.metadata[collectionName]
+
+	columns := []Column{{Name: IDColumn, Kind: ColumnID, ArrowType: "utf8"}}
+
+	embNames := make([]string, 0, len(collEmbs))
+	for name := range collEmbs {
+		embNames = append(embNames, name)
+	}
+	sort.Strings(embNames)
+	for _, name := range embNames {
+		dims := collEmbs[name].Dimensions
+		columns = append(columns, Column{
+			Name:       name,
+			Kind:       ColumnVector,
+			ArrowType:  fmt.Sprintf("fixed_size_list<item: float>[%d]", dims),
+			Dimensions: dims,
+		})
+	}
+
+	metaNames := make([]string, 0, len(collMeta))
+	for name := range collMeta {
+		metaNames = append(metaNames, name)
+	}
+	sort.Strings(metaNames)
+	for _, name := range metaNames {
+		arrowType, ok := arrowTypes[collMeta[name].Type]
+		if !ok {
+			return nil, fmt.Errorf("metadata field '%s' has unsupported type %s", name, collMeta[name].Type)
+		}
+		columns = append(columns, Column{
+			Name:      name,
+			Kind:      ColumnMetadata,
+			ArrowType: arrowType,
+			Nullable:  true,
+		})
+	}
+
+	return columns, nil
+}
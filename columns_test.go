@@ -0,0 +1,52 @@
+package vectql
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vdml"
+)
+
+func TestRecordColumns(t *testing.T) {
+	schema := testSchema()
+	products := schema.Collections["products"]
+	products.Embeddings = append(products.Embeddings, &vdml.Embedding{Name: "image", Dimensions: 512, Metric: vdml.Cosine})
+	products.Metadata = append(products.Metadata, &vdml.MetadataField{Name: "tags", Type: vdml.TypeStringArray})
+
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	columns, err := v.RecordColumns("products")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Column{
+		{Name: "id", Kind: ColumnID, ArrowType: "utf8"},
+		{Name: "description", Kind: ColumnVector, ArrowType: "fixed_size_list<item: float>[384]", Dimensions: 384},
+		{Name: "image", Kind: ColumnVector, ArrowType: "fixed_size_list<item: float>[512]", Dimensions: 512},
+		{Name: "category", Kind: ColumnMetadata, ArrowType: "utf8", Nullable: true},
+		{Name: "location", Kind: ColumnMetadata, ArrowType: "utf8", Nullable: true},
+		{Name: "price", Kind: ColumnMetadata, ArrowType: "double", Nullable: true},
+		{Name: "tags", Kind: ColumnMetadata, ArrowType: "list<item: utf8>", Nullable: true},
+	}
+	if len(columns) != len(expected) {
+		t.Fatalf("expected %d columns, got %d: %+v", len(expected), len(columns), columns)
+	}
+	for i := range expected {
+		if columns[i] != expected[i] {
+			t.Errorf("column %d: expected %+v, got %+v", i, expected[i], columns[i])
+		}
+	}
+}
+
+func TestRecordColumns_UnknownCollection(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := v.RecordColumns("missing"); err == nil {
+		t.Fatal("expected error for unknown collection")
+	}
+}
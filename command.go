@@ -0,0 +1,36 @@
+package vectql
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// BindCommands resolves the arguments of a rendered command plan against
+// params. Each returned command is its name followed by its argument
+// values, ready for a Redis client's Do. Arguments are bound as by
+// BindArgs.
+func BindCommands(commands []Command, params map[string]interface{}, opts ...BindOption) ([][]interface{}, error) {
+	bound := make([][]interface{}, len(commands))
+	for i, cmd := range commands {
+		values, err := BindArgs(cmd.Args, params, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", cmd.Name, err)
+		}
+		bound[i] = append([]interface{}{cmd.Name}, values...)
+	}
+	return bound, nil
+}
+
+// vectorBlob writes a dense vector as little-endian float32 bytes.
+func vectorBlob(v interface{}) ([]byte, error) {
+	floats, err := float32s(v, "encode")
+	if err != nil {
+		return nil, err
+	}
+	blob := make([]byte, 4*len(floats))
+	for i, f := range floats {
+		binary.LittleEndian.PutUint32(blob[4*i:], math.Float32bits(f))
+	}
+	return blob, nil
+}
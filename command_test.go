@@ -0,0 +1,39 @@
+package vectql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestBindCommands(t *testing.T) {
+	commands := []Command{
+		{Name: "FT.SEARCH", Args: []Arg{
+			{Value: "idx", Kind: types.ArgScalar},
+			{Value: "vec", Kind: types.ArgScalar},
+			{Param: "vec", Kind: types.ArgBlob},
+		}},
+		{Name: "DEL", Args: []Arg{{Param: "id"}}},
+	}
+	params := map[string]interface{}{
+		"vec": []float32{1, -2},
+		"id":  "doc:1",
+	}
+
+	bound, err := BindCommands(commands, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := [][]interface{}{
+		{"FT.SEARCH", "idx", "vec", []byte{0x00, 0x00, 0x80, 0x3f, 0x00, 0x00, 0x00, 0xc0}},
+		{"DEL", "doc:1"},
+	}
+	if !reflect.DeepEqual(bound, want) {
+		t.Errorf("expected %v, got %v", want, bound)
+	}
+
+	if _, err := BindCommands(commands, map[string]interface{}{"vec": "text"}); err == nil {
+		t.Error("expected an error for a vector that cannot be encoded")
+	}
+}
@@ -0,0 +1,124 @@
+package vectql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// DefaultCompressMinBytes is the body size below which requests are sent
+// uncompressed; small bodies gain nothing from compression.
+const DefaultCompressMinBytes = 1024
+
+// Compressor encodes request bodies for one HTTP Content-Encoding.
+type Compressor interface {
+	// Encoding is the Content-Encoding token, e.g. "gzip" or "zstd".
+	Encoding() string
+
+	// Compress returns the encoded body.
+	Compress(body []byte) ([]byte, error)
+}
+
+// funcCompressor adapts a function to Compressor.
+type funcCompressor struct {
+	encoding string
+	fn       func([]byte) ([]byte, error)
+}
+
+func (c funcCompressor) Encoding() string { return c.encoding }
+
+func (c funcCompressor) Compress(body []byte) ([]byte, error) { return c.fn(body) }
+
+// NewCompressor adapts fn to a Compressor for encoding. Use it to plug in
+// codecs the standard library lacks, such as zstd.
+func NewCompressor(encoding string, fn func(body []byte) ([]byte, error)) Compressor {
+	return funcCompressor{encoding: encoding, fn: fn}
+}
+
+// Gzip returns a gzip Compressor at level, one of the compress/gzip levels.
+func Gzip(level int) Compressor {
+	return NewCompressor("gzip", func(body []byte) ([]byte, error) {
+		var buf bytes.Buffer
+		w, err := gzip.NewWriterLevel(&buf, level)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	})
+}
+
+// CompressOption configures Compress.
+type CompressOption func(*compressor)
+
+// WithCompressMinBytes sets the body size below which requests are sent
+// uncompressed.
+func WithCompressMinBytes(n int) CompressOption {
+	return func(c *compressor) {
+		c.minBytes = n
+	}
+}
+
+// WithCompressProviders further limits compression to requests for the
+// named providers, for deployments where a proxy in front of some providers
+// does not pass compressed bodies through.
+func WithCompressProviders(providers ...string) CompressOption {
+	return func(c *compressor) {
+		c.providers = providers
+	}
+}
+
+type compressor struct {
+	next      Executor
+	codec     Compressor
+	minBytes  int
+	providers []string
+}
+
+// Compress returns an executor that compresses request bodies with codec
+// before sending them to next, and records the encoding in
+// Request.ContentEncoding for the transport to send as the Content-Encoding
+// header. Only requests whose provider accepts the codec's encoding, as
+// listed in Request.ContentEncodings, are compressed. Bodies below the
+// minimum size, already encoded bodies, and bodies that do not shrink are
+// sent as they are. The caller's request is not modified.
+func Compress(next Executor, codec Compressor, opts ...CompressOption) Executor {
+	c := &compressor{next: next, codec: codec, minBytes: DefaultCompressMinBytes}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Execute compresses req when it qualifies and sends it to the next executor.
+func (c *compressor) Execute(ctx context.Context, req *Request) (*Response, error) {
+	if req.ContentEncoding != "" || len(req.Body) < c.minBytes {
+		return c.next.Execute(ctx, req)
+	}
+	if !slices.Contains(req.ContentEncodings, c.codec.Encoding()) {
+		return c.next.Execute(ctx, req)
+	}
+	if len(c.providers) > 0 && !slices.Contains(c.providers, req.Provider) {
+		return c.next.Execute(ctx, req)
+	}
+
+	encoded, err := c.codec.Compress([]byte(req.Body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compress request: %w", err)
+	}
+	if len(encoded) >= len(req.Body) {
+		return c.next.Execute(ctx, req)
+	}
+
+	compressed := *req
+	compressed.Body = string(encoded)
+	compressed.ContentEncoding = c.codec.Encoding()
+	return c.next.Execute(ctx, &compressed)
+}
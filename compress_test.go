@@ -0,0 +1,156 @@
+package vectql
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+// captureExecutor records the last request it receives.
+type captureExecutor struct {
+	req *Request
+}
+
+func (c *captureExecutor) Execute(_ context.Context, req *Request) (*Response, error) {
+	c.req = req
+	return &Response{}, nil
+}
+
+func TestCompress_Gzip(t *testing.T) {
+	capture := &captureExecutor{}
+	exec := Compress(capture, Gzip(gzip.BestSpeed))
+
+	body := strings.Repeat(`{"vector":[0.1,0.2,0.3]}`, 200)
+	req := &Request{Provider: "qdrant", Body: body, ContentEncodings: []string{"gzip"}}
+	if _, err := exec.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capture.req.ContentEncoding != "gzip" {
+		t.Fatalf("expected gzip encoding, got %q", capture.req.ContentEncoding)
+	}
+	if len(capture.req.Body) >= len(body) {
+		t.Errorf("expected compressed body smaller than %d, got %d", len(body), len(capture.req.Body))
+	}
+
+	r, err := gzip.NewReader(strings.NewReader(capture.req.Body))
+	if err != nil {
+		t.Fatalf("invalid gzip body: %v", err)
+	}
+	decoded, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("invalid gzip body: %v", err)
+	}
+	if string(decoded) != body {
+		t.Error("decompressed body does not match the original")
+	}
+
+	if req.ContentEncoding != "" || req.Body != body {
+		t.Error("expected the caller's request to be unchanged")
+	}
+}
+
+func TestCompress_SkipsSmallBodies(t *testing.T) {
+	capture := &captureExecutor{}
+	exec := Compress(capture, Gzip(gzip.DefaultCompression))
+
+	req := &Request{Body: `{"topK":3}`}
+	if _, err := exec.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if capture.req != req {
+		t.Error("expected small body to pass through unchanged")
+	}
+}
+
+func TestCompress_Providers(t *testing.T) {
+	capture := &captureExecutor{}
+	exec := Compress(capture, Gzip(gzip.DefaultCompression), WithCompressProviders("qdrant"), WithCompressMinBytes(0))
+
+	body := strings.Repeat("a", 512)
+	gzipped := []string{"gzip"}
+	if _, err := exec.Execute(context.Background(), &Request{Provider: "pinecone", Body: body, ContentEncodings: gzipped}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if capture.req.ContentEncoding != "" {
+		t.Errorf("expected pinecone request uncompressed, got %q", capture.req.ContentEncoding)
+	}
+
+	if _, err := exec.Execute(context.Background(), &Request{Provider: "qdrant", Body: body, ContentEncodings: gzipped}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if capture.req.ContentEncoding != "gzip" {
+		t.Errorf("expected qdrant request compressed, got %q", capture.req.ContentEncoding)
+	}
+}
+
+func TestCompress_ProviderEncodings(t *testing.T) {
+	capture := &captureExecutor{}
+	exec := Compress(capture, Gzip(gzip.DefaultCompression), WithCompressMinBytes(0))
+
+	// Prepare records the encodings the renderer declares
+	coll := types.Collection{Name: "products"}
+	query := Search(coll).Vector(Vec(types.Param{Name: "query"})).TopK(5)
+	req, err := Prepare(query, qdrant.New(), map[string]interface{}{"query": []float32{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Contains(req.ContentEncodings, "gzip") {
+		t.Errorf("expected qdrant to accept gzip, got %v", req.ContentEncodings)
+	}
+	req.Body = strings.Repeat(req.Body, 20)
+	if _, err := exec.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if capture.req.ContentEncoding != "gzip" {
+		t.Errorf("expected qdrant request compressed, got %q", capture.req.ContentEncoding)
+	}
+
+	// Providers that declare no encodings get plain bodies
+	req = &Request{Provider: "pinecone", Body: strings.Repeat("a", 512)}
+	if _, err := exec.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if capture.req != req {
+		t.Error("expected the request to pass through uncompressed")
+	}
+}
+
+func TestCompress_CustomCodec(t *testing.T) {
+	capture := &captureExecutor{}
+	codec := NewCompressor("zstd", func(body []byte) ([]byte, error) {
+		return bytes.ToUpper(body[:len(body)/2]), nil
+	})
+	exec := Compress(capture, codec, WithCompressMinBytes(0))
+
+	if _, err := exec.Execute(context.Background(), &Request{Body: "abcdef", ContentEncodings: []string{"zstd"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if capture.req.ContentEncoding != "zstd" || capture.req.Body != "ABC" {
+		t.Errorf("expected zstd body ABC, got %q %q", capture.req.ContentEncoding, capture.req.Body)
+	}
+}
+
+func TestCompress_Errors(t *testing.T) {
+	capture := &captureExecutor{}
+	failing := NewCompressor("zstd", func([]byte) ([]byte, error) {
+		return nil, errors.New("boom")
+	})
+	exec := Compress(capture, failing, WithCompressMinBytes(0))
+
+	_, err := exec.Execute(context.Background(), &Request{Body: "abc", ContentEncodings: []string{"zstd"}})
+	if err == nil || !strings.Contains(err.Error(), "failed to compress request") {
+		t.Fatalf("expected compression error, got %v", err)
+	}
+	if capture.req != nil {
+		t.Error("expected request not to be sent")
+	}
+}
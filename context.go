@@ -0,0 +1,68 @@
+package vectql
+
+import (
+	"context"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// ContextRenderer is implemented by renderers that read the query context,
+// e.g. to record trace IDs or to render per-tenant settings. Builder.Render
+// calls RenderContext instead of Render when the renderer implements it.
+type ContextRenderer interface {
+	Renderer
+
+	// RenderContext converts a VectorAST to a provider-specific QueryResult.
+	RenderContext(ctx context.Context, ast *types.VectorAST) (*types.QueryResult, error)
+}
+
+// renderContext renders ast with r, passing ctx to context-aware renderers.
+// Snapshot reads are rejected for renderers that cannot honor them.
+func renderContext(ctx context.Context, r Renderer, ast *types.VectorAST) (*types.QueryResult, error) {
+	if err := checkSnapshot(r, ast); err != nil {
+		return nil, err
+	}
+	if cr, ok := r.(ContextRenderer); ok {
+		return cr.RenderContext(ctx, ast)
+	}
+	return r.Render(ast)
+}
+
+// WithContext attaches ctx to the query. It is passed to context-aware
+// renderers by Render and to the executor by Execute.
+func (b *Builder) WithContext(ctx context.Context) *Builder {
+	b.ctx = ctx
+	return b
+}
+
+// Context returns the query context, or context.Background if none is set.
+func (b *Builder) Context() context.Context {
+	if b.ctx == nil {
+		return context.Background()
+	}
+	return b.ctx
+}
+
+// Execute prepares the query with r and params and sends it to executor
+// under the query context.
+func (b *Builder) Execute(executor Executor, r Renderer, params map[string]interface{}, opts ...BindOption) (*Response, error) {
+	req, err := Prepare(b, r, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return executor.Execute(b.Context(), req)
+}
+
+type claimsKey struct{}
+
+// ContextWithClaims returns a copy of ctx carrying the caller's claims, so
+// renderers, executors, and redaction can read them without extra plumbing.
+func ContextWithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims attached by ContextWithClaims.
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(Claims)
+	return claims, ok
+}
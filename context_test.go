@@ -0,0 +1,83 @@
+package vectql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+type traceKey struct{}
+
+// contextRenderer records the trace ID and claims seen while rendering.
+type contextRenderer struct {
+	*stubRenderer
+	trace  interface{}
+	claims Claims
+}
+
+func (r *contextRenderer) RenderContext(ctx context.Context, ast *types.VectorAST) (*types.QueryResult, error) {
+	r.trace = ctx.Value(traceKey{})
+	r.claims, _ = ClaimsFromContext(ctx)
+	return r.Render(ast)
+}
+
+func contextSearch() *Builder {
+	return Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(5)
+}
+
+func TestBuilder_WithContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), traceKey{}, "trace-1")
+	ctx = ContextWithClaims(ctx, Scopes{"tenant:acme": true})
+	renderer := &contextRenderer{stubRenderer: newStubRenderer()}
+
+	if _, err := contextSearch().WithContext(ctx).Render(renderer); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if renderer.trace != "trace-1" {
+		t.Errorf("expected trace ID in render context, got %v", renderer.trace)
+	}
+	if renderer.claims == nil || !renderer.claims.HasScope("tenant:acme") {
+		t.Errorf("expected claims in render context, got %v", renderer.claims)
+	}
+}
+
+func TestBuilder_WithContextThroughRouter(t *testing.T) {
+	ctx := context.WithValue(context.Background(), traceKey{}, "trace-2")
+	renderer := &contextRenderer{stubRenderer: newStubRenderer()}
+
+	if _, err := contextSearch().WithContext(ctx).Render(NewRouter(renderer)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if renderer.trace != "trace-2" {
+		t.Errorf("expected router to pass the context, got %v", renderer.trace)
+	}
+}
+
+func TestBuilder_Execute(t *testing.T) {
+	ctx := context.WithValue(context.Background(), traceKey{}, "trace-3")
+	var seen interface{}
+	executor := ExecutorFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		seen = ctx.Value(traceKey{})
+		return &Response{}, nil
+	})
+
+	_, err := contextSearch().WithContext(ctx).Execute(executor, newStubRenderer(), map[string]interface{}{"query_vec": []float32{1}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if seen != "trace-3" {
+		t.Errorf("expected executor to receive the query context, got %v", seen)
+	}
+}
+
+func TestBuilder_ContextDefault(t *testing.T) {
+	if contextSearch().Context() != context.Background() {
+		t.Error("expected background context by default")
+	}
+	if _, ok := ClaimsFromContext(context.Background()); ok {
+		t.Error("expected no claims on a bare context")
+	}
+}
@@ -0,0 +1,94 @@
+package vectql
+
+import (
+	"context"
+	"math"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// DegradationProfile is a named set of SEARCH rewrites that trade result
+// quality for provider load. Middleware activates a profile under load
+// shedding by attaching it to the request context with
+// ContextWithDegradation; queries rendered with that context are rewritten
+// and annotated with the profile name. Other operations are unaffected.
+type DegradationProfile struct {
+	// Name identifies the profile in QueryResult.Degradation and
+	// Request.Degradation.
+	Name string
+
+	// TopKFactor scales static TopK values, keeping at least one result.
+	// Zero keeps them. TopK values bound from parameters are not scaled.
+	TopKFactor float64
+
+	// SkipReranking drops ranking boosts, scoring expressions, and the
+	// rescoring of quantized candidates.
+	SkipReranking bool
+
+	// DisableHybrid turns hybrid searches into plain vector searches by
+	// dropping their keyword query.
+	DisableHybrid bool
+}
+
+// Reduced halves TopK, skips reranking, and disables hybrid search.
+var Reduced = DegradationProfile{
+	Name:          "reduced",
+	TopKFactor:    0.5,
+	SkipReranking: true,
+	DisableHybrid: true,
+}
+
+type degradationKey struct{}
+
+// ContextWithDegradation returns a copy of ctx that activates profile for
+// queries rendered with it; see Builder.WithContext.
+func ContextWithDegradation(ctx context.Context, profile DegradationProfile) context.Context {
+	return context.WithValue(ctx, degradationKey{}, profile)
+}
+
+// DegradationFromContext returns the profile attached by
+// ContextWithDegradation.
+func DegradationFromContext(ctx context.Context) (DegradationProfile, bool) {
+	profile, ok := ctx.Value(degradationKey{}).(DegradationProfile)
+	return profile, ok
+}
+
+// Apply returns ast rewritten by the profile. Operations other than SEARCH
+// are returned unchanged; otherwise a rewritten copy is returned.
+func (p DegradationProfile) Apply(ast *types.VectorAST) *types.VectorAST {
+	if ast.Operation != types.OpSearch {
+		return ast
+	}
+	degraded := *ast
+	if p.TopKFactor > 0 && ast.TopK != nil && ast.TopK.Static != nil {
+		k := int(math.Ceil(float64(*ast.TopK.Static) * p.TopKFactor))
+		if k < 1 {
+			k = 1
+		}
+		degraded.TopK = &types.PaginationValue{Static: &k}
+	}
+	if p.SkipReranking {
+		degraded.Boosts = nil
+		degraded.Scoring = nil
+		if ast.Quantization != nil && ast.Quantization.Rescore {
+			quantization := *ast.Quantization
+			quantization.Rescore = false
+			quantization.Oversampling = 0
+			degraded.Quantization = &quantization
+		}
+	}
+	if p.DisableHybrid && ast.Modality() == types.ModalityHybrid {
+		degraded.TextQuery = nil
+	}
+	return &degraded
+}
+
+// degrade applies the profile active in ctx to ast, returning the profile
+// name, or ast and an empty name when none is active.
+func degrade(ctx context.Context, ast *types.VectorAST) (*types.VectorAST, string) {
+	profile, ok := DegradationFromContext(ctx)
+	if !ok || ast.Operation != types.OpSearch {
+		return ast, ""
+	}
+	return profile.Apply(ast), profile.Name
+}
@@ -0,0 +1,76 @@
+package vectql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestDegradationProfile_Apply(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+	inStock := types.FilterCondition{Field: types.MetadataField{Name: "in_stock"}, Operator: types.EQ, Value: types.Param{Name: "in_stock"}}
+	ast, err := Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		Text(types.Param{Name: "q"}).
+		Boost(inStock, 2).
+		Rescore(2).
+		TopK(5).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	degraded := Reduced.Apply(ast)
+	if *degraded.TopK.Static != 3 {
+		t.Errorf("expected TopK 3, got %d", *degraded.TopK.Static)
+	}
+	if degraded.Boosts != nil || degraded.Quantization.Rescore || degraded.Quantization.Oversampling != 0 {
+		t.Errorf("expected reranking to be skipped, got boosts %v and quantization %#v", degraded.Boosts, degraded.Quantization)
+	}
+	if degraded.Modality() != types.ModalityVector {
+		t.Errorf("expected a vector search, got %s", degraded.Modality())
+	}
+	if *ast.TopK.Static != 5 || len(ast.Boosts) != 1 || !ast.Quantization.Rescore || ast.TextQuery == nil {
+		t.Error("expected the original AST to be unchanged")
+	}
+
+	one := DegradationProfile{TopKFactor: 0.1}.Apply(ast)
+	if *one.TopK.Static != 1 {
+		t.Errorf("expected TopK to keep at least one result, got %d", *one.TopK.Static)
+	}
+
+	del, err := Delete(coll).IDs(types.Param{Name: "id"}).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if Reduced.Apply(del) != del {
+		t.Error("expected non-search operations to be unchanged")
+	}
+}
+
+func TestPrepare_Degradation(t *testing.T) {
+	query := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10)
+	params := map[string]interface{}{"v": []float32{1, 0}}
+
+	req, err := Prepare(query, qdrant.New(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Degradation != "" || !strings.Contains(req.Body, `"limit":10`) {
+		t.Errorf("expected an undegraded request, got %q: %s", req.Degradation, req.Body)
+	}
+
+	ctx := ContextWithDegradation(context.Background(), Reduced)
+	req, err = Prepare(query.WithContext(ctx), qdrant.New(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Degradation != "reduced" || !strings.Contains(req.Body, `"limit":5`) {
+		t.Errorf("expected a reduced request, got %q: %s", req.Degradation, req.Body)
+	}
+}
@@ -0,0 +1,144 @@
+package vectql
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// RecordDiff classifies incoming records against stored records by content
+// hash. Each list holds record IDs in sorted order.
+type RecordDiff struct {
+	// New records are not stored yet.
+	New []string
+
+	// Changed records are stored with a different hash.
+	Changed []string
+
+	// Unchanged records are stored with the same hash and need no write.
+	Unchanged []string
+
+	// Removed records are stored but no longer incoming.
+	Removed []string
+}
+
+// DiffRecords compares the content hashes of stored records with those of
+// incoming records, both keyed by record ID. existing is typically read from
+// a content hash metadata field with ContentHashes. Removed is only
+// meaningful when existing covers everything the incoming set replaces, such
+// as every chunk stored for one source document.
+func DiffRecords(existing, incoming map[string]string) *RecordDiff {
+	d := &RecordDiff{}
+	for id, hash := range incoming {
+		stored, ok := existing[id]
+		switch {
+		case !ok:
+			d.New = append(d.New, id)
+		case stored != hash:
+			d.Changed = append(d.Changed, id)
+		default:
+			d.Unchanged = append(d.Unchanged, id)
+		}
+	}
+	for id := range existing {
+		if _, ok := incoming[id]; !ok {
+			d.Removed = append(d.Removed, id)
+		}
+	}
+	sort.Strings(d.New)
+	sort.Strings(d.Changed)
+	sort.Strings(d.Unchanged)
+	sort.Strings(d.Removed)
+	return d
+}
+
+// ContentHashes reads the content hash of each match from its field
+// metadata value. Matches without a string hash map to the empty hash, so
+// they are reported as changed.
+func ContentHashes(matches []Match, field string) map[string]string {
+	hashes := make(map[string]string, len(matches))
+	for _, m := range matches {
+		hash, _ := m.Metadata[field].(string)
+		hashes[m.ID] = hash
+	}
+	return hashes
+}
+
+// SyncPlan holds the writes that bring stored records in line with the
+// incoming ones.
+type SyncPlan struct {
+	// Upsert writes the new and changed records. It is nil when there are
+	// none.
+	Upsert *Builder
+
+	// Deletes remove the removed records, at most MaxIDsPerFetch IDs each.
+	Deletes []*Builder
+
+	// Params binds both the upsert and the deletes: the upsert parameters
+	// plus one parameter per removed ID.
+	Params map[string]interface{}
+}
+
+// deleteParamPrefix names the ID parameters of planned deletes.
+const deleteParamPrefix = "diff_removed_"
+
+// Plan narrows upsert, an UPSERT of every incoming record bound by params,
+// to the new and changed records, and plans deletes for the removed ones
+// in upsert's collection and namespace. Record IDs must be bound to string
+// values in params.
+func (d *RecordDiff) Plan(upsert *Builder, params map[string]interface{}) (*SyncPlan, error) {
+	ast, err := upsert.Build()
+	if err != nil {
+		return nil, err
+	}
+	if ast.Operation != types.OpUpsert {
+		return nil, fmt.Errorf("sync plans start from an UPSERT, got %s", ast.Operation)
+	}
+
+	write := make(map[string]bool, len(d.New)+len(d.Changed))
+	for _, id := range d.New {
+		write[id] = true
+	}
+	for _, id := range d.Changed {
+		write[id] = true
+	}
+
+	plan := &SyncPlan{Params: make(map[string]interface{}, len(params)+len(d.Removed))}
+	for name, value := range params {
+		plan.Params[name] = value
+	}
+
+	var records []types.VectorRecord
+	for _, record := range ast.Vectors {
+		id, ok := params[record.ID.Name].(string)
+		if !ok {
+			return nil, fmt.Errorf("record ID parameter %s is not bound to a string", record.ID.Name)
+		}
+		if write[id] {
+			records = append(records, record)
+		}
+	}
+	if len(records) > 0 {
+		plan.Upsert = upsert.withVectors(records)
+	}
+
+	for start := 0; start < len(d.Removed); start += types.MaxIDsPerFetch {
+		end := min(start+types.MaxIDsPerFetch, len(d.Removed))
+		ids := make([]types.Param, 0, end-start)
+		for i := start; i < end; i++ {
+			p := types.Param{Name: fmt.Sprintf("%s%d", deleteParamPrefix, i)}
+			if _, taken := params[p.Name]; taken {
+				return nil, fmt.Errorf("parameter %s is reserved for planned deletes", p.Name)
+			}
+			plan.Params[p.Name] = d.Removed[i]
+			ids = append(ids, p)
+		}
+		del := Delete(ast.Target).IDs(ids...)
+		if ast.Namespace != nil {
+			del.Namespace(*ast.Namespace)
+		}
+		plan.Deletes = append(plan.Deletes, del)
+	}
+	return plan, nil
+}
@@ -0,0 +1,90 @@
+package vectql
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestDiffRecords(t *testing.T) {
+	existing := ContentHashes([]Match{
+		{ID: "id_0", Metadata: map[string]interface{}{"hash": "h0"}},
+		{ID: "id_1", Metadata: map[string]interface{}{"hash": "old"}},
+		{ID: "id_2"},
+		{ID: "gone", Metadata: map[string]interface{}{"hash": "hx"}},
+	}, "hash")
+	incoming := map[string]string{"id_0": "h0", "id_1": "h1", "id_2": "h2", "id_3": "h3"}
+
+	d := DiffRecords(existing, incoming)
+	want := &RecordDiff{
+		New:       []string{"id_3"},
+		Changed:   []string{"id_1", "id_2"},
+		Unchanged: []string{"id_0"},
+		Removed:   []string{"gone"},
+	}
+	if !reflect.DeepEqual(d, want) {
+		t.Errorf("expected %+v, got %+v", want, d)
+	}
+}
+
+func TestRecordDiffPlan(t *testing.T) {
+	upsert, params := upsertBatch(4, 3)
+	upsert.Namespace(types.Param{Name: "ns"})
+	params["ns"] = "tenant-a"
+	d := &RecordDiff{
+		New:       []string{"id_3"},
+		Changed:   []string{"id_1"},
+		Unchanged: []string{"id_0", "id_2"},
+		Removed:   []string{"gone"},
+	}
+
+	plan, err := d.Plan(upsert, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ast, err := plan.Upsert.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ast.Vectors) != 2 || ast.Vectors[0].ID.Name != "id_1" || ast.Vectors[1].ID.Name != "id_3" {
+		t.Errorf("expected only the new and changed records, got %+v", ast.Vectors)
+	}
+
+	if len(plan.Deletes) != 1 {
+		t.Fatalf("expected one delete, got %d", len(plan.Deletes))
+	}
+	del, err := plan.Deletes[0].Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(del.IDs) != 1 || plan.Params[del.IDs[0].Name] != "gone" {
+		t.Errorf("expected the removed ID to be bound, got %+v", del.IDs)
+	}
+	if del.Namespace == nil || del.Namespace.Name != "ns" {
+		t.Errorf("expected the delete to keep the namespace, got %+v", del.Namespace)
+	}
+	if _, ok := params[del.IDs[0].Name]; ok {
+		t.Error("expected the caller's params to be left unchanged")
+	}
+}
+
+func TestRecordDiffPlan_NothingToDo(t *testing.T) {
+	upsert, params := upsertBatch(2, 3)
+	plan, err := (&RecordDiff{Unchanged: []string{"id_0", "id_1"}}).Plan(upsert, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plan.Upsert != nil || len(plan.Deletes) != 0 {
+		t.Errorf("expected an empty plan, got %+v", plan)
+	}
+
+	params["id_0"] = 7
+	if _, err := (&RecordDiff{}).Plan(upsert, params); err == nil {
+		t.Error("expected an error for a non-string record ID")
+	}
+	if _, err := (&RecordDiff{}).Plan(Delete(types.Collection{Name: "products"}), params); err == nil {
+		t.Error("expected an error for a plan that does not start from an UPSERT")
+	}
+}
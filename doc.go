@@ -0,0 +1,322 @@
+package vectql
+
+import (
+	"fmt"
+	"html"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/zoobzio/vdml"
+)
+
+// SchemaDoc is a structured description of a VDML schema for developer
+// portals and generated reference pages. Collections, embeddings, and fields
+// are sorted by name.
+type SchemaDoc struct {
+	Name        string
+	Note        string
+	Collections []CollectionDoc
+}
+
+// CollectionDoc describes one collection.
+type CollectionDoc struct {
+	Name string
+	Note string
+
+	// DefaultEmbedding and PartitionKey are the declared defaults, if any.
+	DefaultEmbedding string
+	PartitionKey     string
+
+	// Settings holds the remaining collection settings, keyed by name.
+	// Per-embedding settings appear on the embedding instead.
+	Settings map[string]string
+
+	Embeddings []EmbeddingDoc
+	Fields     []FieldDoc
+
+	// Compatibility lists one entry per renderer passed to Document.
+	Compatibility []CompatibilityDoc
+}
+
+// EmbeddingDoc describes one embedding.
+type EmbeddingDoc struct {
+	Name          string
+	Note          string
+	Dimensions    int
+	Metric        DistanceMetric
+	Model         EmbeddingModel
+	Normalization string
+	Quantization  Quantization
+}
+
+// FieldDoc describes one metadata field.
+type FieldDoc struct {
+	Name     string
+	Note     string
+	Type     string
+	Indexed  bool
+	Required bool
+}
+
+// CompatibilityDoc notes how well a provider supports a collection.
+type CompatibilityDoc struct {
+	Provider string
+
+	// Notes lists the schema features the provider does not support. A
+	// collection the provider fully supports has none.
+	Notes []string
+}
+
+// Document builds a SchemaDoc for schema. For each renderer, every
+// collection gets compatibility notes listing embeddings the provider cannot
+// store as declared: unsupported metrics, too many dimensions, or more
+// embeddings than it stores per record.
+func Document(schema *vdml.Schema, renderers ...Renderer) (*SchemaDoc, error) {
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &SchemaDoc{Name: schema.Name, Note: note(schema.Note)}
+	for _, name := range v.Collections() {
+		coll := v.collections[name]
+		cdoc := CollectionDoc{
+			Name:             name,
+			Note:             note(coll.Note),
+			DefaultEmbedding: coll.Settings[SettingDefaultEmbedding],
+			PartitionKey:     coll.Settings[SettingPartitionKey],
+			Settings:         make(map[string]string),
+		}
+
+		embNames, _ := v.Embeddings(name)
+		for _, embName := range embNames {
+			e, _ := v.TryE(name, embName)
+			cdoc.Embeddings = append(cdoc.Embeddings, EmbeddingDoc{
+				Name:          embName,
+				Note:          note(v.embeddings[name][embName].Note),
+				Dimensions:    e.Dimensions,
+				Metric:        e.Metric,
+				Model:         e.Model,
+				Normalization: string(e.Normalization),
+				Quantization:  e.Quantization,
+			})
+		}
+
+		for key, value := range coll.Settings {
+			if key == SettingDefaultEmbedding || key == SettingPartitionKey || isEmbeddingSetting(key, embNames) {
+				continue
+			}
+			cdoc.Settings[key] = value
+		}
+
+		for f := range v.IterFields(name) {
+			meta := v.metadata[name][f.Name]
+			cdoc.Fields = append(cdoc.Fields, FieldDoc{
+				Name:     f.Name,
+				Note:     note(meta.Note),
+				Type:     f.Type,
+				Indexed:  meta.Indexed,
+				Required: meta.Required,
+			})
+		}
+
+		for _, r := range renderers {
+			cdoc.Compatibility = append(cdoc.Compatibility, compatibility(cdoc, UpgradeRenderer(r).Capabilities()))
+		}
+		doc.Collections = append(doc.Collections, cdoc)
+	}
+	return doc, nil
+}
+
+func note(n *string) string {
+	if n == nil {
+		return ""
+	}
+	return *n
+}
+
+// isEmbeddingSetting reports whether key is a per-embedding setting such as
+// "description.model".
+func isEmbeddingSetting(key string, embeddings []string) bool {
+	for _, name := range embeddings {
+		if strings.HasPrefix(key, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// maxDimensions holds the largest vector the built-in providers accept.
+// Qdrant does not enforce a limit.
+var maxDimensions = map[string]int{
+	"pinecone": 20000,
+	"milvus":   32768,
+	"weaviate": 65535,
+}
+
+// singleVectorProviders store one dense vector per record, so a collection
+// with several embeddings needs one index per embedding.
+var singleVectorProviders = map[string]bool{
+	"pinecone": true,
+}
+
+func compatibility(c CollectionDoc, caps Capabilities) CompatibilityDoc {
+	compat := CompatibilityDoc{Provider: caps.Provider}
+	if singleVectorProviders[caps.Provider] && len(c.Embeddings) > 1 {
+		compat.Notes = append(compat.Notes, fmt.Sprintf("stores one vector per record; the %d embeddings need separate indexes", len(c.Embeddings)))
+	}
+	for _, e := range c.Embeddings {
+		if e.Metric != "" && !caps.SupportsMetric(e.Metric) {
+			compat.Notes = append(compat.Notes, fmt.Sprintf("embedding '%s' uses unsupported metric %s", e.Name, e.Metric))
+		}
+		if limit := maxDimensions[caps.Provider]; limit > 0 && e.Dimensions > limit {
+			compat.Notes = append(compat.Notes, fmt.Sprintf("embedding '%s' has %d dimensions, limit is %d", e.Name, e.Dimensions, limit))
+		}
+	}
+	return compat
+}
+
+// Markdown renders the documentation as a Markdown page.
+func (d *SchemaDoc) Markdown() string {
+	var b strings.Builder
+	title := d.Name
+	if title == "" {
+		title = "Schema"
+	}
+	fmt.Fprintf(&b, "# %s\n", title)
+	if d.Note != "" {
+		fmt.Fprintf(&b, "\n%s\n", d.Note)
+	}
+
+	for _, c := range d.Collections {
+		fmt.Fprintf(&b, "\n## %s\n", c.Name)
+		if c.Note != "" {
+			fmt.Fprintf(&b, "\n%s\n", c.Note)
+		}
+		if defaults := c.defaults(); len(defaults) > 0 {
+			b.WriteString("\n")
+			for _, line := range defaults {
+				fmt.Fprintf(&b, "- %s\n", line)
+			}
+		}
+
+		if len(c.Embeddings) > 0 {
+			b.WriteString("\n### Embeddings\n\n| Name | Dimensions | Metric | Model | Quantization | Note |\n|---|---|---|---|---|---|\n")
+			for _, e := range c.Embeddings {
+				fmt.Fprintf(&b, "| %s | %d | %s | %s | %s | %s |\n",
+					e.Name, e.Dimensions, e.Metric, e.Model, e.Quantization, markdownCell(e.Note))
+			}
+		}
+
+		if len(c.Fields) > 0 {
+			b.WriteString("\n### Metadata\n\n| Name | Type | Indexed | Required | Note |\n|---|---|---|---|---|\n")
+			for _, f := range c.Fields {
+				fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n",
+					f.Name, markdownCell(f.Type), yesNo(f.Indexed), yesNo(f.Required), markdownCell(f.Note))
+			}
+		}
+
+		if len(c.Compatibility) > 0 {
+			b.WriteString("\n### Provider Compatibility\n\n")
+			for _, compat := range c.Compatibility {
+				if len(compat.Notes) == 0 {
+					fmt.Fprintf(&b, "- **%s**: fully supported\n", compat.Provider)
+					continue
+				}
+				fmt.Fprintf(&b, "- **%s**: %s\n", compat.Provider, strings.Join(compat.Notes, "; "))
+			}
+		}
+	}
+	return b.String()
+}
+
+// HTML renders the documentation as an HTML fragment, for embedding in a
+// portal page. All schema text is escaped.
+func (d *SchemaDoc) HTML() string {
+	var b strings.Builder
+	esc := html.EscapeString
+	title := d.Name
+	if title == "" {
+		title = "Schema"
+	}
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", esc(title))
+	if d.Note != "" {
+		fmt.Fprintf(&b, "<p>%s</p>\n", esc(d.Note))
+	}
+
+	for _, c := range d.Collections {
+		fmt.Fprintf(&b, "<section id=\"%s\">\n<h2>%s</h2>\n", esc(c.Name), esc(c.Name))
+		if c.Note != "" {
+			fmt.Fprintf(&b, "<p>%s</p>\n", esc(c.Note))
+		}
+		if defaults := c.defaults(); len(defaults) > 0 {
+			b.WriteString("<ul>\n")
+			for _, line := range defaults {
+				fmt.Fprintf(&b, "<li>%s</li>\n", esc(line))
+			}
+			b.WriteString("</ul>\n")
+		}
+
+		if len(c.Embeddings) > 0 {
+			b.WriteString("<h3>Embeddings</h3>\n<table>\n<tr><th>Name</th><th>Dimensions</th><th>Metric</th><th>Model</th><th>Quantization</th><th>Note</th></tr>\n")
+			for _, e := range c.Embeddings {
+				fmt.Fprintf(&b, "<tr><td>%s</td><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+					esc(e.Name), e.Dimensions, esc(string(e.Metric)), esc(e.Model.String()), esc(string(e.Quantization)), esc(e.Note))
+			}
+			b.WriteString("</table>\n")
+		}
+
+		if len(c.Fields) > 0 {
+			b.WriteString("<h3>Metadata</h3>\n<table>\n<tr><th>Name</th><th>Type</th><th>Indexed</th><th>Required</th><th>Note</th></tr>\n")
+			for _, f := range c.Fields {
+				fmt.Fprintf(&b, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+					esc(f.Name), esc(f.Type), yesNo(f.Indexed), yesNo(f.Required), esc(f.Note))
+			}
+			b.WriteString("</table>\n")
+		}
+
+		if len(c.Compatibility) > 0 {
+			b.WriteString("<h3>Provider Compatibility</h3>\n<ul>\n")
+			for _, compat := range c.Compatibility {
+				notes := "fully supported"
+				if len(compat.Notes) > 0 {
+					notes = strings.Join(compat.Notes, "; ")
+				}
+				fmt.Fprintf(&b, "<li><strong>%s</strong>: %s</li>\n", esc(compat.Provider), esc(notes))
+			}
+			b.WriteString("</ul>\n")
+		}
+		b.WriteString("</section>\n")
+	}
+	return b.String()
+}
+
+// defaults lists the collection's declared defaults and settings as
+// "name: value" lines.
+func (c CollectionDoc) defaults() []string {
+	var lines []string
+	if c.DefaultEmbedding != "" {
+		lines = append(lines, "Default embedding: "+c.DefaultEmbedding)
+	}
+	if c.PartitionKey != "" {
+		lines = append(lines, "Partition key: "+c.PartitionKey)
+	}
+	for _, key := range slices.Sorted(maps.Keys(c.Settings)) {
+		lines = append(lines, key+": "+c.Settings[key])
+	}
+	return lines
+}
+
+// markdownCell escapes text for a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -0,0 +1,121 @@
+package vectql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func docSchema() *vdml.Schema {
+	schema := testSchema()
+	schema.Name = "catalog"
+	products := schema.Collections["products"]
+	products.Embeddings = append(products.Embeddings, &vdml.Embedding{Name: "image", Dimensions: 25000, Metric: vdml.Euclidean})
+	products.Metadata = append(products.Metadata, &vdml.MetadataField{Name: "tags", Type: vdml.TypeStringArray, Indexed: true})
+	note := "Product | <b>catalog</b>"
+	products.Note = &note
+	products.Settings = map[string]string{
+		SettingDefaultEmbedding:                   "description",
+		"description" + SettingModelSuffix:        "minilm",
+		"description" + SettingQuantizationSuffix: "scalar",
+		SettingShards:                             "2",
+	}
+	return schema
+}
+
+func TestDocument(t *testing.T) {
+	doc, err := Document(docSchema(), pinecone.New(), qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc.Name != "catalog" || len(doc.Collections) != 1 {
+		t.Fatalf("unexpected doc: %+v", doc)
+	}
+
+	c := doc.Collections[0]
+	if c.DefaultEmbedding != "description" || c.Settings[SettingShards] != "2" || len(c.Settings) != 1 {
+		t.Errorf("unexpected defaults: %q %v", c.DefaultEmbedding, c.Settings)
+	}
+	if len(c.Embeddings) != 2 {
+		t.Fatalf("expected 2 embeddings, got %d", len(c.Embeddings))
+	}
+	e := c.Embeddings[0]
+	if e.Dimensions != 384 || e.Metric != MetricCosine || e.Model.Name != "minilm" || e.Quantization != QuantizationScalar {
+		t.Errorf("unexpected embedding doc: %+v", e)
+	}
+
+	var names []string
+	for _, f := range c.Fields {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, ",") != "category,location,price,tags" {
+		t.Errorf("expected sorted fields, got %v", names)
+	}
+	if tags := c.Fields[3]; tags.Type != "[]string" || !tags.Indexed {
+		t.Errorf("unexpected field doc: %+v", tags)
+	}
+
+	if len(c.Compatibility) != 2 {
+		t.Fatalf("expected 2 compatibility entries, got %d", len(c.Compatibility))
+	}
+	if p := c.Compatibility[0]; p.Provider != "pinecone" || len(p.Notes) != 2 ||
+		!strings.Contains(p.Notes[0], "one vector per record") || !strings.Contains(p.Notes[1], "25000 dimensions, limit is 20000") {
+		t.Errorf("expected pinecone vector and dimension notes, got %+v", p)
+	}
+	if q := c.Compatibility[1]; q.Provider != "qdrant" || len(q.Notes) != 0 {
+		t.Errorf("expected qdrant to be fully supported, got %+v", q)
+	}
+}
+
+func TestSchemaDoc_Markdown(t *testing.T) {
+	doc, err := Document(docSchema(), qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	md := doc.Markdown()
+	for _, want := range []string{
+		"# catalog",
+		"## products",
+		"- Default embedding: description",
+		"- shards: 2",
+		"| description | 384 | COSINE | minilm | scalar |",
+		"| tags | []string | yes | no |  |",
+		"- **qdrant**: fully supported",
+		`Product | <b>catalog</b>`,
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected markdown to contain %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestSchemaDoc_HTML(t *testing.T) {
+	doc, err := Document(docSchema(), pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := doc.HTML()
+	if strings.Contains(out, "<b>catalog</b>") {
+		t.Error("expected notes to be escaped")
+	}
+	for _, want := range []string{
+		"<h1>catalog</h1>",
+		`<section id="products">`,
+		"&lt;b&gt;catalog&lt;/b&gt;",
+		"<td>description</td><td>384</td>",
+		"<strong>pinecone</strong>: stores one vector per record",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected HTML to contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDocument_InvalidSchema(t *testing.T) {
+	if _, err := Document(nil); err == nil {
+		t.Error("expected error for nil schema")
+	}
+}
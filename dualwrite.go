@@ -0,0 +1,404 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// ErrSecondaryWrite wraps the error of a dual write that the primary applied
+// but the secondary did not.
+var ErrSecondaryWrite = errors.New("secondary write failed")
+
+// DualWrite sends a write to a primary and a secondary provider concurrently,
+// e.g. while migrating between them, and returns the primary's response. If
+// only the secondary fails, the error wraps ErrSecondaryWrite so callers can
+// keep serving from the primary and leave the repair to a ConsistencyChecker.
+func DualWrite(ctx context.Context, query *Builder, params map[string]interface{}, primary, secondary ShadowTarget, opts ...BindOption) (*Response, error) {
+	ast, err := query.Build()
+	if err != nil {
+		return nil, err
+	}
+	if AccessOf(ast.Operation) != AccessWrite {
+		return nil, fmt.Errorf("dual writes are only available for UPSERT, UPDATE, and DELETE")
+	}
+
+	targets := [2]ShadowTarget{primary, secondary}
+	var requests [2]*Request
+	for i, target := range targets {
+		if target.Renderer == nil || target.Executor == nil {
+			return nil, fmt.Errorf("dual-write target requires a renderer and an executor")
+		}
+		requests[i], err = Prepare(query, target.Renderer, params, opts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		wg        sync.WaitGroup
+		responses [2]*Response
+		errs      [2]error
+	)
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = targets[i].Executor.Execute(ctx, requests[i])
+		}(i)
+	}
+	wg.Wait()
+
+	if errs[0] != nil {
+		return nil, fmt.Errorf("primary write failed: %w", errs[0])
+	}
+	if errs[1] != nil {
+		return responses[0], fmt.Errorf("%w: %w", ErrSecondaryWrite, errs[1])
+	}
+	return responses[0], nil
+}
+
+// Default consistency check settings.
+const (
+	DefaultConsistencySampleSize = 20
+	DefaultRecentWrites          = 1000
+	DefaultVectorTolerance       = 1e-3
+)
+
+// MismatchKind classifies a record that differs between two providers.
+type MismatchKind string
+
+// Mismatch kinds.
+const (
+	MismatchMissingPrimary   MismatchKind = "missing_primary"
+	MismatchMissingSecondary MismatchKind = "missing_secondary"
+	MismatchVector           MismatchKind = "vector"
+	MismatchMetadata         MismatchKind = "metadata"
+)
+
+// ConsistencyMismatch describes one record that differs between providers.
+type ConsistencyMismatch struct {
+	Collection string
+	Namespace  string
+	ID         string
+	Kind       MismatchKind
+
+	// CosineDistance is the distance between the two stored vectors for
+	// vector mismatches.
+	CosineDistance float64
+
+	// Fields lists the differing metadata fields for metadata mismatches.
+	Fields []string
+}
+
+// ConsistencyReport is the result of one consistency check.
+type ConsistencyReport struct {
+	CheckedAt time.Time
+
+	// Checked is the number of sampled records.
+	Checked int
+
+	Mismatches []ConsistencyMismatch
+}
+
+// ConsistencyOption configures a ConsistencyChecker.
+type ConsistencyOption func(*ConsistencyChecker)
+
+// WithSampleSize sets how many recent writes each check verifies.
+func WithSampleSize(n int) ConsistencyOption {
+	return func(c *ConsistencyChecker) {
+		c.sampleSize = n
+	}
+}
+
+// WithRecentWrites sets how many recently written records the checker
+// remembers to sample from.
+func WithRecentWrites(n int) ConsistencyOption {
+	return func(c *ConsistencyChecker) {
+		c.capacity = n
+	}
+}
+
+// WithVectorTolerance sets the cosine distance above which two stored
+// vectors are reported as a mismatch. Providers that quantize or normalize
+// vectors need a looser tolerance than the default.
+func WithVectorTolerance(d float64) ConsistencyOption {
+	return func(c *ConsistencyChecker) {
+		c.tolerance = d
+	}
+}
+
+// writeKey identifies a written record.
+type writeKey struct {
+	collection string
+	namespace  string
+	id         string
+}
+
+// ConsistencyChecker verifies a dual-write migration. It remembers recently
+// written record IDs from write events and periodically fetches a sample from
+// both providers, reporting records that are missing on one side or whose
+// vectors or metadata differ. It is safe for concurrent use.
+type ConsistencyChecker struct {
+	primary    ShadowTarget
+	secondary  ShadowTarget
+	sampleSize int
+	capacity   int
+	tolerance  float64
+
+	mu     sync.Mutex
+	recent []writeKey
+	next   int
+}
+
+// NewConsistencyChecker creates a checker comparing primary and secondary.
+func NewConsistencyChecker(primary, secondary ShadowTarget, opts ...ConsistencyOption) (*ConsistencyChecker, error) {
+	c := &ConsistencyChecker{
+		primary:    primary,
+		secondary:  secondary,
+		sampleSize: DefaultConsistencySampleSize,
+		capacity:   DefaultRecentWrites,
+		tolerance:  DefaultVectorTolerance,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	for _, target := range [2]ShadowTarget{primary, secondary} {
+		if target.Renderer == nil || target.Executor == nil {
+			return nil, fmt.Errorf("consistency target requires a renderer and an executor")
+		}
+	}
+	if c.sampleSize <= 0 || c.sampleSize > MaxIDsPerFetch {
+		return nil, fmt.Errorf("sample size must be between 1 and %d: %d", MaxIDsPerFetch, c.sampleSize)
+	}
+	if c.capacity <= 0 {
+		return nil, fmt.Errorf("recent writes must be positive: %d", c.capacity)
+	}
+	if c.tolerance < 0 {
+		return nil, fmt.Errorf("vector tolerance must not be negative: %v", c.tolerance)
+	}
+	return c, nil
+}
+
+// Observe records the IDs of a write event. It is a WriteHook, so the
+// checker follows a dual write by wrapping the primary executor:
+//
+//	primary.Executor = vectql.CaptureWrites(primary.Executor, checker.Observe)
+func (c *ConsistencyChecker) Observe(_ context.Context, event WriteEvent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range event.IDs {
+		key := writeKey{collection: event.Collection, namespace: event.Namespace, id: id}
+		if len(c.recent) < c.capacity {
+			c.recent = append(c.recent, key)
+			continue
+		}
+		c.recent[c.next] = key
+		c.next = (c.next + 1) % c.capacity
+	}
+}
+
+// sample returns up to sampleSize distinct recent writes.
+func (c *ConsistencyChecker) sample() []writeKey {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seen := make(map[writeKey]bool, c.sampleSize)
+	var keys []writeKey
+	for _, i := range rand.Perm(len(c.recent)) {
+		if len(keys) == c.sampleSize {
+			break
+		}
+		key := c.recent[i]
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Check fetches a sample of recently written records from both providers
+// and compares them.
+func (c *ConsistencyChecker) Check(ctx context.Context) (*ConsistencyReport, error) {
+	keys := c.sample()
+	report := &ConsistencyReport{CheckedAt: time.Now(), Checked: len(keys)}
+
+	groups := make(map[writeKey][]string)
+	for _, key := range keys {
+		group := writeKey{collection: key.collection, namespace: key.namespace}
+		groups[group] = append(groups[group], key.id)
+	}
+	order := make([]writeKey, 0, len(groups))
+	for group := range groups {
+		order = append(order, group)
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if order[i].collection != order[j].collection {
+			return order[i].collection < order[j].collection
+		}
+		return order[i].namespace < order[j].namespace
+	})
+
+	for _, group := range order {
+		mismatches, err := c.compare(ctx, group, groups[group])
+		if err != nil {
+			return nil, err
+		}
+		report.Mismatches = append(report.Mismatches, mismatches...)
+	}
+	return report, nil
+}
+
+// Run checks every interval until ctx is done, passing each report to
+// onReport and each failed check to onError, which may be nil.
+func (c *ConsistencyChecker) Run(ctx context.Context, interval time.Duration, onReport func(*ConsistencyReport), onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		report, err := c.Check(ctx)
+		switch {
+		case err != nil:
+			if onError != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		case report.Checked > 0:
+			onReport(report)
+		}
+	}
+}
+
+// compare fetches ids of one collection and namespace from both providers.
+func (c *ConsistencyChecker) compare(ctx context.Context, group writeKey, ids []string) ([]ConsistencyMismatch, error) {
+	params := make(map[string]interface{}, len(ids)+1)
+	idParams := make([]types.Param, len(ids))
+	for i, id := range ids {
+		idParams[i] = types.Param{Name: fmt.Sprintf("id_%d", i)}
+		params[idParams[i].Name] = id
+	}
+	query := Fetch(types.Collection{Name: group.collection}).
+		IDs(idParams...).
+		IncludeVectors(true).
+		IncludeMetadata(true)
+	if group.namespace != "" {
+		query = query.Namespace(types.Param{Name: "namespace"})
+		params["namespace"] = group.namespace
+	}
+
+	var found [2]map[string]Match
+	for i, target := range [2]ShadowTarget{c.primary, c.secondary} {
+		req, err := Prepare(query, target.Renderer, params)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := target.Executor.Execute(ctx, req)
+		if err != nil {
+			side := "primary"
+			if i == 1 {
+				side = "secondary"
+			}
+			return nil, fmt.Errorf("%s fetch failed: %w", side, err)
+		}
+		found[i] = make(map[string]Match, len(resp.Matches))
+		for _, m := range resp.Matches {
+			found[i][m.ID] = m
+		}
+	}
+
+	var mismatches []ConsistencyMismatch
+	for _, id := range ids {
+		mismatch := ConsistencyMismatch{Collection: group.collection, Namespace: group.namespace, ID: id}
+		p, inPrimary := found[0][id]
+		s, inSecondary := found[1][id]
+		switch {
+		case !inPrimary && !inSecondary:
+			// Deleted on both sides
+			continue
+		case !inPrimary:
+			mismatch.Kind = MismatchMissingPrimary
+		case !inSecondary:
+			mismatch.Kind = MismatchMissingSecondary
+		default:
+			if d := cosineDistance(p.Vector, s.Vector); d > c.tolerance {
+				mismatch.Kind = MismatchVector
+				mismatch.CosineDistance = d
+			} else if fields := diffMetadata(p.Metadata, s.Metadata); len(fields) > 0 {
+				mismatch.Kind = MismatchMetadata
+				mismatch.Fields = fields
+			} else {
+				continue
+			}
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+	return mismatches, nil
+}
+
+// cosineDistance returns 1 minus the cosine similarity of a and b. Vectors of
+// different lengths are maximally distant; two empty vectors are identical.
+func cosineDistance(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 2
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 && normB == 0 {
+		return 0
+	}
+	if normA == 0 || normB == 0 {
+		return 1
+	}
+	return 1 - dot/math.Sqrt(normA*normB)
+}
+
+// diffMetadata returns the sorted names of fields whose values differ.
+// Numbers compare by value, since providers decode them as different types.
+func diffMetadata(a, b map[string]interface{}) []string {
+	var fields []string
+	for name, av := range a {
+		bv, ok := b[name]
+		if !ok || !sameValue(av, bv) {
+			fields = append(fields, name)
+		}
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			fields = append(fields, name)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func sameValue(a, b interface{}) bool {
+	as, aok := toSlice(a)
+	bs, bok := toSlice(b)
+	if !aok || !bok {
+		return valuesEqual(a, b)
+	}
+	if len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		if !valuesEqual(as[i], bs[i]) {
+			return false
+		}
+	}
+	return true
+}
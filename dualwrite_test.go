@@ -0,0 +1,153 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+// memoryStore answers FETCH requests from stored matches and counts writes.
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]Match
+	writes  int
+	err     error
+}
+
+func (s *memoryStore) Execute(_ context.Context, req *Request) (*Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	if req.Operation != OpFetch {
+		s.writes++
+		return &Response{}, nil
+	}
+	resp := &Response{}
+	for _, id := range req.IDs {
+		if m, ok := s.records[id]; ok {
+			resp.Matches = append(resp.Matches, m)
+		}
+	}
+	return resp, nil
+}
+
+func TestDualWrite(t *testing.T) {
+	primary, secondary := &memoryStore{}, &memoryStore{}
+	query := Upsert(types.Collection{Name: "products"}).
+		AddVector(NewRecord(types.Param{Name: "id1"}, Vec(types.Param{Name: "vec1"})).Build())
+	params := map[string]interface{}{"id1": "a", "vec1": []float32{1, 0}}
+
+	_, err := DualWrite(context.Background(), query, params,
+		ShadowTarget{Renderer: qdrant.New(), Executor: primary},
+		ShadowTarget{Renderer: pinecone.New(), Executor: secondary})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if primary.writes != 1 || secondary.writes != 1 {
+		t.Errorf("expected one write on each side, got %d and %d", primary.writes, secondary.writes)
+	}
+
+	secondary.err = errors.New("unavailable")
+	resp, err := DualWrite(context.Background(), query, params,
+		ShadowTarget{Renderer: qdrant.New(), Executor: primary},
+		ShadowTarget{Renderer: pinecone.New(), Executor: secondary})
+	if !errors.Is(err, ErrSecondaryWrite) {
+		t.Errorf("expected ErrSecondaryWrite, got %v", err)
+	}
+	if resp == nil {
+		t.Error("expected the primary response on a secondary failure")
+	}
+
+	search := Search(types.Collection{Name: "products"}).Vector(Vec(types.Param{Name: "vec1"})).TopK(3)
+	if _, err := DualWrite(context.Background(), search, params,
+		ShadowTarget{Renderer: qdrant.New(), Executor: primary},
+		ShadowTarget{Renderer: pinecone.New(), Executor: secondary}); err == nil {
+		t.Error("expected error for a SEARCH")
+	}
+}
+
+func TestConsistencyChecker(t *testing.T) {
+	primary := &memoryStore{records: map[string]Match{
+		"same":     {ID: "same", Vector: []float32{1, 0}, Metadata: map[string]interface{}{"price": 10, "tags": []string{"a"}}},
+		"drift":    {ID: "drift", Vector: []float32{1, 0}},
+		"meta":     {ID: "meta", Vector: []float32{0, 1}, Metadata: map[string]interface{}{"category": "shoes"}},
+		"only_one": {ID: "only_one", Vector: []float32{1, 1}},
+	}}
+	secondary := &memoryStore{records: map[string]Match{
+		"same":  {ID: "same", Vector: []float32{2, 0}, Metadata: map[string]interface{}{"price": 10.0, "tags": []interface{}{"a"}}},
+		"drift": {ID: "drift", Vector: []float32{0, 1}},
+		"meta":  {ID: "meta", Vector: []float32{0, 1}, Metadata: map[string]interface{}{"category": "boots"}},
+	}}
+
+	checker, err := NewConsistencyChecker(
+		ShadowTarget{Renderer: qdrant.New(), Executor: primary},
+		ShadowTarget{Renderer: pinecone.New(), Executor: secondary},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exec := CaptureWrites(primary, checker.Observe)
+	write := &Request{Operation: OpUpsert, Collection: "products", IDs: []string{"same", "drift", "meta", "only_one", "deleted"}}
+	if _, err := exec.Execute(context.Background(), write); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, err := checker.Check(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Checked != 5 {
+		t.Errorf("expected 5 checked records, got %d", report.Checked)
+	}
+
+	kinds := make(map[string]ConsistencyMismatch)
+	for _, m := range report.Mismatches {
+		kinds[m.ID] = m
+	}
+	if len(kinds) != 3 {
+		t.Fatalf("expected 3 mismatches, got %+v", report.Mismatches)
+	}
+	if m := kinds["drift"]; m.Kind != MismatchVector || m.CosineDistance != 1 {
+		t.Errorf("expected vector mismatch with distance 1, got %+v", m)
+	}
+	if m := kinds["meta"]; m.Kind != MismatchMetadata || !reflect.DeepEqual(m.Fields, []string{"category"}) {
+		t.Errorf("expected metadata mismatch on category, got %+v", m)
+	}
+	if m := kinds["only_one"]; m.Kind != MismatchMissingSecondary {
+		t.Errorf("expected missing secondary, got %+v", m)
+	}
+}
+
+func TestConsistencyChecker_RecentWrites(t *testing.T) {
+	target := ShadowTarget{Renderer: qdrant.New(), Executor: &memoryStore{}}
+	checker, err := NewConsistencyChecker(target, target, WithRecentWrites(2), WithSampleSize(5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checker.Observe(context.Background(), WriteEvent{Collection: "products", IDs: []string{"a", "b", "c"}})
+
+	keys := checker.sample()
+	ids := map[string]bool{}
+	for _, k := range keys {
+		ids[k.id] = true
+	}
+	if len(ids) != 2 || ids["a"] {
+		t.Errorf("expected the two most recent writes, got %v", keys)
+	}
+
+	if _, err := NewConsistencyChecker(target, target, WithSampleSize(0)); err == nil {
+		t.Error("expected error for zero sample size")
+	}
+	if _, err := NewConsistencyChecker(target, ShadowTarget{}); err == nil {
+		t.Error("expected error for missing target")
+	}
+}
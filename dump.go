@@ -0,0 +1,184 @@
+package vectql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// DumpVectorPrefix is the number of leading values SanitizedDump keeps of a
+// numeric array.
+const DumpVectorPrefix = 3
+
+// dumpParam is the sanitized form of a ParamSpec.
+type dumpParam struct {
+	Name       string            `json:"name"`
+	Type       types.ParamType   `json:"type,omitempty"`
+	Items      types.ParamType   `json:"items,omitempty"`
+	Dimensions int               `json:"dimensions,omitempty"`
+	Source     types.ParamSource `json:"source,omitempty"`
+	Field      string            `json:"field,omitempty"`
+	Repeats    bool              `json:"repeats,omitempty"`
+}
+
+type queryDump struct {
+	Operation   Operation   `json:"operation"`
+	Collection  string      `json:"collection"`
+	Fingerprint string      `json:"fingerprint"`
+	Params      []dumpParam `json:"params,omitempty"`
+	AST         interface{} `json:"ast"`
+	Payload     interface{} `json:"payload"`
+}
+
+// SanitizedDump returns an indented JSON description of a query and its
+// rendered payload that is safe to attach to vendor support tickets and bug
+// reports. Parameters are renamed p1, p2, ... in order of first use, keeping
+// their types, dimensions, and positions, so the dump shows the query's
+// structure without its naming. Numeric arrays, such as literal vectors, are
+// cut to DumpVectorPrefix values followed by a count of the omitted ones.
+// result is the unbound render of ast, so no parameter values appear.
+func SanitizedDump(ast *types.VectorAST, result *types.QueryResult) ([]byte, error) {
+	specs := result.ParamSpec()
+	names := make(map[string]string, len(specs))
+	dump := queryDump{
+		Operation:   ast.Operation,
+		Collection:  ast.Target.Name,
+		Fingerprint: Fingerprint(ast),
+		Params:      make([]dumpParam, len(specs)),
+	}
+	for i, spec := range specs {
+		alias := fmt.Sprintf("p%d", i+1)
+		names[spec.Name] = alias
+		dump.Params[i] = dumpParam{
+			Name:       alias,
+			Type:       spec.Type,
+			Items:      spec.Items,
+			Dimensions: spec.Dimensions,
+			Source:     spec.Source,
+			Field:      spec.Field,
+			Repeats:    spec.Repeats,
+		}
+	}
+	alias := func(name string) string {
+		if a, ok := names[name]; ok {
+			return a
+		}
+		a := fmt.Sprintf("p%d", len(names)+1)
+		names[name] = a
+		return a
+	}
+
+	tree, err := astTree(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode query: %w", err)
+	}
+	known := func(name string) bool {
+		_, ok := names[name]
+		return ok
+	}
+	dump.AST = sanitizeTree(tree, alias, known, true)
+
+	payload, err := decodeTree(result.JSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %w", err)
+	}
+	dump.Payload = sanitizeTree(payload, alias, known, false)
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(dump); err != nil {
+		return nil, fmt.Errorf("failed to serialize dump: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// astTree decodes the JSON form of ast into a generic tree. Updates are keyed
+// by field name, since JSON objects cannot be keyed by field structs.
+func astTree(ast *types.VectorAST) (interface{}, error) {
+	shallow := *ast
+	shallow.Updates = nil
+	data, err := json.Marshal(shallow)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := decodeTree(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(ast.Updates) > 0 {
+		updates := make(map[string]interface{}, len(ast.Updates))
+		for field, p := range ast.Updates {
+			updates[field.Name] = map[string]interface{}{"Name": p.Name, "Scope": p.Scope}
+		}
+		tree.(map[string]interface{})["Updates"] = updates
+	}
+	return tree, nil
+}
+
+func decodeTree(data string) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader([]byte(data)))
+	decoder.UseNumber()
+	var tree interface{}
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// sanitizeTree renames parameters and truncates numeric arrays. In an AST
+// tree a parameter is an object of exactly Name and Scope, and null fields
+// are dropped; in a payload parameters are ":name" placeholders, and text
+// that only resembles one is left alone.
+func sanitizeTree(node interface{}, alias func(string) string, known func(string) bool, isAST bool) interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if name, ok := v["Name"].(string); ok && isAST && len(v) == 2 {
+			if _, ok := v["Scope"]; ok {
+				return map[string]interface{}{"Name": alias(name)}
+			}
+		}
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if child == nil && isAST {
+				continue
+			}
+			out[key] = sanitizeTree(child, alias, known, isAST)
+		}
+		return out
+	case []interface{}:
+		if len(v) > DumpVectorPrefix && allNumbers(v) {
+			out := append([]interface{}{}, v[:DumpVectorPrefix]...)
+			return append(out, fmt.Sprintf("... %d more", len(v)-DumpVectorPrefix))
+		}
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = sanitizeTree(child, alias, known, isAST)
+		}
+		return out
+	case string:
+		if isAST {
+			return v
+		}
+		return placeholderPattern.ReplaceAllStringFunc(v, func(match string) string {
+			if !known(match[1:]) {
+				return match
+			}
+			return ":" + alias(match[1:])
+		})
+	default:
+		return v
+	}
+}
+
+func allNumbers(items []interface{}) bool {
+	for _, item := range items {
+		if _, ok := item.(json.Number); !ok {
+			return false
+		}
+	}
+	return true
+}
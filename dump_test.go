@@ -0,0 +1,93 @@
+package vectql
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestSanitizedDump(t *testing.T) {
+	coll := types.Collection{Name: "customers"}
+	field := types.MetadataField{Name: "email_domain", Type: "string"}
+	query := Search(coll).
+		Vector(VecLiteral([]float32{0.11, 0.22, 0.33, 0.44, 0.55})).
+		TopK(5).
+		Namespace(types.Param{Name: "acme_corp_tenant"}).
+		Filter(types.FilterCondition{Field: field, Operator: types.EQ, Value: types.Param{Name: "secret_domain"}})
+	ast, err := query.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := query.Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := SanitizedDump(ast, result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	dump := string(data)
+	for _, leaked := range []string{"acme_corp_tenant", "secret_domain", "0.44", "0.55"} {
+		if strings.Contains(dump, leaked) {
+			t.Errorf("dump leaks %q:\n%s", leaked, dump)
+		}
+	}
+	for _, kept := range []string{"customers", "email_domain", `":p2"`, "... 2 more", "0.11"} {
+		if !strings.Contains(dump, kept) {
+			t.Errorf("dump is missing %q:\n%s", kept, dump)
+		}
+	}
+
+	var decoded struct {
+		Operation   Operation `json:"operation"`
+		Fingerprint string    `json:"fingerprint"`
+		Params      []struct {
+			Name   string      `json:"name"`
+			Source ParamSource `json:"source"`
+			Field  string      `json:"field"`
+		} `json:"params"`
+		AST map[string]interface{} `json:"ast"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("dump is not valid JSON: %v", err)
+	}
+	if decoded.Operation != OpSearch || decoded.Fingerprint != Fingerprint(ast) {
+		t.Errorf("unexpected header: %+v", decoded)
+	}
+	if len(decoded.Params) != 2 || decoded.Params[0].Source != SourceNamespace ||
+		decoded.Params[1].Name != "p2" || decoded.Params[1].Field != "email_domain" {
+		t.Errorf("unexpected params: %+v", decoded.Params)
+	}
+	if ns, _ := decoded.AST["Namespace"].(map[string]interface{}); ns["Name"] != "p1" {
+		t.Errorf("expected the AST namespace to be p1, got %v", decoded.AST["Namespace"])
+	}
+	if _, ok := decoded.AST["NearText"]; ok {
+		t.Error("expected null AST fields to be dropped")
+	}
+}
+
+func TestSanitizedDump_Update(t *testing.T) {
+	query := Update(types.Collection{Name: "docs"}).
+		IDs(types.Param{Name: "doc_id"}).
+		Set(types.MetadataField{Name: "status", Type: "string"}, types.Param{Name: "new_status"})
+	ast, err := query.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := query.Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := SanitizedDump(ast, result)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "new_status") || !strings.Contains(string(data), `"status"`) {
+		t.Errorf("expected updates keyed by field with anonymized params:\n%s", data)
+	}
+}
@@ -0,0 +1,133 @@
+package embed
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// Cache stores vectors by Key. Implement it over a persistent store, such
+// as a database table or Redis, to keep embeddings across runs.
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the vector stored under key and whether it was found.
+	Get(ctx context.Context, key string) ([]float32, bool, error)
+
+	// Put stores vector under key.
+	Put(ctx context.Context, key string, vector []float32) error
+}
+
+// Key returns the cache key for content embedded with model: the hex
+// SHA-256 of the model and the content. Changing either changes the key, so
+// a model upgrade never serves stale vectors.
+func Key(model, content string) string {
+	h := sha256.New()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write([]byte(content))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// LRU is an in-memory Cache that evicts the least recently used vector
+// once it holds its capacity.
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type lruEntry struct {
+	key    string
+	vector []float32
+}
+
+// NewLRU creates an LRU holding up to capacity vectors. A capacity below
+// one holds one.
+func NewLRU(capacity int) *LRU {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRU{
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns the vector stored under key and marks it recently used.
+func (c *LRU) Get(_ context.Context, key string) ([]float32, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).vector, true, nil
+}
+
+// Put stores vector under key, evicting the least recently used vector when
+// the cache is full.
+func (c *LRU) Put(_ context.Context, key string, vector []float32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		e.Value.(*lruEntry).vector = vector
+		c.order.MoveToFront(e)
+		return nil
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, vector: vector})
+	if c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached vectors.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Tiered layers caches from fastest to slowest, typically an LRU in front
+// of a persistent store. Get tries each tier in order and copies a hit into
+// the faster tiers; Put writes every tier.
+func Tiered(tiers ...Cache) Cache {
+	return tiered(tiers)
+}
+
+type tiered []Cache
+
+func (t tiered) Get(ctx context.Context, key string) ([]float32, bool, error) {
+	for i, tier := range t {
+		vector, ok, err := tier.Get(ctx, key)
+		if err != nil {
+			return nil, false, err
+		}
+		if !ok {
+			continue
+		}
+		for _, faster := range t[:i] {
+			if err := faster.Put(ctx, key, vector); err != nil {
+				return nil, false, err
+			}
+		}
+		return vector, true, nil
+	}
+	return nil, false, nil
+}
+
+func (t tiered) Put(ctx context.Context, key string, vector []float32) error {
+	for _, tier := range t {
+		if err := tier.Put(ctx, key, vector); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package embed
+
+import (
+	"context"
+	"testing"
+)
+
+// stubCache is a map-backed Cache that can fail every call.
+type stubCache struct {
+	vectors map[string][]float32
+	err     error
+}
+
+func (c *stubCache) Get(_ context.Context, key string) ([]float32, bool, error) {
+	if c.err != nil {
+		return nil, false, c.err
+	}
+	v, ok := c.vectors[key]
+	return v, ok, nil
+}
+
+func (c *stubCache) Put(_ context.Context, key string, vector []float32) error {
+	if c.err != nil {
+		return c.err
+	}
+	if c.vectors == nil {
+		c.vectors = make(map[string][]float32)
+	}
+	c.vectors[key] = vector
+	return nil
+}
+
+func TestKey(t *testing.T) {
+	if Key("m", "text") != Key("m", "text") {
+		t.Error("expected keys to be stable")
+	}
+	if Key("m", "text") == Key("m2", "text") || Key("ab", "c") == Key("a", "bc") {
+		t.Error("expected the model and content to be distinguished")
+	}
+}
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	c := NewLRU(2)
+	_ = c.Put(ctx, "a", []float32{1})
+	_ = c.Put(ctx, "b", []float32{2})
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	_ = c.Put(ctx, "c", []float32{3})
+
+	if _, ok, _ := c.Get(ctx, "b"); ok {
+		t.Error("expected the least recently used entry to be evicted")
+	}
+	if _, ok, _ := c.Get(ctx, "a"); !ok {
+		t.Error("expected a recently used entry to be kept")
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+}
+
+func TestTiered(t *testing.T) {
+	ctx := context.Background()
+	front := NewLRU(10)
+	store := &stubCache{vectors: map[string][]float32{"k": {7}}}
+	cache := Tiered(front, store)
+
+	vector, ok, err := cache.Get(ctx, "k")
+	if err != nil || !ok || vector[0] != 7 {
+		t.Fatalf("expected a hit from the store, got %v %v %v", vector, ok, err)
+	}
+	if _, ok, _ := front.Get(ctx, "k"); !ok {
+		t.Error("expected the hit to be copied into the front tier")
+	}
+
+	if err := cache.Put(ctx, "n", []float32{1}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := store.vectors["n"]; !ok {
+		t.Error("expected Put to write every tier")
+	}
+}
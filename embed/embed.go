@@ -0,0 +1,151 @@
+// Package embed integrates embedding models with vectql. It does not ship
+// model clients: an OpenAI, Vertex AI, or local model client is adapted to
+// Embedder in a few lines. Cached wraps an Embedder so that identical
+// content is embedded once, which keeps backfills that re-read unchanged
+// chunks from re-calling the embedding API:
+//
+//	cache := embed.Tiered(embed.NewLRU(100_000), diskStore)
+//	e := embed.Cached(client, cache)
+//	vectors, err := e.Embed(ctx, chunks)
+//
+// Vectors returns model-tagged vectors ready to bind as parameters, so
+// Bind rejects them for embeddings declared with a different model.
+package embed
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/zoobzio/vectql"
+)
+
+// Embedder embeds text with one model.
+type Embedder interface {
+	// Model identifies the model, as "name" or "name@version". It is part
+	// of every cache key.
+	Model() string
+
+	// Embed returns one vector per text, in order.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Func adapts a function to the Embedder interface.
+func Func(model string, embed func(ctx context.Context, texts []string) ([][]float32, error)) Embedder {
+	return &funcEmbedder{model: model, embed: embed}
+}
+
+type funcEmbedder struct {
+	model string
+	embed func(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+func (f *funcEmbedder) Model() string {
+	return f.model
+}
+
+func (f *funcEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return f.embed(ctx, texts)
+}
+
+// Vectors embeds texts with e and tags each vector with e's model.
+func Vectors(ctx context.Context, e Embedder, texts []string) ([]vectql.ModelVector, error) {
+	embedded, err := e.Embed(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	vectors := make([]vectql.ModelVector, len(embedded))
+	for i, values := range embedded {
+		vectors[i] = vectql.ModelVector{Model: e.Model(), Values: values}
+	}
+	return vectors, nil
+}
+
+// CacheStats counts the texts a CachedEmbedder served from its cache and
+// the texts it sent to the model.
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// CachedEmbedder embeds through a Cache. Create one with Cached.
+type CachedEmbedder struct {
+	next   Embedder
+	cache  Cache
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// Cached returns an Embedder that looks up every text in cache by Key and
+// sends only the misses to next, in one call. Texts repeated within a call
+// are embedded once. Cache errors fail the call rather than silently
+// re-embedding.
+func Cached(next Embedder, cache Cache) *CachedEmbedder {
+	return &CachedEmbedder{next: next, cache: cache}
+}
+
+// Model returns the wrapped embedder's model.
+func (c *CachedEmbedder) Model() string {
+	return c.next.Model()
+}
+
+// Embed returns one vector per text, embedding only uncached content.
+func (c *CachedEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	model := c.next.Model()
+	vectors := make([][]float32, len(texts))
+	keys := make([]string, len(texts))
+
+	// Look up the first occurrence of each text; repeats copy it below
+	first := make(map[string]int, len(texts))
+	var missing []int
+	for i, text := range texts {
+		keys[i] = Key(model, text)
+		if _, seen := first[keys[i]]; seen {
+			continue
+		}
+		first[keys[i]] = i
+		vector, ok, err := c.cache.Get(ctx, keys[i])
+		if err != nil {
+			return nil, fmt.Errorf("embedding cache: %w", err)
+		}
+		if ok {
+			c.hits.Add(1)
+			vectors[i] = vector
+		} else {
+			missing = append(missing, i)
+		}
+	}
+
+	if len(missing) > 0 {
+		c.misses.Add(int64(len(missing)))
+		batch := make([]string, len(missing))
+		for j, i := range missing {
+			batch[j] = texts[i]
+		}
+		embedded, err := c.next.Embed(ctx, batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(embedded) != len(batch) {
+			return nil, fmt.Errorf("embedder returned %d vectors for %d texts", len(embedded), len(batch))
+		}
+		for j, i := range missing {
+			if err := c.cache.Put(ctx, keys[i], embedded[j]); err != nil {
+				return nil, fmt.Errorf("embedding cache: %w", err)
+			}
+			vectors[i] = embedded[j]
+		}
+	}
+
+	for i, key := range keys {
+		if vectors[i] == nil {
+			vectors[i] = vectors[first[key]]
+		}
+	}
+	return vectors, nil
+}
+
+// Stats returns the cache hit and miss counts so far.
+func (c *CachedEmbedder) Stats() CacheStats {
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
@@ -0,0 +1,104 @@
+package embed
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// countingEmbedder embeds each text as its length and records the batches.
+func countingEmbedder(batches *[][]string) Embedder {
+	return Func("test-model@1", func(_ context.Context, texts []string) ([][]float32, error) {
+		*batches = append(*batches, texts)
+		vectors := make([][]float32, len(texts))
+		for i, text := range texts {
+			vectors[i] = []float32{float32(len(text))}
+		}
+		return vectors, nil
+	})
+}
+
+func TestCached(t *testing.T) {
+	var batches [][]string
+	e := Cached(countingEmbedder(&batches), NewLRU(10))
+	ctx := context.Background()
+
+	vectors, err := e.Embed(ctx, []string{"a", "bb", "a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := [][]float32{{1}, {2}, {1}}; !reflect.DeepEqual(vectors, want) {
+		t.Errorf("expected %v, got %v", want, vectors)
+	}
+
+	vectors, err = e.Embed(ctx, []string{"bb", "ccc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := [][]float32{{2}, {3}}; !reflect.DeepEqual(vectors, want) {
+		t.Errorf("expected %v, got %v", want, vectors)
+	}
+
+	if want := [][]string{{"a", "bb"}, {"ccc"}}; !reflect.DeepEqual(batches, want) {
+		t.Errorf("expected only misses to be embedded, got %v", batches)
+	}
+	if stats := e.Stats(); stats.Hits != 1 || stats.Misses != 3 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+
+	// Fully cached calls never reach the model
+	if _, err := e.Embed(ctx, []string{"a", "ccc"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(batches) != 2 {
+		t.Errorf("expected no further model calls, got %v", batches)
+	}
+}
+
+func TestCached_ModelInKey(t *testing.T) {
+	cache := NewLRU(10)
+	var batches [][]string
+	if _, err := Cached(countingEmbedder(&batches), cache).Embed(context.Background(), []string{"a"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	other := Func("test-model@2", func(_ context.Context, texts []string) ([][]float32, error) {
+		batches = append(batches, texts)
+		return [][]float32{{9}}, nil
+	})
+	vectors, err := Cached(other, cache).Embed(context.Background(), []string{"a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(batches) != 2 || vectors[0][0] != 9 {
+		t.Errorf("expected a new model version to miss the cache, got %v", vectors)
+	}
+}
+
+func TestCached_Errors(t *testing.T) {
+	failing := Func("m", func(context.Context, []string) ([][]float32, error) {
+		return [][]float32{{1}}, nil
+	})
+	if _, err := Cached(failing, NewLRU(10)).Embed(context.Background(), []string{"a", "b"}); err == nil {
+		t.Error("expected an error for a vector count mismatch")
+	}
+
+	boom := errors.New("store unavailable")
+	broken := &stubCache{err: boom}
+	var batches [][]string
+	if _, err := Cached(countingEmbedder(&batches), broken).Embed(context.Background(), []string{"a"}); !errors.Is(err, boom) {
+		t.Errorf("expected the cache error, got %v", err)
+	}
+}
+
+func TestVectors(t *testing.T) {
+	var batches [][]string
+	vectors, err := Vectors(context.Background(), countingEmbedder(&batches), []string{"abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vectors) != 1 || vectors[0].Model != "test-model@1" || vectors[0].Values[0] != 3 {
+		t.Errorf("unexpected vectors: %+v", vectors)
+	}
+}
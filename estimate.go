@@ -0,0 +1,211 @@
+package vectql
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Storage heuristics. Estimates are for capacity planning, not billing: real
+// usage varies with provider version, segment layout, and payload contents.
+const (
+	// recordOverheadBytes covers the ID and per-record bookkeeping.
+	recordOverheadBytes = 64
+
+	// defaultHNSWM is the HNSW connectivity assumed when an index declares none.
+	defaultHNSWM = 16
+
+	// ivfOverheadBytes covers the centroid assignment stored per vector.
+	ivfOverheadBytes = 8
+)
+
+// metadataBytes estimates the stored size of one metadata value by type.
+var metadataBytes = map[vdml.MetadataType]int64{
+	vdml.TypeString:      32,
+	vdml.TypeInt:         8,
+	vdml.TypeFloat:       8,
+	vdml.TypeBool:        1,
+	vdml.TypeStringArray: 96,
+	vdml.TypeIntArray:    40,
+	vdml.TypeFloatArray:  40,
+}
+
+// storageProfile describes how a provider lays out vectors.
+type storageProfile struct {
+	// graphLinkBytes is the size of one HNSW neighbor link.
+	graphLinkBytes int64
+
+	// graphMultiplier scales M to the average links per vector.
+	graphMultiplier int64
+
+	// payloadInMemory reports whether payloads are held in memory by default.
+	payloadInMemory bool
+}
+
+// storageProfiles holds the built-in providers' layouts. Pinecone is managed,
+// so its memory figure approximates the serving footprint rather than a
+// node size to provision.
+var storageProfiles = map[string]storageProfile{
+	"pinecone": {graphLinkBytes: 4, graphMultiplier: 2, payloadInMemory: false},
+	"qdrant":   {graphLinkBytes: 4, graphMultiplier: 2, payloadInMemory: false},
+	"milvus":   {graphLinkBytes: 4, graphMultiplier: 2, payloadInMemory: true},
+	"weaviate": {graphLinkBytes: 8, graphMultiplier: 2, payloadInMemory: false},
+}
+
+// StorageEstimate is the expected footprint of one collection on one provider,
+// including replication.
+type StorageEstimate struct {
+	Provider   string
+	Collection string
+	Records    int64
+
+	// VectorBytes is the size of the full-precision vectors.
+	VectorBytes int64
+
+	// QuantizedBytes is the size of quantized copies of the vectors.
+	QuantizedBytes int64
+
+	// IndexBytes is the size of the vector index structures.
+	IndexBytes int64
+
+	// PayloadBytes is the size of IDs and metadata.
+	PayloadBytes int64
+
+	// MemoryBytes and DiskBytes split the footprint by where it must reside.
+	// Quantized collections keep only the quantized vectors in memory.
+	MemoryBytes int64
+	DiskBytes   int64
+}
+
+// EstimateStorage computes the expected memory and disk footprint of each
+// collection on each built-in provider. recordsPerCollection maps collection
+// names to expected record counts; collections absent from the map are
+// skipped. Estimates account for embedding dimensions, quantization and
+// replica settings, index type, and metadata types. Results are sorted by
+// provider, then collection.
+func EstimateStorage(schema *vdml.Schema, recordsPerCollection map[string]int64) ([]StorageEstimate, error) {
+	if schema == nil {
+		return nil, fmt.Errorf("schema cannot be nil")
+	}
+
+	var estimates []StorageEstimate
+	for name, records := range recordsPerCollection {
+		coll, ok := schema.Collections[name]
+		if !ok {
+			return nil, fmt.Errorf("collection '%s' not found in schema", name)
+		}
+		if records < 0 {
+			return nil, fmt.Errorf("record count for collection '%s' must not be negative: %d", name, records)
+		}
+		for provider, profile := range storageProfiles {
+			estimate, err := estimateCollection(coll, records, profile)
+			if err != nil {
+				return nil, err
+			}
+			estimate.Provider = provider
+			estimates = append(estimates, estimate)
+		}
+	}
+
+	sort.Slice(estimates, func(i, j int) bool {
+		if estimates[i].Provider != estimates[j].Provider {
+			return estimates[i].Provider < estimates[j].Provider
+		}
+		return estimates[i].Collection < estimates[j].Collection
+	})
+	return estimates, nil
+}
+
+func estimateCollection(coll *vdml.Collection, records int64, profile storageProfile) (StorageEstimate, error) {
+	replicas := int64(1)
+	if value, ok := coll.Settings[SettingReplicas]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return StorageEstimate{}, fmt.Errorf("collection '%s' setting %s must be a positive integer: %q", coll.Name, SettingReplicas, value)
+		}
+		replicas = int64(n)
+	}
+
+	estimate := StorageEstimate{Collection: coll.Name, Records: records}
+
+	perRecordIndex := indexBytesPerVector(coll, profile)
+	var memoryPerRecord, diskPerRecord int64
+	for _, emb := range coll.Embeddings {
+		full := int64(emb.Dimensions) * 4
+		quantized := quantizedBytes(types.Quantization(coll.Settings[emb.Name+SettingQuantizationSuffix]), emb.Dimensions)
+
+		estimate.VectorBytes += full * records
+		estimate.QuantizedBytes += quantized * records
+		estimate.IndexBytes += perRecordIndex * records
+
+		diskPerRecord += full + quantized + perRecordIndex
+		if quantized > 0 {
+			memoryPerRecord += quantized + perRecordIndex
+		} else {
+			memoryPerRecord += full + perRecordIndex
+		}
+	}
+
+	payload := int64(recordOverheadBytes)
+	for _, meta := range coll.Metadata {
+		payload += metadataBytes[meta.Type]
+	}
+	estimate.PayloadBytes = payload * records
+	diskPerRecord += payload
+	if profile.payloadInMemory {
+		memoryPerRecord += payload
+	}
+
+	estimate.VectorBytes *= replicas
+	estimate.QuantizedBytes *= replicas
+	estimate.IndexBytes *= replicas
+	estimate.PayloadBytes *= replicas
+	estimate.MemoryBytes = memoryPerRecord * records * replicas
+	estimate.DiskBytes = diskPerRecord * records * replicas
+	return estimate, nil
+}
+
+// quantizedBytes returns the size of one quantized vector, or zero when the
+// embedding is not quantized.
+func quantizedBytes(q types.Quantization, dimensions int) int64 {
+	dims := int64(dimensions)
+	switch q {
+	case types.QuantizationScalar:
+		return dims
+	case types.QuantizationProduct:
+		// Assumes 16x compression of float32 components.
+		return (dims*4 + 15) / 16
+	case types.QuantizationBinary:
+		return (dims + 7) / 8
+	default:
+		return 0
+	}
+}
+
+// indexBytesPerVector estimates the index structure size per vector from the
+// collection's first declared index, defaulting to HNSW.
+func indexBytesPerVector(coll *vdml.Collection, profile storageProfile) int64 {
+	indexType := vdml.HNSW
+	m := int64(defaultHNSWM)
+	if len(coll.Indexes) > 0 {
+		index := coll.Indexes[0]
+		indexType = index.Type
+		if value, ok := index.Params["m"]; ok {
+			if n, err := strconv.Atoi(value); err == nil && n > 0 {
+				m = int64(n)
+			}
+		}
+	}
+
+	switch indexType {
+	case vdml.HNSW:
+		return m * profile.graphMultiplier * profile.graphLinkBytes
+	case vdml.IVFFlat, vdml.IVFPQ:
+		return ivfOverheadBytes
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,120 @@
+package vectql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vdml"
+)
+
+func estimateSchema() *vdml.Schema {
+	return vdml.NewSchema("estimates").
+		AddCollection(vdml.NewCollection("docs").
+			AddEmbedding(vdml.NewEmbedding("body", 128)).
+			AddMetadata(vdml.NewMetadataField("title", vdml.TypeString)).
+			AddMetadata(vdml.NewMetadataField("year", vdml.TypeInt)))
+}
+
+func findEstimate(t *testing.T, estimates []StorageEstimate, provider, collection string) StorageEstimate {
+	t.Helper()
+	for _, e := range estimates {
+		if e.Provider == provider && e.Collection == collection {
+			return e
+		}
+	}
+	t.Fatalf("no estimate for %s/%s", provider, collection)
+	return StorageEstimate{}
+}
+
+func TestEstimateStorage(t *testing.T) {
+	estimates, err := EstimateStorage(estimateSchema(), map[string]int64{"docs": 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(estimates) != 4 {
+		t.Fatalf("expected one estimate per provider, got %d", len(estimates))
+	}
+	for i, provider := range []string{"milvus", "pinecone", "qdrant", "weaviate"} {
+		if estimates[i].Provider != provider {
+			t.Errorf("estimate %d: expected provider %s, got %s", i, provider, estimates[i].Provider)
+		}
+	}
+
+	e := findEstimate(t, estimates, "qdrant", "docs")
+	if e.VectorBytes != 512_000 {
+		t.Errorf("expected 512000 vector bytes, got %d", e.VectorBytes)
+	}
+	if e.IndexBytes != 128_000 {
+		t.Errorf("expected 128000 index bytes, got %d", e.IndexBytes)
+	}
+	if e.PayloadBytes != 104_000 {
+		t.Errorf("expected 104000 payload bytes, got %d", e.PayloadBytes)
+	}
+	if e.MemoryBytes != 640_000 {
+		t.Errorf("expected 640000 memory bytes, got %d", e.MemoryBytes)
+	}
+	if e.DiskBytes != 744_000 {
+		t.Errorf("expected 744000 disk bytes, got %d", e.DiskBytes)
+	}
+
+	milvus := findEstimate(t, estimates, "milvus", "docs")
+	if milvus.MemoryBytes != e.MemoryBytes+e.PayloadBytes {
+		t.Errorf("expected milvus to hold payloads in memory, got %d", milvus.MemoryBytes)
+	}
+}
+
+func TestEstimateStorage_QuantizationAndReplicas(t *testing.T) {
+	schema := estimateSchema()
+	schema.Collections["docs"].
+		WithSetting("body"+SettingQuantizationSuffix, "scalar").
+		WithSetting(SettingReplicas, "2")
+
+	estimates, err := EstimateStorage(schema, map[string]int64{"docs": 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	e := findEstimate(t, estimates, "qdrant", "docs")
+	if e.QuantizedBytes != 256_000 {
+		t.Errorf("expected 256000 quantized bytes, got %d", e.QuantizedBytes)
+	}
+	if e.MemoryBytes != 512_000 {
+		t.Errorf("expected 512000 memory bytes, got %d", e.MemoryBytes)
+	}
+	if e.DiskBytes != 1_744_000 {
+		t.Errorf("expected 1744000 disk bytes, got %d", e.DiskBytes)
+	}
+}
+
+func TestEstimateStorage_IndexType(t *testing.T) {
+	schema := estimateSchema()
+	schema.Collections["docs"].AddIndex(vdml.NewIndex(vdml.Flat))
+
+	estimates, err := EstimateStorage(schema, map[string]int64{"docs": 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e := findEstimate(t, estimates, "weaviate", "docs"); e.IndexBytes != 0 {
+		t.Errorf("expected no index bytes for a flat index, got %d", e.IndexBytes)
+	}
+}
+
+func TestEstimateStorage_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  *vdml.Schema
+		records map[string]int64
+		wantErr string
+	}{
+		{"nil schema", nil, nil, "schema cannot be nil"},
+		{"unknown collection", estimateSchema(), map[string]int64{"missing": 1}, "not found"},
+		{"negative count", estimateSchema(), map[string]int64{"docs": -1}, "must not be negative"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := EstimateStorage(tt.schema, tt.records)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package vectql
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WriteVersions tracks a write version per collection from CaptureWrites
+// events, so cached read results can be invalidated when a collection
+// changes. Versions start from a random epoch, so versions issued before a
+// restart never match versions issued after it. Versions are local to the
+// process; writes made by other processes are only seen if their events
+// are fed to Hook as well.
+type WriteVersions struct {
+	epoch string
+
+	mu       sync.Mutex
+	versions map[string]uint64
+}
+
+// NewWriteVersions creates a version tracker with a fresh epoch. The epoch
+// is random, or taken from the clock if no randomness is available.
+func NewWriteVersions() *WriteVersions {
+	var epoch [8]byte
+	if _, err := rand.Read(epoch[:]); err != nil {
+		binary.BigEndian.PutUint64(epoch[:], uint64(time.Now().UnixNano()))
+	}
+	return &WriteVersions{epoch: hex.EncodeToString(epoch[:]), versions: make(map[string]uint64)}
+}
+
+// Hook returns a WriteHook that advances the version of each written
+// collection. Pass it to CaptureWrites.
+func (w *WriteVersions) Hook() WriteHook {
+	return func(_ context.Context, event WriteEvent) {
+		w.mu.Lock()
+		w.versions[event.Collection]++
+		w.mu.Unlock()
+	}
+}
+
+// Version returns the current write version of a collection.
+func (w *WriteVersions) Version(collection string) string {
+	w.mu.Lock()
+	n := w.versions[collection]
+	w.mu.Unlock()
+	return fmt.Sprintf("%s.%d", w.epoch, n)
+}
+
+// ETag returns a strong entity tag for the response to a prepared read. It
+// is derived from the query fingerprint, the provider call and its bound
+// body, which carries the parameter values, and the collection's write
+// version, so it changes whenever the query, its values, or the collection
+// does. Writes have no entity tag.
+func ETag(req *Request, version string) (string, error) {
+	if AccessOf(req.Operation) == AccessWrite {
+		return "", fmt.Errorf("%s requests have no entity tag", req.Operation)
+	}
+	h := sha256.New()
+	for _, part := range []string{
+		req.Provider, req.Collection, req.Namespace, req.Fingerprint,
+		req.Endpoint.Method, req.Endpoint.Path, req.Endpoint.Query,
+		req.Body, version,
+	} {
+		fmt.Fprintf(h, "%d:%s\n", len(part), part)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`, nil
+}
+
+// ETagMatches reports whether an If-None-Match header value matches etag,
+// using the weak comparison HTTP specifies for that header. A gateway
+// answers a matching request with 304 Not Modified.
+func ETagMatches(ifNoneMatch, etag string) bool {
+	ifNoneMatch = strings.TrimSpace(ifNoneMatch)
+	if ifNoneMatch == "" || etag == "" {
+		return false
+	}
+	if ifNoneMatch == "*" {
+		return true
+	}
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == want {
+			return true
+		}
+	}
+	return false
+}
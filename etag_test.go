@@ -0,0 +1,74 @@
+package vectql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestETag(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+	query := Search(coll).
+		Vector(Vec(types.Param{Name: "query"})).
+		TopK(5)
+	prepare := func(vec []float32) *Request {
+		req, err := Prepare(query, qdrant.New(), map[string]interface{}{"query": vec})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return req
+	}
+
+	versions := NewWriteVersions()
+	version := versions.Version("products")
+	first, err := ETag(prepare([]float32{1, 0}), version)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	again, _ := ETag(prepare([]float32{1, 0}), version)
+	if first != again {
+		t.Errorf("expected identical searches to share a tag, got %s and %s", first, again)
+	}
+	other, _ := ETag(prepare([]float32{0, 1}), version)
+	if other == first {
+		t.Error("expected different parameter values to change the tag")
+	}
+
+	versions.Hook()(context.Background(), WriteEvent{Operation: OpUpsert, Collection: "other"})
+	if versions.Version("products") != version {
+		t.Error("expected writes to another collection to keep the version")
+	}
+	versions.Hook()(context.Background(), WriteEvent{Operation: OpUpsert, Collection: "products"})
+	written, _ := ETag(prepare([]float32{1, 0}), versions.Version("products"))
+	if written == first {
+		t.Error("expected a write to the collection to change the tag")
+	}
+
+	if NewWriteVersions().Version("products") == version {
+		t.Error("expected a new tracker to start from a different epoch")
+	}
+	if _, err := ETag(&Request{Operation: OpUpsert}, version); err == nil {
+		t.Error("expected an error for a write request")
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"xyz", "abc"`, true},
+		{`*`, true},
+		{`"xyz"`, false},
+		{``, false},
+	}
+	for _, tt := range tests {
+		if got := ETagMatches(tt.header, `"abc"`); got != tt.want {
+			t.Errorf("ETagMatches(%q): expected %v, got %v", tt.header, tt.want, got)
+		}
+	}
+}
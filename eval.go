@@ -0,0 +1,446 @@
+package vectql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// earthRadiusMeters is the mean Earth radius used for geo distance checks.
+const earthRadiusMeters = 6371008.8
+
+// EvalFilter reports whether a metadata map satisfies a filter, resolving
+// parameter references from params. It implements the same semantics used for
+// client-side post-filtering:
+//
+//   - Numeric values compare by value regardless of Go type, including json.Number
+//   - Missing or nil fields fail every predicate except NE, NOT_IN, and NOT_EXISTS
+//   - IN, NOT_IN, and ARRAY_CONTAINS_ANY/ALL expect list parameters
+//   - NOT groups match when none of their conditions match
+//   - Geo radii are in meters; points are {lat, lon} or {latitude, longitude} maps
+//
+// A nil filter matches everything. Missing parameters and malformed patterns
+// are reported as errors.
+func EvalFilter(f types.FilterItem, metadata, params map[string]interface{}) (bool, error) {
+	return evalFilter(f, metadata, params, patternCache{})
+}
+
+// patternCache holds the compiled Matches patterns of one evaluation, so a
+// filter evaluated against many matches compiles each pattern once.
+type patternCache map[string]*regexp.Regexp
+
+// compile returns the compiled pattern, compiling it on first use.
+func (c patternCache) compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := c[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	c[pattern] = re
+	return re, nil
+}
+
+func evalFilter(f types.FilterItem, metadata, params map[string]interface{}, patterns patternCache) (bool, error) {
+	switch filter := f.(type) {
+	case types.FilterCondition:
+		return evalCondition(filter, metadata, params, patterns)
+
+	case types.FilterGroup:
+		return evalGroup(filter, metadata, params, patterns)
+
+	case types.RangeFilter:
+		return evalRange(filter, metadata, params)
+
+	case types.GeoFilter:
+		return evalGeo(filter, metadata, params)
+
+	case types.ExtensionFilter:
+		return false, fmt.Errorf("%s extension filter cannot be evaluated locally", filter.Provider)
+
+	case nil:
+		return true, nil
+
+	default:
+		return false, fmt.Errorf("unsupported filter type: %T", f)
+	}
+}
+
+func evalGroup(group types.FilterGroup, metadata, params map[string]interface{}, patterns patternCache) (bool, error) {
+	switch group.Logic {
+	case types.AND:
+		for _, c := range group.Conditions {
+			ok, err := evalFilter(c, metadata, params, patterns)
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+
+	case types.OR:
+		for _, c := range group.Conditions {
+			ok, err := evalFilter(c, metadata, params, patterns)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+
+	case types.NOT:
+		// NOT matches when none of its conditions match.
+		for _, c := range group.Conditions {
+			ok, err := evalFilter(c, metadata, params, patterns)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return false, nil
+			}
+		}
+		return true, nil
+
+	default:
+		return false, fmt.Errorf("unsupported logic operator: %s", group.Logic)
+	}
+}
+
+func evalCondition(cond types.FilterCondition, metadata, params map[string]interface{}, patterns patternCache) (bool, error) {
+	field, present := metadata[cond.Field.Name]
+	present = present && field != nil
+
+	switch cond.Operator {
+	case types.Exists:
+		return present, nil
+	case types.NotExists:
+		return !present, nil
+	}
+
+	value, err := lookupParam(cond.Value, params)
+	if err != nil {
+		return false, err
+	}
+
+	switch cond.Operator {
+	case types.EQ:
+		return present && valuesEqual(field, value), nil
+
+	case types.EqFold:
+		s, ok := field.(string)
+		v, vok := value.(string)
+		return present && ok && vok && strings.EqualFold(s, v), nil
+
+	case types.NE:
+		return !present || !valuesEqual(field, value), nil
+
+	case types.GT, types.GE, types.LT, types.LE:
+		if !present {
+			return false, nil
+		}
+		cmp, ok := compareValues(field, value)
+		if !ok {
+			return false, nil
+		}
+		switch cond.Operator {
+		case types.GT:
+			return cmp > 0, nil
+		case types.GE:
+			return cmp >= 0, nil
+		case types.LT:
+			return cmp < 0, nil
+		default:
+			return cmp <= 0, nil
+		}
+
+	case types.IN, types.NotIn:
+		set, ok := toSlice(value)
+		if !ok {
+			return false, fmt.Errorf("parameter '%s' must be a list for %s", cond.Value.Name, cond.Operator)
+		}
+		found := present && sliceContains(set, field)
+		if cond.Operator == types.NotIn {
+			return !found, nil
+		}
+		return found, nil
+
+	case types.Contains:
+		if !present {
+			return false, nil
+		}
+		if items, ok := toSlice(field); ok {
+			return sliceContains(items, value), nil
+		}
+		s, sub, ok := bothStrings(field, value)
+		return ok && strings.Contains(s, sub), nil
+
+	case types.StartsWith:
+		s, prefix, ok := bothStrings(field, value)
+		return present && ok && strings.HasPrefix(s, prefix), nil
+
+	case types.EndsWith:
+		s, suffix, ok := bothStrings(field, value)
+		return present && ok && strings.HasSuffix(s, suffix), nil
+
+	case types.Matches:
+		pattern, ok := value.(string)
+		if !ok {
+			return false, fmt.Errorf("parameter '%s' must be a string pattern", cond.Value.Name)
+		}
+		re, err := patterns.compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern in parameter '%s': %w", cond.Value.Name, err)
+		}
+		s, ok := field.(string)
+		return present && ok && re.MatchString(s), nil
+
+	case types.ArrayContains:
+		items, ok := toSlice(field)
+		return present && ok && sliceContains(items, value), nil
+
+	case types.ArrayContainsAny, types.ArrayContainsAll:
+		items, ok := toSlice(field)
+		if !present || !ok {
+			return false, nil
+		}
+		wanted, ok := toSlice(value)
+		if !ok {
+			return false, fmt.Errorf("parameter '%s' must be a list for %s", cond.Value.Name, cond.Operator)
+		}
+		all := cond.Operator == types.ArrayContainsAll
+		for _, w := range wanted {
+			has := sliceContains(items, w)
+			if has && !all {
+				return true, nil
+			}
+			if !has && all {
+				return false, nil
+			}
+		}
+		return all, nil
+
+	default:
+		return false, fmt.Errorf("unsupported filter operator: %s", cond.Operator)
+	}
+}
+
+func evalRange(r types.RangeFilter, metadata, params map[string]interface{}) (bool, error) {
+	field, present := metadata[r.Field.Name]
+	if !present || field == nil {
+		return false, nil
+	}
+
+	if r.Min != nil {
+		minVal, err := lookupParam(*r.Min, params)
+		if err != nil {
+			return false, err
+		}
+		cmp, ok := compareValues(field, minVal)
+		if !ok || cmp < 0 || (cmp == 0 && r.MinExclusive) {
+			return false, nil
+		}
+	}
+
+	if r.Max != nil {
+		maxVal, err := lookupParam(*r.Max, params)
+		if err != nil {
+			return false, err
+		}
+		cmp, ok := compareValues(field, maxVal)
+		if !ok || cmp > 0 || (cmp == 0 && r.MaxExclusive) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func evalGeo(g types.GeoFilter, metadata, params map[string]interface{}) (bool, error) {
+	lat, err := lookupNumber(g.Center.Lat, params)
+	if err != nil {
+		return false, err
+	}
+	lon, err := lookupNumber(g.Center.Lon, params)
+	if err != nil {
+		return false, err
+	}
+	radius, err := lookupNumber(g.Radius, params)
+	if err != nil {
+		return false, err
+	}
+
+	pointLat, pointLon, ok := geoPoint(metadata[g.Field.Name])
+	if !ok {
+		return false, nil
+	}
+
+	return haversine(lat, lon, pointLat, pointLon) <= radius, nil
+}
+
+// lookupParam resolves a parameter reference to its bound value.
+func lookupParam(p types.Param, params map[string]interface{}) (interface{}, error) {
+	value, ok := params[p.Name]
+	if !ok {
+		return nil, fmt.Errorf("missing parameter: %s", p.Name)
+	}
+	return value, nil
+}
+
+func lookupNumber(p types.Param, params map[string]interface{}) (float64, error) {
+	value, err := lookupParam(p, params)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := toFloat(value)
+	if !ok {
+		return 0, fmt.Errorf("parameter '%s' must be numeric", p.Name)
+	}
+	return n, nil
+}
+
+// geoPoint extracts coordinates from a {lat, lon} or {latitude, longitude} map.
+func geoPoint(v interface{}) (lat, lon float64, ok bool) {
+	m, isMap := v.(map[string]interface{})
+	if !isMap {
+		return 0, 0, false
+	}
+	latVal, hasLat := m["lat"]
+	if !hasLat {
+		latVal, hasLat = m["latitude"]
+	}
+	lonVal, hasLon := m["lon"]
+	if !hasLon {
+		lonVal, hasLon = m["longitude"]
+	}
+	if !hasLat || !hasLon {
+		return 0, 0, false
+	}
+	lat, latOK := toFloat(latVal)
+	lon, lonOK := toFloat(lonVal)
+	return lat, lon, latOK && lonOK
+}
+
+// haversine returns the great-circle distance in meters between two points.
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusMeters * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// valuesEqual compares two values, treating all numeric types as equal by value.
+func valuesEqual(a, b interface{}) bool {
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		return ok && af == bf
+	}
+	switch av := a.(type) {
+	case string:
+		bv, ok := b.(string)
+		return ok && av == bv
+	case bool:
+		bv, ok := b.(bool)
+		return ok && av == bv
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+// compareValues orders two numbers or two strings. The second result is false
+// when the values are not comparable.
+func compareValues(a, b interface{}) (int, bool) {
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case af < bf:
+			return -1, true
+		case af > bf:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	as, bs, ok := bothStrings(a, b)
+	if !ok {
+		return 0, false
+	}
+	return strings.Compare(as, bs), true
+}
+
+func bothStrings(a, b interface{}) (string, string, bool) {
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	return as, bs, aok && bok
+}
+
+func sliceContains(items []interface{}, v interface{}) bool {
+	for _, item := range items {
+		if valuesEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// toSlice converts any slice or array value to []interface{}.
+func toSlice(v interface{}) ([]interface{}, bool) {
+	if items, ok := v.([]interface{}); ok {
+		return items, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+// toFloat converts numeric values, including json.Number, to float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,204 @@
+// Package eval measures search relevance against labeled queries. It runs
+// each case through every target — a provider, a query variant, or both —
+// and reports recall@k, MRR, and nDCG@k per case and per target, so index and
+// configuration changes can be compared before rollout.
+package eval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/zoobzio/vectql"
+)
+
+// Case is a labeled query: parameter values and the IDs that should be
+// returned for them.
+type Case struct {
+	Name   string
+	Params map[string]interface{}
+
+	// Relevant lists the IDs judged relevant.
+	Relevant []string
+
+	// Grades optionally assigns graded relevance for nDCG. IDs in Relevant
+	// without a grade count as 1.
+	Grades map[string]float64
+}
+
+func (c Case) gains() map[string]float64 {
+	gains := make(map[string]float64, len(c.Relevant)+len(c.Grades))
+	for _, id := range c.Relevant {
+		gains[id] = 1
+	}
+	for id, g := range c.Grades {
+		gains[id] = g
+	}
+	return gains
+}
+
+// Target is one configuration under evaluation.
+type Target struct {
+	// Label identifies the target in the report. It defaults to the
+	// renderer's provider name.
+	Label string
+
+	Query    *vectql.Builder
+	Renderer vectql.Renderer
+	Executor vectql.Executor
+}
+
+// Result holds the metrics of one case on one target.
+type Result struct {
+	Target string
+	Case   string
+
+	Recall float64
+	MRR    float64
+	NDCG   float64
+}
+
+// Summary holds the mean metrics of one target over all cases.
+type Summary struct {
+	Target string
+	Cases  int
+
+	Recall float64
+	MRR    float64
+	NDCG   float64
+}
+
+// Report is the outcome of an evaluation run.
+type Report struct {
+	K       int
+	Results []Result
+	Summary []Summary
+}
+
+// Run evaluates every case on every target at cutoff k. Any render, bind, or
+// execution error aborts the run.
+func Run(ctx context.Context, k int, targets []Target, cases []Case, opts ...vectql.BindOption) (*Report, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("k must be positive: %d", k)
+	}
+	report := &Report{K: k}
+	for _, target := range targets {
+		summary := Summary{Target: target.Label}
+		for _, c := range cases {
+			req, err := vectql.Prepare(target.Query, target.Renderer, c.Params, opts...)
+			if err != nil {
+				return nil, fmt.Errorf("case %s: %w", c.Name, err)
+			}
+			if summary.Target == "" {
+				summary.Target = req.Provider
+			}
+			resp, err := target.Executor.Execute(ctx, req)
+			if err != nil {
+				return nil, fmt.Errorf("target %s: case %s: %w", summary.Target, c.Name, err)
+			}
+
+			ranked := make([]string, len(resp.Matches))
+			for i, m := range resp.Matches {
+				ranked[i] = m.ID
+			}
+			gains := c.gains()
+			result := Result{
+				Target: summary.Target,
+				Case:   c.Name,
+				Recall: RecallAtK(ranked, gains, k),
+				MRR:    ReciprocalRank(ranked, gains, k),
+				NDCG:   NDCGAtK(ranked, gains, k),
+			}
+			report.Results = append(report.Results, result)
+
+			summary.Cases++
+			summary.Recall += result.Recall
+			summary.MRR += result.MRR
+			summary.NDCG += result.NDCG
+		}
+		if summary.Cases > 0 {
+			n := float64(summary.Cases)
+			summary.Recall /= n
+			summary.MRR /= n
+			summary.NDCG /= n
+		}
+		report.Summary = append(report.Summary, summary)
+	}
+	return report, nil
+}
+
+// RecallAtK returns the fraction of relevant IDs found in the top k. IDs
+// with a non-positive gain are not relevant. It is 1 when nothing is
+// relevant.
+func RecallAtK(ranked []string, gains map[string]float64, k int) float64 {
+	relevant := 0
+	for _, g := range gains {
+		if g > 0 {
+			relevant++
+		}
+	}
+	if relevant == 0 {
+		return 1
+	}
+	found := 0
+	seen := make(map[string]bool)
+	for _, id := range top(ranked, k) {
+		if gains[id] > 0 && !seen[id] {
+			seen[id] = true
+			found++
+		}
+	}
+	return float64(found) / float64(relevant)
+}
+
+// ReciprocalRank returns 1/rank of the first relevant ID in the top k, or 0
+// when none is found. Averaged over cases it is the MRR.
+func ReciprocalRank(ranked []string, gains map[string]float64, k int) float64 {
+	for i, id := range top(ranked, k) {
+		if gains[id] > 0 {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// NDCGAtK returns the normalized discounted cumulative gain of the top k
+// using graded gains. It is 1 when nothing is relevant.
+func NDCGAtK(ranked []string, gains map[string]float64, k int) float64 {
+	var dcg float64
+	seen := make(map[string]bool)
+	for i, id := range top(ranked, k) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		dcg += gains[id] / math.Log2(float64(i+2))
+	}
+
+	ideal := make([]float64, 0, len(gains))
+	for _, g := range gains {
+		if g > 0 {
+			ideal = append(ideal, g)
+		}
+	}
+	if len(ideal) == 0 {
+		return 1
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(ideal)))
+	var idcg float64
+	for i, g := range ideal {
+		if i == k {
+			break
+		}
+		idcg += g / math.Log2(float64(i+2))
+	}
+	return dcg / idcg
+}
+
+func top(ranked []string, k int) []string {
+	if len(ranked) > k {
+		return ranked[:k]
+	}
+	return ranked
+}
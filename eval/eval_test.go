@@ -0,0 +1,90 @@
+package eval
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestMetrics(t *testing.T) {
+	ranked := []string{"x", "a", "y", "b"}
+	gains := map[string]float64{"a": 1, "b": 1, "c": 1}
+
+	if got := RecallAtK(ranked, gains, 2); !approx(got, 1.0/3.0) {
+		t.Errorf("recall@2: expected 1/3, got %v", got)
+	}
+	if got := RecallAtK(ranked, gains, 4); !approx(got, 2.0/3.0) {
+		t.Errorf("recall@4: expected 2/3, got %v", got)
+	}
+	if got := ReciprocalRank(ranked, gains, 4); !approx(got, 0.5) {
+		t.Errorf("reciprocal rank: expected 0.5, got %v", got)
+	}
+	if got := ReciprocalRank(ranked, gains, 1); got != 0 {
+		t.Errorf("reciprocal rank@1: expected 0, got %v", got)
+	}
+
+	dcg := 1/math.Log2(3) + 1/math.Log2(5)
+	idcg := 1 + 1/math.Log2(3) + 1/math.Log2(4)
+	if got := NDCGAtK(ranked, gains, 4); !approx(got, dcg/idcg) {
+		t.Errorf("nDCG@4: expected %v, got %v", dcg/idcg, got)
+	}
+	if got := NDCGAtK([]string{"a", "b", "c"}, gains, 3); !approx(got, 1) {
+		t.Errorf("nDCG of an ideal ranking: expected 1, got %v", got)
+	}
+}
+
+func TestRun(t *testing.T) {
+	schema := vdml.NewSchema("shop").AddCollection(vdml.NewCollection("products").
+		AddEmbedding(vdml.NewEmbedding("embedding", 2)))
+	v, err := vectql.NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query := vectql.Search(v.C("products")).Vector(vectql.Vec(v.P("v"))).TopK(2)
+
+	respond := func(ids ...string) vectql.Executor {
+		return vectql.ExecutorFunc(func(_ context.Context, _ *vectql.Request) (*vectql.Response, error) {
+			matches := make([]vectql.Match, len(ids))
+			for i, id := range ids {
+				matches[i] = vectql.Match{ID: id}
+			}
+			return &vectql.Response{Matches: matches}, nil
+		})
+	}
+	targets := []Target{
+		{Query: query, Renderer: pinecone.New(), Executor: respond("a", "b")},
+		{Label: "qdrant/rescored", Query: query, Renderer: qdrant.New(), Executor: respond("x", "a")},
+	}
+	cases := []Case{
+		{Name: "shoes", Params: map[string]interface{}{"v": []float32{1, 0}}, Relevant: []string{"a", "b"}},
+		{Name: "hats", Params: map[string]interface{}{"v": []float32{0, 1}}, Relevant: []string{"a"}},
+	}
+
+	report, err := Run(context.Background(), 2, targets, cases)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Results) != 4 || len(report.Summary) != 2 {
+		t.Fatalf("expected 4 results and 2 summaries, got %d and %d", len(report.Results), len(report.Summary))
+	}
+
+	pc, qd := report.Summary[0], report.Summary[1]
+	if pc.Target != "pinecone" || qd.Target != "qdrant/rescored" {
+		t.Errorf("unexpected target labels: %s, %s", pc.Target, qd.Target)
+	}
+	if !approx(pc.Recall, 1) || !approx(pc.MRR, 1) {
+		t.Errorf("pinecone: expected perfect recall and MRR, got %+v", pc)
+	}
+	if !approx(qd.Recall, 0.75) || !approx(qd.MRR, 0.5) {
+		t.Errorf("qdrant: expected recall 0.75 and MRR 0.5, got %+v", qd)
+	}
+}
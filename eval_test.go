@@ -0,0 +1,220 @@
+package vectql
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestEvalFilter_Conditions(t *testing.T) {
+	name := types.MetadataField{Name: "name"}
+	price := types.MetadataField{Name: "price"}
+	tags := types.MetadataField{Name: "tags"}
+	missing := types.MetadataField{Name: "missing"}
+	p := types.Param{Name: "p"}
+
+	metadata := map[string]interface{}{
+		"name":  "red running shoe",
+		"price": json.Number("49.5"),
+		"tags":  []interface{}{"sport", "outdoor"},
+	}
+
+	tests := []struct {
+		name   string
+		filter types.FilterItem
+		param  interface{}
+		want   bool
+	}{
+		{"eq string", Eq(name, p), "red running shoe", true},
+		{"eq mismatch", Eq(name, p), "blue", false},
+		{"eq numeric across types", Eq(price, p), 49.5, true},
+		{"eq fold", EqFold(name, p), "Red Running SHOE", true},
+		{"eq fold mismatch", EqFold(name, p), "red shoe", false},
+		{"eq fold number", EqFold(price, p), "49.5", false},
+		{"ne", Ne(name, p), "blue", true},
+		{"ne missing field", Ne(missing, p), "x", true},
+		{"gt", Gt(price, p), 40, true},
+		{"gte equal", Gte(price, p), 49.5, true},
+		{"lt", Lt(price, p), 40, false},
+		{"lte", Lte(price, p), 50, true},
+		{"gt incomparable", Gt(price, p), "abc", false},
+		{"in", In(name, p), []string{"a", "red running shoe"}, true},
+		{"not in", NotIn(name, p), []string{"a", "b"}, true},
+		{"not in missing field", NotIn(missing, p), []string{"a"}, true},
+		{"contains substring", Contains(name, p), "running", true},
+		{"contains array element", Contains(tags, p), "sport", true},
+		{"starts with", StartsWith(name, p), "red", true},
+		{"ends with", EndsWith(name, p), "hat", false},
+		{"matches", Matches(name, p), "^red .* shoe$", true},
+		{"array contains", ArrayContains(tags, p), "outdoor", true},
+		{"array contains any", ArrayContainsAny(tags, p), []string{"indoor", "sport"}, true},
+		{"array contains all", ArrayContainsAll(tags, p), []string{"sport", "indoor"}, false},
+		{"array contains all match", ArrayContainsAll(tags, p), []string{"sport", "outdoor"}, true},
+		{"eq missing field", Eq(missing, p), "x", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvalFilter(tt.filter, metadata, map[string]interface{}{"p": tt.param})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEvalFilter_Existence(t *testing.T) {
+	metadata := map[string]interface{}{"name": "x", "empty": nil}
+
+	tests := []struct {
+		filter types.FilterItem
+		want   bool
+	}{
+		{Exists(types.MetadataField{Name: "name"}), true},
+		{Exists(types.MetadataField{Name: "empty"}), false},
+		{NotExists(types.MetadataField{Name: "missing"}), true},
+		{NotExists(types.MetadataField{Name: "name"}), false},
+	}
+
+	for _, tt := range tests {
+		got, err := EvalFilter(tt.filter, metadata, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("%v: expected %v, got %v", tt.filter, tt.want, got)
+		}
+	}
+}
+
+func TestEvalFilter_Groups(t *testing.T) {
+	category := types.MetadataField{Name: "category"}
+	metadata := map[string]interface{}{"category": "shoes"}
+	params := map[string]interface{}{"shoes": "shoes", "hats": "hats"}
+
+	shoes := Eq(category, types.Param{Name: "shoes"})
+	hats := Eq(category, types.Param{Name: "hats"})
+
+	tests := []struct {
+		name   string
+		filter types.FilterItem
+		want   bool
+	}{
+		{"and", And(shoes, hats), false},
+		{"or", Or(shoes, hats), true},
+		{"not", Not(hats), true},
+		{"nested", And(Or(hats, shoes), Not(hats)), true},
+		{"nil filter", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvalFilter(tt.filter, metadata, params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEvalFilter_Range(t *testing.T) {
+	price := types.MetadataField{Name: "price"}
+	minP := types.Param{Name: "min"}
+	maxP := types.Param{Name: "max"}
+	params := map[string]interface{}{"min": 10, "max": 20}
+
+	tests := []struct {
+		name   string
+		filter types.FilterItem
+		price  interface{}
+		want   bool
+	}{
+		{"inside", Range(price, &minP, &maxP), 15, true},
+		{"inclusive min", Range(price, &minP, &maxP), 10, true},
+		{"exclusive min", RangeExclusive(price, &minP, &maxP), 10, false},
+		{"exclusive max", RangeExclusive(price, &minP, &maxP), 20.0, false},
+		{"above max", Range(price, &minP, &maxP), 21, false},
+		{"open max", Range(price, &minP, nil), 1000, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvalFilter(tt.filter, map[string]interface{}{"price": tt.price}, params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestEvalFilter_Geo(t *testing.T) {
+	location := types.MetadataField{Name: "location"}
+	geo := Geo(location, types.Param{Name: "lat"}, types.Param{Name: "lon"}, types.Param{Name: "radius"})
+
+	// Berlin center with a 10km radius.
+	params := map[string]interface{}{"lat": 52.52, "lon": 13.405, "radius": 10000}
+
+	inside := map[string]interface{}{"location": map[string]interface{}{"lat": 52.5, "lon": 13.4}}
+	outside := map[string]interface{}{"location": map[string]interface{}{"latitude": 48.1, "longitude": 11.6}}
+
+	if ok, err := EvalFilter(geo, inside, params); err != nil || !ok {
+		t.Errorf("expected point inside radius, got %v (err %v)", ok, err)
+	}
+	if ok, err := EvalFilter(geo, outside, params); err != nil || ok {
+		t.Errorf("expected point outside radius, got %v (err %v)", ok, err)
+	}
+}
+
+func TestEvalFilter_Errors(t *testing.T) {
+	name := types.MetadataField{Name: "name"}
+	metadata := map[string]interface{}{"name": "x"}
+
+	tests := []struct {
+		name   string
+		filter types.FilterItem
+		params map[string]interface{}
+	}{
+		{"missing param", Eq(name, types.Param{Name: "p"}), nil},
+		{"in requires list", In(name, types.Param{Name: "p"}), map[string]interface{}{"p": "x"}},
+		{"invalid pattern", Matches(name, types.Param{Name: "p"}), map[string]interface{}{"p": "("}},
+		{"non-string pattern", Matches(name, types.Param{Name: "p"}), map[string]interface{}{"p": 1}},
+		{"unknown logic", types.FilterGroup{Logic: "XOR"}, nil},
+		{"extension", Extension("weaviate", "nearText"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := EvalFilter(tt.filter, metadata, tt.params); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestPatternCache(t *testing.T) {
+	patterns := patternCache{}
+	first, err := patterns.compile("^sh")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := patterns.compile("^sh")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first != second || len(patterns) != 1 {
+		t.Error("expected the pattern to be compiled once")
+	}
+	if _, err := patterns.compile("("); err == nil || len(patterns) != 1 {
+		t.Errorf("expected an invalid pattern to fail without being cached, got %v", err)
+	}
+}
@@ -0,0 +1,280 @@
+package vectql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Request is a bound query ready to send to a provider.
+type Request struct {
+	// Provider names the vector database, as reported by the renderer's
+	// capabilities. It is empty for renderers that do not report one.
+	Provider string
+
+	// Operation and Collection identify the query.
+	Operation  Operation
+	Collection string
+
+	// Namespace is the bound namespace, or empty when the query has none.
+	Namespace string
+
+	// IDs lists the bound record IDs the query addresses: the records of an
+	// UPSERT, or the IDs of a FETCH, DELETE, or UPDATE. It is empty for
+	// searches and for deletes by filter. Like Namespace, the IDs are the
+	// values sent in Body, after bind and field transforms.
+	IDs []string
+
+	// KeyField and Keys describe a FETCH by key: the physical name of the key
+	// field and the bound keys, after bind and field transforms. Both are
+	// empty for other queries.
+	KeyField string
+	Keys     []string
+
+	// Listing marks a paginated listing prepared with List, whose response
+	// carries the cursor of the next page.
+	Listing bool
+
+	// Fingerprint identifies the query shape; see Fingerprint.
+	Fingerprint string
+
+	// ParamSizes reports the size of each bound parameter, e.g. the number of
+	// vector components, without exposing values.
+	ParamSizes map[string]int
+
+	// ParamClasses records the class of each supplied parameter when the
+	// query was bound with WithParamClassifier, and is nil otherwise.
+	ParamClasses map[string]ParamClass
+
+	// Endpoint is the provider API call with path parameters substituted. It
+	// is zero for renderers that do not describe endpoints.
+	Endpoint Endpoint
+
+	// Body is the bound query body. For endpoints that take newline-delimited
+	// JSON it holds one JSON document per line.
+	Body string
+
+	// ContentEncoding names the encoding applied to Body, e.g. "gzip", for
+	// the transport to send as the Content-Encoding header. It is empty for
+	// uncompressed bodies; see Compress.
+	ContentEncoding string
+
+	// ContentEncodings lists the encodings the provider accepts on Body,
+	// from the renderer's capabilities. Compress only compresses into one
+	// of them.
+	ContentEncodings []string
+
+	// Setup lists requests that must succeed before this one, such as the
+	// creation of the target tenant; see WithTenantSetup and RunSetup.
+	Setup []*Request
+
+	// Degradation names the degradation profile the query was rewritten
+	// with, or is empty when none was active; see DegradationProfile.
+	Degradation string
+
+	// APIVersion is the provider API version the body was rendered for,
+	// for the transport to send, e.g. as Pinecone's X-Pinecone-API-Version
+	// header. It is empty for renderers that do not target a versioned API.
+	APIVersion string
+
+	// CreateIfMissing marks a setup request that creates a resource which
+	// may already exist. Transports treat an already-exists answer as
+	// success.
+	CreateIfMissing bool
+}
+
+// Response holds the decoded provider response.
+type Response struct {
+	// Matches holds the results in provider order.
+	Matches []Match
+
+	// Stale reports that the results came from a secondary provider that
+	// may lag behind the primary; see Failover.
+	Stale bool
+
+	// Staleness bounds how far a stale response may lag, when known.
+	Staleness time.Duration
+
+	// NextPage is the cursor for the next page of a paginated read. It is
+	// zero on the last page; see Scroll.
+	NextPage PageToken
+}
+
+// Executor sends requests to a vector database. The httpexec package
+// implements it over net/http for REST providers; applications using a
+// provider SDK or gRPC implement it over their client. Either way it can be
+// wrapped with the middleware in this package.
+type Executor interface {
+	Execute(ctx context.Context, req *Request) (*Response, error)
+}
+
+// ExecutorFunc adapts a function to the Executor interface.
+type ExecutorFunc func(ctx context.Context, req *Request) (*Response, error)
+
+// Execute calls f.
+func (f ExecutorFunc) Execute(ctx context.Context, req *Request) (*Response, error) {
+	return f(ctx, req)
+}
+
+// Prepare renders a query with r, binds params into the body and endpoint
+// path, and returns the request to execute.
+func Prepare(b *Builder, r Renderer, params map[string]interface{}, opts ...BindOption) (*Request, error) {
+	result, rw, err := b.render(r)
+	if err != nil {
+		return nil, err
+	}
+	ast := rw.logical
+
+	v2 := UpgradeRenderer(rw.renderer)
+	var cfg bindConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if caps := v2.Capabilities(); !caps.SupportsVectorEncoding(cfg.encoding) {
+		return nil, fmt.Errorf("renderer %s does not accept %s vectors", caps.Provider, cfg.encoding)
+	}
+
+	classes, err := classifyParams(result, params, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	body, err := bind(result, params, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	params = deriveParams(result.Derived, params)
+	values, err := boundValues(result, params, &cfg)
+	if err != nil {
+		return nil, err
+	}
+	req := &Request{
+		Provider:         v2.Capabilities().Provider,
+		ContentEncodings: v2.Capabilities().ContentEncodings,
+		Operation:        ast.Operation,
+		Collection:       ast.Target.Name,
+		Namespace:        boundNamespace(rw.physical, values),
+		IDs:              boundIDs(rw.physical, values),
+		KeyField:         keyField(rw.physical),
+		Keys:             boundKeys(rw.physical, values),
+		Listing:          ast.Lists(),
+		Fingerprint:      Fingerprint(ast),
+		ParamSizes:       paramSizes(result.RequiredParams, params),
+		ParamClasses:     classes,
+		Body:             body,
+		Degradation:      result.Degradation,
+		APIVersion:       result.APIVersion,
+	}
+	endpoint, err := v2.Endpoint(rw.physical)
+	switch {
+	case errors.Is(err, ErrNoEndpoint):
+	case err != nil:
+		return nil, err
+	default:
+		path, err := bindPath(endpoint.Path, values)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Path = path
+		if endpoint.Query != "" {
+			if endpoint.Path, err = bindQuery(endpoint.Path, endpoint.Query, result, values); err != nil {
+				return nil, err
+			}
+			endpoint.Query = ""
+		}
+		req.Endpoint = endpoint
+		if endpoint.Lines {
+			if req.Body, err = jsonLines(req.Body); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if cfg.tenantSetup {
+		if err := planTenantSetup(req, rw.renderer); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// jsonLines converts a bound JSON array into newline-delimited JSON, one
+// element per line with a trailing newline.
+func jsonLines(body string) (string, error) {
+	var elements []json.RawMessage
+	if err := json.Unmarshal([]byte(body), &elements); err != nil {
+		return "", fmt.Errorf("newline-delimited body must be a JSON array: %w", err)
+	}
+	var b strings.Builder
+	for _, e := range elements {
+		b.Write(e)
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
+
+// boundValues returns params with the bind transforms and field transforms
+// applied, as bind applies them to the body, so the endpoint and the
+// request's namespace and IDs carry the values the body was bound with.
+func boundValues(result *QueryResult, params map[string]interface{}, cfg *bindConfig) (map[string]interface{}, error) {
+	if len(result.Transforms) == 0 && cfg.transforms == nil {
+		return params, nil
+	}
+	values := make(map[string]interface{}, len(params))
+	for name, value := range params {
+		values[name] = value
+	}
+	if err := applyBindTransforms(result.Transforms, values); err != nil {
+		return nil, err
+	}
+	if cfg.transforms != nil {
+		if err := transformFields(result.Fields, values, cfg.transforms); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// bindQuery binds the placeholders in the values of an encoded query string
+// like expression strings and appends the query string to path.
+func bindQuery(path, query string, result *QueryResult, params map[string]interface{}) (string, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint query: %w", err)
+	}
+	required := make(map[string]bool, len(result.RequiredParams))
+	for _, name := range result.RequiredParams {
+		required[name] = true
+	}
+	for _, vs := range values {
+		for i, v := range vs {
+			if vs[i], err = bindExpression(v, params, required, result.Expressions); err != nil {
+				return "", err
+			}
+		}
+	}
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + values.Encode(), nil
+}
+
+// bindPath substitutes ":name" placeholders in an endpoint path with the
+// path-escaped parameter values.
+func bindPath(path string, params map[string]interface{}) (string, error) {
+	var bindErr error
+	bound := placeholderPattern.ReplaceAllStringFunc(path, func(match string) string {
+		value, ok := params[match[1:]]
+		if !ok {
+			if bindErr == nil {
+				bindErr = fmt.Errorf("missing parameter: %s", match[1:])
+			}
+			return match
+		}
+		return url.PathEscape(fmt.Sprint(value))
+	})
+	return bound, bindErr
+}
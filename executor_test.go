@@ -0,0 +1,152 @@
+package vectql
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/elasticsearch"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+	"github.com/zoobzio/vectql/pkg/typesense"
+	"github.com/zoobzio/vectql/pkg/weaviate"
+)
+
+func TestPrepare(t *testing.T) {
+	query := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(3)
+
+	req, err := Prepare(query, qdrant.New(), map[string]interface{}{"query_vec": []float32{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Provider != "qdrant" {
+		t.Errorf("expected provider qdrant, got %s", req.Provider)
+	}
+	if req.Endpoint.Method != "POST" || req.Endpoint.Path != "/collections/products/points/search" {
+		t.Errorf("unexpected endpoint: %+v", req.Endpoint)
+	}
+	if req.Operation != OpSearch || req.Fingerprint == "" {
+		t.Errorf("expected operation and fingerprint, got %s %q", req.Operation, req.Fingerprint)
+	}
+	if req.ParamSizes["query_vec"] != 2 {
+		t.Errorf("expected query_vec size 2, got %v", req.ParamSizes)
+	}
+	if !strings.Contains(req.Body, `"vector":[1,0]`) {
+		t.Errorf("expected bound vector in body, got %s", req.Body)
+	}
+}
+
+func TestPrepare_BindsPath(t *testing.T) {
+	query := Update(types.Collection{Name: "products"}).
+		IDs(types.Param{Name: "id"}).
+		Set(types.MetadataField{Name: "category"}, types.Param{Name: "cat"})
+
+	req, err := Prepare(query, weaviate.New(), map[string]interface{}{"id": "a/b", "cat": "shoes"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Endpoint.Path != "/v1/objects/Products/a%2Fb" {
+		t.Errorf("expected escaped ID in path, got %s", req.Endpoint.Path)
+	}
+}
+
+func TestPrepare_TransformedNamespaceAndIDs(t *testing.T) {
+	query := Fetch(types.Collection{Name: "products"}).
+		Namespace(types.Param{Name: "ns"}).
+		IDs(types.Param{Name: "id"}).
+		Transform(types.Param{Name: "ns"}, TrimSpace(), Lowercase()).
+		Transform(types.Param{Name: "id"}, TrimSpace())
+
+	req, err := Prepare(query, pinecone.New(), map[string]interface{}{"ns": " Tenant-A ", "id": " doc-1 "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Namespace != "tenant-a" || len(req.IDs) != 1 || req.IDs[0] != "doc-1" {
+		t.Errorf("expected the transformed namespace and ID, got %q %q", req.Namespace, req.IDs)
+	}
+	if !strings.Contains(req.Body, `"tenant-a"`) || !strings.Contains(req.Body, `"doc-1"`) {
+		t.Errorf("expected the body to carry the same values, got %s", req.Body)
+	}
+}
+
+func TestPrepare_Lines(t *testing.T) {
+	query := Delete(types.Collection{Name: "products"}).
+		IDs(types.Param{Name: "a"}, types.Param{Name: "b"})
+
+	req, err := Prepare(query, elasticsearch.New(), map[string]interface{}{"a": "x", "b": "y"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !req.Endpoint.Lines {
+		t.Errorf("expected a newline-delimited endpoint, got %+v", req.Endpoint)
+	}
+	expected := "{\"delete\":{\"_id\":\"x\"}}\n{\"delete\":{\"_id\":\"y\"}}\n"
+	if req.Body != expected {
+		t.Errorf("expected %q, got %q", expected, req.Body)
+	}
+}
+
+func TestPrepare_BindsQuery(t *testing.T) {
+	query := Delete(types.Collection{Name: "products"}).
+		IDs(types.Param{Name: "a"}, types.Param{Name: "b"})
+
+	req, err := Prepare(query, typesense.New(), map[string]interface{}{"a": "x&y", "b": "z"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "/collections/products/documents?filter_by=id%3A%5B%60x%26y%60%2C%60z%60%5D"
+	if req.Endpoint.Path != expected || req.Endpoint.Query != "" {
+		t.Errorf("expected %s, got %+v", expected, req.Endpoint)
+	}
+}
+
+func TestPrepare_APIVersion(t *testing.T) {
+	query := Fetch(types.Collection{Name: "products"}).IDs(types.Param{Name: "id"})
+
+	req, err := Prepare(query, pinecone.NewServerless(pinecone.APIVersion202407), map[string]interface{}{"id": "a"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.APIVersion != "2024-07" {
+		t.Errorf("expected API version 2024-07, got %q", req.APIVersion)
+	}
+}
+
+func TestPrepare_NoEndpoint(t *testing.T) {
+	query := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(3)
+
+	req, err := Prepare(query, newStubRenderer(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Endpoint != (Endpoint{}) {
+		t.Errorf("expected zero endpoint, got %+v", req.Endpoint)
+	}
+}
+
+func TestPrepare_EndpointUsesRewrittenAST(t *testing.T) {
+	v := caseInsensitiveInstance(t)
+	query := Delete(v.C("products")).
+		Filter(v.Eq(v.M("products", "category"), v.P("cat"))).
+		DeleteAll()
+
+	req, err := Prepare(query, typesense.New(), map[string]interface{}{"cat": "Shoes"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filterBy, err := url.ParseQuery(strings.SplitN(req.Endpoint.Path, "?", 2)[1])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filterBy.Get("filter_by") != "category_lower:=`shoes`" {
+		t.Errorf("expected the endpoint to filter the lowercased shadow field, got %s", filterBy.Get("filter_by"))
+	}
+	if req.Body != `{"filter_by":"category_lower:=`+"`shoes`"+`"}` {
+		t.Errorf("expected the body to filter the lowercased shadow field, got %s", req.Body)
+	}
+}
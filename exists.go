@@ -0,0 +1,63 @@
+package vectql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// checkExists rejects an existence check by filter for renderers that
+// cannot render it, which would otherwise fetch no IDs.
+func checkExists(ast *types.VectorAST, renderer Renderer) error {
+	if !ast.ExistsByFilter() {
+		return nil
+	}
+	if er, ok := renderer.(types.ExistsRenderer); ok && er.SupportsExistsByFilter() {
+		return nil
+	}
+	return fmt.Errorf("renderer %s does not support EXISTS by filter", UpgradeRenderer(renderer).Capabilities().Provider)
+}
+
+// ExecuteExists executes an existence check prepared with CheckExists.
+// A check by IDs reports whether each of req.IDs exists, in order, and a
+// check by key whether each of req.Keys exists, matched on the key field of
+// the returned records. A check by filter reports a single result: whether
+// any record matched.
+//
+//	req, _ := vectql.Prepare(vectql.CheckExists(products).IDs(v.P("a"), v.P("b")), r, params)
+//	found, err := vectql.ExecuteExists(ctx, exec, req)
+func ExecuteExists(ctx context.Context, e Executor, req *Request) ([]bool, error) {
+	if req.Operation != types.OpFetch {
+		return nil, fmt.Errorf("existence check must be a FETCH, got %s", req.Operation)
+	}
+	resp, err := e.Execute(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Keys) > 0 {
+		found := make(map[string]bool, len(resp.Matches))
+		for _, m := range resp.Matches {
+			if value, ok := m.Metadata[req.KeyField]; ok {
+				found[fmt.Sprint(value)] = true
+			}
+		}
+		exists := make([]bool, len(req.Keys))
+		for i, key := range req.Keys {
+			exists[i] = found[key]
+		}
+		return exists, nil
+	}
+	if len(req.IDs) == 0 {
+		return []bool{len(resp.Matches) > 0}, nil
+	}
+	found := make(map[string]bool, len(resp.Matches))
+	for _, m := range resp.Matches {
+		found[m.ID] = true
+	}
+	exists := make([]bool, len(req.IDs))
+	for i, id := range req.IDs {
+		exists[i] = found[id]
+	}
+	return exists, nil
+}
@@ -0,0 +1,143 @@
+package vectql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func existsInstance(t *testing.T) *VECTQL {
+	t.Helper()
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return v
+}
+
+// matchesExecutor answers every request with matches for ids.
+func matchesExecutor(ids ...string) Executor {
+	return ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+		resp := &Response{}
+		for _, id := range ids {
+			resp.Matches = append(resp.Matches, Match{ID: id})
+		}
+		return resp, nil
+	})
+}
+
+func TestCheckExists_IDs(t *testing.T) {
+	v := existsInstance(t)
+	builder := CheckExists(v.C("products")).IDs(v.P("a"), v.P("b"))
+	req, err := Prepare(builder, pinecone.New(), map[string]interface{}{"a": "doc-1", "b": "doc-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found, err := ExecuteExists(context.Background(), matchesExecutor("doc-2"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 2 || found[0] || !found[1] {
+		t.Errorf("expected [false true], got %v", found)
+	}
+
+	ast, err := builder.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ast.IncludeMetadata || ast.IncludeVectors {
+		t.Error("expected an existence check to read IDs only")
+	}
+	fetch, err := Fetch(v.C("products")).IDs(v.P("a"), v.P("b")).IncludeMetadata(false).IncludeVectors(false).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if Fingerprint(ast) == Fingerprint(fetch) {
+		t.Error("expected existence checks and fetches to have different fingerprints")
+	}
+}
+
+func TestCheckExists_Filter(t *testing.T) {
+	v := existsInstance(t)
+	builder := CheckExists(v.C("products")).Filter(v.Eq(v.M("products", "category"), v.P("cat")))
+	req, err := Prepare(builder, qdrant.New(), map[string]interface{}{"cat": "shoes"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Endpoint.Path != "/collections/products/points/scroll" || !strings.Contains(req.Body, `"limit":1`) {
+		t.Errorf("expected a scroll of one point, got %s %s", req.Endpoint.Path, req.Body)
+	}
+
+	found, err := ExecuteExists(context.Background(), matchesExecutor("doc-1"), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 1 || !found[0] {
+		t.Errorf("expected [true], got %v", found)
+	}
+	found, err = ExecuteExists(context.Background(), matchesExecutor(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 1 || found[0] {
+		t.Errorf("expected [false], got %v", found)
+	}
+}
+
+func TestCheckExists_Keys(t *testing.T) {
+	v := uniqueKeyInstance(t)
+	category := v.M("products", "category")
+	builder := CheckExists(v.C("products")).FetchBy(category, v.P("a"), v.P("b"), v.P("c"))
+	req, err := Prepare(builder, qdrant.New(), map[string]interface{}{"a": "shoes", "b": "hats", "c": "bags"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.KeyField != "category" || len(req.Keys) != 3 {
+		t.Errorf("expected the category keys, got %q %v", req.KeyField, req.Keys)
+	}
+
+	exec := ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+		return &Response{Matches: []Match{
+			{ID: "doc-1", Metadata: map[string]interface{}{"category": "bags"}},
+			{ID: "doc-2", Metadata: map[string]interface{}{"category": "shoes"}},
+		}}, nil
+	})
+	found, err := ExecuteExists(context.Background(), exec, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(found) != 3 || !found[0] || found[1] || !found[2] {
+		t.Errorf("expected [true false true], got %v", found)
+	}
+
+	ast, err := builder.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ast.IncludeMetadata || len(ast.MetadataFields) != 1 || ast.MetadataFields[0].Name != "category" {
+		t.Errorf("expected a check by key to read the key field, got %v", ast.MetadataFields)
+	}
+}
+
+func TestCheckExists_Errors(t *testing.T) {
+	v := existsInstance(t)
+
+	_, err := CheckExists(v.C("products")).Build()
+	if err == nil || !strings.Contains(err.Error(), "EXISTS requires IDs, a key field, or a filter") {
+		t.Errorf("expected a missing target error, got %v", err)
+	}
+
+	_, err = CheckExists(v.C("products")).Filter(v.Eq(v.M("products", "category"), v.P("cat"))).Render(pinecone.New())
+	if err == nil || !strings.Contains(err.Error(), "pinecone does not support EXISTS by filter") {
+		t.Errorf("expected an unsupported renderer error, got %v", err)
+	}
+
+	_, err = ExecuteExists(context.Background(), matchesExecutor(), &Request{Operation: OpSearch})
+	if err == nil || !strings.Contains(err.Error(), "must be a FETCH") {
+		t.Errorf("expected an operation error, got %v", err)
+	}
+}
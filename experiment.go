@@ -0,0 +1,95 @@
+package vectql
+
+import (
+	"fmt"
+	"hash/fnv"
+)
+
+// Variant is one arm of an experiment.
+type Variant struct {
+	// Label identifies the variant in logs and metrics.
+	Label string
+
+	// Weight is the variant's relative share of traffic.
+	Weight int
+
+	// Query is the SEARCH to run for requests assigned to the variant. It is
+	// shared across requests and must not be modified after the experiment
+	// is created.
+	Query *Builder
+}
+
+// Experiment splits search traffic between query variants. Assignment is a
+// deterministic function of the experiment name and a request key, such as a
+// user ID, so a key always sees the same variant while the weights are
+// unchanged.
+type Experiment struct {
+	name     string
+	variants []Variant
+	total    int
+}
+
+// NewExperiment creates an experiment. Each variant needs a unique label, a
+// positive weight, and a valid SEARCH query.
+func NewExperiment(name string, variants ...Variant) (*Experiment, error) {
+	if len(variants) == 0 {
+		return nil, fmt.Errorf("experiment %s requires at least one variant", name)
+	}
+	e := &Experiment{name: name, variants: variants}
+	labels := make(map[string]bool, len(variants))
+	for _, v := range variants {
+		if v.Label == "" {
+			return nil, fmt.Errorf("experiment %s: variant label is required", name)
+		}
+		if labels[v.Label] {
+			return nil, fmt.Errorf("experiment %s: duplicate variant label %s", name, v.Label)
+		}
+		labels[v.Label] = true
+		if v.Weight <= 0 {
+			return nil, fmt.Errorf("experiment %s: variant %s weight must be positive: %d", name, v.Label, v.Weight)
+		}
+		if v.Query == nil {
+			return nil, fmt.Errorf("experiment %s: variant %s has no query", name, v.Label)
+		}
+		ast, err := v.Query.Build()
+		if err != nil {
+			return nil, fmt.Errorf("experiment %s: variant %s: %w", name, v.Label, err)
+		}
+		if ast.Operation != OpSearch {
+			return nil, fmt.Errorf("experiment %s: variant %s must be a SEARCH, got %s", name, v.Label, ast.Operation)
+		}
+		e.total += v.Weight
+	}
+	return e, nil
+}
+
+// Name returns the experiment name.
+func (e *Experiment) Name() string {
+	return e.name
+}
+
+// Choose assigns key to a variant and returns its query and label.
+func (e *Experiment) Choose(key string) (*Builder, string) {
+	v := e.variants[e.bucket(key)]
+	return v.Query, v.Label
+}
+
+// Assign returns the label of the variant key is assigned to.
+func (e *Experiment) Assign(key string) string {
+	return e.variants[e.bucket(key)].Label
+}
+
+func (e *Experiment) bucket(key string) int {
+	h := fnv.New64a()
+	h.Write([]byte(e.name))
+	h.Write([]byte{0})
+	h.Write([]byte(key))
+	point := int(h.Sum64() % uint64(e.total))
+	for i, v := range e.variants {
+		if point < v.Weight {
+			return i
+		}
+		point -= v.Weight
+	}
+	return len(e.variants) - 1
+}
@@ -0,0 +1,63 @@
+package vectql
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func experimentQuery(topK int) *Builder {
+	return Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(topK)
+}
+
+func TestExperiment_Choose(t *testing.T) {
+	control, treatment := experimentQuery(10), experimentQuery(20)
+	e, err := NewExperiment("ranking",
+		Variant{Label: "control", Weight: 3, Query: control},
+		Variant{Label: "treatment", Weight: 1, Query: treatment},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 4000; i++ {
+		key := fmt.Sprintf("user-%d", i)
+		query, label := e.Choose(key)
+		if (label == "control") != (query == control) {
+			t.Fatalf("label %s does not match the returned query", label)
+		}
+		if again := e.Assign(key); again != label {
+			t.Fatalf("expected stable assignment for %s, got %s then %s", key, label, again)
+		}
+		counts[label]++
+	}
+	if share := float64(counts["treatment"]) / 4000; share < 0.2 || share > 0.3 {
+		t.Errorf("expected about 25%% treatment traffic, got %.2f", share)
+	}
+}
+
+func TestNewExperiment_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		variants []Variant
+		wantErr  string
+	}{
+		{"no variants", nil, "at least one variant"},
+		{"zero weight", []Variant{{Label: "a", Query: experimentQuery(5)}}, "weight must be positive"},
+		{"duplicate label", []Variant{{Label: "a", Weight: 1, Query: experimentQuery(5)}, {Label: "a", Weight: 1, Query: experimentQuery(5)}}, "duplicate variant label"},
+		{"not a search", []Variant{{Label: "a", Weight: 1, Query: Delete(types.Collection{Name: "products"}).IDs(types.Param{Name: "id"})}}, "must be a SEARCH"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewExperiment("exp", tt.variants...)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -64,6 +64,50 @@ func Geo(field types.MetadataField, lat, lon, radius types.Param) types.GeoFilte
 	}
 }
 
+// Extension creates a provider-specific filter. Only renderers whose provider
+// matches consume the payload; other renderers return an error.
+func Extension(provider string, payload interface{}, params ...types.Param) types.ExtensionFilter {
+	return types.ExtensionFilter{
+		Provider: provider,
+		Payload:  payload,
+		Params:   params,
+	}
+}
+
+// Scoring creates a provider-specific scoring expression for a SEARCH. The
+// renderer for provider must allow-list kind; every other renderer rejects
+// the query. Params lists the parameters the payload references.
+func Scoring(provider, kind string, payload interface{}, params ...types.Param) types.ScoreExpression {
+	return types.ScoreExpression{
+		Provider: provider,
+		Kind:     kind,
+		Payload:  payload,
+		Params:   params,
+	}
+}
+
+// Lowercase lowercases a string parameter at bind time.
+func Lowercase() types.BindTransform {
+	return types.BindTransform{Op: types.TransformLower}
+}
+
+// TrimSpace trims surrounding whitespace from a string parameter at bind time.
+func TrimSpace() types.BindTransform {
+	return types.BindTransform{Op: types.TransformTrim}
+}
+
+// Hash replaces a string parameter with its hex SHA-256 digest at bind time,
+// to match metadata stored as hashes.
+func Hash() types.BindTransform {
+	return types.BindTransform{Op: types.TransformHash}
+}
+
+// TruncateTime truncates a time parameter (time.Time or RFC 3339 string) to
+// the start of unit at bind time: second, minute, hour, day, month, or year.
+func TruncateTime(unit string) types.BindTransform {
+	return types.BindTransform{Op: types.TransformTruncate, Unit: unit}
+}
+
 // Vec creates a VectorValue from a parameter.
 func Vec(p types.Param) types.VectorValue {
 	return types.VectorValue{Param: &p}
@@ -122,6 +166,11 @@ func Eq(field types.MetadataField, value types.Param) types.FilterCondition {
 	return F(field, types.EQ, value)
 }
 
+// EqFold creates a case-insensitive equality filter on a string field.
+func EqFold(field types.MetadataField, value types.Param) types.FilterCondition {
+	return F(field, types.EqFold, value)
+}
+
 // Ne creates a not-equal filter.
 func Ne(field types.MetadataField, value types.Param) types.FilterCondition {
 	return F(field, types.NE, value)
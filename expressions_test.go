@@ -222,3 +222,28 @@ func TestGenericFilterHelper(t *testing.T) {
 		t.Errorf("expected value, got %s", filter.Value.Name)
 	}
 }
+
+func TestExtensionFilter(t *testing.T) {
+	payload := map[string]interface{}{"concepts": ":concepts"}
+	ext := Extension("weaviate", payload, types.Param{Name: "concepts"})
+
+	if ext.Provider != "weaviate" {
+		t.Errorf("expected weaviate, got %s", ext.Provider)
+	}
+	if len(ext.Params) != 1 || ext.Params[0].Name != "concepts" {
+		t.Errorf("expected [concepts], got %v", ext.Params)
+	}
+
+	renderer := newStubRenderer()
+	_, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		Filter(ext).
+		Render(renderer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := renderer.rendered.FilterClause.(types.ExtensionFilter); !ok {
+		t.Errorf("expected extension filter to reach the renderer, got %#v", renderer.rendered.FilterClause)
+	}
+}
@@ -0,0 +1,92 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FailoverOption configures a Failover.
+type FailoverOption func(*Failover)
+
+// WithReplicaLag records how far the secondary may lag behind the primary.
+// It is reported as the Staleness of responses served by the secondary.
+func WithReplicaLag(d time.Duration) FailoverOption {
+	return func(f *Failover) {
+		f.lag = d
+	}
+}
+
+// WithFailoverOn sets which primary errors send a read to the secondary. By
+// default only ErrCircuitOpen does, so reads fail over once a CircuitBreaker
+// around the primary has opened rather than on every transient error.
+func WithFailoverOn(match func(err error) bool) FailoverOption {
+	return func(f *Failover) {
+		f.match = match
+	}
+}
+
+// Failover executes queries against a primary provider and serves reads from
+// a secondary when the primary is unavailable. Wrap the primary executor in a
+// CircuitBreaker: while it is open, SEARCH and FETCH queries are rendered for
+// the secondary and its response is marked Stale. Writes always go to the
+// primary, so the secondary is never written to behind the caller's back.
+type Failover struct {
+	primary   ShadowTarget
+	secondary ShadowTarget
+	lag       time.Duration
+	match     func(err error) bool
+}
+
+// NewFailover creates a failover between primary and secondary.
+func NewFailover(primary, secondary ShadowTarget, opts ...FailoverOption) (*Failover, error) {
+	for _, target := range [2]ShadowTarget{primary, secondary} {
+		if target.Renderer == nil || target.Executor == nil {
+			return nil, fmt.Errorf("failover target requires a renderer and an executor")
+		}
+	}
+	f := &Failover{
+		primary:   primary,
+		secondary: secondary,
+		match: func(err error) bool {
+			return errors.Is(err, ErrCircuitOpen)
+		},
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f, nil
+}
+
+// Execute prepares and executes query on the primary, failing over to the
+// secondary for reads. When the secondary also fails, both errors are
+// returned.
+func (f *Failover) Execute(ctx context.Context, query *Builder, params map[string]interface{}, opts ...BindOption) (*Response, error) {
+	ast, err := query.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := Prepare(query, f.primary.Renderer, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.primary.Executor.Execute(ctx, req)
+	if err == nil || AccessOf(ast.Operation) != AccessRead || !f.match(err) {
+		return resp, err
+	}
+
+	req, prepErr := Prepare(query, f.secondary.Renderer, params, opts...)
+	if prepErr != nil {
+		return nil, errors.Join(err, fmt.Errorf("failover prepare failed: %w", prepErr))
+	}
+	resp, secErr := f.secondary.Executor.Execute(ctx, req)
+	if secErr != nil {
+		return nil, errors.Join(err, fmt.Errorf("failover read failed: %w", secErr))
+	}
+	stale := *resp
+	stale.Stale = true
+	stale.Staleness = f.lag
+	return &stale, nil
+}
@@ -0,0 +1,111 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestFailover_ReadsUseSecondaryWhenCircuitOpen(t *testing.T) {
+	failing := ExecutorFunc(func(context.Context, *Request) (*Response, error) {
+		return nil, errors.New("unavailable")
+	})
+	breaker, err := NewCircuitBreaker(failing, WithFailureRate(0.5, 1), WithOpenDuration(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var secondaryReq *Request
+	secondary := ExecutorFunc(func(_ context.Context, req *Request) (*Response, error) {
+		secondaryReq = req
+		return &Response{Matches: []Match{{ID: "a"}}}, nil
+	})
+
+	f, err := NewFailover(
+		ShadowTarget{Renderer: qdrant.New(), Executor: breaker},
+		ShadowTarget{Renderer: pinecone.New(), Executor: secondary},
+		WithReplicaLag(5*time.Second),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	search := Search(types.Collection{Name: "products"}).Vector(Vec(types.Param{Name: "q"})).TopK(3)
+	params := map[string]interface{}{"q": []float32{1, 0}}
+
+	// The first failure opens the breaker but is not a reason to fail over
+	if _, err := f.Execute(context.Background(), search, params); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected the primary error, got %v", err)
+	}
+	if secondaryReq != nil {
+		t.Fatal("expected no failover before the circuit opens")
+	}
+
+	resp, err := f.Execute(context.Background(), search, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !resp.Stale || resp.Staleness != 5*time.Second || len(resp.Matches) != 1 {
+		t.Errorf("expected stale secondary response, got %+v", resp)
+	}
+	if secondaryReq == nil || secondaryReq.Provider != "pinecone" {
+		t.Errorf("expected the query to be rendered for the secondary, got %+v", secondaryReq)
+	}
+}
+
+func TestFailover_WritesStayOnPrimary(t *testing.T) {
+	primary := ExecutorFunc(func(context.Context, *Request) (*Response, error) {
+		return nil, ErrCircuitOpen
+	})
+	called := false
+	secondary := ExecutorFunc(func(context.Context, *Request) (*Response, error) {
+		called = true
+		return &Response{}, nil
+	})
+	f, err := NewFailover(
+		ShadowTarget{Renderer: qdrant.New(), Executor: primary},
+		ShadowTarget{Renderer: qdrant.New(), Executor: secondary},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	upsert := Upsert(types.Collection{Name: "products"}).
+		AddVector(NewRecord(types.Param{Name: "id"}, Vec(types.Param{Name: "vec"})).Build())
+	_, err = f.Execute(context.Background(), upsert, map[string]interface{}{"id": "a", "vec": []float32{1}})
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if called {
+		t.Error("expected writes never to reach the secondary")
+	}
+}
+
+func TestFailover_BothFail(t *testing.T) {
+	primary := ExecutorFunc(func(context.Context, *Request) (*Response, error) {
+		return nil, ErrCircuitOpen
+	})
+	secondaryErr := errors.New("secondary down")
+	secondary := ExecutorFunc(func(context.Context, *Request) (*Response, error) {
+		return nil, secondaryErr
+	})
+	f, err := NewFailover(
+		ShadowTarget{Renderer: qdrant.New(), Executor: primary},
+		ShadowTarget{Renderer: qdrant.New(), Executor: secondary},
+		WithFailoverOn(func(error) bool { return true }),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fetch := Fetch(types.Collection{Name: "products"}).IDs(types.Param{Name: "id"})
+	_, err = f.Execute(context.Background(), fetch, map[string]interface{}{"id": "a"})
+	if !errors.Is(err, ErrCircuitOpen) || !errors.Is(err, secondaryErr) {
+		t.Errorf("expected both errors, got %v", err)
+	}
+}
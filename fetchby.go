@@ -0,0 +1,42 @@
+package vectql
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// checkFetchBy rejects a FETCH by key for renderers that cannot render it,
+// which would otherwise fetch no IDs.
+func checkFetchBy(ast *types.VectorAST, renderer Renderer) error {
+	if ast.FetchKey == nil {
+		return nil
+	}
+	if fr, ok := renderer.(types.FetchByRenderer); ok && fr.SupportsFetchBy() {
+		return nil
+	}
+	return fmt.Errorf("renderer %s does not support FETCH by key", UpgradeRenderer(renderer).Capabilities().Provider)
+}
+
+// keyField returns the key field name of a FETCH by key, or "" for other
+// queries.
+func keyField(ast *VectorAST) string {
+	if ast.FetchKey == nil {
+		return ""
+	}
+	return ast.FetchKey.Field.Name
+}
+
+// boundKeys returns the bound keys of a FETCH by key, in order.
+func boundKeys(ast *VectorAST, params map[string]interface{}) []string {
+	if ast.FetchKey == nil {
+		return nil
+	}
+	var keys []string
+	for _, key := range ast.FetchKey.Keys {
+		if value, ok := params[key.Name]; ok {
+			keys = append(keys, fmt.Sprint(value))
+		}
+	}
+	return keys
+}
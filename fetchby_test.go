@@ -0,0 +1,122 @@
+package vectql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func uniqueKeyInstance(t *testing.T) *VECTQL {
+	t.Helper()
+	schema := testSchema()
+	schema.Collections["products"].Settings = map[string]string{"category" + SettingUniqueSuffix: "true"}
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return v
+}
+
+func TestFetchBy(t *testing.T) {
+	v := uniqueKeyInstance(t)
+	if !v.M("products", "category").Unique {
+		t.Fatal("expected the category field to be unique")
+	}
+
+	builder := Fetch(v.C("products")).FetchBy(v.M("products", "category"), v.P("k1"), v.P("k2"))
+	req, err := Prepare(builder, qdrant.New(), map[string]interface{}{"k1": "sku-1", "k2": "sku-2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Endpoint.Path != "/collections/products/points/scroll" {
+		t.Errorf("expected a scroll, got %s %s", req.Endpoint.Method, req.Endpoint.Path)
+	}
+	if !strings.Contains(req.Body, `"match":{"any":["sku-1","sku-2"]}`) || !strings.Contains(req.Body, `"limit":2`) {
+		t.Errorf("expected a key filter limited to two points, got %s", req.Body)
+	}
+	if len(req.IDs) != 0 {
+		t.Errorf("expected keys not to be reported as IDs, got %v", req.IDs)
+	}
+
+	ast, err := builder.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	specs := ast.ParamSpecs()
+	if len(specs) != 2 || specs[0].Source != SourceKey || specs[0].Field != "category" || specs[0].Type != types.ParamString {
+		t.Errorf("unexpected param specs: %+v", specs)
+	}
+	byID, err := Fetch(v.C("products")).IDs(v.P("id")).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if Fingerprint(ast) == Fingerprint(byID) {
+		t.Error("expected fetches by key and by ID to have different fingerprints")
+	}
+}
+
+func TestFetchBy_PhysicalName(t *testing.T) {
+	v := uniqueKeyInstance(t)
+	result, err := Fetch(v.C("products")).
+		FetchBy(v.M("products", "category"), v.P("k1")).
+		Render(&qdrant.Renderer{FieldNames: FieldNames{Prefix: "app_"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.JSON, `"key":"app_category"`) {
+		t.Errorf("expected the physical key field, got %s", result.JSON)
+	}
+}
+
+func TestFetchBy_Errors(t *testing.T) {
+	v := uniqueKeyInstance(t)
+
+	_, err := Fetch(v.C("products")).FetchBy(v.M("products", "location"), v.P("k")).Build()
+	if err == nil || !strings.Contains(err.Error(), "not declared unique") {
+		t.Errorf("expected a unique field error, got %v", err)
+	}
+
+	_, err = Search(v.C("products")).FetchBy(v.M("products", "category"), v.P("k")).Build()
+	if err == nil || !strings.Contains(err.Error(), "only be used with FETCH") {
+		t.Errorf("expected an operation error, got %v", err)
+	}
+
+	_, err = Fetch(v.C("products")).FetchBy(v.M("products", "category")).Build()
+	if err == nil || !strings.Contains(err.Error(), "at least one key") {
+		t.Errorf("expected a missing key error, got %v", err)
+	}
+
+	_, err = Fetch(v.C("products")).IDs(v.P("id")).FetchBy(v.M("products", "category"), v.P("k")).Build()
+	if err == nil || !strings.Contains(err.Error(), "either IDs or a key field") {
+		t.Errorf("expected a conflict error, got %v", err)
+	}
+
+	_, err = Fetch(v.C("products")).FetchBy(v.M("products", "category"), v.P("k")).Render(pinecone.New())
+	if err == nil || !strings.Contains(err.Error(), "pinecone does not support FETCH by key") {
+		t.Errorf("expected an unsupported renderer error, got %v", err)
+	}
+}
+
+func TestUniqueSetting_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting map[string]string
+		want    string
+	}{
+		{"not boolean", map[string]string{"category" + SettingUniqueSuffix: "yes"}, "must be true or false"},
+		{"float field", map[string]string{"price" + SettingUniqueSuffix: "true"}, "must be a string or int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := testSchema()
+			schema.Collections["products"].Settings = tt.setting
+			_, err := NewFromVDML(schema)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
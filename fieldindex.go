@@ -0,0 +1,61 @@
+package vectql
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// FieldIndexRenderer is implemented by renderers that describe payload index
+// creation. Qdrant creates payload indexes, Milvus scalar indexes, Weaviate
+// properties with their inverted index configuration, and Elasticsearch and
+// Typesense mapped fields.
+type FieldIndexRenderer interface {
+	// RenderFieldIndex describes the call that creates the index declared
+	// on field in collection.
+	RenderFieldIndex(collection string, field types.MetadataField) (Endpoint, string, error)
+}
+
+// PrepareFieldIndexes renders one Request per indexed field, in order, for
+// an Executor. Fields are typically taken from VECTQL.IndexedFields; fields
+// without an index kind are skipped, and fields are renamed like in Render.
+// It fails when the renderer does not create payload indexes, or when its
+// provider cannot store a field's name; see NameValidator.
+func PrepareFieldIndexes(r Renderer, collection types.Collection, fields []types.MetadataField) ([]*Request, error) {
+	fr, ok := r.(FieldIndexRenderer)
+	if !ok {
+		return nil, fmt.Errorf("renderer %s does not create payload indexes", UpgradeRenderer(r).Capabilities().Provider)
+	}
+	provider := UpgradeRenderer(r).Capabilities().Provider
+	names, _ := physicalNames(r)
+
+	indexed := CollectionNames{Name: collection.Name}
+	for _, field := range fields {
+		if field.Index != "" {
+			indexed.Fields = append(indexed.Fields, names.Physical(field))
+		}
+	}
+	if err := checkNames(r, SchemaNames{Collections: []CollectionNames{indexed}}); err != nil {
+		return nil, err
+	}
+
+	var reqs []*Request
+	for _, field := range fields {
+		if field.Index == "" {
+			continue
+		}
+		physical := field
+		physical.Name = names.Physical(field)
+		endpoint, body, err := fr.RenderFieldIndex(collection.Name, physical)
+		if err != nil {
+			return nil, fmt.Errorf("field '%s': %w", field.Name, err)
+		}
+		reqs = append(reqs, &Request{
+			Provider:   provider,
+			Collection: collection.Name,
+			Endpoint:   endpoint,
+			Body:       body,
+		})
+	}
+	return reqs, nil
+}
@@ -0,0 +1,35 @@
+package vectql
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestPrepareFieldIndexes(t *testing.T) {
+	fields := []types.MetadataField{
+		{Name: "category", Type: "string", Index: types.IndexKeyword},
+		{Name: "note", Type: "string"},
+		{Name: "price", Type: "float", Index: types.IndexFloat},
+	}
+
+	reqs, err := PrepareFieldIndexes(qdrant.New(), types.Collection{Name: "products"}, fields)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(reqs))
+	}
+	if reqs[0].Provider != "qdrant" || reqs[0].Collection != "products" || reqs[0].Endpoint.Path != "/collections/products/index" {
+		t.Errorf("unexpected request: %+v", reqs[0])
+	}
+	if expected := `{"field_name":"price","field_schema":"float"}`; reqs[1].Body != expected {
+		t.Errorf("expected %s, got %s", expected, reqs[1].Body)
+	}
+
+	if _, err := PrepareFieldIndexes(pinecone.New(), types.Collection{Name: "products"}, fields); err == nil {
+		t.Error("expected error for a renderer without payload indexes")
+	}
+}
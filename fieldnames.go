@@ -0,0 +1,136 @@
+package vectql
+
+import (
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// FieldNamer is implemented by renderers that can be configured with
+// physical field names; see FieldNames.
+type FieldNamer interface {
+	// PhysicalFieldNames returns the renderer's field name mapping.
+	PhysicalFieldNames() FieldNames
+}
+
+// physicalNames returns the field name mapping of renderer, or false when
+// it has none.
+func physicalNames(renderer Renderer) (FieldNames, bool) {
+	namer, ok := renderer.(FieldNamer)
+	if !ok {
+		return FieldNames{}, false
+	}
+	names := namer.PhysicalFieldNames()
+	return names, !names.IsZero()
+}
+
+// renameFields rewrites the metadata fields of ast to the physical names
+// configured on renderer. The AST is returned unchanged when the renderer
+// has no mapping; otherwise a rewritten copy is returned.
+func renameFields(ast *types.VectorAST, renderer Renderer) *types.VectorAST {
+	names, ok := physicalNames(renderer)
+	if !ok {
+		return ast
+	}
+	rename := func(f types.MetadataField) types.MetadataField {
+		f.Name = names.Physical(f)
+		return f
+	}
+
+	renamed := *ast
+	renamed.FilterClause = renameFilter(ast.FilterClause, rename)
+	if ast.TextQuery != nil {
+		text := *ast.TextQuery
+		text.Properties = make([]types.TextProperty, len(ast.TextQuery.Properties))
+		for i, p := range ast.TextQuery.Properties {
+			p.Field = rename(p.Field)
+			text.Properties[i] = p
+		}
+		renamed.TextQuery = &text
+	}
+	if ast.Boosts != nil {
+		renamed.Boosts = make([]types.Boost, len(ast.Boosts))
+		for i, b := range ast.Boosts {
+			b.Filter = renameFilter(b.Filter, rename)
+			if b.Field != nil {
+				field := rename(*b.Field)
+				b.Field = &field
+			}
+			renamed.Boosts[i] = b
+		}
+	}
+	if ast.MetadataFields != nil {
+		renamed.MetadataFields = make([]types.MetadataField, len(ast.MetadataFields))
+		for i, f := range ast.MetadataFields {
+			renamed.MetadataFields[i] = rename(f)
+		}
+	}
+	if ast.FetchKey != nil {
+		key := *ast.FetchKey
+		key.Field = rename(key.Field)
+		renamed.FetchKey = &key
+	}
+	if ast.Updates != nil {
+		renamed.Updates = renameFieldMap(ast.Updates, rename)
+	}
+	if ast.Vectors != nil {
+		renamed.Vectors = make([]types.VectorRecord, len(ast.Vectors))
+		for i, record := range ast.Vectors {
+			if record.Metadata != nil {
+				record.Metadata = renameFieldMap(record.Metadata, rename)
+			}
+			renamed.Vectors[i] = record
+		}
+	}
+	return &renamed
+}
+
+func renameFieldMap(m map[types.MetadataField]types.Param, rename func(types.MetadataField) types.MetadataField) map[types.MetadataField]types.Param {
+	out := make(map[types.MetadataField]types.Param, len(m))
+	for field, param := range m {
+		out[rename(field)] = param
+	}
+	return out
+}
+
+func renameFilter(f types.FilterItem, rename func(types.MetadataField) types.MetadataField) types.FilterItem {
+	switch filter := f.(type) {
+	case types.FilterCondition:
+		filter.Field = rename(filter.Field)
+		return filter
+	case types.RangeFilter:
+		filter.Field = rename(filter.Field)
+		return filter
+	case types.GeoFilter:
+		filter.Field = rename(filter.Field)
+		return filter
+	case types.FilterGroup:
+		conditions := make([]types.FilterItem, len(filter.Conditions))
+		for i, c := range filter.Conditions {
+			conditions[i] = renameFilter(c, rename)
+		}
+		filter.Conditions = conditions
+		return filter
+	default:
+		return f
+	}
+}
+
+// LogicalMatches renames the metadata of matches from collection, read
+// from a provider configured with names, back to logical field names.
+// Keys the mapping does not produce are kept as they are. Metadata maps are
+// replaced rather than modified in place.
+func LogicalMatches(names FieldNames, collection string, matches []Match) {
+	if names.IsZero() {
+		return
+	}
+	for i := range matches {
+		if matches[i].Metadata == nil {
+			continue
+		}
+		logical := make(map[string]interface{}, len(matches[i].Metadata))
+		for key, value := range matches[i].Metadata {
+			name, _ := names.Logical(collection, key)
+			logical[name] = value
+		}
+		matches[i].Metadata = logical
+	}
+}
@@ -0,0 +1,110 @@
+package vectql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestFieldNames(t *testing.T) {
+	names := FieldNames{
+		Prefix: "app_",
+		Fields: map[string]string{
+			"category":        "cat",
+			"orders.category": "order_category",
+		},
+	}
+	tests := []struct {
+		collection, field, physical string
+	}{
+		{"products", "category", "app_cat"},
+		{"orders", "category", "app_order_category"},
+		{"products", "price", "app_price"},
+		{"products", "category" + types.ShadowSuffix, "app_cat" + types.ShadowSuffix},
+	}
+	for _, tt := range tests {
+		physical := names.Physical(types.MetadataField{Collection: tt.collection, Name: tt.field})
+		if physical != tt.physical {
+			t.Errorf("Physical(%s.%s): expected %s, got %s", tt.collection, tt.field, tt.physical, physical)
+		}
+		logical, ok := names.Logical(tt.collection, physical)
+		if !ok || logical != tt.field {
+			t.Errorf("Logical(%s, %s): expected %s, got %s (%v)", tt.collection, physical, tt.field, logical, ok)
+		}
+	}
+	for _, physical := range []string{"price", "app_category"} {
+		if _, ok := names.Logical("products", physical); ok {
+			t.Errorf("expected %s not to be a physical name", physical)
+		}
+	}
+}
+
+func TestRender_FieldNames(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+	category := types.MetadataField{Name: "category", Collection: "products", Type: "string"}
+	price := types.MetadataField{Name: "price", Collection: "products", Type: "float"}
+	query := Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		Filter(And(Eq(category, types.Param{Name: "category"}), Range(price, &types.Param{Name: "min"}, nil))).
+		SelectMetadata(category).
+		TopK(5)
+
+	r := qdrant.New()
+	r.FieldNames = FieldNames{Prefix: "app_", Fields: map[string]string{"category": "cat"}}
+	result, err := query.Render(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{`"app_cat"`, `"app_price"`} {
+		if !strings.Contains(result.JSON, want) {
+			t.Errorf("expected %s in %s", want, result.JSON)
+		}
+	}
+	if strings.Contains(result.JSON, `"category"`) {
+		t.Errorf("expected no logical names in %s", result.JSON)
+	}
+	if len(result.Fields) == 0 || result.Fields[0].Field.Name != "category" {
+		t.Errorf("expected field parameters to keep logical names, got %+v", result.Fields)
+	}
+
+	plain, err := query.Render(pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(plain.JSON, `"category"`) {
+		t.Errorf("expected logical names without a mapping, got %s", plain.JSON)
+	}
+}
+
+func TestPrepareFieldIndexes_FieldNames(t *testing.T) {
+	r := qdrant.New()
+	r.FieldNames = FieldNames{Prefix: "app_"}
+	reqs, err := PrepareFieldIndexes(r, types.Collection{Name: "products"}, []types.MetadataField{
+		{Name: "category", Collection: "products", Type: "string", Index: types.IndexKeyword},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reqs) != 1 || !strings.Contains(reqs[0].Body, `"app_category"`) {
+		t.Errorf("expected the physical field name, got %+v", reqs)
+	}
+}
+
+func TestLogicalMatches(t *testing.T) {
+	names := FieldNames{Prefix: "app_", Fields: map[string]string{"category": "cat"}}
+	original := map[string]interface{}{"app_cat": "shoes", "app_price": 10, "other": true}
+	matches := []Match{{ID: "a", Metadata: original}, {ID: "b"}}
+	LogicalMatches(names, "products", matches)
+
+	want := map[string]interface{}{"category": "shoes", "price": 10, "other": true}
+	if !reflect.DeepEqual(matches[0].Metadata, want) {
+		t.Errorf("expected %v, got %v", want, matches[0].Metadata)
+	}
+	if _, ok := original["app_cat"]; !ok {
+		t.Error("expected the original metadata map to be unchanged")
+	}
+}
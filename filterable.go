@@ -0,0 +1,82 @@
+package vectql
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// typeOperators lists the filter operators meaningful for each VDML metadata
+// type, in FilterOperators order.
+var typeOperators = map[string][]FilterOperator{
+	"string": {types.EQ, types.NE, types.EqFold, types.IN, types.NotIn, types.Contains, types.StartsWith, types.EndsWith, types.Matches, types.Exists, types.NotExists},
+	"int":    {types.EQ, types.NE, types.GT, types.GE, types.LT, types.LE, types.IN, types.NotIn, types.Exists, types.NotExists},
+	"float":  {types.EQ, types.NE, types.GT, types.GE, types.LT, types.LE, types.IN, types.NotIn, types.Exists, types.NotExists},
+	"bool":   {types.EQ, types.NE, types.Exists, types.NotExists},
+}
+
+// arrayOperators lists the filter operators meaningful for array fields.
+var arrayOperators = []FilterOperator{types.Exists, types.NotExists, types.ArrayContains, types.ArrayContainsAny, types.ArrayContainsAll}
+
+// OperatorsForType returns the filter operators meaningful for a VDML
+// metadata type, e.g. ordering comparisons for numbers and prefix matching for
+// strings. Unknown types have none.
+func OperatorsForType(fieldType string) []FilterOperator {
+	if strings.HasPrefix(fieldType, "[]") {
+		return slices.Clone(arrayOperators)
+	}
+	return slices.Clone(typeOperators[fieldType])
+}
+
+// FilterableField describes a metadata field a UI can offer as a filter.
+type FilterableField struct {
+	Name string
+	Type string
+
+	// Indexed reports whether the schema declares an index on the field.
+	Indexed bool
+
+	// Operators lists the operators valid for the field's type that the
+	// target provider supports.
+	Operators []FilterOperator
+}
+
+// FilterableFields returns the metadata fields of a collection that can be
+// filtered when rendering with r, sorted by name, each with the operators
+// valid for its type and supported by r. Fields without any such operator
+// are omitted. A nil renderer lists every operator valid for the type. The
+// result is intended to drive query-builder UIs.
+func (v *VECTQL) FilterableFields(collectionName string, r Renderer) ([]FilterableField, error) {
+	collMeta, ok := v.metadata[collectionName]
+	if !ok {
+		return nil, fmt.Errorf("collection '%s' not found", collectionName)
+	}
+
+	var caps *Capabilities
+	if r != nil {
+		c := UpgradeRenderer(r).Capabilities()
+		caps = &c
+	}
+
+	var fields []FilterableField
+	for f := range v.IterFields(collectionName) {
+		var ops []FilterOperator
+		for _, op := range OperatorsForType(f.Type) {
+			if caps == nil || caps.SupportsFilter(op) {
+				ops = append(ops, op)
+			}
+		}
+		if len(ops) == 0 {
+			continue
+		}
+		fields = append(fields, FilterableField{
+			Name:      f.Name,
+			Type:      f.Type,
+			Indexed:   collMeta[f.Name].Indexed,
+			Operators: ops,
+		})
+	}
+	return fields, nil
+}
@@ -0,0 +1,84 @@
+package vectql
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestOperatorsForType(t *testing.T) {
+	if ops := OperatorsForType("float"); !slices.Contains(ops, OpGT) || slices.Contains(ops, OpStartsWith) {
+		t.Errorf("expected ordering but not prefix operators for float, got %v", ops)
+	}
+	if ops := OperatorsForType("string"); !slices.Contains(ops, OpStartsWith) || slices.Contains(ops, OpGT) {
+		t.Errorf("expected prefix but not ordering operators for string, got %v", ops)
+	}
+	if ops := OperatorsForType("[]string"); !slices.Contains(ops, OpArrayContainsAny) || slices.Contains(ops, OpEQ) {
+		t.Errorf("expected array operators for []string, got %v", ops)
+	}
+	if ops := OperatorsForType("geo"); len(ops) != 0 {
+		t.Errorf("expected no operators for unknown type, got %v", ops)
+	}
+}
+
+func TestFilterableFields(t *testing.T) {
+	schema := testSchema()
+	products := schema.Collections["products"]
+	products.Metadata = append(products.Metadata,
+		&vdml.MetadataField{Name: "in_stock", Type: vdml.TypeBool, Indexed: true},
+		&vdml.MetadataField{Name: "tags", Type: vdml.TypeStringArray},
+	)
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fields, err := v.FilterableFields("products", pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byName := make(map[string]FilterableField)
+	var names []string
+	for _, f := range fields {
+		byName[f.Name] = f
+		names = append(names, f.Name)
+	}
+	// Pinecone supports no operator valid for array fields
+	if !slices.Equal(names, []string{"category", "in_stock", "location", "price"}) {
+		t.Errorf("unexpected fields: %v", names)
+	}
+	if ops := byName["price"].Operators; !slices.Equal(ops, []FilterOperator{OpEQ, OpNE, OpGT, OpGE, OpLT, OpLE, OpIN, OpNotIn}) {
+		t.Errorf("unexpected price operators: %v", ops)
+	}
+	if ops := byName["category"].Operators; slices.Contains(ops, OpContains) {
+		t.Errorf("expected pinecone string operators without contains, got %v", ops)
+	}
+	if f := byName["in_stock"]; f.Type != "bool" || !f.Indexed || !slices.Equal(f.Operators, []FilterOperator{OpEQ, OpNE}) {
+		t.Errorf("unexpected in_stock field: %+v", f)
+	}
+
+	fields, err = v.FilterableFields("products", qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range fields {
+		if f.Name == "category" && !slices.Contains(f.Operators, OpContains) {
+			t.Errorf("expected qdrant to offer contains on strings, got %v", f.Operators)
+		}
+	}
+
+	all, err := v.FilterableFields("products", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != 5 {
+		t.Errorf("expected every field without a renderer, got %d", len(all))
+	}
+
+	if _, err := v.FilterableFields("missing", nil); err == nil {
+		t.Error("expected error for unknown collection")
+	}
+}
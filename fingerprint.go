@@ -0,0 +1,123 @@
+package vectql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Fingerprint returns a short stable hash of a query's shape: the operation,
+// collection, embedding, search input, filter structure, boosts, and selected fields.
+// Parameter names and literal values are excluded, so queries that differ
+// only in their bound values or batch sizes share a fingerprint.
+func Fingerprint(ast *types.VectorAST) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s", ast.Operation, ast.Target.Name, ast.Modality())
+	if ast.QueryEmbedding != nil {
+		fmt.Fprintf(&b, " embedding=%s", ast.QueryEmbedding.Name)
+	}
+	if ast.TopK != nil {
+		b.WriteString(" topk")
+	}
+	if ast.MinScore != nil {
+		b.WriteString(" minscore")
+	}
+	if ast.Namespace != nil {
+		b.WriteString(" namespace")
+	}
+	if ast.Quantization != nil {
+		b.WriteString(" quantization")
+	}
+	if ast.Freshness != nil {
+		fmt.Fprintf(&b, " freshness=%s", ast.Freshness.Level)
+	}
+	if ast.Snapshot != nil {
+		b.WriteString(" snapshot")
+	}
+	fmt.Fprintf(&b, " vectors=%t metadata=%t deleteall=%t", ast.IncludeVectors, ast.IncludeMetadata, ast.DeleteAll)
+	if ast.FetchKey != nil {
+		fmt.Fprintf(&b, " key=%s", ast.FetchKey.Field.Name)
+	}
+	if ast.Exists {
+		b.WriteString(" exists")
+	}
+	if ast.Page != nil {
+		b.WriteString(" list")
+	}
+	if ast.FilterClause != nil {
+		b.WriteString(" filter=")
+		writeFilterShape(&b, ast.FilterClause)
+	}
+	for _, boost := range ast.Boosts {
+		if boost.Field != nil {
+			fmt.Fprintf(&b, " boost=field:%s", boost.Field.Name)
+			continue
+		}
+		b.WriteString(" boost=")
+		writeFilterShape(&b, boost.Filter)
+	}
+	if ast.Scoring != nil {
+		fmt.Fprintf(&b, " scoring=%s:%s", ast.Scoring.Provider, ast.Scoring.Kind)
+	}
+
+	fields := make([]string, 0, len(ast.MetadataFields)+len(ast.Updates))
+	for _, f := range ast.MetadataFields {
+		fields = append(fields, "select:"+f.Name)
+	}
+	for f := range ast.Updates {
+		fields = append(fields, "set:"+f.Name)
+	}
+	sort.Strings(fields)
+	for _, f := range fields {
+		b.WriteString(" " + f)
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+func writeFilterShape(b *strings.Builder, f types.FilterItem) {
+	switch filter := f.(type) {
+	case types.FilterCondition:
+		fmt.Fprintf(b, "(%s %s)", filter.Operator, filter.Field.Name)
+	case types.FilterGroup:
+		fmt.Fprintf(b, "(%s", filter.Logic)
+		for _, c := range filter.Conditions {
+			b.WriteString(" ")
+			writeFilterShape(b, c)
+		}
+		b.WriteString(")")
+	case types.RangeFilter:
+		fmt.Fprintf(b, "(RANGE %s %t %t)", filter.Field.Name, filter.Min != nil, filter.Max != nil)
+	case types.GeoFilter:
+		fmt.Fprintf(b, "(GEO %s)", filter.Field.Name)
+	case types.ExtensionFilter:
+		fmt.Fprintf(b, "(EXT %s)", filter.Provider)
+	default:
+		fmt.Fprintf(b, "(%T)", f)
+	}
+}
+
+// paramSizes reports the size of each named parameter without its value: the
+// length of strings, slices, and maps, and 1 for scalars.
+func paramSizes(names []string, params map[string]interface{}) map[string]int {
+	sizes := make(map[string]int, len(names))
+	for _, name := range names {
+		value := params[name]
+		if mv, ok := value.(ModelVector); ok {
+			value = mv.Values
+		}
+		switch v := reflect.ValueOf(value); v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+			sizes[name] = v.Len()
+		default:
+			sizes[name] = 1
+		}
+	}
+	return sizes
+}
@@ -0,0 +1,28 @@
+package vectql
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestFingerprint_IgnoresValues(t *testing.T) {
+	build := func(param string, topK int) string {
+		ast, err := shadowQuery().TopK(topK).Vector(Vec(types.Param{Name: param})).Build()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return Fingerprint(ast)
+	}
+	if build("a", 3) != build("b", 7) {
+		t.Error("expected queries differing only in values to share a fingerprint")
+	}
+
+	ast, err := Delete(types.Collection{Name: "products"}).IDs(types.Param{Name: "id"}).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if Fingerprint(ast) == build("a", 3) {
+		t.Error("expected different operations to have different fingerprints")
+	}
+}
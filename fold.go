@@ -0,0 +1,218 @@
+package vectql
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// foldCase prepares case-insensitive comparisons for renderer. Equality
+// filters on fields declared case-insensitive become EqFold. Renderers that
+// support EqFold render it natively; for the rest, EqFold compares the
+// field's lowercased shadow field (see MetadataField.Shadow) against a
+// derived copy of the parameter that is lowercased at bind time, so the
+// caller's value is unchanged wherever else the query uses it. Writers must
+// keep the shadow field populated for the fallback to match.
+//
+// The AST is returned unchanged when the query has no case-insensitive
+// comparisons; otherwise a rewritten copy is returned, with the derived
+// parameters mapped to the parameters they copy.
+func foldCase(ast *types.VectorAST, renderer Renderer) (*types.VectorAST, map[string]string) {
+	f := &folder{
+		native: renderer.SupportsFilter(types.EqFold),
+		taken:  make(map[string]bool),
+	}
+	for _, spec := range ast.ParamSpecs() {
+		f.taken[spec.Name] = true
+	}
+
+	filter, changed := f.filter(ast.FilterClause)
+	boosts := ast.Boosts
+	copied := false
+	for i, boost := range ast.Boosts {
+		if boost.Filter == nil {
+			continue
+		}
+		rewritten, ok := f.filter(boost.Filter)
+		if !ok {
+			continue
+		}
+		if !copied {
+			boosts = append([]types.Boost(nil), ast.Boosts...)
+			copied = true
+		}
+		boosts[i].Filter = rewritten
+		changed = true
+	}
+	if !changed {
+		return ast, nil
+	}
+
+	folded := *ast
+	folded.FilterClause = filter
+	folded.Boosts = boosts
+	if len(f.derived) > 0 {
+		// A parameter used only in folded comparisons leaves the query, and
+		// its transforms with it
+		used := make(map[string]bool)
+		for _, spec := range folded.ParamSpecs() {
+			used[spec.Name] = true
+		}
+		transforms := make(map[string][]types.BindTransform, len(ast.BindTransforms)+len(f.derived))
+		for name, chain := range ast.BindTransforms {
+			if used[name] {
+				transforms[name] = chain
+			}
+		}
+		for name, source := range f.derived {
+			chain := ast.BindTransforms[source]
+			if !hasLowercase(chain) {
+				chain = append(append([]types.BindTransform(nil), chain...), types.BindTransform{Op: types.TransformLower})
+			}
+			transforms[name] = chain
+		}
+		folded.BindTransforms = transforms
+	}
+	return &folded, f.derived
+}
+
+// folder rewrites the case-insensitive comparisons of a query.
+type folder struct {
+	native bool
+
+	// taken holds the parameter names in use, so derived names are unique.
+	taken map[string]bool
+
+	// derived maps each derived parameter to the parameter it copies, and
+	// lowered is its inverse.
+	derived map[string]string
+	lowered map[string]string
+}
+
+// lower returns the derived parameter holding the lowercased value of p.
+func (f *folder) lower(p types.Param) types.Param {
+	if name, ok := f.lowered[p.Name]; ok {
+		return types.Param{Name: name}
+	}
+	name := p.Name + "_folded"
+	for i := 2; f.taken[name]; i++ {
+		name = fmt.Sprintf("%s_folded%d", p.Name, i)
+	}
+	f.taken[name] = true
+	if f.derived == nil {
+		f.derived = make(map[string]string)
+		f.lowered = make(map[string]string)
+	}
+	f.derived[name] = p.Name
+	f.lowered[p.Name] = name
+	return types.Param{Name: name}
+}
+
+// filter rewrites the case-insensitive comparisons in item, reporting
+// whether anything changed.
+func (f *folder) filter(item types.FilterItem) (types.FilterItem, bool) {
+	switch filter := item.(type) {
+	case types.FilterCondition:
+		if filter.Operator == types.EQ && filter.Field.CaseInsensitive {
+			filter.Operator = types.EqFold
+		} else if filter.Operator != types.EqFold {
+			return item, false
+		}
+		if !f.native {
+			filter.Field = filter.Field.Shadow()
+			filter.Operator = types.EQ
+			filter.Value = f.lower(filter.Value)
+		}
+		return filter, true
+
+	case types.FilterGroup:
+		var conditions []types.FilterItem
+		for i, c := range filter.Conditions {
+			rewritten, ok := f.filter(c)
+			if !ok {
+				continue
+			}
+			if conditions == nil {
+				conditions = append([]types.FilterItem(nil), filter.Conditions...)
+			}
+			conditions[i] = rewritten
+		}
+		if conditions == nil {
+			return item, false
+		}
+		filter.Conditions = conditions
+		return filter, true
+
+	default:
+		return item, false
+	}
+}
+
+func hasLowercase(chain []types.BindTransform) bool {
+	for _, t := range chain {
+		if t.Op == types.TransformLower {
+			return true
+		}
+	}
+	return false
+}
+
+// deriveParams returns params with the value of each derived parameter
+// copied from the parameter it derives from. The caller's map is not
+// modified.
+func deriveParams(derived map[string]string, params map[string]interface{}) map[string]interface{} {
+	if len(derived) == 0 {
+		return params
+	}
+	values := make(map[string]interface{}, len(params)+len(derived))
+	for name, value := range params {
+		values[name] = value
+	}
+	for name, source := range derived {
+		if value, ok := params[source]; ok {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// derivedSpecs merges the specs of derived parameters into the specs of the
+// parameters they copy, so callers see only their own parameters.
+func derivedSpecs(specs []types.ParamSpec, derived map[string]string) []types.ParamSpec {
+	if len(derived) == 0 {
+		return specs
+	}
+	merged := make([]types.ParamSpec, 0, len(specs))
+	index := make(map[string]int, len(specs))
+	for _, spec := range specs {
+		if source, ok := derived[spec.Name]; ok {
+			spec.Name = source
+		}
+		i, ok := index[spec.Name]
+		if !ok {
+			index[spec.Name] = len(merged)
+			merged = append(merged, spec)
+			continue
+		}
+		merged[i].Repeats = true
+		for _, source := range spec.Sources {
+			if !slices.Contains(merged[i].Sources, source) {
+				merged[i].Sources = append(merged[i].Sources, source)
+			}
+		}
+	}
+	return merged
+}
+
+// requireSources adds the parameters derived parameters copy to required,
+// since the caller must supply them.
+func requireSources(required []string, derived map[string]string) []string {
+	out := append([]string(nil), required...)
+	for _, name := range required {
+		if source, ok := derived[name]; ok && !slices.Contains(out, source) {
+			out = append(out, source)
+		}
+	}
+	return out
+}
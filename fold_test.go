@@ -0,0 +1,122 @@
+package vectql
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/pkg/elasticsearch"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+	"github.com/zoobzio/vectql/pkg/redis"
+)
+
+func caseInsensitiveInstance(t *testing.T) *VECTQL {
+	t.Helper()
+	schema := testSchema()
+	schema.Collections["products"].Settings = map[string]string{"category" + SettingCaseInsensitiveSuffix: "true"}
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return v
+}
+
+func TestFoldCase_Native(t *testing.T) {
+	v := caseInsensitiveInstance(t)
+	result, err := Search(v.C("products")).
+		Vector(Vec(v.P("vec"))).
+		TopK(5).
+		Filter(v.Eq(v.M("products", "category"), v.P("cat"))).
+		Render(elasticsearch.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.JSON, `"term":{"category":{"case_insensitive":true,"value":":cat"}}`) {
+		t.Errorf("expected a case-insensitive term query, got %s", result.JSON)
+	}
+	if len(result.Transforms) != 0 {
+		t.Errorf("expected no bind transforms, got %v", result.Transforms)
+	}
+}
+
+func TestFoldCase_ShadowField(t *testing.T) {
+	v := caseInsensitiveInstance(t)
+	builder := Search(v.C("products")).
+		Vector(Vec(v.P("vec"))).
+		TopK(5).
+		Filter(v.And(
+			v.Eq(v.M("products", "category"), v.P("cat")),
+			v.EqFold(v.M("products", "location"), v.P("loc")),
+			v.Eq(v.M("products", "price"), v.P("price")),
+		)).
+		Transform(v.P("loc"), TrimSpace())
+	result, err := builder.Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{`"key":"category_lower"`, `"key":"location_lower"`, `"key":"price"`} {
+		if !strings.Contains(result.JSON, want) {
+			t.Errorf("expected %s in %s", want, result.JSON)
+		}
+	}
+
+	body, err := Bind(result, map[string]interface{}{"vec": []float32{1}, "cat": "Books", "loc": " Paris ", "price": 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(body, `"value":"books"`) || !strings.Contains(body, `"value":"paris"`) {
+		t.Errorf("expected lowercased values, got %s", body)
+	}
+
+	// The builder's own query is not modified
+	ast, err := builder.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if chain := ast.BindTransforms["loc"]; len(chain) != 1 {
+		t.Errorf("expected the builder's transforms to be unchanged, got %v", ast.BindTransforms)
+	}
+}
+
+func TestFoldCase_ReusedParam(t *testing.T) {
+	v := caseInsensitiveInstance(t)
+	builder := Search(v.C("products")).
+		Vector(Vec(v.P("vec"))).
+		TopK(5).
+		Filter(v.Or(
+			v.Eq(v.M("products", "category"), v.P("term")),
+			v.Eq(v.M("products", "location"), v.P("term")),
+		))
+	result, err := builder.Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.ParamSpecs) != 2 || result.ParamSpecs[1].Name != "term" || !result.ParamSpecs[1].Repeats {
+		t.Errorf("expected the derived parameter to merge into term, got %+v", result.ParamSpecs)
+	}
+
+	body, err := Bind(result, map[string]interface{}{"vec": []float32{1}, "term": "Paris"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Only the value compared against the shadow field is lowercased
+	if !strings.Contains(body, `"key":"category_lower","match":{"value":"paris"}`) {
+		t.Errorf("expected a lowercased shadow comparison, got %s", body)
+	}
+	if !strings.Contains(body, `"key":"location","match":{"value":"Paris"}`) {
+		t.Errorf("expected the caller's value elsewhere, got %s", body)
+	}
+
+	result, err = builder.Render(redis.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	commands, err := BindCommands(result.Commands, map[string]interface{}{"vec": []float32{1}, "term": "Paris"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	args := commands[0]
+	if !slices.Contains(args, interface{}("paris")) || !slices.Contains(args, interface{}("Paris")) {
+		t.Errorf("expected both the lowercased and the caller's value, got %v", args)
+	}
+}
@@ -0,0 +1,26 @@
+package vectql
+
+import (
+	"time"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Strong asks a read to see every acknowledged write. It maps to Milvus'
+// Strong consistency level and to Qdrant reads from all replicas.
+func Strong() types.Freshness {
+	return types.Freshness{Level: types.FreshnessStrong}
+}
+
+// Bounded asks a read to see data at most d behind the writes. It maps to
+// Milvus' Bounded consistency level, whose window is the server's
+// graceful_time, and to Qdrant majority reads.
+func Bounded(d time.Duration) types.Freshness {
+	return types.Freshness{Level: types.FreshnessBounded, MaxStaleness: d}
+}
+
+// Eventual lets a read see whatever the serving replica has, which is the
+// cheapest and the default for most providers.
+func Eventual() types.Freshness {
+	return types.Freshness{Level: types.FreshnessEventual}
+}
@@ -0,0 +1,77 @@
+package vectql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestFreshness(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+	search := func() *Builder {
+		return Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(10)
+	}
+
+	ast, err := search().Freshness(Bounded(5 * time.Second)).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f := ast.Freshness; f == nil || f.Level != FreshnessBounded || f.MaxStaleness != 5*time.Second {
+		t.Errorf("expected bounded freshness, got %#v", ast.Freshness)
+	}
+
+	if _, err := Fetch(coll).IDs(types.Param{Name: "id"}).Freshness(Strong()).Build(); err != nil {
+		t.Errorf("unexpected error for FETCH: %v", err)
+	}
+	if _, err := search().Freshness(Bounded(0)).Build(); err == nil {
+		t.Error("expected error for bounded freshness without staleness")
+	}
+	if _, err := search().Freshness(Freshness{Level: "linearizable"}).Build(); err == nil {
+		t.Error("expected error for an unknown level")
+	}
+	if _, err := Delete(coll).IDs(types.Param{Name: "id"}).Freshness(Strong()).Build(); err == nil {
+		t.Error("expected error for freshness on a write")
+	}
+
+	plain, _ := search().Build()
+	strong, _ := search().Freshness(Strong()).Build()
+	if Fingerprint(plain) == Fingerprint(strong) {
+		t.Error("expected freshness to change the fingerprint")
+	}
+}
+
+func TestFreshness_Warnings(t *testing.T) {
+	query := func() *Builder {
+		return Search(types.Collection{Name: "products"}).
+			Vector(Vec(types.Param{Name: "v"})).
+			TopK(10).
+			Freshness(Strong())
+	}
+
+	_, warnings, err := query().RenderWithWarnings(pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found := false
+	for _, w := range warnings {
+		if w.String() == "ignored_option: pinecone ignores the freshness option" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected ignored freshness warning, got %v", warnings)
+	}
+
+	_, warnings, err = query().RenderWithWarnings(qdrant.NewQuery())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, w := range warnings {
+		if w.Code == WarnIgnoredOption {
+			t.Errorf("unexpected warning for qdrant: %v", w)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package vectql
+
+import (
+	"context"
+	"time"
+)
+
+// Hedge returns an executor that cuts tail latency for reads. When a SEARCH
+// or FETCH has not completed after delay, a duplicate request is sent and the
+// first successful response wins; the other request's context is cancelled.
+// If both fail, the first error is returned; a request that fails before the
+// delay is not hedged. Writes pass through unhedged,
+// since duplicating them is not safe in general.
+func Hedge(next Executor, delay time.Duration) Executor {
+	return ExecutorFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		if AccessOf(req.Operation) != AccessRead {
+			return next.Execute(ctx, req)
+		}
+
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type outcome struct {
+			resp *Response
+			err  error
+		}
+		results := make(chan outcome, 2)
+		send := func() {
+			resp, err := next.Execute(ctx, req)
+			results <- outcome{resp: resp, err: err}
+		}
+
+		go send()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		pending := 1
+		var firstErr error
+		for {
+			select {
+			case <-timer.C:
+				pending++
+				go send()
+			case o := <-results:
+				pending--
+				if o.err == nil {
+					return o.resp, nil
+				}
+				if firstErr == nil {
+					firstErr = o.err
+				}
+				if pending == 0 {
+					return nil, firstErr
+				}
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedge_DuplicateWins(t *testing.T) {
+	var calls atomic.Int32
+	var cancelled atomic.Bool
+	executor := Hedge(ExecutorFunc(func(ctx context.Context, _ *Request) (*Response, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			cancelled.Store(true)
+			return nil, ctx.Err()
+		}
+		return &Response{Matches: []Match{{ID: "hedged"}}}, nil
+	}), time.Millisecond)
+
+	resp, err := executor.Execute(context.Background(), &Request{Operation: OpSearch})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.Matches) != 1 || resp.Matches[0].ID != "hedged" {
+		t.Errorf("expected the hedged response, got %+v", resp)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 calls, got %d", calls.Load())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !cancelled.Load() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !cancelled.Load() {
+		t.Error("expected the slow request to be cancelled")
+	}
+}
+
+func TestHedge_FastResponseNotHedged(t *testing.T) {
+	var calls atomic.Int32
+	executor := Hedge(ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+		calls.Add(1)
+		return &Response{}, nil
+	}), time.Hour)
+
+	if _, err := executor.Execute(context.Background(), &Request{Operation: OpFetch}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected 1 call, got %d", calls.Load())
+	}
+}
+
+func TestHedge_WritesPassThrough(t *testing.T) {
+	var calls atomic.Int32
+	executor := Hedge(ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+		calls.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return nil, errors.New("failed")
+	}), time.Microsecond)
+
+	if _, err := executor.Execute(context.Background(), &Request{Operation: OpUpsert}); err == nil {
+		t.Error("expected error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("expected writes not to be hedged, got %d calls", calls.Load())
+	}
+}
+
+func TestHedge_BothFail(t *testing.T) {
+	executor := Hedge(ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+		time.Sleep(2 * time.Millisecond)
+		return nil, errors.New("unavailable")
+	}), time.Millisecond)
+
+	if _, err := executor.Execute(context.Background(), &Request{Operation: OpSearch}); err == nil || err.Error() != "unavailable" {
+		t.Errorf("expected unavailable error, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package vectql
+
+import "github.com/zoobzio/vectql/internal/types"
+
+// HintOption sets a planner hint on a filter condition.
+type HintOption func(*types.FilterHint)
+
+// Indexed hints that the condition's field has a payload or keyword index.
+// Qdrant searches restrict themselves to indexed segments when every
+// condition of the filter is indexed.
+func Indexed(h *types.FilterHint) {
+	h.Indexed = true
+}
+
+// Selectivity hints the expected fraction of documents the condition
+// matches, in (0, 1]. Elasticsearch widens num_candidates for selective
+// filters so filtered kNN searches still find k results.
+func Selectivity(fraction float64) HintOption {
+	return func(h *types.FilterHint) {
+		h.Selectivity = fraction
+	}
+}
+
+// Hint attaches planner hints to a filter condition:
+//
+//	vectql.Hint(vectql.Eq(tenant, tenantID), vectql.Indexed, vectql.Selectivity(0.001))
+//
+// Hints never change which documents match. Renderers without a matching
+// planner setting ignore them.
+func Hint(c types.FilterCondition, opts ...HintOption) types.FilterCondition {
+	h := types.FilterHint{}
+	if c.Hint != nil {
+		h = *c.Hint
+	}
+	for _, opt := range opts {
+		opt(&h)
+	}
+	c.Hint = &h
+	return c
+}
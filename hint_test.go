@@ -0,0 +1,78 @@
+package vectql
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/elasticsearch"
+)
+
+func TestHint(t *testing.T) {
+	tenant := types.MetadataField{Name: "tenant"}
+	c := Hint(Eq(tenant, types.Param{Name: "t"}), Indexed)
+	c = Hint(c, Selectivity(0.01))
+	if c.Hint == nil || !c.Hint.Indexed || c.Hint.Selectivity != 0.01 {
+		t.Errorf("expected merged hints, got %#v", c.Hint)
+	}
+
+	if _, err := Search(types.Collection{Name: "docs"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		Filter(Hint(Eq(tenant, types.Param{Name: "t"}), Selectivity(2))).
+		Build(); err == nil {
+		t.Error("expected error for a selectivity above 1")
+	}
+}
+
+func TestSelectivityEstimate(t *testing.T) {
+	field := types.MetadataField{Name: "f"}
+	hinted := func(s float64) types.FilterCondition {
+		return Hint(Eq(field, types.Param{Name: "p"}), Selectivity(s))
+	}
+	plain := Eq(field, types.Param{Name: "p"})
+
+	tests := []struct {
+		name   string
+		filter types.FilterItem
+		want   float64
+		known  bool
+	}{
+		{"condition", hinted(0.1), 0.1, true},
+		{"unhinted", plain, 0, false},
+		{"and", And(hinted(0.1), hinted(0.5), plain), 0.05, true},
+		{"and of unhinted", And(plain, plain), 0, false},
+		{"or", Or(hinted(0.1), hinted(0.5)), 0.55, true},
+		{"or with unhinted", Or(hinted(0.1), plain), 0, false},
+		{"not", Not(hinted(0.1)), 0.9, true},
+	}
+	for _, tt := range tests {
+		got, known := types.Selectivity(tt.filter)
+		if known != tt.known || math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: expected %g (%t), got %g (%t)", tt.name, tt.want, tt.known, got, known)
+		}
+	}
+
+	if !types.IndexedOnly(And(Hint(plain, Indexed), Hint(plain, Indexed))) {
+		t.Error("expected an AND of indexed conditions to be indexed")
+	}
+	if types.IndexedOnly(And(Hint(plain, Indexed), plain)) {
+		t.Error("expected an unindexed condition to make the filter unindexed")
+	}
+}
+
+func TestHint_Elasticsearch(t *testing.T) {
+	tenant := types.MetadataField{Name: "tenant"}
+	result, err := Search(types.Collection{Name: "docs"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		Filter(Hint(Eq(tenant, types.Param{Name: "t"}), Selectivity(0.05))).
+		Render(elasticsearch.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.JSON, `"num_candidates":2000`) {
+		t.Errorf("expected num_candidates widened to 2000: %s", result.JSON)
+	}
+}
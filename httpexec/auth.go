@@ -0,0 +1,136 @@
+package httpexec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Credentials authenticate an outgoing request, typically by setting a
+// header. Implementations must be safe for concurrent use.
+type Credentials interface {
+	Apply(req *http.Request) error
+}
+
+// CredentialsFunc adapts a function to the Credentials interface.
+type CredentialsFunc func(req *http.Request) error
+
+// Apply calls f.
+func (f CredentialsFunc) Apply(req *http.Request) error {
+	return f(req)
+}
+
+// APIKey sets header to key on every request, e.g. APIKey("Api-Key", key)
+// for Pinecone or APIKey("Authorization", "ApiKey "+encoded) for
+// Elasticsearch.
+func APIKey(header, key string) Credentials {
+	return CredentialsFunc(func(req *http.Request) error {
+		req.Header.Set(header, key)
+		return nil
+	})
+}
+
+// Token is an access token and the time it expires. A zero Expiry never
+// expires.
+type Token struct {
+	Value  string
+	Expiry time.Time
+}
+
+// TokenSource supplies access tokens.
+type TokenSource interface {
+	Token(ctx context.Context) (Token, error)
+}
+
+// TokenSourceFunc adapts a function to the TokenSource interface.
+type TokenSourceFunc func(ctx context.Context) (Token, error)
+
+// Token calls f.
+func (f TokenSourceFunc) Token(ctx context.Context) (Token, error) {
+	return f(ctx)
+}
+
+// TokenRefreshMargin is how long before expiry Bearer fetches a new token.
+const TokenRefreshMargin = time.Minute
+
+// Bearer sends "Authorization: Bearer <token>". Tokens are cached and
+// fetched again from src shortly before they expire.
+func Bearer(src TokenSource) Credentials {
+	b := &bearer{src: src}
+	return CredentialsFunc(b.apply)
+}
+
+type bearer struct {
+	src   TokenSource
+	mu    sync.Mutex
+	token Token
+}
+
+func (b *bearer) apply(req *http.Request) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.token.Value == "" || (!b.token.Expiry.IsZero() && time.Until(b.token.Expiry) < TokenRefreshMargin) {
+		token, err := b.src.Token(req.Context())
+		if err != nil {
+			return err
+		}
+		b.token = token
+	}
+	req.Header.Set("Authorization", "Bearer "+b.token.Value)
+	return nil
+}
+
+// gcpMetadataTokenPath is the metadata server path of the default service
+// account's access token.
+const gcpMetadataTokenPath = "/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCPMetadataToken fetches access tokens for the default service account
+// from the GCE metadata server, as available on GCE, GKE, and Cloud Run.
+// Use it with Bearer for Vertex AI. GCE_METADATA_HOST overrides the
+// metadata server address. A nil client uses http.DefaultClient.
+func GCPMetadataToken(client *http.Client) TokenSource {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return TokenSourceFunc(func(ctx context.Context) (Token, error) {
+		host := os.Getenv("GCE_METADATA_HOST")
+		if host == "" {
+			host = "metadata.google.internal"
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+host+gcpMetadataTokenPath, nil)
+		if err != nil {
+			return Token{}, err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return Token{}, fmt.Errorf("metadata server: %w", err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return Token{}, fmt.Errorf("metadata server: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return Token{}, fmt.Errorf("metadata server returned %d: %s", resp.StatusCode, body)
+		}
+
+		var token struct {
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}
+		if err := json.Unmarshal(body, &token); err != nil {
+			return Token{}, fmt.Errorf("metadata server: invalid token response: %w", err)
+		}
+		return Token{
+			Value:  token.AccessToken,
+			Expiry: time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+		}, nil
+	})
+}
@@ -0,0 +1,64 @@
+package httpexec
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBearer_Refresh(t *testing.T) {
+	calls := 0
+	lifetime := time.Hour
+	src := TokenSourceFunc(func(context.Context) (Token, error) {
+		calls++
+		return Token{Value: fmt.Sprintf("t%d", calls), Expiry: time.Now().Add(lifetime)}, nil
+	})
+	creds := Bearer(src)
+
+	apply := func() string {
+		req, _ := http.NewRequest(http.MethodGet, "https://example.com/", nil)
+		if err := creds.Apply(req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return req.Header.Get("Authorization")
+	}
+
+	if got := apply(); got != "Bearer t1" {
+		t.Errorf("expected Bearer t1, got %s", got)
+	}
+	if got := apply(); got != "Bearer t1" || calls != 1 {
+		t.Errorf("expected the cached token, got %s after %d calls", got, calls)
+	}
+
+	// A token inside the refresh margin is replaced on next use
+	lifetime = TokenRefreshMargin / 2
+	creds = Bearer(src)
+	apply()
+	if got := apply(); got != "Bearer t3" || calls != 3 {
+		t.Errorf("expected a refreshed token near expiry, got %s after %d calls", got, calls)
+	}
+}
+
+func TestGCPMetadataToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" || r.URL.Path != gcpMetadataTokenPath {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"ya29.token","expires_in":3599,"token_type":"Bearer"}`))
+	}))
+	defer server.Close()
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(server.URL, "http://"))
+
+	token, err := GCPMetadataToken(nil).Token(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if token.Value != "ya29.token" || time.Until(token.Expiry) < 59*time.Minute {
+		t.Errorf("unexpected token: %+v", token)
+	}
+}
@@ -0,0 +1,167 @@
+// Package httpexec sends prepared vectql requests to REST-based providers
+// over net/http, so rendered queries run against managed services without a
+// hand-written transport:
+//
+//	exec, err := httpexec.New("https://my-index.svc.pinecone.io",
+//	    httpexec.WithCredentials(httpexec.APIKey("Api-Key", key)))
+//	req, err := vectql.Prepare(query, pinecone.New(), params)
+//	resp, err := exec.Execute(ctx, req)
+//
+// Responses are not decoded unless a Decoder is configured; the raw body is
+// left to the decoder because every provider shapes results differently.
+// Qdrant and Pinecone page cursors are read into Response.NextPage either
+// way, so vectql.Scroll pages through them.
+package httpexec
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/zoobzio/vectql"
+)
+
+// PineconeAPIVersionHeader carries the API version Pinecone requests were
+// rendered for.
+const PineconeAPIVersionHeader = "X-Pinecone-API-Version"
+
+// Content types sent with request bodies.
+const (
+	ContentTypeJSON   = "application/json"
+	ContentTypeNDJSON = "application/x-ndjson"
+)
+
+// Decoder converts a successful provider response body into a Response.
+type Decoder func(req *vectql.Request, body []byte) (*vectql.Response, error)
+
+// StatusError is returned for responses with a non-2xx status.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("provider returned %d: %s", e.StatusCode, e.Body)
+}
+
+// Option configures an Executor.
+type Option func(*Executor)
+
+// WithClient sets the HTTP client. The default is http.DefaultClient.
+// Transport options apply to a copy of c, leaving c itself unchanged.
+func WithClient(c *http.Client) Option {
+	return func(e *Executor) {
+		e.client = c
+	}
+}
+
+// WithCredentials authenticates every request with c.
+func WithCredentials(c Credentials) Option {
+	return func(e *Executor) {
+		e.credentials = c
+	}
+}
+
+// WithDecoder decodes response bodies with d.
+func WithDecoder(d Decoder) Option {
+	return func(e *Executor) {
+		e.decode = d
+	}
+}
+
+// Executor sends requests to one provider base URL. It implements
+// vectql.Executor.
+type Executor struct {
+	baseURL     string
+	client      *http.Client
+	credentials Credentials
+	decode      Decoder
+	transport   transportConfig
+}
+
+// New creates an executor for the provider at baseURL. Endpoint paths are
+// appended to it. It returns an error when proxy or TLS options cannot
+// configure the transport.
+func New(baseURL string, opts ...Option) (*Executor, error) {
+	e := &Executor{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	if e.transport.configured() {
+		rt, err := e.transport.build(e.client.Transport)
+		if err != nil {
+			return nil, err
+		}
+		client := *e.client
+		client.Transport = rt
+		e.client = &client
+	}
+	return e, nil
+}
+
+// Execute sends req. Setup requests with CreateIfMissing treat 409 and 422
+// answers as success, since they report that the resource already exists.
+// Requests from Prepare carry their bound query string in Endpoint.Path; an
+// Endpoint.Query set on a request built by hand is appended as is.
+func (e *Executor) Execute(ctx context.Context, req *vectql.Request) (*vectql.Response, error) {
+	if req.Endpoint.Method == "" {
+		return nil, fmt.Errorf("request for %s has no endpoint", req.Provider)
+	}
+
+	target := e.baseURL + req.Endpoint.Path
+	if req.Endpoint.Query != "" {
+		target += "?" + req.Endpoint.Query
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, req.Endpoint.Method, target, strings.NewReader(req.Body))
+	if err != nil {
+		return nil, err
+	}
+	if req.Endpoint.Lines {
+		httpReq.Header.Set("Content-Type", ContentTypeNDJSON)
+	} else {
+		httpReq.Header.Set("Content-Type", ContentTypeJSON)
+	}
+	if req.ContentEncoding != "" {
+		httpReq.Header.Set("Content-Encoding", req.ContentEncoding)
+	}
+	if req.Provider == "pinecone" && req.APIVersion != "" {
+		httpReq.Header.Set(PineconeAPIVersionHeader, req.APIVersion)
+	}
+	if e.credentials != nil {
+		if err := e.credentials.Apply(httpReq); err != nil {
+			return nil, fmt.Errorf("failed to authenticate request: %w", err)
+		}
+	}
+
+	resp, err := e.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if req.CreateIfMissing && (resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusUnprocessableEntity) {
+			return &vectql.Response{}, nil
+		}
+		return nil, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	out := &vectql.Response{}
+	if e.decode != nil {
+		if out, err = e.decode(req, body); err != nil {
+			return nil, err
+		}
+	}
+	if out.NextPage.IsZero() {
+		out.NextPage = nextPage(req, body)
+	}
+	return out, nil
+}
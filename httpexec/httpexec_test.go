@@ -0,0 +1,137 @@
+package httpexec
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zoobzio/vectql"
+)
+
+func TestExecute(t *testing.T) {
+	var got *http.Request
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		got, body = r, string(data)
+		_, _ = w.Write([]byte(`{"matches":[{"id":"a"}]}`))
+	}))
+	defer server.Close()
+
+	decode := func(_ *vectql.Request, data []byte) (*vectql.Response, error) {
+		var result struct {
+			Matches []vectql.Match `json:"matches"`
+		}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, err
+		}
+		return &vectql.Response{Matches: result.Matches}, nil
+	}
+	exec, err := New(server.URL+"/", WithCredentials(APIKey("Api-Key", "secret")), WithDecoder(decode))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	resp, err := exec.Execute(context.Background(), &vectql.Request{
+		Endpoint:        vectql.Endpoint{Method: "POST", Path: "/query"},
+		Body:            `{"topK":1}`,
+		ContentEncoding: "gzip",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.URL.Path != "/query" || body != `{"topK":1}` {
+		t.Errorf("unexpected request: %s %s", got.URL.Path, body)
+	}
+	if got.Header.Get("Api-Key") != "secret" || got.Header.Get("Content-Type") != ContentTypeJSON || got.Header.Get("Content-Encoding") != "gzip" {
+		t.Errorf("unexpected headers: %v", got.Header)
+	}
+	if len(resp.Matches) != 1 || resp.Matches[0].ID != "a" {
+		t.Errorf("unexpected response: %+v", resp)
+	}
+
+	if _, err := exec.Execute(context.Background(), &vectql.Request{
+		Endpoint: vectql.Endpoint{Method: "POST", Path: "/_bulk", Lines: true},
+		Body:     "{}\n",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Header.Get("Content-Type") != ContentTypeNDJSON {
+		t.Errorf("expected ndjson content type, got %s", got.Header.Get("Content-Type"))
+	}
+
+	if _, err := exec.Execute(context.Background(), &vectql.Request{
+		Endpoint: vectql.Endpoint{Method: "DELETE", Path: "/collections/products/documents", Query: "filter_by=category%3A%3Dshoes"},
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.URL.Path != "/collections/products/documents" || got.URL.Query().Get("filter_by") != "category:=shoes" {
+		t.Errorf("expected the endpoint query to be sent, got %s", got.URL)
+	}
+}
+
+func TestExecute_Status(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		_, _ = w.Write([]byte("tenant exists"))
+	}))
+	defer server.Close()
+	exec, err := New(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := &vectql.Request{Endpoint: vectql.Endpoint{Method: "POST", Path: "/v1/schema/Products/tenants"}}
+	_, err = exec.Execute(context.Background(), req)
+	var status *StatusError
+	if !errors.As(err, &status) || status.StatusCode != 422 || status.Body != "tenant exists" {
+		t.Errorf("expected a 422 StatusError, got %v", err)
+	}
+
+	req.CreateIfMissing = true
+	if _, err := exec.Execute(context.Background(), req); err != nil {
+		t.Errorf("expected already-exists to succeed, got %v", err)
+	}
+
+	if _, err := exec.Execute(context.Background(), &vectql.Request{Provider: "milvus"}); err == nil {
+		t.Error("expected an error for a request without an endpoint")
+	}
+}
+
+func TestExecute_PineconeAPIVersion(t *testing.T) {
+	var version string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version = r.Header.Get(PineconeAPIVersionHeader)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	exec, err := New(server.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		req      *vectql.Request
+		expected string
+	}{
+		{"pinecone", &vectql.Request{Provider: "pinecone", APIVersion: "2025-04"}, "2025-04"},
+		{"pinecone legacy API", &vectql.Request{Provider: "pinecone"}, ""},
+		{"other provider", &vectql.Request{Provider: "qdrant", APIVersion: "2025-04"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Endpoint = vectql.Endpoint{Method: "POST", Path: "/query"}
+			if _, err := exec.Execute(context.Background(), tt.req); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if version != tt.expected {
+				t.Errorf("expected API version header %q, got %q", tt.expected, version)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package httpexec
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/zoobzio/vectql"
+)
+
+// pageBody holds the cursors providers return with a page of results:
+// Qdrant's scroll next_page_offset, Pinecone's list pagination.next, the
+// objects Weaviate's after cursor resumes from, and the sort values of
+// Elasticsearch and OpenSearch hits that search_after resumes from.
+type pageBody struct {
+	Result struct {
+		NextPageOffset json.RawMessage `json:"next_page_offset"`
+	} `json:"result"`
+	Pagination struct {
+		Next string `json:"next"`
+	} `json:"pagination"`
+
+	// Objects is a Weaviate REST object listing; Data.Get holds the
+	// objects of a GraphQL Get query by class.
+	Objects []weaviateObject `json:"objects"`
+	Data    struct {
+		Get map[string][]struct {
+			Additional weaviateObject `json:"_additional"`
+		} `json:"Get"`
+	} `json:"data"`
+
+	Hits struct {
+		Hits []struct {
+			Sort json.RawMessage `json:"sort"`
+		} `json:"hits"`
+	} `json:"hits"`
+}
+
+type weaviateObject struct {
+	ID string `json:"id"`
+}
+
+// nextPage returns the token for the page after body, the response to req,
+// or the zero token when body is the last page or the provider returns no
+// cursor.
+func nextPage(req *vectql.Request, body []byte) vectql.PageToken {
+	var page pageBody
+	if err := json.Unmarshal(body, &page); err != nil {
+		return vectql.PageToken{}
+	}
+	provider := req.Provider
+	var cursor string
+	switch provider {
+	case "qdrant":
+		// Offsets are point IDs: unsigned integers or UUID strings. The
+		// last page has a null offset, which leaves cursor empty.
+		if err := json.Unmarshal(page.Result.NextPageOffset, &cursor); err != nil {
+			cursor = strings.TrimSpace(string(page.Result.NextPageOffset))
+		}
+	case "pinecone":
+		cursor = page.Pagination.Next
+	case "weaviate":
+		// Only listings resume with after; searches return no cursor
+		if req.Listing {
+			cursor = weaviateCursor(page)
+		}
+	case "elasticsearch", "opensearch":
+		// search_after takes the sort values of the last hit, passed back
+		// as the JSON array they were returned as. Hits of an unsorted
+		// query have none.
+		if hits := page.Hits.Hits; len(hits) > 0 {
+			cursor = compactJSON(hits[len(hits)-1].Sort)
+		}
+	}
+	if cursor == "" {
+		return vectql.PageToken{}
+	}
+	return vectql.NewPageToken(provider, cursor)
+}
+
+// weaviateCursor returns the ID of the last object of a Weaviate page, which
+// the after parameter resumes from. Weaviate returns no explicit cursor, so
+// only an empty page ends the iteration.
+func weaviateCursor(page pageBody) string {
+	objects := page.Objects
+	if len(objects) == 0 {
+		// A Get query selects a single class.
+		for _, results := range page.Data.Get {
+			for _, r := range results {
+				objects = append(objects, r.Additional)
+			}
+		}
+	}
+	if len(objects) == 0 {
+		return ""
+	}
+	return objects[len(objects)-1].ID
+}
+
+// compactJSON returns raw without insignificant whitespace, or the empty
+// string for missing, null, or empty array values.
+func compactJSON(raw json.RawMessage) string {
+	var values []interface{}
+	if err := json.Unmarshal(raw, &values); err != nil || len(values) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, raw); err != nil {
+		return ""
+	}
+	return buf.String()
+}
@@ -0,0 +1,114 @@
+package httpexec
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/zoobzio/vectql"
+)
+
+func TestNextPage(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		body     string
+		expected string
+	}{
+		{"qdrant uuid offset", "qdrant", `{"result":{"points":[],"next_page_offset":"5c56c793-69f3-4fbf-87e6-c4bf54c28c26"}}`, "5c56c793-69f3-4fbf-87e6-c4bf54c28c26"},
+		{"qdrant integer offset", "qdrant", `{"result":{"points":[],"next_page_offset":42}}`, "42"},
+		{"qdrant last page", "qdrant", `{"result":{"points":[],"next_page_offset":null}}`, ""},
+		{"pinecone", "pinecone", `{"vectors":[],"pagination":{"next":"tok"}}`, "tok"},
+		{"pinecone last page", "pinecone", `{"vectors":[]}`, ""},
+		{"weaviate graphql", "weaviate", `{"data":{"Get":{"Products":[{"_additional":{"id":"a"}},{"_additional":{"id":"b"}}]}}}`, "b"},
+		{"weaviate objects", "weaviate", `{"objects":[{"id":"a"},{"id":"b"}],"totalResults":2}`, "b"},
+		{"weaviate last page", "weaviate", `{"data":{"Get":{"Products":[]}}}`, ""},
+		{"elasticsearch search_after", "elasticsearch", `{"hits":{"hits":[{"_id":"a","sort":[0.9, "a"]},{"_id":"b","sort":[0.8, "b"]}]}}`, `[0.8,"b"]`},
+		{"elasticsearch unsorted", "elasticsearch", `{"hits":{"hits":[{"_id":"a"}]}}`, ""},
+		{"elasticsearch last page", "elasticsearch", `{"hits":{"hits":[]}}`, ""},
+		{"opensearch search_after", "opensearch", `{"hits":{"hits":[{"_id":"a","sort":[1700000000,"a"]}]}}`, `[1700000000,"a"]`},
+		{"other provider", "milvus", `{"pagination":{"next":"tok"}}`, ""},
+		{"not json", "qdrant", `ok`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := nextPage(&vectql.Request{Provider: tt.provider, Listing: true}, []byte(tt.body))
+			if token.Cursor() != tt.expected {
+				t.Errorf("expected cursor %q, got %q", tt.expected, token.Cursor())
+			}
+			if !token.IsZero() && token.Provider() != tt.provider {
+				t.Errorf("expected provider %s, got %s", tt.provider, token.Provider())
+			}
+		})
+	}
+}
+
+func TestNextPage_WeaviateSearch(t *testing.T) {
+	body := `{"data":{"Get":{"Products":[{"_additional":{"id":"a","distance":0.1}}]}}}`
+	if token := nextPage(&vectql.Request{Provider: "weaviate"}, []byte(body)); !token.IsZero() {
+		t.Errorf("expected no cursor for a search, got %q", token.Cursor())
+	}
+}
+
+func TestExecute_Scroll(t *testing.T) {
+	pages := map[string]string{
+		"":  `{"result":{"points":[{"id":1},{"id":2}],"next_page_offset":3}}`,
+		"3": `{"result":{"points":[{"id":3}],"next_page_offset":null}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		_, _ = w.Write([]byte(pages[string(data)]))
+	}))
+	defer server.Close()
+
+	decode := func(_ *vectql.Request, data []byte) (*vectql.Response, error) {
+		var body struct {
+			Result struct {
+				Points []struct {
+					ID json.Number `json:"id"`
+				} `json:"points"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(data, &body); err != nil {
+			return nil, err
+		}
+		resp := &vectql.Response{}
+		for _, p := range body.Result.Points {
+			resp.Matches = append(resp.Matches, vectql.Match{ID: p.ID.String()})
+		}
+		return resp, nil
+	}
+	exec, err := New(server.URL, WithDecoder(decode))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	page := func(token vectql.PageToken) (*vectql.Request, error) {
+		var cursor string
+		if !token.IsZero() {
+			var err error
+			if cursor, err = token.CursorFor("qdrant"); err != nil {
+				return nil, err
+			}
+		}
+		return &vectql.Request{
+			Provider: "qdrant",
+			Endpoint: vectql.Endpoint{Method: "POST", Path: "/collections/products/points/scroll"},
+			Body:     cursor,
+		}, nil
+	}
+
+	var ids []string
+	for m, err := range vectql.Scroll(context.Background(), exec, page) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, m.ID)
+	}
+	if len(ids) != 3 || ids[2] != "3" {
+		t.Errorf("expected three matches over two pages, got %v", ids)
+	}
+}
@@ -0,0 +1,168 @@
+package httpexec
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSCredentials are the keys requests are signed with.
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// SessionToken is set for temporary credentials.
+	SessionToken string
+}
+
+// AWSCredentialsProvider supplies AWS credentials, such as the static keys
+// or the rotating role credentials of the environment.
+type AWSCredentialsProvider func(ctx context.Context) (AWSCredentials, error)
+
+// StaticAWSCredentials returns the same credentials on every call.
+func StaticAWSCredentials(creds AWSCredentials) AWSCredentialsProvider {
+	return func(context.Context) (AWSCredentials, error) {
+		return creds, nil
+	}
+}
+
+// SigV4 signs requests with AWS Signature Version 4, as Amazon OpenSearch
+// Service ("es") and OpenSearch Serverless ("aoss") require.
+func SigV4(region, service string, creds AWSCredentialsProvider) Credentials {
+	return CredentialsFunc(func(req *http.Request) error {
+		c, err := creds(req.Context())
+		if err != nil {
+			return err
+		}
+		payload, err := payloadHash(req)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC()
+		req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+		req.Header.Set("X-Amz-Content-Sha256", payload)
+		if c.SessionToken != "" {
+			req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+		}
+		signV4(req, c, region, service, payload, now)
+		return nil
+	})
+}
+
+// payloadHash returns the hex SHA-256 of the request body without
+// consuming it.
+func payloadHash(req *http.Request) (string, error) {
+	h := sha256.New()
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return "", fmt.Errorf("cannot sign a request body that cannot be re-read")
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		defer body.Close()
+		if _, err := io.Copy(h, body); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// signV4 sets the Authorization header. It signs the host header and every
+// X-Amz-* header already set on req.
+func signV4(req *http.Request, c AWSCredentials, region, service, payload string, now time.Time) {
+	headers := map[string]string{"host": req.URL.Host}
+	if req.Host != "" {
+		headers["host"] = req.Host
+	}
+	for name, values := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		awsEscape(path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payload,
+	}, "\n")
+
+	date := now.Format("20060102")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + now.Format("20060102T150405Z") + "\n" + scope + "\n" + sha256Hex(canonicalRequest)
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery sorts and strictly encodes query parameters.
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for key, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, awsEscape(key, true)+"="+awsEscape(v, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// awsEscape percent-encodes every byte except unreserved characters, and
+// slashes unless escapeSlash is set.
+func awsEscape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !escapeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,66 @@
+package httpexec
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignV4_Vanilla checks the get-vanilla case of the AWS Signature
+// Version 4 test suite.
+func TestSignV4_Vanilla(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	req.Header.Set("X-Amz-Date", "20150830T123600Z")
+
+	creds := AWSCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	signV4(req, creds, "us-east-1", "service", sha256Hex(""), now)
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+		"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+	if got := req.Header.Get("Authorization"); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestSigV4(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://search.example.com/products/_search?pretty", strings.NewReader(`{"size":1}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	creds := StaticAWSCredentials(AWSCredentials{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "session"})
+	if err := SigV4("eu-west-1", "aoss", creds).Apply(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if req.Header.Get("X-Amz-Content-Sha256") != sha256Hex(`{"size":1}`) {
+		t.Errorf("unexpected payload hash: %s", req.Header.Get("X-Amz-Content-Sha256"))
+	}
+	if req.Header.Get("X-Amz-Security-Token") != "session" {
+		t.Error("expected the session token header")
+	}
+	auth := req.Header.Get("Authorization")
+	if !strings.Contains(auth, "/eu-west-1/aoss/aws4_request") ||
+		!strings.Contains(auth, "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,") {
+		t.Errorf("unexpected authorization: %s", auth)
+	}
+
+	if _, err := payloadHash(req); err != nil {
+		t.Errorf("expected the body to stay readable: %v", err)
+	}
+}
+
+func TestSigV4_CredentialsError(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://search.example.com/", nil)
+	failing := func(context.Context) (AWSCredentials, error) {
+		return AWSCredentials{}, context.DeadlineExceeded
+	}
+	if err := SigV4("us-east-1", "es", failing).Apply(req); err == nil {
+		t.Error("expected the credentials error")
+	}
+}
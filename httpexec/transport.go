@@ -0,0 +1,108 @@
+package httpexec
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// transportConfig collects the transport options until New builds the
+// client's transport from them.
+type transportConfig struct {
+	roundTripper http.RoundTripper
+	proxy        func(*http.Request) (*url.URL, error)
+	tls          *tls.Config
+}
+
+// WithTransport sends requests through rt, e.g. an instrumented or
+// egress-gateway RoundTripper. Proxy and TLS options configure a copy of rt
+// when it is an *http.Transport; New rejects them for any other
+// RoundTripper, which must proxy and present client certificates itself.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(e *Executor) {
+		e.transport.roundTripper = rt
+	}
+}
+
+// WithProxy routes requests through the proxy at proxyURL. Without it the
+// default transport honors HTTP_PROXY, HTTPS_PROXY, and NO_PROXY.
+func WithProxy(proxyURL *url.URL) Option {
+	return WithProxyFunc(http.ProxyURL(proxyURL))
+}
+
+// WithProxyFunc chooses the proxy for each request with proxy, as
+// http.Transport.Proxy does. A nil URL sends the request directly.
+func WithProxyFunc(proxy func(*http.Request) (*url.URL, error)) Option {
+	return func(e *Executor) {
+		e.transport.proxy = proxy
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of the transport. Later
+// WithClientCertificate and WithRootCAs options add to a copy of cfg.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(e *Executor) {
+		e.transport.tls = cfg.Clone()
+	}
+}
+
+// WithClientCertificate presents cert to servers that require mutual TLS.
+// Load one with tls.LoadX509KeyPair.
+func WithClientCertificate(cert tls.Certificate) Option {
+	return func(e *Executor) {
+		cfg := e.transport.tlsConfig()
+		cfg.Certificates = append(cfg.Certificates, cert)
+	}
+}
+
+// WithRootCAs verifies server certificates against pool instead of the
+// system roots, for providers behind a private certificate authority.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(e *Executor) {
+		e.transport.tlsConfig().RootCAs = pool
+	}
+}
+
+// tlsConfig returns the TLS configuration, creating it on first use.
+func (c *transportConfig) tlsConfig() *tls.Config {
+	if c.tls == nil {
+		c.tls = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return c.tls
+}
+
+// configured reports whether any transport option was given.
+func (c *transportConfig) configured() bool {
+	return c.roundTripper != nil || c.proxy != nil || c.tls != nil
+}
+
+// build returns the RoundTripper for the options. base is the client's
+// current transport, used when WithTransport was not given. Proxy and TLS
+// options cannot configure a RoundTripper other than *http.Transport.
+func (c *transportConfig) build(base http.RoundTripper) (http.RoundTripper, error) {
+	rt := c.roundTripper
+	if rt == nil {
+		rt = base
+	}
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if c.proxy == nil && c.tls == nil {
+		return rt, nil
+	}
+
+	t, ok := rt.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("proxy and TLS options need an *http.Transport, got %T", rt)
+	}
+	t = t.Clone()
+	if c.proxy != nil {
+		t.Proxy = c.proxy
+	}
+	if c.tls != nil {
+		t.TLSClientConfig = c.tls
+	}
+	return t, nil
+}
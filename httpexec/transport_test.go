@@ -0,0 +1,154 @@
+package httpexec
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var calls int
+	rt := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	client := &http.Client{Timeout: time.Second}
+	exec, err := New(server.URL, WithClient(client), WithTransport(rt))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := exec.Execute(context.Background(), &vectql.Request{Endpoint: vectql.Endpoint{Method: "POST", Path: "/query"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected the custom transport to be used once, got %d", calls)
+	}
+	if client.Transport != nil {
+		t.Error("expected the supplied client to be left unchanged")
+	}
+	if exec.client.Timeout != time.Second {
+		t.Errorf("expected the client timeout to be kept, got %v", exec.client.Timeout)
+	}
+}
+
+func TestWithProxy(t *testing.T) {
+	var target string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target = r.URL.String()
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exec, err := New("http://vectors.internal:6333", WithProxy(proxyURL))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := exec.Execute(context.Background(), &vectql.Request{Endpoint: vectql.Endpoint{Method: "POST", Path: "/collections/products/points/search"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target != "http://vectors.internal:6333/collections/products/points/search" {
+		t.Errorf("expected the proxy to receive the absolute URL, got %q", target)
+	}
+}
+
+func TestWithClientCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "vectql-client" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	req := &vectql.Request{Endpoint: vectql.Endpoint{Method: "POST", Path: "/query"}}
+
+	exec, err := New(server.URL, WithRootCAs(roots))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := exec.Execute(context.Background(), req); err == nil {
+		t.Fatal("expected the handshake to fail without a client certificate")
+	}
+
+	exec, err = New(server.URL, WithRootCAs(roots), WithClientCertificate(clientCertificate(t)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := exec.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNew_TransportOptionsNeedHTTPTransport(t *testing.T) {
+	rt := roundTripperFunc(http.DefaultTransport.RoundTrip)
+	proxyURL := &url.URL{Scheme: "http", Host: "proxy.internal:3128"}
+
+	if _, err := New("https://vectors.internal", WithTransport(rt), WithProxy(proxyURL)); err == nil {
+		t.Error("expected an error for a proxy with a custom RoundTripper")
+	}
+	if _, err := New("https://vectors.internal", WithTransport(rt), WithClientCertificate(tls.Certificate{})); err == nil {
+		t.Error("expected an error for a client certificate with a custom RoundTripper")
+	}
+	if _, err := New("https://vectors.internal", WithClient(&http.Client{Transport: rt}), WithRootCAs(x509.NewCertPool())); err == nil {
+		t.Error("expected an error for root CAs with a custom client transport")
+	}
+	if _, err := New("https://vectors.internal", WithTransport(rt)); err != nil {
+		t.Errorf("expected a custom RoundTripper alone to be accepted, got %v", err)
+	}
+}
+
+// clientCertificate returns a self-signed client certificate.
+func clientCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "vectql-client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
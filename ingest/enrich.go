@@ -0,0 +1,99 @@
+package ingest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Enricher modifies a decoded record before it is validated and batched,
+// e.g. to stamp a tenant or derive a content hash. Returning an error
+// rejects the message. Enrichers run in Config.Enrichers order, so later
+// enrichers see the fields earlier ones set.
+type Enricher func(ctx context.Context, r *Record) error
+
+// setField sets a metadata field, allocating the map when needed.
+func setField(r *Record, field string, value interface{}) {
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]interface{})
+	}
+	r.Metadata[field] = value
+}
+
+// Set sets field to value on every record, overwriting what the producer
+// sent. Use it to stamp the tenant of a per-tenant pipeline so producers
+// cannot write into another tenant.
+func Set(field string, value interface{}) Enricher {
+	return func(_ context.Context, r *Record) error {
+		setField(r, field, value)
+		return nil
+	}
+}
+
+// Timestamp sets field to the ingestion time in Unix seconds, for an int
+// field. now defaults to time.Now.
+func Timestamp(field string, now func() time.Time) Enricher {
+	if now == nil {
+		now = time.Now
+	}
+	return func(_ context.Context, r *Record) error {
+		setField(r, field, now().Unix())
+		return nil
+	}
+}
+
+// ContentHash sets field to the hex SHA-256 of the named metadata fields,
+// or of the vector when no fields are named. Records with unchanged content
+// keep their hash, so syncs can skip them.
+func ContentHash(field string, from ...string) Enricher {
+	return func(_ context.Context, r *Record) error {
+		h := sha256.New()
+		if len(from) == 0 {
+			var buf [4]byte
+			for _, f := range r.Vector {
+				binary.LittleEndian.PutUint32(buf[:], math.Float32bits(f))
+				h.Write(buf[:])
+			}
+		}
+		for _, name := range from {
+			data, err := json.Marshal(r.Metadata[name])
+			if err != nil {
+				return fmt.Errorf("record '%s': cannot hash metadata field '%s': %w", r.ID, name, err)
+			}
+			h.Write(data)
+			h.Write([]byte{0})
+		}
+		setField(r, field, hex.EncodeToString(h.Sum(nil)))
+		return nil
+	}
+}
+
+// Derive sets field to the value fn computes from the record, e.g. the
+// language detected from a text field. A nil value leaves the field unset.
+func Derive(field string, fn func(ctx context.Context, r Record) (interface{}, error)) Enricher {
+	return func(ctx context.Context, r *Record) error {
+		value, err := fn(ctx, *r)
+		if err != nil {
+			return fmt.Errorf("record '%s': cannot derive metadata field '%s': %w", r.ID, field, err)
+		}
+		if value != nil {
+			setField(r, field, value)
+		}
+		return nil
+	}
+}
+
+// enrich applies the configured enrichers to r.
+func (in *Ingester) enrich(ctx context.Context, r *Record) error {
+	for _, e := range in.cfg.Enrichers {
+		if err := e(ctx, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql/pkg/pinecone"
+)
+
+func TestRun_Enrichers(t *testing.T) {
+	exec := &recordingExecutor{}
+	var reported []error
+	in, err := New(testVECTQL(t), Config{
+		Collection: "products",
+		Renderer:   pinecone.New(),
+		Executor:   exec,
+		Enrichers: []Enricher{
+			Set("category", "tenant-a"),
+			Timestamp("stock", func() time.Time { return time.Unix(1700000000, 0) }),
+			Derive("tags", func(_ context.Context, r Record) (interface{}, error) {
+				if r.ID == "bad" {
+					return nil, errors.New("detector unavailable")
+				}
+				return []interface{}{"en"}, nil
+			}),
+		},
+		OnError: func(err error) { reported = append(reported, err) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := messages(t,
+		`{"id":"a","vector":[1,0,0],"metadata":{"category":"spoofed"}}`,
+		`{"id":"bad","vector":[1,0,0]}`,
+	)
+	stats, err := in.Run(context.Background(), &sliceSource{messages: append([]*testMessage(nil), msgs...)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.Ingested != 1 || stats.Rejected != 1 || len(reported) != 1 || !msgs[1].naked {
+		t.Fatalf("expected the failing enrichment to reject its message, got %+v %v", stats, reported)
+	}
+
+	var body struct {
+		Vectors []struct {
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"vectors"`
+	}
+	if err := json.Unmarshal([]byte(exec.bodies[0]), &body); err != nil {
+		t.Fatalf("invalid body %s: %v", exec.bodies[0], err)
+	}
+	meta := body.Vectors[0].Metadata
+	if meta["category"] != "tenant-a" || meta["stock"] != float64(1700000000) {
+		t.Errorf("expected enriched metadata, got %v", meta)
+	}
+	if tags, ok := meta["tags"].([]interface{}); !ok || len(tags) != 1 || tags[0] != "en" {
+		t.Errorf("expected derived tags, got %v", meta["tags"])
+	}
+}
+
+func TestContentHash(t *testing.T) {
+	ctx := context.Background()
+	a := Record{ID: "a", Vector: []float32{1, 2}, Metadata: map[string]interface{}{"text": "hello"}}
+	b := Record{ID: "b", Vector: []float32{3, 4}, Metadata: map[string]interface{}{"text": "hello"}}
+
+	byText := ContentHash("hash", "text")
+	for _, r := range []*Record{&a, &b} {
+		if err := byText(ctx, r); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if a.Metadata["hash"] != b.Metadata["hash"] || len(a.Metadata["hash"].(string)) != 64 {
+		t.Errorf("expected equal text to hash equally, got %v and %v", a.Metadata["hash"], b.Metadata["hash"])
+	}
+
+	byVector := ContentHash("vhash")
+	_ = byVector(ctx, &a)
+	_ = byVector(ctx, &b)
+	if a.Metadata["vhash"] == b.Metadata["vhash"] {
+		t.Error("expected different vectors to hash differently")
+	}
+}
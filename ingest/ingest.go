@@ -0,0 +1,401 @@
+// Package ingest feeds vector records from a message broker into a vector
+// database. It does not ship broker clients or adapters: a Kafka consumer or a NATS
+// JetStream subscription is adapted to Source in a few lines, and the
+// Ingester batches the decoded records into upserts:
+//
+//	in, err := ingest.New(v, ingest.Config{
+//	    Collection: "products",
+//	    Renderer:   qdrant.New(),
+//	    Executor:   executor,
+//	})
+//	stats, err := in.Run(ctx, source)
+//
+// Publish is the producer side, for exporters that write records to a
+// topic for another pipeline to ingest.
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// DefaultFlushInterval bounds how long a partial batch waits for more records.
+const DefaultFlushInterval = time.Second
+
+// Record is the wire form of one vector record.
+type Record struct {
+	ID       string                 `json:"id"`
+	Vector   []float32              `json:"vector"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Message is one message received from a broker.
+type Message interface {
+	// Data returns the message payload.
+	Data() []byte
+
+	// Ack confirms the message was ingested, e.g. by committing its Kafka
+	// offset or acknowledging it on JetStream.
+	Ack() error
+
+	// Nak reports the message was not ingested so the broker can redeliver
+	// or dead-letter it. On offset-based brokers such as Kafka, acking a
+	// later message commits past this one, so an adapter must stop
+	// committing or dead-letter the message itself.
+	Nak() error
+}
+
+// Source delivers messages. Next blocks until a message arrives or ctx is
+// done, and returns io.EOF when the source is exhausted.
+type Source interface {
+	Next(ctx context.Context) (Message, error)
+}
+
+// Sink publishes message payloads to a broker.
+type Sink interface {
+	Publish(ctx context.Context, data []byte) error
+}
+
+// Decoder decodes a message payload into a record. DecodeJSON is the
+// default; supply a Decoder for protobuf or other encodings.
+type Decoder func(data []byte) (Record, error)
+
+// DecodeJSON decodes a JSON record.
+func DecodeJSON(data []byte) (Record, error) {
+	var r Record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Record{}, fmt.Errorf("invalid record: %w", err)
+	}
+	return r, nil
+}
+
+// Publish encodes records as JSON and publishes one message per record.
+func Publish(ctx context.Context, sink Sink, records ...Record) error {
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to encode record '%s': %w", r.ID, err)
+		}
+		if err := sink.Publish(ctx, data); err != nil {
+			return fmt.Errorf("failed to publish record '%s': %w", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// Config controls an Ingester.
+type Config struct {
+	// Collection is the collection records are upserted into.
+	Collection string
+
+	// Renderer and Executor render and send each batch.
+	Renderer vectql.Renderer
+	Executor vectql.Executor
+
+	// BatchSize is the number of records per upsert. It defaults to, and
+	// may not exceed, vectql.MaxBatchSize.
+	BatchSize int
+
+	// FlushInterval bounds how long a partial batch waits for more records.
+	// It defaults to DefaultFlushInterval.
+	FlushInterval time.Duration
+
+	// Decoder decodes message payloads. It defaults to DecodeJSON.
+	Decoder Decoder
+
+	// Enrichers modify each decoded record, in order, before it is
+	// validated against the collection schema.
+	Enrichers []Enricher
+
+	// BindOptions are passed to vectql.Prepare for every batch.
+	BindOptions []vectql.BindOption
+
+	// OnError is called for every rejected message and failed batch. The
+	// affected messages have already been Nak'd.
+	OnError func(err error)
+}
+
+// Stats summarizes an ingestion run.
+type Stats struct {
+	// Received counts messages read from the source.
+	Received int
+
+	// Ingested counts records upserted and acknowledged.
+	Ingested int
+
+	// Rejected counts messages that failed decoding or validation.
+	Rejected int
+
+	// Batches and FailedBatches count upsert requests sent and failed.
+	Batches       int
+	FailedBatches int
+}
+
+// Ingester batches records from a Source into upserts.
+type Ingester struct {
+	v          *vectql.VECTQL
+	cfg        Config
+	collection types.Collection
+	dimensions int
+}
+
+// New creates an Ingester for the configured collection.
+func New(v *vectql.VECTQL, cfg Config) (*Ingester, error) {
+	if cfg.Renderer == nil || cfg.Executor == nil {
+		return nil, fmt.Errorf("a renderer and an executor are required")
+	}
+	coll, err := v.TryC(cfg.Collection)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BatchSize == 0 {
+		cfg.BatchSize = vectql.MaxBatchSize
+	}
+	if cfg.BatchSize < 0 || cfg.BatchSize > vectql.MaxBatchSize {
+		return nil, fmt.Errorf("batch size must be between 1 and %d: %d", vectql.MaxBatchSize, cfg.BatchSize)
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultFlushInterval
+	}
+	if cfg.Decoder == nil {
+		cfg.Decoder = DecodeJSON
+	}
+
+	in := &Ingester{v: v, cfg: cfg, collection: coll}
+
+	// Records carry one vector, written to the default embedding; its
+	// dimensions are checked when it can be determined
+	embedding := coll.DefaultEmbedding
+	if embedding == "" {
+		if names, err := v.Embeddings(cfg.Collection); err == nil && len(names) == 1 {
+			embedding = names[0]
+		}
+	}
+	if embedding != "" {
+		dims, err := v.GetEmbeddingDimensions(cfg.Collection, embedding)
+		if err != nil {
+			return nil, err
+		}
+		in.dimensions = dims
+	}
+	return in, nil
+}
+
+// pending is a decoded record waiting in a batch.
+type pending struct {
+	msg    Message
+	record Record
+}
+
+// Run reads messages from src until it returns io.EOF or ctx is done. A
+// batch is sent when it is full or FlushInterval after its first record.
+// Messages are acknowledged once their batch succeeds and Nak'd when it
+// fails or the message is rejected. On io.EOF the last batch is flushed and
+// Run returns nil; when ctx is done pending messages are Nak'd and ctx's
+// error is returned.
+func (in *Ingester) Run(ctx context.Context, src Source) (*Stats, error) {
+	stats := &Stats{}
+	var batch []pending
+	var deadline time.Time
+
+	for {
+		next := ctx
+		cancel := context.CancelFunc(func() {})
+		if len(batch) > 0 {
+			next, cancel = context.WithDeadline(ctx, deadline)
+		}
+		msg, err := src.Next(next)
+		cancel()
+
+		switch {
+		case err == nil:
+		case errors.Is(err, io.EOF):
+			in.flush(ctx, batch, stats)
+			return stats, nil
+		case ctx.Err() != nil:
+			in.nakAll(batch)
+			return stats, ctx.Err()
+		case errors.Is(err, context.DeadlineExceeded) && len(batch) > 0:
+			in.flush(ctx, batch, stats)
+			batch = nil
+			continue
+		default:
+			in.nakAll(batch)
+			return stats, fmt.Errorf("failed to receive message: %w", err)
+		}
+
+		stats.Received++
+		record, err := in.cfg.Decoder(msg.Data())
+		if err == nil {
+			err = in.enrich(ctx, &record)
+		}
+		if err == nil {
+			err = in.validate(record)
+		}
+		if err != nil {
+			stats.Rejected++
+			in.report(err)
+			if nakErr := msg.Nak(); nakErr != nil {
+				in.report(fmt.Errorf("failed to nak message: %w", nakErr))
+			}
+			continue
+		}
+
+		if len(batch) == 0 {
+			deadline = time.Now().Add(in.cfg.FlushInterval)
+		}
+		batch = append(batch, pending{msg: msg, record: record})
+		if len(batch) >= in.cfg.BatchSize {
+			in.flush(ctx, batch, stats)
+			batch = nil
+		}
+	}
+}
+
+// flush upserts batch and acknowledges or Naks its messages.
+func (in *Ingester) flush(ctx context.Context, batch []pending, stats *Stats) {
+	if len(batch) == 0 {
+		return
+	}
+	stats.Batches++
+
+	err := in.send(ctx, batch)
+	if err != nil {
+		stats.FailedBatches++
+		in.report(fmt.Errorf("batch of %d records failed: %w", len(batch), err))
+		in.nakAll(batch)
+		return
+	}
+	for _, p := range batch {
+		if err := p.msg.Ack(); err != nil {
+			in.report(fmt.Errorf("failed to ack record '%s': %w", p.record.ID, err))
+			continue
+		}
+		stats.Ingested++
+	}
+}
+
+// send renders, binds, and executes one upsert for batch.
+func (in *Ingester) send(ctx context.Context, batch []pending) error {
+	builder := vectql.Upsert(in.collection)
+	params := make(map[string]interface{}, 3*len(batch))
+
+	for i, p := range batch {
+		id, err := in.v.TryP(fmt.Sprintf("id_%d", i))
+		if err != nil {
+			return err
+		}
+		vec, err := in.v.TryP(fmt.Sprintf("vec_%d", i))
+		if err != nil {
+			return err
+		}
+		params[id.Name] = p.record.ID
+		params[vec.Name] = p.record.Vector
+
+		record := vectql.NewRecord(id, vectql.Vec(vec))
+		keys := make([]string, 0, len(p.record.Metadata))
+		for key := range p.record.Metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for j, key := range keys {
+			field, err := in.v.TryM(in.cfg.Collection, key)
+			if err != nil {
+				return err
+			}
+			value, err := in.v.TryP(fmt.Sprintf("meta_%d_%d", i, j))
+			if err != nil {
+				return err
+			}
+			params[value.Name] = p.record.Metadata[key]
+			record.WithMetadata(field, value)
+		}
+		builder.AddVector(record.Build())
+	}
+
+	req, err := vectql.Prepare(builder, in.cfg.Renderer, params, in.cfg.BindOptions...)
+	if err != nil {
+		return err
+	}
+	_, err = in.cfg.Executor.Execute(ctx, req)
+	return err
+}
+
+// validate checks a record against the collection schema.
+func (in *Ingester) validate(r Record) error {
+	if r.ID == "" {
+		return fmt.Errorf("record has no id")
+	}
+	if len(r.Vector) == 0 {
+		return fmt.Errorf("record '%s' has no vector", r.ID)
+	}
+	if in.dimensions > 0 && len(r.Vector) != in.dimensions {
+		return fmt.Errorf("record '%s' has %d dimensions, expected %d", r.ID, len(r.Vector), in.dimensions)
+	}
+	for key, value := range r.Metadata {
+		field, err := in.v.TryM(in.cfg.Collection, key)
+		if err != nil {
+			return fmt.Errorf("record '%s': %w", r.ID, err)
+		}
+		if !matchesType(value, field.Type) {
+			return fmt.Errorf("record '%s': metadata field '%s' expects %s, got %T", r.ID, key, field.Type, value)
+		}
+	}
+	return nil
+}
+
+// matchesType reports whether a metadata value fits a VDML metadata type.
+// Decoded JSON numbers are float64; Go integers come from enrichers.
+func matchesType(value interface{}, fieldType string) bool {
+	if items, ok := value.([]interface{}); ok {
+		element, ok := strings.CutPrefix(fieldType, "[]")
+		if !ok {
+			return false
+		}
+		for _, item := range items {
+			if !matchesType(item, element) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch v := value.(type) {
+	case string:
+		return fieldType == "string"
+	case bool:
+		return fieldType == "bool"
+	case float64:
+		return fieldType == "float" || (fieldType == "int" && v == math.Trunc(v))
+	case int, int64:
+		return fieldType == "int" || fieldType == "float"
+	case nil:
+		return true
+	default:
+		return false
+	}
+}
+
+func (in *Ingester) nakAll(batch []pending) {
+	for _, p := range batch {
+		if err := p.msg.Nak(); err != nil {
+			in.report(fmt.Errorf("failed to nak record '%s': %w", p.record.ID, err))
+		}
+	}
+}
+
+func (in *Ingester) report(err error) {
+	if in.cfg.OnError != nil {
+		in.cfg.OnError(err)
+	}
+}
@@ -0,0 +1,316 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+)
+
+func testVECTQL(t *testing.T) *vectql.VECTQL {
+	t.Helper()
+	schema := vdml.NewSchema("test").AddCollection(
+		vdml.NewCollection("products").
+			AddEmbedding(vdml.NewEmbedding("embedding", 3).WithMetric(vdml.Cosine)).
+			AddMetadata(vdml.NewMetadataField("category", vdml.TypeString)).
+			AddMetadata(vdml.NewMetadataField("stock", vdml.TypeInt)).
+			AddMetadata(vdml.NewMetadataField("tags", vdml.TypeStringArray)),
+	)
+	v, err := vectql.NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return v
+}
+
+type testMessage struct {
+	data         []byte
+	acked, naked bool
+}
+
+func (m *testMessage) Data() []byte { return m.data }
+func (m *testMessage) Ack() error   { m.acked = true; return nil }
+func (m *testMessage) Nak() error   { m.naked = true; return nil }
+
+// sliceSource delivers messages in order, then io.EOF.
+type sliceSource struct {
+	messages []*testMessage
+}
+
+func (s *sliceSource) Next(ctx context.Context) (Message, error) {
+	if len(s.messages) == 0 {
+		return nil, io.EOF
+	}
+	m := s.messages[0]
+	s.messages = s.messages[1:]
+	return m, nil
+}
+
+func messages(t *testing.T, payloads ...string) []*testMessage {
+	t.Helper()
+	out := make([]*testMessage, len(payloads))
+	for i, p := range payloads {
+		out[i] = &testMessage{data: []byte(p)}
+	}
+	return out
+}
+
+type recordingExecutor struct {
+	mu     sync.Mutex
+	bodies []string
+	err    error
+}
+
+func (e *recordingExecutor) Execute(ctx context.Context, req *vectql.Request) (*vectql.Response, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.bodies = append(e.bodies, string(req.Body))
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &vectql.Response{}, nil
+}
+
+func TestRun_Batches(t *testing.T) {
+	exec := &recordingExecutor{}
+	in, err := New(testVECTQL(t), Config{
+		Collection: "products",
+		Renderer:   pinecone.New(),
+		Executor:   exec,
+		BatchSize:  2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := messages(t,
+		`{"id":"a","vector":[1,0,0],"metadata":{"category":"shoes","stock":3}}`,
+		`{"id":"b","vector":[0,1,0],"metadata":{"tags":["x","y"]}}`,
+		`{"id":"c","vector":[0,0,1]}`,
+	)
+	stats, err := in.Run(context.Background(), &sliceSource{messages: append([]*testMessage(nil), msgs...)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stats.Received != 3 || stats.Ingested != 3 || stats.Batches != 2 || stats.Rejected != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	for i, m := range msgs {
+		if !m.acked || m.naked {
+			t.Errorf("message %d: expected ack only, got acked=%v naked=%v", i, m.acked, m.naked)
+		}
+	}
+	if len(exec.bodies) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(exec.bodies))
+	}
+
+	var body struct {
+		Vectors []struct {
+			ID       string                 `json:"id"`
+			Values   []float64              `json:"values"`
+			Metadata map[string]interface{} `json:"metadata"`
+		} `json:"vectors"`
+	}
+	if err := json.Unmarshal([]byte(exec.bodies[0]), &body); err != nil {
+		t.Fatalf("invalid body %s: %v", exec.bodies[0], err)
+	}
+	if len(body.Vectors) != 2 || body.Vectors[0].ID != "a" || body.Vectors[1].ID != "b" {
+		t.Fatalf("unexpected vectors: %s", exec.bodies[0])
+	}
+	if body.Vectors[0].Metadata["category"] != "shoes" || body.Vectors[0].Metadata["stock"] != float64(3) {
+		t.Errorf("expected metadata to be bound, got %v", body.Vectors[0].Metadata)
+	}
+	if !strings.Contains(exec.bodies[1], `"id":"c"`) {
+		t.Errorf("expected final partial batch to be flushed, got %s", exec.bodies[1])
+	}
+}
+
+func TestRun_RejectsInvalidRecords(t *testing.T) {
+	var reported []error
+	in, err := New(testVECTQL(t), Config{
+		Collection: "products",
+		Renderer:   pinecone.New(),
+		Executor:   &recordingExecutor{},
+		OnError:    func(err error) { reported = append(reported, err) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := messages(t,
+		`not json`,
+		`{"vector":[1,0,0]}`,
+		`{"id":"a","vector":[1,0]}`,
+		`{"id":"b","vector":[1,0,0],"metadata":{"color":"red"}}`,
+		`{"id":"c","vector":[1,0,0],"metadata":{"stock":1.5}}`,
+		`{"id":"d","vector":[1,0,0],"metadata":{"tags":[1]}}`,
+		`{"id":"ok","vector":[1,0,0]}`,
+	)
+	stats, err := in.Run(context.Background(), &sliceSource{messages: append([]*testMessage(nil), msgs...)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if stats.Rejected != 6 || stats.Ingested != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if len(reported) != 6 {
+		t.Errorf("expected 6 reported errors, got %v", reported)
+	}
+	for i, m := range msgs[:6] {
+		if !m.naked || m.acked {
+			t.Errorf("message %d: expected nak only", i)
+		}
+	}
+	if !msgs[6].acked {
+		t.Error("expected valid message to be acked")
+	}
+}
+
+func TestRun_FailedBatchNaks(t *testing.T) {
+	var reported error
+	in, err := New(testVECTQL(t), Config{
+		Collection: "products",
+		Renderer:   pinecone.New(),
+		Executor:   &recordingExecutor{err: errors.New("unavailable")},
+		OnError:    func(err error) { reported = err },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	msgs := messages(t, `{"id":"a","vector":[1,0,0]}`, `{"id":"b","vector":[0,1,0]}`)
+	stats, err := in.Run(context.Background(), &sliceSource{messages: append([]*testMessage(nil), msgs...)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats.FailedBatches != 1 || stats.Ingested != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	for i, m := range msgs {
+		if !m.naked || m.acked {
+			t.Errorf("message %d: expected nak only", i)
+		}
+	}
+	if reported == nil || !strings.Contains(reported.Error(), "unavailable") {
+		t.Errorf("expected batch error to be reported, got %v", reported)
+	}
+}
+
+// chanSource delivers messages from a channel, blocking until ctx is done.
+type chanSource chan *testMessage
+
+func (s chanSource) Next(ctx context.Context) (Message, error) {
+	select {
+	case m := <-s:
+		return m, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestRun_FlushInterval(t *testing.T) {
+	exec := &recordingExecutor{}
+	in, err := New(testVECTQL(t), Config{
+		Collection:    "products",
+		Renderer:      pinecone.New(),
+		Executor:      exec,
+		FlushInterval: 10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	src := make(chanSource, 1)
+	msg := &testMessage{data: []byte(`{"id":"a","vector":[1,0,0]}`)}
+	src <- msg
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	var stats *Stats
+	go func() {
+		var err error
+		stats, err = in.Run(ctx, src)
+		done <- err
+	}()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		exec.mu.Lock()
+		n := len(exec.bodies)
+		exec.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch was not flushed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if !msg.acked || stats.Ingested != 1 {
+		t.Errorf("expected flushed message to be acked, got stats %+v", stats)
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	v := testVECTQL(t)
+	exec := &recordingExecutor{}
+
+	if _, err := New(v, Config{Collection: "missing", Renderer: pinecone.New(), Executor: exec}); err == nil {
+		t.Error("expected error for unknown collection")
+	}
+	if _, err := New(v, Config{Collection: "products", Executor: exec}); err == nil {
+		t.Error("expected error for missing renderer")
+	}
+	if _, err := New(v, Config{Collection: "products", Renderer: pinecone.New(), Executor: exec, BatchSize: vectql.MaxBatchSize + 1}); err == nil {
+		t.Error("expected error for oversized batch")
+	}
+}
+
+type sliceSink struct {
+	messages [][]byte
+}
+
+func (s *sliceSink) Publish(ctx context.Context, data []byte) error {
+	s.messages = append(s.messages, data)
+	return nil
+}
+
+func TestPublish_RoundTrip(t *testing.T) {
+	sink := &sliceSink{}
+	records := []Record{
+		{ID: "a", Vector: []float32{0.5, 0.25, 0}, Metadata: map[string]interface{}{"category": "shoes"}},
+		{ID: "b", Vector: []float32{1, 0, 0}},
+	}
+	if err := Publish(context.Background(), sink, records...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sink.messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(sink.messages))
+	}
+
+	got, err := DecodeJSON(sink.messages[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.ID != "a" || len(got.Vector) != 3 || got.Vector[0] != 0.5 || got.Metadata["category"] != "shoes" {
+		t.Errorf("unexpected round trip: %+v", got)
+	}
+	if strings.Contains(string(sink.messages[1]), "metadata") {
+		t.Errorf("expected empty metadata to be omitted, got %s", sink.messages[1])
+	}
+}
@@ -2,7 +2,10 @@ package vectql
 
 import (
 	"fmt"
-	"strings"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
 
 	"github.com/zoobzio/vdml"
 	"github.com/zoobzio/vectql/internal/types"
@@ -14,10 +17,14 @@ type VECTQL struct {
 	collections map[string]*vdml.Collection
 	embeddings  map[string]map[string]*vdml.Embedding
 	metadata    map[string]map[string]*vdml.MetadataField
+
+	presetsMu sync.RWMutex
+	presets   map[string]types.FilterItem
 }
 
-// NewFromVDML creates a new VECTQL instance from a VDML schema.
-func NewFromVDML(schema *vdml.Schema) (*VECTQL, error) {
+// NewFromVDML creates a new VECTQL instance from a VDML schema. With
+// ForProviders it also checks that the providers can store every name.
+func NewFromVDML(schema *vdml.Schema, opts ...InstanceOption) (*VECTQL, error) {
 	if schema == nil {
 		return nil, fmt.Errorf("schema cannot be nil")
 	}
@@ -31,18 +38,52 @@ func NewFromVDML(schema *vdml.Schema) (*VECTQL, error) {
 
 	// Build indexes
 	for name, coll := range schema.Collections {
+		if err := validateShardSettings(coll); err != nil {
+			return nil, err
+		}
+		if err := validateDefaultEmbedding(coll); err != nil {
+			return nil, err
+		}
+
 		v.collections[name] = coll
 		v.embeddings[name] = make(map[string]*vdml.Embedding)
 		v.metadata[name] = make(map[string]*vdml.MetadataField)
 
 		for _, emb := range coll.Embeddings {
 			v.embeddings[name][emb.Name] = emb
+
+			switch q := types.Quantization(coll.Settings[emb.Name+SettingQuantizationSuffix]); q {
+			case types.QuantizationNone, types.QuantizationScalar, types.QuantizationProduct, types.QuantizationBinary:
+			default:
+				return nil, fmt.Errorf("embedding '%s' in collection '%s' has unknown quantization: %s", emb.Name, name, q)
+			}
 		}
 		for _, meta := range coll.Metadata {
+			if err := validateIndexSetting(coll, meta); err != nil {
+				return nil, err
+			}
+			if err := validateCaseInsensitiveSetting(coll, meta); err != nil {
+				return nil, err
+			}
+			if err := validateLanguageSetting(coll, meta); err != nil {
+				return nil, err
+			}
+			if err := validateUniqueSetting(coll, meta); err != nil {
+				return nil, err
+			}
 			v.metadata[name][meta.Name] = meta
 		}
 	}
 
+	var cfg instanceConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	for _, r := range cfg.providers {
+		if err := v.CheckNames(r); err != nil {
+			return nil, err
+		}
+	}
 	return v, nil
 }
 
@@ -60,7 +101,23 @@ func (v *VECTQL) TryC(name string) (types.Collection, error) {
 	if _, ok := v.collections[name]; !ok {
 		return types.Collection{}, fmt.Errorf("collection '%s' not found in schema", name)
 	}
-	return types.Collection{Name: name}, nil
+	settings := v.collections[name].Settings
+	c := types.Collection{
+		Name:                 name,
+		PartitionKey:         settings[SettingPartitionKey],
+		PartitionKeyRequired: settings[SettingPartitionKeyRequired] == "true",
+		DefaultEmbedding:     settings[SettingDefaultEmbedding],
+	}
+	target := c.DefaultEmbedding
+	if target == "" && len(v.embeddings[name]) == 1 {
+		for only := range v.embeddings[name] {
+			target = only
+		}
+	}
+	if target != "" {
+		c.Embedding, _ = v.TryE(name, target)
+	}
+	return c, nil
 }
 
 // E creates a validated embedding field reference.
@@ -81,7 +138,15 @@ func (v *VECTQL) TryE(collectionName, embeddingName string) (types.EmbeddingFiel
 	if _, ok := collEmbs[embeddingName]; !ok {
 		return types.EmbeddingField{}, fmt.Errorf("embedding '%s' not found in collection '%s'", embeddingName, collectionName)
 	}
-	return types.EmbeddingField{Name: embeddingName, Collection: collectionName}, nil
+	return types.EmbeddingField{
+		Name:          embeddingName,
+		Collection:    collectionName,
+		Dimensions:    collEmbs[embeddingName].Dimensions,
+		Model:         v.embeddingModel(collectionName, embeddingName),
+		Metric:        metricFromVDML(collEmbs[embeddingName].Metric),
+		Normalization: v.embeddingNormalization(collectionName, embeddingName),
+		Quantization:  v.embeddingQuantization(collectionName, embeddingName),
+	}, nil
 }
 
 // M creates a validated metadata field reference.
@@ -99,10 +164,20 @@ func (v *VECTQL) TryM(collectionName, fieldName string) (types.MetadataField, er
 	if !ok {
 		return types.MetadataField{}, fmt.Errorf("collection '%s' not found", collectionName)
 	}
-	if _, ok := collMeta[fieldName]; !ok {
+	meta, ok := collMeta[fieldName]
+	if !ok {
 		return types.MetadataField{}, fmt.Errorf("metadata field '%s' not found in collection '%s'", fieldName, collectionName)
 	}
-	return types.MetadataField{Name: fieldName, Collection: collectionName}, nil
+	return types.MetadataField{
+		Name:       fieldName,
+		Collection: collectionName,
+		Type:       string(meta.Type),
+		Index:      v.fieldIndex(collectionName, meta),
+
+		CaseInsensitive: v.collections[collectionName].Settings[fieldName+SettingCaseInsensitiveSuffix] == "true",
+		Language:        v.collections[collectionName].Settings[fieldName+SettingLanguageSuffix],
+		Unique:          v.collections[collectionName].Settings[fieldName+SettingUniqueSuffix] == "true",
+	}, nil
 }
 
 // P creates a validated parameter reference.
@@ -142,75 +217,310 @@ func (v *VECTQL) GetEmbeddingMetric(collectionName, embeddingName string) (vdml.
 	return "", fmt.Errorf("embedding '%s' not found in collection '%s'", embeddingName, collectionName)
 }
 
-// Collections returns all collection names in the schema.
-func (v *VECTQL) Collections() []string {
-	names := make([]string, 0, len(v.collections))
-	for name := range v.collections {
-		names = append(names, name)
+// Collection settings that configure sharding. The partition key names a
+// metadata field; shards and replicas are positive integers. Setting
+// "partition_key.required" to "true" makes SEARCH require a filter on the
+// partition key.
+const (
+	SettingPartitionKey         = "partition_key"
+	SettingPartitionKeyRequired = "partition_key.required"
+	SettingShards               = "shards"
+	SettingReplicas             = "replicas"
+)
+
+// SettingDefaultEmbedding names the embedding a collection's queries and
+// records use when they do not name one, e.g. the Qdrant named vector or the
+// Milvus vector field.
+const SettingDefaultEmbedding = "default_embedding"
+
+// Collection settings that configure an embedding, keyed by embedding name:
+// "<embedding>.model", "<embedding>.model_version", "<embedding>.normalize",
+// and "<embedding>.quantization". The normalize setting is "true" or "false";
+// quantization is "scalar", "product", or "binary".
+const (
+	SettingModelSuffix        = ".model"
+	SettingModelVersionSuffix = ".model_version"
+	SettingNormalizeSuffix    = ".normalize"
+	SettingQuantizationSuffix = ".quantization"
+)
+
+// SettingIndexSuffix declares the payload index kind of an indexed metadata
+// field, keyed by field name: "<field>.index" is "keyword", "integer",
+// "float", "bool", "geo", or "text". Indexed fields without the setting get
+// the default kind for their type.
+const SettingIndexSuffix = ".index"
+
+// SettingCaseInsensitiveSuffix declares a string metadata field
+// case-insensitive, keyed by field name: "<field>.case_insensitive" is "true"
+// or "false". Equality filters on a case-insensitive field compare with
+// EqFold; see Builder.Render for providers without native support.
+const SettingCaseInsensitiveSuffix = ".case_insensitive"
+
+// SettingLanguageSuffix declares the language of a text-indexed field as an
+// ISO 639-1 code, keyed by field name: "<field>.language" is e.g. "en" or
+// "ja". DDL renderers configure the field's analyzer or tokenizer from it.
+const SettingLanguageSuffix = ".language"
+
+// SettingUniqueSuffix declares a string or int metadata field a unique
+// business key, keyed by field name: "<field>.unique" is "true" or "false".
+// Unique fields address records in Builder.FetchBy. The provider does not
+// enforce uniqueness; writers must.
+const SettingUniqueSuffix = ".unique"
+
+// GetEmbeddingModel returns the model declared for an embedding field. The
+// model is zero when the collection settings do not declare one.
+func (v *VECTQL) GetEmbeddingModel(collectionName, embeddingName string) (types.EmbeddingModel, error) {
+	if collEmbs, ok := v.embeddings[collectionName]; ok {
+		if _, ok := collEmbs[embeddingName]; ok {
+			return v.embeddingModel(collectionName, embeddingName), nil
+		}
 	}
-	return names
+	return types.EmbeddingModel{}, fmt.Errorf("embedding '%s' not found in collection '%s'", embeddingName, collectionName)
 }
 
-// Embeddings returns all embedding names for a collection.
-func (v *VECTQL) Embeddings(collectionName string) ([]string, error) {
-	collEmbs, ok := v.embeddings[collectionName]
+func (v *VECTQL) embeddingModel(collectionName, embeddingName string) types.EmbeddingModel {
+	settings := v.collections[collectionName].Settings
+	return types.EmbeddingModel{
+		Name:    settings[embeddingName+SettingModelSuffix],
+		Version: settings[embeddingName+SettingModelVersionSuffix],
+	}
+}
+
+func (v *VECTQL) embeddingNormalization(collectionName, embeddingName string) types.Normalization {
+	switch v.collections[collectionName].Settings[embeddingName+SettingNormalizeSuffix] {
+	case "true":
+		return types.NormalizeAlways
+	case "false":
+		return types.NormalizeNever
+	default:
+		return types.NormalizeDefault
+	}
+}
+
+func validateShardSettings(coll *vdml.Collection) error {
+	if key := coll.Settings[SettingPartitionKey]; key != "" {
+		found := false
+		for _, meta := range coll.Metadata {
+			if meta.Name == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("partition key '%s' is not a metadata field of collection '%s'", key, coll.Name)
+		}
+	}
+	if required, ok := coll.Settings[SettingPartitionKeyRequired]; ok {
+		if required != "true" && required != "false" {
+			return fmt.Errorf("collection '%s' setting %s must be \"true\" or \"false\": %q", coll.Name, SettingPartitionKeyRequired, required)
+		}
+		if required == "true" && coll.Settings[SettingPartitionKey] == "" {
+			return fmt.Errorf("collection '%s' requires a partition key filter but declares no partition key", coll.Name)
+		}
+	}
+	for _, setting := range []string{SettingShards, SettingReplicas} {
+		value, ok := coll.Settings[setting]
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(value); err != nil || n < 1 {
+			return fmt.Errorf("collection '%s' setting %s must be a positive integer: %q", coll.Name, setting, value)
+		}
+	}
+	return nil
+}
+
+func validateDefaultEmbedding(coll *vdml.Collection) error {
+	name, ok := coll.Settings[SettingDefaultEmbedding]
 	if !ok {
-		return nil, fmt.Errorf("collection '%s' not found", collectionName)
+		return nil
 	}
-	names := make([]string, 0, len(collEmbs))
-	for name := range collEmbs {
-		names = append(names, name)
+	for _, emb := range coll.Embeddings {
+		if emb.Name == name {
+			return nil
+		}
 	}
-	return names, nil
+	return fmt.Errorf("default embedding '%s' is not an embedding of collection '%s'", name, coll.Name)
 }
 
-// MetadataFields returns all metadata field names for a collection.
-func (v *VECTQL) MetadataFields(collectionName string) ([]string, error) {
-	collMeta, ok := v.metadata[collectionName]
+// fieldIndex returns the payload index kind of a metadata field, or "" when
+// it is not indexed.
+func (v *VECTQL) fieldIndex(collectionName string, meta *vdml.MetadataField) types.IndexKind {
+	if !meta.Indexed {
+		return ""
+	}
+	if kind, ok := v.collections[collectionName].Settings[meta.Name+SettingIndexSuffix]; ok {
+		return types.IndexKind(kind)
+	}
+	return types.DefaultIndexKind(string(meta.Type))
+}
+
+func validateIndexSetting(coll *vdml.Collection, meta *vdml.MetadataField) error {
+	kind, ok := coll.Settings[meta.Name+SettingIndexSuffix]
 	if !ok {
+		if meta.Indexed && types.DefaultIndexKind(string(meta.Type)) == "" {
+			return fmt.Errorf("indexed field '%s' in collection '%s' needs an index kind", meta.Name, coll.Name)
+		}
+		return nil
+	}
+	if !meta.Indexed {
+		return fmt.Errorf("field '%s' in collection '%s' declares an index kind but is not indexed", meta.Name, coll.Name)
+	}
+	switch types.IndexKind(kind) {
+	case types.IndexKeyword, types.IndexInteger, types.IndexFloat, types.IndexBool, types.IndexGeo, types.IndexText:
+		return nil
+	default:
+		return fmt.Errorf("field '%s' in collection '%s' has unknown index kind: %s", meta.Name, coll.Name, kind)
+	}
+}
+
+func validateCaseInsensitiveSetting(coll *vdml.Collection, meta *vdml.MetadataField) error {
+	value, ok := coll.Settings[meta.Name+SettingCaseInsensitiveSuffix]
+	if !ok {
+		return nil
+	}
+	if value != "true" && value != "false" {
+		return fmt.Errorf("field '%s' in collection '%s' setting %s must be true or false: %q", meta.Name, coll.Name, SettingCaseInsensitiveSuffix, value)
+	}
+	if value == "true" && meta.Type != vdml.TypeString {
+		return fmt.Errorf("case-insensitive field '%s' in collection '%s' must be a string", meta.Name, coll.Name)
+	}
+	return nil
+}
+
+func validateLanguageSetting(coll *vdml.Collection, meta *vdml.MetadataField) error {
+	lang, ok := coll.Settings[meta.Name+SettingLanguageSuffix]
+	if !ok {
+		return nil
+	}
+	if _, known := types.Languages[lang]; !known {
+		return fmt.Errorf("field '%s' in collection '%s' has unknown language: %q", meta.Name, coll.Name, lang)
+	}
+	if coll.Settings[meta.Name+SettingIndexSuffix] != string(types.IndexText) {
+		return fmt.Errorf("field '%s' in collection '%s' declares a language but is not text indexed", meta.Name, coll.Name)
+	}
+	return nil
+}
+
+func validateUniqueSetting(coll *vdml.Collection, meta *vdml.MetadataField) error {
+	value, ok := coll.Settings[meta.Name+SettingUniqueSuffix]
+	if !ok {
+		return nil
+	}
+	if value != "true" && value != "false" {
+		return fmt.Errorf("field '%s' in collection '%s' setting %s must be true or false: %q", meta.Name, coll.Name, SettingUniqueSuffix, value)
+	}
+	if value == "true" && meta.Type != vdml.TypeString && meta.Type != vdml.TypeInt {
+		return fmt.Errorf("unique field '%s' in collection '%s' must be a string or int", meta.Name, coll.Name)
+	}
+	return nil
+}
+
+// IndexedFields returns the indexed metadata fields of a collection, sorted
+// by name, each with its index kind. DDL renderers create the payload
+// indexes from them; see PrepareFieldIndexes.
+func (v *VECTQL) IndexedFields(collectionName string) ([]types.MetadataField, error) {
+	if _, ok := v.metadata[collectionName]; !ok {
 		return nil, fmt.Errorf("collection '%s' not found", collectionName)
 	}
-	names := make([]string, 0, len(collMeta))
-	for name := range collMeta {
-		names = append(names, name)
+	var fields []types.MetadataField
+	for f := range v.IterFields(collectionName) {
+		if f.Index != "" {
+			fields = append(fields, f)
+		}
 	}
-	return names, nil
+	return fields, nil
 }
 
-// suspiciousPatterns contains strings that indicate potential injection attempts.
-var suspiciousPatterns = []string{
-	";", "--", "/*", "*/", "'", "\"", "`", "\\",
-	" or ", " and ", "drop ", "delete ", "insert ",
-	"update ", "select ", "union ", "exec ", "execute ",
+func (v *VECTQL) embeddingQuantization(collectionName, embeddingName string) types.Quantization {
+	return types.Quantization(v.collections[collectionName].Settings[embeddingName+SettingQuantizationSuffix])
 }
 
-func isValidIdentifier(s string) bool {
-	if s == "" {
-		return false
+func metricFromVDML(metric vdml.DistanceMetric) types.DistanceMetric {
+	switch metric {
+	case vdml.Cosine:
+		return types.Cosine
+	case vdml.Euclidean:
+		return types.Euclidean
+	case vdml.DotProduct:
+		return types.DotProduct
+	default:
+		return ""
 	}
+}
 
-	// Check character validity
-	for i, r := range s {
-		if i == 0 {
-			if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && r != '_' {
-				return false
-			}
-		} else {
-			if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' {
-				return false
-			}
+// Order controls the order of names returned by introspection methods.
+type Order int
+
+// Introspection orders.
+const (
+	// OrderByName sorts names lexically. It is the default.
+	OrderByName Order = iota
+
+	// OrderDeclared keeps the order in which the VDML schema declares
+	// embeddings and metadata fields.
+	OrderDeclared
+)
+
+// IntrospectOption configures an introspection method.
+type IntrospectOption func(*Order)
+
+// WithOrder sets the order of returned names.
+func WithOrder(order Order) IntrospectOption {
+	return func(o *Order) {
+		*o = order
+	}
+}
+
+func introspectOrder(opts []IntrospectOption) Order {
+	order := OrderByName
+	for _, opt := range opts {
+		opt(&order)
+	}
+	return order
+}
+
+// Collections returns all collection names in the schema, sorted by name.
+func (v *VECTQL) Collections() []string {
+	return slices.Sorted(maps.Keys(v.collections))
+}
+
+// Embeddings returns all embedding names for a collection, sorted by name
+// unless WithOrder(OrderDeclared) is given.
+func (v *VECTQL) Embeddings(collectionName string, opts ...IntrospectOption) ([]string, error) {
+	collEmbs, ok := v.embeddings[collectionName]
+	if !ok {
+		return nil, fmt.Errorf("collection '%s' not found", collectionName)
+	}
+	if introspectOrder(opts) == OrderDeclared {
+		names := make([]string, 0, len(collEmbs))
+		for _, emb := range v.collections[collectionName].Embeddings {
+			names = append(names, emb.Name)
 		}
+		return names, nil
 	}
+	return slices.Sorted(maps.Keys(collEmbs)), nil
+}
 
-	// Check for injection patterns
-	lower := strings.ToLower(s)
-	for _, pattern := range suspiciousPatterns {
-		if strings.Contains(lower, pattern) {
-			return false
+// MetadataFields returns all metadata field names for a collection, sorted
+// by name unless WithOrder(OrderDeclared) is given.
+func (v *VECTQL) MetadataFields(collectionName string, opts ...IntrospectOption) ([]string, error) {
+	collMeta, ok := v.metadata[collectionName]
+	if !ok {
+		return nil, fmt.Errorf("collection '%s' not found", collectionName)
+	}
+	if introspectOrder(opts) == OrderDeclared {
+		names := make([]string, 0, len(collMeta))
+		for _, meta := range v.collections[collectionName].Metadata {
+			names = append(names, meta.Name)
 		}
+		return names, nil
 	}
+	return slices.Sorted(maps.Keys(collMeta)), nil
+}
 
-	return true
+func isValidIdentifier(s string) bool {
+	return types.IsValidIdentifier(s)
 }
 
 // --- Filter Operator Accessors ---
@@ -406,6 +716,24 @@ func (v *VECTQL) Eq(field types.MetadataField, value types.Param) types.FilterCo
 	return v.F(field, types.EQ, value)
 }
 
+// TryEqFold creates a validated case-insensitive equality filter condition
+// on a string field.
+func (v *VECTQL) TryEqFold(field types.MetadataField, value types.Param) (types.FilterCondition, error) {
+	if field.Type != "" && field.Type != "string" {
+		return types.FilterCondition{}, fmt.Errorf("EqFold requires a string field, '%s' is %s", field.Name, field.Type)
+	}
+	return v.TryF(field, types.EqFold, value)
+}
+
+// EqFold creates a case-insensitive equality filter condition (panics on error).
+func (v *VECTQL) EqFold(field types.MetadataField, value types.Param) types.FilterCondition {
+	c, err := v.TryEqFold(field, value)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
 // TryNe creates a validated not-equal filter condition.
 func (v *VECTQL) TryNe(field types.MetadataField, value types.Param) (types.FilterCondition, error) {
 	return v.TryF(field, types.NE, value)
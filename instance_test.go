@@ -1,6 +1,7 @@
 package vectql
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/zoobzio/vdml"
@@ -44,8 +45,258 @@ func TestNewFromVDML_NilSchema(t *testing.T) {
 	}
 }
 
+func TestNewFromVDML_UnknownQuantization(t *testing.T) {
+	schema := testSchema()
+	schema.Collections["products"].Settings = map[string]string{"description" + SettingQuantizationSuffix: "int3"}
+
+	if _, err := NewFromVDML(schema); err == nil {
+		t.Fatal("expected error for unknown quantization")
+	}
+
+	schema.Collections["products"].Settings["description"+SettingQuantizationSuffix] = "scalar"
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q := v.E("products", "description").Quantization; q != types.QuantizationScalar {
+		t.Errorf("expected scalar quantization, got %q", q)
+	}
+}
+
+func TestNewFromVDML_ShardSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]string
+		wantErr  bool
+	}{
+		{"valid", map[string]string{SettingPartitionKey: "category", SettingShards: "4", SettingReplicas: "2"}, false},
+		{"unknown partition key", map[string]string{SettingPartitionKey: "tenant"}, true},
+		{"required partition key", map[string]string{SettingPartitionKey: "category", SettingPartitionKeyRequired: "true"}, false},
+		{"required without partition key", map[string]string{SettingPartitionKeyRequired: "true"}, true},
+		{"invalid required value", map[string]string{SettingPartitionKey: "category", SettingPartitionKeyRequired: "yes"}, true},
+		{"zero replicas", map[string]string{SettingReplicas: "0"}, true},
+		{"non-numeric shards", map[string]string{SettingShards: "many"}, true},
+		{"default embedding", map[string]string{SettingDefaultEmbedding: "description"}, false},
+		{"unknown default embedding", map[string]string{SettingDefaultEmbedding: "title"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := testSchema()
+			schema.Collections["products"].Settings = tt.settings
+			_, err := NewFromVDML(schema)
+			if tt.wantErr && err == nil {
+				t.Error("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewFromVDML_IndexSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]string
+		wantErr  bool
+	}{
+		{"default kinds", nil, false},
+		{"declared kind", map[string]string{"category" + SettingIndexSuffix: "text"}, false},
+		{"unknown kind", map[string]string{"category" + SettingIndexSuffix: "btree"}, true},
+		{"field not indexed", map[string]string{"location" + SettingIndexSuffix: "geo"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := testSchema()
+			schema.Collections["products"].Metadata[0].Indexed = true
+			schema.Collections["products"].Settings = tt.settings
+			_, err := NewFromVDML(schema)
+			if tt.wantErr && err == nil {
+				t.Error("expected error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewFromVDML_CaseInsensitiveSettings(t *testing.T) {
+	schema := testSchema()
+	schema.Collections["products"].Settings = map[string]string{"category" + SettingCaseInsensitiveSuffix: "true"}
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !v.M("products", "category").CaseInsensitive || v.M("products", "location").CaseInsensitive {
+		t.Error("expected only category to be case-insensitive")
+	}
+	if _, err := v.TryEqFold(v.M("products", "price"), v.P("p")); err == nil {
+		t.Error("expected error for EqFold on a float field")
+	}
+
+	for _, settings := range []map[string]string{
+		{"category" + SettingCaseInsensitiveSuffix: "yes"},
+		{"price" + SettingCaseInsensitiveSuffix: "true"},
+	} {
+		schema := testSchema()
+		schema.Collections["products"].Settings = settings
+		if _, err := NewFromVDML(schema); err == nil {
+			t.Errorf("expected error for settings %v", settings)
+		}
+	}
+}
+
+func TestNewFromVDML_LanguageSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]string
+		wantErr  bool
+	}{
+		{"text field", map[string]string{"category" + SettingIndexSuffix: "text", "category" + SettingLanguageSuffix: "ja"}, false},
+		{"unknown language", map[string]string{"category" + SettingIndexSuffix: "text", "category" + SettingLanguageSuffix: "klingon"}, true},
+		{"keyword field", map[string]string{"category" + SettingLanguageSuffix: "en"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := testSchema()
+			schema.Collections["products"].Metadata[0].Indexed = true
+			schema.Collections["products"].Settings = tt.settings
+			v, err := NewFromVDML(schema)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if lang := v.M("products", "category").Language; lang != "ja" {
+				t.Errorf("expected language ja, got %q", lang)
+			}
+		})
+	}
+}
+
+func TestIndexedFields(t *testing.T) {
+	schema := testSchema()
+	meta := schema.Collections["products"].Metadata
+	meta[0].Indexed = true
+	meta[1].Indexed = true
+	meta[2].Indexed = true
+	schema.Collections["products"].Settings = map[string]string{"location" + SettingIndexSuffix: "geo"}
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fields, err := v.IndexedFields("products")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]types.IndexKind{"category": types.IndexKeyword, "location": types.IndexGeo, "price": types.IndexFloat}
+	if len(fields) != len(want) {
+		t.Fatalf("expected %d fields, got %v", len(want), fields)
+	}
+	for _, f := range fields {
+		if f.Index != want[f.Name] {
+			t.Errorf("field %s: expected %s index, got %q", f.Name, want[f.Name], f.Index)
+		}
+	}
+	if v.M("products", "price").Index != types.IndexFloat {
+		t.Error("expected M to carry the index kind")
+	}
+	if _, err := v.IndexedFields("missing"); err == nil {
+		t.Error("expected error for unknown collection")
+	}
+}
+
+func TestC_RecordsPartitionKey(t *testing.T) {
+	schema := testSchema()
+	schema.Collections["products"].Settings = map[string]string{SettingPartitionKey: "category"}
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if key := v.C("products").PartitionKey; key != "category" {
+		t.Errorf("expected partition key category, got %q", key)
+	}
+	if v.C("products").PartitionKeyRequired {
+		t.Error("expected the partition key filter to be optional by default")
+	}
+
+	schema.Collections["products"].Settings[SettingPartitionKeyRequired] = "true"
+	v, err = NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !v.C("products").PartitionKeyRequired {
+		t.Error("expected the partition key filter to be required")
+	}
+}
+
+func TestC_RecordsDefaultEmbedding(t *testing.T) {
+	schema := testSchema()
+	schema.Collections["products"].Settings = map[string]string{SettingDefaultEmbedding: "description"}
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if name := v.C("products").DefaultEmbedding; name != "description" {
+		t.Errorf("expected default embedding description, got %q", name)
+	}
+}
+
 // --- Injection Detection Tests ---
 
+func TestIntrospection_Sorted(t *testing.T) {
+	schema := testSchema()
+	schema.Collections["articles"] = &vdml.Collection{Name: "articles"}
+	schema.Collections["products"].Embeddings = append(schema.Collections["products"].Embeddings,
+		&vdml.Embedding{Name: "ary", Dimensions: 8, Metric: vdml.Cosine})
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := v.Collections(); !slices.Equal(got, []string{"articles", "products"}) {
+		t.Errorf("expected sorted collections, got %v", got)
+	}
+
+	embeddings, err := v.Embeddings("products")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(embeddings, []string{"ary", "description"}) {
+		t.Errorf("expected sorted embeddings, got %v", embeddings)
+	}
+	embeddings, _ = v.Embeddings("products", WithOrder(OrderDeclared))
+	if !slices.Equal(embeddings, []string{"description", "ary"}) {
+		t.Errorf("expected declared embeddings, got %v", embeddings)
+	}
+
+	fields, err := v.MetadataFields("products")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(fields, []string{"category", "location", "price"}) {
+		t.Errorf("expected sorted fields, got %v", fields)
+	}
+	fields, _ = v.MetadataFields("products", WithOrder(OrderDeclared))
+	if !slices.Equal(fields, []string{"category", "price", "location"}) {
+		t.Errorf("expected declared fields, got %v", fields)
+	}
+
+	if _, err := v.MetadataFields("missing"); err == nil {
+		t.Error("expected error for unknown collection")
+	}
+}
+
 func TestIsValidIdentifier_ValidNames(t *testing.T) {
 	validNames := []string{
 		"query_vec",
@@ -0,0 +1,230 @@
+package vectql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// IntentVectorParam is the parameter a query built by FromIntent reads its
+// query vector from. Callers embed the user's question and bind it under
+// this name.
+const IntentVectorParam = "query_vec"
+
+// DefaultIntentK is the result count used when an intent does not set K.
+const DefaultIntentK = 10
+
+// QueryIntent is a structured description of a search, intended as the
+// output format of a language model that translates natural-language
+// questions into queries. It names schema elements and carries plain values
+// only, so FromIntent can validate every part of it before a query is
+// built; the model never writes provider JSON.
+type QueryIntent struct {
+	// Collection and Embedding name the search target. Embedding may be
+	// empty to use the collection's default.
+	Collection string `json:"collection"`
+	Embedding  string `json:"embedding,omitempty"`
+
+	// Constraints are combined with AND.
+	Constraints []IntentConstraint `json:"constraints,omitempty"`
+
+	// K is the number of results, DefaultIntentK when zero.
+	K int `json:"k,omitempty"`
+
+	// Fields selects the metadata fields to return; empty returns all.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// IntentConstraint is one metadata filter of a QueryIntent. Value must match
+// the field's type; IN, NOT_IN, ARRAY_CONTAINS_ANY, and ARRAY_CONTAINS_ALL
+// take a list, and EXISTS and NOT_EXISTS take none.
+type IntentConstraint struct {
+	Field    string         `json:"field"`
+	Operator FilterOperator `json:"operator"`
+	Value    interface{}    `json:"value,omitempty"`
+}
+
+// ParseIntent decodes a JSON intent, rejecting unknown keys.
+func ParseIntent(data []byte) (QueryIntent, error) {
+	var intent QueryIntent
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&intent); err != nil {
+		return QueryIntent{}, fmt.Errorf("invalid query intent: %w", err)
+	}
+	return intent, nil
+}
+
+// FromIntent validates intent against the schema and builds the SEARCH it
+// describes. Constraint values are never inlined: each becomes a parameter,
+// returned in params alongside the builder, so they are bound like any other
+// user input. The query vector is read from IntentVectorParam, which the
+// caller adds to params.
+func (v *VECTQL) FromIntent(intent QueryIntent) (*Builder, map[string]interface{}, error) {
+	coll, err := v.TryC(intent.Collection)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	k := intent.K
+	if k == 0 {
+		k = DefaultIntentK
+	}
+	if k < 0 || k > MaxTopK {
+		return nil, nil, fmt.Errorf("intent k must be between 1 and %d: %d", MaxTopK, k)
+	}
+	if len(intent.Constraints) > MaxMetadataFields {
+		return nil, nil, fmt.Errorf("intent has %d constraints, limit is %d", len(intent.Constraints), MaxMetadataFields)
+	}
+
+	query := Search(coll).Vector(Vec(types.Param{Name: IntentVectorParam})).TopK(k)
+	if intent.Embedding != "" {
+		e, err := v.TryE(intent.Collection, intent.Embedding)
+		if err != nil {
+			return nil, nil, err
+		}
+		query.Embedding(e)
+	}
+
+	if len(intent.Fields) > 0 {
+		fields := make([]types.MetadataField, len(intent.Fields))
+		for i, name := range intent.Fields {
+			if fields[i], err = v.TryM(intent.Collection, name); err != nil {
+				return nil, nil, err
+			}
+		}
+		query.SelectMetadata(fields...)
+	}
+
+	params := make(map[string]interface{}, len(intent.Constraints))
+	conditions := make([]types.FilterItem, 0, len(intent.Constraints))
+	for i, c := range intent.Constraints {
+		field, err := v.TryM(intent.Collection, c.Field)
+		if err != nil {
+			return nil, nil, fmt.Errorf("constraint %d: %w", i, err)
+		}
+		if !slices.Contains(OperatorsForType(field.Type), c.Operator) {
+			return nil, nil, fmt.Errorf("constraint %d: operator %q is not valid for %s field '%s'", i, c.Operator, field.Type, field.Name)
+		}
+		if err := checkIntentValue(field.Type, c.Operator, c.Value); err != nil {
+			return nil, nil, fmt.Errorf("constraint %d on '%s': %w", i, field.Name, err)
+		}
+
+		if c.Operator == types.Exists || c.Operator == types.NotExists {
+			conditions = append(conditions, types.FilterCondition{Field: field, Operator: c.Operator})
+			continue
+		}
+		name := fmt.Sprintf("intent_%d", i)
+		params[name] = c.Value
+		conditions = append(conditions, types.FilterCondition{Field: field, Operator: c.Operator, Value: types.Param{Name: name}})
+	}
+	switch len(conditions) {
+	case 0:
+	case 1:
+		query.Filter(conditions[0])
+	default:
+		query.Filter(types.FilterGroup{Logic: types.AND, Conditions: conditions})
+	}
+
+	if _, err := query.Build(); err != nil {
+		return nil, nil, err
+	}
+	return query, params, nil
+}
+
+// checkIntentValue checks that a decoded JSON value suits the field type and
+// operator.
+func checkIntentValue(fieldType string, op FilterOperator, value interface{}) error {
+	element, isArray := strings.CutPrefix(fieldType, "[]")
+	switch op {
+	case types.Exists, types.NotExists:
+		if value != nil {
+			return fmt.Errorf("%s takes no value", op)
+		}
+		return nil
+	case types.IN, types.NotIn, types.ArrayContainsAny, types.ArrayContainsAll:
+		items, ok := value.([]interface{})
+		if !ok || len(items) == 0 {
+			return fmt.Errorf("%s requires a non-empty list", op)
+		}
+		for _, item := range items {
+			if !scalarMatches(element, item) {
+				return fmt.Errorf("expected %s values, got %T", element, item)
+			}
+		}
+		return nil
+	default:
+		if isArray && op != types.ArrayContains {
+			return fmt.Errorf("%s is not valid for array fields", op)
+		}
+		if !scalarMatches(element, value) {
+			return fmt.Errorf("expected a %s value, got %T", element, value)
+		}
+		return nil
+	}
+}
+
+// scalarMatches reports whether a decoded JSON value is of a VDML scalar type.
+func scalarMatches(fieldType string, value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return fieldType == "string"
+	case bool:
+		return fieldType == "bool"
+	case float64:
+		return fieldType == "float" || (fieldType == "int" && v == math.Trunc(v))
+	default:
+		return false
+	}
+}
+
+// IntentJSONSchema returns a JSON Schema for QueryIntent objects targeting a
+// collection, listing its embeddings, fields, and the operators each field
+// supports with r (every operator valid for the type when r is nil). Supply it
+// as the structured output format of a language model so its answers parse
+// into valid intents.
+func (v *VECTQL) IntentJSONSchema(collectionName string, r Renderer) (map[string]interface{}, error) {
+	fields, err := v.FilterableFields(collectionName, r)
+	if err != nil {
+		return nil, err
+	}
+	embeddings, _ := v.Embeddings(collectionName)
+	metadata, _ := v.MetadataFields(collectionName)
+
+	constraints := make([]interface{}, 0, len(fields))
+	for _, f := range fields {
+		ops := make([]string, len(f.Operators))
+		for i, op := range f.Operators {
+			ops[i] = string(op)
+		}
+		constraints = append(constraints, map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"field":    map[string]interface{}{"const": f.Name},
+				"operator": map[string]interface{}{"enum": ops},
+				"value":    map[string]interface{}{},
+			},
+			"required":             []string{"field", "operator"},
+			"additionalProperties": false,
+		})
+	}
+
+	return map[string]interface{}{
+		"$schema": JSONSchemaDialect,
+		"type":    "object",
+		"properties": map[string]interface{}{
+			"collection":  map[string]interface{}{"const": collectionName},
+			"embedding":   map[string]interface{}{"enum": embeddings},
+			"k":           map[string]interface{}{"type": "integer", "minimum": 1, "maximum": MaxTopK},
+			"fields":      map[string]interface{}{"type": "array", "items": map[string]interface{}{"enum": metadata}},
+			"constraints": map[string]interface{}{"type": "array", "maxItems": MaxMetadataFields, "items": map[string]interface{}{"anyOf": constraints}},
+		},
+		"required":             []string{"collection"},
+		"additionalProperties": false,
+	}, nil
+}
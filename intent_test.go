@@ -0,0 +1,158 @@
+package vectql
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func intentVECTQL(t *testing.T) *VECTQL {
+	t.Helper()
+	schema := testSchema()
+	products := schema.Collections["products"]
+	products.Metadata = append(products.Metadata, &vdml.MetadataField{Name: "tags", Type: vdml.TypeStringArray})
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return v
+}
+
+func TestFromIntent(t *testing.T) {
+	v := intentVECTQL(t)
+	intent, err := ParseIntent([]byte(`{
+		"collection": "products",
+		"embedding": "description",
+		"k": 5,
+		"fields": ["category"],
+		"constraints": [
+			{"field": "category", "operator": "IN", "value": ["shoes", "boots"]},
+			{"field": "price", "operator": "<", "value": 100}
+		]
+	}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, params, err := v.FromIntent(intent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ast, err := query.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ast.Operation != OpSearch || *ast.TopK.Static != 5 || ast.QueryEmbedding.Name != "description" {
+		t.Errorf("unexpected AST: %+v", ast)
+	}
+	if ast.QueryVector.Param.Name != IntentVectorParam {
+		t.Errorf("expected query vector param %s, got %+v", IntentVectorParam, ast.QueryVector)
+	}
+	group, ok := ast.FilterClause.(types.FilterGroup)
+	if !ok || group.Logic != types.AND || len(group.Conditions) != 2 {
+		t.Fatalf("expected AND of 2 conditions, got %+v", ast.FilterClause)
+	}
+	if len(params) != 2 || params["intent_1"] != float64(100) {
+		t.Errorf("expected constraint values as params, got %v", params)
+	}
+
+	params[IntentVectorParam] = []float32{0.1, 0.2}
+	result, err := query.Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, err := Bind(result, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(body, `"boots"`) {
+		t.Errorf("expected bound constraint values, got %s", body)
+	}
+}
+
+func TestFromIntent_Exists(t *testing.T) {
+	v := intentVECTQL(t)
+	query, params, err := v.FromIntent(QueryIntent{
+		Collection:  "products",
+		Constraints: []IntentConstraint{{Field: "location", Operator: OpExists}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ast, err := query.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cond, ok := ast.FilterClause.(types.FilterCondition)
+	if !ok || cond.Operator != OpExists || cond.Value.Name != "" || len(params) != 0 {
+		t.Errorf("expected a value-less EXISTS condition, got %+v and %v", ast.FilterClause, params)
+	}
+	if *ast.TopK.Static != DefaultIntentK {
+		t.Errorf("expected default k, got %d", *ast.TopK.Static)
+	}
+}
+
+func TestFromIntent_Rejects(t *testing.T) {
+	v := intentVECTQL(t)
+	tests := []struct {
+		name   string
+		intent QueryIntent
+		want   string
+	}{
+		{"unknown collection", QueryIntent{Collection: "users"}, "not found"},
+		{"unknown embedding", QueryIntent{Collection: "products", Embedding: "image"}, "not found"},
+		{"unknown field", QueryIntent{Collection: "products", Constraints: []IntentConstraint{{Field: "password", Operator: OpEQ, Value: "x"}}}, "not found"},
+		{"unknown selected field", QueryIntent{Collection: "products", Fields: []string{"secret"}}, "not found"},
+		{"negative k", QueryIntent{Collection: "products", K: -1}, "between 1 and"},
+		{"operator for type", QueryIntent{Collection: "products", Constraints: []IntentConstraint{{Field: "category", Operator: OpGT, Value: "a"}}}, "not valid for string"},
+		{"value type", QueryIntent{Collection: "products", Constraints: []IntentConstraint{{Field: "price", Operator: OpEQ, Value: "cheap"}}}, "expected a float"},
+		{"list required", QueryIntent{Collection: "products", Constraints: []IntentConstraint{{Field: "category", Operator: OpIN, Value: "shoes"}}}, "non-empty list"},
+		{"exists value", QueryIntent{Collection: "products", Constraints: []IntentConstraint{{Field: "category", Operator: OpExists, Value: "x"}}}, "takes no value"},
+		{"array element", QueryIntent{Collection: "products", Constraints: []IntentConstraint{{Field: "tags", Operator: OpArrayContains, Value: 3.0}}}, "expected a string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := v.FromIntent(tt.intent)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestParseIntent_UnknownKeys(t *testing.T) {
+	if _, err := ParseIntent([]byte(`{"collection": "products", "namespace": "other-tenant"}`)); err == nil {
+		t.Error("expected error for unknown key")
+	}
+}
+
+func TestIntentJSONSchema(t *testing.T) {
+	v := intentVECTQL(t)
+	schema, err := v.IntentJSONSchema("products", pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{`"collection":{"const":"products"}`, `"field":{"const":"price"}`, `"embedding":{"enum":["description"]}`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected schema to contain %s: %s", want, out)
+		}
+	}
+	// Pinecone cannot filter array fields, so tags is not offered
+	if strings.Contains(out, `"const":"tags"`) {
+		t.Errorf("expected no constraint for tags: %s", out)
+	}
+
+	if _, err := v.IntentJSONSchema("missing", nil); err == nil {
+		t.Error("expected error for unknown collection")
+	}
+}
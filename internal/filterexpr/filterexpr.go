@@ -0,0 +1,227 @@
+// Package filterexpr compiles filter ASTs into string expressions for
+// providers with SQL-like filter languages.
+//
+// Renderers describe their language with a Dialect and share traversal,
+// placeholder tracking, field-name validation, and operator precedence,
+// rather than concatenating strings by hand.
+package filterexpr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Precedence levels, lowest binding first.
+const (
+	precOr = iota + 1
+	precAnd
+	precNot
+	precAtom
+)
+
+// Dialect describes how a provider's expression language spells filters.
+type Dialect struct {
+	// Operator returns the infix spelling of a comparison operator.
+	// Returning false rejects the operator as unsupported.
+	Operator func(op types.FilterOperator) (string, bool)
+
+	// Field formats a field reference. Defaults to ValidateIdentifier.
+	Field func(name string) (string, error)
+
+	// Placeholder formats a parameter reference. Defaults to ":name".
+	Placeholder func(name string) string
+
+	// Comparison joins a field, an operator spelling, and a placeholder.
+	// Defaults to "field op value".
+	Comparison func(field, op, value string) string
+
+	// And, Or, and Not are the logical keywords. An empty Not rejects NOT
+	// groups.
+	And string
+	Or  string
+	Not string
+
+	// ParenthesizeGroups wraps every group and range in parentheses, even
+	// when precedence does not require it.
+	ParenthesizeGroups bool
+}
+
+// Compiler compiles filters for a dialect, recording the parameters it references.
+type Compiler struct {
+	dialect *Dialect
+	params  *[]string
+}
+
+// New creates a compiler that appends referenced parameter names to params.
+func New(dialect *Dialect, params *[]string) *Compiler {
+	return &Compiler{dialect: dialect, params: params}
+}
+
+// Compile converts a filter into an expression string.
+func (c *Compiler) Compile(f types.FilterItem) (string, error) {
+	expr, _, err := c.compile(f)
+	return expr, err
+}
+
+func (c *Compiler) compile(f types.FilterItem) (string, int, error) {
+	switch filter := f.(type) {
+	case types.FilterCondition:
+		expr, err := c.comparison(filter.Field, filter.Operator, filter.Value)
+		return expr, precAtom, err
+
+	case types.FilterGroup:
+		return c.group(filter)
+
+	case types.RangeFilter:
+		return c.rangeExpr(filter)
+
+	case types.ExtensionFilter:
+		return "", 0, fmt.Errorf("%s extension filter is not supported by this renderer", filter.Provider)
+
+	default:
+		return "", 0, fmt.Errorf("unsupported filter type: %T", f)
+	}
+}
+
+func (c *Compiler) comparison(field types.MetadataField, op types.FilterOperator, value types.Param) (string, error) {
+	spelled, ok := c.dialect.Operator(op)
+	if !ok {
+		return "", fmt.Errorf("unsupported filter operator: %s", op)
+	}
+	name, err := c.field(field.Name)
+	if err != nil {
+		return "", err
+	}
+	if c.dialect.Comparison != nil {
+		return c.dialect.Comparison(name, spelled, c.param(value)), nil
+	}
+	return fmt.Sprintf("%s %s %s", name, spelled, c.param(value)), nil
+}
+
+func (c *Compiler) group(g types.FilterGroup) (string, int, error) {
+	if len(g.Conditions) == 0 {
+		return "", 0, fmt.Errorf("%s group has no conditions", g.Logic)
+	}
+
+	var keyword string
+	var prec int
+	switch g.Logic {
+	case types.AND:
+		keyword, prec = c.dialect.And, precAnd
+	case types.OR:
+		keyword, prec = c.dialect.Or, precOr
+	case types.NOT:
+		if c.dialect.Not == "" {
+			return "", 0, fmt.Errorf("NOT groups are not supported by this renderer")
+		}
+		// NOT matches when none of its conditions match.
+		inner, _, err := c.join(g.Conditions, c.dialect.Or, precOr)
+		if err != nil {
+			return "", 0, err
+		}
+		return fmt.Sprintf("%s (%s)", c.dialect.Not, inner), precNot, nil
+	default:
+		return "", 0, fmt.Errorf("unsupported logic operator: %s", g.Logic)
+	}
+
+	expr, prec, err := c.join(g.Conditions, keyword, prec)
+	if err != nil {
+		return "", 0, err
+	}
+	if c.dialect.ParenthesizeGroups {
+		return "(" + expr + ")", precAtom, nil
+	}
+	return expr, prec, nil
+}
+
+// join compiles conditions and joins them with a keyword, parenthesizing
+// operands that bind more loosely than the keyword.
+func (c *Compiler) join(conditions []types.FilterItem, keyword string, prec int) (string, int, error) {
+	parts := make([]string, 0, len(conditions))
+	for _, cond := range conditions {
+		expr, p, err := c.compile(cond)
+		if err != nil {
+			return "", 0, err
+		}
+		if p < prec {
+			expr = "(" + expr + ")"
+		}
+		parts = append(parts, expr)
+	}
+	if len(parts) == 1 {
+		return parts[0], precAtom, nil
+	}
+	return strings.Join(parts, " "+keyword+" "), prec, nil
+}
+
+func (c *Compiler) rangeExpr(r types.RangeFilter) (string, int, error) {
+	var parts []string
+	if r.Min != nil {
+		op := types.GE
+		if r.MinExclusive {
+			op = types.GT
+		}
+		expr, err := c.comparison(r.Field, op, *r.Min)
+		if err != nil {
+			return "", 0, err
+		}
+		parts = append(parts, expr)
+	}
+	if r.Max != nil {
+		op := types.LE
+		if r.MaxExclusive {
+			op = types.LT
+		}
+		expr, err := c.comparison(r.Field, op, *r.Max)
+		if err != nil {
+			return "", 0, err
+		}
+		parts = append(parts, expr)
+	}
+	if len(parts) == 0 {
+		return "", 0, fmt.Errorf("range on '%s' has no bounds", r.Field.Name)
+	}
+
+	expr := strings.Join(parts, " "+c.dialect.And+" ")
+	if c.dialect.ParenthesizeGroups {
+		return "(" + expr + ")", precAtom, nil
+	}
+	if len(parts) == 1 {
+		return expr, precAtom, nil
+	}
+	return expr, precAnd, nil
+}
+
+func (c *Compiler) field(name string) (string, error) {
+	if c.dialect.Field != nil {
+		return c.dialect.Field(name)
+	}
+	return ValidateIdentifier(name)
+}
+
+func (c *Compiler) param(p types.Param) string {
+	*c.params = append(*c.params, p.Name)
+	if c.dialect.Placeholder != nil {
+		return c.dialect.Placeholder(p.Name)
+	}
+	return ":" + p.Name
+}
+
+// ValidateIdentifier accepts bare identifiers ([A-Za-z_][A-Za-z0-9_]*) and
+// rejects anything that would need quoting, preventing field names from
+// altering the structure of an expression.
+func ValidateIdentifier(name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("field name cannot be empty")
+	}
+	for i, r := range name {
+		letter := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_'
+		digit := r >= '0' && r <= '9'
+		if !letter && (i == 0 || !digit) {
+			return "", fmt.Errorf("invalid field name in filter expression: %q", name)
+		}
+	}
+	return name, nil
+}
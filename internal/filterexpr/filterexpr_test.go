@@ -0,0 +1,217 @@
+package filterexpr
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func testDialect(parenthesize bool) *Dialect {
+	return &Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			switch op {
+			case types.EQ:
+				return "==", true
+			case types.GE:
+				return ">=", true
+			case types.GT:
+				return ">", true
+			case types.LE:
+				return "<=", true
+			case types.LT:
+				return "<", true
+			default:
+				return "", false
+			}
+		},
+		And:                "and",
+		Or:                 "or",
+		Not:                "not",
+		ParenthesizeGroups: parenthesize,
+	}
+}
+
+func eq(field, param string) types.FilterCondition {
+	return types.FilterCondition{
+		Field:    types.MetadataField{Name: field},
+		Operator: types.EQ,
+		Value:    types.Param{Name: param},
+	}
+}
+
+func TestCompile_Precedence(t *testing.T) {
+	a, b, c := eq("a", "pa"), eq("b", "pb"), eq("c", "pc")
+
+	tests := []struct {
+		name     string
+		filter   types.FilterItem
+		expected string
+	}{
+		{"condition", a, "a == :pa"},
+		{"and", types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{a, b}}, "a == :pa and b == :pb"},
+		{
+			"or inside and",
+			types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{
+				a,
+				types.FilterGroup{Logic: types.OR, Conditions: []types.FilterItem{b, c}},
+			}},
+			"a == :pa and (b == :pb or c == :pc)",
+		},
+		{
+			"and inside or",
+			types.FilterGroup{Logic: types.OR, Conditions: []types.FilterItem{
+				a,
+				types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{b, c}},
+			}},
+			"a == :pa or b == :pb and c == :pc",
+		},
+		{"not", types.FilterGroup{Logic: types.NOT, Conditions: []types.FilterItem{a}}, "not (a == :pa)"},
+		{"not many", types.FilterGroup{Logic: types.NOT, Conditions: []types.FilterItem{a, b}}, "not (a == :pa or b == :pb)"},
+		{"single-member group", types.FilterGroup{Logic: types.OR, Conditions: []types.FilterItem{a}}, "a == :pa"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params []string
+			got, err := New(testDialect(false), &params).Compile(tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompile_ParenthesizeGroups(t *testing.T) {
+	filter := types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{eq("a", "pa"), eq("b", "pb")}}
+
+	var params []string
+	got, err := New(testDialect(true), &params).Compile(filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "(a == :pa and b == :pb)" {
+		t.Errorf("unexpected expression: %q", got)
+	}
+}
+
+func TestCompile_Range(t *testing.T) {
+	minP, maxP := types.Param{Name: "min"}, types.Param{Name: "max"}
+	field := types.MetadataField{Name: "price"}
+
+	tests := []struct {
+		name     string
+		filter   types.RangeFilter
+		expected string
+	}{
+		{"inclusive", types.RangeFilter{Field: field, Min: &minP, Max: &maxP}, "price >= :min and price <= :max"},
+		{"exclusive", types.RangeFilter{Field: field, Min: &minP, Max: &maxP, MinExclusive: true, MaxExclusive: true}, "price > :min and price < :max"},
+		{"min only", types.RangeFilter{Field: field, Min: &minP}, "price >= :min"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params []string
+			got, err := New(testDialect(false), &params).Compile(tt.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompile_TracksParams(t *testing.T) {
+	minP := types.Param{Name: "min"}
+	filter := types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{
+		eq("a", "pa"),
+		types.RangeFilter{Field: types.MetadataField{Name: "price"}, Min: &minP},
+	}}
+
+	var params []string
+	if _, err := New(testDialect(false), &params).Compile(filter); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(params) != 2 || params[0] != "pa" || params[1] != "min" {
+		t.Errorf("expected [pa min], got %v", params)
+	}
+}
+
+func TestCompile_CustomPlaceholder(t *testing.T) {
+	dialect := testDialect(false)
+	dialect.Placeholder = func(name string) string { return "{" + name + "}" }
+
+	var params []string
+	got, err := New(dialect, &params).Compile(eq("a", "pa"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "a == {pa}" {
+		t.Errorf("unexpected expression: %q", got)
+	}
+}
+
+func TestCompile_CustomComparison(t *testing.T) {
+	dialect := testDialect(false)
+	dialect.Comparison = func(field, op, value string) string { return field + ":" + op + value }
+
+	var params []string
+	got, err := New(dialect, &params).Compile(types.RangeFilter{Field: types.MetadataField{Name: "a"}, Min: &types.Param{Name: "min"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "a:>=:min" {
+		t.Errorf("unexpected expression: %q", got)
+	}
+
+	dialect.Not = ""
+	not := types.FilterGroup{Logic: types.NOT, Conditions: []types.FilterItem{eq("a", "pa")}}
+	if _, err := New(dialect, &params).Compile(not); err == nil {
+		t.Error("expected error for NOT without a keyword")
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter types.FilterItem
+	}{
+		{"unsupported operator", types.FilterCondition{Field: types.MetadataField{Name: "a"}, Operator: types.Matches}},
+		{"injected field name", eq("a == 1 or b", "p")},
+		{"empty field name", eq("", "p")},
+		{"empty group", types.FilterGroup{Logic: types.AND}},
+		{"unknown logic", types.FilterGroup{Logic: "XOR", Conditions: []types.FilterItem{eq("a", "p")}}},
+		{"unbounded range", types.RangeFilter{Field: types.MetadataField{Name: "a"}}},
+		{"geo", types.GeoFilter{Field: types.MetadataField{Name: "a"}}},
+		{"extension", types.ExtensionFilter{Provider: "elasticsearch", Payload: "script"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params []string
+			if _, err := New(testDialect(false), &params).Compile(tt.filter); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestValidateIdentifier(t *testing.T) {
+	valid := []string{"a", "_x", "price_2", "Category"}
+	for _, name := range valid {
+		if _, err := ValidateIdentifier(name); err != nil {
+			t.Errorf("expected %q to be valid: %v", name, err)
+		}
+	}
+
+	invalid := []string{"", "2a", "a b", "a-b", "a\"", "a)"}
+	for _, name := range invalid {
+		if _, err := ValidateIdentifier(name); err == nil {
+			t.Errorf("expected %q to be invalid", name)
+		}
+	}
+}
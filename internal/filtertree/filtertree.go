@@ -0,0 +1,182 @@
+// Package filtertree compiles filter ASTs into JSON-compatible trees for
+// providers whose filter languages are expressed as nested objects.
+//
+// The compiler owns traversal and parameter tracking; a Dialect supplies the
+// provider's operator tables and how nodes compose (nested objects, clause
+// arrays, operand lists). The Nested helpers implement the common {field: {op: value}}
+// composition used by Pinecone and MongoDB-style query languages.
+package filtertree
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Condition is a comparison ready to be rendered by a dialect.
+type Condition struct {
+	// Field is the metadata field name and FieldType its VDML type, if known.
+	Field     string
+	FieldType string
+
+	// Operator is the filter operator and Spelled its dialect spelling.
+	Operator types.FilterOperator
+	Spelled  string
+
+	// Value is the rendered parameter placeholder.
+	Value interface{}
+}
+
+// Bound is one side of a range filter.
+type Bound struct {
+	// Operator is GT or GE for lower bounds, LT or LE for upper bounds.
+	Operator types.FilterOperator
+	Spelled  string
+	Value    interface{}
+
+	// FieldType is the VDML type of the range field, if known.
+	FieldType string
+}
+
+// Dialect describes how a provider spells filters as JSON trees.
+type Dialect struct {
+	// Operator returns the spelling of a comparison operator.
+	// Returning false rejects the operator as unsupported.
+	Operator func(op types.FilterOperator) (string, bool)
+
+	// Logic returns the spelling of a logic operator.
+	Logic func(logic types.LogicOperator) string
+
+	// Condition renders a single comparison.
+	Condition func(c Condition) interface{}
+
+	// Group composes rendered child filters.
+	Group func(logic string, children []interface{}) interface{}
+
+	// Range renders a range filter from its lower and/or upper bounds.
+	Range func(field string, bounds []Bound) interface{}
+
+	// Geo renders a radius filter. A nil Geo rejects geo filters.
+	Geo func(field string, lat, lon, radius interface{}) interface{}
+
+	// Placeholder formats a parameter reference. Defaults to ":name".
+	Placeholder func(name string) interface{}
+}
+
+// Compiler compiles filters for a dialect, recording the parameters it references.
+type Compiler struct {
+	dialect *Dialect
+	params  *[]string
+}
+
+// New creates a compiler that appends referenced parameter names to params.
+func New(dialect *Dialect, params *[]string) *Compiler {
+	return &Compiler{dialect: dialect, params: params}
+}
+
+// Compile converts a filter into a JSON-compatible tree.
+func (c *Compiler) Compile(f types.FilterItem) (interface{}, error) {
+	switch filter := f.(type) {
+	case types.FilterCondition:
+		spelled, ok := c.dialect.Operator(filter.Operator)
+		if !ok {
+			return nil, fmt.Errorf("unsupported filter operator: %s", filter.Operator)
+		}
+		return c.dialect.Condition(Condition{
+			Field:     filter.Field.Name,
+			FieldType: filter.Field.Type,
+			Operator:  filter.Operator,
+			Spelled:   spelled,
+			Value:     c.param(filter.Value),
+		}), nil
+
+	case types.FilterGroup:
+		children := make([]interface{}, 0, len(filter.Conditions))
+		for _, cond := range filter.Conditions {
+			rendered, err := c.Compile(cond)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, rendered)
+		}
+		return c.dialect.Group(c.dialect.Logic(filter.Logic), children), nil
+
+	case types.RangeFilter:
+		var bounds []Bound
+		if filter.Min != nil {
+			op := types.GE
+			if filter.MinExclusive {
+				op = types.GT
+			}
+			bound, err := c.bound(op, *filter.Min, filter.Field.Type)
+			if err != nil {
+				return nil, err
+			}
+			bounds = append(bounds, bound)
+		}
+		if filter.Max != nil {
+			op := types.LE
+			if filter.MaxExclusive {
+				op = types.LT
+			}
+			bound, err := c.bound(op, *filter.Max, filter.Field.Type)
+			if err != nil {
+				return nil, err
+			}
+			bounds = append(bounds, bound)
+		}
+		return c.dialect.Range(filter.Field.Name, bounds), nil
+
+	case types.GeoFilter:
+		if c.dialect.Geo == nil {
+			return nil, fmt.Errorf("unsupported filter type: %T", f)
+		}
+		lat := c.param(filter.Center.Lat)
+		lon := c.param(filter.Center.Lon)
+		radius := c.param(filter.Radius)
+		return c.dialect.Geo(filter.Field.Name, lat, lon, radius), nil
+
+	case types.ExtensionFilter:
+		return nil, fmt.Errorf("%s extension filter is not supported by this renderer", filter.Provider)
+
+	default:
+		return nil, fmt.Errorf("unsupported filter type: %T", f)
+	}
+}
+
+func (c *Compiler) bound(op types.FilterOperator, p types.Param, fieldType string) (Bound, error) {
+	spelled, ok := c.dialect.Operator(op)
+	if !ok {
+		return Bound{}, fmt.Errorf("unsupported filter operator: %s", op)
+	}
+	return Bound{Operator: op, Spelled: spelled, Value: c.param(p), FieldType: fieldType}, nil
+}
+
+func (c *Compiler) param(p types.Param) interface{} {
+	*c.params = append(*c.params, p.Name)
+	if c.dialect.Placeholder != nil {
+		return c.dialect.Placeholder(p.Name)
+	}
+	return fmt.Sprintf(":%s", p.Name)
+}
+
+// NestedCondition renders {field: {op: value}}.
+func NestedCondition(c Condition) interface{} {
+	return map[string]interface{}{
+		c.Field: map[string]interface{}{c.Spelled: c.Value},
+	}
+}
+
+// NestedGroup renders {logic: [children...]}.
+func NestedGroup(logic string, children []interface{}) interface{} {
+	return map[string]interface{}{logic: children}
+}
+
+// NestedRange renders {field: {op: value, ...}} with one key per bound.
+func NestedRange(field string, bounds []Bound) interface{} {
+	rangeFilter := make(map[string]interface{}, len(bounds))
+	for _, b := range bounds {
+		rangeFilter[b.Spelled] = b.Value
+	}
+	return map[string]interface{}{field: rangeFilter}
+}
@@ -0,0 +1,156 @@
+package filtertree
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func nestedDialect() *Dialect {
+	return &Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			switch op {
+			case types.EQ:
+				return "$eq", true
+			case types.GE:
+				return "$gte", true
+			case types.GT:
+				return "$gt", true
+			case types.LE:
+				return "$lte", true
+			case types.LT:
+				return "$lt", true
+			default:
+				return "", false
+			}
+		},
+		Logic: func(logic types.LogicOperator) string {
+			return "$" + string(logic)
+		},
+		Condition: NestedCondition,
+		Group:     NestedGroup,
+		Range:     NestedRange,
+	}
+}
+
+func eq(field, param string) types.FilterCondition {
+	return types.FilterCondition{
+		Field:    types.MetadataField{Name: field},
+		Operator: types.EQ,
+		Value:    types.Param{Name: param},
+	}
+}
+
+func compileJSON(t *testing.T, d *Dialect, f types.FilterItem) (string, []string) {
+	t.Helper()
+	var params []string
+	tree, err := New(d, &params).Compile(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	return string(out), params
+}
+
+func TestCompile_Nested(t *testing.T) {
+	minP, maxP := types.Param{Name: "min"}, types.Param{Name: "max"}
+
+	tests := []struct {
+		name     string
+		filter   types.FilterItem
+		expected string
+	}{
+		{"condition", eq("a", "pa"), `{"a":{"$eq":":pa"}}`},
+		{
+			"group",
+			types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{eq("a", "pa"), eq("b", "pb")}},
+			`{"$AND":[{"a":{"$eq":":pa"}},{"b":{"$eq":":pb"}}]}`,
+		},
+		{
+			"range",
+			types.RangeFilter{Field: types.MetadataField{Name: "price"}, Min: &minP, Max: &maxP, MaxExclusive: true},
+			`{"price":{"$gte":":min","$lt":":max"}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := compileJSON(t, nestedDialect(), tt.filter)
+			if got != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompile_TracksParams(t *testing.T) {
+	minP := types.Param{Name: "min"}
+	filter := types.FilterGroup{Logic: types.OR, Conditions: []types.FilterItem{
+		eq("a", "pa"),
+		types.RangeFilter{Field: types.MetadataField{Name: "price"}, Min: &minP},
+	}}
+
+	_, params := compileJSON(t, nestedDialect(), filter)
+	if len(params) != 2 || params[0] != "pa" || params[1] != "min" {
+		t.Errorf("expected [pa min], got %v", params)
+	}
+}
+
+func TestCompile_Geo(t *testing.T) {
+	dialect := nestedDialect()
+	dialect.Geo = func(field string, lat, lon, radius interface{}) interface{} {
+		return map[string]interface{}{field: []interface{}{lat, lon, radius}}
+	}
+	filter := types.GeoFilter{
+		Field:  types.MetadataField{Name: "loc"},
+		Center: types.GeoPoint{Lat: types.Param{Name: "lat"}, Lon: types.Param{Name: "lon"}},
+		Radius: types.Param{Name: "r"},
+	}
+
+	got, params := compileJSON(t, dialect, filter)
+	if got != `{"loc":[":lat",":lon",":r"]}` {
+		t.Errorf("unexpected tree: %s", got)
+	}
+	if len(params) != 3 || params[0] != "lat" || params[1] != "lon" || params[2] != "r" {
+		t.Errorf("expected [lat lon r], got %v", params)
+	}
+}
+
+func TestCompile_CustomPlaceholder(t *testing.T) {
+	dialect := nestedDialect()
+	dialect.Placeholder = func(name string) interface{} {
+		return map[string]string{"$param": name}
+	}
+
+	got, _ := compileJSON(t, dialect, eq("a", "pa"))
+	if got != `{"a":{"$eq":{"$param":"pa"}}}` {
+		t.Errorf("unexpected tree: %s", got)
+	}
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter types.FilterItem
+	}{
+		{"unsupported operator", types.FilterCondition{Field: types.MetadataField{Name: "a"}, Operator: types.Matches}},
+		{"unsupported operator in group", types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "a"}, Operator: types.Matches},
+		}}},
+		{"geo without dialect support", types.GeoFilter{Field: types.MetadataField{Name: "loc"}}},
+		{"extension", types.ExtensionFilter{Provider: "elasticsearch", Payload: "script"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params []string
+			if _, err := New(nestedDialect(), &params).Compile(tt.filter); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+// Package querydsl compiles filters into the bool query DSL shared by
+// Elasticsearch and OpenSearch: term-level queries combined with bool
+// queries, with range queries rather than scripts for bounds.
+package querydsl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/zoobzio/vectql/internal/filtertree"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Operators lists the filter operators the DSL expresses. Array fields
+// match term queries on any element, so ArrayContains and ArrayContainsAny
+// map to term and terms.
+var Operators = []types.FilterOperator{
+	types.EQ, types.NE, types.EqFold, types.GT, types.GE, types.LT, types.LE, types.IN, types.NotIn,
+	types.StartsWith, types.ArrayContains, types.ArrayContainsAny,
+}
+
+// Dialect returns a filtertree dialect for the bool query DSL. supports
+// decides which operators the renderer accepts.
+func Dialect(supports func(types.FilterOperator) bool) *filtertree.Dialect {
+	return &filtertree.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			if !supports(op) {
+				return "", false
+			}
+			return mapOperator(op), true
+		},
+		Logic:     mapLogic,
+		Condition: condition,
+		Group:     group,
+		Range: func(field string, bounds []filtertree.Bound) interface{} {
+			return map[string]interface{}{"range": filtertree.NestedRange(field, bounds)}
+		},
+		Geo: func(field string, lat, lon, radius interface{}) interface{} {
+			return map[string]interface{}{
+				"geo_distance": map[string]interface{}{
+					"distance": radius,
+					field:      map[string]interface{}{"lat": lat, "lon": lon},
+				},
+			}
+		},
+	}
+}
+
+// Filter wraps a compiled filter in a non-scoring bool query, as used by
+// delete-by-query.
+func Filter(filter interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"bool": map[string]interface{}{"filter": []interface{}{filter}},
+	}
+}
+
+// Source returns the _source setting that returns the requested metadata
+// and the vector field, or nil to return whole documents.
+func Source(ast *types.VectorAST, vectorField string) interface{} {
+	switch {
+	case !ast.IncludeMetadata && !ast.IncludeVectors:
+		return false
+	case !ast.IncludeMetadata:
+		return []string{vectorField}
+	case len(ast.MetadataFields) > 0:
+		fields := make([]string, 0, len(ast.MetadataFields)+1)
+		for _, f := range ast.MetadataFields {
+			fields = append(fields, f.Name)
+		}
+		if ast.IncludeVectors {
+			fields = append(fields, vectorField)
+		}
+		return fields
+	case !ast.IncludeVectors:
+		return map[string]interface{}{"excludes": []string{vectorField}}
+	default:
+		return nil
+	}
+}
+
+// PIT returns the pit clause that pins a search to the point in time in
+// snapshot. Searches with a pit clause are sent to /_search without an
+// index, since the point in time already names it. The keep-alive is
+// rendered in milliseconds, rounded up.
+func PIT(snapshot *types.Snapshot, params *[]string) map[string]interface{} {
+	*params = append(*params, snapshot.ID.Name)
+	pit := map[string]interface{}{"id": fmt.Sprintf(":%s", snapshot.ID.Name)}
+	if snapshot.KeepAlive > 0 {
+		ms := (snapshot.KeepAlive + time.Millisecond - 1) / time.Millisecond
+		pit["keep_alive"] = fmt.Sprintf("%dms", ms)
+	}
+	return pit
+}
+
+// SearchPath returns the search API path for a query on index: the
+// index's own search API, or /_search for queries pinned to a point in
+// time.
+func SearchPath(ast *types.VectorAST, index string) string {
+	if ast.Snapshot != nil {
+		return "/_search"
+	}
+	return index + "/_search"
+}
+
+// condition renders a comparison as a term-level query. Negations wrap the
+// positive query in bool.must_not. EqFold sets the term query's
+// case_insensitive flag.
+func condition(c filtertree.Condition) interface{} {
+	switch c.Operator {
+	case types.EqFold:
+		return map[string]interface{}{
+			"term": map[string]interface{}{
+				c.Field: map[string]interface{}{"value": c.Value, "case_insensitive": true},
+			},
+		}
+	case types.GT, types.GE, types.LT, types.LE:
+		return map[string]interface{}{"range": filtertree.NestedCondition(c)}
+	case types.NE, types.NotIn:
+		positive := map[string]interface{}{c.Spelled: map[string]interface{}{c.Field: c.Value}}
+		return map[string]interface{}{
+			"bool": map[string]interface{}{"must_not": []interface{}{positive}},
+		}
+	default:
+		return map[string]interface{}{c.Spelled: map[string]interface{}{c.Field: c.Value}}
+	}
+}
+
+// group renders a bool query. OR groups require one matching clause.
+func group(logic string, children []interface{}) interface{} {
+	clauses := map[string]interface{}{logic: children}
+	if logic == "should" {
+		clauses["minimum_should_match"] = 1
+	}
+	return map[string]interface{}{"bool": clauses}
+}
+
+func mapOperator(op types.FilterOperator) string {
+	switch op {
+	case types.GT:
+		return "gt"
+	case types.GE:
+		return "gte"
+	case types.LT:
+		return "lt"
+	case types.LE:
+		return "lte"
+	case types.IN, types.NotIn, types.ArrayContainsAny:
+		return "terms"
+	case types.StartsWith:
+		return "prefix"
+	default:
+		return "term"
+	}
+}
+
+func mapLogic(logic types.LogicOperator) string {
+	switch logic {
+	case types.OR:
+		return "should"
+	case types.NOT:
+		return "must_not"
+	default:
+		return "filter"
+	}
+}
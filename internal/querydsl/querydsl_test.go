@@ -0,0 +1,87 @@
+package querydsl
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql/internal/filtertree"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func compile(t *testing.T, f types.FilterItem) string {
+	t.Helper()
+	var params []string
+	tree, err := filtertree.New(Dialect(func(op types.FilterOperator) bool {
+		return slices.Contains(Operators, op)
+	}), &params).Compile(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return string(data)
+}
+
+func TestDialect(t *testing.T) {
+	field := types.MetadataField{Name: "tags"}
+	tests := []struct {
+		name     string
+		filter   types.FilterItem
+		expected string
+	}{
+		{"in", types.FilterCondition{Field: field, Operator: types.IN, Value: types.Param{Name: "p"}}, `{"terms":{"tags":":p"}}`},
+		{"eq fold", types.FilterCondition{Field: field, Operator: types.EqFold, Value: types.Param{Name: "p"}}, `{"term":{"tags":{"case_insensitive":true,"value":":p"}}}`},
+		{"array contains", types.FilterCondition{Field: field, Operator: types.ArrayContains, Value: types.Param{Name: "p"}}, `{"term":{"tags":":p"}}`},
+		{"not", types.FilterGroup{Logic: types.NOT, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: field, Operator: types.EQ, Value: types.Param{Name: "p"}},
+		}}, `{"bool":{"must_not":[{"term":{"tags":":p"}}]}}`},
+		{"geo", types.GeoFilter{
+			Field:  types.MetadataField{Name: "loc"},
+			Center: types.GeoPoint{Lat: types.Param{Name: "lat"}, Lon: types.Param{Name: "lon"}},
+			Radius: types.Param{Name: "r"},
+		}, `{"geo_distance":{"distance":":r","loc":{"lat":":lat","lon":":lon"}}}`},
+	}
+	for _, tt := range tests {
+		if got := compile(t, tt.filter); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	ast := &types.VectorAST{IncludeMetadata: true, IncludeVectors: true}
+	if got := Source(ast, "embedding"); got != nil {
+		t.Errorf("expected whole documents, got %v", got)
+	}
+}
+
+func TestPIT(t *testing.T) {
+	var params []string
+	pit := PIT(&types.Snapshot{ID: types.Param{Name: "pit"}, KeepAlive: 1500 * time.Microsecond}, &params)
+	if pit["id"] != ":pit" || pit["keep_alive"] != "2ms" {
+		t.Errorf("unexpected pit clause: %v", pit)
+	}
+	if len(params) != 1 || params[0] != "pit" {
+		t.Errorf("expected params [pit], got %v", params)
+	}
+
+	pit = PIT(&types.Snapshot{ID: types.Param{Name: "pit"}}, &params)
+	if _, ok := pit["keep_alive"]; ok {
+		t.Errorf("expected no keep_alive without a keep-alive, got %v", pit)
+	}
+}
+
+func TestSearchPath(t *testing.T) {
+	ast := &types.VectorAST{}
+	if got := SearchPath(ast, "/products"); got != "/products/_search" {
+		t.Errorf("expected index search path, got %s", got)
+	}
+	ast.Snapshot = &types.Snapshot{ID: types.Param{Name: "pit"}}
+	if got := SearchPath(ast, "/products"); got != "/_search" {
+		t.Errorf("expected pinned search path, got %s", got)
+	}
+}
@@ -1,6 +1,9 @@
 package types
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // Operation represents the type of vector database operation.
 type Operation string
@@ -31,15 +34,27 @@ type VectorAST struct {
 
 	// Search-specific fields
 	QueryVector     *VectorValue
+	NearText        *NearText
+	NearImage       *NearImage
+	TextQuery       *TextQuery
 	QueryEmbedding  *EmbeddingField
 	TopK            *PaginationValue
 	MinScore        *Param
 	IncludeVectors  bool
 	IncludeMetadata bool
+	Quantization    *QuantizationParams
+
+	// Read consistency, for SEARCH and FETCH
+	Freshness *Freshness
+	Snapshot  *Snapshot
 
 	// Filter clause
 	FilterClause FilterItem
 
+	// Scoring boosts and a provider scoring expression, for SEARCH
+	Boosts  []Boost
+	Scoring *ScoreExpression
+
 	// Metadata field selection
 	MetadataFields []MetadataField
 
@@ -51,8 +66,92 @@ type VectorAST struct {
 	IDs       []Param
 	DeleteAll bool
 
+	// FetchKey looks FETCH records up by a unique metadata field instead of
+	// by ID
+	FetchKey *FetchKey
+
+	// Exists marks a FETCH as an existence check reading record IDs only.
+	// Without IDs or a key it checks for any record matching FilterClause
+	Exists bool
+
+	// Page makes a FETCH without IDs or a key a paginated listing of the
+	// records matching FilterClause
+	Page *Page
+
 	// Namespace/partition
 	Namespace *Param
+
+	// Value transforms applied at bind time, by parameter name
+	BindTransforms map[string][]BindTransform
+}
+
+// FetchKey is a FETCH by business key: the records whose unique field
+// equals one of Keys. The schema guarantees at most one record per key.
+type FetchKey struct {
+	Field MetadataField
+	Keys  []Param
+}
+
+// FetchByRenderer is implemented by renderers that render a FETCH with a
+// FetchKey as a filtered read. Other renderers reject such queries.
+type FetchByRenderer interface {
+	// SupportsFetchBy indicates if the renderer renders FETCH by key.
+	SupportsFetchBy() bool
+}
+
+// Page is a paginated listing: a FETCH of the records matching the filter,
+// Size at a time, in provider order. After holds the token of the previous
+// page; the zero token reads the first page.
+type Page struct {
+	Size  int
+	After PageToken
+}
+
+// Cursor returns the provider cursor the page resumes after, or the empty
+// string for the first page. A token issued by another provider is an
+// error.
+func (p *Page) Cursor(provider string) (string, error) {
+	if p.After.IsZero() {
+		return "", nil
+	}
+	return p.After.CursorFor(provider)
+}
+
+// ListRenderer is implemented by renderers that render a FETCH with a Page
+// as a paginated read. Other renderers reject such queries.
+type ListRenderer interface {
+	// SupportsList indicates if the renderer renders paginated listings.
+	SupportsList() bool
+}
+
+// TargetEmbedding returns the embedding the query's dense vectors target:
+// the query embedding, or else the collection's default or only embedding.
+// It is zero when neither is known.
+func (ast *VectorAST) TargetEmbedding() EmbeddingField {
+	if ast.QueryEmbedding != nil {
+		return *ast.QueryEmbedding
+	}
+	return ast.Target.Embedding
+}
+
+// ExistsRenderer is implemented by renderers that render an existence check
+// by filter as a read of at most one record. Other renderers reject such
+// queries.
+type ExistsRenderer interface {
+	// SupportsExistsByFilter indicates if the renderer renders existence
+	// checks by filter.
+	SupportsExistsByFilter() bool
+}
+
+// ExistsByFilter reports whether ast checks for any record matching its
+// filter rather than for given IDs or keys.
+func (ast *VectorAST) ExistsByFilter() bool {
+	return ast.Operation == OpFetch && ast.Exists && len(ast.IDs) == 0 && ast.FetchKey == nil
+}
+
+// Lists reports whether ast is a paginated listing.
+func (ast *VectorAST) Lists() bool {
+	return ast.Operation == OpFetch && ast.Page != nil
 }
 
 // VectorValue can be a literal vector or a parameter reference.
@@ -61,6 +160,170 @@ type VectorValue struct {
 	Param   *Param
 }
 
+// NearText is a text search input vectorized by the provider.
+type NearText struct {
+	// Concepts is a parameter holding the list of query strings.
+	Concepts Param
+}
+
+// NearImage is an image search input vectorized by the provider.
+type NearImage struct {
+	// Image is a parameter holding the base64-encoded image.
+	Image Param
+}
+
+// TextQuery is a keyword (BM25) search input. On its own it makes a keyword
+// search; combined with a query vector, a hybrid search.
+type TextQuery struct {
+	// Query is a parameter holding the query string.
+	Query Param
+
+	// Properties lists the fields BM25 searches. Empty searches every text
+	// field.
+	Properties []TextProperty
+
+	// Language is the ISO 639-1 code of the query string's language. When
+	// set, every property that declares a language must match it.
+	Language string
+}
+
+// TextProperty is a field a keyword search covers, with an optional boost.
+type TextProperty struct {
+	Field MetadataField
+
+	// Boost multiplies the field's BM25 score. Zero leaves it unboosted.
+	Boost float64
+}
+
+// Boost raises the score of search results. A filter boost adds Weight to
+// the score of results matching Filter, like a "should" clause; a field boost
+// adds a numeric field's value multiplied by Weight. Exactly one of Filter and
+// Field is set.
+type Boost struct {
+	Filter FilterItem
+	Field  *MetadataField
+	Weight float64
+}
+
+// ScoreExpression carries a provider-specific scoring expression, such as an
+// Elasticsearch script_score script. The renderer for Provider accepts only
+// the kinds it allow-lists; every other renderer rejects the query rather
+// than ranking without it.
+type ScoreExpression struct {
+	// Provider names the renderer that understands the expression. It
+	// matches Capabilities.Provider.
+	Provider string
+
+	// Kind selects the expression form within the provider, such as
+	// "script_score".
+	Kind string
+
+	// Payload is the provider-defined expression.
+	Payload interface{}
+
+	// Params lists the parameters referenced by Payload.
+	Params []Param
+}
+
+// ValidateScoring checks the query's scoring expression, if any, against a
+// renderer's provider name and allow-listed kinds. Renderers without scoring
+// expressions pass no kinds.
+func (ast *VectorAST) ValidateScoring(provider string, kinds ...string) error {
+	expr := ast.Scoring
+	if expr == nil {
+		return nil
+	}
+	if expr.Provider != provider {
+		return fmt.Errorf("%s scoring expression is not supported by %s", expr.Provider, provider)
+	}
+	for _, kind := range kinds {
+		if expr.Kind == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s does not allow %q scoring expressions", provider, expr.Kind)
+}
+
+// QuantizationParams controls how a SEARCH uses a quantized index.
+type QuantizationParams struct {
+	// Ignore searches the original vectors instead of the quantized ones.
+	Ignore bool
+
+	// Rescore re-ranks candidates with the original vectors.
+	Rescore bool
+
+	// Oversampling fetches Oversampling x TopK candidates before rescoring.
+	// Zero uses the provider default.
+	Oversampling float64
+}
+
+// FreshnessLevel is how current the data a read sees must be.
+type FreshnessLevel string
+
+// Freshness levels.
+const (
+	// FreshnessStrong reads every acknowledged write.
+	FreshnessStrong FreshnessLevel = "strong"
+
+	// FreshnessBounded reads data at most MaxStaleness behind the writes.
+	FreshnessBounded FreshnessLevel = "bounded"
+
+	// FreshnessEventual reads whatever the serving replica has.
+	FreshnessEventual FreshnessLevel = "eventual"
+)
+
+// Freshness is the consistency a read asks for.
+type Freshness struct {
+	Level FreshnessLevel
+
+	// MaxStaleness bounds the lag of FreshnessBounded reads.
+	MaxStaleness time.Duration
+}
+
+// Snapshot pins a read to a point-in-time view of the index, so repeated
+// reads see the same data while writes continue.
+type Snapshot struct {
+	// ID is a parameter holding the provider's snapshot handle, e.g. an
+	// Elasticsearch point-in-time ID.
+	ID Param
+
+	// KeepAlive extends the snapshot's lifetime by this much on every read.
+	// Zero leaves the lifetime to the provider.
+	KeepAlive time.Duration
+}
+
+// Modality identifies the kind of search input a SEARCH uses.
+type Modality string
+
+// Search input modalities.
+const (
+	ModalityVector Modality = "vector"
+	ModalityText   Modality = "text"
+	ModalityImage  Modality = "image"
+
+	// ModalityKeyword is a keyword (BM25) search without a vector.
+	ModalityKeyword Modality = "keyword"
+
+	// ModalityHybrid fuses a keyword search with a vector search.
+	ModalityHybrid Modality = "hybrid"
+)
+
+// Modality returns the kind of search input the AST carries.
+func (ast *VectorAST) Modality() Modality {
+	switch {
+	case ast.NearText != nil:
+		return ModalityText
+	case ast.NearImage != nil:
+		return ModalityImage
+	case ast.TextQuery != nil && ast.QueryVector != nil:
+		return ModalityHybrid
+	case ast.TextQuery != nil:
+		return ModalityKeyword
+	default:
+		return ModalityVector
+	}
+}
+
 // SparseVectorValue represents a sparse vector for hybrid search.
 type SparseVectorValue struct {
 	Indices []int
@@ -87,6 +350,12 @@ func (ast *VectorAST) Validate() error {
 	if ast.Target.Name == "" {
 		return fmt.Errorf("target collection is required")
 	}
+	if err := ast.validateParamScopes(); err != nil {
+		return err
+	}
+	if err := ast.validateBindTransforms(); err != nil {
+		return err
+	}
 
 	switch ast.Operation {
 	case OpSearch:
@@ -105,9 +374,36 @@ func (ast *VectorAST) Validate() error {
 }
 
 func (ast *VectorAST) validateSearch() error {
-	if ast.QueryVector == nil {
+	inputs := 0
+	for _, set := range []bool{ast.QueryVector != nil, ast.NearText != nil, ast.NearImage != nil} {
+		if set {
+			inputs++
+		}
+	}
+	if inputs == 0 && ast.TextQuery == nil {
 		return fmt.Errorf("SEARCH requires a query vector")
 	}
+	if inputs > 1 {
+		return fmt.Errorf("SEARCH accepts only one of a query vector, NearText, or NearImage")
+	}
+	if ast.TextQuery != nil {
+		if ast.NearText != nil || ast.NearImage != nil {
+			return fmt.Errorf("a text query only combines with a query vector")
+		}
+		if lang := ast.TextQuery.Language; lang != "" {
+			if _, ok := Languages[lang]; !ok {
+				return fmt.Errorf("unknown text query language: %q", lang)
+			}
+		}
+		for _, p := range ast.TextQuery.Properties {
+			if p.Boost < 0 {
+				return fmt.Errorf("boost of text property '%s' cannot be negative: %g", p.Field.Name, p.Boost)
+			}
+			if lang := ast.TextQuery.Language; lang != "" && p.Field.Language != "" && p.Field.Language != lang {
+				return fmt.Errorf("text query in language %s searches property '%s' analyzed as %s", lang, p.Field.Name, p.Field.Language)
+			}
+		}
+	}
 
 	if ast.TopK == nil {
 		return fmt.Errorf("SEARCH requires TopK")
@@ -121,19 +417,83 @@ func (ast *VectorAST) validateSearch() error {
 		return fmt.Errorf("TopK must be positive: %d", *ast.TopK.Static)
 	}
 
+	if q := ast.Quantization; q != nil {
+		if q.Ignore && (q.Rescore || q.Oversampling != 0) {
+			return fmt.Errorf("quantization cannot be both ignored and rescored")
+		}
+		if q.Oversampling != 0 && q.Oversampling < 1 {
+			return fmt.Errorf("quantization oversampling must be at least 1: %g", q.Oversampling)
+		}
+	}
+
 	if len(ast.MetadataFields) > MaxMetadataFields {
 		return fmt.Errorf("metadata fields exceed maximum: %d > %d", len(ast.MetadataFields), MaxMetadataFields)
 	}
 
 	if ast.FilterClause != nil {
-		if err := validateFilterDepth(ast.FilterClause, 0); err != nil {
+		if err := validateFilter(ast.FilterClause, 0); err != nil {
 			return err
 		}
 	}
 
+	if expr := ast.Scoring; expr != nil && (expr.Provider == "" || expr.Kind == "") {
+		return fmt.Errorf("scoring expression requires a provider and a kind")
+	}
+
+	for i, boost := range ast.Boosts {
+		if err := validateBoost(boost); err != nil {
+			return fmt.Errorf("boost %d: %w", i, err)
+		}
+	}
+
+	if key := ast.Target.PartitionKey; ast.Target.PartitionKeyRequired && key != "" && !constrainsField(ast.FilterClause, key) {
+		return fmt.Errorf("SEARCH on collection '%s' requires an equality or IN filter on partition key '%s'",
+			ast.Target.Name, key)
+	}
+
 	return nil
 }
 
+func validateBoost(boost Boost) error {
+	if (boost.Filter == nil) == (boost.Field == nil) {
+		return fmt.Errorf("requires exactly one of a filter and a field")
+	}
+	if boost.Weight <= 0 {
+		return fmt.Errorf("weight must be positive: %g", boost.Weight)
+	}
+	if boost.Filter != nil {
+		return validateFilter(boost.Filter, 0)
+	}
+	switch boost.Field.Type {
+	case "", "int", "float":
+		return nil
+	default:
+		return fmt.Errorf("field '%s' is not numeric: %s", boost.Field.Name, boost.Field.Type)
+	}
+}
+
+// constrainsField reports whether every match of f must satisfy an equality
+// or IN condition on field: either f is such a condition or it is an AND
+// group containing one.
+func constrainsField(f FilterItem, field string) bool {
+	switch filter := f.(type) {
+	case FilterCondition:
+		return filter.Field.Name == field && (filter.Operator == EQ || filter.Operator == IN)
+	case FilterGroup:
+		if filter.Logic != AND {
+			return false
+		}
+		for _, c := range filter.Conditions {
+			if constrainsField(c, field) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
 func (ast *VectorAST) validateUpsert() error {
 	if len(ast.Vectors) == 0 {
 		return fmt.Errorf("UPSERT requires at least one vector")
@@ -158,6 +518,39 @@ func (ast *VectorAST) validateDelete() error {
 }
 
 func (ast *VectorAST) validateFetch() error {
+	if page := ast.Page; page != nil {
+		if len(ast.IDs) > 0 || ast.FetchKey != nil || ast.Exists {
+			return fmt.Errorf("a listing accepts neither IDs, a key field, nor an existence check")
+		}
+		if page.Size <= 0 || page.Size > MaxIDsPerFetch {
+			return fmt.Errorf("page size must be between 1 and %d: %d", MaxIDsPerFetch, page.Size)
+		}
+		if ast.FilterClause != nil {
+			return validateFilter(ast.FilterClause, 0)
+		}
+		return nil
+	}
+	if key := ast.FetchKey; key != nil {
+		if len(ast.IDs) > 0 {
+			return fmt.Errorf("FETCH accepts either IDs or a key field, not both")
+		}
+		if !key.Field.Unique {
+			return fmt.Errorf("FETCH by key requires a unique field: '%s' is not declared unique", key.Field.Name)
+		}
+		if len(key.Keys) == 0 {
+			return fmt.Errorf("FETCH by key requires at least one key")
+		}
+		if len(key.Keys) > MaxIDsPerFetch {
+			return fmt.Errorf("too many keys: %d > %d", len(key.Keys), MaxIDsPerFetch)
+		}
+		return nil
+	}
+	if ast.ExistsByFilter() {
+		if ast.FilterClause == nil {
+			return fmt.Errorf("EXISTS requires IDs, a key field, or a filter")
+		}
+		return validateFilter(ast.FilterClause, 0)
+	}
 	if len(ast.IDs) == 0 {
 		return fmt.Errorf("FETCH requires at least one ID")
 	}
@@ -180,17 +573,22 @@ func (ast *VectorAST) validateUpdate() error {
 	return nil
 }
 
-func validateFilterDepth(f FilterItem, depth int) error {
+func validateFilter(f FilterItem, depth int) error {
 	if depth > MaxFilterDepth {
 		return fmt.Errorf("filter nesting too deep: %d > %d", depth, MaxFilterDepth)
 	}
 
-	if group, ok := f.(FilterGroup); ok {
-		for _, c := range group.Conditions {
-			if err := validateFilterDepth(c, depth+1); err != nil {
+	switch filter := f.(type) {
+	case FilterGroup:
+		for _, c := range filter.Conditions {
+			if err := validateFilter(c, depth+1); err != nil {
 				return err
 			}
 		}
+	case FilterCondition:
+		if h := filter.Hint; h != nil && (h.Selectivity < 0 || h.Selectivity > 1) {
+			return fmt.Errorf("selectivity hint on '%s' must be between 0 and 1: %g", filter.Field.Name, h.Selectivity)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,82 @@
+package types
+
+import "fmt"
+
+// TransformOp names a bind-time value transform.
+type TransformOp string
+
+// Bind transforms.
+const (
+	// TransformLower lowercases string values.
+	TransformLower TransformOp = "lower"
+
+	// TransformTrim removes leading and trailing whitespace from string values.
+	TransformTrim TransformOp = "trim"
+
+	// TransformHash replaces string values with their hex-encoded SHA-256 digest.
+	TransformHash TransformOp = "sha256"
+
+	// TransformTruncate truncates time values to the start of Unit.
+	TransformTruncate TransformOp = "truncate"
+)
+
+// TruncateUnits lists the units TransformTruncate accepts.
+var TruncateUnits = []string{"second", "minute", "hour", "day", "month", "year"}
+
+// BindTransform normalizes a parameter value when it is bound, so callers
+// cannot disagree on the form of values compared against stored metadata.
+type BindTransform struct {
+	Op TransformOp
+
+	// Unit is the truncation unit, for TransformTruncate.
+	Unit string
+}
+
+func (t BindTransform) validate() error {
+	switch t.Op {
+	case TransformLower, TransformTrim, TransformHash:
+		if t.Unit != "" {
+			return fmt.Errorf("%s transform takes no unit", t.Op)
+		}
+		return nil
+	case TransformTruncate:
+		for _, unit := range TruncateUnits {
+			if t.Unit == unit {
+				return nil
+			}
+		}
+		return fmt.Errorf("invalid truncation unit: %q", t.Unit)
+	default:
+		return fmt.Errorf("unknown bind transform: %q", t.Op)
+	}
+}
+
+// validateBindTransforms checks that transforms name scalar parameters of the
+// query and are well formed.
+func (ast *VectorAST) validateBindTransforms() error {
+	if len(ast.BindTransforms) == 0 {
+		return nil
+	}
+	specs := make(map[string]ParamSpec)
+	for _, spec := range ast.ParamSpecs() {
+		specs[spec.Name] = spec
+	}
+	for name, transforms := range ast.BindTransforms {
+		spec, ok := specs[name]
+		if !ok {
+			return fmt.Errorf("bind transform for unknown parameter: %s", name)
+		}
+		if spec.Type == ParamVector {
+			return fmt.Errorf("bind transforms do not apply to vector parameter %s", name)
+		}
+		if len(transforms) == 0 {
+			return fmt.Errorf("parameter %s has an empty bind transform list", name)
+		}
+		for _, t := range transforms {
+			if err := t.validate(); err != nil {
+				return fmt.Errorf("parameter %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
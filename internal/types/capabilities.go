@@ -0,0 +1,220 @@
+package types
+
+import (
+	"errors"
+	"slices"
+)
+
+// Format identifies the wire format of a rendered query.
+type Format string
+
+// Wire formats.
+const (
+	FormatJSON Format = "json"
+)
+
+// Endpoint describes the provider API call a rendered query is sent to.
+type Endpoint struct {
+	// Method is the HTTP method.
+	Method string
+
+	// Path is relative to the provider base URL. It may contain ":name"
+	// placeholders for parameters, like the rendered body.
+	Path string
+
+	// Lines reports that the call takes newline-delimited JSON, as bulk APIs
+	// do. The rendered body is then a JSON array with one element per line.
+	Lines bool
+
+	// Query is an encoded query string, as url.Values.Encode returns it.
+	// Its values may embed ":name" placeholders, which are bound like
+	// expression strings in the body.
+	Query string
+}
+
+// ErrNoEndpoint is returned by Endpoint when a renderer does not describe
+// the provider API call for a query.
+var ErrNoEndpoint = errors.New("renderer does not describe endpoints")
+
+// Operations lists every operation, in declaration order.
+var Operations = []Operation{OpSearch, OpUpsert, OpDelete, OpFetch, OpUpdate}
+
+// FilterOperators lists every filter operator, in declaration order.
+var FilterOperators = []FilterOperator{
+	EQ, NE, EqFold, GT, GE, LT, LE, IN, NotIn,
+	Contains, StartsWith, EndsWith, Matches,
+	Exists, NotExists,
+	ArrayContains, ArrayContainsAny, ArrayContainsAll,
+}
+
+// DistanceMetrics lists every distance metric, in declaration order.
+var DistanceMetrics = []DistanceMetric{Cosine, Euclidean, DotProduct, Manhattan}
+
+// Capabilities describes what a renderer supports.
+type Capabilities struct {
+	// Provider names the vector database, e.g. "pinecone".
+	Provider string
+
+	// Operations, Filters, and Metrics list the supported features.
+	Operations []Operation
+	Filters    []FilterOperator
+	Metrics    []DistanceMetric
+
+	// Modalities lists the supported search inputs. Every renderer supports
+	// ModalityVector.
+	Modalities []Modality
+
+	// VectorEncodings lists the compact vector encodings the provider accepts
+	// in place of JSON number arrays. Every renderer accepts VectorEncodingJSON.
+	VectorEncodings []VectorEncoding
+
+	// Snapshots reports that reads can be pinned to a point-in-time view of
+	// the index.
+	Snapshots bool
+
+	// ContentEncodings lists the HTTP Content-Encodings the provider
+	// accepts on request bodies, e.g. "gzip". Bodies are sent uncompressed
+	// when it is empty.
+	ContentEncodings []string
+}
+
+// SupportsOperation indicates if an operation is supported.
+func (c Capabilities) SupportsOperation(op Operation) bool {
+	return slices.Contains(c.Operations, op)
+}
+
+// SupportsFilter indicates if a filter operator is supported.
+func (c Capabilities) SupportsFilter(op FilterOperator) bool {
+	return slices.Contains(c.Filters, op)
+}
+
+// SupportsMetric indicates if a distance metric is supported.
+func (c Capabilities) SupportsMetric(metric DistanceMetric) bool {
+	return slices.Contains(c.Metrics, metric)
+}
+
+// SupportsModality indicates if a search input modality is supported.
+func (c Capabilities) SupportsModality(m Modality) bool {
+	return slices.Contains(c.Modalities, m)
+}
+
+// SupportsVectorEncoding indicates if a bind-time vector encoding is accepted.
+func (c Capabilities) SupportsVectorEncoding(e VectorEncoding) bool {
+	return e == VectorEncodingJSON || slices.Contains(c.VectorEncodings, e)
+}
+
+// SupportsContentEncoding indicates if request bodies can be sent with a
+// Content-Encoding.
+func (c Capabilities) SupportsContentEncoding(encoding string) bool {
+	return slices.Contains(c.ContentEncodings, encoding)
+}
+
+// Prober reports support for individual features.
+type Prober interface {
+	SupportsOperation(op Operation) bool
+	SupportsFilter(op FilterOperator) bool
+	SupportsMetric(metric DistanceMetric) bool
+}
+
+// ModalityProber is implemented by renderers that accept search inputs other
+// than vectors.
+type ModalityProber interface {
+	SupportsModality(m Modality) bool
+}
+
+// Modalities lists every search input modality, in declaration order.
+var Modalities = []Modality{ModalityVector, ModalityText, ModalityImage, ModalityKeyword, ModalityHybrid}
+
+// VectorEncoding identifies how dense vectors are written into a bound body.
+type VectorEncoding string
+
+// Vector encodings.
+const (
+	// VectorEncodingJSON writes vectors as JSON number arrays.
+	VectorEncodingJSON VectorEncoding = ""
+
+	// VectorEncodingFloat32Base64 writes vectors as base64 strings of packed
+	// little-endian float32 values.
+	VectorEncodingFloat32Base64 VectorEncoding = "float32_base64"
+
+	// VectorEncodingFloat16Base64 writes vectors as base64 strings of packed
+	// little-endian IEEE 754 half-precision values.
+	VectorEncodingFloat16Base64 VectorEncoding = "float16_base64"
+)
+
+// VectorEncodings lists every compact vector encoding, most compact first.
+var VectorEncodings = []VectorEncoding{VectorEncodingFloat16Base64, VectorEncodingFloat32Base64}
+
+// VectorEncodingProber is implemented by renderers whose provider accepts
+// compact vector encodings.
+type VectorEncodingProber interface {
+	SupportsVectorEncoding(e VectorEncoding) bool
+}
+
+// SnapshotProber is implemented by renderers whose provider supports
+// point-in-time reads.
+type SnapshotProber interface {
+	SupportsSnapshots() bool
+}
+
+// ContentEncodings lists the request Content-Encodings ProbeCapabilities
+// probes for.
+var ContentEncodings = []string{"gzip", "deflate", "br", "zstd"}
+
+// ContentEncodingProber is implemented by renderers whose provider accepts
+// compressed request bodies.
+type ContentEncodingProber interface {
+	SupportsContentEncoding(encoding string) bool
+}
+
+// ProbeCapabilities builds Capabilities by probing every known operation,
+// filter operator, and distance metric. Modalities are probed when p
+// implements ModalityProber; otherwise only ModalityVector is reported.
+// Compact vector encodings are probed when p implements VectorEncodingProber,
+// snapshot support when it implements SnapshotProber, and request
+// Content-Encodings when it implements ContentEncodingProber.
+func ProbeCapabilities(provider string, p Prober) Capabilities {
+	caps := Capabilities{Provider: provider}
+	if mp, ok := p.(ModalityProber); ok {
+		for _, m := range Modalities {
+			if mp.SupportsModality(m) {
+				caps.Modalities = append(caps.Modalities, m)
+			}
+		}
+	} else {
+		caps.Modalities = []Modality{ModalityVector}
+	}
+	if ep, ok := p.(VectorEncodingProber); ok {
+		for _, e := range VectorEncodings {
+			if ep.SupportsVectorEncoding(e) {
+				caps.VectorEncodings = append(caps.VectorEncodings, e)
+			}
+		}
+	}
+	if sp, ok := p.(SnapshotProber); ok {
+		caps.Snapshots = sp.SupportsSnapshots()
+	}
+	if cp, ok := p.(ContentEncodingProber); ok {
+		for _, e := range ContentEncodings {
+			if cp.SupportsContentEncoding(e) {
+				caps.ContentEncodings = append(caps.ContentEncodings, e)
+			}
+		}
+	}
+	for _, op := range Operations {
+		if p.SupportsOperation(op) {
+			caps.Operations = append(caps.Operations, op)
+		}
+	}
+	for _, op := range FilterOperators {
+		if p.SupportsFilter(op) {
+			caps.Filters = append(caps.Filters, op)
+		}
+	}
+	for _, metric := range DistanceMetrics {
+		if p.SupportsMetric(metric) {
+			caps.Metrics = append(caps.Metrics, metric)
+		}
+	}
+	return caps
+}
@@ -4,4 +4,21 @@ package types
 type Collection struct {
 	Name      string
 	Namespace string
+
+	// PartitionKey is the metadata field the collection is partitioned by,
+	// if any.
+	PartitionKey string
+
+	// PartitionKeyRequired makes SEARCH require a filter on PartitionKey, so
+	// providers can prune partitions and no query scans every tenant.
+	PartitionKeyRequired bool
+
+	// DefaultEmbedding names the embedding used when a query or record does
+	// not name one. Renderers for providers with named vectors use it.
+	DefaultEmbedding string
+
+	// Embedding is the embedding targeted by query vectors and records that
+	// do not name one: the default embedding, or the only embedding of the
+	// collection. It is zero when neither is known.
+	Embedding EmbeddingField
 }
@@ -0,0 +1,8 @@
+package types
+
+// Command is one command of a rendered command plan, for stores such as
+// Redis that are driven by a command protocol instead of a JSON API.
+type Command struct {
+	Name string
+	Args []Arg
+}
@@ -4,4 +4,61 @@ package types
 type EmbeddingField struct {
 	Name       string
 	Collection string
+
+	// Dimensions is the vector size declared in the schema, if known.
+	Dimensions int
+
+	// Model is the embedding model declared in the schema, if any.
+	Model EmbeddingModel
+
+	// Metric is the distance metric declared in the schema, if any.
+	Metric DistanceMetric
+
+	// Normalization overrides whether bound vectors are L2-normalized.
+	Normalization Normalization
+
+	// Quantization is the storage quantization declared in the schema, if any.
+	Quantization Quantization
+}
+
+// Quantization identifies how an embedding's vectors are compressed in the index.
+type Quantization string
+
+// Quantization schemes.
+const (
+	QuantizationNone    Quantization = ""
+	QuantizationScalar  Quantization = "scalar"
+	QuantizationProduct Quantization = "product"
+	QuantizationBinary  Quantization = "binary"
+)
+
+// Normalization controls L2 normalization of vectors at bind time.
+type Normalization string
+
+// Normalization modes.
+const (
+	// NormalizeDefault normalizes when requested at bind time and the metric
+	// is cosine or dot product.
+	NormalizeDefault Normalization = ""
+	NormalizeAlways  Normalization = "always"
+	NormalizeNever   Normalization = "never"
+)
+
+// EmbeddingModel identifies the model that produces vectors for an embedding.
+type EmbeddingModel struct {
+	Name    string
+	Version string
+}
+
+// IsZero reports whether no model is declared.
+func (m EmbeddingModel) IsZero() bool {
+	return m.Name == ""
+}
+
+// String returns "name" or "name@version".
+func (m EmbeddingModel) String() string {
+	if m.Version == "" {
+		return m.Name
+	}
+	return m.Name + "@" + m.Version
 }
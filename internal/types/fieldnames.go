@@ -0,0 +1,82 @@
+package types
+
+import "strings"
+
+// FieldNames maps the logical metadata field names of a schema to the
+// physical names a deployment stores them under, so schemas keep clean
+// names when a deployment prefixes payload keys or maps index fields.
+type FieldNames struct {
+	// Prefix is prepended verbatim to every physical name.
+	Prefix string
+
+	// Fields maps logical field names to physical names. Keys are field
+	// names or "collection.field"; the latter take precedence. Unmapped
+	// fields keep their logical name.
+	Fields map[string]string
+}
+
+// IsZero reports whether the mapping leaves every name unchanged.
+func (n FieldNames) IsZero() bool {
+	return n.Prefix == "" && len(n.Fields) == 0
+}
+
+// Physical returns the physical name of f. The shadow field of a mapped
+// case-insensitive field follows its mapping.
+func (n FieldNames) Physical(f MetadataField) string {
+	return n.Prefix + n.mapped(f.Collection, f.Name)
+}
+
+func (n FieldNames) mapped(collection, name string) string {
+	if physical, ok := n.Fields[collection+"."+name]; ok {
+		return physical
+	}
+	if physical, ok := n.Fields[name]; ok {
+		return physical
+	}
+	if base, ok := strings.CutSuffix(name, ShadowSuffix); ok {
+		if physical := n.mapped(collection, base); physical != base {
+			return physical + ShadowSuffix
+		}
+	}
+	return name
+}
+
+// Logical returns the logical name of a physical field name in collection,
+// reporting false for names the mapping does not produce.
+func (n FieldNames) Logical(collection, physical string) (string, bool) {
+	name, ok := strings.CutPrefix(physical, n.Prefix)
+	if !ok {
+		return physical, false
+	}
+	if logical, ok := n.reverse(collection, name); ok {
+		return logical, true
+	}
+	if base, ok := strings.CutSuffix(name, ShadowSuffix); ok {
+		if logical, ok := n.reverse(collection, base); ok {
+			return logical + ShadowSuffix, true
+		}
+	}
+	if n.mapped(collection, name) != name {
+		// A mapped field's logical name is not a physical name.
+		return physical, false
+	}
+	return name, true
+}
+
+func (n FieldNames) reverse(collection, name string) (string, bool) {
+	prefix := collection + "."
+	for key, physical := range n.Fields {
+		if physical == name && strings.HasPrefix(key, prefix) {
+			return strings.TrimPrefix(key, prefix), true
+		}
+	}
+	for key, physical := range n.Fields {
+		if physical != name || strings.Contains(key, ".") {
+			continue
+		}
+		if _, overridden := n.Fields[prefix+key]; !overridden {
+			return key, true
+		}
+	}
+	return "", false
+}
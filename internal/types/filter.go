@@ -10,6 +10,21 @@ type FilterCondition struct {
 	Field    MetadataField
 	Operator FilterOperator
 	Value    Param
+
+	// Hint describes how the condition behaves for provider planners. It
+	// never changes which documents match.
+	Hint *FilterHint
+}
+
+// FilterHint describes a filter condition to provider query planners.
+type FilterHint struct {
+	// Indexed reports that the field has a payload or keyword index that
+	// can serve the condition.
+	Indexed bool
+
+	// Selectivity is the expected fraction of documents the condition
+	// matches, in (0, 1]. Zero means unknown.
+	Selectivity float64
 }
 
 func (FilterCondition) isFilterItem() {}
@@ -42,8 +57,87 @@ type GeoFilter struct {
 
 func (GeoFilter) isFilterItem() {}
 
+// ExtensionFilter carries a provider-specific filter node that the core AST
+// cannot express, such as a Weaviate nearText where or an Elasticsearch
+// script filter. Renderers for Provider interpret Payload; all others reject it.
+type ExtensionFilter struct {
+	// Provider names the renderer that understands Payload. It matches
+	// Capabilities.Provider.
+	Provider string
+
+	// Payload is the provider-defined filter value.
+	Payload interface{}
+
+	// Params lists the parameters referenced by Payload.
+	Params []Param
+}
+
+func (ExtensionFilter) isFilterItem() {}
+
 // GeoPoint represents a geographic coordinate.
 type GeoPoint struct {
 	Lat Param
 	Lon Param
 }
+
+// Selectivity estimates the fraction of documents f matches from the hints
+// of its conditions, treating conditions as independent. It returns false
+// when a condition the estimate depends on has no selectivity hint.
+func Selectivity(f FilterItem) (float64, bool) {
+	switch filter := f.(type) {
+	case FilterCondition:
+		if filter.Hint == nil || filter.Hint.Selectivity == 0 {
+			return 0, false
+		}
+		return filter.Hint.Selectivity, true
+
+	case FilterGroup:
+		// AND multiplies the match fractions of its hinted conditions. OR and
+		// NOT multiply the miss fractions: NOT matches documents that match
+		// none of its conditions.
+		estimate, known := 1.0, false
+		for _, c := range filter.Conditions {
+			s, ok := Selectivity(c)
+			switch {
+			case !ok && filter.Logic == AND:
+				continue
+			case !ok:
+				return 0, false
+			case filter.Logic == AND:
+				estimate *= s
+			default:
+				estimate *= 1 - s
+			}
+			known = true
+		}
+		if !known {
+			return 0, false
+		}
+		if filter.Logic == OR {
+			return 1 - estimate, true
+		}
+		return estimate, true
+
+	default:
+		return 0, false
+	}
+}
+
+// IndexedOnly reports whether every condition of f is hinted as served by
+// an index. Range, geo, and extension filters carry no hints and count as
+// unindexed.
+func IndexedOnly(f FilterItem) bool {
+	switch filter := f.(type) {
+	case FilterCondition:
+		return filter.Hint != nil && filter.Hint.Indexed
+	case FilterGroup:
+		for _, c := range filter.Conditions {
+			if !IndexedOnly(c) {
+				return false
+			}
+		}
+		return len(filter.Conditions) > 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,41 @@
+package types
+
+import "strings"
+
+// suspiciousPatterns contains strings that indicate potential injection attempts.
+var suspiciousPatterns = []string{
+	";", "--", "/*", "*/", "'", "\"", "`", "\\",
+	" or ", " and ", "drop ", "delete ", "insert ",
+	"update ", "select ", "union ", "exec ", "execute ",
+}
+
+// IsValidIdentifier reports whether s is safe to use as a parameter or
+// field name: [A-Za-z_][A-Za-z0-9_]* with no injection patterns.
+func IsValidIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	// Check character validity
+	for i, r := range s {
+		if i == 0 {
+			if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && r != '_' {
+				return false
+			}
+		} else {
+			if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '_' {
+				return false
+			}
+		}
+	}
+
+	// Check for injection patterns
+	lower := strings.ToLower(s)
+	for _, pattern := range suspiciousPatterns {
+		if strings.Contains(lower, pattern) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -4,4 +4,115 @@ package types
 type MetadataField struct {
 	Name       string
 	Collection string
+
+	// Type is the VDML metadata type declared in the schema, e.g. "string"
+	// or "[]int". It is empty when the type is unknown.
+	Type string
+
+	// Index is the kind of payload index the schema declares on the field.
+	// It is empty when the field is not indexed or the schema is unknown.
+	Index IndexKind
+
+	// CaseInsensitive reports that the schema declares the field's string
+	// values case-insensitive, so equality filters on it compare with EqFold.
+	CaseInsensitive bool
+
+	// Language is the ISO 639-1 code of the language of a text field's
+	// values, e.g. "en" or "ja". DDL renderers configure the field's
+	// tokenization from it. It is empty when the schema declares none.
+	Language string
+
+	// Unique reports that the schema declares the field a unique business
+	// key, so it can address records in FETCH by key.
+	Unique bool
+}
+
+// Languages maps the ISO 639-1 codes text fields may declare to language
+// names. The names match the Elasticsearch language analyzers.
+var Languages = map[string]string{
+	"ar": "arabic",
+	"bg": "bulgarian",
+	"ca": "catalan",
+	"cs": "czech",
+	"da": "danish",
+	"de": "german",
+	"el": "greek",
+	"en": "english",
+	"es": "spanish",
+	"eu": "basque",
+	"fa": "persian",
+	"fi": "finnish",
+	"fr": "french",
+	"ga": "irish",
+	"gl": "galician",
+	"hi": "hindi",
+	"hu": "hungarian",
+	"hy": "armenian",
+	"id": "indonesian",
+	"it": "italian",
+	"ja": "japanese",
+	"ko": "korean",
+	"lt": "lithuanian",
+	"lv": "latvian",
+	"nl": "dutch",
+	"no": "norwegian",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sv": "swedish",
+	"th": "thai",
+	"tr": "turkish",
+	"zh": "chinese",
+}
+
+// Unsegmented reports whether a language is written without spaces between
+// words, so it needs a dictionary or n-gram tokenizer.
+func Unsegmented(language string) bool {
+	switch language {
+	case "ja", "ko", "th", "zh":
+		return true
+	default:
+		return false
+	}
+}
+
+// ShadowSuffix names the lowercased copy of a case-insensitive field that
+// providers without case-insensitive comparison filter on instead.
+const ShadowSuffix = "_lower"
+
+// Shadow returns the lowercased shadow field of f.
+func (f MetadataField) Shadow() MetadataField {
+	return MetadataField{Name: f.Name + ShadowSuffix, Collection: f.Collection, Type: f.Type, Index: f.Index}
+}
+
+// IndexKind is the kind of a payload index on a metadata field.
+type IndexKind string
+
+// Payload index kinds. Keyword indexes serve exact matches on strings, text
+// indexes serve full-text matches, and integer and float indexes serve
+// equality and range filters on numbers.
+const (
+	IndexKeyword IndexKind = "keyword"
+	IndexInteger IndexKind = "integer"
+	IndexFloat   IndexKind = "float"
+	IndexBool    IndexKind = "bool"
+	IndexGeo     IndexKind = "geo"
+	IndexText    IndexKind = "text"
+)
+
+// DefaultIndexKind returns the index kind an indexed field of a VDML type
+// gets when the schema does not declare one, or "" when there is none.
+func DefaultIndexKind(fieldType string) IndexKind {
+	switch fieldType {
+	case "string", "[]string":
+		return IndexKeyword
+	case "int", "[]int":
+		return IndexInteger
+	case "float", "[]float":
+		return IndexFloat
+	case "bool":
+		return IndexBool
+	default:
+		return ""
+	}
 }
@@ -0,0 +1,22 @@
+package types
+
+// SchemaNames lists the names a schema declares, for providers that restrict
+// names; see NameValidator.
+type SchemaNames struct {
+	Collections []CollectionNames
+}
+
+// CollectionNames lists the names a collection declares.
+type CollectionNames struct {
+	Name       string
+	Embeddings []string
+	Fields     []string
+}
+
+// NameValidator is implemented by renderers for providers with reserved
+// words or naming rules, such as Weaviate class names, Milvus field names,
+// and Pinecone metadata keys.
+type NameValidator interface {
+	// ValidateNames reports the names the provider cannot store.
+	ValidateNames(names SchemaNames) error
+}
@@ -4,10 +4,11 @@ package types
 // FilterOperator represents metadata filter operators.
 type FilterOperator string
 
-// Equality operators.
+// Equality operators. EqFold compares strings case-insensitively.
 const (
-	EQ FilterOperator = "="
-	NE FilterOperator = "!="
+	EQ     FilterOperator = "="
+	NE     FilterOperator = "!="
+	EqFold FilterOperator = "EQ_FOLD"
 )
 
 // Comparison operators.
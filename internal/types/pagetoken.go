@@ -0,0 +1,86 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// PageToken is an opaque cursor for resuming a paginated read.
+//
+// It wraps the provider-specific cursor (Qdrant next_page_offset, Weaviate
+// after-UUID, Elasticsearch search_after) together with the provider that
+// issued it, so application code can pass tokens between requests without
+// knowing their shape. Tokens round-trip through String and ParsePageToken.
+type PageToken struct {
+	provider string
+	cursor   string
+}
+
+// pageTokenWire is the serialized form of a PageToken.
+type pageTokenWire struct {
+	Provider string `json:"p"`
+	Cursor   string `json:"c"`
+}
+
+// NewPageToken creates a page token for a provider cursor.
+func NewPageToken(provider, cursor string) PageToken {
+	return PageToken{provider: provider, cursor: cursor}
+}
+
+// Provider returns the name of the provider that issued the token.
+func (t PageToken) Provider() string {
+	return t.provider
+}
+
+// Cursor returns the provider-specific cursor value.
+func (t PageToken) Cursor() string {
+	return t.cursor
+}
+
+// IsZero reports whether the token is empty, meaning there are no more pages.
+func (t PageToken) IsZero() bool {
+	return t.cursor == ""
+}
+
+// CursorFor returns the cursor if the token was issued by the given provider.
+// Tokens from another provider cannot be resumed and produce an error.
+func (t PageToken) CursorFor(provider string) (string, error) {
+	if t.provider != provider {
+		return "", fmt.Errorf("page token was issued by '%s', not '%s'", t.provider, provider)
+	}
+	return t.cursor, nil
+}
+
+// String encodes the token as URL-safe text suitable for API responses.
+// The zero token encodes as the empty string.
+func (t PageToken) String() string {
+	if t.IsZero() {
+		return ""
+	}
+	data, err := json.Marshal(pageTokenWire{Provider: t.provider, Cursor: t.cursor})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParsePageToken decodes a token produced by PageToken.String.
+// The empty string decodes to the zero token.
+func ParsePageToken(s string) (PageToken, error) {
+	if s == "" {
+		return PageToken{}, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return PageToken{}, fmt.Errorf("invalid page token: %w", err)
+	}
+	var wire pageTokenWire
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return PageToken{}, fmt.Errorf("invalid page token: %w", err)
+	}
+	if wire.Provider == "" || wire.Cursor == "" {
+		return PageToken{}, fmt.Errorf("invalid page token: missing provider or cursor")
+	}
+	return PageToken{provider: wire.Provider, cursor: wire.Cursor}, nil
+}
@@ -1,6 +1,302 @@
 package types
 
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
 // Param represents a named parameter reference.
 type Param struct {
 	Name string
+
+	// Scope records the scopes added by WithScope, outermost first and
+	// separated by dots. It is empty for unscoped parameters.
+	Scope string
+}
+
+// WithScope returns the parameter prefixed with scope, so parameters added
+// by different parts of a composed query cannot merge: P("tenant") scoped to
+// "policy" binds as "policy_tenant". Scopes nest.
+func (p Param) WithScope(scope string) Param {
+	scoped := Param{Name: scope + "_" + p.Name, Scope: scope}
+	if p.Scope != "" {
+		scoped.Scope = scope + "." + p.Scope
+	}
+	return scoped
+}
+
+// ParamType is the JSON type of a parameter value. The names match the
+// parameter types of saved query definitions.
+type ParamType string
+
+// Parameter types. ParamAny marks parameters whose type cannot be inferred,
+// such as those of provider extension filters or fields of unknown type.
+const (
+	ParamAny     ParamType = ""
+	ParamVector  ParamType = "vector"
+	ParamString  ParamType = "string"
+	ParamNumber  ParamType = "number"
+	ParamInteger ParamType = "integer"
+	ParamBoolean ParamType = "boolean"
+	ParamList    ParamType = "list"
+)
+
+// ParamSource identifies the part of a query that uses a parameter.
+type ParamSource string
+
+// Parameter sources.
+const (
+	SourceQueryVector    ParamSource = "query_vector"
+	SourceNearText       ParamSource = "near_text"
+	SourceNearImage      ParamSource = "near_image"
+	SourceTextQuery      ParamSource = "text_query"
+	SourceTopK           ParamSource = "top_k"
+	SourceMinScore       ParamSource = "min_score"
+	SourceNamespace      ParamSource = "namespace"
+	SourceSnapshot       ParamSource = "snapshot"
+	SourceID             ParamSource = "id"
+	SourceKey            ParamSource = "key"
+	SourceRecordID       ParamSource = "record_id"
+	SourceRecordVector   ParamSource = "record_vector"
+	SourceSparseVector   ParamSource = "sparse_vector"
+	SourceRecordMetadata ParamSource = "record_metadata"
+	SourceUpdate         ParamSource = "update"
+	SourceFilter         ParamSource = "filter"
+	SourceScoring        ParamSource = "scoring"
+)
+
+// ParamSpec describes a parameter a query requires, for generating request
+// validation and API schemas.
+type ParamSpec struct {
+	Name string
+	Type ParamType
+
+	// Items is the element type of list parameters.
+	Items ParamType
+
+	// Dimensions is the vector size of vector parameters, if the targeted
+	// embedding declares one.
+	Dimensions int
+
+	// Source is where the parameter is first used, and Field the metadata
+	// field it is bound to, if any.
+	Source ParamSource
+	Field  string
+
+	// Sources lists every distinct position the parameter is used at, in
+	// order of first use. Sources[0] is Source.
+	Sources []ParamSource
+
+	// Repeats reports whether the query uses the parameter more than once.
+	Repeats bool
+}
+
+// ParamSpecs describes every parameter of the AST in order of first use.
+func (ast *VectorAST) ParamSpecs() []ParamSpec {
+	var specs []ParamSpec
+	index := make(map[string]int)
+	for _, use := range ast.paramUses() {
+		if i, ok := index[use.param.Name]; ok {
+			specs[i].Repeats = true
+			if !slices.Contains(specs[i].Sources, use.spec.Source) {
+				specs[i].Sources = append(specs[i].Sources, use.spec.Source)
+			}
+			continue
+		}
+		index[use.param.Name] = len(specs)
+		spec := use.spec
+		spec.Name = use.param.Name
+		spec.Sources = []ParamSource{spec.Source}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// paramUse is one occurrence of a parameter and where in the query it is
+// used.
+type paramUse struct {
+	param Param
+	site  string
+	spec  ParamSpec
+}
+
+// validateParamScopes reports parameters that resolve to the same name from
+// different scopes, which would otherwise silently bind to one value.
+func (ast *VectorAST) validateParamScopes() error {
+	seen := make(map[string]paramUse)
+	for _, use := range ast.paramUses() {
+		if use.param.Scope != "" && !IsValidIdentifier(use.param.Name) {
+			return fmt.Errorf("invalid scoped parameter name: %s", use.param.Name)
+		}
+		first, ok := seen[use.param.Name]
+		if !ok {
+			seen[use.param.Name] = use
+			continue
+		}
+		if first.param.Scope != use.param.Scope {
+			return fmt.Errorf("parameter '%s' defined twice: %s at %s and %s at %s",
+				use.param.Name, describeScope(first.param.Scope), first.site, describeScope(use.param.Scope), use.site)
+		}
+	}
+	return nil
+}
+
+func describeScope(scope string) string {
+	if scope == "" {
+		return "unscoped"
+	}
+	return fmt.Sprintf("scope '%s'", scope)
+}
+
+// paramUses lists every parameter in the AST in a stable order.
+func (ast *VectorAST) paramUses() []paramUse {
+	var uses []paramUse
+	add := func(p *Param, site string, spec ParamSpec) {
+		if p != nil && p.Name != "" {
+			uses = append(uses, paramUse{param: *p, site: site, spec: spec})
+		}
+	}
+	vector := ParamSpec{Type: ParamVector, Dimensions: ast.TargetEmbedding().Dimensions}
+
+	if ast.QueryVector != nil {
+		spec := vector
+		spec.Source = SourceQueryVector
+		add(ast.QueryVector.Param, "query vector", spec)
+	}
+	if ast.NearText != nil {
+		add(&ast.NearText.Concepts, "near text", ParamSpec{Type: ParamList, Items: ParamString, Source: SourceNearText})
+	}
+	if ast.NearImage != nil {
+		add(&ast.NearImage.Image, "near image", ParamSpec{Type: ParamString, Source: SourceNearImage})
+	}
+	if ast.TextQuery != nil {
+		add(&ast.TextQuery.Query, "text query", ParamSpec{Type: ParamString, Source: SourceTextQuery})
+	}
+	if ast.TopK != nil {
+		add(ast.TopK.Param, "topK", ParamSpec{Type: ParamInteger, Source: SourceTopK})
+	}
+	add(ast.MinScore, "min score", ParamSpec{Type: ParamNumber, Source: SourceMinScore})
+	add(ast.Namespace, "namespace", ParamSpec{Type: ParamString, Source: SourceNamespace})
+	if ast.Snapshot != nil {
+		add(&ast.Snapshot.ID, "snapshot", ParamSpec{Type: ParamString, Source: SourceSnapshot})
+	}
+	for i := range ast.IDs {
+		add(&ast.IDs[i], fmt.Sprintf("id %d", i), ParamSpec{Type: ParamString, Source: SourceID})
+	}
+	if key := ast.FetchKey; key != nil {
+		for i := range key.Keys {
+			add(&key.Keys[i], fmt.Sprintf("key %d", i), fieldSpec(key.Field, SourceKey))
+		}
+	}
+	for i := range ast.Vectors {
+		record := &ast.Vectors[i]
+		add(&record.ID, fmt.Sprintf("record %d id", i), ParamSpec{Type: ParamString, Source: SourceRecordID})
+		spec := vector
+		spec.Source = SourceRecordVector
+		add(record.Vector.Param, fmt.Sprintf("record %d vector", i), spec)
+		if record.SparseVector != nil {
+			add(record.SparseVector.Param, fmt.Sprintf("record %d sparse vector", i), ParamSpec{Type: ParamVector, Source: SourceSparseVector})
+		}
+		for _, field := range sortedFields(record.Metadata) {
+			p := record.Metadata[field]
+			add(&p, fmt.Sprintf("record %d metadata '%s'", i, field.Name), fieldSpec(field, SourceRecordMetadata))
+		}
+	}
+	for _, field := range sortedFields(ast.Updates) {
+		p := ast.Updates[field]
+		add(&p, fmt.Sprintf("update of '%s'", field.Name), fieldSpec(field, SourceUpdate))
+	}
+	if ast.FilterClause != nil {
+		filterParamUses(ast.FilterClause, add)
+	}
+	for _, boost := range ast.Boosts {
+		if boost.Filter != nil {
+			filterParamUses(boost.Filter, add)
+		}
+	}
+	if expr := ast.Scoring; expr != nil {
+		for i := range expr.Params {
+			add(&expr.Params[i], fmt.Sprintf("%s scoring expression", expr.Provider), ParamSpec{Source: SourceScoring})
+		}
+	}
+	return uses
+}
+
+func filterParamUses(item FilterItem, add func(*Param, string, ParamSpec)) {
+	switch f := item.(type) {
+	case FilterCondition:
+		spec := fieldSpec(f.Field, SourceFilter)
+		switch f.Operator {
+		case IN, NotIn, ArrayContainsAny, ArrayContainsAll:
+			spec.Items = elementType(f.Field.Type)
+			spec.Type = ParamList
+		case ArrayContains:
+			spec.Type, spec.Items = elementType(f.Field.Type), ParamAny
+		case Contains, StartsWith, EndsWith, Matches:
+			spec.Type, spec.Items = ParamString, ParamAny
+		}
+		add(&f.Value, fmt.Sprintf("filter on '%s'", f.Field.Name), spec)
+	case FilterGroup:
+		for _, c := range f.Conditions {
+			filterParamUses(c, add)
+		}
+	case RangeFilter:
+		spec := fieldSpec(f.Field, SourceFilter)
+		add(f.Min, fmt.Sprintf("range minimum on '%s'", f.Field.Name), spec)
+		add(f.Max, fmt.Sprintf("range maximum on '%s'", f.Field.Name), spec)
+	case GeoFilter:
+		site := fmt.Sprintf("geo filter on '%s'", f.Field.Name)
+		spec := ParamSpec{Type: ParamNumber, Source: SourceFilter, Field: f.Field.Name}
+		add(&f.Center.Lat, site, spec)
+		add(&f.Center.Lon, site, spec)
+		add(&f.Radius, site, spec)
+	case ExtensionFilter:
+		for i := range f.Params {
+			add(&f.Params[i], fmt.Sprintf("%s extension filter", f.Provider), ParamSpec{Source: SourceFilter})
+		}
+	}
+}
+
+// fieldSpec describes a parameter holding a value of field.
+func fieldSpec(field MetadataField, source ParamSource) ParamSpec {
+	spec := ParamSpec{Source: source, Field: field.Name}
+	if elem, ok := strings.CutPrefix(field.Type, "[]"); ok {
+		spec.Type = ParamList
+		spec.Items = scalarType(elem)
+	} else {
+		spec.Type = scalarType(field.Type)
+	}
+	return spec
+}
+
+// elementType returns the type of a single value of a field: the element
+// type for array fields and the field type otherwise.
+func elementType(fieldType string) ParamType {
+	return scalarType(strings.TrimPrefix(fieldType, "[]"))
+}
+
+func scalarType(vdmlType string) ParamType {
+	switch vdmlType {
+	case "string":
+		return ParamString
+	case "int":
+		return ParamInteger
+	case "float":
+		return ParamNumber
+	case "bool":
+		return ParamBoolean
+	default:
+		return ParamAny
+	}
+}
+
+func sortedFields(m map[MetadataField]Param) []MetadataField {
+	fields := make([]MetadataField, 0, len(m))
+	for field := range m {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
 }
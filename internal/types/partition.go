@@ -0,0 +1,19 @@
+package types
+
+import "errors"
+
+// PartitionOp is a partition lifecycle operation.
+type PartitionOp string
+
+// Partition operations. Providers without partitions map them to their
+// namespace or tenant equivalent.
+const (
+	PartitionCreate PartitionOp = "CREATE_PARTITION"
+	PartitionDrop   PartitionOp = "DROP_PARTITION"
+	PartitionLoad   PartitionOp = "LOAD_PARTITION"
+)
+
+// ErrImplicitPartition is returned for partition operations the provider
+// performs implicitly, such as creating a Pinecone namespace, which happens
+// on the first write. No call is needed.
+var ErrImplicitPartition = errors.New("provider manages this partition operation implicitly")
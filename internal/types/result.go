@@ -7,4 +7,158 @@ type QueryResult struct {
 
 	// RequiredParams lists all parameter names required for the query.
 	RequiredParams []string
+
+	// PostFilter holds predicates the provider cannot express. They must be
+	// evaluated client-side against the metadata of returned matches.
+	// Nil unless the query was rendered with post-filtering enabled.
+	PostFilter FilterItem
+
+	// PostFilterLimit is the number of matches to keep after post-filtering.
+	// The rendered query over-fetches to compensate for discarded matches.
+	PostFilterLimit int
+
+	// Vectors describes the dense vector parameters of the query so that
+	// Bind can check and transform them. Populated by Builder.Render.
+	Vectors []VectorParam
+
+	// Fields describes the parameters bound to metadata fields so that Bind
+	// can transform them. Populated by Builder.Render.
+	Fields []FieldParam
+
+	// ParamSpecs describes each required parameter. Populated by
+	// Builder.Render; see ParamSpec.
+	ParamSpecs []ParamSpec
+
+	// Transforms lists the bind transforms of each parameter, applied by
+	// Bind in order. Populated by Builder.Render.
+	Transforms map[string][]BindTransform
+
+	// Derived lists the parameters the query adds to the caller's, by name,
+	// with the caller's parameter each one copies. Bind copies the value
+	// before applying bind transforms, so a derived parameter can be
+	// transformed without changing the caller's value elsewhere in the
+	// query. Populated by Builder.Render.
+	Derived map[string]string
+
+	// Features lists the deprecated and experimental mappings the renderer
+	// used, so output changes can be rolled out across versions.
+	Features []FeatureNotice
+
+	// Ignored lists query options the provider cannot honor and the renderer
+	// dropped instead of failing, such as a freshness level.
+	Ignored []string
+
+	// Degradation names the degradation profile the query was rewritten
+	// with, or is empty when none was active.
+	Degradation string
+
+	// Commands holds the command plan for providers driven by a command
+	// protocol, such as Redis. JSON then carries the same plan as an array
+	// of commands for inspection. Bind the arguments with BindCommands.
+	Commands []Command
+
+	// Joined lists the list parameters Bind writes as a single string, by
+	// name, with the separator their elements are joined by. Renderers set
+	// it for providers that take one query string where the AST holds a
+	// list, such as NearText concepts.
+	Joined map[string]string
+
+	// Expressions selects how Bind writes values into placeholders embedded
+	// in expression strings, such as filters.
+	Expressions ExpressionStyle
+
+	// APIVersion names the provider API version the query was rendered for,
+	// such as Pinecone's "2024-07". It is empty for renderers that do not
+	// target a versioned API.
+	APIVersion string
+}
+
+// ExpressionStyle selects how bound values are written into expression
+// strings.
+type ExpressionStyle string
+
+// Expression styles.
+const (
+	// ExpressionJSON writes JSON literals, as Milvus expressions expect.
+	ExpressionJSON ExpressionStyle = ""
+
+	// ExpressionBacktick wraps strings in backticks and writes lists as
+	// [a,b], as Typesense filter_by expects.
+	ExpressionBacktick ExpressionStyle = "backtick"
+
+	// ExpressionEscaped backslash-escapes whitespace and syntax characters
+	// in strings and writes lists as (a, b), as Marqo filters expect.
+	ExpressionEscaped ExpressionStyle = "escaped"
+)
+
+// Stability marks a renderer mapping whose output is expected to change.
+type Stability string
+
+// Stability levels. Deprecated mappings will be removed or replaced;
+// experimental mappings may change without notice.
+const (
+	StabilityDeprecated   Stability = "deprecated"
+	StabilityExperimental Stability = "experimental"
+)
+
+// FeatureNotice records a deprecated or experimental mapping used to render
+// a query.
+type FeatureNotice struct {
+	// Feature names the mapping, as "provider.feature".
+	Feature   string
+	Stability Stability
+
+	// Message explains the change and the replacement, if any.
+	Message string
+}
+
+// ParamSpec describes every required parameter: its inferred type, where the
+// query uses it, and whether it is used more than once. For results not
+// produced by Builder.Render, types and sources are unknown.
+func (r *QueryResult) ParamSpec() []ParamSpec {
+	if r.ParamSpecs != nil {
+		return append([]ParamSpec(nil), r.ParamSpecs...)
+	}
+	specs := make([]ParamSpec, 0, len(r.RequiredParams))
+	seen := make(map[string]bool, len(r.RequiredParams))
+	for _, name := range r.RequiredParams {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		specs = append(specs, ParamSpec{Name: name})
+	}
+	return specs
+}
+
+// VectorParam describes a parameter that binds a dense vector.
+type VectorParam struct {
+	// Param is the parameter name.
+	Param string
+
+	// Embedding is the targeted embedding field; see
+	// VectorAST.TargetEmbedding. It is zero when it is not known.
+	Embedding EmbeddingField
+}
+
+// FieldParam describes a parameter that binds a metadata field value.
+type FieldParam struct {
+	// Param is the parameter name.
+	Param string
+
+	// Field is the metadata field. Its Collection is always set.
+	Field MetadataField
+
+	// List reports whether the parameter holds a list of field values, as
+	// for IN filters, rather than a single field value.
+	List bool
+
+	// Match reports whether the value is compared against stored values,
+	// as for filter values and FetchBy keys, rather than written.
+	Match bool
+
+	// Operator is the comparison applied to a matched value: the filter
+	// operator, the bound operator of a range filter, or EQ for FetchBy
+	// keys. It is empty for written values.
+	Operator FilterOperator
 }
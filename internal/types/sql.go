@@ -0,0 +1,49 @@
+package types
+
+// ArgKind describes how a positional argument of a SQL statement or command
+// is encoded for the driver.
+type ArgKind string
+
+// Argument kinds.
+const (
+	// ArgScalar values are passed to the driver unchanged.
+	ArgScalar ArgKind = "scalar"
+
+	// ArgVector values are dense vectors, passed as "[x,y,...]" text, the
+	// input format of pgvector and sqlite-vec.
+	ArgVector ArgKind = "vector"
+
+	// ArgJSON values are passed as JSON text, e.g. for metadata columns.
+	ArgJSON ArgKind = "json"
+
+	// ArgBlob values are dense vectors, passed as little-endian float32
+	// bytes, the vector format of Redis hashes and query parameters.
+	ArgBlob ArgKind = "blob"
+)
+
+// Arg is a positional argument of a rendered SQL statement or command. It
+// references a parameter by name, or carries a literal value when Param is
+// empty.
+type Arg struct {
+	Param string
+	Value interface{}
+	Kind  ArgKind
+
+	// Transforms lists the bind transforms of Param, applied before the
+	// value is encoded. Builder.RenderSQL and Builder.Render set them.
+	Transforms []BindTransform
+
+	// Source names the caller's parameter Param copies, when the query
+	// derives Param; see QueryResult.Derived. Builder.RenderSQL and
+	// Builder.Render set it.
+	Source string
+
+	// Spec, Vector, and Fields describe Param for the checks BindArgs runs
+	// with bind options: parameter classification, vector model checks and
+	// normalization, and metadata field transforms. Vector is nil unless
+	// Param binds a dense vector. Builder.RenderSQL and Builder.Render set
+	// them.
+	Spec   *ParamSpec
+	Vector *VectorParam
+	Fields []FieldParam
+}
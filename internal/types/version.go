@@ -0,0 +1,112 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// VersionRange is a range of provider versions, such as "1.10" up to but
+// excluding "2.0". Empty bounds are open.
+type VersionRange struct {
+	// Min is the lowest supported version, inclusive.
+	Min string
+
+	// Max is the first unsupported version, exclusive.
+	Max string
+}
+
+// IsZero reports whether the range is open at both ends.
+func (r VersionRange) IsZero() bool {
+	return r.Min == "" && r.Max == ""
+}
+
+// Contains reports whether version lies in the range. Versions compare by
+// their numeric major, minor and patch components; a leading "v" and any
+// pre-release or build suffix are ignored.
+func (r VersionRange) Contains(version string) (bool, error) {
+	if r.Min != "" {
+		c, err := CompareVersions(version, r.Min)
+		if err != nil {
+			return false, err
+		}
+		if c < 0 {
+			return false, nil
+		}
+	}
+	if r.Max != "" {
+		c, err := CompareVersions(version, r.Max)
+		if err != nil {
+			return false, err
+		}
+		if c >= 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// String describes the range, e.g. ">= 1.10, < 2.0".
+func (r VersionRange) String() string {
+	switch {
+	case r.Min != "" && r.Max != "":
+		return ">= " + r.Min + ", < " + r.Max
+	case r.Min != "":
+		return ">= " + r.Min
+	case r.Max != "":
+		return "< " + r.Max
+	default:
+		return "any"
+	}
+}
+
+// CompareVersions compares two versions, returning -1, 0 or 1. Missing
+// components count as zero, so "1.10" equals "1.10.0".
+func CompareVersions(a, b string) (int, error) {
+	pa, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1, nil
+		case pa[i] > pb[i]:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// parseVersion parses the major, minor and patch components of version.
+func parseVersion(version string) ([3]int, error) {
+	var parts [3]int
+	v := strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	fields := strings.Split(v, ".")
+	if v == "" || len(fields) > len(parts) {
+		return parts, fmt.Errorf("invalid version: %q", version)
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, fmt.Errorf("invalid version: %q", version)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
+
+// VersionRenderer is implemented by renderers whose output needs a
+// particular range of provider versions, such as Qdrant's query API.
+type VersionRenderer interface {
+	// SupportedVersions returns the provider versions the renderer's
+	// output works with, as configured.
+	SupportedVersions() VersionRange
+}
@@ -0,0 +1,105 @@
+package vectql
+
+import (
+	"context"
+	"iter"
+	"maps"
+	"slices"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// IterCollections yields the schema's collection references in name order.
+func (v *VECTQL) IterCollections() iter.Seq[types.Collection] {
+	return func(yield func(types.Collection) bool) {
+		for _, name := range slices.Sorted(maps.Keys(v.collections)) {
+			c, _ := v.TryC(name)
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+// IterEmbeddings yields a collection's embedding references in name order.
+// An unknown collection yields nothing.
+func (v *VECTQL) IterEmbeddings(collectionName string) iter.Seq[types.EmbeddingField] {
+	return func(yield func(types.EmbeddingField) bool) {
+		for _, name := range slices.Sorted(maps.Keys(v.embeddings[collectionName])) {
+			e, _ := v.TryE(collectionName, name)
+			if !yield(e) {
+				return
+			}
+		}
+	}
+}
+
+// IterFields yields a collection's metadata field references in name order.
+// An unknown collection yields nothing.
+func (v *VECTQL) IterFields(collectionName string) iter.Seq[types.MetadataField] {
+	return func(yield func(types.MetadataField) bool) {
+		for _, name := range slices.Sorted(maps.Keys(v.metadata[collectionName])) {
+			m, _ := v.TryM(collectionName, name)
+			if !yield(m) {
+				return
+			}
+		}
+	}
+}
+
+// All yields the response's matches in provider order.
+func (r *Response) All() iter.Seq2[int, Match] {
+	return func(yield func(int, Match) bool) {
+		if r == nil {
+			return
+		}
+		for i, m := range r.Matches {
+			if !yield(i, m) {
+				return
+			}
+		}
+	}
+}
+
+// PageFunc prepares the request for the page after token. The zero token
+// requests the first page.
+type PageFunc func(token PageToken) (*Request, error)
+
+// Scroll yields the matches of a paginated read one page at a time. Each page
+// is prepared by page and sent to executor; the response's NextPage token
+// requests the following page until it is zero. An error ends the iteration
+// after being yielded with a zero Match. Breaking out of the loop stops
+// paging, so callers can stop early without reading every page:
+//
+//	for m, err := range vectql.Scroll(ctx, executor, page) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    process(m)
+//	}
+func Scroll(ctx context.Context, executor Executor, page PageFunc) iter.Seq2[Match, error] {
+	return func(yield func(Match, error) bool) {
+		var token PageToken
+		for {
+			req, err := page(token)
+			if err != nil {
+				yield(Match{}, err)
+				return
+			}
+			resp, err := executor.Execute(ctx, req)
+			if err != nil {
+				yield(Match{}, err)
+				return
+			}
+			for _, m := range resp.Matches {
+				if !yield(m, nil) {
+					return
+				}
+			}
+			if resp.NextPage.IsZero() {
+				return
+			}
+			token = resp.NextPage
+		}
+	}
+}
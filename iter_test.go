@@ -0,0 +1,128 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zoobzio/vdml"
+)
+
+func TestIterSchema(t *testing.T) {
+	schema := testSchema()
+	schema.Collections["articles"] = &vdml.Collection{
+		Name:       "articles",
+		Embeddings: []*vdml.Embedding{{Name: "body", Dimensions: 8, Metric: vdml.Cosine}},
+	}
+	v, err := NewFromVDML(schema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var collections []string
+	for c := range v.IterCollections() {
+		collections = append(collections, c.Name)
+	}
+	if len(collections) != 2 || collections[0] != "articles" || collections[1] != "products" {
+		t.Errorf("expected [articles products], got %v", collections)
+	}
+
+	var fields []string
+	for f := range v.IterFields("products") {
+		if f.Collection != "products" || f.Type == "" {
+			t.Errorf("expected a complete field reference, got %+v", f)
+		}
+		fields = append(fields, f.Name)
+	}
+	if len(fields) != 3 || fields[0] != "category" || fields[1] != "location" || fields[2] != "price" {
+		t.Errorf("expected sorted fields, got %v", fields)
+	}
+
+	for e := range v.IterEmbeddings("products") {
+		if e.Name != "description" || e.Dimensions != 384 {
+			t.Errorf("unexpected embedding %+v", e)
+		}
+	}
+
+	for range v.IterFields("missing") {
+		t.Error("expected no fields for an unknown collection")
+	}
+
+	// Breaking early stops the iteration
+	n := 0
+	for range v.IterCollections() {
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("expected one iteration, got %d", n)
+	}
+}
+
+func TestResponseAll(t *testing.T) {
+	resp := &Response{Matches: []Match{{ID: "a"}, {ID: "b"}}}
+	var ids []string
+	for i, m := range resp.All() {
+		if resp.Matches[i].ID != m.ID {
+			t.Errorf("index %d does not match %s", i, m.ID)
+		}
+		ids = append(ids, m.ID)
+	}
+	if len(ids) != 2 {
+		t.Errorf("expected 2 matches, got %v", ids)
+	}
+
+	var nilResp *Response
+	for range nilResp.All() {
+		t.Error("expected no matches from a nil response")
+	}
+}
+
+func TestScroll(t *testing.T) {
+	pages := map[string]*Response{
+		"":   {Matches: []Match{{ID: "a"}, {ID: "b"}}, NextPage: NewPageToken("qdrant", "p2")},
+		"p2": {Matches: []Match{{ID: "c"}}, NextPage: NewPageToken("qdrant", "p3")},
+		"p3": {Matches: []Match{{ID: "d"}}},
+	}
+	executor := ExecutorFunc(func(_ context.Context, req *Request) (*Response, error) {
+		return pages[req.Body], nil
+	})
+	var requested []string
+	page := func(token PageToken) (*Request, error) {
+		requested = append(requested, token.Cursor())
+		return &Request{Body: token.Cursor()}, nil
+	}
+
+	var ids []string
+	for m, err := range Scroll(context.Background(), executor, page) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		ids = append(ids, m.ID)
+	}
+	if len(ids) != 4 || ids[3] != "d" {
+		t.Errorf("expected all four matches, got %v", ids)
+	}
+
+	// Stopping early fetches no further pages
+	requested = nil
+	for m := range Scroll(context.Background(), executor, page) {
+		if m.ID == "b" {
+			break
+		}
+	}
+	if len(requested) != 1 {
+		t.Errorf("expected one page request, got %v", requested)
+	}
+
+	failing := ExecutorFunc(func(context.Context, *Request) (*Response, error) {
+		return nil, errors.New("unavailable")
+	})
+	var gotErr error
+	for _, err := range Scroll(context.Background(), failing, page) {
+		gotErr = err
+	}
+	if gotErr == nil {
+		t.Error("expected the executor error to be yielded")
+	}
+}
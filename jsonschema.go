@@ -0,0 +1,86 @@
+package vectql
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// JSONSchemaDialect is the JSON Schema version emitted by JSONSchemaFor.
+const JSONSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchemaFor returns a JSON Schema describing the parameter object a
+// caller must supply to bind result, built from result.ParamSpec. Every
+// parameter is required and unknown properties are rejected. Parameters
+// whose type cannot be inferred accept any value. The schema is a plain map
+// so it can be marshaled directly or embedded in an OpenAPI document.
+func JSONSchemaFor(result *QueryResult) map[string]interface{} {
+	specs := result.ParamSpec()
+	properties := make(map[string]interface{}, len(specs))
+	required := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		properties[spec.Name] = paramSchema(spec)
+		required = append(required, spec.Name)
+	}
+	return map[string]interface{}{
+		"$schema":              JSONSchemaDialect,
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func paramSchema(spec types.ParamSpec) map[string]interface{} {
+	var schema map[string]interface{}
+	switch {
+	case spec.Source == types.SourceSparseVector:
+		schema = map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"indices": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer", "minimum": 0}},
+				"values":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "number"}},
+			},
+			"required": []string{"indices", "values"},
+		}
+	case spec.Type == types.ParamVector:
+		schema = map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "number"}}
+		if spec.Dimensions > 0 {
+			schema["minItems"] = spec.Dimensions
+			schema["maxItems"] = spec.Dimensions
+		} else {
+			schema["minItems"] = 1
+		}
+	case spec.Type == types.ParamList:
+		schema = map[string]interface{}{"type": "array", "items": scalarSchema(spec.Items)}
+	default:
+		schema = scalarSchema(spec.Type)
+	}
+
+	if spec.Source == types.SourceTopK {
+		schema["minimum"] = 1
+		schema["maximum"] = types.MaxTopK
+	}
+	if description := paramDescription(spec); description != "" {
+		schema["description"] = description
+	}
+	return schema
+}
+
+func scalarSchema(t types.ParamType) map[string]interface{} {
+	if t == types.ParamAny {
+		return map[string]interface{}{}
+	}
+	return map[string]interface{}{"type": string(t)}
+}
+
+func paramDescription(spec types.ParamSpec) string {
+	switch {
+	case spec.Source == "":
+		return ""
+	case spec.Field != "":
+		return fmt.Sprintf("%s on field %s", spec.Source, spec.Field)
+	default:
+		return string(spec.Source)
+	}
+}
@@ -0,0 +1,67 @@
+package vectql
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestJSONSchemaFor(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := Search(v.C("products")).
+		Vector(Vec(v.P("query_vec"))).
+		Embedding(v.E("products", "description")).
+		TopKParam(v.P("limit")).
+		Filter(v.In(v.M("products", "category"), v.P("categories"))).
+		Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := json.Marshal(JSONSchemaFor(result))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if schema["type"] != "object" || schema["additionalProperties"] != false {
+		t.Errorf("expected closed object schema, got %v", schema)
+	}
+	if required := schema["required"]; !reflect.DeepEqual(required, []interface{}{"query_vec", "limit", "categories"}) {
+		t.Errorf("unexpected required list: %v", required)
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	vec := properties["query_vec"].(map[string]interface{})
+	if vec["type"] != "array" || vec["minItems"] != float64(384) || vec["maxItems"] != float64(384) {
+		t.Errorf("expected 384-dimension vector schema, got %v", vec)
+	}
+	limit := properties["limit"].(map[string]interface{})
+	if limit["type"] != "integer" || limit["maximum"] != float64(MaxTopK) {
+		t.Errorf("expected bounded integer topK schema, got %v", limit)
+	}
+	categories := properties["categories"].(map[string]interface{})
+	if !reflect.DeepEqual(categories["items"], map[string]interface{}{"type": "string"}) {
+		t.Errorf("expected list of strings, got %v", categories)
+	}
+	if categories["description"] != "filter on field category" {
+		t.Errorf("unexpected description: %v", categories["description"])
+	}
+}
+
+func TestJSONSchemaFor_UnknownTypes(t *testing.T) {
+	schema := JSONSchemaFor(&types.QueryResult{RequiredParams: []string{"x"}})
+	properties := schema["properties"].(map[string]interface{})
+	if !reflect.DeepEqual(properties["x"], map[string]interface{}{}) {
+		t.Errorf("expected unconstrained schema, got %v", properties["x"])
+	}
+}
@@ -0,0 +1,273 @@
+package vectql
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the histogram upper bounds used when none are
+// configured.
+var DefaultLatencyBuckets = []time.Duration{
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// LatencyOption configures a LatencyTracker.
+type LatencyOption func(*LatencyTracker)
+
+// WithLatencyBuckets sets the histogram upper bounds. Bounds are sorted;
+// durations above the last bound fall in an overflow bucket.
+func WithLatencyBuckets(bounds ...time.Duration) LatencyOption {
+	return func(t *LatencyTracker) {
+		t.bounds = append([]time.Duration(nil), bounds...)
+		sort.Slice(t.bounds, func(i, j int) bool { return t.bounds[i] < t.bounds[j] })
+	}
+}
+
+// WithSlowQueryLog logs requests that take at least threshold to logger.
+// Entries carry the query fingerprint, parameter sizes, and response time,
+// never parameter values. A nil logger uses slog.Default.
+func WithSlowQueryLog(threshold time.Duration, logger *slog.Logger) LatencyOption {
+	return func(t *LatencyTracker) {
+		if logger == nil {
+			logger = slog.Default()
+		}
+		t.slowThreshold = threshold
+		t.logger = logger
+	}
+}
+
+// WithSlowQueryHistory keeps the most recent size requests that take at
+// least threshold, for SlowQueries. Like the slow query log, entries carry
+// parameter sizes, never parameter values.
+func WithSlowQueryHistory(threshold time.Duration, size int) LatencyOption {
+	return func(t *LatencyTracker) {
+		t.historyThreshold = threshold
+		t.historySize = size
+	}
+}
+
+// SlowQuery is a request retained by WithSlowQueryHistory.
+type SlowQuery struct {
+	Provider    string         `json:"provider"`
+	Operation   Operation      `json:"operation"`
+	Collection  string         `json:"collection"`
+	Fingerprint string         `json:"fingerprint"`
+	ParamSizes  map[string]int `json:"param_sizes,omitempty"`
+	Elapsed     time.Duration  `json:"elapsed"`
+	Error       string         `json:"error,omitempty"`
+	At          time.Time      `json:"at"`
+}
+
+// LatencyStats is a latency histogram for one provider and operation.
+type LatencyStats struct {
+	Provider  string
+	Operation Operation
+
+	// Bounds are the bucket upper bounds. Counts has one more entry than
+	// Bounds; the last counts requests slower than every bound.
+	Bounds []time.Duration
+	Counts []int64
+
+	Count  int64
+	Errors int64
+	Total  time.Duration
+	Max    time.Duration
+}
+
+// Mean returns the mean latency, or zero when nothing was recorded.
+func (s LatencyStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+type latencyKey struct {
+	provider  string
+	operation Operation
+}
+
+// LatencyTracker records per-provider, per-operation latency histograms for
+// requests passed through executors it wraps, and optionally logs slow
+// queries. It is safe for concurrent use.
+type LatencyTracker struct {
+	mu            sync.Mutex
+	bounds        []time.Duration
+	stats         map[latencyKey]*LatencyStats
+	slowThreshold time.Duration
+	logger        *slog.Logger
+
+	historyThreshold time.Duration
+	historySize      int
+	history          []SlowQuery
+	historyNext      int
+}
+
+// NewLatencyTracker creates a latency tracker.
+func NewLatencyTracker(opts ...LatencyOption) *LatencyTracker {
+	t := &LatencyTracker{
+		bounds: DefaultLatencyBuckets,
+		stats:  make(map[latencyKey]*LatencyStats),
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Wrap returns an executor that records the latency of every request sent
+// through next.
+func (t *LatencyTracker) Wrap(next Executor) Executor {
+	return ExecutorFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		start := time.Now()
+		resp, err := next.Execute(ctx, req)
+		elapsed := time.Since(start)
+		t.Record(req, elapsed, err)
+		if t.logger != nil && elapsed >= t.slowThreshold {
+			t.logSlow(ctx, req, elapsed, err)
+		}
+		if t.historySize > 0 && elapsed >= t.historyThreshold {
+			t.remember(req, elapsed, err)
+		}
+		return resp, err
+	})
+}
+
+// Record adds one observation for req.
+func (t *LatencyTracker) Record(req *Request, elapsed time.Duration, err error) {
+	key := latencyKey{provider: req.Provider, operation: req.Operation}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.stats[key]
+	if !ok {
+		s = &LatencyStats{
+			Provider:  req.Provider,
+			Operation: req.Operation,
+			Bounds:    t.bounds,
+			Counts:    make([]int64, len(t.bounds)+1),
+		}
+		t.stats[key] = s
+	}
+	bucket := sort.Search(len(t.bounds), func(i int) bool { return elapsed <= t.bounds[i] })
+	s.Counts[bucket]++
+	s.Count++
+	s.Total += elapsed
+	if elapsed > s.Max {
+		s.Max = elapsed
+	}
+	if err != nil {
+		s.Errors++
+	}
+}
+
+// Snapshot returns a copy of the recorded histograms sorted by provider and
+// operation.
+func (t *LatencyTracker) Snapshot() []LatencyStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]LatencyStats, 0, len(t.stats))
+	for _, s := range t.stats {
+		snapshot := *s
+		snapshot.Counts = append([]int64(nil), s.Counts...)
+		out = append(out, snapshot)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Provider != out[j].Provider {
+			return out[i].Provider < out[j].Provider
+		}
+		return out[i].Operation < out[j].Operation
+	})
+	return out
+}
+
+// SlowQueries returns the requests retained by WithSlowQueryHistory, most
+// recent first.
+func (t *LatencyTracker) SlowQueries() []SlowQuery {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]SlowQuery, 0, len(t.history))
+	for i := 1; i <= len(t.history); i++ {
+		out = append(out, t.history[(t.historyNext-i+len(t.history))%len(t.history)])
+	}
+	return out
+}
+
+// Reset discards all recorded observations and retained slow queries.
+func (t *LatencyTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats = make(map[latencyKey]*LatencyStats)
+	t.history = nil
+	t.historyNext = 0
+}
+
+func (t *LatencyTracker) remember(req *Request, elapsed time.Duration, err error) {
+	entry := SlowQuery{
+		Provider:    req.Provider,
+		Operation:   req.Operation,
+		Collection:  req.Collection,
+		Fingerprint: req.Fingerprint,
+		Elapsed:     elapsed,
+		At:          time.Now(),
+	}
+	if len(req.ParamSizes) > 0 {
+		entry.ParamSizes = make(map[string]int, len(req.ParamSizes))
+		for name, size := range req.ParamSizes {
+			entry.ParamSizes[name] = size
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.history) < t.historySize {
+		t.history = append(t.history, entry)
+		t.historyNext = len(t.history) % t.historySize
+		return
+	}
+	t.history[t.historyNext] = entry
+	t.historyNext = (t.historyNext + 1) % t.historySize
+}
+
+func (t *LatencyTracker) logSlow(ctx context.Context, req *Request, elapsed time.Duration, err error) {
+	attrs := []slog.Attr{
+		slog.String("provider", req.Provider),
+		slog.String("operation", string(req.Operation)),
+		slog.String("collection", req.Collection),
+		slog.String("fingerprint", req.Fingerprint),
+		slog.Duration("elapsed", elapsed),
+	}
+	if len(req.ParamSizes) > 0 {
+		names := make([]string, 0, len(req.ParamSizes))
+		for name := range req.ParamSizes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		sizes := make([]slog.Attr, len(names))
+		for i, name := range names {
+			sizes[i] = slog.Int(name, req.ParamSizes[name])
+		}
+		attrs = append(attrs, slog.Any("param_sizes", slog.GroupValue(sizes...)))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	t.logger.LogAttrs(ctx, slog.LevelWarn, "slow vector query", attrs...)
+}
@@ -0,0 +1,103 @@
+package vectql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLatencyTracker_Histogram(t *testing.T) {
+	tracker := NewLatencyTracker(WithLatencyBuckets(10*time.Millisecond, time.Millisecond))
+
+	search := &Request{Provider: "qdrant", Operation: OpSearch}
+	tracker.Record(search, 500*time.Microsecond, nil)
+	tracker.Record(search, 5*time.Millisecond, nil)
+	tracker.Record(search, time.Second, errors.New("timeout"))
+	tracker.Record(&Request{Provider: "pinecone", Operation: OpUpsert}, time.Millisecond, nil)
+
+	stats := tracker.Snapshot()
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 histograms, got %d", len(stats))
+	}
+	if stats[0].Provider != "pinecone" || stats[1].Provider != "qdrant" {
+		t.Errorf("expected histograms sorted by provider, got %s, %s", stats[0].Provider, stats[1].Provider)
+	}
+	s := stats[1]
+	if s.Count != 3 || s.Errors != 1 || s.Max != time.Second {
+		t.Errorf("unexpected totals: count=%d errors=%d max=%v", s.Count, s.Errors, s.Max)
+	}
+	expected := []int64{1, 1, 1}
+	for i, c := range expected {
+		if s.Counts[i] != c {
+			t.Errorf("bucket %d: expected %d, got %d", i, c, s.Counts[i])
+		}
+	}
+}
+
+func TestLatencyTracker_SlowQueryLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	tracker := NewLatencyTracker(WithSlowQueryLog(time.Nanosecond, logger))
+
+	executor := tracker.Wrap(ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+		time.Sleep(time.Millisecond)
+		return &Response{}, nil
+	}))
+	req := &Request{
+		Provider:    "qdrant",
+		Operation:   OpSearch,
+		Fingerprint: "abc123",
+		ParamSizes:  map[string]int{"query_vec": 1536},
+		Body:        `{"vector":[0.1]}`,
+	}
+	if _, err := executor.Execute(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	line := buf.String()
+	for _, want := range []string{"slow vector query", "fingerprint=abc123", "param_sizes.query_vec=1536"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("expected log to contain %q, got %s", want, line)
+		}
+	}
+	if strings.Contains(line, "0.1") {
+		t.Errorf("expected log not to contain parameter values, got %s", line)
+	}
+	if stats := tracker.Snapshot(); len(stats) != 1 || stats[0].Count != 1 {
+		t.Errorf("expected one recorded request, got %+v", stats)
+	}
+}
+
+func TestLatencyTracker_SlowQueryHistory(t *testing.T) {
+	tracker := NewLatencyTracker(WithSlowQueryHistory(0, 2))
+	executor := tracker.Wrap(ExecutorFunc(func(_ context.Context, req *Request) (*Response, error) {
+		if req.Collection == "failing" {
+			return nil, errors.New("timeout")
+		}
+		return &Response{}, nil
+	}))
+	for _, collection := range []string{"first", "second", "failing"} {
+		req := &Request{Provider: "qdrant", Operation: OpSearch, Collection: collection, ParamSizes: map[string]int{"query_vec": 3}}
+		_, _ = executor.Execute(context.Background(), req)
+	}
+
+	slow := tracker.SlowQueries()
+	if len(slow) != 2 {
+		t.Fatalf("expected 2 retained queries, got %d", len(slow))
+	}
+	if slow[0].Collection != "failing" || slow[1].Collection != "second" {
+		t.Errorf("expected most recent first, got %s, %s", slow[0].Collection, slow[1].Collection)
+	}
+	if slow[0].Error != "timeout" || slow[0].ParamSizes["query_vec"] != 3 {
+		t.Errorf("unexpected entry: %+v", slow[0])
+	}
+
+	tracker.Reset()
+	if len(tracker.SlowQueries()) != 0 {
+		t.Error("expected Reset to discard retained queries")
+	}
+}
@@ -0,0 +1,19 @@
+package vectql
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// checkList rejects a listing for renderers that cannot page through a
+// collection, which would otherwise fetch no IDs.
+func checkList(ast *types.VectorAST, renderer Renderer) error {
+	if !ast.Lists() {
+		return nil
+	}
+	if lr, ok := renderer.(types.ListRenderer); ok && lr.SupportsList() {
+		return nil
+	}
+	return fmt.Errorf("renderer %s does not support paginated listings", UpgradeRenderer(renderer).Capabilities().Provider)
+}
@@ -0,0 +1,60 @@
+package vectql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestList(t *testing.T) {
+	v := existsInstance(t)
+	builder := List(v.C("products"), 2).Filter(v.Eq(v.M("products", "category"), v.P("cat")))
+
+	first, err := Prepare(builder, qdrant.New(), map[string]interface{}{"cat": "shoes"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Endpoint.Path != "/collections/products/points/scroll" || !first.Listing {
+		t.Errorf("expected a listing scroll, got %s listing=%t", first.Endpoint.Path, first.Listing)
+	}
+	if strings.Contains(first.Body, "offset") || !strings.Contains(first.Body, `"limit":2`) {
+		t.Errorf("expected a first page of two points, got %s", first.Body)
+	}
+
+	next, err := Prepare(builder.After(NewPageToken("qdrant", "42")), qdrant.New(), map[string]interface{}{"cat": "shoes"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(next.Body, `"offset":42`) {
+		t.Errorf("expected the page to resume after point 42, got %s", next.Body)
+	}
+	if first.Fingerprint != next.Fingerprint {
+		t.Error("expected pages of a listing to share a fingerprint")
+	}
+}
+
+func TestList_Errors(t *testing.T) {
+	v := existsInstance(t)
+
+	_, err := Fetch(v.C("products")).IDs(v.P("id")).After(NewPageToken("qdrant", "42")).Build()
+	if err == nil || !strings.Contains(err.Error(), "After() can only be used with List") {
+		t.Errorf("expected a listing error, got %v", err)
+	}
+
+	_, err = List(v.C("products"), 0).Build()
+	if err == nil || !strings.Contains(err.Error(), "page size must be between 1 and") {
+		t.Errorf("expected a page size error, got %v", err)
+	}
+
+	_, err = List(v.C("products"), 10).Render(pinecone.New())
+	if err == nil || !strings.Contains(err.Error(), "pinecone does not support paginated listings") {
+		t.Errorf("expected an unsupported renderer error, got %v", err)
+	}
+
+	_, err = List(v.C("products"), 10).After(NewPageToken("weaviate", "a")).Render(qdrant.New())
+	if err == nil || !strings.Contains(err.Error(), "issued by 'weaviate'") {
+		t.Errorf("expected a provider error, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+// Package loadgen replays bound queries against an executor at a target rate
+// and reports latency and errors. It is meant for load tests in CI against
+// containerized providers or in staging:
+//
+//	report, err := loadgen.Run(ctx, executor, requests, loadgen.Config{
+//	    QPS:         200,
+//	    Concurrency: 32,
+//	    Duration:    time.Minute,
+//	})
+package loadgen
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/zoobzio/vectql"
+)
+
+// Config controls a load run. The run stops when Duration elapses, Requests
+// have been dispatched, or the context is cancelled, whichever comes first.
+// At least one of Duration and Requests must be set.
+type Config struct {
+	// QPS is the target dispatch rate.
+	QPS float64
+
+	// Concurrency caps the requests in flight. A request due while the cap
+	// is reached is dropped and counted, so the report shows when the target
+	// rate could not be sustained.
+	Concurrency int
+
+	Duration time.Duration
+	Requests int
+}
+
+// Report summarizes a load run.
+type Report struct {
+	Sent      int
+	Succeeded int
+	Failed    int
+	Dropped   int
+
+	Elapsed time.Duration
+
+	// QPS is the achieved rate of completed requests.
+	QPS float64
+
+	// Latency percentiles over completed requests, successful or not.
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+
+	// Errors counts failures by error message.
+	Errors map[string]int
+}
+
+// Run replays requests round-robin against executor.
+func Run(ctx context.Context, executor vectql.Executor, requests []*vectql.Request, cfg Config) (*Report, error) {
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("at least one request is required")
+	}
+	if cfg.QPS <= 0 {
+		return nil, fmt.Errorf("QPS must be positive: %g", cfg.QPS)
+	}
+	if cfg.Concurrency <= 0 {
+		return nil, fmt.Errorf("concurrency must be positive: %d", cfg.Concurrency)
+	}
+	if cfg.Duration <= 0 && cfg.Requests <= 0 {
+		return nil, fmt.Errorf("a duration or request count is required")
+	}
+
+	if cfg.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Duration)
+		defer cancel()
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		latencies []time.Duration
+		report    = &Report{Errors: make(map[string]int)}
+		slots     = make(chan struct{}, cfg.Concurrency)
+	)
+
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / cfg.QPS))
+	defer ticker.Stop()
+
+	start := time.Now()
+	for dispatched := 0; cfg.Requests <= 0 || dispatched < cfg.Requests; dispatched++ {
+		if dispatched > 0 {
+			select {
+			case <-ctx.Done():
+			case <-ticker.C:
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			report.Dropped++
+			continue
+		}
+		report.Sent++
+		req := requests[dispatched%len(requests)]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			began := time.Now()
+			_, err := executor.Execute(ctx, req)
+			elapsed := time.Since(began)
+
+			mu.Lock()
+			defer mu.Unlock()
+			latencies = append(latencies, elapsed)
+			if err != nil {
+				report.Failed++
+				report.Errors[err.Error()]++
+			} else {
+				report.Succeeded++
+			}
+		}()
+	}
+	wg.Wait()
+	report.Elapsed = time.Since(start)
+
+	summarize(report, latencies)
+	return report, nil
+}
+
+func summarize(report *Report, latencies []time.Duration) {
+	if len(latencies) == 0 {
+		return
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	var total time.Duration
+	for _, l := range latencies {
+		total += l
+	}
+	report.Mean = total / time.Duration(len(latencies))
+	report.P50 = percentile(latencies, 0.50)
+	report.P90 = percentile(latencies, 0.90)
+	report.P99 = percentile(latencies, 0.99)
+	report.Max = latencies[len(latencies)-1]
+	if report.Elapsed > 0 {
+		report.QPS = float64(len(latencies)) / report.Elapsed.Seconds()
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p*float64(len(sorted))+0.5) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
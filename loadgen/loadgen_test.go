@@ -0,0 +1,71 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql"
+)
+
+func TestRun(t *testing.T) {
+	var calls atomic.Int32
+	executor := vectql.ExecutorFunc(func(_ context.Context, req *vectql.Request) (*vectql.Response, error) {
+		calls.Add(1)
+		if req.Body == "bad" {
+			return nil, errors.New("rejected")
+		}
+		return &vectql.Response{}, nil
+	})
+	requests := []*vectql.Request{{Body: "good"}, {Body: "bad"}}
+
+	report, err := Run(context.Background(), executor, requests, Config{QPS: 1000, Concurrency: 4, Requests: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Sent+report.Dropped != 10 || int(calls.Load()) != report.Sent {
+		t.Errorf("expected 10 dispatched requests, got sent=%d dropped=%d calls=%d", report.Sent, report.Dropped, calls.Load())
+	}
+	if report.Succeeded+report.Failed != report.Sent {
+		t.Errorf("expected every sent request to complete, got %+v", report)
+	}
+	if report.Failed != report.Errors["rejected"] || report.Failed == 0 {
+		t.Errorf("expected failures counted by message, got %+v", report.Errors)
+	}
+	if report.Max < report.P50 {
+		t.Errorf("expected max >= p50, got %v < %v", report.Max, report.P50)
+	}
+}
+
+func TestRun_DropsWhenSaturated(t *testing.T) {
+	release := make(chan struct{})
+	executor := vectql.ExecutorFunc(func(_ context.Context, _ *vectql.Request) (*vectql.Response, error) {
+		<-release
+		return &vectql.Response{}, nil
+	})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(release)
+	}()
+
+	report, err := Run(context.Background(), executor, []*vectql.Request{{}}, Config{QPS: 1000, Concurrency: 1, Requests: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Sent != 1 || report.Dropped != 4 {
+		t.Errorf("expected 1 sent and 4 dropped, got sent=%d dropped=%d", report.Sent, report.Dropped)
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	executor := vectql.ExecutorFunc(func(_ context.Context, _ *vectql.Request) (*vectql.Response, error) {
+		return &vectql.Response{}, nil
+	})
+	_, err := Run(context.Background(), executor, []*vectql.Request{{}}, Config{QPS: 10, Concurrency: 1})
+	if err == nil || !strings.Contains(err.Error(), "duration or request count") {
+		t.Errorf("expected configuration error, got %v", err)
+	}
+}
@@ -0,0 +1,16 @@
+package vectql
+
+// Match represents a single result returned by a vector database.
+type Match struct {
+	// ID is the provider identifier of the matched vector.
+	ID string
+
+	// Score is the similarity score reported by the provider.
+	Score float64
+
+	// Vector holds the stored vector when it was requested.
+	Vector []float32
+
+	// Metadata holds the stored metadata keyed by field name.
+	Metadata map[string]interface{}
+}
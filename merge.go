@@ -0,0 +1,124 @@
+package vectql
+
+import "sort"
+
+// DefaultRRFConstant is the rank constant k commonly used for reciprocal rank fusion.
+const DefaultRRFConstant = 60
+
+// ScoreOrder reports whether score a ranks ahead of score b.
+type ScoreOrder func(a, b float64) bool
+
+// Score orders. Similarities rank HigherFirst; distances, such as sqlite-vec
+// scores and Euclidean scores on some providers, rank LowerFirst.
+var (
+	HigherFirst ScoreOrder = func(a, b float64) bool { return a > b }
+	LowerFirst  ScoreOrder = func(a, b float64) bool { return a < b }
+)
+
+// MergeMax unions result sets by ID, keeping the highest-scoring occurrence of
+// each match. The merged set is ordered by descending score; ties keep the
+// order in which IDs were first seen. It assumes higher scores are better;
+// merge distances with MergeBy and LowerFirst.
+func MergeMax(sets ...[]Match) []Match {
+	return MergeBy(HigherFirst, sets...)
+}
+
+// MergeBy unions result sets by ID, keeping the best-scoring occurrence of
+// each match under order. The merged set is ordered best first; ties keep
+// the order in which IDs were first seen.
+func MergeBy(order ScoreOrder, sets ...[]Match) []Match {
+	index := make(map[string]int)
+	var merged []Match
+	for _, set := range sets {
+		for _, m := range set {
+			i, seen := index[m.ID]
+			if !seen {
+				index[m.ID] = len(merged)
+				merged = append(merged, m)
+				continue
+			}
+			if order(m.Score, merged[i].Score) {
+				merged[i] = m
+			}
+		}
+	}
+	sortBy(merged, order)
+	return merged
+}
+
+// MergeRRF fuses ranked result sets with reciprocal rank fusion. Each match
+// scores the sum of 1/(k+rank) over the sets it appears in, where rank is its
+// 1-based position. The fused score replaces the provider score, which makes
+// RRF suitable for combining sets whose scores are not comparable (dense and
+// sparse search, or different providers). A non-positive k uses DefaultRRFConstant.
+func MergeRRF(k int, sets ...[]Match) []Match {
+	if k <= 0 {
+		k = DefaultRRFConstant
+	}
+	index := make(map[string]int)
+	var merged []Match
+	for _, set := range sets {
+		seen := make(map[string]bool, len(set))
+		for rank, m := range set {
+			// Only the best rank of a duplicated ID within one set counts.
+			if seen[m.ID] {
+				continue
+			}
+			seen[m.ID] = true
+
+			contribution := 1 / float64(k+rank+1)
+			i, ok := index[m.ID]
+			if !ok {
+				index[m.ID] = len(merged)
+				m.Score = contribution
+				merged = append(merged, m)
+				continue
+			}
+			merged[i].Score += contribution
+		}
+	}
+	sortBy(merged, HigherFirst)
+	return merged
+}
+
+// Interleave merges result sets round-robin, taking the next unseen match from
+// each set in turn. Scores are left untouched. Interleaving preserves each
+// set's internal order and is useful for blending results in A/B comparisons.
+func Interleave(sets ...[]Match) []Match {
+	seen := make(map[string]bool)
+	var merged []Match
+	positions := make([]int, len(sets))
+	for remaining := true; remaining; {
+		remaining = false
+		for s, set := range sets {
+			for positions[s] < len(set) {
+				m := set[positions[s]]
+				positions[s]++
+				if seen[m.ID] {
+					continue
+				}
+				seen[m.ID] = true
+				merged = append(merged, m)
+				break
+			}
+			if positions[s] < len(set) {
+				remaining = true
+			}
+		}
+	}
+	return merged
+}
+
+// TruncateMatches returns at most k matches.
+func TruncateMatches(matches []Match, k int) []Match {
+	if k >= 0 && len(matches) > k {
+		return matches[:k]
+	}
+	return matches
+}
+
+func sortBy(matches []Match, order ScoreOrder) {
+	sort.SliceStable(matches, func(i, j int) bool {
+		return order(matches[i].Score, matches[j].Score)
+	})
+}
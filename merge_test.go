@@ -0,0 +1,112 @@
+package vectql
+
+import (
+	"math"
+	"testing"
+)
+
+func matchIDs(matches []Match) []string {
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.ID
+	}
+	return ids
+}
+
+func assertIDs(t *testing.T, expected []string, matches []Match) {
+	t.Helper()
+	got := matchIDs(matches)
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestMergeMax(t *testing.T) {
+	a := []Match{{ID: "1", Score: 0.9}, {ID: "2", Score: 0.5}}
+	b := []Match{{ID: "2", Score: 0.95, Metadata: map[string]interface{}{"src": "b"}}, {ID: "3", Score: 0.1}}
+
+	merged := MergeMax(a, b)
+
+	assertIDs(t, []string{"2", "1", "3"}, merged)
+	if merged[0].Score != 0.95 {
+		t.Errorf("expected max score 0.95, got %v", merged[0].Score)
+	}
+	if merged[0].Metadata["src"] != "b" {
+		t.Error("expected highest-scoring occurrence to be kept")
+	}
+}
+
+func TestMergeMax_StableTies(t *testing.T) {
+	merged := MergeMax([]Match{{ID: "a", Score: 1}, {ID: "b", Score: 1}}, []Match{{ID: "c", Score: 1}})
+	assertIDs(t, []string{"a", "b", "c"}, merged)
+}
+
+func TestMergeBy_LowerFirst(t *testing.T) {
+	a := []Match{{ID: "1", Score: 0.2}, {ID: "2", Score: 0.6}}
+	b := []Match{{ID: "2", Score: 0.1, Metadata: map[string]interface{}{"src": "b"}}, {ID: "3", Score: 0.9}}
+
+	merged := MergeBy(LowerFirst, a, b)
+
+	assertIDs(t, []string{"2", "1", "3"}, merged)
+	if merged[0].Score != 0.1 || merged[0].Metadata["src"] != "b" {
+		t.Errorf("expected the closest occurrence to be kept, got %+v", merged[0])
+	}
+}
+
+func TestMergeRRF(t *testing.T) {
+	dense := []Match{{ID: "1", Score: 0.99}, {ID: "2", Score: 0.98}, {ID: "3", Score: 0.5}}
+	sparse := []Match{{ID: "3", Score: 12}, {ID: "2", Score: 9}}
+
+	merged := MergeRRF(60, dense, sparse)
+
+	// 3: 1/63 + 1/61, 2: 1/62 + 1/62, 1: 1/61
+	assertIDs(t, []string{"3", "2", "1"}, merged)
+	want := 1.0/63 + 1.0/61
+	if math.Abs(merged[0].Score-want) > 1e-12 {
+		t.Errorf("expected fused score %v, got %v", want, merged[0].Score)
+	}
+}
+
+func TestMergeRRF_DefaultConstant(t *testing.T) {
+	merged := MergeRRF(0, []Match{{ID: "1"}})
+	want := 1.0 / float64(DefaultRRFConstant+1)
+	if merged[0].Score != want {
+		t.Errorf("expected %v, got %v", want, merged[0].Score)
+	}
+}
+
+func TestMergeRRF_DuplicateWithinSet(t *testing.T) {
+	merged := MergeRRF(60, []Match{{ID: "1"}, {ID: "1"}})
+	if len(merged) != 1 || merged[0].Score != 1.0/61 {
+		t.Errorf("expected single match scored by best rank, got %v", merged)
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	a := []Match{{ID: "a1"}, {ID: "shared"}, {ID: "a3"}}
+	b := []Match{{ID: "shared"}, {ID: "b2"}}
+
+	merged := Interleave(a, b)
+
+	assertIDs(t, []string{"a1", "shared", "a3", "b2"}, merged)
+}
+
+func TestInterleave_Empty(t *testing.T) {
+	if merged := Interleave(); len(merged) != 0 {
+		t.Errorf("expected no matches, got %v", merged)
+	}
+	if merged := Interleave(nil, []Match{{ID: "1"}}); len(merged) != 1 {
+		t.Errorf("expected one match, got %v", merged)
+	}
+}
+
+func TestTruncateMatches(t *testing.T) {
+	matches := []Match{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+	assertIDs(t, []string{"1", "2"}, TruncateMatches(matches, 2))
+	assertIDs(t, []string{"1", "2", "3"}, TruncateMatches(matches, 10))
+}
@@ -0,0 +1,58 @@
+package vectql
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// InstanceOption configures NewFromVDML.
+type InstanceOption func(*instanceConfig)
+
+type instanceConfig struct {
+	providers []Renderer
+}
+
+// ForProviders makes NewFromVDML reject schemas with names the providers
+// cannot store, such as reserved words, so incompatibilities surface when
+// the schema is loaded rather than at the first write. Renderers that do not
+// implement NameValidator accept every name.
+func ForProviders(renderers ...Renderer) InstanceOption {
+	return func(c *instanceConfig) {
+		c.providers = append(c.providers, renderers...)
+	}
+}
+
+// SchemaNames returns the names the schema declares, with collections
+// sorted by name and their fields in declaration order.
+func (v *VECTQL) SchemaNames() SchemaNames {
+	var names SchemaNames
+	for _, name := range v.Collections() {
+		coll := CollectionNames{Name: name}
+		for _, emb := range v.collections[name].Embeddings {
+			coll.Embeddings = append(coll.Embeddings, emb.Name)
+		}
+		for _, meta := range v.collections[name].Metadata {
+			coll.Fields = append(coll.Fields, meta.Name)
+		}
+		names.Collections = append(names.Collections, coll)
+	}
+	return names
+}
+
+// CheckNames reports the schema names the provider of r cannot store. It
+// returns nil for renderers that do not implement NameValidator.
+func (v *VECTQL) CheckNames(r Renderer) error {
+	return checkNames(r, v.SchemaNames())
+}
+
+func checkNames(r Renderer, names SchemaNames) error {
+	validator, ok := r.(types.NameValidator)
+	if !ok {
+		return nil
+	}
+	if err := validator.ValidateNames(names); err != nil {
+		return fmt.Errorf("schema is incompatible with %s: %w", UpgradeRenderer(r).Capabilities().Provider, err)
+	}
+	return nil
+}
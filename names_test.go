@@ -0,0 +1,49 @@
+package vectql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vdml"
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/milvus"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestNewFromVDML_ForProviders(t *testing.T) {
+	schema := vdml.NewSchema("shop").
+		AddCollection(vdml.NewCollection("products").
+			AddEmbedding(vdml.NewEmbedding("embedding", 3)).
+			AddMetadata(vdml.NewMetadataField("id", vdml.TypeString)).
+			AddMetadata(vdml.NewMetadataField("category", vdml.TypeString)))
+
+	v, err := NewFromVDML(schema, ForProviders(qdrant.New()))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := SchemaNames{Collections: []CollectionNames{
+		{Name: "products", Embeddings: []string{"embedding"}, Fields: []string{"id", "category"}},
+	}}
+	if got := v.SchemaNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	_, err = NewFromVDML(schema, ForProviders(qdrant.New(), milvus.New()))
+	if err == nil || !strings.Contains(err.Error(), "schema is incompatible with milvus") ||
+		!strings.Contains(err.Error(), "field 'id'") {
+		t.Errorf("expected a milvus naming error, got %v", err)
+	}
+	if err := v.CheckNames(milvus.New()); err == nil {
+		t.Error("expected CheckNames to report the reserved field")
+	}
+}
+
+func TestPrepareFieldIndexes_Names(t *testing.T) {
+	_, err := PrepareFieldIndexes(milvus.New(), types.Collection{Name: "docs"}, []types.MetadataField{
+		{Name: "id", Collection: "docs", Type: "string", Index: types.IndexKeyword},
+	})
+	if err == nil || !strings.Contains(err.Error(), "reserved for the primary key") {
+		t.Errorf("expected a naming error, got %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package vectql
+
+import "github.com/zoobzio/vectql/internal/types"
+
+// NewPageToken creates a page token for a provider cursor.
+func NewPageToken(provider, cursor string) PageToken {
+	return types.NewPageToken(provider, cursor)
+}
+
+// ParsePageToken decodes a token produced by PageToken.String.
+// The empty string decodes to the zero token.
+func ParsePageToken(s string) (PageToken, error) {
+	return types.ParsePageToken(s)
+}
@@ -0,0 +1,65 @@
+package vectql
+
+import "testing"
+
+func TestPageToken_RoundTrip(t *testing.T) {
+	token := NewPageToken("qdrant", "6f1c2a9e-1111-4b3a-9c55-000000000001")
+
+	parsed, err := ParsePageToken(token.String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed != token {
+		t.Errorf("expected %+v, got %+v", token, parsed)
+	}
+	if parsed.Provider() != "qdrant" {
+		t.Errorf("expected provider qdrant, got %s", parsed.Provider())
+	}
+}
+
+func TestPageToken_Zero(t *testing.T) {
+	var token PageToken
+	if !token.IsZero() {
+		t.Error("expected zero token")
+	}
+	if token.String() != "" {
+		t.Errorf("expected empty encoding, got %q", token.String())
+	}
+
+	parsed, err := ParsePageToken("")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !parsed.IsZero() {
+		t.Error("expected empty string to parse as zero token")
+	}
+}
+
+func TestPageToken_CursorFor(t *testing.T) {
+	token := NewPageToken("weaviate", "uuid-1")
+
+	cursor, err := token.CursorFor("weaviate")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cursor != "uuid-1" {
+		t.Errorf("expected uuid-1, got %s", cursor)
+	}
+
+	if _, err := token.CursorFor("qdrant"); err == nil {
+		t.Error("expected error for provider mismatch")
+	}
+}
+
+func TestParsePageToken_Invalid(t *testing.T) {
+	invalid := []string{
+		"not base64!",
+		"bm90IGpzb24",         // "not json"
+		"eyJwIjoicWRyYW50In0", // {"p":"qdrant"} without cursor
+	}
+	for _, s := range invalid {
+		if _, err := ParsePageToken(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package vectql
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// ErrImplicitPartition is returned by PreparePartition when the provider
+// performs the operation on its own, such as creating a Pinecone namespace
+// on first write. Callers can treat it as success.
+var ErrImplicitPartition = types.ErrImplicitPartition
+
+// PartitionRenderer is implemented by renderers that describe partition
+// lifecycle calls. Milvus renders them as partition operations; providers
+// without partitions map them to their namespace or tenant equivalent.
+type PartitionRenderer interface {
+	// RenderPartition describes the call that applies op to partition in
+	// collection.
+	RenderPartition(op PartitionOp, collection, partition string) (Endpoint, string, error)
+}
+
+// PreparePartition renders a partition operation into a Request for an
+// Executor. It fails when the renderer does not manage partitions, and
+// returns ErrImplicitPartition when no call is needed.
+func PreparePartition(r Renderer, op PartitionOp, collection types.Collection, partition string) (*Request, error) {
+	pr, ok := r.(PartitionRenderer)
+	if !ok {
+		return nil, fmt.Errorf("renderer %s does not manage partitions", UpgradeRenderer(r).Capabilities().Provider)
+	}
+	endpoint, body, err := pr.RenderPartition(op, collection.Name, partition)
+	if err != nil {
+		return nil, err
+	}
+	return &Request{
+		Provider:   UpgradeRenderer(r).Capabilities().Provider,
+		Collection: collection.Name,
+		Namespace:  partition,
+		Endpoint:   endpoint,
+		Body:       body,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package vectql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/milvus"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+)
+
+func TestPreparePartition(t *testing.T) {
+	r := milvus.New()
+	r.Mode = milvus.ModeRESTv2
+
+	req, err := PreparePartition(r, PartitionCreate, types.Collection{Name: "docs"}, "tenant_a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Provider != "milvus" || req.Collection != "docs" || req.Namespace != "tenant_a" {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if req.Endpoint.Path != "/v2/vectordb/partitions/create" {
+		t.Errorf("unexpected endpoint: %+v", req.Endpoint)
+	}
+	if req.Body != `{"collectionName":"docs","partitionName":"tenant_a"}` {
+		t.Errorf("unexpected body: %s", req.Body)
+	}
+}
+
+func TestPreparePartition_Unsupported(t *testing.T) {
+	_, err := PreparePartition(pinecone.New(), PartitionCreate, types.Collection{Name: "docs"}, "tenant_a")
+	if !errors.Is(err, ErrImplicitPartition) {
+		t.Errorf("expected ErrImplicitPartition, got %v", err)
+	}
+
+	if _, err := PreparePartition(newStubRenderer(), PartitionDrop, types.Collection{Name: "docs"}, "a"); err == nil {
+		t.Error("expected an error for a renderer without partitions")
+	}
+}
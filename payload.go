@@ -0,0 +1,77 @@
+package vectql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// ErrPayloadTooLarge is returned when a request body exceeds the provider's
+// size limit and cannot be split further.
+var ErrPayloadTooLarge = errors.New("request payload exceeds provider limit")
+
+// payloadLimits holds the default maximum request body size, in bytes, of the
+// built-in providers. Weaviate does not enforce one by default.
+var payloadLimits = map[string]int{
+	"pinecone": 2 << 20,
+	"qdrant":   32 << 20,
+	"milvus":   64 << 20,
+}
+
+// PayloadLimit returns the default maximum request body size in bytes for a
+// provider, or 0 when the provider has no known limit.
+func PayloadLimit(provider string) int {
+	return payloadLimits[provider]
+}
+
+// EstimatePayloadSize returns the size in bytes of the body result produces
+// once params are bound.
+func EstimatePayloadSize(result *types.QueryResult, params map[string]interface{}, opts ...BindOption) (int, error) {
+	body, err := Bind(result, params, opts...)
+	if err != nil {
+		return 0, err
+	}
+	return len(body), nil
+}
+
+// PrepareChunked prepares a query like Prepare, splitting an UPSERT into
+// several requests when one body would exceed maxBytes. A maxBytes of 0 uses
+// the provider's PayloadLimit; if that is also 0 no limit applies. Batches are
+// halved until every request fits, so records keep their order across the
+// returned requests. Other operations, and single records, that exceed the
+// limit return ErrPayloadTooLarge.
+func PrepareChunked(b *Builder, r Renderer, params map[string]interface{}, maxBytes int, opts ...BindOption) ([]*Request, error) {
+	req, err := Prepare(b, r, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes == 0 {
+		maxBytes = PayloadLimit(req.Provider)
+	}
+	if maxBytes <= 0 || len(req.Body) <= maxBytes {
+		return []*Request{req}, nil
+	}
+
+	if req.Operation != OpUpsert || len(b.ast.Vectors) < 2 {
+		return nil, fmt.Errorf("%w: %s body is %d bytes, limit is %d", ErrPayloadTooLarge, req.Operation, len(req.Body), maxBytes)
+	}
+
+	mid := len(b.ast.Vectors) / 2
+	head, err := PrepareChunked(b.withVectors(b.ast.Vectors[:mid]), r, params, maxBytes, opts...)
+	if err != nil {
+		return nil, err
+	}
+	tail, err := PrepareChunked(b.withVectors(b.ast.Vectors[mid:]), r, params, maxBytes, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return append(head, tail...), nil
+}
+
+// withVectors returns a copy of b whose UPSERT carries only records.
+func (b *Builder) withVectors(records []types.VectorRecord) *Builder {
+	ast := *b.ast
+	ast.Vectors = records
+	return b.withAST(&ast)
+}
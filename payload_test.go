@@ -0,0 +1,131 @@
+package vectql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+)
+
+// upsertBatch builds an UPSERT of n records with vectors of dims components.
+func upsertBatch(n, dims int) (*Builder, map[string]interface{}) {
+	builder := Upsert(types.Collection{Name: "products"})
+	params := make(map[string]interface{})
+	for i := 0; i < n; i++ {
+		id, vec := fmt.Sprintf("id_%d", i), fmt.Sprintf("vec_%d", i)
+		builder = builder.AddVector(NewRecord(types.Param{Name: id}, Vec(types.Param{Name: vec})).Build())
+		params[id] = id
+		params[vec] = make([]float32, dims)
+	}
+	return builder, params
+}
+
+func TestEstimatePayloadSize(t *testing.T) {
+	builder, params := upsertBatch(2, 4)
+	result, err := builder.Render(pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	size, err := EstimatePayloadSize(result, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := Bind(result, params)
+	if size != len(body) {
+		t.Errorf("expected size %d, got %d", len(body), size)
+	}
+
+	if _, err := EstimatePayloadSize(result, map[string]interface{}{}); err == nil {
+		t.Error("expected error for missing parameters")
+	}
+}
+
+func TestPayloadLimit(t *testing.T) {
+	if PayloadLimit("pinecone") != 2<<20 {
+		t.Errorf("expected 2MB pinecone limit, got %d", PayloadLimit("pinecone"))
+	}
+	if PayloadLimit("qdrant") != 32<<20 {
+		t.Errorf("expected 32MB qdrant limit, got %d", PayloadLimit("qdrant"))
+	}
+	if PayloadLimit("weaviate") != 0 {
+		t.Errorf("expected no weaviate limit, got %d", PayloadLimit("weaviate"))
+	}
+}
+
+func TestPrepareChunked_FitsInOne(t *testing.T) {
+	builder, params := upsertBatch(5, 8)
+
+	reqs, err := PrepareChunked(builder, pinecone.New(), params, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reqs) != 1 {
+		t.Fatalf("expected 1 request, got %d", len(reqs))
+	}
+}
+
+func TestPrepareChunked_SplitsUpsert(t *testing.T) {
+	builder, params := upsertBatch(5, 64)
+
+	single, _ := upsertBatch(1, 64)
+	one, err := Prepare(single, pinecone.New(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	limit := 2*len(one.Body) + 16
+
+	reqs, err := PrepareChunked(builder, pinecone.New(), params, limit)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reqs) < 3 {
+		t.Fatalf("expected at least 3 requests, got %d", len(reqs))
+	}
+
+	next := 0
+	for _, req := range reqs {
+		if len(req.Body) > limit {
+			t.Errorf("request body %d bytes exceeds limit %d", len(req.Body), limit)
+		}
+		// Records keep their order across chunks
+		for i := next; i < 5; i++ {
+			if !strings.Contains(req.Body, fmt.Sprintf(`"id_%d"`, i)) {
+				break
+			}
+			next = i + 1
+		}
+	}
+	if next != 5 {
+		t.Errorf("expected all 5 records in order across chunks, stopped at %d", next)
+	}
+
+	// The original builder is unchanged
+	ast, err := builder.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ast.Vectors) != 5 {
+		t.Errorf("expected original builder to keep 5 records, got %d", len(ast.Vectors))
+	}
+}
+
+func TestPrepareChunked_TooLarge(t *testing.T) {
+	builder, params := upsertBatch(1, 64)
+
+	_, err := PrepareChunked(builder, pinecone.New(), params, 32)
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("expected ErrPayloadTooLarge, got %v", err)
+	}
+
+	search := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(3)
+	_, err = PrepareChunked(search, pinecone.New(), map[string]interface{}{"query_vec": make([]float32, 64)}, 32)
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("expected ErrPayloadTooLarge for SEARCH, got %v", err)
+	}
+}
@@ -0,0 +1,585 @@
+// Package elasticsearch provides a VECTQL renderer for Elasticsearch 8.x
+// dense_vector indices.
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/filtertree"
+	"github.com/zoobzio/vectql/internal/querydsl"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// fallbackVectorField is the dense_vector field used when neither the query
+// nor the schema names one.
+const fallbackVectorField = "embedding"
+
+// DefaultCandidateFactor is the number of candidates per requested result
+// each shard considers when the renderer does not set one.
+const DefaultCandidateFactor = 10
+
+// maxNumCandidates is the largest num_candidates Elasticsearch accepts.
+const maxNumCandidates = 10000
+
+// ScriptScore is the scoring expression kind Elasticsearch accepts. Its
+// payload is a Painless script source; see vectql.Scoring.
+const ScriptScore = "script_score"
+
+// toResult serializes a query to JSON and returns a QueryResult.
+func toResult(query interface{}, params []string) (*types.QueryResult, error) {
+	jsonBytes, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize query: %w", err)
+	}
+	return &types.QueryResult{
+		JSON:           string(jsonBytes),
+		RequiredParams: params,
+	}, nil
+}
+
+// Renderer renders VectorAST to Elasticsearch request bodies. Searches use
+// the top-level knn section of the search API; writes use the bulk API,
+// whose bodies render as a JSON array of lines (see types.Endpoint.Lines).
+// Metadata fields are stored as top-level document fields next to the
+// dense_vector field.
+type Renderer struct {
+	// CandidateFactor sets num_candidates to CandidateFactor x k, capped at
+	// 10000. Zero uses DefaultCandidateFactor.
+	CandidateFactor int
+
+	// Refresh is sent as the refresh parameter of writes: "true" or
+	// "wait_for" make them visible to the next search. Empty leaves the
+	// index refresh interval in charge.
+	Refresh string
+
+	// FieldNames maps schema field names to the index's mapped fields,
+	// e.g. a Prefix of "attributes." for metadata kept in an object field.
+	FieldNames types.FieldNames
+}
+
+// PhysicalFieldNames returns the configured field mapping.
+func (r *Renderer) PhysicalFieldNames() types.FieldNames {
+	return r.FieldNames
+}
+
+// SupportedVersions reports that output targets Elasticsearch 8, whose
+// top-level knn search section the renderer uses.
+func (r *Renderer) SupportedVersions() types.VersionRange {
+	return types.VersionRange{Min: "8.0"}
+}
+
+// SupportsFetchBy reports that FETCH by key renders as a terms search on
+// the key field.
+func (r *Renderer) SupportsFetchBy() bool {
+	return true
+}
+
+// SupportsExistsByFilter reports that existence checks by filter render as
+// a search stopping at the first hit.
+func (r *Renderer) SupportsExistsByFilter() bool {
+	return true
+}
+
+// SupportsList reports that listings render as a search sorted on
+// _shard_doc that resumes from the previous page with search_after.
+func (r *Renderer) SupportsList() bool {
+	return true
+}
+
+// New creates a new Elasticsearch renderer.
+func New() *Renderer {
+	return &Renderer{}
+}
+
+// vectorField returns the dense_vector field a query targets.
+func vectorField(ast *types.VectorAST) string {
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
+		return ast.QueryEmbedding.Name
+	}
+	if ast.Target.DefaultEmbedding != "" {
+		return ast.Target.DefaultEmbedding
+	}
+	return fallbackVectorField
+}
+
+// Render converts a VectorAST to an Elasticsearch request body.
+func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	if err := ast.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid AST: %w", err)
+	}
+
+	var params []string
+
+	switch ast.Operation {
+	case types.OpSearch:
+		return r.renderSearch(ast, &params)
+	case types.OpUpsert:
+		return r.renderUpsert(ast, &params)
+	case types.OpDelete:
+		return r.renderDelete(ast, &params)
+	case types.OpFetch:
+		return r.renderFetch(ast, &params)
+	case types.OpUpdate:
+		return r.renderUpdate(ast, &params)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
+
+func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("elasticsearch does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("elasticsearch", ScriptScore); err != nil {
+		return nil, err
+	}
+	// Elasticsearch configures quantization and rescoring on the index mapping
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("elasticsearch does not support quantization search parameters")
+	}
+
+	field := vectorField(ast)
+	knn := map[string]interface{}{"field": field}
+
+	// Vector
+	if ast.QueryVector.Param != nil {
+		*params = append(*params, ast.QueryVector.Param.Name)
+		knn["query_vector"] = fmt.Sprintf(":%s", ast.QueryVector.Param.Name)
+	} else {
+		knn["query_vector"] = ast.QueryVector.Literal
+	}
+
+	// k and num_candidates; a parameterized k leaves num_candidates to the
+	// server default
+	query := map[string]interface{}{"knn": knn}
+	if ast.TopK.Static != nil {
+		k := *ast.TopK.Static
+		knn["k"] = k
+		selectivity := 1.0
+		if ast.FilterClause != nil {
+			if s, ok := types.Selectivity(ast.FilterClause); ok {
+				selectivity = s
+			}
+		}
+		knn["num_candidates"] = r.numCandidates(k, selectivity)
+		query["size"] = k
+	} else {
+		*params = append(*params, ast.TopK.Param.Name)
+		k := fmt.Sprintf(":%s", ast.TopK.Param.Name)
+		knn["k"] = k
+		query["size"] = k
+	}
+
+	// Score threshold
+	if ast.MinScore != nil {
+		*params = append(*params, ast.MinScore.Name)
+		knn["similarity"] = fmt.Sprintf(":%s", ast.MinScore.Name)
+	}
+
+	// Filter, applied during the kNN search rather than after it
+	if ast.FilterClause != nil {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		knn["filter"] = filter
+	}
+
+	// Boosts and scoring scripts rescore the kNN hits, so the search moves
+	// into a knn query wrapped in function_score and then script_score
+	if len(ast.Boosts) > 0 || ast.Scoring != nil {
+		scored := map[string]interface{}{"knn": knn}
+		if len(ast.Boosts) > 0 {
+			var err error
+			if scored, err = r.renderBoosts(scored, ast.Boosts, params); err != nil {
+				return nil, err
+			}
+		}
+		if ast.Scoring != nil {
+			var err error
+			if scored, err = renderScriptScore(scored, ast.Scoring, params); err != nil {
+				return nil, err
+			}
+		}
+		delete(query, "knn")
+		query["query"] = scored
+	}
+
+	if source := querydsl.Source(ast, field); source != nil {
+		query["_source"] = source
+	}
+	if ast.Snapshot != nil {
+		query["pit"] = querydsl.PIT(ast.Snapshot, params)
+	}
+
+	result, err := toResult(query, *params)
+	if err != nil {
+		return nil, err
+	}
+	// Searches are near real-time; freshness is controlled on writes with Refresh
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	return result, nil
+}
+
+// renderBoosts wraps a query in a function_score query. Filter boosts add
+// their weight to matching hits and field boosts add the field's value times
+// the weight; the sum is added to the similarity score. The knn query needs
+// Elasticsearch 8.12 or later.
+func (r *Renderer) renderBoosts(inner map[string]interface{}, boosts []types.Boost, params *[]string) (map[string]interface{}, error) {
+	functions := make([]interface{}, 0, len(boosts))
+	for _, boost := range boosts {
+		if boost.Field != nil {
+			functions = append(functions, map[string]interface{}{
+				"field_value_factor": map[string]interface{}{
+					"field":   boost.Field.Name,
+					"factor":  boost.Weight,
+					"missing": 0,
+				},
+			})
+			continue
+		}
+		filter, err := r.renderFilter(boost.Filter, params)
+		if err != nil {
+			return nil, err
+		}
+		functions = append(functions, map[string]interface{}{"filter": filter, "weight": boost.Weight})
+	}
+	return map[string]interface{}{
+		"function_score": map[string]interface{}{
+			"query":      inner,
+			"functions":  functions,
+			"score_mode": "sum",
+			"boost_mode": "sum",
+		},
+	}, nil
+}
+
+// renderScriptScore wraps a query in a script_score query. The payload is a
+// Painless script source that reads the inner score as _score and each
+// parameter as params.<name>.
+func renderScriptScore(inner map[string]interface{}, expr *types.ScoreExpression, params *[]string) (map[string]interface{}, error) {
+	source, ok := expr.Payload.(string)
+	if !ok || source == "" {
+		return nil, fmt.Errorf("elasticsearch %s expression requires a script source string", expr.Kind)
+	}
+	script := map[string]interface{}{"source": source}
+	if len(expr.Params) > 0 {
+		scriptParams := make(map[string]interface{}, len(expr.Params))
+		for _, p := range expr.Params {
+			*params = append(*params, p.Name)
+			scriptParams[p.Name] = fmt.Sprintf(":%s", p.Name)
+		}
+		script["params"] = scriptParams
+	}
+	return map[string]interface{}{
+		"script_score": map[string]interface{}{
+			"query":  inner,
+			"script": script,
+		},
+	}, nil
+}
+
+// numCandidates returns the per-shard candidate count for k results. A
+// filter expected to match a fraction selectivity of the documents widens
+// the count by 1/selectivity, since HNSW visits that many more candidates
+// per match.
+func (r *Renderer) numCandidates(k int, selectivity float64) int {
+	factor := r.CandidateFactor
+	if factor <= 0 {
+		factor = DefaultCandidateFactor
+	}
+	n := float64(k * factor)
+	if selectivity > 0 && selectivity < 1 {
+		n = math.Ceil(n / selectivity)
+	}
+	if n < maxNumCandidates {
+		return int(n)
+	}
+	if k > maxNumCandidates {
+		return k
+	}
+	return maxNumCandidates
+}
+
+func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	field := vectorField(ast)
+	lines := make([]interface{}, 0, 2*len(ast.Vectors))
+
+	for _, record := range ast.Vectors {
+		if record.SparseVector != nil {
+			return nil, fmt.Errorf("elasticsearch does not support indexed sparse vectors")
+		}
+
+		*params = append(*params, record.ID.Name)
+		action := map[string]interface{}{"_id": fmt.Sprintf(":%s", record.ID.Name)}
+
+		doc := make(map[string]interface{}, len(record.Metadata)+1)
+		if record.Vector.Param != nil {
+			*params = append(*params, record.Vector.Param.Name)
+			doc[field] = fmt.Sprintf(":%s", record.Vector.Param.Name)
+		} else {
+			doc[field] = record.Vector.Literal
+		}
+		for f, value := range record.Metadata {
+			*params = append(*params, value.Name)
+			doc[f.Name] = fmt.Sprintf(":%s", value.Name)
+		}
+
+		lines = append(lines, map[string]interface{}{"index": action}, doc)
+	}
+
+	return toResult(lines, *params)
+}
+
+func (r *Renderer) renderDelete(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	// Deletes by filter go to the delete-by-query API
+	if len(ast.IDs) == 0 {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		return toResult(map[string]interface{}{"query": querydsl.Filter(filter)}, *params)
+	}
+
+	lines := make([]interface{}, len(ast.IDs))
+	for i, id := range ast.IDs {
+		*params = append(*params, id.Name)
+		lines[i] = map[string]interface{}{
+			"delete": map[string]interface{}{"_id": fmt.Sprintf(":%s", id.Name)},
+		}
+	}
+	return toResult(lines, *params)
+}
+
+func (r *Renderer) renderFetch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	ids := make([]string, len(ast.IDs))
+	for i, id := range ast.IDs {
+		*params = append(*params, id.Name)
+		ids[i] = fmt.Sprintf(":%s", id.Name)
+	}
+
+	// _mget cannot read a point in time, so pinned fetches search by ID.
+	// Fetches by key search on the key field, collapsed to one hit per key
+	// so records sharing a key cannot crowd out other keys, and existence
+	// checks by filter stop at the first hit
+	body := map[string]interface{}{"ids": ids}
+	if ast.Lists() {
+		var err error
+		if body, err = r.renderList(ast, params); err != nil {
+			return nil, err
+		}
+	} else if ast.ExistsByFilter() {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		body = map[string]interface{}{
+			"query":           querydsl.Filter(filter),
+			"size":            1,
+			"_source":         false,
+			"terminate_after": 1,
+		}
+		if ast.Snapshot != nil {
+			body["pit"] = querydsl.PIT(ast.Snapshot, params)
+		}
+	} else if key := ast.FetchKey; key != nil {
+		keys := make([]string, len(key.Keys))
+		for i, k := range key.Keys {
+			*params = append(*params, k.Name)
+			keys[i] = fmt.Sprintf(":%s", k.Name)
+		}
+		body = map[string]interface{}{
+			"query":    map[string]interface{}{"terms": map[string]interface{}{key.Field.Name: keys}},
+			"collapse": map[string]interface{}{"field": key.Field.Name},
+			"size":     len(keys),
+		}
+		if ast.Snapshot != nil {
+			body["pit"] = querydsl.PIT(ast.Snapshot, params)
+		}
+	} else if ast.Snapshot != nil {
+		body = map[string]interface{}{
+			"query": map[string]interface{}{"ids": map[string]interface{}{"values": ids}},
+			"size":  len(ids),
+			"pit":   querydsl.PIT(ast.Snapshot, params),
+		}
+	}
+
+	result, err := toResult(body, *params)
+	if err != nil {
+		return nil, err
+	}
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	return result, nil
+}
+
+// renderList renders a listing as a search of a point in time sorted on
+// _shard_doc, the cheapest stable order, resuming after the sort values of
+// the previous page's last hit. Without a point in time the order is not
+// stable across pages, so listings require a snapshot.
+func (r *Renderer) renderList(ast *types.VectorAST, params *[]string) (map[string]interface{}, error) {
+	if ast.Snapshot == nil {
+		return nil, fmt.Errorf("elasticsearch lists records only within a snapshot")
+	}
+	query := map[string]interface{}{"match_all": map[string]interface{}{}}
+	if ast.FilterClause != nil {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		query = querydsl.Filter(filter)
+	}
+	body := map[string]interface{}{
+		"query": query,
+		"size":  ast.Page.Size,
+		"sort":  []interface{}{map[string]interface{}{"_shard_doc": "asc"}},
+		"pit":   querydsl.PIT(ast.Snapshot, params),
+	}
+	if source := querydsl.Source(ast, vectorField(ast)); source != nil {
+		body["_source"] = source
+	}
+	cursor, err := ast.Page.Cursor("elasticsearch")
+	if err != nil {
+		return nil, err
+	}
+	if cursor != "" {
+		// Sort values are longs; decoding them as json.Number keeps them exact
+		dec := json.NewDecoder(strings.NewReader(cursor))
+		dec.UseNumber()
+		var after []interface{}
+		if err := dec.Decode(&after); err != nil {
+			return nil, fmt.Errorf("invalid elasticsearch page cursor: %w", err)
+		}
+		body["search_after"] = after
+	}
+	return body, nil
+}
+
+func (r *Renderer) renderUpdate(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	lines := make([]interface{}, 0, 2*len(ast.IDs))
+	for _, id := range ast.IDs {
+		*params = append(*params, id.Name)
+		action := map[string]interface{}{"_id": fmt.Sprintf(":%s", id.Name)}
+
+		doc := make(map[string]interface{}, len(ast.Updates))
+		for field, value := range ast.Updates {
+			*params = append(*params, value.Name)
+			doc[field.Name] = fmt.Sprintf(":%s", value.Name)
+		}
+
+		lines = append(lines, map[string]interface{}{"update": action}, map[string]interface{}{"doc": doc})
+	}
+
+	return toResult(lines, *params)
+}
+
+func (r *Renderer) renderFilter(f types.FilterItem, params *[]string) (interface{}, error) {
+	return filtertree.New(querydsl.Dialect(r.SupportsFilter), params).Compile(f)
+}
+
+// SupportsOperation indicates if Elasticsearch supports an operation.
+func (r *Renderer) SupportsOperation(op types.Operation) bool {
+	switch op {
+	case types.OpSearch, types.OpUpsert, types.OpDelete, types.OpFetch, types.OpUpdate:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFilter indicates if Elasticsearch supports a filter operator.
+func (r *Renderer) SupportsFilter(op types.FilterOperator) bool {
+	return slices.Contains(querydsl.Operators, op)
+}
+
+// SupportsMetric indicates if Elasticsearch supports a distance metric.
+func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
+	switch metric {
+	case types.Cosine, types.Euclidean, types.DotProduct:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsModality indicates if Elasticsearch accepts a search input
+// modality. Only vector input is rendered.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	return m == types.ModalityVector
+}
+
+// SupportsSnapshots reports that searches and fetches can be pinned to a
+// point in time opened with the Elasticsearch PIT API.
+func (r *Renderer) SupportsSnapshots() bool {
+	return true
+}
+
+// SupportsContentEncoding indicates if Elasticsearch accepts request bodies with a
+// Content-Encoding. Its HTTP layer decompresses gzip and deflate payloads.
+func (r *Renderer) SupportsContentEncoding(encoding string) bool {
+	return encoding == "gzip" || encoding == "deflate"
+}
+
+// Capabilities describes the features supported by Elasticsearch.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("elasticsearch", r)
+}
+
+// RenderTo writes the Elasticsearch request body for ast to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// Format reports that Elasticsearch queries are JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// Endpoint returns the Elasticsearch REST call for ast. Upserts, updates,
+// and deletes by ID use the index's bulk API and take newline-delimited
+// JSON.
+func (r *Renderer) Endpoint(ast *types.VectorAST) (types.Endpoint, error) {
+	index := "/" + url.PathEscape(ast.Target.Name)
+	switch ast.Operation {
+	case types.OpSearch:
+		return types.Endpoint{Method: "POST", Path: querydsl.SearchPath(ast, index)}, nil
+	case types.OpUpsert, types.OpUpdate:
+		return types.Endpoint{Method: "POST", Path: index + "/_bulk" + r.refresh(), Lines: true}, nil
+	case types.OpDelete:
+		if len(ast.IDs) == 0 {
+			return types.Endpoint{Method: "POST", Path: index + "/_delete_by_query" + r.refresh()}, nil
+		}
+		return types.Endpoint{Method: "POST", Path: index + "/_bulk" + r.refresh(), Lines: true}, nil
+	case types.OpFetch:
+		if ast.Snapshot != nil || ast.FetchKey != nil || ast.ExistsByFilter() || ast.Lists() {
+			return types.Endpoint{Method: "POST", Path: querydsl.SearchPath(ast, index)}, nil
+		}
+		return types.Endpoint{Method: "POST", Path: index + "/_mget"}, nil
+	default:
+		return types.Endpoint{}, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
+
+// refresh returns the refresh query string for writes.
+func (r *Renderer) refresh() string {
+	if r.Refresh == "" {
+		return ""
+	}
+	return "?refresh=" + url.QueryEscape(r.Refresh)
+}
@@ -0,0 +1,504 @@
+package elasticsearch
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderSearch(t *testing.T) {
+	renderer := New()
+
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:       types.OpSearch,
+		Target:          types.Collection{Name: "products"},
+		QueryVector:     &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:            &types.PaginationValue{Static: &topK},
+		IncludeMetadata: true,
+	}
+
+	result, err := renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"_source":{"excludes":["embedding"]},"knn":{"field":"embedding","k":10,"num_candidates":100,"query_vector":":query_vec"},"size":10}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if len(result.RequiredParams) != 1 || result.RequiredParams[0] != "query_vec" {
+		t.Errorf("expected RequiredParams=[query_vec], got %v", result.RequiredParams)
+	}
+}
+
+func TestRenderSearchWithFilter(t *testing.T) {
+	topK := 10
+	min := types.Param{Name: "min_price"}
+	ast := &types.VectorAST{
+		Operation:       types.OpSearch,
+		Target:          types.Collection{Name: "products"},
+		QueryVector:     &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:            &types.PaginationValue{Static: &topK},
+		IncludeMetadata: true,
+		MinScore:        &types.Param{Name: "min_score"},
+	}
+	ast.FilterClause = types.FilterGroup{
+		Logic: types.AND,
+		Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "category"}, Operator: types.EQ, Value: types.Param{Name: "cat"}},
+			types.FilterCondition{Field: types.MetadataField{Name: "brand"}, Operator: types.NotIn, Value: types.Param{Name: "brands"}},
+			types.RangeFilter{Field: types.MetadataField{Name: "price"}, Min: &min},
+			types.FilterGroup{
+				Logic: types.OR,
+				Conditions: []types.FilterItem{
+					types.FilterCondition{Field: types.MetadataField{Name: "stock"}, Operator: types.GT, Value: types.Param{Name: "stock"}},
+					types.FilterCondition{Field: types.MetadataField{Name: "sku"}, Operator: types.StartsWith, Value: types.Param{Name: "prefix"}},
+				},
+			},
+		},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"_source":{"excludes":["embedding"]},"knn":{"field":"embedding","filter":{"bool":{"filter":[` +
+		`{"term":{"category":":cat"}},` +
+		`{"bool":{"must_not":[{"terms":{"brand":":brands"}}]}},` +
+		`{"range":{"price":{"gte":":min_price"}}},` +
+		`{"bool":{"minimum_should_match":1,"should":[{"range":{"stock":{"gt":":stock"}}},{"prefix":{"sku":":prefix"}}]}}` +
+		`]}},"k":10,"num_candidates":100,"query_vector":":query_vec","similarity":":min_score"},"size":10}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+}
+
+func TestRenderSearchBoosts(t *testing.T) {
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:       types.OpSearch,
+		Target:          types.Collection{Name: "products"},
+		QueryVector:     &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:            &types.PaginationValue{Static: &topK},
+		IncludeMetadata: true,
+		Boosts: []types.Boost{
+			{Filter: types.FilterCondition{Field: types.MetadataField{Name: "in_stock"}, Operator: types.EQ, Value: types.Param{Name: "in_stock"}}, Weight: 2},
+			{Field: &types.MetadataField{Name: "popularity", Type: "float"}, Weight: 0.5},
+		},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"_source":{"excludes":["embedding"]},"query":{"function_score":{"boost_mode":"sum","functions":[` +
+		`{"filter":{"term":{"in_stock":":in_stock"}},"weight":2},` +
+		`{"field_value_factor":{"factor":0.5,"field":"popularity","missing":0}}],` +
+		`"query":{"knn":{"field":"embedding","k":10,"num_candidates":100,"query_vector":":query_vec"}},"score_mode":"sum"}},"size":10}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if len(result.RequiredParams) != 2 || result.RequiredParams[1] != "in_stock" {
+		t.Errorf("expected RequiredParams=[query_vec in_stock], got %v", result.RequiredParams)
+	}
+	if len(result.Ignored) != 0 {
+		t.Errorf("expected no ignored options, got %v", result.Ignored)
+	}
+}
+
+func TestRenderSearchScriptScore(t *testing.T) {
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:       types.OpSearch,
+		Target:          types.Collection{Name: "products"},
+		QueryVector:     &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:            &types.PaginationValue{Static: &topK},
+		IncludeMetadata: true,
+		Boosts:          []types.Boost{{Field: &types.MetadataField{Name: "popularity", Type: "float"}, Weight: 1}},
+		Scoring: &types.ScoreExpression{
+			Provider: "elasticsearch",
+			Kind:     ScriptScore,
+			Payload:  "_score * params.factor",
+			Params:   []types.Param{{Name: "factor"}},
+		},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"_source":{"excludes":["embedding"]},"query":{"script_score":{"query":{"function_score":{"boost_mode":"sum","functions":[` +
+		`{"field_value_factor":{"factor":1,"field":"popularity","missing":0}}],` +
+		`"query":{"knn":{"field":"embedding","k":10,"num_candidates":100,"query_vector":":query_vec"}},"score_mode":"sum"}},` +
+		`"script":{"params":{"factor":":factor"},"source":"_score * params.factor"}}},"size":10}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if len(result.RequiredParams) != 2 || result.RequiredParams[1] != "factor" {
+		t.Errorf("expected RequiredParams=[query_vec factor], got %v", result.RequiredParams)
+	}
+
+	ast.Scoring = &types.ScoreExpression{Provider: "elasticsearch", Kind: "rank_feature", Payload: "x"}
+	if _, err := New().Render(ast); err == nil {
+		t.Error("expected error for a kind that is not allow-listed")
+	}
+	ast.Scoring = &types.ScoreExpression{Provider: "elasticsearch", Kind: ScriptScore, Payload: 1}
+	if _, err := New().Render(ast); err == nil {
+		t.Error("expected error for a non-string script")
+	}
+	ast.Scoring = &types.ScoreExpression{Provider: "vespa", Kind: "rank_expression", Payload: "x"}
+	if _, err := New().Render(ast); err == nil {
+		t.Error("expected error for another provider's expression")
+	}
+}
+
+func TestRenderSearchSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata bool
+		vectors  bool
+		fields   []types.MetadataField
+		expected string
+	}{
+		{"nothing", false, false, nil, `false`},
+		{"vectors", false, true, nil, `["embedding"]`},
+		{"fields", true, false, []types.MetadataField{{Name: "title"}}, `["title"]`},
+		{"fields and vectors", true, true, []types.MetadataField{{Name: "title"}}, `["title","embedding"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topK := 10
+			ast := &types.VectorAST{
+				Operation:       types.OpSearch,
+				Target:          types.Collection{Name: "products"},
+				QueryVector:     &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+				TopK:            &types.PaginationValue{Static: &topK},
+				IncludeMetadata: tt.metadata,
+				IncludeVectors:  tt.vectors,
+				MetadataFields:  tt.fields,
+			}
+			result, err := New().Render(ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := `{"_source":` + tt.expected + `,"knn":{"field":"embedding","k":10,"num_candidates":100,"query_vector":":query_vec"},"size":10}`
+			if result.JSON != expected {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+			}
+		})
+	}
+}
+
+func TestRenderSnapshot(t *testing.T) {
+	snapshot := &types.Snapshot{ID: types.Param{Name: "pit"}, KeepAlive: time.Minute}
+
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:       types.OpSearch,
+		Target:          types.Collection{Name: "products"},
+		QueryVector:     &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:            &types.PaginationValue{Static: &topK},
+		IncludeMetadata: true,
+		Snapshot:        snapshot,
+	}
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"_source":{"excludes":["embedding"]},"knn":{"field":"embedding","k":10,"num_candidates":100,"query_vector":":query_vec"},` +
+		`"pit":{"id":":pit","keep_alive":"60000ms"},"size":10}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if len(result.RequiredParams) != 2 || result.RequiredParams[1] != "pit" {
+		t.Errorf("expected RequiredParams=[query_vec pit], got %v", result.RequiredParams)
+	}
+
+	fetch := &types.VectorAST{
+		Operation: types.OpFetch,
+		Target:    types.Collection{Name: "products"},
+		IDs:       []types.Param{{Name: "id1"}, {Name: "id2"}},
+		Snapshot:  snapshot,
+	}
+	result, err = New().Render(fetch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = `{"pit":{"id":":pit","keep_alive":"60000ms"},"query":{"ids":{"values":[":id1",":id2"]}},"size":2}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	endpoint, err := New().Endpoint(fetch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Path != "/_search" {
+		t.Errorf("expected pinned fetch to search, got %+v", endpoint)
+	}
+}
+
+func TestNumCandidates(t *testing.T) {
+	r := &Renderer{CandidateFactor: 4}
+	if n := r.numCandidates(10, 1); n != 40 {
+		t.Errorf("expected 40, got %d", n)
+	}
+	if n := r.numCandidates(5000, 1); n != 10000 {
+		t.Errorf("expected the 10000 cap, got %d", n)
+	}
+	if n := r.numCandidates(10, 0.1); n != 400 {
+		t.Errorf("expected a selective filter to widen to 400, got %d", n)
+	}
+	if n := r.numCandidates(10, 1e-300); n != 10000 {
+		t.Errorf("expected the 10000 cap, got %d", n)
+	}
+}
+
+func TestRenderUpsert(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation: types.OpUpsert,
+		Target:    types.Collection{Name: "products", DefaultEmbedding: "vec"},
+		Vectors: []types.VectorRecord{{
+			ID:       types.Param{Name: "id"},
+			Vector:   types.VectorValue{Param: &types.Param{Name: "v"}},
+			Metadata: map[types.MetadataField]types.Param{{Name: "title"}: {Name: "title"}},
+		}},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `[{"index":{"_id":":id"}},{"title":":title","vec":":v"}]`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	ast.Vectors[0].SparseVector = &types.SparseVectorValue{Param: &types.Param{Name: "s"}}
+	if _, err := New().Render(ast); err == nil {
+		t.Error("expected an error for sparse vectors")
+	}
+}
+
+func TestRenderDeleteAndUpdate(t *testing.T) {
+	del := &types.VectorAST{
+		Operation: types.OpDelete,
+		Target:    types.Collection{Name: "products"},
+		IDs:       []types.Param{{Name: "a"}, {Name: "b"}},
+	}
+	result, err := New().Render(del)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `[{"delete":{"_id":":a"}},{"delete":{"_id":":b"}}]`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	byFilter := &types.VectorAST{
+		Operation:    types.OpDelete,
+		Target:       types.Collection{Name: "products"},
+		FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "tags"}, Operator: types.ArrayContains, Value: types.Param{Name: "tag"}},
+		DeleteAll:    true,
+	}
+	result, err = New().Render(byFilter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"query":{"bool":{"filter":[{"term":{"tags":":tag"}}]}}}`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	update := &types.VectorAST{
+		Operation: types.OpUpdate,
+		Target:    types.Collection{Name: "products"},
+		IDs:       []types.Param{{Name: "id"}},
+		Updates:   map[types.MetadataField]types.Param{{Name: "price"}: {Name: "price"}},
+	}
+	result, err = New().Render(update)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `[{"update":{"_id":":id"}},{"doc":{"price":":price"}}]`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+}
+
+func TestRenderSearchUnsupported(t *testing.T) {
+	topK := 10
+	vector := &types.VectorValue{Param: &types.Param{Name: "query_vec"}}
+
+	tests := []struct {
+		name string
+		ast  *types.VectorAST
+		want string
+	}{
+		{
+			// Term-level queries have no regular expression operator
+			name: "regex filter",
+			ast: &types.VectorAST{
+				Operation:    types.OpSearch,
+				Target:       types.Collection{Name: "products"},
+				QueryVector:  vector,
+				TopK:         &types.PaginationValue{Static: &topK},
+				FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "title"}, Operator: types.Matches, Value: types.Param{Name: "re"}},
+			},
+			want: "unsupported filter operator",
+		},
+		{
+			// Quantization and rescoring are configured on the index mapping
+			name: "quantization",
+			ast: &types.VectorAST{
+				Operation:    types.OpSearch,
+				Target:       types.Collection{Name: "products"},
+				QueryVector:  vector,
+				TopK:         &types.PaginationValue{Static: &topK},
+				Quantization: &types.QuantizationParams{Rescore: true},
+			},
+			want: "quantization",
+		},
+		{
+			// kNN search needs a vector; there is no server-side vectorizer
+			name: "text input",
+			ast: &types.VectorAST{
+				Operation: types.OpSearch,
+				Target:    types.Collection{Name: "products"},
+				NearText:  &types.NearText{Concepts: types.Param{Name: "concepts"}},
+				TopK:      &types.PaginationValue{Static: &topK},
+			},
+			want: "search input",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New().Render(tt.ast)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestRenderFetchBy(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation: types.OpFetch,
+		Target:    types.Collection{Name: "products"},
+		FetchKey: &types.FetchKey{
+			Field: types.MetadataField{Name: "sku", Type: "string", Unique: true},
+			Keys:  []types.Param{{Name: "k1"}, {Name: "k2"}},
+		},
+	}
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"collapse":{"field":"sku"},"query":{"terms":{"sku":[":k1",":k2"]}},"size":2}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+	endpoint, err := New().Endpoint(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Method != "POST" || endpoint.Path != "/products/_search" {
+		t.Errorf("unexpected endpoint: %s %s", endpoint.Method, endpoint.Path)
+	}
+}
+
+func TestRenderExists(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation: types.OpFetch,
+		Target:    types.Collection{Name: "products"},
+		Exists:    true,
+		FilterClause: types.FilterCondition{
+			Field:    types.MetadataField{Name: "sku"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "sku"},
+		},
+	}
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"_source":false,"query":{"bool":{"filter":[{"term":{"sku":":sku"}}]}},"size":1,"terminate_after":1}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+	endpoint, err := New().Endpoint(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Path != "/products/_search" {
+		t.Errorf("unexpected endpoint: %s %s", endpoint.Method, endpoint.Path)
+	}
+}
+
+func TestRenderList(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation:       types.OpFetch,
+		Target:          types.Collection{Name: "products"},
+		Page:            &types.Page{Size: 100, After: types.NewPageToken("elasticsearch", `[12884901890]`)},
+		Snapshot:        &types.Snapshot{ID: types.Param{Name: "pit"}},
+		IncludeMetadata: true,
+	}
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"_source":{"excludes":["embedding"]},"pit":{"id":":pit"},"query":{"match_all":{}},` +
+		`"search_after":[12884901890],"size":100,"sort":[{"_shard_doc":"asc"}]}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+	endpoint, err := New().Endpoint(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Path != "/_search" {
+		t.Errorf("unexpected endpoint: %s %s", endpoint.Method, endpoint.Path)
+	}
+
+	// _shard_doc order is only stable within a point in time
+	ast.Snapshot = nil
+	if _, err := New().Render(ast); err == nil || !strings.Contains(err.Error(), "only within a snapshot") {
+		t.Errorf("expected a snapshot error, got %v", err)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	r := &Renderer{Refresh: "wait_for"}
+
+	tests := []struct {
+		ast   *types.VectorAST
+		path  string
+		lines bool
+	}{
+		{&types.VectorAST{Operation: types.OpSearch, Target: types.Collection{Name: "products"}}, "/products/_search", false},
+		{&types.VectorAST{Operation: types.OpUpsert, Target: types.Collection{Name: "products"}}, "/products/_bulk?refresh=wait_for", true},
+		{&types.VectorAST{Operation: types.OpDelete, Target: types.Collection{Name: "products"}, IDs: []types.Param{{Name: "id"}}}, "/products/_bulk?refresh=wait_for", true},
+		{&types.VectorAST{Operation: types.OpDelete, Target: types.Collection{Name: "products"}}, "/products/_delete_by_query?refresh=wait_for", false},
+		{&types.VectorAST{Operation: types.OpFetch, Target: types.Collection{Name: "products"}}, "/products/_mget", false},
+	}
+	for _, tt := range tests {
+		endpoint, err := r.Endpoint(tt.ast)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if endpoint.Method != "POST" || endpoint.Path != tt.path || endpoint.Lines != tt.lines {
+			t.Errorf("%s: unexpected endpoint %+v", tt.ast.Operation, endpoint)
+		}
+	}
+}
+
+func TestSupportsContentEncoding(t *testing.T) {
+	caps := New().Capabilities()
+	for encoding, want := range map[string]bool{"gzip": true, "deflate": true, "br": false, "zstd": false} {
+		if got := caps.SupportsContentEncoding(encoding); got != want {
+			t.Errorf("%s: expected %v, got %v", encoding, want, got)
+		}
+	}
+}
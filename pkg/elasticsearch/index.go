@@ -0,0 +1,57 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// indexFieldTypes maps index kinds to Elasticsearch field types. Arrays map
+// to the element type.
+var indexFieldTypes = map[types.IndexKind]string{
+	types.IndexKeyword: "keyword",
+	types.IndexText:    "text",
+	types.IndexInteger: "long",
+	types.IndexFloat:   "double",
+	types.IndexBool:    "boolean",
+	types.IndexGeo:     "geo_point",
+}
+
+// RenderFieldIndex renders a mapping update that adds the field. Text fields
+// use the language analyzer of their declared language, or the cjk analyzer
+// for Chinese, Japanese, and Korean. Elasticsearch cannot change the type or
+// analyzer of a mapped field, so the call fails for fields that exist.
+func (r *Renderer) RenderFieldIndex(collection string, field types.MetadataField) (types.Endpoint, string, error) {
+	if collection == "" || field.Name == "" {
+		return types.Endpoint{}, "", fmt.Errorf("collection and field names are required")
+	}
+	fieldType, ok := indexFieldTypes[field.Index]
+	if !ok {
+		return types.Endpoint{}, "", fmt.Errorf("unsupported index kind: %q", field.Index)
+	}
+
+	mapping := map[string]interface{}{"type": fieldType}
+	if field.Index == types.IndexText && field.Language != "" {
+		mapping["analyzer"] = textAnalyzer(field.Language)
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"properties": map[string]interface{}{field.Name: mapping},
+	})
+	if err != nil {
+		return types.Endpoint{}, "", fmt.Errorf("failed to serialize mapping: %w", err)
+	}
+	return types.Endpoint{Method: "PUT", Path: "/" + url.PathEscape(collection) + "/_mapping"}, string(data), nil
+}
+
+// textAnalyzer returns the built-in analyzer for a language.
+func textAnalyzer(language string) string {
+	switch language {
+	case "ja", "ko", "zh":
+		return "cjk"
+	default:
+		return types.Languages[language]
+	}
+}
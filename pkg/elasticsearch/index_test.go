@@ -0,0 +1,41 @@
+package elasticsearch
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderFieldIndex(t *testing.T) {
+	tests := []struct {
+		field types.MetadataField
+		body  string
+	}{
+		{types.MetadataField{Name: "tags", Type: "[]string", Index: types.IndexKeyword},
+			`{"properties":{"tags":{"type":"keyword"}}}`},
+		{types.MetadataField{Name: "body", Type: "string", Index: types.IndexText},
+			`{"properties":{"body":{"type":"text"}}}`},
+		{types.MetadataField{Name: "body_de", Type: "string", Index: types.IndexText, Language: "de"},
+			`{"properties":{"body_de":{"analyzer":"german","type":"text"}}}`},
+		{types.MetadataField{Name: "body_ja", Type: "string", Index: types.IndexText, Language: "ja"},
+			`{"properties":{"body_ja":{"analyzer":"cjk","type":"text"}}}`},
+		{types.MetadataField{Name: "location", Index: types.IndexGeo},
+			`{"properties":{"location":{"type":"geo_point"}}}`},
+	}
+	for _, tt := range tests {
+		endpoint, body, err := New().RenderFieldIndex("products", tt.field)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.field.Name, err)
+		}
+		if endpoint.Method != "PUT" || endpoint.Path != "/products/_mapping" {
+			t.Errorf("%s: unexpected endpoint: %+v", tt.field.Name, endpoint)
+		}
+		if body != tt.body {
+			t.Errorf("%s: expected %s, got %s", tt.field.Name, tt.body, body)
+		}
+	}
+
+	if _, _, err := New().RenderFieldIndex("products", types.MetadataField{Name: "title"}); err == nil {
+		t.Error("expected error without an index kind")
+	}
+}
@@ -0,0 +1,380 @@
+// Package marqo provides a VECTQL renderer for Marqo.
+package marqo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/filterexpr"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// fallbackVectorField is the custom vector field used when neither the
+// query nor the collection names an embedding.
+const fallbackVectorField = "embedding"
+
+// conceptSeparator joins the concepts of a text search into the q string.
+const conceptSeparator = ", "
+
+// toResult serializes a request body to JSON and returns a QueryResult.
+// Filters are expression strings whose values Marqo reads with backslash
+// escapes.
+func toResult(body interface{}, params []string) (*types.QueryResult, error) {
+	// Keep "<" and ">" of filters readable
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(body); err != nil {
+		return nil, fmt.Errorf("failed to serialize query: %w", err)
+	}
+	return &types.QueryResult{
+		JSON:           strings.TrimSuffix(buf.String(), "\n"),
+		RequiredParams: params,
+		Expressions:    types.ExpressionEscaped,
+	}, nil
+}
+
+// Renderer renders VectorAST to Marqo REST bodies. Collections are indexes.
+// Vector searches pass the query vector as search context; text and image
+// searches pass it as q, for Marqo to vectorize. Records are documents
+// whose vector is a custom_vector field, next to top-level metadata fields.
+type Renderer struct{}
+
+// New creates a new Marqo renderer.
+func New() *Renderer {
+	return &Renderer{}
+}
+
+// vectorField returns the tensor field a query or record targets.
+func vectorField(ast *types.VectorAST) string {
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
+		return ast.QueryEmbedding.Name
+	}
+	if ast.Target.DefaultEmbedding != "" {
+		return ast.Target.DefaultEmbedding
+	}
+	return fallbackVectorField
+}
+
+// Render converts a VectorAST to a Marqo request body.
+func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	if err := ast.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid AST: %w", err)
+	}
+	// Marqo has no namespaces; tenants are separate indexes or filters
+	if ast.Namespace != nil {
+		return nil, fmt.Errorf("marqo does not support namespaces")
+	}
+
+	var params []string
+
+	switch ast.Operation {
+	case types.OpSearch:
+		return r.renderSearch(ast, &params)
+	case types.OpUpsert:
+		return r.renderUpsert(ast, &params)
+	case types.OpDelete:
+		return r.renderDelete(ast, &params)
+	case types.OpFetch:
+		return r.renderFetch(ast, &params)
+	case types.OpUpdate:
+		return r.renderUpdate(ast, &params)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
+
+func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("marqo does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("marqo"); err != nil {
+		return nil, err
+	}
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("marqo does not support quantization search parameters")
+	}
+	if ast.MinScore != nil {
+		return nil, fmt.Errorf("marqo does not support score thresholds")
+	}
+
+	body := map[string]interface{}{"searchMethod": "TENSOR"}
+	var joined map[string]string
+	switch ast.Modality() {
+	case types.ModalityText:
+		// q is one string; the concepts list binds joined into it
+		*params = append(*params, ast.NearText.Concepts.Name)
+		body["q"] = fmt.Sprintf(":%s", ast.NearText.Concepts.Name)
+		joined = map[string]string{ast.NearText.Concepts.Name: conceptSeparator}
+	case types.ModalityImage:
+		*params = append(*params, ast.NearImage.Image.Name)
+		body["q"] = fmt.Sprintf(":%s", ast.NearImage.Image.Name)
+	default:
+		var vector interface{}
+		if ast.QueryVector.Param != nil {
+			*params = append(*params, ast.QueryVector.Param.Name)
+			vector = fmt.Sprintf(":%s", ast.QueryVector.Param.Name)
+		} else {
+			vector = ast.QueryVector.Literal
+		}
+		body["context"] = map[string]interface{}{
+			"tensor": []interface{}{map[string]interface{}{"vector": vector, "weight": 1}},
+		}
+	}
+
+	if ast.TopK.Static != nil {
+		body["limit"] = *ast.TopK.Static
+	} else {
+		*params = append(*params, ast.TopK.Param.Name)
+		body["limit"] = fmt.Sprintf(":%s", ast.TopK.Param.Name)
+	}
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
+		body["searchableAttributes"] = []string{ast.QueryEmbedding.Name}
+	}
+	if ast.FilterClause != nil {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		body["filter"] = filter
+	}
+	switch {
+	case !ast.IncludeMetadata:
+		body["attributesToRetrieve"] = []string{"_id"}
+	case len(ast.MetadataFields) > 0:
+		attributes := []string{"_id"}
+		for _, f := range ast.MetadataFields {
+			attributes = append(attributes, f.Name)
+		}
+		body["attributesToRetrieve"] = attributes
+	}
+
+	result, err := toResult(body, *params)
+	if err != nil {
+		return nil, err
+	}
+	result.Joined = joined
+	// Search hits carry highlights rather than vectors, and writes are
+	// searchable once acknowledged
+	if ast.IncludeVectors {
+		result.Ignored = append(result.Ignored, "include_vectors")
+	}
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	// Tensor search scores have no adjustment
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	field := vectorField(ast)
+	docs := make([]interface{}, len(ast.Vectors))
+	for i, record := range ast.Vectors {
+		if record.SparseVector != nil {
+			return nil, fmt.Errorf("marqo does not support sparse vectors")
+		}
+
+		*params = append(*params, record.ID.Name)
+		doc := map[string]interface{}{"_id": fmt.Sprintf(":%s", record.ID.Name)}
+		if record.Vector.Param != nil {
+			*params = append(*params, record.Vector.Param.Name)
+			doc[field] = map[string]interface{}{"vector": fmt.Sprintf(":%s", record.Vector.Param.Name)}
+		} else {
+			doc[field] = map[string]interface{}{"vector": record.Vector.Literal}
+		}
+		for f, value := range record.Metadata {
+			*params = append(*params, value.Name)
+			doc[f.Name] = fmt.Sprintf(":%s", value.Name)
+		}
+		docs[i] = doc
+	}
+
+	return toResult(map[string]interface{}{
+		"documents":    docs,
+		"tensorFields": []string{field},
+		"mappings":     map[string]interface{}{field: map[string]string{"type": "custom_vector"}},
+	}, *params)
+}
+
+// renderUpdate renders a partial update. Marqo only updates documents of
+// structured indexes in place, and never their tensor fields.
+func (r *Renderer) renderUpdate(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	docs := make([]interface{}, len(ast.IDs))
+	for i, id := range ast.IDs {
+		*params = append(*params, id.Name)
+		doc := map[string]interface{}{"_id": fmt.Sprintf(":%s", id.Name)}
+		for f, value := range ast.Updates {
+			*params = append(*params, value.Name)
+			doc[f.Name] = fmt.Sprintf(":%s", value.Name)
+		}
+		docs[i] = doc
+	}
+	return toResult(map[string]interface{}{"documents": docs}, *params)
+}
+
+func (r *Renderer) renderDelete(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if len(ast.IDs) == 0 {
+		return nil, fmt.Errorf("marqo only deletes documents by ID")
+	}
+	return toResult(ids(ast, params), *params)
+}
+
+func (r *Renderer) renderFetch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	result, err := toResult(ids(ast, params), *params)
+	if err != nil {
+		return nil, err
+	}
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	return result, nil
+}
+
+func ids(ast *types.VectorAST, params *[]string) []string {
+	ids := make([]string, len(ast.IDs))
+	for i, id := range ast.IDs {
+		*params = append(*params, id.Name)
+		ids[i] = fmt.Sprintf(":%s", id.Name)
+	}
+	return ids
+}
+
+func (r *Renderer) renderFilter(f types.FilterItem, params *[]string) (string, error) {
+	return filterexpr.New(dialect(), params).Compile(f)
+}
+
+// dialect describes the Marqo filter string language. Equality is
+// field:(value), ranges are inclusive [min TO max] intervals, and IN takes
+// a bound list, written as (a, b).
+func dialect() *filterexpr.Dialect {
+	return &filterexpr.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			spelled, ok := operators[op]
+			return spelled, ok
+		},
+		Comparison: func(field, op, value string) string {
+			switch op {
+			case "!=":
+				return fmt.Sprintf("(NOT %s:(%s))", field, value)
+			case ">=":
+				return fmt.Sprintf("%s:[%s TO *]", field, value)
+			case "<=":
+				return fmt.Sprintf("%s:[* TO %s]", field, value)
+			case "IN":
+				return fmt.Sprintf("%s IN %s", field, value)
+			case "NOT IN":
+				return fmt.Sprintf("(NOT %s IN %s)", field, value)
+			default:
+				return fmt.Sprintf("%s:(%s)", field, value)
+			}
+		},
+		And:                "AND",
+		Or:                 "OR",
+		Not:                "NOT",
+		ParenthesizeGroups: true,
+	}
+}
+
+// operators spells the filter operators Marqo supports. Range bounds are
+// always inclusive, so strict comparisons are not supported; array fields
+// match when any element does.
+var operators = map[types.FilterOperator]string{
+	types.EQ:            "=",
+	types.NE:            "!=",
+	types.GE:            ">=",
+	types.LE:            "<=",
+	types.IN:            "IN",
+	types.NotIn:         "NOT IN",
+	types.ArrayContains: "=",
+}
+
+// SupportsOperation indicates if Marqo supports an operation.
+func (r *Renderer) SupportsOperation(op types.Operation) bool {
+	switch op {
+	case types.OpSearch, types.OpUpsert, types.OpDelete, types.OpFetch, types.OpUpdate:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFilter indicates if Marqo filter strings express a filter
+// operator.
+func (r *Renderer) SupportsFilter(op types.FilterOperator) bool {
+	_, ok := operators[op]
+	return ok
+}
+
+// SupportsMetric indicates if Marqo supports a distance metric. Indexes use
+// angular distance by default and accept euclidean and dot product.
+func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
+	switch metric {
+	case types.Cosine, types.Euclidean, types.DotProduct:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsModality indicates if Marqo accepts a search input modality.
+// Marqo vectorizes text and image queries with the index's model.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	switch m {
+	case types.ModalityVector, types.ModalityText, types.ModalityImage:
+		return true
+	default:
+		return false
+	}
+}
+
+// Capabilities describes the features supported by Marqo.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("marqo", r)
+}
+
+// RenderTo writes the Marqo request body for ast to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// Format reports that Marqo queries are JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// Endpoint returns the Marqo REST call for ast. Fetches that include
+// vectors ask Marqo to expose each document's tensor facets.
+func (r *Renderer) Endpoint(ast *types.VectorAST) (types.Endpoint, error) {
+	index := "/indexes/" + url.PathEscape(ast.Target.Name)
+	switch ast.Operation {
+	case types.OpSearch:
+		return types.Endpoint{Method: "POST", Path: index + "/search"}, nil
+	case types.OpUpsert:
+		return types.Endpoint{Method: "POST", Path: index + "/documents"}, nil
+	case types.OpUpdate:
+		return types.Endpoint{Method: "PATCH", Path: index + "/documents"}, nil
+	case types.OpDelete:
+		return types.Endpoint{Method: "POST", Path: index + "/documents/delete-batch"}, nil
+	case types.OpFetch:
+		endpoint := types.Endpoint{Method: "GET", Path: index + "/documents"}
+		if ast.IncludeVectors {
+			endpoint.Query = "expose_facets=true"
+		}
+		return endpoint, nil
+	default:
+		return types.Endpoint{}, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
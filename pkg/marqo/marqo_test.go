@@ -0,0 +1,217 @@
+package marqo
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderSearch(t *testing.T) {
+	topK := 10
+	min, max := types.Param{Name: "min_price"}, types.Param{Name: "max_price"}
+	ast := &types.VectorAST{
+		Operation:      types.OpSearch,
+		Target:         types.Collection{Name: "products"},
+		QueryVector:    &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		QueryEmbedding: &types.EmbeddingField{Name: "image_vec"},
+		TopK:           &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.EQ, Value: types.Param{Name: "color"}},
+			types.FilterGroup{Logic: types.OR, Conditions: []types.FilterItem{
+				types.FilterCondition{Field: types.MetadataField{Name: "brand"}, Operator: types.IN, Value: types.Param{Name: "brands"}},
+				types.FilterCondition{Field: types.MetadataField{Name: "tag"}, Operator: types.NE, Value: types.Param{Name: "tag"}},
+			}},
+			types.RangeFilter{Field: types.MetadataField{Name: "price"}, Min: &min, Max: &max},
+		}},
+		IncludeMetadata: true,
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"context":{"tensor":[{"vector":":query_vec","weight":1}]},` +
+		`"filter":"(color:(:color) AND (brand IN :brands OR (NOT tag:(:tag))) AND (price:[:min_price TO *] AND price:[* TO :max_price]))",` +
+		`"limit":10,"searchMethod":"TENSOR","searchableAttributes":["image_vec"]}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if result.Expressions != types.ExpressionEscaped {
+		t.Errorf("expected escaped expressions, got %q", result.Expressions)
+	}
+	if len(result.RequiredParams) != 6 {
+		t.Errorf("expected 6 params, got %v", result.RequiredParams)
+	}
+}
+
+func TestRenderSearchText(t *testing.T) {
+	topK := 5
+	ast := &types.VectorAST{
+		Operation:      types.OpSearch,
+		Target:         types.Collection{Name: "products"},
+		NearText:       &types.NearText{Concepts: types.Param{Name: "q"}},
+		TopK:           &types.PaginationValue{Static: &topK},
+		IncludeVectors: true,
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"attributesToRetrieve":["_id"],"limit":5,"q":":q","searchMethod":"TENSOR"}`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if len(result.Ignored) != 1 || result.Ignored[0] != "include_vectors" {
+		t.Errorf("expected include_vectors to be ignored, got %v", result.Ignored)
+	}
+	// q takes one string, so the concepts list is joined when bound
+	if result.Joined["q"] != ", " {
+		t.Errorf("expected the concepts to be joined, got %v", result.Joined)
+	}
+}
+
+func TestRenderSearchUnsupported(t *testing.T) {
+	topK := 10
+	vector := &types.VectorValue{Param: &types.Param{Name: "query_vec"}}
+
+	tests := []struct {
+		name   string
+		ast    *types.VectorAST
+		errMsg string
+	}{
+		{
+			// Marqo ranges are inclusive at both ends
+			name: "strict comparison",
+			ast: &types.VectorAST{
+				Operation:    types.OpSearch,
+				Target:       types.Collection{Name: "products"},
+				QueryVector:  vector,
+				TopK:         &types.PaginationValue{Static: &topK},
+				FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "price"}, Operator: types.GT, Value: types.Param{Name: "price"}},
+			},
+			errMsg: "unsupported filter operator",
+		},
+		{
+			// A vector context cannot be combined with a lexical query
+			name: "hybrid",
+			ast: &types.VectorAST{
+				Operation:   types.OpSearch,
+				Target:      types.Collection{Name: "products"},
+				QueryVector: vector,
+				TextQuery:   &types.TextQuery{Query: types.Param{Name: "q"}},
+				TopK:        &types.PaginationValue{Static: &topK},
+			},
+			errMsg: "hybrid",
+		},
+		{
+			// Tensor search has no score cutoff
+			name: "score threshold",
+			ast: &types.VectorAST{
+				Operation:   types.OpSearch,
+				Target:      types.Collection{Name: "products"},
+				QueryVector: vector,
+				TopK:        &types.PaginationValue{Static: &topK},
+				MinScore:    &types.Param{Name: "min_score"},
+			},
+			errMsg: "score thresholds",
+		},
+	}
+	for _, tt := range tests {
+		_, err := New().Render(tt.ast)
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.errMsg, err)
+		}
+	}
+}
+
+func TestRenderUpsert(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation: types.OpUpsert,
+		Target:    types.Collection{Name: "products"},
+		Vectors: []types.VectorRecord{{
+			ID:       types.Param{Name: "id"},
+			Vector:   types.VectorValue{Param: &types.Param{Name: "vec"}},
+			Metadata: map[types.MetadataField]types.Param{{Name: "color"}: {Name: "color"}},
+		}},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"documents":[{"_id":":id","color":":color","embedding":{"vector":":vec"}}],` +
+		`"mappings":{"embedding":{"type":"custom_vector"}},"tensorFields":["embedding"]}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+}
+
+func TestRenderDeleteFetchUpdate(t *testing.T) {
+	ids := []types.Param{{Name: "id1"}, {Name: "id2"}}
+
+	for _, op := range []types.Operation{types.OpDelete, types.OpFetch} {
+		result, err := New().Render(&types.VectorAST{Operation: op, Target: types.Collection{Name: "products"}, IDs: ids})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", op, err)
+		}
+		if expected := `[":id1",":id2"]`; result.JSON != expected {
+			t.Errorf("%s: expected %s, got %s", op, expected, result.JSON)
+		}
+	}
+
+	result, err := New().Render(&types.VectorAST{
+		Operation: types.OpUpdate,
+		Target:    types.Collection{Name: "products"},
+		IDs:       ids[:1],
+		Updates:   map[types.MetadataField]types.Param{{Name: "color"}: {Name: "color"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"documents":[{"_id":":id1","color":":color"}]}`; result.JSON != expected {
+		t.Errorf("expected %s, got %s", expected, result.JSON)
+	}
+
+	filtered := &types.VectorAST{
+		Operation:    types.OpDelete,
+		Target:       types.Collection{Name: "products"},
+		FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "a"}, Operator: types.EQ, Value: types.Param{Name: "a"}},
+		DeleteAll:    true,
+	}
+	if _, err := New().Render(filtered); err == nil {
+		t.Error("expected error for delete by filter")
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		op     types.Operation
+		method string
+		path   string
+	}{
+		{types.OpSearch, "POST", "/indexes/products/search"},
+		{types.OpUpsert, "POST", "/indexes/products/documents"},
+		{types.OpUpdate, "PATCH", "/indexes/products/documents"},
+		{types.OpDelete, "POST", "/indexes/products/documents/delete-batch"},
+		{types.OpFetch, "GET", "/indexes/products/documents"},
+	}
+	for _, tt := range tests {
+		endpoint, err := New().Endpoint(&types.VectorAST{Operation: tt.op, Target: types.Collection{Name: "products"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if endpoint.Method != tt.method || endpoint.Path != tt.path || endpoint.Query != "" {
+			t.Errorf("%s: unexpected endpoint %+v", tt.op, endpoint)
+		}
+	}
+
+	endpoint, err := New().Endpoint(&types.VectorAST{Operation: types.OpFetch, Target: types.Collection{Name: "products"}, IncludeVectors: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Query != "expose_facets=true" {
+		t.Errorf("expected facets to be exposed, got %+v", endpoint)
+	}
+}
@@ -0,0 +1,15 @@
+package milvus
+
+import "github.com/zoobzio/vectql/internal/types"
+
+// SupportsExistsByFilter reports that existence checks by filter render as
+// a query for one entity, in either mode.
+func (r *Renderer) SupportsExistsByFilter() bool {
+	return true
+}
+
+// queries reports whether a FETCH renders as a query by expression rather
+// than a get by ID.
+func queries(ast *types.VectorAST) bool {
+	return ast.FetchKey != nil || ast.ExistsByFilter()
+}
@@ -0,0 +1,48 @@
+package milvus
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderExists(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation: types.OpFetch,
+		Target:    types.Collection{Name: "docs"},
+		Exists:    true,
+		FilterClause: types.FilterCondition{
+			Field:    types.MetadataField{Name: "hash"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "hash"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		renderer *Renderer
+		expected string
+		path     string
+	}{
+		{"sdk", New(), `{"collection_name":"docs","filter":"hash == :hash","limit":1}`, "/v1/vector/query"},
+		{"restv2", NewRESTv2(), `{"collectionName":"docs","filter":"hash == :hash","limit":1}`, "/v2/vectordb/entities/query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.renderer.Render(ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.JSON != tt.expected {
+				t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, tt.expected)
+			}
+			endpoint, err := tt.renderer.Endpoint(ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if endpoint.Path != tt.path {
+				t.Errorf("expected %s, got %s", tt.path, endpoint.Path)
+			}
+		})
+	}
+}
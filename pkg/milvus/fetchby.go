@@ -0,0 +1,24 @@
+package milvus
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// SupportsFetchBy reports that FETCH by key renders as a query with a
+// boolean expression on the key field, in either mode.
+func (r *Renderer) SupportsFetchBy() bool {
+	return true
+}
+
+// keyFilter renders the expression matching the records of a FETCH by key.
+func keyFilter(key *types.FetchKey, params *[]string) string {
+	exprs := make([]string, len(key.Keys))
+	for i, k := range key.Keys {
+		*params = append(*params, k.Name)
+		exprs[i] = fmt.Sprintf(":%s", k.Name)
+	}
+	return fmt.Sprintf("%s in [%s]", key.Field.Name, strings.Join(exprs, ", "))
+}
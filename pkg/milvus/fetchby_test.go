@@ -0,0 +1,46 @@
+package milvus
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderFetchBy(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation: types.OpFetch,
+		Target:    types.Collection{Name: "docs"},
+		FetchKey: &types.FetchKey{
+			Field: types.MetadataField{Name: "external_id", Type: "string", Unique: true},
+			Keys:  []types.Param{{Name: "k1"}, {Name: "k2"}},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		renderer *Renderer
+		expected string
+		path     string
+	}{
+		{"sdk", New(), `{"collection_name":"docs","filter":"external_id in [:k1, :k2]","limit":2}`, "/v1/vector/query"},
+		{"restv2", NewRESTv2(), `{"collectionName":"docs","filter":"external_id in [:k1, :k2]","limit":2}`, "/v2/vectordb/entities/query"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.renderer.Render(ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.JSON != tt.expected {
+				t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, tt.expected)
+			}
+			endpoint, err := tt.renderer.Endpoint(ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if endpoint.Path != tt.path {
+				t.Errorf("expected %s, got %s", tt.path, endpoint.Path)
+			}
+		})
+	}
+}
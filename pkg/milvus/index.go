@@ -0,0 +1,61 @@
+package milvus
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// RenderFieldIndex renders a scalar index creation call. Keyword, bool, and
+// array fields get INVERTED indexes and numbers STL_SORT. Milvus has no
+// scalar index for geo or full-text fields. In RESTful v2 mode it targets
+// /v2/vectordb/indexes/create; in SDK mode the endpoint is zero.
+func (r *Renderer) RenderFieldIndex(collection string, field types.MetadataField) (types.Endpoint, string, error) {
+	if collection == "" || field.Name == "" {
+		return types.Endpoint{}, "", fmt.Errorf("collection and field names are required")
+	}
+
+	var indexType string
+	switch field.Index {
+	case types.IndexKeyword, types.IndexBool:
+		indexType = "INVERTED"
+	case types.IndexInteger, types.IndexFloat:
+		indexType = "STL_SORT"
+		if strings.HasPrefix(field.Type, "[]") {
+			indexType = "INVERTED"
+		}
+	case types.IndexGeo, types.IndexText:
+		return types.Endpoint{}, "", fmt.Errorf("milvus does not support %s scalar indexes", field.Index)
+	default:
+		return types.Endpoint{}, "", fmt.Errorf("unsupported index kind: %q", field.Index)
+	}
+
+	var body map[string]interface{}
+	var endpoint types.Endpoint
+	if r.Mode == ModeRESTv2 {
+		body = map[string]interface{}{
+			"collectionName": collection,
+			"indexParams": []interface{}{map[string]interface{}{
+				"fieldName": field.Name,
+				"indexName": field.Name,
+				"indexType": indexType,
+			}},
+		}
+		endpoint = types.Endpoint{Method: "POST", Path: "/v2/vectordb/indexes/create"}
+	} else {
+		body = map[string]interface{}{
+			"collection_name": collection,
+			"field_name":      field.Name,
+			"index_name":      field.Name,
+			"index_params":    map[string]interface{}{"index_type": indexType},
+		}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return types.Endpoint{}, "", fmt.Errorf("failed to serialize scalar index: %w", err)
+	}
+	return endpoint, string(data), nil
+}
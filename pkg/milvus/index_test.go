@@ -0,0 +1,51 @@
+package milvus
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderFieldIndex(t *testing.T) {
+	tests := []struct {
+		field types.MetadataField
+		body  string
+	}{
+		{types.MetadataField{Name: "category", Type: "string", Index: types.IndexKeyword},
+			`{"collection_name":"docs","field_name":"category","index_name":"category","index_params":{"index_type":"INVERTED"}}`},
+		{types.MetadataField{Name: "year", Type: "int", Index: types.IndexInteger},
+			`{"collection_name":"docs","field_name":"year","index_name":"year","index_params":{"index_type":"STL_SORT"}}`},
+		{types.MetadataField{Name: "scores", Type: "[]float", Index: types.IndexFloat},
+			`{"collection_name":"docs","field_name":"scores","index_name":"scores","index_params":{"index_type":"INVERTED"}}`},
+	}
+	for _, tt := range tests {
+		endpoint, body, err := New().RenderFieldIndex("docs", tt.field)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.field.Name, err)
+		}
+		if endpoint != (types.Endpoint{}) {
+			t.Errorf("%s: expected zero endpoint in SDK mode, got %+v", tt.field.Name, endpoint)
+		}
+		if body != tt.body {
+			t.Errorf("%s: expected %s, got %s", tt.field.Name, tt.body, body)
+		}
+	}
+
+	if _, _, err := New().RenderFieldIndex("docs", types.MetadataField{Name: "loc", Index: types.IndexGeo}); err == nil {
+		t.Error("expected error for a geo index")
+	}
+}
+
+func TestRenderFieldIndexRESTv2(t *testing.T) {
+	endpoint, body, err := NewRESTv2().RenderFieldIndex("docs", types.MetadataField{Name: "category", Type: "string", Index: types.IndexKeyword})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Method != "POST" || endpoint.Path != "/v2/vectordb/indexes/create" {
+		t.Errorf("unexpected endpoint: %+v", endpoint)
+	}
+	expected := `{"collectionName":"docs","indexParams":[{"fieldName":"category","indexName":"category","indexType":"INVERTED"}]}`
+	if body != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+}
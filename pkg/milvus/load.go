@@ -0,0 +1,33 @@
+package milvus
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// RenderLoad renders the call that loads collection into query node memory,
+// which Milvus requires before it serves searches. In RESTful v2 mode it
+// targets /v2/vectordb/collections/load; in SDK mode the body mirrors the
+// SDK call arguments and the endpoint is zero.
+func (r *Renderer) RenderLoad(collection string) (types.Endpoint, string, error) {
+	if collection == "" {
+		return types.Endpoint{}, "", fmt.Errorf("collection name is required")
+	}
+
+	var body map[string]interface{}
+	var endpoint types.Endpoint
+	if r.Mode == ModeRESTv2 {
+		body = map[string]interface{}{"collectionName": collection}
+		endpoint = types.Endpoint{Method: "POST", Path: "/v2/vectordb/collections/load"}
+	} else {
+		body = map[string]interface{}{"collection_name": collection}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return types.Endpoint{}, "", fmt.Errorf("failed to serialize load call: %w", err)
+	}
+	return endpoint, string(data), nil
+}
@@ -0,0 +1,34 @@
+package milvus
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderLoad(t *testing.T) {
+	r := New()
+	r.Mode = ModeRESTv2
+	endpoint, body, err := r.RenderLoad("docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Method != "POST" || endpoint.Path != "/v2/vectordb/collections/load" {
+		t.Errorf("unexpected endpoint: %s %s", endpoint.Method, endpoint.Path)
+	}
+	if body != `{"collectionName":"docs"}` {
+		t.Errorf("unexpected body: %s", body)
+	}
+
+	endpoint, body, err = New().RenderLoad("docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint != (types.Endpoint{}) || body != `{"collection_name":"docs"}` {
+		t.Errorf("unexpected SDK call: %+v %s", endpoint, body)
+	}
+
+	if _, _, err := New().RenderLoad(""); err == nil {
+		t.Error("expected an error for an empty collection")
+	}
+}
@@ -4,8 +4,10 @@ package milvus
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"strings"
 
+	"github.com/zoobzio/vectql/internal/filterexpr"
 	"github.com/zoobzio/vectql/internal/types"
 )
 
@@ -21,17 +23,27 @@ func toResult(query map[string]interface{}, params []string) (*types.QueryResult
 	}, nil
 }
 
+// fallbackVectorField is the vector field used when neither the query nor
+// the collection names an embedding.
+const fallbackVectorField = "embedding"
+
 // Renderer renders VectorAST to Milvus query format.
 type Renderer struct {
-	// DefaultVectorField is the default vector field name.
-	DefaultVectorField string
+	// Mode selects the request body format. The zero value is ModeSDK.
+	Mode Mode
 }
 
 // New creates a new Milvus renderer.
 func New() *Renderer {
-	return &Renderer{
-		DefaultVectorField: "embedding",
+	return &Renderer{}
+}
+
+// vectorField returns the collection's default vector field.
+func vectorField(c types.Collection) string {
+	if c.DefaultEmbedding != "" {
+		return c.DefaultEmbedding
 	}
+	return fallbackVectorField
 }
 
 // Render converts a VectorAST to Milvus query format.
@@ -42,6 +54,10 @@ func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
 
 	var params []string
 
+	if r.Mode == ModeRESTv2 {
+		return r.renderV2(ast, &params)
+	}
+
 	switch ast.Operation {
 	case types.OpSearch:
 		return r.renderSearch(ast, &params)
@@ -59,16 +75,26 @@ func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
 }
 
 func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("milvus does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("milvus"); err != nil {
+		return nil, err
+	}
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("milvus does not support quantization search parameters")
+	}
+
 	query := make(map[string]interface{})
 
 	query["collection_name"] = ast.Target.Name
 
 	// Vector field
-	vectorField := r.DefaultVectorField
+	field := vectorField(ast.Target)
 	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
-		vectorField = ast.QueryEmbedding.Name
+		field = ast.QueryEmbedding.Name
 	}
-	query["anns_field"] = vectorField
+	query["anns_field"] = field
 
 	// Vector data
 	if ast.QueryVector != nil {
@@ -92,11 +118,7 @@ func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.
 
 	// Output fields
 	if ast.IncludeMetadata && len(ast.MetadataFields) > 0 {
-		fields := make([]string, len(ast.MetadataFields))
-		for i, f := range ast.MetadataFields {
-			fields[i] = f.Name
-		}
-		query["output_fields"] = fields
+		query["output_fields"] = fieldNames(ast.MetadataFields)
 	}
 
 	// Filter expression
@@ -114,7 +136,19 @@ func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.
 		query["partition_names"] = []string{fmt.Sprintf(":%s", ast.Namespace.Name)}
 	}
 
-	return toResult(query, *params)
+	if ast.Freshness != nil {
+		query["consistency_level"] = consistencyLevels[ast.Freshness.Level]
+	}
+
+	result, err := toResult(query, *params)
+	if err != nil {
+		return nil, err
+	}
+	// Milvus ranks by vector distance alone
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
 }
 
 func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
@@ -132,12 +166,12 @@ func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) (*types.
 		row["id"] = fmt.Sprintf(":%s", record.ID.Name)
 
 		// Vector
-		vectorField := r.DefaultVectorField
+		field := vectorField(ast.Target)
 		if record.Vector.Param != nil {
 			*params = append(*params, record.Vector.Param.Name)
-			row[vectorField] = fmt.Sprintf(":%s", record.Vector.Param.Name)
+			row[field] = fmt.Sprintf(":%s", record.Vector.Param.Name)
 		} else {
-			row[vectorField] = record.Vector.Literal
+			row[field] = record.Vector.Literal
 		}
 
 		// Metadata
@@ -166,12 +200,7 @@ func (r *Renderer) renderDelete(ast *types.VectorAST, params *[]string) (*types.
 
 	if len(ast.IDs) > 0 {
 		// Delete by IDs - build expression
-		idExprs := make([]string, len(ast.IDs))
-		for i, id := range ast.IDs {
-			*params = append(*params, id.Name)
-			idExprs[i] = fmt.Sprintf(":%s", id.Name)
-		}
-		query["filter"] = fmt.Sprintf("id in [%s]", strings.Join(idExprs, ", "))
+		query["filter"] = idFilter(ast.IDs, params)
 	} else if ast.FilterClause != nil && ast.DeleteAll {
 		expr, err := r.renderFilter(ast.FilterClause, params)
 		if err != nil {
@@ -194,21 +223,28 @@ func (r *Renderer) renderFetch(ast *types.VectorAST, params *[]string) (*types.Q
 		"collection_name": ast.Target.Name,
 	}
 
-	// Build ID filter expression
-	idExprs := make([]string, len(ast.IDs))
-	for i, id := range ast.IDs {
-		*params = append(*params, id.Name)
-		idExprs[i] = fmt.Sprintf(":%s", id.Name)
+	// Build ID filter expression; a key fetch is a query limited to as
+	// many records as keys, an existence check by filter a query for one.
+	// Milvus cannot group a query, so records sharing a key can use up
+	// the limit
+	switch {
+	case ast.FetchKey != nil:
+		query["filter"] = keyFilter(ast.FetchKey, params)
+		query["limit"] = len(ast.FetchKey.Keys)
+	case ast.ExistsByFilter():
+		expr, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		query["filter"] = expr
+		query["limit"] = 1
+	default:
+		query["filter"] = idFilter(ast.IDs, params)
 	}
-	query["filter"] = fmt.Sprintf("id in [%s]", strings.Join(idExprs, ", "))
 
 	// Output fields
 	if ast.IncludeMetadata && len(ast.MetadataFields) > 0 {
-		fields := make([]string, len(ast.MetadataFields))
-		for i, f := range ast.MetadataFields {
-			fields[i] = f.Name
-		}
-		query["output_fields"] = fields
+		query["output_fields"] = fieldNames(ast.MetadataFields)
 	} else if ast.IncludeMetadata {
 		query["output_fields"] = []string{"*"}
 	}
@@ -219,6 +255,10 @@ func (r *Renderer) renderFetch(ast *types.VectorAST, params *[]string) (*types.Q
 		query["partition_names"] = []string{fmt.Sprintf(":%s", ast.Namespace.Name)}
 	}
 
+	if ast.Freshness != nil {
+		query["consistency_level"] = consistencyLevels[ast.Freshness.Level]
+	}
+
 	return toResult(query, *params)
 }
 
@@ -246,60 +286,50 @@ func (r *Renderer) renderUpdate(ast *types.VectorAST, params *[]string) (*types.
 	return toResult(query, *params)
 }
 
+// idFilter builds an "id in [...]" expression over ID placeholders.
+func idFilter(ids []types.Param, params *[]string) string {
+	exprs := make([]string, len(ids))
+	for i, id := range ids {
+		*params = append(*params, id.Name)
+		exprs[i] = fmt.Sprintf(":%s", id.Name)
+	}
+	return fmt.Sprintf("id in [%s]", strings.Join(exprs, ", "))
+}
+
+// consistencyLevels maps freshness levels to Milvus consistency levels. The
+// Bounded staleness window is the server's graceful_time, not MaxStaleness.
+var consistencyLevels = map[types.FreshnessLevel]string{
+	types.FreshnessStrong:   "Strong",
+	types.FreshnessBounded:  "Bounded",
+	types.FreshnessEventual: "Eventually",
+}
+
+// fieldNames returns the names of fields.
+func fieldNames(fields []types.MetadataField) []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
 func (r *Renderer) renderFilter(f types.FilterItem, params *[]string) (string, error) {
-	switch filter := f.(type) {
-	case types.FilterCondition:
-		*params = append(*params, filter.Value.Name)
-		return fmt.Sprintf("%s %s :%s", filter.Field.Name, r.mapOperator(filter.Operator), filter.Value.Name), nil
-
-	case types.FilterGroup:
-		if filter.Logic == types.NOT {
-			if len(filter.Conditions) > 0 {
-				inner, err := r.renderFilter(filter.Conditions[0], params)
-				if err != nil {
-					return "", err
-				}
-				return fmt.Sprintf("not (%s)", inner), nil
-			}
-			return "", nil
-		}
+	return filterexpr.New(r.dialect(), params).Compile(f)
+}
 
-		parts := make([]string, 0, len(filter.Conditions))
-		for _, c := range filter.Conditions {
-			rendered, err := r.renderFilter(c, params)
-			if err != nil {
-				return "", err
-			}
-			parts = append(parts, rendered)
-		}
-		op := " and "
-		if filter.Logic == types.OR {
-			op = " or "
-		}
-		return "(" + strings.Join(parts, op) + ")", nil
-
-	case types.RangeFilter:
-		var parts []string
-		if filter.Min != nil {
-			*params = append(*params, filter.Min.Name)
-			op := ">="
-			if filter.MinExclusive {
-				op = ">"
-			}
-			parts = append(parts, fmt.Sprintf("%s %s :%s", filter.Field.Name, op, filter.Min.Name))
-		}
-		if filter.Max != nil {
-			*params = append(*params, filter.Max.Name)
-			op := "<="
-			if filter.MaxExclusive {
-				op = "<"
+// dialect describes the Milvus boolean expression language.
+func (r *Renderer) dialect() *filterexpr.Dialect {
+	return &filterexpr.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			if !r.SupportsFilter(op) {
+				return "", false
 			}
-			parts = append(parts, fmt.Sprintf("%s %s :%s", filter.Field.Name, op, filter.Max.Name))
-		}
-		return "(" + strings.Join(parts, " and ") + ")", nil
-
-	default:
-		return "", fmt.Errorf("unsupported filter type: %T", f)
+			return r.mapOperator(op), true
+		},
+		And:                "and",
+		Or:                 "or",
+		Not:                "not",
+		ParenthesizeGroups: true,
 	}
 }
 
@@ -358,3 +388,60 @@ func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
 		return false
 	}
 }
+
+// SupportsModality indicates if Milvus accepts a search input modality.
+// Milvus only searches with vectors.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	return m == types.ModalityVector
+}
+
+// SupportsVectorEncoding indicates if Milvus accepts a compact vector
+// encoding. The RESTful v2 API takes Float16Vector values as base64 strings.
+func (r *Renderer) SupportsVectorEncoding(e types.VectorEncoding) bool {
+	return r.Mode == ModeRESTv2 && e == types.VectorEncodingFloat16Base64
+}
+
+// Capabilities describes the features supported by Milvus.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("milvus", r)
+}
+
+// RenderTo writes the Milvus query body for ast to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// Format reports that Milvus queries are JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// Endpoint returns the Milvus RESTful call for ast. Updates are sent as upserts.
+// In ModeRESTv2 the v2 entity paths are returned.
+func (r *Renderer) Endpoint(ast *types.VectorAST) (types.Endpoint, error) {
+	if r.Mode == ModeRESTv2 {
+		return r.endpointV2(ast)
+	}
+	switch ast.Operation {
+	case types.OpSearch:
+		return types.Endpoint{Method: "POST", Path: "/v1/vector/search"}, nil
+	case types.OpUpsert, types.OpUpdate:
+		return types.Endpoint{Method: "POST", Path: "/v1/vector/upsert"}, nil
+	case types.OpDelete:
+		return types.Endpoint{Method: "POST", Path: "/v1/vector/delete"}, nil
+	case types.OpFetch:
+		if queries(ast) {
+			return types.Endpoint{Method: "POST", Path: "/v1/vector/query"}, nil
+		}
+		return types.Endpoint{Method: "POST", Path: "/v1/vector/get"}, nil
+	default:
+		return types.Endpoint{}, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
@@ -70,6 +70,85 @@ func TestRenderSearchWithFilter(t *testing.T) {
 	}
 }
 
+func TestRenderFilterExpression(t *testing.T) {
+	renderer := New()
+	minPrice := types.Param{Name: "min_price"}
+
+	filter := types.FilterGroup{
+		Logic: types.AND,
+		Conditions: []types.FilterItem{
+			types.FilterCondition{
+				Field:    types.MetadataField{Name: "category"},
+				Operator: types.EQ,
+				Value:    types.Param{Name: "cat"},
+			},
+			types.RangeFilter{
+				Field: types.MetadataField{Name: "price"},
+				Min:   &minPrice,
+			},
+			types.FilterGroup{
+				Logic: types.NOT,
+				Conditions: []types.FilterItem{
+					types.FilterCondition{
+						Field:    types.MetadataField{Name: "brand"},
+						Operator: types.IN,
+						Value:    types.Param{Name: "brands"},
+					},
+				},
+			},
+		},
+	}
+
+	var params []string
+	expr, err := renderer.renderFilter(filter, &params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "(category == :cat and (price >= :min_price) and not (brand in :brands))"
+	if expr != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, expr)
+	}
+	if len(params) != 3 {
+		t.Errorf("expected 3 params, got %v", params)
+	}
+}
+
+func TestRenderFilterRejectsUnsafeInput(t *testing.T) {
+	renderer := New()
+
+	tests := []struct {
+		name   string
+		filter types.FilterItem
+	}{
+		{
+			name: "unsupported operator",
+			filter: types.FilterCondition{
+				Field:    types.MetadataField{Name: "name"},
+				Operator: types.Matches,
+				Value:    types.Param{Name: "p"},
+			},
+		},
+		{
+			name: "field name injection",
+			filter: types.FilterCondition{
+				Field:    types.MetadataField{Name: "id > 0 or category"},
+				Operator: types.EQ,
+				Value:    types.Param{Name: "p"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params []string
+			if _, err := renderer.renderFilter(tt.filter, &params); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
 func TestRenderSearchWithOutputFields(t *testing.T) {
 	renderer := New()
 
@@ -130,6 +209,45 @@ func TestRenderUpsert(t *testing.T) {
 	}
 }
 
+func TestDefaultVectorFieldPerCollection(t *testing.T) {
+	renderer := New()
+	topK := 5
+
+	search := func(target types.Collection) string {
+		result, err := renderer.Render(&types.VectorAST{
+			Operation:   types.OpSearch,
+			Target:      target,
+			QueryVector: &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+			TopK:        &types.PaginationValue{Static: &topK},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return result.JSON
+	}
+
+	if got := search(types.Collection{Name: "products"}); !strings.Contains(got, `"anns_field":"embedding"`) {
+		t.Errorf("expected fallback vector field in JSON: %s", got)
+	}
+	if got := search(types.Collection{Name: "images", DefaultEmbedding: "clip"}); !strings.Contains(got, `"anns_field":"clip"`) {
+		t.Errorf("expected collection default vector field in JSON: %s", got)
+	}
+
+	result, err := renderer.Render(&types.VectorAST{
+		Operation: types.OpUpsert,
+		Target:    types.Collection{Name: "images", DefaultEmbedding: "clip"},
+		Vectors: []types.VectorRecord{
+			{ID: types.Param{Name: "id1"}, Vector: types.VectorValue{Param: &types.Param{Name: "vec1"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.JSON, `"clip":":vec1"`) {
+		t.Errorf("expected default vector field in upsert rows: %s", result.JSON)
+	}
+}
+
 func TestRenderDelete(t *testing.T) {
 	renderer := New()
 
@@ -284,3 +402,97 @@ func TestOperatorMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestCapabilities(t *testing.T) {
+	renderer := New()
+	caps := renderer.Capabilities()
+
+	if caps.Provider != "milvus" {
+		t.Errorf("expected provider milvus, got %q", caps.Provider)
+	}
+	for _, op := range types.FilterOperators {
+		if caps.SupportsFilter(op) != renderer.SupportsFilter(op) {
+			t.Errorf("capabilities disagree with SupportsFilter for %s", op)
+		}
+	}
+	if renderer.Format() != types.FormatJSON {
+		t.Errorf("expected JSON format, got %s", renderer.Format())
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	renderer := New()
+
+	tests := []struct {
+		op     types.Operation
+		method string
+		path   string
+	}{
+		{types.OpSearch, "POST", "/v1/vector/search"},
+		{types.OpUpdate, "POST", "/v1/vector/upsert"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.op), func(t *testing.T) {
+			ast := &types.VectorAST{
+				Operation: tt.op,
+				Target:    types.Collection{Name: "products"},
+				IDs:       []types.Param{{Name: "id"}},
+			}
+			endpoint, err := renderer.Endpoint(ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if endpoint.Method != tt.method || endpoint.Path != tt.path {
+				t.Errorf("expected %s %s, got %s %s", tt.method, tt.path, endpoint.Method, endpoint.Path)
+			}
+		})
+	}
+}
+
+func TestRenderSearchRejectsNearText(t *testing.T) {
+	renderer := New()
+
+	topK := 10
+	ast := &types.VectorAST{
+		Operation: types.OpSearch,
+		Target:    types.Collection{Name: "products"},
+		NearText:  &types.NearText{Concepts: types.Param{Name: "concepts"}},
+		TopK:      &types.PaginationValue{Static: &topK},
+	}
+
+	if _, err := renderer.Render(ast); err == nil {
+		t.Error("expected error for text search input")
+	}
+	if renderer.Capabilities().SupportsModality(types.ModalityText) {
+		t.Error("expected text modality to be unsupported")
+	}
+}
+
+func TestRenderFreshness(t *testing.T) {
+	topK := 5
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "v"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		Freshness:   &types.Freshness{Level: types.FreshnessEventual},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.JSON, `"consistency_level":"Eventually"`) {
+		t.Errorf("expected consistency level, got %s", result.JSON)
+	}
+
+	ast.Freshness = &types.Freshness{Level: types.FreshnessStrong}
+	result, err = NewRESTv2().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.JSON, `"consistencyLevel":"Strong"`) {
+		t.Errorf("expected consistency level, got %s", result.JSON)
+	}
+}
@@ -0,0 +1,62 @@
+package milvus
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// maxNameLength is the longest collection or field name Milvus accepts.
+const maxNameLength = 255
+
+// primaryField is the primary key field rendered queries address.
+const primaryField = "id"
+
+// ValidateNames reports names Milvus cannot store: names that are not
+// letters, digits, and underscores starting with a letter or underscore,
+// names longer than 255 characters, fields named like the primary key, and
+// metadata fields named like an embedding, since both are fields of the
+// same collection schema.
+func (r *Renderer) ValidateNames(names types.SchemaNames) error {
+	var errs []error
+	for _, coll := range names.Collections {
+		if coll.Name != "" {
+			if err := checkName(coll.Name); err != nil {
+				errs = append(errs, fmt.Errorf("collection '%s': %w", coll.Name, err))
+			}
+		}
+		embeddings := make(map[string]bool, len(coll.Embeddings))
+		for _, name := range coll.Embeddings {
+			embeddings[name] = true
+			if err := checkFieldName(name); err != nil {
+				errs = append(errs, fmt.Errorf("embedding '%s' in collection '%s': %w", name, coll.Name, err))
+			}
+		}
+		for _, name := range coll.Fields {
+			if err := checkFieldName(name); err != nil {
+				errs = append(errs, fmt.Errorf("field '%s' in collection '%s': %w", name, coll.Name, err))
+			} else if embeddings[name] {
+				errs = append(errs, fmt.Errorf("field '%s' in collection '%s': conflicts with the embedding of the same name", name, coll.Name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkFieldName(name string) error {
+	if name == primaryField {
+		return fmt.Errorf("name is reserved for the primary key")
+	}
+	return checkName(name)
+}
+
+func checkName(name string) error {
+	if !types.IsValidIdentifier(name) {
+		return fmt.Errorf("name must contain only letters, digits, and underscores and start with a letter or underscore")
+	}
+	if len(name) > maxNameLength {
+		return fmt.Errorf("name exceeds %d characters", maxNameLength)
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package milvus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestValidateNames(t *testing.T) {
+	valid := types.SchemaNames{Collections: []types.CollectionNames{
+		{Name: "docs", Embeddings: []string{"embedding"}, Fields: []string{"category"}},
+	}}
+	if err := New().ValidateNames(valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := New().ValidateNames(types.SchemaNames{Collections: []types.CollectionNames{
+		{Name: "my-docs", Embeddings: []string{"embedding"}, Fields: []string{"id", "embedding", strings.Repeat("a", 256)}},
+	}})
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		"collection 'my-docs': name must contain only letters",
+		"field 'id' in collection 'my-docs': name is reserved for the primary key",
+		"field 'embedding' in collection 'my-docs': conflicts with the embedding",
+		"exceeds 255 characters",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package milvus
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// RenderPartition renders a partition lifecycle call. In RESTful v2 mode it
+// targets /v2/vectordb/partitions/*; in SDK mode the body mirrors the SDK
+// call arguments and the endpoint is zero, since the v1 RESTful API has no
+// partition calls. Milvus only drops released partitions.
+func (r *Renderer) RenderPartition(op types.PartitionOp, collection, partition string) (types.Endpoint, string, error) {
+	if collection == "" || partition == "" {
+		return types.Endpoint{}, "", fmt.Errorf("collection and partition names are required")
+	}
+
+	var action string
+	switch op {
+	case types.PartitionCreate:
+		action = "create"
+	case types.PartitionDrop:
+		action = "drop"
+	case types.PartitionLoad:
+		action = "load"
+	default:
+		return types.Endpoint{}, "", fmt.Errorf("unsupported partition operation: %s", op)
+	}
+
+	var body map[string]interface{}
+	var endpoint types.Endpoint
+	if r.Mode == ModeRESTv2 {
+		body = map[string]interface{}{"collectionName": collection}
+		if op == types.PartitionLoad {
+			body["partitionNames"] = []string{partition}
+		} else {
+			body["partitionName"] = partition
+		}
+		endpoint = types.Endpoint{Method: "POST", Path: "/v2/vectordb/partitions/" + action}
+	} else {
+		body = map[string]interface{}{"collection_name": collection}
+		if op == types.PartitionLoad {
+			body["partition_names"] = []string{partition}
+		} else {
+			body["partition_name"] = partition
+		}
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return types.Endpoint{}, "", fmt.Errorf("failed to serialize partition call: %w", err)
+	}
+	return endpoint, string(data), nil
+}
@@ -0,0 +1,54 @@
+package milvus
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderPartitionRESTv2(t *testing.T) {
+	r := New()
+	r.Mode = ModeRESTv2
+
+	tests := []struct {
+		op   types.PartitionOp
+		path string
+		body string
+	}{
+		{types.PartitionCreate, "/v2/vectordb/partitions/create", `{"collectionName":"docs","partitionName":"tenant_a"}`},
+		{types.PartitionDrop, "/v2/vectordb/partitions/drop", `{"collectionName":"docs","partitionName":"tenant_a"}`},
+		{types.PartitionLoad, "/v2/vectordb/partitions/load", `{"collectionName":"docs","partitionNames":["tenant_a"]}`},
+	}
+	for _, tt := range tests {
+		endpoint, body, err := r.RenderPartition(tt.op, "docs", "tenant_a")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.op, err)
+		}
+		if endpoint.Method != "POST" || endpoint.Path != tt.path {
+			t.Errorf("%s: unexpected endpoint: %s %s", tt.op, endpoint.Method, endpoint.Path)
+		}
+		if body != tt.body {
+			t.Errorf("%s: unexpected body: %s", tt.op, body)
+		}
+	}
+}
+
+func TestRenderPartitionSDK(t *testing.T) {
+	endpoint, body, err := New().RenderPartition(types.PartitionLoad, "docs", "tenant_a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint != (types.Endpoint{}) {
+		t.Errorf("expected no endpoint, got %+v", endpoint)
+	}
+	if body != `{"collection_name":"docs","partition_names":["tenant_a"]}` {
+		t.Errorf("unexpected body: %s", body)
+	}
+
+	if _, _, err := New().RenderPartition(types.PartitionCreate, "docs", ""); err == nil {
+		t.Error("expected an error for an empty partition")
+	}
+	if _, _, err := New().RenderPartition("RENAME_PARTITION", "docs", "a"); err == nil {
+		t.Error("expected an error for an unknown operation")
+	}
+}
@@ -0,0 +1,290 @@
+package milvus
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Mode selects the request format the renderer emits.
+type Mode int
+
+const (
+	// ModeSDK emits bodies shaped after the Milvus SDK call arguments
+	// (collection_name, anns_field, ...). This is the default.
+	ModeSDK Mode = iota
+
+	// ModeRESTv2 emits the exact Milvus 2.4 RESTful v2 request bodies
+	// (collectionName, annsField, ...), and Endpoint reports the matching
+	// /v2/vectordb/entities/* paths, so output can be posted over plain HTTP.
+	ModeRESTv2
+)
+
+// NewRESTv2 creates a Milvus renderer in RESTful v2 mode.
+func NewRESTv2() *Renderer {
+	return &Renderer{Mode: ModeRESTv2}
+}
+
+// SupportedVersions reports the Milvus versions the configured mode works
+// with. The RESTful v2 API was introduced in Milvus 2.4.
+func (r *Renderer) SupportedVersions() types.VersionRange {
+	if r.Mode == ModeRESTv2 {
+		return types.VersionRange{Min: "2.4"}
+	}
+	return types.VersionRange{}
+}
+
+// metricTypes maps distance metrics to Milvus metric type names.
+var metricTypes = map[types.DistanceMetric]string{
+	types.Cosine:     "COSINE",
+	types.Euclidean:  "L2",
+	types.DotProduct: "IP",
+}
+
+func (r *Renderer) renderV2(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	switch ast.Operation {
+	case types.OpSearch:
+		return r.renderSearchV2(ast, params)
+	case types.OpUpsert:
+		return r.renderUpsertV2(ast, params)
+	case types.OpDelete:
+		return r.renderDeleteV2(ast, params)
+	case types.OpFetch:
+		return r.renderFetchV2(ast, params)
+	case types.OpUpdate:
+		return r.renderUpdateV2(ast, params)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
+
+func (r *Renderer) renderSearchV2(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("milvus does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("milvus"); err != nil {
+		return nil, err
+	}
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("milvus does not support quantization search parameters")
+	}
+
+	query := map[string]interface{}{
+		"collectionName": ast.Target.Name,
+	}
+
+	field := vectorField(ast.Target)
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
+		field = ast.QueryEmbedding.Name
+	}
+	query["annsField"] = field
+
+	// data is a list of query vectors; a parameter binds to the single entry
+	if ast.QueryVector != nil {
+		if ast.QueryVector.Param != nil {
+			*params = append(*params, ast.QueryVector.Param.Name)
+			query["data"] = []string{fmt.Sprintf(":%s", ast.QueryVector.Param.Name)}
+		} else {
+			query["data"] = [][]float32{ast.QueryVector.Literal}
+		}
+	}
+
+	if ast.TopK != nil {
+		if ast.TopK.Static != nil {
+			query["limit"] = *ast.TopK.Static
+		} else if ast.TopK.Param != nil {
+			*params = append(*params, ast.TopK.Param.Name)
+			query["limit"] = fmt.Sprintf(":%s", ast.TopK.Param.Name)
+		}
+	}
+
+	if ast.IncludeMetadata && len(ast.MetadataFields) > 0 {
+		query["outputFields"] = fieldNames(ast.MetadataFields)
+	}
+
+	if ast.FilterClause != nil {
+		expr, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		query["filter"] = expr
+	}
+
+	if ast.Namespace != nil {
+		*params = append(*params, ast.Namespace.Name)
+		query["partitionNames"] = []string{fmt.Sprintf(":%s", ast.Namespace.Name)}
+	}
+
+	if ast.Freshness != nil {
+		query["consistencyLevel"] = consistencyLevels[ast.Freshness.Level]
+	}
+
+	// The metric must match the index, so it is only sent when the schema declares it
+	if ast.QueryEmbedding != nil {
+		if metric, ok := metricTypes[ast.QueryEmbedding.Metric]; ok {
+			query["searchParams"] = map[string]interface{}{"metricType": metric}
+		}
+	}
+
+	result, err := toResult(query, *params)
+	if err != nil {
+		return nil, err
+	}
+	// Milvus ranks by vector distance alone
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+func (r *Renderer) renderUpsertV2(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	field := vectorField(ast.Target)
+
+	data := make([]map[string]interface{}, len(ast.Vectors))
+	for i, record := range ast.Vectors {
+		row := make(map[string]interface{})
+
+		*params = append(*params, record.ID.Name)
+		row["id"] = fmt.Sprintf(":%s", record.ID.Name)
+
+		if record.Vector.Param != nil {
+			*params = append(*params, record.Vector.Param.Name)
+			row[field] = fmt.Sprintf(":%s", record.Vector.Param.Name)
+		} else {
+			row[field] = record.Vector.Literal
+		}
+
+		for f, value := range record.Metadata {
+			*params = append(*params, value.Name)
+			row[f.Name] = fmt.Sprintf(":%s", value.Name)
+		}
+
+		data[i] = row
+	}
+
+	query := map[string]interface{}{
+		"collectionName": ast.Target.Name,
+		"data":           data,
+	}
+
+	if ast.Namespace != nil {
+		*params = append(*params, ast.Namespace.Name)
+		query["partitionName"] = fmt.Sprintf(":%s", ast.Namespace.Name)
+	}
+
+	return toResult(query, *params)
+}
+
+func (r *Renderer) renderDeleteV2(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	query := map[string]interface{}{
+		"collectionName": ast.Target.Name,
+	}
+
+	// The v2 delete endpoint only accepts a filter expression
+	if len(ast.IDs) > 0 {
+		query["filter"] = idFilter(ast.IDs, params)
+	} else if ast.FilterClause != nil && ast.DeleteAll {
+		expr, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		query["filter"] = expr
+	}
+
+	if ast.Namespace != nil {
+		*params = append(*params, ast.Namespace.Name)
+		query["partitionName"] = fmt.Sprintf(":%s", ast.Namespace.Name)
+	}
+
+	return toResult(query, *params)
+}
+
+func (r *Renderer) renderFetchV2(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	query := map[string]interface{}{
+		"collectionName": ast.Target.Name,
+	}
+
+	switch {
+	case ast.FetchKey != nil:
+		query["filter"] = keyFilter(ast.FetchKey, params)
+		query["limit"] = len(ast.FetchKey.Keys)
+	case ast.ExistsByFilter():
+		expr, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		query["filter"] = expr
+		query["limit"] = 1
+	default:
+		ids := make([]string, len(ast.IDs))
+		for i, id := range ast.IDs {
+			*params = append(*params, id.Name)
+			ids[i] = fmt.Sprintf(":%s", id.Name)
+		}
+		query["id"] = ids
+	}
+
+	if ast.IncludeMetadata && len(ast.MetadataFields) > 0 {
+		query["outputFields"] = fieldNames(ast.MetadataFields)
+	} else if ast.IncludeMetadata {
+		query["outputFields"] = []string{"*"}
+	}
+
+	if ast.Namespace != nil {
+		*params = append(*params, ast.Namespace.Name)
+		query["partitionNames"] = []string{fmt.Sprintf(":%s", ast.Namespace.Name)}
+	}
+
+	if ast.Freshness != nil {
+		query["consistencyLevel"] = consistencyLevels[ast.Freshness.Level]
+	}
+
+	return toResult(query, *params)
+}
+
+func (r *Renderer) renderUpdateV2(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	// Milvus uses upsert for updates
+	data := make([]map[string]interface{}, len(ast.IDs))
+	for i, id := range ast.IDs {
+		row := make(map[string]interface{})
+		*params = append(*params, id.Name)
+		row["id"] = fmt.Sprintf(":%s", id.Name)
+
+		for field, value := range ast.Updates {
+			*params = append(*params, value.Name)
+			row[field.Name] = fmt.Sprintf(":%s", value.Name)
+		}
+		data[i] = row
+	}
+
+	query := map[string]interface{}{
+		"collectionName": ast.Target.Name,
+		"data":           data,
+	}
+
+	if ast.Namespace != nil {
+		*params = append(*params, ast.Namespace.Name)
+		query["partitionName"] = fmt.Sprintf(":%s", ast.Namespace.Name)
+	}
+
+	return toResult(query, *params)
+}
+
+// endpointV2 returns the RESTful v2 call for ast.
+func (r *Renderer) endpointV2(ast *types.VectorAST) (types.Endpoint, error) {
+	switch ast.Operation {
+	case types.OpSearch:
+		return types.Endpoint{Method: "POST", Path: "/v2/vectordb/entities/search"}, nil
+	case types.OpUpsert, types.OpUpdate:
+		return types.Endpoint{Method: "POST", Path: "/v2/vectordb/entities/upsert"}, nil
+	case types.OpDelete:
+		return types.Endpoint{Method: "POST", Path: "/v2/vectordb/entities/delete"}, nil
+	case types.OpFetch:
+		if queries(ast) {
+			return types.Endpoint{Method: "POST", Path: "/v2/vectordb/entities/query"}, nil
+		}
+		return types.Endpoint{Method: "POST", Path: "/v2/vectordb/entities/get"}, nil
+	default:
+		return types.Endpoint{}, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
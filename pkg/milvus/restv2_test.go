@@ -0,0 +1,181 @@
+package milvus
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// decodeBody unmarshals a rendered query into a generic map.
+func decodeBody(t *testing.T, result *types.QueryResult) map[string]interface{} {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(result.JSON), &body); err != nil {
+		t.Fatalf("invalid JSON %s: %v", result.JSON, err)
+	}
+	return body
+}
+
+func TestRenderSearchRESTv2(t *testing.T) {
+	renderer := NewRESTv2()
+
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:      types.OpSearch,
+		Target:         types.Collection{Name: "products"},
+		QueryEmbedding: &types.EmbeddingField{Name: "description", Metric: types.Cosine},
+		QueryVector:    &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:           &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterCondition{
+			Field:    types.MetadataField{Name: "category"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "cat"},
+		},
+		Namespace:       &types.Param{Name: "partition"},
+		IncludeMetadata: true,
+		MetadataFields:  []types.MetadataField{{Name: "category"}, {Name: "price"}},
+	}
+
+	result, err := renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"annsField":"description","collectionName":"products","data":[":query_vec"],` +
+		`"filter":"category == :cat","limit":10,"outputFields":["category","price"],` +
+		`"partitionNames":[":partition"],"searchParams":{"metricType":"COSINE"}}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+
+	want := []string{"query_vec", "cat", "partition"}
+	if len(result.RequiredParams) != len(want) {
+		t.Fatalf("expected RequiredParams=%v, got %v", want, result.RequiredParams)
+	}
+	for i, p := range want {
+		if result.RequiredParams[i] != p {
+			t.Errorf("expected RequiredParams=%v, got %v", want, result.RequiredParams)
+		}
+	}
+}
+
+func TestRenderSearchRESTv2WithoutMetric(t *testing.T) {
+	renderer := NewRESTv2()
+
+	topK := 5
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products"},
+		QueryVector: &types.VectorValue{Literal: []float32{0.5, 0.25}},
+		TopK:        &types.PaginationValue{Static: &topK},
+	}
+
+	result, err := renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body := decodeBody(t, result)
+	if _, ok := body["searchParams"]; ok {
+		t.Errorf("expected no searchParams without a declared metric: %s", result.JSON)
+	}
+	if body["annsField"] != fallbackVectorField {
+		t.Errorf("expected annsField=%s, got %v", fallbackVectorField, body["annsField"])
+	}
+	if data, ok := body["data"].([]interface{}); !ok || len(data) != 1 {
+		t.Errorf("expected one literal query vector, got %v", body["data"])
+	}
+}
+
+func TestRenderWritesRESTv2(t *testing.T) {
+	renderer := NewRESTv2()
+
+	tests := []struct {
+		name     string
+		ast      *types.VectorAST
+		expected string
+	}{
+		{
+			name: "upsert",
+			ast: &types.VectorAST{
+				Operation: types.OpUpsert,
+				Target:    types.Collection{Name: "products", DefaultEmbedding: "description"},
+				Vectors: []types.VectorRecord{{
+					ID:     types.Param{Name: "id"},
+					Vector: types.VectorValue{Param: &types.Param{Name: "vec"}},
+				}},
+				Namespace: &types.Param{Name: "partition"},
+			},
+			expected: `{"collectionName":"products","data":[{"description":":vec","id":":id"}],"partitionName":":partition"}`,
+		},
+		{
+			name: "delete",
+			ast: &types.VectorAST{
+				Operation: types.OpDelete,
+				Target:    types.Collection{Name: "products"},
+				IDs:       []types.Param{{Name: "id1"}, {Name: "id2"}},
+			},
+			expected: `{"collectionName":"products","filter":"id in [:id1, :id2]"}`,
+		},
+		{
+			name: "fetch",
+			ast: &types.VectorAST{
+				Operation:       types.OpFetch,
+				Target:          types.Collection{Name: "products"},
+				IDs:             []types.Param{{Name: "id"}},
+				IncludeMetadata: true,
+			},
+			expected: `{"collectionName":"products","id":[":id"],"outputFields":["*"]}`,
+		},
+		{
+			name: "update",
+			ast: &types.VectorAST{
+				Operation: types.OpUpdate,
+				Target:    types.Collection{Name: "products"},
+				IDs:       []types.Param{{Name: "id"}},
+				Updates:   map[types.MetadataField]types.Param{{Name: "price"}: {Name: "price"}},
+			},
+			expected: `{"collectionName":"products","data":[{"id":":id","price":":price"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderer.Render(tt.ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.JSON != tt.expected {
+				t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEndpointRESTv2(t *testing.T) {
+	renderer := NewRESTv2()
+
+	tests := []struct {
+		op   types.Operation
+		path string
+	}{
+		{types.OpSearch, "/v2/vectordb/entities/search"},
+		{types.OpUpsert, "/v2/vectordb/entities/upsert"},
+		{types.OpUpdate, "/v2/vectordb/entities/upsert"},
+		{types.OpDelete, "/v2/vectordb/entities/delete"},
+		{types.OpFetch, "/v2/vectordb/entities/get"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.op), func(t *testing.T) {
+			ep, err := renderer.Endpoint(&types.VectorAST{Operation: tt.op})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ep.Method != "POST" || ep.Path != tt.path {
+				t.Errorf("expected POST %s, got %s %s", tt.path, ep.Method, ep.Path)
+			}
+		})
+	}
+}
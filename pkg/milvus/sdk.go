@@ -0,0 +1,368 @@
+package milvus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// The call types below mirror the arguments of the milvus-sdk-go client
+// methods in plain Go types. This package does not depend on the SDK, so
+// callers convert vectors and columns to SDK entity types themselves.
+
+// SearchCall holds the arguments of Client.Search.
+type SearchCall struct {
+	CollectionName   string
+	PartitionNames   []string
+	Expr             string
+	OutputFields     []string
+	Vectors          [][]float32
+	VectorField      string
+	TopK             int
+	ConsistencyLevel string
+}
+
+// QueryCall holds the arguments of Client.Query, for fetches. Limit is zero
+// for fetches by ID.
+type QueryCall struct {
+	CollectionName   string
+	PartitionNames   []string
+	Expr             string
+	OutputFields     []string
+	Limit            int
+	ConsistencyLevel string
+}
+
+// DeleteCall holds the arguments of Client.Delete.
+type DeleteCall struct {
+	CollectionName string
+	PartitionName  string
+	Expr           string
+}
+
+// UpsertCall holds the arguments of Client.Upsert, for upserts and updates.
+type UpsertCall struct {
+	CollectionName string
+	PartitionName  string
+
+	// Columns holds one column per field, the primary key first and the
+	// rest sorted by name.
+	Columns []Column
+}
+
+// ColumnType names the entity column a Column becomes.
+type ColumnType string
+
+// Column types.
+const (
+	ColumnVarChar     ColumnType = "VarChar"
+	ColumnInt64       ColumnType = "Int64"
+	ColumnDouble      ColumnType = "Double"
+	ColumnBool        ColumnType = "Bool"
+	ColumnFloatVector ColumnType = "FloatVector"
+	ColumnJSON        ColumnType = "JSON"
+)
+
+// Column holds the values of one field across the rows of an upsert. The
+// slice matching Type is set; build the entity column from it, e.g.
+// entity.NewColumnVarChar(c.Name, c.VarChars) or
+// entity.NewColumnFloatVector(c.Name, c.Dim, c.FloatVectors).
+type Column struct {
+	Name string
+	Type ColumnType
+
+	// Dim is the dimension of a FloatVector column.
+	Dim int
+
+	VarChars     []string
+	Int64s       []int64
+	Doubles      []float64
+	Bools        []bool
+	FloatVectors [][]float32
+
+	// JSON holds the encoded values of a JSON column.
+	JSON [][]byte
+}
+
+// SDKSearch decodes a bound ModeSDK search body into a SearchCall holding
+// the arguments of the milvus-sdk-go Client.Search call. The vectors are
+// plain float32 slices for the caller to wrap as entity vectors:
+//
+//	result, _ := query.Render(milvus.New())
+//	body, _ := vectql.Bind(result, params)
+//	call, _ := milvus.SDKSearch(body)
+//	vectors := make([]entity.Vector, len(call.Vectors))
+//	for i, v := range call.Vectors {
+//	    vectors[i] = entity.FloatVector(v)
+//	}
+//	results, err := c.Search(ctx, call.CollectionName, call.PartitionNames, call.Expr,
+//	    call.OutputFields, vectors, call.VectorField, metricType, call.TopK, searchParam)
+//
+// Expr is the boolean expression string with the bound values written in.
+// The metric type and search parameters belong to the index, not the query,
+// and are left to the caller.
+func SDKSearch(body string) (*SearchCall, error) {
+	m, err := decodeSDKBody(body)
+	if err != nil {
+		return nil, err
+	}
+	call := &SearchCall{
+		CollectionName:   stringValue(m["collection_name"]),
+		PartitionNames:   stringList(m["partition_names"]),
+		Expr:             stringValue(m["filter"]),
+		OutputFields:     stringList(m["output_fields"]),
+		VectorField:      stringValue(m["anns_field"]),
+		ConsistencyLevel: stringValue(m["consistency_level"]),
+	}
+	if call.TopK, err = intValue(m["limit"]); err != nil {
+		return nil, fmt.Errorf("invalid limit: %w", err)
+	}
+	// A bound query vector parameter holds one vector, a literal a list
+	data, _ := m["data"].([]interface{})
+	if len(data) > 0 {
+		if _, nested := data[0].([]interface{}); !nested {
+			data = []interface{}{data}
+		}
+	}
+	for i, v := range data {
+		vector, ok := floatVector(v)
+		if !ok {
+			return nil, fmt.Errorf("query vector %d is not a list of numbers", i)
+		}
+		call.Vectors = append(call.Vectors, vector)
+	}
+	return call, nil
+}
+
+// SDKQuery decodes a bound ModeSDK fetch body into the arguments of
+// Client.Query.
+func SDKQuery(body string) (*QueryCall, error) {
+	m, err := decodeSDKBody(body)
+	if err != nil {
+		return nil, err
+	}
+	call := &QueryCall{
+		CollectionName:   stringValue(m["collection_name"]),
+		PartitionNames:   stringList(m["partition_names"]),
+		Expr:             stringValue(m["filter"]),
+		OutputFields:     stringList(m["output_fields"]),
+		ConsistencyLevel: stringValue(m["consistency_level"]),
+	}
+	if call.Limit, err = intValue(m["limit"]); err != nil {
+		return nil, fmt.Errorf("invalid limit: %w", err)
+	}
+	return call, nil
+}
+
+// SDKDelete decodes a bound ModeSDK delete body into the arguments of
+// Client.Delete.
+func SDKDelete(body string) (*DeleteCall, error) {
+	m, err := decodeSDKBody(body)
+	if err != nil {
+		return nil, err
+	}
+	return &DeleteCall{
+		CollectionName: stringValue(m["collection_name"]),
+		PartitionName:  stringValue(m["partition_name"]),
+		Expr:           stringValue(m["filter"]),
+	}, nil
+}
+
+// SDKUpsert decodes a bound ModeSDK upsert or update body into the columns
+// of Client.Upsert. Metadata columns take their type from the schema types
+// recorded in result, the rendered query the body was bound from. The
+// primary key is a VarChar or Int64 column as bound, and vector fields are
+// FloatVector columns. Untyped fields become JSON columns; array fields are
+// not supported.
+func SDKUpsert(result *types.QueryResult, body string) (*UpsertCall, error) {
+	m, err := decodeSDKBody(body)
+	if err != nil {
+		return nil, err
+	}
+	rows, ok := m["data"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("body has no data rows")
+	}
+
+	fieldTypes := make(map[string]string, len(result.Fields))
+	for _, f := range result.Fields {
+		fieldTypes[f.Field.Name] = f.Field.Type
+	}
+
+	var names []string
+	values := make(map[string][]interface{})
+	for i, r := range rows {
+		row, ok := r.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("data row %d is not an object", i)
+		}
+		if i == 0 {
+			for name := range row {
+				names = append(names, name)
+			}
+		} else if len(row) != len(names) {
+			return nil, fmt.Errorf("data row %d has different fields than row 0", i)
+		}
+		for _, name := range names {
+			v, ok := row[name]
+			if !ok {
+				return nil, fmt.Errorf("data row %d has no value for %s", i, name)
+			}
+			values[name] = append(values[name], v)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == "id") != (names[j] == "id") {
+			return names[i] == "id"
+		}
+		return names[i] < names[j]
+	})
+
+	call := &UpsertCall{
+		CollectionName: stringValue(m["collection_name"]),
+		PartitionName:  stringValue(m["partition_name"]),
+	}
+	for _, name := range names {
+		column, err := buildColumn(name, fieldTypes[name], values[name])
+		if err != nil {
+			return nil, err
+		}
+		call.Columns = append(call.Columns, column)
+	}
+	return call, nil
+}
+
+// buildColumn types the values of one field.
+func buildColumn(name, fieldType string, values []interface{}) (Column, error) {
+	column := Column{Name: name}
+	if strings.HasPrefix(fieldType, "[]") {
+		return column, fmt.Errorf("array field %s has no SDK column mapping", name)
+	}
+	switch {
+	case name == "id":
+		if _, ok := values[0].(string); ok {
+			fieldType = "string"
+		} else {
+			fieldType = "int"
+		}
+	case fieldType == "":
+		if _, ok := floatVector(values[0]); ok {
+			column.Type = ColumnFloatVector
+		}
+	}
+
+	for i, v := range values {
+		var ok bool
+		switch {
+		case column.Type == ColumnFloatVector:
+			var vector []float32
+			if vector, ok = floatVector(v); ok {
+				if i == 0 {
+					column.Dim = len(vector)
+				}
+				ok = len(vector) == column.Dim
+				column.FloatVectors = append(column.FloatVectors, vector)
+			}
+		case fieldType == "string":
+			var s string
+			s, ok = v.(string)
+			column.Type = ColumnVarChar
+			column.VarChars = append(column.VarChars, s)
+		case fieldType == "int":
+			n, err := intValue(v)
+			ok = err == nil
+			column.Type = ColumnInt64
+			column.Int64s = append(column.Int64s, int64(n))
+		case fieldType == "float":
+			var f float64
+			f, ok = floatValue(v)
+			column.Type = ColumnDouble
+			column.Doubles = append(column.Doubles, f)
+		case fieldType == "bool":
+			var b bool
+			b, ok = v.(bool)
+			column.Type = ColumnBool
+			column.Bools = append(column.Bools, b)
+		default:
+			data, err := json.Marshal(v)
+			ok = err == nil
+			column.Type = ColumnJSON
+			column.JSON = append(column.JSON, data)
+		}
+		if !ok {
+			return column, fmt.Errorf("value %d of %s does not fit a %s column", i, name, column.Type)
+		}
+	}
+	return column, nil
+}
+
+func decodeSDKBody(body string) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(body)))
+	dec.UseNumber()
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("failed to decode body: %w", err)
+	}
+	return m, nil
+}
+
+func stringValue(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func stringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	if len(list) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		out = append(out, stringValue(item))
+	}
+	return out
+}
+
+func intValue(v interface{}) (int, error) {
+	if v == nil {
+		return 0, nil
+	}
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("not a number: %v", v)
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return 0, err
+	}
+	return int(i), nil
+}
+
+func floatValue(v interface{}) (float64, bool) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	f, err := n.Float64()
+	return f, err == nil
+}
+
+func floatVector(v interface{}) ([]float32, bool) {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	vector := make([]float32, len(list))
+	for i, item := range list {
+		f, ok := floatValue(item)
+		if !ok {
+			return nil, false
+		}
+		vector[i] = float32(f)
+	}
+	return vector, true
+}
@@ -0,0 +1,148 @@
+package milvus
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestSDKSearch(t *testing.T) {
+	body := `{"collection_name":"products","anns_field":"embedding","data":[0.1,0.2,0.3],` +
+		`"limit":10,"filter":"category == \"shoes\"","output_fields":["category"],` +
+		`"partition_names":["tenant_a"],"consistency_level":"Strong"}`
+
+	call, err := SDKSearch(body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if call.CollectionName != "products" || call.VectorField != "embedding" || call.TopK != 10 {
+		t.Errorf("unexpected call: %+v", call)
+	}
+	if call.Expr != `category == "shoes"` {
+		t.Errorf("unexpected expression: %s", call.Expr)
+	}
+	if len(call.Vectors) != 1 || len(call.Vectors[0]) != 3 || call.Vectors[0][1] != 0.2 {
+		t.Errorf("expected one bound vector, got %v", call.Vectors)
+	}
+	if len(call.OutputFields) != 1 || len(call.PartitionNames) != 1 || call.ConsistencyLevel != "Strong" {
+		t.Errorf("unexpected call: %+v", call)
+	}
+}
+
+func TestSDKSearch_LiteralVectors(t *testing.T) {
+	call, err := SDKSearch(`{"collection_name":"products","anns_field":"embedding","data":[[1,0],[0,1]],"limit":5}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(call.Vectors) != 2 || call.Vectors[1][1] != 1 {
+		t.Errorf("expected two vectors, got %v", call.Vectors)
+	}
+
+	_, err = SDKSearch(`{"collection_name":"products","data":[["a"]],"limit":5}`)
+	if err == nil || !strings.Contains(err.Error(), "not a list of numbers") {
+		t.Errorf("expected a vector error, got %v", err)
+	}
+	_, err = SDKSearch(`{"collection_name":"products","limit":"ten"}`)
+	if err == nil || !strings.Contains(err.Error(), "invalid limit") {
+		t.Errorf("expected a limit error, got %v", err)
+	}
+}
+
+func TestSDKQueryAndDelete(t *testing.T) {
+	query, err := SDKQuery(`{"collection_name":"products","filter":"sku in [\"a\", \"b\"]","limit":2,"output_fields":["*"]}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query.Expr != `sku in ["a", "b"]` || query.Limit != 2 || query.OutputFields[0] != "*" {
+		t.Errorf("unexpected query call: %+v", query)
+	}
+
+	del, err := SDKDelete(`{"collection_name":"products","filter":"id in [1, 2]","partition_name":"tenant_a"}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if del.CollectionName != "products" || del.Expr != "id in [1, 2]" || del.PartitionName != "tenant_a" {
+		t.Errorf("unexpected delete call: %+v", del)
+	}
+
+	if _, err := SDKDelete(`not json`); err == nil {
+		t.Error("expected a decode error")
+	}
+}
+
+func TestSDKUpsert(t *testing.T) {
+	result := &types.QueryResult{Fields: []types.FieldParam{
+		{Param: "cat1", Field: types.MetadataField{Name: "category", Type: "string"}},
+		{Param: "price1", Field: types.MetadataField{Name: "price", Type: "float"}},
+	}}
+	body := `{"collection_name":"products","partition_name":"tenant_a","data":[` +
+		`{"id":"a","embedding":[0.1,0.2],"category":"shoes","price":10,"extra":{"k":1}},` +
+		`{"id":"b","embedding":[0.3,0.4],"category":"hats","price":2.5,"extra":null}]}`
+
+	call, err := SDKUpsert(result, body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if call.CollectionName != "products" || call.PartitionName != "tenant_a" {
+		t.Errorf("unexpected call: %+v", call)
+	}
+
+	want := []struct {
+		name string
+		typ  ColumnType
+	}{
+		{"id", ColumnVarChar},
+		{"category", ColumnVarChar},
+		{"embedding", ColumnFloatVector},
+		{"extra", ColumnJSON},
+		{"price", ColumnDouble},
+	}
+	if len(call.Columns) != len(want) {
+		t.Fatalf("expected %d columns, got %+v", len(want), call.Columns)
+	}
+	for i, w := range want {
+		if c := call.Columns[i]; c.Name != w.name || c.Type != w.typ {
+			t.Errorf("column %d: expected %s %s, got %s %s", i, w.name, w.typ, c.Name, c.Type)
+		}
+	}
+	if ids := call.Columns[0].VarChars; len(ids) != 2 || ids[1] != "b" {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+	if c := call.Columns[2]; c.Dim != 2 || len(c.FloatVectors) != 2 {
+		t.Errorf("unexpected vector column: %+v", c)
+	}
+	if c := call.Columns[3]; string(c.JSON[0]) != `{"k":1}` || string(c.JSON[1]) != "null" {
+		t.Errorf("unexpected JSON column: %+v", c)
+	}
+	if prices := call.Columns[4].Doubles; prices[0] != 10 || prices[1] != 2.5 {
+		t.Errorf("unexpected prices: %v", prices)
+	}
+}
+
+func TestSDKUpsert_Errors(t *testing.T) {
+	result := &types.QueryResult{Fields: []types.FieldParam{
+		{Param: "tags1", Field: types.MetadataField{Name: "tags", Type: "[]string"}},
+		{Param: "stock1", Field: types.MetadataField{Name: "stock", Type: "int"}},
+	}}
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"no rows", `{"collection_name":"products"}`, "no data rows"},
+		{"array field", `{"data":[{"id":1,"tags":["a"]}]}`, "array field tags"},
+		{"wrong type", `{"data":[{"id":1,"stock":"many"}]}`, "does not fit a Int64 column"},
+		{"ragged vectors", `{"data":[{"id":1,"embedding":[1,2]},{"id":2,"embedding":[1]}]}`, "does not fit a FloatVector column"},
+		{"missing field", `{"data":[{"id":1,"stock":1},{"id":2,"other":1}]}`, "has no value for"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SDKUpsert(result, tt.body)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,461 @@
+// Package mongoatlas provides a VECTQL renderer for MongoDB Atlas Vector
+// Search.
+package mongoatlas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/zoobzio/vectql/internal/filtertree"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// fallbackVectorField is the vector field used when neither the query nor
+// the schema names one.
+const fallbackVectorField = "embedding"
+
+// DefaultIndex is the Atlas Vector Search index searched when the renderer
+// does not name one.
+const DefaultIndex = "vector_index"
+
+// DefaultCandidateFactor is the number of candidates per requested result
+// the search considers when the renderer does not set one.
+const DefaultCandidateFactor = 10
+
+// maxNumCandidates is the largest numCandidates Atlas accepts.
+const maxNumCandidates = 10000
+
+// scoreField is the field search results carry their score in.
+const scoreField = "score"
+
+// toResult serializes a command to JSON and returns a QueryResult.
+func toResult(command interface{}, params []string) (*types.QueryResult, error) {
+	jsonBytes, err := json.Marshal(command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize query: %w", err)
+	}
+	return &types.QueryResult{
+		JSON:           string(jsonBytes),
+		RequiredParams: params,
+	}, nil
+}
+
+// Renderer renders VectorAST to MongoDB database commands, to be sent with
+// a driver's RunCommand against the collection's database. Searches are
+// aggregate commands whose pipeline starts with $vectorSearch; writes are
+// update and delete commands keyed by _id, and fetches are find commands.
+// Metadata fields are top-level document fields next to the vector field.
+type Renderer struct {
+	// Index is the Atlas Vector Search index name. Empty uses DefaultIndex.
+	Index string
+
+	// CandidateFactor sets numCandidates to CandidateFactor x limit, capped
+	// at 10000. Zero uses DefaultCandidateFactor.
+	CandidateFactor int
+}
+
+// New creates a new MongoDB Atlas renderer.
+func New() *Renderer {
+	return &Renderer{}
+}
+
+// vectorField returns the vector field a query targets.
+func vectorField(ast *types.VectorAST) string {
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
+		return ast.QueryEmbedding.Name
+	}
+	if ast.Target.DefaultEmbedding != "" {
+		return ast.Target.DefaultEmbedding
+	}
+	return fallbackVectorField
+}
+
+// Render converts a VectorAST to a MongoDB database command.
+func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	if err := ast.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid AST: %w", err)
+	}
+
+	var params []string
+
+	switch ast.Operation {
+	case types.OpSearch:
+		return r.renderSearch(ast, &params)
+	case types.OpUpsert:
+		return r.renderUpsert(ast, &params)
+	case types.OpDelete:
+		return r.renderDelete(ast, &params)
+	case types.OpFetch:
+		return r.renderFetch(ast, &params)
+	case types.OpUpdate:
+		return r.renderUpdate(ast, &params)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
+
+func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("mongoatlas does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("mongoatlas"); err != nil {
+		return nil, err
+	}
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("mongoatlas does not support quantization search parameters")
+	}
+	// numCandidates is required and derived from the limit
+	if ast.TopK.Static == nil {
+		return nil, fmt.Errorf("mongoatlas requires a static top k to set numCandidates")
+	}
+
+	field := vectorField(ast)
+	index := r.Index
+	if index == "" {
+		index = DefaultIndex
+	}
+	k := *ast.TopK.Static
+	search := map[string]interface{}{
+		"index":         index,
+		"path":          field,
+		"limit":         k,
+		"numCandidates": r.numCandidates(k),
+	}
+
+	// Vector
+	if ast.QueryVector.Param != nil {
+		*params = append(*params, ast.QueryVector.Param.Name)
+		search["queryVector"] = fmt.Sprintf(":%s", ast.QueryVector.Param.Name)
+	} else {
+		search["queryVector"] = ast.QueryVector.Literal
+	}
+
+	// Pre-filter on fields indexed as filter fields
+	if ast.FilterClause != nil {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		search["filter"] = filter
+	}
+
+	pipeline := []interface{}{
+		map[string]interface{}{"$vectorSearch": search},
+		map[string]interface{}{"$addFields": map[string]interface{}{
+			scoreField: map[string]interface{}{"$meta": "vectorSearchScore"},
+		}},
+	}
+
+	// Score threshold
+	if ast.MinScore != nil {
+		*params = append(*params, ast.MinScore.Name)
+		pipeline = append(pipeline, map[string]interface{}{
+			"$match": map[string]interface{}{
+				scoreField: map[string]interface{}{"$gte": fmt.Sprintf(":%s", ast.MinScore.Name)},
+			},
+		})
+	}
+
+	if projection := project(ast, field, true); projection != nil {
+		pipeline = append(pipeline, map[string]interface{}{"$project": projection})
+	}
+
+	result, err := toResult(map[string]interface{}{
+		"aggregate": ast.Target.Name,
+		"pipeline":  pipeline,
+		"cursor":    map[string]interface{}{},
+	}, *params)
+	if err != nil {
+		return nil, err
+	}
+	// Search indexes are synchronized asynchronously from the collection
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	// $vectorSearch scores have no adjustment
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+// numCandidates returns the candidate count for limit results.
+func (r *Renderer) numCandidates(limit int) int {
+	factor := r.CandidateFactor
+	if factor <= 0 {
+		factor = DefaultCandidateFactor
+	}
+	if n := limit * factor; n < maxNumCandidates {
+		return n
+	}
+	if limit > maxNumCandidates {
+		return limit
+	}
+	return maxNumCandidates
+}
+
+// project returns the projection that returns the requested metadata and
+// the vector field, or nil to return whole documents. withScore keeps the
+// search score in inclusion projections.
+func project(ast *types.VectorAST, vectorField string, withScore bool) map[string]interface{} {
+	include := func(fields ...string) map[string]interface{} {
+		projection := make(map[string]interface{}, len(fields)+1)
+		for _, f := range fields {
+			projection[f] = 1
+		}
+		if withScore {
+			projection[scoreField] = 1
+		}
+		return projection
+	}
+
+	switch {
+	case !ast.IncludeMetadata && !ast.IncludeVectors:
+		return include()
+	case !ast.IncludeMetadata:
+		return include(vectorField)
+	case len(ast.MetadataFields) > 0:
+		fields := make([]string, 0, len(ast.MetadataFields)+1)
+		for _, f := range ast.MetadataFields {
+			fields = append(fields, f.Name)
+		}
+		if ast.IncludeVectors {
+			fields = append(fields, vectorField)
+		}
+		return include(fields...)
+	case !ast.IncludeVectors:
+		return map[string]interface{}{vectorField: 0}
+	default:
+		return nil
+	}
+}
+
+func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	field := vectorField(ast)
+	updates := make([]interface{}, 0, len(ast.Vectors))
+
+	for _, record := range ast.Vectors {
+		if record.SparseVector != nil {
+			return nil, fmt.Errorf("mongoatlas does not support sparse vectors")
+		}
+
+		*params = append(*params, record.ID.Name)
+		id := fmt.Sprintf(":%s", record.ID.Name)
+
+		// Replacement document; the _id comes from the query
+		doc := make(map[string]interface{}, len(record.Metadata)+1)
+		if record.Vector.Param != nil {
+			*params = append(*params, record.Vector.Param.Name)
+			doc[field] = fmt.Sprintf(":%s", record.Vector.Param.Name)
+		} else {
+			doc[field] = record.Vector.Literal
+		}
+		for f, value := range record.Metadata {
+			*params = append(*params, value.Name)
+			doc[f.Name] = fmt.Sprintf(":%s", value.Name)
+		}
+
+		updates = append(updates, map[string]interface{}{
+			"q":      map[string]interface{}{"_id": id},
+			"u":      doc,
+			"upsert": true,
+		})
+	}
+
+	return toResult(map[string]interface{}{
+		"update":  ast.Target.Name,
+		"updates": updates,
+	}, *params)
+}
+
+func (r *Renderer) renderDelete(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	var query interface{}
+	if len(ast.IDs) > 0 {
+		query = idsQuery(ast.IDs, params)
+	} else {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		query = filter
+	}
+
+	return toResult(map[string]interface{}{
+		"delete": ast.Target.Name,
+		// A limit of 0 deletes every matching document
+		"deletes": []interface{}{map[string]interface{}{"q": query, "limit": 0}},
+	}, *params)
+}
+
+func (r *Renderer) renderFetch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	command := map[string]interface{}{
+		"find":   ast.Target.Name,
+		"filter": idsQuery(ast.IDs, params),
+	}
+	if projection := project(ast, vectorField(ast), false); projection != nil {
+		command["projection"] = projection
+	}
+
+	result, err := toResult(command, *params)
+	if err != nil {
+		return nil, err
+	}
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	return result, nil
+}
+
+func (r *Renderer) renderUpdate(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	updates := make([]interface{}, 0, len(ast.IDs))
+	for _, id := range ast.IDs {
+		*params = append(*params, id.Name)
+
+		// An update pipeline; $literal keeps bound values from being read
+		// as field paths or expressions
+		set := make(map[string]interface{}, len(ast.Updates))
+		for field, value := range ast.Updates {
+			*params = append(*params, value.Name)
+			set[field.Name] = map[string]interface{}{"$literal": fmt.Sprintf(":%s", value.Name)}
+		}
+
+		updates = append(updates, map[string]interface{}{
+			"q": map[string]interface{}{"_id": fmt.Sprintf(":%s", id.Name)},
+			"u": []interface{}{map[string]interface{}{"$set": set}},
+		})
+	}
+
+	return toResult(map[string]interface{}{
+		"update":  ast.Target.Name,
+		"updates": updates,
+	}, *params)
+}
+
+// idsQuery matches documents by _id.
+func idsQuery(ids []types.Param, params *[]string) map[string]interface{} {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		*params = append(*params, id.Name)
+		values[i] = fmt.Sprintf(":%s", id.Name)
+	}
+	return map[string]interface{}{"_id": map[string]interface{}{"$in": values}}
+}
+
+func (r *Renderer) renderFilter(f types.FilterItem, params *[]string) (interface{}, error) {
+	return filtertree.New(r.dialect(), params).Compile(f)
+}
+
+// dialect returns the filtertree dialect for MQL query operators. NOT
+// groups become $nor, matching documents that satisfy none of the clauses.
+func (r *Renderer) dialect() *filtertree.Dialect {
+	return &filtertree.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			if !r.SupportsFilter(op) {
+				return "", false
+			}
+			return mapOperator(op), true
+		},
+		Logic: func(logic types.LogicOperator) string {
+			switch logic {
+			case types.OR:
+				return "$or"
+			case types.NOT:
+				return "$nor"
+			default:
+				return "$and"
+			}
+		},
+		Condition: filtertree.NestedCondition,
+		Group:     filtertree.NestedGroup,
+		Range:     filtertree.NestedRange,
+	}
+}
+
+func mapOperator(op types.FilterOperator) string {
+	switch op {
+	case types.NE:
+		return "$ne"
+	case types.GT:
+		return "$gt"
+	case types.GE:
+		return "$gte"
+	case types.LT:
+		return "$lt"
+	case types.LE:
+		return "$lte"
+	case types.IN, types.ArrayContainsAny:
+		return "$in"
+	case types.NotIn:
+		return "$nin"
+	default:
+		// EQ, and ArrayContains: $eq on an array field matches any element
+		return "$eq"
+	}
+}
+
+// SupportsOperation indicates if MongoDB Atlas supports an operation.
+func (r *Renderer) SupportsOperation(op types.Operation) bool {
+	switch op {
+	case types.OpSearch, types.OpUpsert, types.OpDelete, types.OpFetch, types.OpUpdate:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFilter indicates if MongoDB Atlas supports a filter operator.
+func (r *Renderer) SupportsFilter(op types.FilterOperator) bool {
+	switch op {
+	case types.EQ, types.NE, types.GT, types.GE, types.LT, types.LE, types.IN, types.NotIn,
+		types.ArrayContains, types.ArrayContainsAny:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsMetric indicates if MongoDB Atlas supports a distance metric, as
+// the cosine, euclidean, and dotProduct similarity functions.
+func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
+	switch metric {
+	case types.Cosine, types.Euclidean, types.DotProduct:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsModality indicates if MongoDB Atlas accepts a search input
+// modality. Only vector input is rendered.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	return m == types.ModalityVector
+}
+
+// Capabilities describes the features supported by MongoDB Atlas.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("mongoatlas", r)
+}
+
+// RenderTo writes the MongoDB command for ast to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// Format reports that MongoDB commands are written as JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// Endpoint returns ErrNoEndpoint: commands are sent through a MongoDB
+// driver, not a REST API.
+func (r *Renderer) Endpoint(_ *types.VectorAST) (types.Endpoint, error) {
+	return types.Endpoint{}, types.ErrNoEndpoint
+}
@@ -0,0 +1,151 @@
+package mongoatlas
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderSearch(t *testing.T) {
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products", DefaultEmbedding: "plot_embedding"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "category"}, Operator: types.IN, Value: types.Param{Name: "cats"}},
+			types.FilterCondition{Field: types.MetadataField{Name: "year"}, Operator: types.GE, Value: types.Param{Name: "year"}},
+		}},
+		MinScore:        &types.Param{Name: "min_score"},
+		IncludeMetadata: true,
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"aggregate":"products","cursor":{},"pipeline":[` +
+		`{"$vectorSearch":{"filter":{"$and":[{"category":{"$in":":cats"}},{"year":{"$gte":":year"}}]},` +
+		`"index":"vector_index","limit":10,"numCandidates":100,"path":"plot_embedding","queryVector":":query_vec"}},` +
+		`{"$addFields":{"score":{"$meta":"vectorSearchScore"}}},` +
+		`{"$match":{"score":{"$gte":":min_score"}}},` +
+		`{"$project":{"plot_embedding":0}}]}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if len(result.RequiredParams) != 4 {
+		t.Errorf("expected 4 params, got %v", result.RequiredParams)
+	}
+
+	paramK := &types.PaginationValue{Param: &types.Param{Name: "k"}}
+	ast.TopK = paramK
+	if _, err := New().Render(ast); err == nil {
+		t.Error("expected an error for a parameterized top k")
+	}
+}
+
+func TestRenderSearchProjection(t *testing.T) {
+	topK := 5
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "v"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterGroup{Logic: types.NOT, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "status"}, Operator: types.EQ, Value: types.Param{Name: "s"}},
+		}},
+	}
+
+	result, err := (&Renderer{Index: "products_idx", CandidateFactor: 50}).Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"aggregate":"products","cursor":{},"pipeline":[` +
+		`{"$vectorSearch":{"filter":{"$nor":[{"status":{"$eq":":s"}}]},"index":"products_idx","limit":5,"numCandidates":250,"path":"embedding","queryVector":":v"}},` +
+		`{"$addFields":{"score":{"$meta":"vectorSearchScore"}}},` +
+		`{"$project":{"score":1}}]}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+}
+
+func TestRenderWrites(t *testing.T) {
+	tests := []struct {
+		name     string
+		ast      *types.VectorAST
+		expected string
+	}{
+		{
+			"upsert",
+			&types.VectorAST{
+				Operation: types.OpUpsert,
+				Target:    types.Collection{Name: "products"},
+				Vectors: []types.VectorRecord{{
+					ID:       types.Param{Name: "id"},
+					Vector:   types.VectorValue{Param: &types.Param{Name: "v"}},
+					Metadata: map[types.MetadataField]types.Param{{Name: "category"}: {Name: "cat"}},
+				}},
+			},
+			`{"update":"products","updates":[{"q":{"_id":":id"},"u":{"category":":cat","embedding":":v"},"upsert":true}]}`,
+		},
+		{
+			"update",
+			&types.VectorAST{
+				Operation: types.OpUpdate,
+				Target:    types.Collection{Name: "products"},
+				IDs:       []types.Param{{Name: "id"}},
+				Updates:   map[types.MetadataField]types.Param{{Name: "price"}: {Name: "price"}},
+			},
+			`{"update":"products","updates":[{"q":{"_id":":id"},"u":[{"$set":{"price":{"$literal":":price"}}}]}]}`,
+		},
+		{
+			"delete by id",
+			&types.VectorAST{
+				Operation: types.OpDelete,
+				Target:    types.Collection{Name: "products"},
+				IDs:       []types.Param{{Name: "a"}, {Name: "b"}},
+			},
+			`{"delete":"products","deletes":[{"limit":0,"q":{"_id":{"$in":[":a",":b"]}}}]}`,
+		},
+		{
+			"delete by filter",
+			&types.VectorAST{
+				Operation:    types.OpDelete,
+				Target:       types.Collection{Name: "products"},
+				FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "tenant"}, Operator: types.NotIn, Value: types.Param{Name: "t"}},
+				DeleteAll:    true,
+			},
+			`{"delete":"products","deletes":[{"limit":0,"q":{"tenant":{"$nin":":t"}}}]}`,
+		},
+		{
+			"fetch",
+			&types.VectorAST{
+				Operation:       types.OpFetch,
+				Target:          types.Collection{Name: "products"},
+				IDs:             []types.Param{{Name: "a"}},
+				IncludeMetadata: true,
+				IncludeVectors:  true,
+			},
+			`{"filter":{"_id":{"$in":[":a"]}},"find":"products"}`,
+		},
+	}
+	for _, tt := range tests {
+		result, err := New().Render(tt.ast)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if result.JSON != tt.expected {
+			t.Errorf("%s: expected:\n%s\ngot:\n%s", tt.name, tt.expected, result.JSON)
+		}
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	ast := &types.VectorAST{Operation: types.OpFetch, Target: types.Collection{Name: "products"}}
+	if _, err := New().Endpoint(ast); !errors.Is(err, types.ErrNoEndpoint) {
+		t.Errorf("expected ErrNoEndpoint, got %v", err)
+	}
+}
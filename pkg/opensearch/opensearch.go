@@ -0,0 +1,387 @@
+// Package opensearch provides a VECTQL renderer for OpenSearch k-NN
+// indices, including neural search.
+package opensearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"slices"
+
+	"github.com/zoobzio/vectql/internal/filtertree"
+	"github.com/zoobzio/vectql/internal/querydsl"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// fallbackVectorField is the knn_vector field used when neither the query
+// nor the schema names one.
+const fallbackVectorField = "embedding"
+
+// toResult serializes a query to JSON and returns a QueryResult.
+func toResult(query interface{}, params []string) (*types.QueryResult, error) {
+	jsonBytes, err := json.Marshal(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize query: %w", err)
+	}
+	return &types.QueryResult{
+		JSON:           string(jsonBytes),
+		RequiredParams: params,
+	}, nil
+}
+
+// Renderer renders VectorAST to OpenSearch request bodies. Vector searches
+// use the k-NN plugin's knn query; NearText and NearImage searches use the
+// neural search plugin's neural query, which embeds the input with a
+// deployed model. Writes use the bulk API, whose bodies render as a JSON
+// array of lines (see types.Endpoint.Lines).
+type Renderer struct {
+	// ModelID is the deployed model neural queries embed their input with.
+	// Empty relies on the index's default model from the
+	// neural_query_enricher search pipeline.
+	ModelID string
+
+	// Refresh is sent as the refresh parameter of writes: "true" or
+	// "wait_for" make them visible to the next search.
+	Refresh string
+
+	// FieldNames maps schema field names to the index's mapped fields,
+	// e.g. a Prefix of "attributes." for metadata kept in an object field.
+	FieldNames types.FieldNames
+}
+
+// PhysicalFieldNames returns the configured field mapping.
+func (r *Renderer) PhysicalFieldNames() types.FieldNames {
+	return r.FieldNames
+}
+
+// New creates a new OpenSearch renderer.
+func New() *Renderer {
+	return &Renderer{}
+}
+
+// vectorField returns the knn_vector field a query targets.
+func vectorField(ast *types.VectorAST) string {
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
+		return ast.QueryEmbedding.Name
+	}
+	if ast.Target.DefaultEmbedding != "" {
+		return ast.Target.DefaultEmbedding
+	}
+	return fallbackVectorField
+}
+
+// Render converts a VectorAST to an OpenSearch request body.
+func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	if err := ast.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid AST: %w", err)
+	}
+
+	var params []string
+
+	switch ast.Operation {
+	case types.OpSearch:
+		return r.renderSearch(ast, &params)
+	case types.OpUpsert:
+		return r.renderUpsert(ast, &params)
+	case types.OpDelete:
+		return r.renderDelete(ast, &params)
+	case types.OpFetch:
+		return r.renderFetch(ast, &params)
+	case types.OpUpdate:
+		return r.renderUpdate(ast, &params)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
+
+func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("opensearch does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("opensearch"); err != nil {
+		return nil, err
+	}
+	// Quantization and rescoring are set on the knn_vector mapping
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("opensearch does not support quantization search parameters")
+	}
+
+	field := vectorField(ast)
+	clause := make(map[string]interface{})
+	kind := "knn"
+
+	// Search input
+	switch ast.Modality() {
+	case types.ModalityText:
+		kind = "neural"
+		*params = append(*params, ast.NearText.Concepts.Name)
+		clause["query_text"] = fmt.Sprintf(":%s", ast.NearText.Concepts.Name)
+	case types.ModalityImage:
+		kind = "neural"
+		*params = append(*params, ast.NearImage.Image.Name)
+		clause["query_image"] = fmt.Sprintf(":%s", ast.NearImage.Image.Name)
+	default:
+		if ast.QueryVector.Param != nil {
+			*params = append(*params, ast.QueryVector.Param.Name)
+			clause["vector"] = fmt.Sprintf(":%s", ast.QueryVector.Param.Name)
+		} else {
+			clause["vector"] = ast.QueryVector.Literal
+		}
+	}
+	if kind == "neural" && r.ModelID != "" {
+		clause["model_id"] = r.ModelID
+	}
+
+	// k, also used as the page size
+	query := make(map[string]interface{})
+	if ast.TopK.Static != nil {
+		clause["k"] = *ast.TopK.Static
+		query["size"] = *ast.TopK.Static
+	} else {
+		*params = append(*params, ast.TopK.Param.Name)
+		k := fmt.Sprintf(":%s", ast.TopK.Param.Name)
+		clause["k"] = k
+		query["size"] = k
+	}
+
+	// Filter, applied during the search by the Lucene and Faiss engines
+	if ast.FilterClause != nil {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		clause["filter"] = filter
+	}
+
+	query["query"] = map[string]interface{}{kind: map[string]interface{}{field: clause}}
+
+	// Score threshold
+	if ast.MinScore != nil {
+		*params = append(*params, ast.MinScore.Name)
+		query["min_score"] = fmt.Sprintf(":%s", ast.MinScore.Name)
+	}
+
+	if source := querydsl.Source(ast, field); source != nil {
+		query["_source"] = source
+	}
+	if ast.Snapshot != nil {
+		query["pit"] = querydsl.PIT(ast.Snapshot, params)
+	}
+
+	result, err := toResult(query, *params)
+	if err != nil {
+		return nil, err
+	}
+	// Searches are near real-time; freshness is controlled on writes with Refresh
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	// Boosts are only rendered for Elasticsearch
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	field := vectorField(ast)
+	lines := make([]interface{}, 0, 2*len(ast.Vectors))
+
+	for _, record := range ast.Vectors {
+		if record.SparseVector != nil {
+			return nil, fmt.Errorf("opensearch does not support indexed sparse vectors")
+		}
+
+		*params = append(*params, record.ID.Name)
+		action := map[string]interface{}{"_id": fmt.Sprintf(":%s", record.ID.Name)}
+
+		doc := make(map[string]interface{}, len(record.Metadata)+1)
+		if record.Vector.Param != nil {
+			*params = append(*params, record.Vector.Param.Name)
+			doc[field] = fmt.Sprintf(":%s", record.Vector.Param.Name)
+		} else {
+			doc[field] = record.Vector.Literal
+		}
+		for f, value := range record.Metadata {
+			*params = append(*params, value.Name)
+			doc[f.Name] = fmt.Sprintf(":%s", value.Name)
+		}
+
+		lines = append(lines, map[string]interface{}{"index": action}, doc)
+	}
+
+	return toResult(lines, *params)
+}
+
+func (r *Renderer) renderDelete(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	// Deletes by filter go to the delete-by-query API
+	if len(ast.IDs) == 0 {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		return toResult(map[string]interface{}{"query": querydsl.Filter(filter)}, *params)
+	}
+
+	lines := make([]interface{}, len(ast.IDs))
+	for i, id := range ast.IDs {
+		*params = append(*params, id.Name)
+		lines[i] = map[string]interface{}{
+			"delete": map[string]interface{}{"_id": fmt.Sprintf(":%s", id.Name)},
+		}
+	}
+	return toResult(lines, *params)
+}
+
+func (r *Renderer) renderFetch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	ids := make([]string, len(ast.IDs))
+	for i, id := range ast.IDs {
+		*params = append(*params, id.Name)
+		ids[i] = fmt.Sprintf(":%s", id.Name)
+	}
+
+	// _mget cannot read a point in time, so pinned fetches search by ID
+	body := map[string]interface{}{"ids": ids}
+	if ast.Snapshot != nil {
+		body = map[string]interface{}{
+			"query": map[string]interface{}{"ids": map[string]interface{}{"values": ids}},
+			"size":  len(ids),
+			"pit":   querydsl.PIT(ast.Snapshot, params),
+		}
+	}
+
+	result, err := toResult(body, *params)
+	if err != nil {
+		return nil, err
+	}
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	return result, nil
+}
+
+func (r *Renderer) renderUpdate(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	lines := make([]interface{}, 0, 2*len(ast.IDs))
+	for _, id := range ast.IDs {
+		*params = append(*params, id.Name)
+		action := map[string]interface{}{"_id": fmt.Sprintf(":%s", id.Name)}
+
+		doc := make(map[string]interface{}, len(ast.Updates))
+		for field, value := range ast.Updates {
+			*params = append(*params, value.Name)
+			doc[field.Name] = fmt.Sprintf(":%s", value.Name)
+		}
+
+		lines = append(lines, map[string]interface{}{"update": action}, map[string]interface{}{"doc": doc})
+	}
+
+	return toResult(lines, *params)
+}
+
+func (r *Renderer) renderFilter(f types.FilterItem, params *[]string) (interface{}, error) {
+	return filtertree.New(querydsl.Dialect(r.SupportsFilter), params).Compile(f)
+}
+
+// SupportsOperation indicates if OpenSearch supports an operation.
+func (r *Renderer) SupportsOperation(op types.Operation) bool {
+	switch op {
+	case types.OpSearch, types.OpUpsert, types.OpDelete, types.OpFetch, types.OpUpdate:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFilter indicates if OpenSearch supports a filter operator.
+func (r *Renderer) SupportsFilter(op types.FilterOperator) bool {
+	return slices.Contains(querydsl.Operators, op)
+}
+
+// SupportsMetric indicates if OpenSearch supports a distance metric, as the
+// cosinesimil, l2, and innerproduct space types.
+func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
+	switch metric {
+	case types.Cosine, types.Euclidean, types.DotProduct:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsModality indicates if OpenSearch accepts a search input modality.
+// Text and image inputs are embedded by the neural search plugin.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	switch m {
+	case types.ModalityVector, types.ModalityText, types.ModalityImage:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsSnapshots reports that searches and fetches can be pinned to a
+// point in time opened with the OpenSearch PIT API.
+func (r *Renderer) SupportsSnapshots() bool {
+	return true
+}
+
+// SupportsContentEncoding indicates if OpenSearch accepts request bodies with a
+// Content-Encoding. Its HTTP layer decompresses gzip and deflate payloads.
+func (r *Renderer) SupportsContentEncoding(encoding string) bool {
+	return encoding == "gzip" || encoding == "deflate"
+}
+
+// Capabilities describes the features supported by OpenSearch.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("opensearch", r)
+}
+
+// RenderTo writes the OpenSearch request body for ast to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// Format reports that OpenSearch queries are JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// Endpoint returns the OpenSearch REST call for ast. Upserts, updates, and
+// deletes by ID use the index's bulk API and take newline-delimited JSON.
+func (r *Renderer) Endpoint(ast *types.VectorAST) (types.Endpoint, error) {
+	index := "/" + url.PathEscape(ast.Target.Name)
+	switch ast.Operation {
+	case types.OpSearch:
+		return types.Endpoint{Method: "POST", Path: querydsl.SearchPath(ast, index)}, nil
+	case types.OpUpsert, types.OpUpdate:
+		return types.Endpoint{Method: "POST", Path: index + "/_bulk" + r.refresh(), Lines: true}, nil
+	case types.OpDelete:
+		if len(ast.IDs) == 0 {
+			return types.Endpoint{Method: "POST", Path: index + "/_delete_by_query" + r.refresh()}, nil
+		}
+		return types.Endpoint{Method: "POST", Path: index + "/_bulk" + r.refresh(), Lines: true}, nil
+	case types.OpFetch:
+		if ast.Snapshot != nil {
+			return types.Endpoint{Method: "POST", Path: querydsl.SearchPath(ast, index)}, nil
+		}
+		return types.Endpoint{Method: "POST", Path: index + "/_mget"}, nil
+	default:
+		return types.Endpoint{}, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
+
+// refresh returns the refresh query string for writes.
+func (r *Renderer) refresh() string {
+	if r.Refresh == "" {
+		return ""
+	}
+	return "?refresh=" + url.QueryEscape(r.Refresh)
+}
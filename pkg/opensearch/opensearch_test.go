@@ -0,0 +1,186 @@
+package opensearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderSearch(t *testing.T) {
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterCondition{
+			Field:    types.MetadataField{Name: "category"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "cat"},
+		},
+		MinScore:        &types.Param{Name: "min_score"},
+		IncludeMetadata: true,
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"_source":{"excludes":["embedding"]},"min_score":":min_score","query":{"knn":{"embedding":` +
+		`{"filter":{"term":{"category":":cat"}},"k":10,"vector":":query_vec"}}},"size":10}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if len(result.RequiredParams) != 3 {
+		t.Errorf("expected 3 params, got %v", result.RequiredParams)
+	}
+}
+
+func TestRenderSearchRange(t *testing.T) {
+	topK := 5
+	min, max := types.Param{Name: "lo"}, types.Param{Name: "hi"}
+	ast := &types.VectorAST{
+		Operation:    types.OpSearch,
+		Target:       types.Collection{Name: "products"},
+		QueryVector:  &types.VectorValue{Param: &types.Param{Name: "v"}},
+		TopK:         &types.PaginationValue{Static: &topK},
+		FilterClause: types.RangeFilter{Field: types.MetadataField{Name: "price"}, Min: &min, Max: &max, MaxExclusive: true},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"_source":false,"query":{"knn":{"embedding":{"filter":{"range":{"price":{"gte":":lo","lt":":hi"}}},"k":5,"vector":":v"}}},"size":5}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+}
+
+func TestRenderSearchNeural(t *testing.T) {
+	topK := 5
+	ast := &types.VectorAST{
+		Operation:       types.OpSearch,
+		Target:          types.Collection{Name: "products", DefaultEmbedding: "passage_embedding"},
+		NearText:        &types.NearText{Concepts: types.Param{Name: "q"}},
+		TopK:            &types.PaginationValue{Static: &topK},
+		IncludeMetadata: true,
+	}
+
+	result, err := (&Renderer{ModelID: "aVeif4oB5Vm0Tdw8zYO2"}).Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"_source":{"excludes":["passage_embedding"]},"query":{"neural":{"passage_embedding":` +
+		`{"k":5,"model_id":"aVeif4oB5Vm0Tdw8zYO2","query_text":":q"}}},"size":5}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	ast.NearText = nil
+	ast.NearImage = &types.NearImage{Image: types.Param{Name: "img"}}
+	result, err = New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = `{"_source":{"excludes":["passage_embedding"]},"query":{"neural":{"passage_embedding":{"k":5,"query_image":":img"}}},"size":5}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+}
+
+func TestRenderWrites(t *testing.T) {
+	upsert := &types.VectorAST{
+		Operation: types.OpUpsert,
+		Target:    types.Collection{Name: "products"},
+		Vectors: []types.VectorRecord{{
+			ID:     types.Param{Name: "id"},
+			Vector: types.VectorValue{Param: &types.Param{Name: "v"}},
+		}},
+	}
+	result, err := New().Render(upsert)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `[{"index":{"_id":":id"}},{"embedding":":v"}]`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	del := &types.VectorAST{
+		Operation:    types.OpDelete,
+		Target:       types.Collection{Name: "products"},
+		FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "tenant"}, Operator: types.NE, Value: types.Param{Name: "t"}},
+		DeleteAll:    true,
+	}
+	result, err = New().Render(del)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"query":{"bool":{"filter":[{"bool":{"must_not":[{"term":{"tenant":":t"}}]}}]}}}`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	tests := []struct {
+		op    types.Operation
+		path  string
+		lines bool
+	}{
+		{types.OpSearch, "/products/_search", false},
+		{types.OpUpsert, "/products/_bulk", true},
+		{types.OpUpdate, "/products/_bulk", true},
+		{types.OpFetch, "/products/_mget", false},
+	}
+	for _, tt := range tests {
+		endpoint, err := New().Endpoint(&types.VectorAST{Operation: tt.op, Target: types.Collection{Name: "products"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if endpoint.Method != "POST" || endpoint.Path != tt.path || endpoint.Lines != tt.lines {
+			t.Errorf("%s: unexpected endpoint %+v", tt.op, endpoint)
+		}
+	}
+}
+
+func TestSupportsContentEncoding(t *testing.T) {
+	caps := New().Capabilities()
+	for encoding, want := range map[string]bool{"gzip": true, "deflate": true, "br": false, "zstd": false} {
+		if got := caps.SupportsContentEncoding(encoding); got != want {
+			t.Errorf("%s: expected %v, got %v", encoding, want, got)
+		}
+	}
+}
+
+func TestRenderSnapshot(t *testing.T) {
+	topK := 5
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		Snapshot:    &types.Snapshot{ID: types.Param{Name: "pit"}, KeepAlive: 30 * time.Second},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"_source":false,"pit":{"id":":pit","keep_alive":"30000ms"},"query":{"knn":{"embedding":{"k":5,"vector":":query_vec"}}},"size":5}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	endpoint, err := New().Endpoint(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Path != "/_search" {
+		t.Errorf("expected pinned search path, got %+v", endpoint)
+	}
+	if !New().Capabilities().Snapshots {
+		t.Error("expected snapshot support")
+	}
+}
@@ -0,0 +1,26 @@
+package pinecone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// ValidateNames reports metadata keys Pinecone cannot filter on: empty keys
+// and keys starting with "$", which Pinecone reserves for filter operators.
+func (r *Renderer) ValidateNames(names types.SchemaNames) error {
+	var errs []error
+	for _, coll := range names.Collections {
+		for _, name := range coll.Fields {
+			switch {
+			case name == "":
+				errs = append(errs, fmt.Errorf("collection '%s' has an empty metadata key", coll.Name))
+			case strings.HasPrefix(name, "$"):
+				errs = append(errs, fmt.Errorf("metadata key '%s' in collection '%s' starts with the reserved '$'", name, coll.Name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
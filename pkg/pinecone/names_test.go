@@ -0,0 +1,20 @@
+package pinecone
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestValidateNames(t *testing.T) {
+	err := New().ValidateNames(types.SchemaNames{Collections: []types.CollectionNames{
+		{Name: "products", Fields: []string{"category", "$price"}},
+	}})
+	if err == nil || !strings.Contains(err.Error(), "metadata key '$price'") {
+		t.Errorf("expected an error for a reserved key, got %v", err)
+	}
+	if strings.Contains(err.Error(), "'category'") {
+		t.Errorf("expected valid keys to pass, got %v", err)
+	}
+}
@@ -4,7 +4,9 @@ package pinecone
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 
+	"github.com/zoobzio/vectql/internal/filtertree"
 	"github.com/zoobzio/vectql/internal/types"
 )
 
@@ -21,7 +23,16 @@ func toResult(query map[string]interface{}, params []string) (*types.QueryResult
 }
 
 // Renderer renders VectorAST to Pinecone query format.
-type Renderer struct{}
+type Renderer struct {
+	// APIVersion is the Pinecone API version to target, such as
+	// APIVersion202407, reported on every QueryResult for the transport to
+	// send. Empty targets the unversioned legacy API.
+	APIVersion string
+
+	// Deployment selects pod-based or serverless indexes. The zero value is
+	// DeploymentPod.
+	Deployment Deployment
+}
 
 // New creates a new Pinecone renderer.
 func New() *Renderer {
@@ -30,6 +41,26 @@ func New() *Renderer {
 
 // Render converts a VectorAST to Pinecone query format.
 func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	if err := r.checkTarget(ast); err != nil {
+		return nil, err
+	}
+	result, err := r.render(ast)
+	if err != nil {
+		return nil, err
+	}
+	result.APIVersion = r.APIVersion
+	// Pinecone reads have no consistency setting, so freshness is dropped
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	// Matches are ranked by vector similarity alone
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+func (r *Renderer) render(ast *types.VectorAST) (*types.QueryResult, error) {
 	if err := ast.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid AST: %w", err)
 	}
@@ -53,6 +84,16 @@ func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
 }
 
 func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("pinecone does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("pinecone"); err != nil {
+		return nil, err
+	}
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("pinecone does not support quantization search parameters")
+	}
+
 	query := make(map[string]interface{})
 
 	// TopK
@@ -228,52 +269,22 @@ func (r *Renderer) renderUpdate(ast *types.VectorAST, params *[]string) (*types.
 }
 
 func (r *Renderer) renderFilter(f types.FilterItem, params *[]string) (interface{}, error) {
-	switch filter := f.(type) {
-	case types.FilterCondition:
-		*params = append(*params, filter.Value.Name)
-		return map[string]interface{}{
-			filter.Field.Name: map[string]interface{}{
-				r.mapOperator(filter.Operator): fmt.Sprintf(":%s", filter.Value.Name),
-			},
-		}, nil
-
-	case types.FilterGroup:
-		conditions := make([]interface{}, 0, len(filter.Conditions))
-		for _, c := range filter.Conditions {
-			rendered, err := r.renderFilter(c, params)
-			if err != nil {
-				return nil, err
-			}
-			conditions = append(conditions, rendered)
-		}
-		return map[string]interface{}{
-			r.mapLogic(filter.Logic): conditions,
-		}, nil
-
-	case types.RangeFilter:
-		rangeFilter := make(map[string]interface{})
-		if filter.Min != nil {
-			*params = append(*params, filter.Min.Name)
-			op := "$gte"
-			if filter.MinExclusive {
-				op = "$gt"
-			}
-			rangeFilter[op] = fmt.Sprintf(":%s", filter.Min.Name)
-		}
-		if filter.Max != nil {
-			*params = append(*params, filter.Max.Name)
-			op := "$lte"
-			if filter.MaxExclusive {
-				op = "$lt"
-			}
-			rangeFilter[op] = fmt.Sprintf(":%s", filter.Max.Name)
-		}
-		return map[string]interface{}{
-			filter.Field.Name: rangeFilter,
-		}, nil
+	return filtertree.New(r.dialect(), params).Compile(f)
+}
 
-	default:
-		return nil, fmt.Errorf("unsupported filter type: %T", f)
+// dialect describes Pinecone's MongoDB-style metadata filter language.
+func (r *Renderer) dialect() *filtertree.Dialect {
+	return &filtertree.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			if !r.SupportsFilter(op) {
+				return "", false
+			}
+			return r.mapOperator(op), true
+		},
+		Logic:     r.mapLogic,
+		Condition: filtertree.NestedCondition,
+		Group:     filtertree.NestedGroup,
+		Range:     filtertree.NestedRange,
 	}
 }
 
@@ -342,3 +353,70 @@ func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
 		return false
 	}
 }
+
+// SupportsModality indicates if Pinecone accepts a search input modality.
+// Pinecone only searches with vectors.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	return m == types.ModalityVector
+}
+
+// Capabilities describes the features supported by Pinecone.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("pinecone", r)
+}
+
+// RenderTo writes the Pinecone query body for ast to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// Format reports that Pinecone queries are JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// RenderPartition maps partition lifecycle operations to namespaces.
+// Namespaces are created on first write and are always loaded, so only drop
+// needs a call: it deletes every vector in the namespace.
+func (r *Renderer) RenderPartition(op types.PartitionOp, _ string, namespace string) (types.Endpoint, string, error) {
+	switch op {
+	case types.PartitionCreate, types.PartitionLoad:
+		return types.Endpoint{}, "", types.ErrImplicitPartition
+	case types.PartitionDrop:
+		if namespace == "" {
+			return types.Endpoint{}, "", fmt.Errorf("namespace is required")
+		}
+		data, err := json.Marshal(map[string]interface{}{"deleteAll": true, "namespace": namespace})
+		if err != nil {
+			return types.Endpoint{}, "", fmt.Errorf("failed to serialize partition call: %w", err)
+		}
+		return types.Endpoint{Method: "POST", Path: "/vectors/delete"}, string(data), nil
+	default:
+		return types.Endpoint{}, "", fmt.Errorf("unsupported partition operation: %s", op)
+	}
+}
+
+// Endpoint returns the Pinecone data plane call for ast, relative to the index host.
+func (r *Renderer) Endpoint(ast *types.VectorAST) (types.Endpoint, error) {
+	switch ast.Operation {
+	case types.OpSearch:
+		return types.Endpoint{Method: "POST", Path: "/query"}, nil
+	case types.OpUpsert:
+		return types.Endpoint{Method: "POST", Path: "/vectors/upsert"}, nil
+	case types.OpDelete:
+		return types.Endpoint{Method: "POST", Path: "/vectors/delete"}, nil
+	case types.OpFetch:
+		return types.Endpoint{Method: "GET", Path: "/vectors/fetch"}, nil
+	case types.OpUpdate:
+		return types.Endpoint{Method: "POST", Path: "/vectors/update"}, nil
+	default:
+		return types.Endpoint{}, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
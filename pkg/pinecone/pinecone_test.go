@@ -318,3 +318,131 @@ func TestLogicOperatorMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderFilterRejectsUnsupportedOperator(t *testing.T) {
+	renderer := New()
+
+	filter := types.FilterGroup{
+		Logic: types.AND,
+		Conditions: []types.FilterItem{
+			types.FilterCondition{
+				Field:    types.MetadataField{Name: "category"},
+				Operator: types.EQ,
+				Value:    types.Param{Name: "cat"},
+			},
+			types.FilterCondition{
+				Field:    types.MetadataField{Name: "name"},
+				Operator: types.Matches,
+				Value:    types.Param{Name: "pattern"},
+			},
+		},
+	}
+
+	var params []string
+	if _, err := renderer.renderFilter(filter, &params); err == nil {
+		t.Error("expected error for unsupported operator")
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	renderer := New()
+	caps := renderer.Capabilities()
+
+	if caps.Provider != "pinecone" {
+		t.Errorf("expected provider pinecone, got %q", caps.Provider)
+	}
+	for _, op := range types.FilterOperators {
+		if caps.SupportsFilter(op) != renderer.SupportsFilter(op) {
+			t.Errorf("capabilities disagree with SupportsFilter for %s", op)
+		}
+	}
+	if renderer.Format() != types.FormatJSON {
+		t.Errorf("expected JSON format, got %s", renderer.Format())
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	renderer := New()
+
+	tests := []struct {
+		op     types.Operation
+		method string
+		path   string
+	}{
+		{types.OpSearch, "POST", "/query"},
+		{types.OpFetch, "GET", "/vectors/fetch"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.op), func(t *testing.T) {
+			ast := &types.VectorAST{
+				Operation: tt.op,
+				Target:    types.Collection{Name: "products"},
+				IDs:       []types.Param{{Name: "id"}},
+			}
+			endpoint, err := renderer.Endpoint(ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if endpoint.Method != tt.method || endpoint.Path != tt.path {
+				t.Errorf("expected %s %s, got %s %s", tt.method, tt.path, endpoint.Method, endpoint.Path)
+			}
+		})
+	}
+}
+
+func TestRenderSearchRejectsNearText(t *testing.T) {
+	renderer := New()
+
+	topK := 10
+	ast := &types.VectorAST{
+		Operation: types.OpSearch,
+		Target:    types.Collection{Name: "products"},
+		NearText:  &types.NearText{Concepts: types.Param{Name: "concepts"}},
+		TopK:      &types.PaginationValue{Static: &topK},
+	}
+
+	if _, err := renderer.Render(ast); err == nil {
+		t.Error("expected error for text search input")
+	}
+	if renderer.Capabilities().SupportsModality(types.ModalityText) {
+		t.Error("expected text modality to be unsupported")
+	}
+}
+
+func TestRenderSearchRejectsQuantization(t *testing.T) {
+	renderer := New()
+
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:    types.OpSearch,
+		Target:       types.Collection{Name: "products"},
+		QueryVector:  &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:         &types.PaginationValue{Static: &topK},
+		Quantization: &types.QuantizationParams{Ignore: true},
+	}
+
+	if _, err := renderer.Render(ast); err == nil {
+		t.Error("expected error for quantization search parameters")
+	}
+}
+
+func TestRenderPartition(t *testing.T) {
+	r := New()
+	endpoint, body, err := r.RenderPartition(types.PartitionDrop, "products", "tenant_a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Method != "POST" || endpoint.Path != "/vectors/delete" {
+		t.Errorf("unexpected endpoint: %s %s", endpoint.Method, endpoint.Path)
+	}
+	if body != `{"deleteAll":true,"namespace":"tenant_a"}` {
+		t.Errorf("unexpected body: %s", body)
+	}
+
+	for _, op := range []types.PartitionOp{types.PartitionCreate, types.PartitionLoad} {
+		if _, _, err := r.RenderPartition(op, "products", "tenant_a"); err != types.ErrImplicitPartition {
+			t.Errorf("%s: expected ErrImplicitPartition, got %v", op, err)
+		}
+	}
+}
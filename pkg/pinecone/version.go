@@ -0,0 +1,92 @@
+package pinecone
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Deployment selects the Pinecone index architecture the renderer targets.
+type Deployment int
+
+const (
+	// DeploymentPod targets pod-based indexes. This is the default.
+	DeploymentPod Deployment = iota
+
+	// DeploymentServerless targets serverless indexes. They list record IDs
+	// with RenderListIDs, and delete by metadata filter only from API
+	// version 2025-04.
+	DeploymentServerless
+)
+
+// Pinecone API versions, sent as the X-Pinecone-API-Version header.
+const (
+	APIVersion202404 = "2024-04"
+	APIVersion202407 = "2024-07"
+	APIVersion202410 = "2024-10"
+	APIVersion202501 = "2025-01"
+	APIVersion202504 = "2025-04"
+)
+
+// serverlessDeleteByFilter is the first API version that deletes by
+// metadata filter on serverless indexes.
+const serverlessDeleteByFilter = APIVersion202504
+
+// maxListLimit is the most record IDs a list call returns per page.
+const maxListLimit = 100
+
+// apiVersionPattern matches Pinecone's date-based API versions.
+var apiVersionPattern = regexp.MustCompile(`^\d{4}-\d{2}$`)
+
+// NewServerless creates a Pinecone renderer for a serverless index on the
+// given API version.
+func NewServerless(apiVersion string) *Renderer {
+	return &Renderer{Deployment: DeploymentServerless, APIVersion: apiVersion}
+}
+
+// checkTarget rejects an invalid API version and queries the targeted
+// deployment cannot run.
+func (r *Renderer) checkTarget(ast *types.VectorAST) error {
+	if r.APIVersion != "" && !apiVersionPattern.MatchString(r.APIVersion) {
+		return fmt.Errorf("invalid pinecone API version %q: expected YYYY-MM", r.APIVersion)
+	}
+	if r.Deployment != DeploymentServerless {
+		return nil
+	}
+	// Date versions compare as strings; the unversioned API is the oldest
+	if ast.Operation == types.OpDelete && len(ast.IDs) == 0 && r.APIVersion < serverlessDeleteByFilter {
+		return fmt.Errorf("pinecone serverless indexes delete by metadata filter from API version %s", serverlessDeleteByFilter)
+	}
+	return nil
+}
+
+// RenderListIDs returns the call listing the record IDs in namespace that
+// start with prefix, one page of at most limit IDs at a time. Pass the
+// NextPage token of the previous response as after, or the zero token for
+// the first page. Only serverless indexes list IDs.
+func (r *Renderer) RenderListIDs(namespace, prefix string, limit int, after types.PageToken) (types.Endpoint, error) {
+	if r.Deployment != DeploymentServerless {
+		return types.Endpoint{}, fmt.Errorf("pinecone lists record IDs on serverless indexes only")
+	}
+	if limit <= 0 || limit > maxListLimit {
+		return types.Endpoint{}, fmt.Errorf("list limit must be between 1 and %d: %d", maxListLimit, limit)
+	}
+	query := url.Values{"limit": {strconv.Itoa(limit)}}
+	if namespace != "" {
+		query.Set("namespace", namespace)
+	}
+	if prefix != "" {
+		query.Set("prefix", prefix)
+	}
+	if !after.IsZero() {
+		cursor, err := after.CursorFor("pinecone")
+		if err != nil {
+			return types.Endpoint{}, err
+		}
+		query.Set("paginationToken", cursor)
+	}
+	return types.Endpoint{Method: "GET", Path: "/vectors/list", Query: query.Encode()}, nil
+}
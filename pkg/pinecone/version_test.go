@@ -0,0 +1,115 @@
+package pinecone
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func deleteByFilterAST() *types.VectorAST {
+	return &types.VectorAST{
+		Operation: types.OpDelete,
+		Target:    types.Collection{Name: "products"},
+		FilterClause: types.FilterCondition{
+			Field:    types.MetadataField{Name: "category"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "cat"},
+		},
+		DeleteAll: true,
+	}
+}
+
+func TestRenderAPIVersion(t *testing.T) {
+	fetch := &types.VectorAST{
+		Operation: types.OpFetch,
+		Target:    types.Collection{Name: "products"},
+		IDs:       []types.Param{{Name: "id"}},
+	}
+
+	result, err := New().Render(fetch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.APIVersion != "" {
+		t.Errorf("expected no API version for the legacy API, got %q", result.APIVersion)
+	}
+
+	result, err = NewServerless(APIVersion202407).Render(fetch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.APIVersion != "2024-07" {
+		t.Errorf("expected API version 2024-07, got %q", result.APIVersion)
+	}
+
+	_, err = (&Renderer{APIVersion: "v2"}).Render(fetch)
+	if err == nil || !strings.Contains(err.Error(), "invalid pinecone API version") {
+		t.Errorf("expected an API version error, got %v", err)
+	}
+}
+
+func TestRenderServerlessDeleteByFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		renderer *Renderer
+		wantErr  bool
+	}{
+		{"pod", &Renderer{APIVersion: APIVersion202407}, false},
+		{"serverless legacy", &Renderer{Deployment: DeploymentServerless}, true},
+		{"serverless 2024-07", NewServerless(APIVersion202407), true},
+		{"serverless 2025-04", NewServerless(APIVersion202504), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.renderer.Render(deleteByFilterAST())
+			if tt.wantErr && (err == nil || !strings.Contains(err.Error(), "from API version 2025-04")) {
+				t.Errorf("expected a deployment error, got %v", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	del := &types.VectorAST{
+		Operation: types.OpDelete,
+		Target:    types.Collection{Name: "products"},
+		IDs:       []types.Param{{Name: "id"}},
+	}
+	if _, err := NewServerless(APIVersion202407).Render(del); err != nil {
+		t.Errorf("expected deletes by ID on serverless, got %v", err)
+	}
+}
+
+func TestRenderListIDs(t *testing.T) {
+	endpoint, err := NewServerless(APIVersion202407).RenderListIDs("tenant_a", "doc#", 50, types.NewPageToken("pinecone", "tok"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Method != "GET" || endpoint.Path != "/vectors/list" {
+		t.Errorf("unexpected endpoint: %s %s", endpoint.Method, endpoint.Path)
+	}
+	expected := "limit=50&namespace=tenant_a&paginationToken=tok&prefix=doc%23"
+	if endpoint.Query != expected {
+		t.Errorf("unexpected query:\n got: %s\nwant: %s", endpoint.Query, expected)
+	}
+
+	first, err := NewServerless(APIVersion202407).RenderListIDs("", "", 100, types.PageToken{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.Query != "limit=100" {
+		t.Errorf("expected only a limit on the first page, got %s", first.Query)
+	}
+
+	if _, err := New().RenderListIDs("", "", 10, types.PageToken{}); err == nil || !strings.Contains(err.Error(), "serverless indexes only") {
+		t.Errorf("expected a deployment error, got %v", err)
+	}
+	if _, err := NewServerless(APIVersion202407).RenderListIDs("", "", 101, types.PageToken{}); err == nil {
+		t.Error("expected a limit error")
+	}
+	if _, err := NewServerless(APIVersion202407).RenderListIDs("", "", 10, types.NewPageToken("qdrant", "7")); err == nil || !strings.Contains(err.Error(), "issued by 'qdrant'") {
+		t.Errorf("expected a provider error, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package qdrant
+
+import (
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// SupportsExistsByFilter reports that existence checks by filter render as
+// a scroll of at most one point, for either protocol.
+func (r *Renderer) SupportsExistsByFilter() bool {
+	return true
+}
+
+// scrolls reports whether a FETCH renders as a scroll rather than a read
+// of points by ID.
+func scrolls(ast *types.VectorAST) bool {
+	return ast.FetchKey != nil || ast.ExistsByFilter() || ast.Lists()
+}
+
+// renderExists renders an existence check by filter as a points/scroll
+// request for one point without payload or vector.
+func (r *Renderer) renderExists(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	filter, err := r.renderFilter(ast.FilterClause, params)
+	if err != nil {
+		return nil, err
+	}
+	query := map[string]interface{}{
+		"filter":       filter,
+		"limit":        1,
+		"with_payload": false,
+		"with_vector":  false,
+	}
+	return toResult(query, *params)
+}
+
+// grpcExistsScroll fills a ScrollPoints message for an existence check by
+// filter.
+func (r *Renderer) grpcExistsScroll(ast *types.VectorAST, msg map[string]interface{}, params *[]string) error {
+	filter, err := r.renderGRPCFilter(ast.FilterClause, params)
+	if err != nil {
+		return err
+	}
+	msg["filter"] = filter
+	msg["limit"] = 1
+	msg["with_payload"] = map[string]interface{}{"enable": false}
+	msg["with_vectors"] = map[string]interface{}{"enable": false}
+	if rc := grpcReadConsistency(ast); rc != nil {
+		msg["read_consistency"] = rc
+	}
+	return nil
+}
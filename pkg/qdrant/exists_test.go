@@ -0,0 +1,59 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func existsAST() *types.VectorAST {
+	return &types.VectorAST{
+		Operation: types.OpFetch,
+		Target:    types.Collection{Name: "products"},
+		Exists:    true,
+		FilterClause: types.FilterCondition{
+			Field:    types.MetadataField{Name: "sku", Type: "string"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "sku"},
+		},
+	}
+}
+
+func TestRenderExists(t *testing.T) {
+	result, err := New().Render(existsAST())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"filter":{"must":[{"key":"sku","match":{"value":":sku"}}]},"limit":1,"with_payload":false,"with_vector":false}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+
+	endpoint, err := New().Endpoint(existsAST())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Path != "/collections/products/points/scroll" {
+		t.Errorf("unexpected endpoint: %s %s", endpoint.Method, endpoint.Path)
+	}
+}
+
+func TestRenderGRPCExists(t *testing.T) {
+	result, err := NewGRPC().Render(existsAST())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"collection_name":"products","filter":{"must":[{"field":{"key":"sku","match":{"keyword":":sku"}}}]},` +
+		`"limit":1,"with_payload":{"enable":false},"with_vectors":{"enable":false}}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+
+	endpoint, err := NewGRPC().Endpoint(existsAST())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Path != "/qdrant.Points/Scroll" {
+		t.Errorf("unexpected endpoint: %s %s", endpoint.Method, endpoint.Path)
+	}
+}
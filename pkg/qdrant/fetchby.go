@@ -0,0 +1,73 @@
+package qdrant
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// SupportsFetchBy reports that FETCH by key renders as a scroll filtered
+// on the key field, for either protocol.
+func (r *Renderer) SupportsFetchBy() bool {
+	return true
+}
+
+// keyPlaceholders returns the placeholders of the keys of a FETCH by key.
+func keyPlaceholders(key *types.FetchKey, params *[]string) []string {
+	keys := make([]string, len(key.Keys))
+	for i, k := range key.Keys {
+		*params = append(*params, k.Name)
+		keys[i] = fmt.Sprintf(":%s", k.Name)
+	}
+	return keys
+}
+
+// renderFetchBy renders a FETCH by key as a points/scroll request whose
+// filter matches any of the keys, limited to as many points as keys. A
+// scroll cannot be grouped, so points sharing a key can use up the limit.
+func (r *Renderer) renderFetchBy(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	key := ast.FetchKey
+	query := map[string]interface{}{
+		"filter": map[string]interface{}{
+			condMust: []map[string]interface{}{
+				{
+					"key":   key.Field.Name,
+					"match": map[string]interface{}{"any": keyPlaceholders(key, params)},
+				},
+			},
+		},
+		"limit":        len(key.Keys),
+		"with_payload": ast.IncludeMetadata,
+		"with_vector":  ast.IncludeVectors,
+	}
+	return toResult(query, *params)
+}
+
+// grpcScrollPoints fills a ScrollPoints message for a FETCH by key.
+func (r *Renderer) grpcScrollPoints(ast *types.VectorAST, msg map[string]interface{}, params *[]string) {
+	key := ast.FetchKey
+	kind, _ := matchKind(key.Field.Type)
+	var match map[string]interface{}
+	if kind == "integer" {
+		match = map[string]interface{}{
+			"integers": map[string]interface{}{"integers": keyPlaceholders(key, params)},
+		}
+	} else {
+		match = map[string]interface{}{
+			"keywords": map[string]interface{}{"strings": keyPlaceholders(key, params)},
+		}
+	}
+	msg["filter"] = map[string]interface{}{
+		condMust: []interface{}{
+			map[string]interface{}{
+				"field": map[string]interface{}{"key": key.Field.Name, "match": match},
+			},
+		},
+	}
+	msg["limit"] = len(key.Keys)
+	msg["with_payload"] = payloadSelector(ast)
+	msg["with_vectors"] = map[string]interface{}{"enable": ast.IncludeVectors}
+	if rc := grpcReadConsistency(ast); rc != nil {
+		msg["read_consistency"] = rc
+	}
+}
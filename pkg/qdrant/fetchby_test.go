@@ -0,0 +1,69 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func fetchByAST(fieldType string) *types.VectorAST {
+	return &types.VectorAST{
+		Operation: types.OpFetch,
+		Target:    types.Collection{Name: "products"},
+		FetchKey: &types.FetchKey{
+			Field: types.MetadataField{Name: "sku", Type: fieldType, Unique: true},
+			Keys:  []types.Param{{Name: "k1"}, {Name: "k2"}},
+		},
+		IncludeMetadata: true,
+	}
+}
+
+func TestRenderFetchBy(t *testing.T) {
+	result, err := New().Render(fetchByAST("string"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"filter":{"must":[{"key":"sku","match":{"any":[":k1",":k2"]}}]},"limit":2,"with_payload":true,"with_vector":false}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+
+	endpoint, err := New().Endpoint(fetchByAST("string"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Method != "POST" || endpoint.Path != "/collections/products/points/scroll" {
+		t.Errorf("unexpected endpoint: %s %s", endpoint.Method, endpoint.Path)
+	}
+}
+
+func TestRenderGRPCFetchBy(t *testing.T) {
+	tests := []struct {
+		fieldType string
+		expected  string
+	}{
+		{"string", `{"collection_name":"products","filter":{"must":[{"field":{"key":"sku","match":{"keywords":{"strings":[":k1",":k2"]}}}}]},` +
+			`"limit":2,"with_payload":{"enable":true},"with_vectors":{"enable":false}}`},
+		{"int", `{"collection_name":"products","filter":{"must":[{"field":{"key":"sku","match":{"integers":{"integers":[":k1",":k2"]}}}}]},` +
+			`"limit":2,"with_payload":{"enable":true},"with_vectors":{"enable":false}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fieldType, func(t *testing.T) {
+			result, err := NewGRPC().Render(fetchByAST(tt.fieldType))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.JSON != tt.expected {
+				t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, tt.expected)
+			}
+		})
+	}
+
+	endpoint, err := NewGRPC().Endpoint(fetchByAST("string"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Path != "/qdrant.Points/Scroll" {
+		t.Errorf("unexpected endpoint: %s", endpoint.Path)
+	}
+}
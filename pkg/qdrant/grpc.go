@@ -0,0 +1,484 @@
+package qdrant
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/filtertree"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Protocol selects the wire format of rendered requests.
+type Protocol int
+
+const (
+	// ProtocolREST renders JSON bodies for the Qdrant REST API. This is the
+	// default.
+	ProtocolREST Protocol = iota
+
+	// ProtocolGRPC renders the protobuf messages of the Qdrant gRPC API
+	// (SearchPoints, QueryPoints, UpsertPoints, DeletePoints, GetPoints,
+	// ScrollPoints and SetPayloadPoints) in their canonical JSON mapping, so
+	// a bound body can be decoded with protojson.Unmarshal straight into the
+	// matching qdrant-go-client message. Endpoint reports the full gRPC method name
+	// as its path. The collection name moves into the body, read
+	// consistency becomes a message field, and filters and payloads need
+	// schema field types to choose between typed protobuf values.
+	ProtocolGRPC
+)
+
+// NewGRPC creates a Qdrant renderer that emits gRPC request messages.
+func NewGRPC() *Renderer {
+	return &Renderer{Protocol: ProtocolGRPC}
+}
+
+// gRPC methods of the qdrant.Points service, by operation.
+const (
+	grpcSearch     = "/qdrant.Points/Search"
+	grpcQuery      = "/qdrant.Points/Query"
+	grpcUpsert     = "/qdrant.Points/Upsert"
+	grpcDelete     = "/qdrant.Points/Delete"
+	grpcGet        = "/qdrant.Points/Get"
+	grpcScroll     = "/qdrant.Points/Scroll"
+	grpcSetPayload = "/qdrant.Points/SetPayload"
+)
+
+// grpcEndpoint returns the gRPC method for ast.
+func (r *Renderer) grpcEndpoint(ast *types.VectorAST) (types.Endpoint, error) {
+	var method string
+	switch ast.Operation {
+	case types.OpSearch:
+		method = grpcSearch
+		if r.Mode == ModeQuery {
+			method = grpcQuery
+		}
+	case types.OpUpsert:
+		method = grpcUpsert
+	case types.OpDelete:
+		method = grpcDelete
+	case types.OpFetch:
+		method = grpcGet
+		if scrolls(ast) {
+			method = grpcScroll
+		}
+	case types.OpUpdate:
+		method = grpcSetPayload
+	default:
+		return types.Endpoint{}, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+	return types.Endpoint{Method: "POST", Path: method}, nil
+}
+
+// renderGRPC renders ast as a gRPC request message.
+func (r *Renderer) renderGRPC(ast *types.VectorAST) (*types.QueryResult, error) {
+	var params []string
+	msg := map[string]interface{}{"collection_name": ast.Target.Name}
+
+	var err error
+	switch ast.Operation {
+	case types.OpSearch:
+		err = r.grpcSearchPoints(ast, msg, &params)
+	case types.OpUpsert:
+		err = r.grpcUpsertPoints(ast, msg, &params)
+	case types.OpDelete:
+		err = r.grpcDeletePoints(ast, msg, &params)
+	case types.OpFetch:
+		switch {
+		case ast.FetchKey != nil:
+			r.grpcScrollPoints(ast, msg, &params)
+		case ast.ExistsByFilter():
+			err = r.grpcExistsScroll(ast, msg, &params)
+		case ast.Lists():
+			err = r.grpcListScroll(ast, msg, &params)
+		default:
+			r.grpcGetPoints(ast, msg, &params)
+		}
+	case types.OpUpdate:
+		err = r.grpcSetPayloadPoints(ast, msg, &params)
+	default:
+		err = fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := toResult(msg, params)
+	if err != nil {
+		return nil, err
+	}
+	// Scores are vector similarities; there is no score adjustment
+	if ast.Operation == types.OpSearch && len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+// grpcSearchPoints fills a SearchPoints message, or a QueryPoints message
+// in ModeQuery.
+func (r *Renderer) grpcSearchPoints(ast *types.VectorAST, msg map[string]interface{}, params *[]string) error {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return fmt.Errorf("qdrant does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("qdrant"); err != nil {
+		return err
+	}
+
+	var vector interface{}
+	if ast.QueryVector != nil {
+		if ast.QueryVector.Param != nil {
+			*params = append(*params, ast.QueryVector.Param.Name)
+			vector = fmt.Sprintf(":%s", ast.QueryVector.Param.Name)
+		} else {
+			vector = ast.QueryVector.Literal
+		}
+	}
+	name := ast.Target.DefaultEmbedding
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
+		name = ast.QueryEmbedding.Name
+	}
+
+	if r.Mode == ModeQuery {
+		msg["query"] = map[string]interface{}{
+			"nearest": map[string]interface{}{
+				"dense": map[string]interface{}{"data": vector},
+			},
+		}
+		if name != "" {
+			msg["using"] = name
+		}
+	} else {
+		msg["vector"] = vector
+		if name != "" {
+			msg["vector_name"] = name
+		}
+	}
+
+	if ast.TopK != nil {
+		if ast.TopK.Static != nil {
+			msg["limit"] = *ast.TopK.Static
+		} else if ast.TopK.Param != nil {
+			*params = append(*params, ast.TopK.Param.Name)
+			msg["limit"] = fmt.Sprintf(":%s", ast.TopK.Param.Name)
+		}
+	}
+
+	if ast.MinScore != nil {
+		*params = append(*params, ast.MinScore.Name)
+		msg["score_threshold"] = fmt.Sprintf(":%s", ast.MinScore.Name)
+	}
+
+	if sp := searchParams(ast); sp != nil {
+		msg["params"] = sp
+	}
+
+	msg["with_payload"] = payloadSelector(ast)
+	msg["with_vectors"] = map[string]interface{}{"enable": ast.IncludeVectors}
+
+	if ast.FilterClause != nil {
+		filter, err := r.renderGRPCFilter(ast.FilterClause, params)
+		if err != nil {
+			return err
+		}
+		msg["filter"] = filter
+	}
+
+	if rc := grpcReadConsistency(ast); rc != nil {
+		msg["read_consistency"] = rc
+	}
+	return nil
+}
+
+// grpcUpsertPoints fills an UpsertPoints message.
+func (r *Renderer) grpcUpsertPoints(ast *types.VectorAST, msg map[string]interface{}, params *[]string) error {
+	points := make([]map[string]interface{}, len(ast.Vectors))
+	for i, record := range ast.Vectors {
+		point := map[string]interface{}{
+			"id": r.pointID(record.ID, params),
+		}
+
+		var data interface{} = record.Vector.Literal
+		if record.Vector.Param != nil {
+			*params = append(*params, record.Vector.Param.Name)
+			data = fmt.Sprintf(":%s", record.Vector.Param.Name)
+		}
+		vector := map[string]interface{}{"data": data}
+		if name := ast.Target.DefaultEmbedding; name != "" {
+			point["vectors"] = map[string]interface{}{
+				"vectors": map[string]interface{}{
+					"vectors": map[string]interface{}{name: vector},
+				},
+			}
+		} else {
+			point["vectors"] = map[string]interface{}{"vector": vector}
+		}
+
+		if len(record.Metadata) > 0 {
+			payload, err := grpcPayload(record.Metadata, params)
+			if err != nil {
+				return err
+			}
+			point["payload"] = payload
+		}
+		points[i] = point
+	}
+	msg["points"] = points
+	return nil
+}
+
+// grpcDeletePoints fills a DeletePoints message.
+func (r *Renderer) grpcDeletePoints(ast *types.VectorAST, msg map[string]interface{}, params *[]string) error {
+	if len(ast.IDs) > 0 {
+		msg["points"] = map[string]interface{}{"points": r.pointIDs(ast.IDs, params)}
+	} else if ast.FilterClause != nil && ast.DeleteAll {
+		filter, err := r.renderGRPCFilter(ast.FilterClause, params)
+		if err != nil {
+			return err
+		}
+		msg["points"] = map[string]interface{}{"filter": filter}
+	}
+	return nil
+}
+
+// grpcGetPoints fills a GetPoints message.
+func (r *Renderer) grpcGetPoints(ast *types.VectorAST, msg map[string]interface{}, params *[]string) {
+	msg["ids"] = r.pointIDs(ast.IDs, params)["ids"]
+	msg["with_payload"] = payloadSelector(ast)
+	msg["with_vectors"] = map[string]interface{}{"enable": ast.IncludeVectors}
+	if rc := grpcReadConsistency(ast); rc != nil {
+		msg["read_consistency"] = rc
+	}
+}
+
+// grpcSetPayloadPoints fills a SetPayloadPoints message.
+func (r *Renderer) grpcSetPayloadPoints(ast *types.VectorAST, msg map[string]interface{}, params *[]string) error {
+	selector := map[string]interface{}{"points": r.pointIDs(ast.IDs, params)}
+	payload, err := grpcPayload(ast.Updates, params)
+	if err != nil {
+		return err
+	}
+	msg["payload"] = payload
+	msg["points_selector"] = selector
+	return nil
+}
+
+// pointID renders a PointId. Qdrant point IDs are UUIDs or unsigned
+// integers; NumericIDs selects the integer form.
+func (r *Renderer) pointID(id types.Param, params *[]string) map[string]interface{} {
+	*params = append(*params, id.Name)
+	kind := "uuid"
+	if r.NumericIDs {
+		kind = "num"
+	}
+	return map[string]interface{}{kind: fmt.Sprintf(":%s", id.Name)}
+}
+
+// pointIDs renders a PointsIdsList.
+func (r *Renderer) pointIDs(ids []types.Param, params *[]string) map[string]interface{} {
+	list := make([]map[string]interface{}, len(ids))
+	for i, id := range ids {
+		list[i] = r.pointID(id, params)
+	}
+	return map[string]interface{}{"ids": list}
+}
+
+// payloadSelector renders a WithPayloadSelector, naming the selected
+// fields when the query selects some.
+func payloadSelector(ast *types.VectorAST) map[string]interface{} {
+	if ast.IncludeMetadata && len(ast.MetadataFields) > 0 {
+		fields := make([]string, len(ast.MetadataFields))
+		for i, f := range ast.MetadataFields {
+			fields[i] = f.Name
+		}
+		return map[string]interface{}{
+			"include": map[string]interface{}{"fields": fields},
+		}
+	}
+	return map[string]interface{}{"enable": ast.IncludeMetadata}
+}
+
+// grpcReadConsistency renders the ReadConsistency of a read with a
+// freshness level, or nil for the default; see readConsistency.
+func grpcReadConsistency(ast *types.VectorAST) map[string]interface{} {
+	if ast.Freshness == nil {
+		return nil
+	}
+	switch ast.Freshness.Level {
+	case types.FreshnessStrong:
+		return map[string]interface{}{"type": "All"}
+	case types.FreshnessBounded:
+		return map[string]interface{}{"type": "Majority"}
+	default:
+		return nil
+	}
+}
+
+// grpcPayload renders a payload map of typed Value messages. Qdrant's Value
+// is a oneof, so each field needs a scalar schema type.
+func grpcPayload(fields map[types.MetadataField]types.Param, params *[]string) (map[string]interface{}, error) {
+	payload := make(map[string]interface{}, len(fields))
+	for field, value := range fields {
+		var kind string
+		switch field.Type {
+		case "string":
+			kind = "string_value"
+		case "int":
+			kind = "integer_value"
+		case "float":
+			kind = "double_value"
+		case "bool":
+			kind = "bool_value"
+		case "":
+			return nil, fmt.Errorf("qdrant gRPC payload field %s has no schema type", field.Name)
+		default:
+			return nil, fmt.Errorf("qdrant gRPC payload field %s has unsupported type %s", field.Name, field.Type)
+		}
+		*params = append(*params, value.Name)
+		payload[field.Name] = map[string]interface{}{kind: fmt.Sprintf(":%s", value.Name)}
+	}
+	return payload, nil
+}
+
+// renderGRPCFilter renders a Filter message.
+func (r *Renderer) renderGRPCFilter(f types.FilterItem, params *[]string) (interface{}, error) {
+	if err := checkGRPCFilter(f); err != nil {
+		return nil, err
+	}
+	return filtertree.New(r.grpcDialect(), params).Compile(f)
+}
+
+// matchKind returns the Match oneof for single values of a field type.
+// Array fields match on any element.
+func matchKind(fieldType string) (string, bool) {
+	switch strings.TrimPrefix(fieldType, "[]") {
+	case "string":
+		return "keyword", true
+	case "int":
+		return "integer", true
+	case "bool":
+		return "boolean", true
+	default:
+		return "", false
+	}
+}
+
+// checkGRPCFilter rejects conditions whose typed Match cannot be chosen,
+// since the filter dialect cannot fail.
+func checkGRPCFilter(f types.FilterItem) error {
+	switch filter := f.(type) {
+	case types.FilterGroup:
+		for _, c := range filter.Conditions {
+			if err := checkGRPCFilter(c); err != nil {
+				return err
+			}
+		}
+	case types.FilterCondition:
+		field := filter.Field
+		switch filter.Operator {
+		case types.EQ, types.NE, types.IN, types.Contains:
+		default:
+			return nil
+		}
+		if field.Type == "" {
+			return fmt.Errorf("qdrant gRPC filter on %s needs the field's schema type", field.Name)
+		}
+		kind, ok := matchKind(field.Type)
+		switch filter.Operator {
+		case types.EQ, types.NE:
+			ok = ok || field.Type == "float"
+		case types.IN:
+			ok = ok && kind != "boolean"
+		case types.Contains:
+			ok = field.Type == "string" || (ok && kind != "boolean" && strings.HasPrefix(field.Type, "[]"))
+		}
+		if !ok {
+			return fmt.Errorf("qdrant gRPC filter does not support %s on %s field %s", filter.Operator, field.Type, field.Name)
+		}
+	}
+	return nil
+}
+
+// grpcDialect describes the Filter message. Every node renders as a Filter;
+// conditions sit in its must or must_not list and groups nest their
+// children as filter conditions.
+func (r *Renderer) grpcDialect() *filtertree.Dialect {
+	field := func(clause, key string, body map[string]interface{}) interface{} {
+		body["key"] = key
+		return map[string]interface{}{
+			clause: []interface{}{map[string]interface{}{"field": body}},
+		}
+	}
+	return &filtertree.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			if !r.SupportsFilter(op) {
+				return "", false
+			}
+			if op == types.NE || op == types.Exists {
+				return condMustNot, true
+			}
+			return condMust, true
+		},
+		Logic: r.mapLogic,
+		Condition: func(c filtertree.Condition) interface{} {
+			switch c.Operator {
+			case types.Exists, types.NotExists:
+				return map[string]interface{}{
+					c.Spelled: []interface{}{
+						map[string]interface{}{"is_empty": map[string]interface{}{"key": c.Field}},
+					},
+				}
+			case types.GT, types.GE, types.LT, types.LE:
+				return field(c.Spelled, c.Field, map[string]interface{}{
+					"range": map[string]interface{}{rangeOperator(c.Operator): c.Value},
+				})
+			case types.IN:
+				kind, _ := matchKind(c.FieldType)
+				list := map[string]interface{}{"strings": c.Value}
+				if kind == "integer" {
+					list = map[string]interface{}{"integers": c.Value}
+				}
+				return field(c.Spelled, c.Field, map[string]interface{}{
+					"match": map[string]interface{}{kind + "s": list},
+				})
+			case types.Contains:
+				if c.FieldType == "string" {
+					return field(c.Spelled, c.Field, map[string]interface{}{
+						"match": map[string]interface{}{"text": c.Value},
+					})
+				}
+			}
+			// Floats have no exact match; an inclusive range on the value
+			// is equivalent
+			if c.FieldType == "float" {
+				return field(c.Spelled, c.Field, map[string]interface{}{
+					"range": map[string]interface{}{"gte": c.Value, "lte": c.Value},
+				})
+			}
+			kind, _ := matchKind(c.FieldType)
+			return field(c.Spelled, c.Field, map[string]interface{}{
+				"match": map[string]interface{}{kind: c.Value},
+			})
+		},
+		Group: func(logic string, children []interface{}) interface{} {
+			conditions := make([]interface{}, len(children))
+			for i, child := range children {
+				conditions[i] = map[string]interface{}{"filter": child}
+			}
+			return map[string]interface{}{logic: conditions}
+		},
+		Range: func(key string, bounds []filtertree.Bound) interface{} {
+			rangeValues := make(map[string]interface{}, len(bounds))
+			for _, b := range bounds {
+				rangeValues[rangeOperator(b.Operator)] = b.Value
+			}
+			return field(condMust, key, map[string]interface{}{"range": rangeValues})
+		},
+		Geo: func(key string, lat, lon, radius interface{}) interface{} {
+			return field(condMust, key, map[string]interface{}{
+				"geo_radius": map[string]interface{}{
+					"center": map[string]interface{}{"lat": lat, "lon": lon},
+					"radius": radius,
+				},
+			})
+		},
+	}
+}
@@ -0,0 +1,351 @@
+package qdrant
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderGRPCSearchPoints(t *testing.T) {
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:      types.OpSearch,
+		Target:         types.Collection{Name: "products"},
+		QueryEmbedding: &types.EmbeddingField{Name: "description"},
+		QueryVector:    &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:           &types.PaginationValue{Static: &topK},
+		MinScore:       &types.Param{Name: "min_score"},
+		FilterClause: types.FilterGroup{
+			Logic: types.AND,
+			Conditions: []types.FilterItem{
+				types.FilterCondition{
+					Field:    types.MetadataField{Name: "category", Type: "string"},
+					Operator: types.EQ,
+					Value:    types.Param{Name: "cat"},
+				},
+				types.FilterCondition{
+					Field:    types.MetadataField{Name: "stock", Type: "int"},
+					Operator: types.GT,
+					Value:    types.Param{Name: "min_stock"},
+				},
+			},
+		},
+		IncludeMetadata: true,
+		Freshness:       &types.Freshness{Level: types.FreshnessStrong},
+	}
+
+	result, err := NewGRPC().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"collection_name":"products","filter":{"must":[` +
+		`{"filter":{"must":[{"field":{"key":"category","match":{"keyword":":cat"}}}]}},` +
+		`{"filter":{"must":[{"field":{"key":"stock","range":{"gt":":min_stock"}}}]}}]},` +
+		`"limit":10,"read_consistency":{"type":"All"},"score_threshold":":min_score",` +
+		`"vector":":query_vec","vector_name":"description",` +
+		`"with_payload":{"enable":true},"with_vectors":{"enable":false}}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+	for _, f := range result.Features {
+		if f == legacySearch {
+			t.Error("gRPC searches should not carry the points/search notice")
+		}
+	}
+}
+
+func TestRenderGRPCQueryPoints(t *testing.T) {
+	topK := 5
+	renderer := &Renderer{Mode: ModeQuery, Protocol: ProtocolGRPC}
+	result, err := renderer.Render(&types.VectorAST{
+		Operation:       types.OpSearch,
+		Target:          types.Collection{Name: "products", DefaultEmbedding: "description"},
+		QueryVector:     &types.VectorValue{Literal: []float32{0.5, 0.25}},
+		TopK:            &types.PaginationValue{Static: &topK},
+		IncludeMetadata: true,
+		MetadataFields:  []types.MetadataField{{Name: "category"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"collection_name":"products","limit":5,` +
+		`"query":{"nearest":{"dense":{"data":[0.5,0.25]}}},"using":"description",` +
+		`"with_payload":{"include":{"fields":["category"]}},"with_vectors":{"enable":false}}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+}
+
+func TestRenderGRPCUpsertPoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		renderer *Renderer
+		target   types.Collection
+		expected string
+	}{
+		{
+			name:     "uuid ids unnamed vector",
+			renderer: NewGRPC(),
+			target:   types.Collection{Name: "products"},
+			expected: `{"collection_name":"products","points":[{"id":{"uuid":":id1"},` +
+				`"payload":{"price":{"double_value":":price1"}},"vectors":{"vector":{"data":":vec1"}}}]}`,
+		},
+		{
+			name:     "numeric ids named vector",
+			renderer: &Renderer{Protocol: ProtocolGRPC, NumericIDs: true},
+			target:   types.Collection{Name: "products", DefaultEmbedding: "description"},
+			expected: `{"collection_name":"products","points":[{"id":{"num":":id1"},` +
+				`"payload":{"price":{"double_value":":price1"}},` +
+				`"vectors":{"vectors":{"vectors":{"description":{"data":":vec1"}}}}}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.renderer.Render(&types.VectorAST{
+				Operation: types.OpUpsert,
+				Target:    tt.target,
+				Vectors: []types.VectorRecord{{
+					ID:     types.Param{Name: "id1"},
+					Vector: types.VectorValue{Param: &types.Param{Name: "vec1"}},
+					Metadata: map[types.MetadataField]types.Param{
+						{Name: "price", Type: "float"}: {Name: "price1"},
+					},
+				}},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.JSON != tt.expected {
+				t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderGRPCDeletePoints(t *testing.T) {
+	result, err := NewGRPC().Render(&types.VectorAST{
+		Operation: types.OpDelete,
+		Target:    types.Collection{Name: "products"},
+		IDs:       []types.Param{{Name: "id1"}, {Name: "id2"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"collection_name":"products","points":{"points":{"ids":[{"uuid":":id1"},{"uuid":":id2"}]}}}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+
+	result, err = NewGRPC().Render(&types.VectorAST{
+		Operation: types.OpDelete,
+		Target:    types.Collection{Name: "products"},
+		DeleteAll: true,
+		FilterClause: types.FilterCondition{
+			Field:    types.MetadataField{Name: "archived", Type: "bool"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "archived"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = `{"collection_name":"products","points":{"filter":` +
+		`{"must":[{"field":{"key":"archived","match":{"boolean":":archived"}}}]}}}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+}
+
+func TestRenderGRPCGetAndSetPayload(t *testing.T) {
+	result, err := NewGRPC().Render(&types.VectorAST{
+		Operation:       types.OpFetch,
+		Target:          types.Collection{Name: "products"},
+		IDs:             []types.Param{{Name: "id1"}},
+		IncludeMetadata: true,
+		Freshness:       &types.Freshness{Level: types.FreshnessBounded},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"collection_name":"products","ids":[{"uuid":":id1"}],"read_consistency":{"type":"Majority"},` +
+		`"with_payload":{"enable":true},"with_vectors":{"enable":false}}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+
+	result, err = NewGRPC().Render(&types.VectorAST{
+		Operation: types.OpUpdate,
+		Target:    types.Collection{Name: "products"},
+		IDs:       []types.Param{{Name: "id1"}},
+		Updates: map[types.MetadataField]types.Param{
+			{Name: "stock", Type: "int"}: {Name: "stock"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = `{"collection_name":"products","payload":{"stock":{"integer_value":":stock"}},` +
+		`"points_selector":{"points":{"ids":[{"uuid":":id1"}]}}}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+	want := []string{"id1", "stock"}
+	if strings.Join(result.RequiredParams, ",") != strings.Join(want, ",") {
+		t.Errorf("expected RequiredParams=%v, got %v", want, result.RequiredParams)
+	}
+}
+
+func TestRenderGRPCFilterConditions(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    types.MetadataField
+		op       types.FilterOperator
+		expected string
+	}{
+		{"not equal", types.MetadataField{Name: "brand", Type: "string"}, types.NE,
+			`{"must_not":[{"field":{"key":"brand","match":{"keyword":":v"}}}]}`},
+		{"float equal", types.MetadataField{Name: "price", Type: "float"}, types.EQ,
+			`{"must":[{"field":{"key":"price","range":{"gte":":v","lte":":v"}}}]}`},
+		{"in strings", types.MetadataField{Name: "brand", Type: "string"}, types.IN,
+			`{"must":[{"field":{"key":"brand","match":{"keywords":{"strings":":v"}}}}]}`},
+		{"in integers", types.MetadataField{Name: "year", Type: "int"}, types.IN,
+			`{"must":[{"field":{"key":"year","match":{"integers":{"integers":":v"}}}}]}`},
+		{"contains text", types.MetadataField{Name: "title", Type: "string"}, types.Contains,
+			`{"must":[{"field":{"key":"title","match":{"text":":v"}}}]}`},
+		{"contains element", types.MetadataField{Name: "tags", Type: "[]string"}, types.Contains,
+			`{"must":[{"field":{"key":"tags","match":{"keyword":":v"}}}]}`},
+		{"exists", types.MetadataField{Name: "brand"}, types.Exists,
+			`{"must_not":[{"is_empty":{"key":"brand"}}]}`},
+		{"not exists", types.MetadataField{Name: "brand"}, types.NotExists,
+			`{"must":[{"is_empty":{"key":"brand"}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var params []string
+			filter, err := NewGRPC().renderGRPCFilter(types.FilterCondition{
+				Field:    tt.field,
+				Operator: tt.op,
+				Value:    types.Param{Name: "v"},
+			}, &params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result, err := toResult(filter.(map[string]interface{}), params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.JSON != tt.expected {
+				t.Errorf("unexpected filter:\n got: %s\nwant: %s", result.JSON, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderGRPCRejectsUntypedFields(t *testing.T) {
+	tests := []struct {
+		name string
+		ast  *types.VectorAST
+		want string
+	}{
+		{
+			name: "untyped filter",
+			ast: &types.VectorAST{
+				Operation: types.OpDelete,
+				Target:    types.Collection{Name: "products"},
+				DeleteAll: true,
+				FilterClause: types.FilterCondition{
+					Field:    types.MetadataField{Name: "category"},
+					Operator: types.EQ,
+					Value:    types.Param{Name: "cat"},
+				},
+			},
+			want: "needs the field's schema type",
+		},
+		{
+			name: "float in",
+			ast: &types.VectorAST{
+				Operation: types.OpDelete,
+				Target:    types.Collection{Name: "products"},
+				DeleteAll: true,
+				FilterClause: types.FilterCondition{
+					Field:    types.MetadataField{Name: "price", Type: "float"},
+					Operator: types.IN,
+					Value:    types.Param{Name: "prices"},
+				},
+			},
+			want: "does not support",
+		},
+		{
+			name: "untyped payload",
+			ast: &types.VectorAST{
+				Operation: types.OpUpdate,
+				Target:    types.Collection{Name: "products"},
+				IDs:       []types.Param{{Name: "id1"}},
+				Updates: map[types.MetadataField]types.Param{
+					{Name: "category"}: {Name: "cat"},
+				},
+			},
+			want: "has no schema type",
+		},
+		{
+			name: "list payload",
+			ast: &types.VectorAST{
+				Operation: types.OpUpdate,
+				Target:    types.Collection{Name: "products"},
+				IDs:       []types.Param{{Name: "id1"}},
+				Updates: map[types.MetadataField]types.Param{
+					{Name: "tags", Type: "[]string"}: {Name: "tags"},
+				},
+			},
+			want: "unsupported type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewGRPC().Render(tt.ast)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestEndpointGRPC(t *testing.T) {
+	tests := []struct {
+		renderer *Renderer
+		op       types.Operation
+		path     string
+	}{
+		{NewGRPC(), types.OpSearch, "/qdrant.Points/Search"},
+		{&Renderer{Mode: ModeQuery, Protocol: ProtocolGRPC}, types.OpSearch, "/qdrant.Points/Query"},
+		{NewGRPC(), types.OpUpsert, "/qdrant.Points/Upsert"},
+		{NewGRPC(), types.OpDelete, "/qdrant.Points/Delete"},
+		{NewGRPC(), types.OpFetch, "/qdrant.Points/Get"},
+		{NewGRPC(), types.OpUpdate, "/qdrant.Points/SetPayload"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			endpoint, err := tt.renderer.Endpoint(&types.VectorAST{
+				Operation: tt.op,
+				Target:    types.Collection{Name: "products"},
+				Freshness: &types.Freshness{Level: types.FreshnessStrong},
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if endpoint.Method != "POST" || endpoint.Path != tt.path {
+				t.Errorf("expected POST %s, got %s %s", tt.path, endpoint.Method, endpoint.Path)
+			}
+		})
+	}
+}
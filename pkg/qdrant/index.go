@@ -0,0 +1,42 @@
+package qdrant
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// RenderFieldIndex renders a payload index creation call. Qdrant's field
+// schemas share their names with the index kinds. Text fields declaring a
+// language written without spaces, such as Japanese, use the multilingual
+// tokenizer.
+func (r *Renderer) RenderFieldIndex(collection string, field types.MetadataField) (types.Endpoint, string, error) {
+	if collection == "" || field.Name == "" {
+		return types.Endpoint{}, "", fmt.Errorf("collection and field names are required")
+	}
+	switch field.Index {
+	case types.IndexKeyword, types.IndexInteger, types.IndexFloat, types.IndexBool, types.IndexGeo, types.IndexText:
+	default:
+		return types.Endpoint{}, "", fmt.Errorf("unsupported index kind: %q", field.Index)
+	}
+
+	var schema interface{} = string(field.Index)
+	if field.Index == types.IndexText && field.Language != "" {
+		tokenizer := "word"
+		if types.Unsegmented(field.Language) {
+			tokenizer = "multilingual"
+		}
+		schema = map[string]interface{}{"type": "text", "tokenizer": tokenizer, "lowercase": true}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{
+		"field_name":   field.Name,
+		"field_schema": schema,
+	})
+	if err != nil {
+		return types.Endpoint{}, "", fmt.Errorf("failed to serialize payload index: %w", err)
+	}
+	return types.Endpoint{Method: "PUT", Path: "/collections/" + url.PathEscape(collection) + "/index"}, string(data), nil
+}
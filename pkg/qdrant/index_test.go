@@ -0,0 +1,32 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderFieldIndex(t *testing.T) {
+	endpoint, body, err := New().RenderFieldIndex("docs", types.MetadataField{Name: "title", Index: types.IndexText})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Method != "PUT" || endpoint.Path != "/collections/docs/index" {
+		t.Errorf("unexpected endpoint: %+v", endpoint)
+	}
+	if expected := `{"field_name":"title","field_schema":"text"}`; body != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+
+	_, body, err = New().RenderFieldIndex("docs", types.MetadataField{Name: "title_zh", Index: types.IndexText, Language: "zh"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"field_name":"title_zh","field_schema":{"lowercase":true,"tokenizer":"multilingual","type":"text"}}`; body != expected {
+		t.Errorf("expected %s, got %s", expected, body)
+	}
+
+	if _, _, err := New().RenderFieldIndex("docs", types.MetadataField{Name: "title"}); err == nil {
+		t.Error("expected error without an index kind")
+	}
+}
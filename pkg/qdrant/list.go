@@ -0,0 +1,85 @@
+package qdrant
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// SupportsList reports that listings render as a scroll resuming from the
+// previous page's next_page_offset, for either protocol.
+func (r *Renderer) SupportsList() bool {
+	return true
+}
+
+// pageOffset returns the point ID a listing resumes from, or nil for the
+// first page. REST bodies take unsigned integer IDs as numbers and UUIDs as
+// strings.
+func pageOffset(page *types.Page) (interface{}, error) {
+	cursor, err := page.Cursor("qdrant")
+	if err != nil || cursor == "" {
+		return nil, err
+	}
+	if n, err := strconv.ParseUint(cursor, 10, 64); err == nil {
+		return n, nil
+	}
+	return cursor, nil
+}
+
+// renderList renders a listing as a points/scroll request for a page of
+// points matching the filter.
+func (r *Renderer) renderList(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	offset, err := pageOffset(ast.Page)
+	if err != nil {
+		return nil, err
+	}
+	query := map[string]interface{}{
+		"limit":        ast.Page.Size,
+		"with_payload": ast.IncludeMetadata,
+		"with_vector":  ast.IncludeVectors,
+	}
+	if offset != nil {
+		query["offset"] = offset
+	}
+	if ast.FilterClause != nil {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		query["filter"] = filter
+	}
+	return toResult(query, *params)
+}
+
+// grpcListScroll fills a ScrollPoints message for a listing.
+func (r *Renderer) grpcListScroll(ast *types.VectorAST, msg map[string]interface{}, params *[]string) error {
+	cursor, err := ast.Page.Cursor("qdrant")
+	if err != nil {
+		return err
+	}
+	if cursor != "" {
+		// Like point IDs, offsets take the form NumericIDs selects
+		if !r.NumericIDs {
+			msg["offset"] = map[string]interface{}{"uuid": cursor}
+		} else if n, err := strconv.ParseUint(cursor, 10, 64); err == nil {
+			msg["offset"] = map[string]interface{}{"num": n}
+		} else {
+			return fmt.Errorf("invalid qdrant page offset: %q is not a numeric point ID", cursor)
+		}
+	}
+	if ast.FilterClause != nil {
+		filter, err := r.renderGRPCFilter(ast.FilterClause, params)
+		if err != nil {
+			return err
+		}
+		msg["filter"] = filter
+	}
+	msg["limit"] = ast.Page.Size
+	msg["with_payload"] = payloadSelector(ast)
+	msg["with_vectors"] = map[string]interface{}{"enable": ast.IncludeVectors}
+	if rc := grpcReadConsistency(ast); rc != nil {
+		msg["read_consistency"] = rc
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package qdrant
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func listAST(after types.PageToken) *types.VectorAST {
+	return &types.VectorAST{
+		Operation: types.OpFetch,
+		Target:    types.Collection{Name: "products"},
+		Page:      &types.Page{Size: 50, After: after},
+		FilterClause: types.FilterCondition{
+			Field:    types.MetadataField{Name: "category", Type: "string"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "cat"},
+		},
+		IncludeMetadata: true,
+	}
+}
+
+func TestRenderList(t *testing.T) {
+	tests := []struct {
+		name     string
+		after    types.PageToken
+		expected string
+	}{
+		{"first page", types.PageToken{},
+			`{"filter":{"must":[{"key":"category","match":{"value":":cat"}}]},"limit":50,"with_payload":true,"with_vector":false}`},
+		{"uuid offset", types.NewPageToken("qdrant", "5c56c793-69f3-4fbf-87e6-c4bf54c28c26"),
+			`{"filter":{"must":[{"key":"category","match":{"value":":cat"}}]},"limit":50,"offset":"5c56c793-69f3-4fbf-87e6-c4bf54c28c26","with_payload":true,"with_vector":false}`},
+		{"integer offset", types.NewPageToken("qdrant", "42"),
+			`{"filter":{"must":[{"key":"category","match":{"value":":cat"}}]},"limit":50,"offset":42,"with_payload":true,"with_vector":false}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := New().Render(listAST(tt.after))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.JSON != tt.expected {
+				t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, tt.expected)
+			}
+		})
+	}
+
+	endpoint, err := New().Endpoint(listAST(types.PageToken{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Path != "/collections/products/points/scroll" {
+		t.Errorf("unexpected endpoint: %s %s", endpoint.Method, endpoint.Path)
+	}
+}
+
+func TestRenderGRPCList(t *testing.T) {
+	r := NewGRPC()
+	r.NumericIDs = true
+	result, err := r.Render(listAST(types.NewPageToken("qdrant", "42")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.JSON, `"offset":{"num":42}`) {
+		t.Errorf("expected a numeric offset, got %s", result.JSON)
+	}
+
+	_, err = r.Render(listAST(types.NewPageToken("qdrant", "5c56c793-69f3-4fbf-87e6-c4bf54c28c26")))
+	if err == nil || !strings.Contains(err.Error(), "not a numeric point ID") {
+		t.Errorf("expected an offset error, got %v", err)
+	}
+}
@@ -4,7 +4,10 @@ package qdrant
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
 
+	"github.com/zoobzio/vectql/internal/filtertree"
 	"github.com/zoobzio/vectql/internal/types"
 )
 
@@ -27,17 +30,42 @@ const (
 	condShould  = "should"
 )
 
-// Renderer renders VectorAST to Qdrant query format.
+// Renderer renders VectorAST to Qdrant query format. Queries that do not
+// name an embedding use the collection's default embedding as the named
+// vector, or the unnamed vector when the collection declares none.
 type Renderer struct {
-	// DefaultVectorName is the default vector name for named vectors.
-	DefaultVectorName string
+	// Mode selects the search API. The zero value is ModeSearch.
+	Mode Mode
+
+	// Protocol selects REST bodies or gRPC messages. The zero value is
+	// ProtocolREST.
+	Protocol Protocol
+
+	// NumericIDs renders gRPC point IDs as unsigned integers rather than
+	// UUIDs. REST bodies take either form as bound.
+	NumericIDs bool
+
+	// FieldNames maps schema field names to the payload keys the
+	// deployment stores, e.g. a Prefix of "app_". A dotted Prefix addresses
+	// nested payload objects in filters, but writes store flat keys.
+	FieldNames types.FieldNames
+}
+
+// PhysicalFieldNames returns the configured payload key mapping.
+func (r *Renderer) PhysicalFieldNames() types.FieldNames {
+	return r.FieldNames
+}
+
+// legacySearch marks searches rendered for the points/search endpoint.
+var legacySearch = types.FeatureNotice{
+	Feature:   "qdrant.points_search",
+	Stability: types.StabilityDeprecated,
+	Message:   "the points/search body is superseded by points/query; use NewQuery",
 }
 
 // New creates a new Qdrant renderer.
 func New() *Renderer {
-	return &Renderer{
-		DefaultVectorName: "",
-	}
+	return &Renderer{}
 }
 
 // Render converts a VectorAST to Qdrant query format.
@@ -45,12 +73,23 @@ func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
 	if err := ast.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid AST: %w", err)
 	}
+	if r.Protocol == ProtocolGRPC {
+		return r.renderGRPC(ast)
+	}
 
 	var params []string
 
 	switch ast.Operation {
 	case types.OpSearch:
-		return r.renderSearch(ast, &params)
+		if r.Mode == ModeQuery {
+			return r.renderQuery(ast, &params)
+		}
+		result, err := r.renderSearch(ast, &params)
+		if err != nil {
+			return nil, err
+		}
+		result.Features = append(result.Features, legacySearch)
+		return result, nil
 	case types.OpUpsert:
 		return r.renderUpsert(ast, &params)
 	case types.OpDelete:
@@ -65,6 +104,13 @@ func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
 }
 
 func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("qdrant does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("qdrant"); err != nil {
+		return nil, err
+	}
+
 	query := make(map[string]interface{})
 
 	// Vector
@@ -81,8 +127,8 @@ func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.
 	// Named vector support
 	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
 		vectorQuery["name"] = ast.QueryEmbedding.Name
-	} else if r.DefaultVectorName != "" {
-		vectorQuery["name"] = r.DefaultVectorName
+	} else if ast.Target.DefaultEmbedding != "" {
+		vectorQuery["name"] = ast.Target.DefaultEmbedding
 	}
 
 	query["query"] = vectorQuery
@@ -103,6 +149,11 @@ func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.
 		query["score_threshold"] = fmt.Sprintf(":%s", ast.MinScore.Name)
 	}
 
+	// Quantization and indexing search params
+	if sp := searchParams(ast); sp != nil {
+		query["params"] = sp
+	}
+
 	// With payload/vectors
 	query["with_payload"] = ast.IncludeMetadata
 	query["with_vector"] = ast.IncludeVectors
@@ -116,7 +167,45 @@ func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.
 		query["filter"] = filter
 	}
 
-	return toResult(query, *params)
+	result, err := toResult(query, *params)
+	if err != nil {
+		return nil, err
+	}
+	// Scores are vector similarities; there is no score adjustment
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+// searchParams renders the search parameters of ast, or nil for the
+// defaults. Filters whose conditions are all hinted as indexed restrict the
+// search to indexed segments, which skips slow scans of segments still
+// being indexed at the cost of missing their points.
+func searchParams(ast *types.VectorAST) map[string]interface{} {
+	sp := make(map[string]interface{})
+	if q := ast.Quantization; q != nil {
+		sp["quantization"] = quantizationParams(q)
+	}
+	if ast.FilterClause != nil && types.IndexedOnly(ast.FilterClause) {
+		sp["indexed_only"] = true
+	}
+	if len(sp) == 0 {
+		return nil
+	}
+	return sp
+}
+
+// quantizationParams renders the quantization search parameters.
+func quantizationParams(q *types.QuantizationParams) map[string]interface{} {
+	quantization := map[string]interface{}{
+		"ignore":  q.Ignore,
+		"rescore": q.Rescore,
+	}
+	if q.Oversampling != 0 {
+		quantization["oversampling"] = q.Oversampling
+	}
+	return quantization
 }
 
 func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
@@ -129,13 +218,16 @@ func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) (*types.
 		*params = append(*params, record.ID.Name)
 		point["id"] = fmt.Sprintf(":%s", record.ID.Name)
 
-		// Vector
+		// Vector, keyed by name when the collection uses named vectors
+		var vector interface{} = record.Vector.Literal
 		if record.Vector.Param != nil {
 			*params = append(*params, record.Vector.Param.Name)
-			point["vector"] = fmt.Sprintf(":%s", record.Vector.Param.Name)
-		} else {
-			point["vector"] = record.Vector.Literal
+			vector = fmt.Sprintf(":%s", record.Vector.Param.Name)
+		}
+		if name := ast.Target.DefaultEmbedding; name != "" {
+			vector = map[string]interface{}{name: vector}
 		}
+		point["vector"] = vector
 
 		// Payload (metadata)
 		if len(record.Metadata) > 0 {
@@ -179,6 +271,15 @@ func (r *Renderer) renderDelete(ast *types.VectorAST, params *[]string) (*types.
 }
 
 func (r *Renderer) renderFetch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if ast.FetchKey != nil {
+		return r.renderFetchBy(ast, params)
+	}
+	if ast.ExistsByFilter() {
+		return r.renderExists(ast, params)
+	}
+	if ast.Lists() {
+		return r.renderList(ast, params)
+	}
 	ids := make([]string, len(ast.IDs))
 	for i, id := range ast.IDs {
 		*params = append(*params, id.Name)
@@ -216,78 +317,77 @@ func (r *Renderer) renderUpdate(ast *types.VectorAST, params *[]string) (*types.
 }
 
 func (r *Renderer) renderFilter(f types.FilterItem, params *[]string) (interface{}, error) {
-	switch filter := f.(type) {
-	case types.FilterCondition:
-		*params = append(*params, filter.Value.Name)
-		return map[string]interface{}{
-			r.mapConditionType(filter.Operator): []map[string]interface{}{
-				{
-					"key":   filter.Field.Name,
-					"match": map[string]interface{}{"value": fmt.Sprintf(":%s", filter.Value.Name)},
+	return filtertree.New(r.dialect(), params).Compile(f)
+}
+
+// dialect describes Qdrant's must/should/must_not clause filters. Operators
+// spell as the clause a condition belongs to.
+func (r *Renderer) dialect() *filtertree.Dialect {
+	return &filtertree.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			if !r.SupportsFilter(op) {
+				return "", false
+			}
+			return r.mapConditionType(op), true
+		},
+		Logic: r.mapLogic,
+		Condition: func(c filtertree.Condition) interface{} {
+			return map[string]interface{}{
+				c.Spelled: []map[string]interface{}{
+					{
+						"key":   c.Field,
+						"match": map[string]interface{}{"value": c.Value},
+					},
 				},
-			},
-		}, nil
-
-	case types.FilterGroup:
-		conditions := make([]interface{}, 0, len(filter.Conditions))
-		for _, c := range filter.Conditions {
-			rendered, err := r.renderFilter(c, params)
-			if err != nil {
-				return nil, err
 			}
-			conditions = append(conditions, rendered)
-		}
-		return map[string]interface{}{
-			r.mapLogic(filter.Logic): conditions,
-		}, nil
-
-	case types.RangeFilter:
-		rangeCondition := map[string]interface{}{
-			"key": filter.Field.Name,
-		}
-		rangeValues := make(map[string]interface{})
-		if filter.Min != nil {
-			*params = append(*params, filter.Min.Name)
-			if filter.MinExclusive {
-				rangeValues["gt"] = fmt.Sprintf(":%s", filter.Min.Name)
-			} else {
-				rangeValues["gte"] = fmt.Sprintf(":%s", filter.Min.Name)
+		},
+		Group: func(logic string, children []interface{}) interface{} {
+			return map[string]interface{}{logic: children}
+		},
+		Range: func(field string, bounds []filtertree.Bound) interface{} {
+			rangeValues := make(map[string]interface{}, len(bounds))
+			for _, b := range bounds {
+				rangeValues[rangeOperator(b.Operator)] = b.Value
 			}
-		}
-		if filter.Max != nil {
-			*params = append(*params, filter.Max.Name)
-			if filter.MaxExclusive {
-				rangeValues["lt"] = fmt.Sprintf(":%s", filter.Max.Name)
-			} else {
-				rangeValues["lte"] = fmt.Sprintf(":%s", filter.Max.Name)
+			return map[string]interface{}{
+				condMust: []interface{}{
+					map[string]interface{}{
+						"key":   field,
+						"range": rangeValues,
+					},
+				},
 			}
-		}
-		rangeCondition["range"] = rangeValues
-		return map[string]interface{}{
-			condMust: []interface{}{rangeCondition},
-		}, nil
-
-	case types.GeoFilter:
-		*params = append(*params, filter.Center.Lat.Name)
-		*params = append(*params, filter.Center.Lon.Name)
-		*params = append(*params, filter.Radius.Name)
-		return map[string]interface{}{
-			condMust: []map[string]interface{}{
-				{
-					"key": filter.Field.Name,
-					"geo_radius": map[string]interface{}{
-						"center": map[string]interface{}{
-							"lat": fmt.Sprintf(":%s", filter.Center.Lat.Name),
-							"lon": fmt.Sprintf(":%s", filter.Center.Lon.Name),
+		},
+		Geo: func(field string, lat, lon, radius interface{}) interface{} {
+			return map[string]interface{}{
+				condMust: []map[string]interface{}{
+					{
+						"key": field,
+						"geo_radius": map[string]interface{}{
+							"center": map[string]interface{}{
+								"lat": lat,
+								"lon": lon,
+							},
+							"radius": radius,
 						},
-						"radius": fmt.Sprintf(":%s", filter.Radius.Name),
 					},
 				},
-			},
-		}, nil
+			}
+		},
+	}
+}
 
+// rangeOperator maps a range bound operator to its Qdrant range key.
+func rangeOperator(op types.FilterOperator) string {
+	switch op {
+	case types.GT:
+		return "gt"
+	case types.LT:
+		return "lt"
+	case types.LE:
+		return "lte"
 	default:
-		return nil, fmt.Errorf("unsupported filter type: %T", f)
+		return "gte"
 	}
 }
 
@@ -343,3 +443,90 @@ func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
 		return false
 	}
 }
+
+// SupportsModality indicates if Qdrant accepts a search input modality.
+// Qdrant only searches with vectors.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	return m == types.ModalityVector
+}
+
+// SupportsContentEncoding indicates if Qdrant accepts request bodies with a
+// Content-Encoding. Its HTTP server decompresses gzip, deflate, brotli, and
+// zstd payloads.
+func (r *Renderer) SupportsContentEncoding(encoding string) bool {
+	switch encoding {
+	case "gzip", "deflate", "br", "zstd":
+		return true
+	default:
+		return false
+	}
+}
+
+// Capabilities describes the features supported by Qdrant.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("qdrant", r)
+}
+
+// RenderTo writes the Qdrant query body for ast to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// Format reports that Qdrant queries are JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// readConsistency returns the consistency query string for reads with a
+// freshness level. Qdrant reads from one replica by default, which is
+// eventual; a bounded read asks a majority of replicas, since Qdrant has no
+// time-based bound.
+func readConsistency(ast *types.VectorAST) string {
+	if ast.Freshness == nil {
+		return ""
+	}
+	switch ast.Freshness.Level {
+	case types.FreshnessStrong:
+		return "?consistency=all"
+	case types.FreshnessBounded:
+		return "?consistency=majority"
+	default:
+		return ""
+	}
+}
+
+// Endpoint returns the Qdrant REST call for ast, or its gRPC method in
+// ProtocolGRPC.
+func (r *Renderer) Endpoint(ast *types.VectorAST) (types.Endpoint, error) {
+	if r.Protocol == ProtocolGRPC {
+		return r.grpcEndpoint(ast)
+	}
+	points := "/collections/" + url.PathEscape(ast.Target.Name) + "/points"
+	switch ast.Operation {
+	case types.OpSearch:
+		if r.Mode == ModeQuery {
+			return types.Endpoint{Method: "POST", Path: points + "/query" + readConsistency(ast)}, nil
+		}
+		return types.Endpoint{Method: "POST", Path: points + "/search" + readConsistency(ast)}, nil
+	case types.OpUpsert:
+		return types.Endpoint{Method: "PUT", Path: points}, nil
+	case types.OpDelete:
+		return types.Endpoint{Method: "POST", Path: points + "/delete"}, nil
+	case types.OpFetch:
+		if scrolls(ast) {
+			return types.Endpoint{Method: "POST", Path: points + "/scroll" + readConsistency(ast)}, nil
+		}
+		return types.Endpoint{Method: "POST", Path: points + readConsistency(ast)}, nil
+	case types.OpUpdate:
+		return types.Endpoint{Method: "POST", Path: points + "/payload"}, nil
+	default:
+		return types.Endpoint{}, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
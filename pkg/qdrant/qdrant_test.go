@@ -46,12 +46,11 @@ func TestRenderSearch(t *testing.T) {
 
 func TestRenderSearchWithNamedVector(t *testing.T) {
 	renderer := New()
-	renderer.DefaultVectorName = "description_embedding"
 
 	topK := 10
 	ast := &types.VectorAST{
 		Operation: types.OpSearch,
-		Target:    types.Collection{Name: "products"},
+		Target:    types.Collection{Name: "products", DefaultEmbedding: "description_embedding"},
 		QueryVector: &types.VectorValue{
 			Param: &types.Param{Name: "query_vec"},
 		},
@@ -100,6 +99,30 @@ func TestRenderUpsert(t *testing.T) {
 	}
 }
 
+func TestRenderUpsertWithNamedVector(t *testing.T) {
+	renderer := New()
+
+	ast := &types.VectorAST{
+		Operation: types.OpUpsert,
+		Target:    types.Collection{Name: "products", DefaultEmbedding: "description_embedding"},
+		Vectors: []types.VectorRecord{
+			{
+				ID:     types.Param{Name: "id1"},
+				Vector: types.VectorValue{Param: &types.Param{Name: "vec1"}},
+			},
+		},
+	}
+
+	result, err := renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !strings.Contains(result.JSON, `"vector":{"description_embedding":":vec1"}`) {
+		t.Errorf("expected named vector in JSON: %s", result.JSON)
+	}
+}
+
 func TestRenderDelete(t *testing.T) {
 	renderer := New()
 
@@ -270,3 +293,212 @@ func TestLogicMapping(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderFilterRejectsUnsupportedOperator(t *testing.T) {
+	renderer := New()
+
+	filter := types.FilterGroup{
+		Logic: types.AND,
+		Conditions: []types.FilterItem{
+			types.FilterCondition{
+				Field:    types.MetadataField{Name: "category"},
+				Operator: types.EQ,
+				Value:    types.Param{Name: "cat"},
+			},
+			types.FilterCondition{
+				Field:    types.MetadataField{Name: "name"},
+				Operator: types.Matches,
+				Value:    types.Param{Name: "pattern"},
+			},
+		},
+	}
+
+	var params []string
+	if _, err := renderer.renderFilter(filter, &params); err == nil {
+		t.Error("expected error for unsupported operator")
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	renderer := New()
+	caps := renderer.Capabilities()
+
+	if caps.Provider != "qdrant" {
+		t.Errorf("expected provider qdrant, got %q", caps.Provider)
+	}
+	for _, op := range types.FilterOperators {
+		if caps.SupportsFilter(op) != renderer.SupportsFilter(op) {
+			t.Errorf("capabilities disagree with SupportsFilter for %s", op)
+		}
+	}
+	if renderer.Format() != types.FormatJSON {
+		t.Errorf("expected JSON format, got %s", renderer.Format())
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	renderer := New()
+
+	tests := []struct {
+		op     types.Operation
+		method string
+		path   string
+	}{
+		{types.OpSearch, "POST", "/collections/products/points/search"},
+		{types.OpUpsert, "PUT", "/collections/products/points"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.op), func(t *testing.T) {
+			ast := &types.VectorAST{
+				Operation: tt.op,
+				Target:    types.Collection{Name: "products"},
+				IDs:       []types.Param{{Name: "id"}},
+			}
+			endpoint, err := renderer.Endpoint(ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if endpoint.Method != tt.method || endpoint.Path != tt.path {
+				t.Errorf("expected %s %s, got %s %s", tt.method, tt.path, endpoint.Method, endpoint.Path)
+			}
+		})
+	}
+}
+
+func TestEndpointFreshness(t *testing.T) {
+	tests := []struct {
+		level types.FreshnessLevel
+		path  string
+	}{
+		{types.FreshnessStrong, "/collections/products/points/query?consistency=all"},
+		{types.FreshnessBounded, "/collections/products/points/query?consistency=majority"},
+		{types.FreshnessEventual, "/collections/products/points/query"},
+	}
+	for _, tt := range tests {
+		ast := &types.VectorAST{
+			Operation: types.OpSearch,
+			Target:    types.Collection{Name: "products"},
+			Freshness: &types.Freshness{Level: tt.level},
+		}
+		endpoint, err := NewQuery().Endpoint(ast)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if endpoint.Path != tt.path {
+			t.Errorf("%s: expected %s, got %s", tt.level, tt.path, endpoint.Path)
+		}
+	}
+}
+
+func TestRenderSearchRejectsNearText(t *testing.T) {
+	renderer := New()
+
+	topK := 10
+	ast := &types.VectorAST{
+		Operation: types.OpSearch,
+		Target:    types.Collection{Name: "products"},
+		NearText:  &types.NearText{Concepts: types.Param{Name: "concepts"}},
+		TopK:      &types.PaginationValue{Static: &topK},
+	}
+
+	if _, err := renderer.Render(ast); err == nil {
+		t.Error("expected error for text search input")
+	}
+	if renderer.Capabilities().SupportsModality(types.ModalityText) {
+		t.Error("expected text modality to be unsupported")
+	}
+}
+
+func TestRenderSearchQuantization(t *testing.T) {
+	renderer := New()
+
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:    types.OpSearch,
+		Target:       types.Collection{Name: "products"},
+		QueryVector:  &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:         &types.PaginationValue{Static: &topK},
+		Quantization: &types.QuantizationParams{Rescore: true, Oversampling: 2},
+	}
+
+	result, err := renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `"params":{"quantization":{"ignore":false,"oversampling":2,"rescore":true}}`
+	if !strings.Contains(result.JSON, expected) {
+		t.Errorf("expected %s in JSON: %s", expected, result.JSON)
+	}
+}
+
+func TestRenderSearchIndexedOnly(t *testing.T) {
+	topK := 10
+	indexed := &types.FilterHint{Indexed: true}
+	tenant := types.FilterCondition{Field: types.MetadataField{Name: "tenant"}, Operator: types.EQ, Value: types.Param{Name: "tenant"}, Hint: indexed}
+	kind := types.FilterCondition{Field: types.MetadataField{Name: "kind"}, Operator: types.EQ, Value: types.Param{Name: "kind"}}
+
+	ast := &types.VectorAST{
+		Operation:    types.OpSearch,
+		Target:       types.Collection{Name: "products"},
+		QueryVector:  &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:         &types.PaginationValue{Static: &topK},
+		FilterClause: tenant,
+	}
+	for _, r := range []interface {
+		Render(*types.VectorAST) (*types.QueryResult, error)
+	}{New(), NewQuery()} {
+		result, err := r.Render(ast)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(result.JSON, `"params":{"indexed_only":true}`) {
+			t.Errorf("expected indexed_only in JSON: %s", result.JSON)
+		}
+	}
+
+	ast.FilterClause = types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{tenant, kind}}
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(result.JSON, "indexed_only") {
+		t.Errorf("expected no indexed_only with an unindexed condition: %s", result.JSON)
+	}
+}
+
+func TestRenderSearchIgnoresBoosts(t *testing.T) {
+	topK := 10
+	boosts := []types.Boost{{Field: &types.MetadataField{Name: "popularity"}, Weight: 1}}
+	for _, renderer := range []*Renderer{New(), {Mode: ModeQuery}} {
+		result, err := renderer.Render(&types.VectorAST{
+			Operation:   types.OpSearch,
+			Target:      types.Collection{Name: "products"},
+			QueryVector: &types.VectorValue{Param: &types.Param{Name: "v"}},
+			TopK:        &types.PaginationValue{Static: &topK},
+			Boosts:      boosts,
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(result.Ignored) != 1 || result.Ignored[0] != "boosts" {
+			t.Errorf("expected boosts to be ignored, got %v", result.Ignored)
+		}
+	}
+}
+
+func TestRenderSearchRejectsScoring(t *testing.T) {
+	topK := 10
+	for _, renderer := range []*Renderer{New(), {Mode: ModeQuery}} {
+		_, err := renderer.Render(&types.VectorAST{
+			Operation:   types.OpSearch,
+			Target:      types.Collection{Name: "products"},
+			QueryVector: &types.VectorValue{Param: &types.Param{Name: "v"}},
+			TopK:        &types.PaginationValue{Static: &topK},
+			Scoring:     &types.ScoreExpression{Provider: "elasticsearch", Kind: "script_score", Payload: "_score"},
+		})
+		if err == nil {
+			t.Error("expected error for an elasticsearch scoring expression")
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package qdrant
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Mode selects which Qdrant search API the renderer targets.
+type Mode int
+
+const (
+	// ModeSearch renders searches for the legacy points/search endpoint.
+	// This is the default; its searches carry a deprecation notice.
+	ModeSearch Mode = iota
+
+	// ModeQuery renders searches for the universal points/query endpoint
+	// (Qdrant v1.10+), and Endpoint reports its path. Other operations are
+	// unchanged.
+	ModeQuery
+)
+
+// NewQuery creates a Qdrant renderer targeting the universal query API.
+func NewQuery() *Renderer {
+	return &Renderer{Mode: ModeQuery}
+}
+
+// renderQuery renders a SEARCH as a points/query request. The query vector
+// is the nearest-neighbour query itself and the named vector moves to "using".
+func (r *Renderer) renderQuery(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("qdrant does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("qdrant"); err != nil {
+		return nil, err
+	}
+
+	query := make(map[string]interface{})
+
+	if ast.QueryVector != nil {
+		if ast.QueryVector.Param != nil {
+			*params = append(*params, ast.QueryVector.Param.Name)
+			query["query"] = fmt.Sprintf(":%s", ast.QueryVector.Param.Name)
+		} else {
+			query["query"] = ast.QueryVector.Literal
+		}
+	}
+
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
+		query["using"] = ast.QueryEmbedding.Name
+	} else if ast.Target.DefaultEmbedding != "" {
+		query["using"] = ast.Target.DefaultEmbedding
+	}
+
+	if ast.TopK != nil {
+		if ast.TopK.Static != nil {
+			query["limit"] = *ast.TopK.Static
+		} else if ast.TopK.Param != nil {
+			*params = append(*params, ast.TopK.Param.Name)
+			query["limit"] = fmt.Sprintf(":%s", ast.TopK.Param.Name)
+		}
+	}
+
+	if ast.MinScore != nil {
+		*params = append(*params, ast.MinScore.Name)
+		query["score_threshold"] = fmt.Sprintf(":%s", ast.MinScore.Name)
+	}
+
+	if sp := searchParams(ast); sp != nil {
+		query["params"] = sp
+	}
+
+	// The query API accepts a payload selector, so selected fields are sent
+	// by name rather than returning the whole payload
+	if ast.IncludeMetadata && len(ast.MetadataFields) > 0 {
+		fields := make([]string, len(ast.MetadataFields))
+		for i, f := range ast.MetadataFields {
+			fields[i] = f.Name
+		}
+		query["with_payload"] = fields
+	} else {
+		query["with_payload"] = ast.IncludeMetadata
+	}
+	query["with_vector"] = ast.IncludeVectors
+
+	if ast.FilterClause != nil {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		query["filter"] = filter
+	}
+
+	result, err := toResult(query, *params)
+	if err != nil {
+		return nil, err
+	}
+	// Scores are vector similarities; there is no score adjustment
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+// SupportedVersions reports the Qdrant versions the configured mode works
+// with. The universal query API needs Qdrant v1.10.
+func (r *Renderer) SupportedVersions() types.VersionRange {
+	if r.Mode == ModeQuery {
+		return types.VersionRange{Min: "1.10"}
+	}
+	return types.VersionRange{}
+}
@@ -0,0 +1,138 @@
+package qdrant
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderSearchQueryAPI(t *testing.T) {
+	renderer := NewQuery()
+
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:      types.OpSearch,
+		Target:         types.Collection{Name: "products"},
+		QueryEmbedding: &types.EmbeddingField{Name: "description"},
+		QueryVector:    &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:           &types.PaginationValue{Static: &topK},
+		MinScore:       &types.Param{Name: "min_score"},
+		Quantization:   &types.QuantizationParams{Rescore: true, Oversampling: 2},
+		FilterClause: types.FilterCondition{
+			Field:    types.MetadataField{Name: "category"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "cat"},
+		},
+		IncludeMetadata: true,
+		MetadataFields:  []types.MetadataField{{Name: "category"}, {Name: "price"}},
+	}
+
+	result, err := renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"filter":{"must":[{"key":"category","match":{"value":":cat"}}]},"limit":10,` +
+		`"params":{"quantization":{"ignore":false,"oversampling":2,"rescore":true}},` +
+		`"query":":query_vec","score_threshold":":min_score","using":"description",` +
+		`"with_payload":["category","price"],"with_vector":false}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+
+	want := []string{"query_vec", "min_score", "cat"}
+	if len(result.RequiredParams) != len(want) {
+		t.Fatalf("expected RequiredParams=%v, got %v", want, result.RequiredParams)
+	}
+	for i, p := range want {
+		if result.RequiredParams[i] != p {
+			t.Errorf("expected RequiredParams=%v, got %v", want, result.RequiredParams)
+		}
+	}
+}
+
+func TestRenderSearchQueryAPIDefaults(t *testing.T) {
+	topK := 5
+	tests := []struct {
+		name     string
+		target   types.Collection
+		expected string
+	}{
+		{
+			name:     "unnamed vector",
+			target:   types.Collection{Name: "products"},
+			expected: `{"limit":5,"query":[0.5,0.25],"with_payload":true,"with_vector":true}`,
+		},
+		{
+			name:     "default embedding",
+			target:   types.Collection{Name: "products", DefaultEmbedding: "description"},
+			expected: `{"limit":5,"query":[0.5,0.25],"using":"description","with_payload":true,"with_vector":true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewQuery().Render(&types.VectorAST{
+				Operation:       types.OpSearch,
+				Target:          tt.target,
+				QueryVector:     &types.VectorValue{Literal: []float32{0.5, 0.25}},
+				TopK:            &types.PaginationValue{Static: &topK},
+				IncludeMetadata: true,
+				IncludeVectors:  true,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.JSON != tt.expected {
+				t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEndpointQueryAPI(t *testing.T) {
+	renderer := NewQuery()
+
+	search, err := renderer.Endpoint(&types.VectorAST{Operation: types.OpSearch, Target: types.Collection{Name: "products"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if search.Method != "POST" || search.Path != "/collections/products/points/query" {
+		t.Errorf("unexpected search endpoint: %s %s", search.Method, search.Path)
+	}
+
+	// Writes keep their legacy endpoints
+	upsert, err := renderer.Endpoint(&types.VectorAST{Operation: types.OpUpsert, Target: types.Collection{Name: "products"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if upsert.Method != "PUT" || upsert.Path != "/collections/products/points" {
+		t.Errorf("unexpected upsert endpoint: %s %s", upsert.Method, upsert.Path)
+	}
+}
+
+func TestRenderSearch_LegacyDeprecated(t *testing.T) {
+	k := 5
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "v"}},
+		TopK:        &types.PaginationValue{Static: &k},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Features) != 1 || result.Features[0].Stability != types.StabilityDeprecated {
+		t.Errorf("expected a deprecation notice, got %v", result.Features)
+	}
+
+	result, err = NewQuery().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Features) != 0 {
+		t.Errorf("expected no notices in query mode, got %v", result.Features)
+	}
+}
@@ -0,0 +1,476 @@
+// Package redis provides a VECTQL renderer for Redis Stack vector indices
+// queried through RediSearch.
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/filtertree"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// fallbackVectorField is the VECTOR field used when neither the query nor
+// the schema names one.
+const fallbackVectorField = "embedding"
+
+// scoreField is the alias KNN results carry their distance under.
+const scoreField = "__vector_score"
+
+// literalVectorParam names the query parameter a literal query vector is
+// passed as.
+const literalVectorParam = "vector"
+
+// Renderer renders VectorAST to RediSearch command plans. Documents are
+// hashes whose keys are the record IDs, so IDs include the key prefix the
+// index was created with. Searches run FT.SEARCH with a KNN query; every
+// value, including filter values, is passed through PARAMS rather than
+// spliced into the query string.
+type Renderer struct{}
+
+// New creates a new Redis renderer.
+func New() *Renderer {
+	return &Renderer{}
+}
+
+// vectorField returns the VECTOR field a query targets.
+func vectorField(ast *types.VectorAST) string {
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
+		return ast.QueryEmbedding.Name
+	}
+	if ast.Target.DefaultEmbedding != "" {
+		return ast.Target.DefaultEmbedding
+	}
+	return fallbackVectorField
+}
+
+// Render converts a VectorAST to a Redis command plan. The plan is returned
+// in QueryResult.Commands, and as a JSON array of commands in
+// QueryResult.JSON.
+func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	if err := ast.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid AST: %w", err)
+	}
+
+	var params []string
+	var commands []types.Command
+	var err error
+
+	switch ast.Operation {
+	case types.OpSearch:
+		commands, err = r.renderSearch(ast, &params)
+	case types.OpUpsert:
+		commands, err = r.renderUpsert(ast, &params)
+	case types.OpDelete:
+		commands, err = r.renderDelete(ast, &params)
+	case types.OpFetch:
+		commands = r.renderFetch(ast, &params)
+	case types.OpUpdate:
+		commands = r.renderUpdate(ast, &params)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := toResult(commands, params)
+	if err != nil {
+		return nil, err
+	}
+	// Redis reads are consistent on the primary; there is nothing to tune
+	if ast.Freshness != nil && (ast.Operation == types.OpSearch || ast.Operation == types.OpFetch) {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	// KNN queries sort by vector distance alone
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+// toResult serializes a command plan and returns a QueryResult carrying it.
+// Parameters appear as ":name" placeholders in the JSON form.
+func toResult(commands []types.Command, params []string) (*types.QueryResult, error) {
+	plan := make([][]interface{}, len(commands))
+	for i, cmd := range commands {
+		line := make([]interface{}, 0, len(cmd.Args)+1)
+		line = append(line, cmd.Name)
+		for _, arg := range cmd.Args {
+			if arg.Param != "" {
+				line = append(line, fmt.Sprintf(":%s", arg.Param))
+			} else {
+				line = append(line, arg.Value)
+			}
+		}
+		plan[i] = line
+	}
+
+	// Keep "=>" of KNN queries readable
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(plan); err != nil {
+		return nil, fmt.Errorf("failed to serialize query: %w", err)
+	}
+	return &types.QueryResult{
+		JSON:           strings.TrimSuffix(buf.String(), "\n"),
+		RequiredParams: params,
+		Commands:       commands,
+	}, nil
+}
+
+// literal returns a literal argument.
+func literal(v interface{}) types.Arg {
+	return types.Arg{Value: v, Kind: types.ArgScalar}
+}
+
+// param returns an argument bound from a parameter and records it.
+func param(name string, kind types.ArgKind, params *[]string) types.Arg {
+	*params = append(*params, name)
+	return types.Arg{Param: name, Kind: kind}
+}
+
+func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) ([]types.Command, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("redis does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("redis"); err != nil {
+		return nil, err
+	}
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("redis does not support quantization search parameters")
+	}
+	// KNN scores are distances; thresholds need a VECTOR_RANGE query
+	if ast.MinScore != nil {
+		return nil, fmt.Errorf("redis does not support score thresholds on KNN queries")
+	}
+
+	field := vectorField(ast)
+	var queryParams []types.Arg
+	var queryParamNames []string
+	addQueryParam := func(name string, arg types.Arg) {
+		for _, seen := range queryParamNames {
+			if seen == name {
+				return
+			}
+		}
+		queryParamNames = append(queryParamNames, name)
+		queryParams = append(queryParams, literal(name), arg)
+	}
+
+	// Filter, applied before the KNN search ranks candidates
+	prefilter := "*"
+	if ast.FilterClause != nil {
+		start := len(*params)
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		prefilter = filter
+		for _, name := range (*params)[start:] {
+			addQueryParam(name, types.Arg{Param: name, Kind: types.ArgScalar})
+		}
+	}
+
+	// Vector
+	vectorName := literalVectorParam
+	if ast.QueryVector.Param != nil {
+		vectorName = ast.QueryVector.Param.Name
+		addQueryParam(vectorName, param(vectorName, types.ArgBlob, params))
+	} else {
+		addQueryParam(vectorName, types.Arg{Value: ast.QueryVector.Literal, Kind: types.ArgBlob})
+	}
+
+	// k, inline when static; LIMIT takes it as an argument
+	var k string
+	var limit types.Arg
+	if ast.TopK.Static != nil {
+		k = fmt.Sprintf("%d", *ast.TopK.Static)
+		limit = literal(*ast.TopK.Static)
+	} else {
+		k = "$" + ast.TopK.Param.Name
+		limit = param(ast.TopK.Param.Name, types.ArgScalar, params)
+		addQueryParam(ast.TopK.Param.Name, types.Arg{Param: ast.TopK.Param.Name, Kind: types.ArgScalar})
+	}
+
+	query := fmt.Sprintf("%s=>[KNN %s @%s $%s AS %s]", prefilter, k, field, vectorName, scoreField)
+	args := []types.Arg{literal(ast.Target.Name), literal(query)}
+	args = append(args, literal("PARAMS"), literal(len(queryParams)))
+	args = append(args, queryParams...)
+	args = append(args, literal("SORTBY"), literal(scoreField))
+	if fields := returnFields(ast, field); fields != nil {
+		args = append(args, literal("RETURN"), literal(len(fields)))
+		for _, f := range fields {
+			args = append(args, literal(f))
+		}
+	}
+	args = append(args, literal("LIMIT"), literal(0), limit, literal("DIALECT"), literal(2))
+
+	return []types.Command{{Name: "FT.SEARCH", Args: args}}, nil
+}
+
+// returnFields lists the fields FT.SEARCH returns, or nil to return whole
+// hashes. Redis cannot exclude a field, so requesting all metadata also
+// returns the vector.
+func returnFields(ast *types.VectorAST, vectorField string) []string {
+	switch {
+	case !ast.IncludeMetadata && !ast.IncludeVectors:
+		return []string{scoreField}
+	case !ast.IncludeMetadata:
+		return []string{scoreField, vectorField}
+	case len(ast.MetadataFields) > 0:
+		fields := []string{scoreField}
+		for _, f := range ast.MetadataFields {
+			fields = append(fields, f.Name)
+		}
+		if ast.IncludeVectors {
+			fields = append(fields, vectorField)
+		}
+		return fields
+	default:
+		return nil
+	}
+}
+
+func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) ([]types.Command, error) {
+	field := vectorField(ast)
+	commands := make([]types.Command, 0, len(ast.Vectors))
+
+	for _, record := range ast.Vectors {
+		if record.SparseVector != nil {
+			return nil, fmt.Errorf("redis does not support sparse vectors")
+		}
+
+		args := []types.Arg{param(record.ID.Name, types.ArgScalar, params), literal(field)}
+		if record.Vector.Param != nil {
+			args = append(args, param(record.Vector.Param.Name, types.ArgBlob, params))
+		} else {
+			args = append(args, types.Arg{Value: record.Vector.Literal, Kind: types.ArgBlob})
+		}
+
+		values := make(map[string]types.Param, len(record.Metadata))
+		for f, value := range record.Metadata {
+			values[f.Name] = value
+		}
+		args = append(args, fieldArgs(values, params)...)
+
+		commands = append(commands, types.Command{Name: "HSET", Args: args})
+	}
+
+	return commands, nil
+}
+
+// fieldArgs returns field-value pairs for HSET in field name order.
+func fieldArgs(values map[string]types.Param, params *[]string) []types.Arg {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := make([]types.Arg, 0, 2*len(names))
+	for _, name := range names {
+		args = append(args, literal(name), param(values[name].Name, types.ArgScalar, params))
+	}
+	return args
+}
+
+func (r *Renderer) renderDelete(ast *types.VectorAST, params *[]string) ([]types.Command, error) {
+	if len(ast.IDs) == 0 {
+		return nil, fmt.Errorf("redis does not support delete by filter")
+	}
+
+	args := make([]types.Arg, len(ast.IDs))
+	for i, id := range ast.IDs {
+		args[i] = param(id.Name, types.ArgScalar, params)
+	}
+	return []types.Command{{Name: "DEL", Args: args}}, nil
+}
+
+func (r *Renderer) renderFetch(ast *types.VectorAST, params *[]string) []types.Command {
+	commands := make([]types.Command, len(ast.IDs))
+	for i, id := range ast.IDs {
+		commands[i] = types.Command{Name: "HGETALL", Args: []types.Arg{param(id.Name, types.ArgScalar, params)}}
+	}
+	return commands
+}
+
+func (r *Renderer) renderUpdate(ast *types.VectorAST, params *[]string) []types.Command {
+	values := make(map[string]types.Param, len(ast.Updates))
+	for f, value := range ast.Updates {
+		values[f.Name] = value
+	}
+
+	commands := make([]types.Command, len(ast.IDs))
+	for i, id := range ast.IDs {
+		args := []types.Arg{param(id.Name, types.ArgScalar, params)}
+		args = append(args, fieldArgs(values, params)...)
+		commands[i] = types.Command{Name: "HSET", Args: args}
+	}
+	return commands
+}
+
+func (r *Renderer) renderFilter(f types.FilterItem, params *[]string) (string, error) {
+	filter, err := filtertree.New(r.dialect(), params).Compile(f)
+	if err != nil {
+		return "", err
+	}
+	return filter.(string), nil
+}
+
+// dialect returns the filtertree dialect for RediSearch query syntax.
+// Values are referenced as $name query parameters.
+func (r *Renderer) dialect() *filtertree.Dialect {
+	return &filtertree.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			return string(op), r.SupportsFilter(op)
+		},
+		Logic: func(logic types.LogicOperator) string {
+			return string(logic)
+		},
+		Condition: condition,
+		Group:     group,
+		Range: func(field string, bounds []filtertree.Bound) interface{} {
+			lo, hi := "-inf", "+inf"
+			for _, b := range bounds {
+				switch b.Operator {
+				case types.GT:
+					lo = fmt.Sprintf("(%v", b.Value)
+				case types.GE:
+					lo = fmt.Sprintf("%v", b.Value)
+				case types.LT:
+					hi = fmt.Sprintf("(%v", b.Value)
+				case types.LE:
+					hi = fmt.Sprintf("%v", b.Value)
+				}
+			}
+			return fmt.Sprintf("@%s:[%s %s]", field, lo, hi)
+		},
+		Geo: func(field string, lat, lon, radius interface{}) interface{} {
+			return fmt.Sprintf("@%s:[%v %v %v m]", field, lon, lat, radius)
+		},
+		Placeholder: func(name string) interface{} {
+			return "$" + name
+		},
+	}
+}
+
+// condition renders a comparison. Numeric fields compare with ranges; all
+// other fields are matched as TAG fields.
+func condition(c filtertree.Condition) interface{} {
+	switch c.Operator {
+	case types.GT:
+		return fmt.Sprintf("@%s:[(%v +inf]", c.Field, c.Value)
+	case types.GE:
+		return fmt.Sprintf("@%s:[%v +inf]", c.Field, c.Value)
+	case types.LT:
+		return fmt.Sprintf("@%s:[-inf (%v]", c.Field, c.Value)
+	case types.LE:
+		return fmt.Sprintf("@%s:[-inf %v]", c.Field, c.Value)
+	}
+
+	match := fmt.Sprintf("@%s:{%v}", c.Field, c.Value)
+	if numeric(c.FieldType) {
+		match = fmt.Sprintf("@%s:[%v %v]", c.Field, c.Value, c.Value)
+	}
+	if c.Operator == types.NE {
+		return "-" + match
+	}
+	return match
+}
+
+// group renders a parenthesized intersection or union. NOT negates the
+// union, matching documents that satisfy none of the children.
+func group(logic string, children []interface{}) interface{} {
+	parts := make([]string, len(children))
+	for i, child := range children {
+		parts[i] = child.(string)
+	}
+	switch types.LogicOperator(logic) {
+	case types.OR:
+		return "(" + strings.Join(parts, " | ") + ")"
+	case types.NOT:
+		return "-(" + strings.Join(parts, " | ") + ")"
+	default:
+		return "(" + strings.Join(parts, " ") + ")"
+	}
+}
+
+// numeric reports whether a VDML metadata type is indexed as NUMERIC.
+func numeric(fieldType string) bool {
+	switch fieldType {
+	case "int", "float":
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsOperation indicates if Redis supports an operation.
+func (r *Renderer) SupportsOperation(op types.Operation) bool {
+	switch op {
+	case types.OpSearch, types.OpUpsert, types.OpDelete, types.OpFetch, types.OpUpdate:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFilter indicates if Redis supports a filter operator.
+func (r *Renderer) SupportsFilter(op types.FilterOperator) bool {
+	switch op {
+	case types.EQ, types.NE, types.GT, types.GE, types.LT, types.LE:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsMetric indicates if Redis supports a distance metric, as the
+// COSINE, L2, and IP distance metrics.
+func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
+	switch metric {
+	case types.Cosine, types.Euclidean, types.DotProduct:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsModality indicates if Redis accepts a search input modality. Only
+// vector input is rendered.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	return m == types.ModalityVector
+}
+
+// Capabilities describes the features supported by Redis.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("redis", r)
+}
+
+// RenderTo writes the JSON form of the Redis command plan for ast to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// Format reports that Redis command plans are written as JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// Endpoint returns ErrNoEndpoint: commands are sent over the Redis
+// protocol, not HTTP.
+func (r *Renderer) Endpoint(_ *types.VectorAST) (types.Endpoint, error) {
+	return types.Endpoint{}, types.ErrNoEndpoint
+}
@@ -0,0 +1,177 @@
+package redis
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderSearch(t *testing.T) {
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "idx:products"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "vec"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "category"}, Operator: types.EQ, Value: types.Param{Name: "cat"}},
+			types.FilterCondition{Field: types.MetadataField{Name: "price"}, Operator: types.LT, Value: types.Param{Name: "max_price"}},
+		}},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `[["FT.SEARCH","idx:products","(@category:{$cat} @price:[-inf ($max_price])=>[KNN 10 @embedding $vec AS __vector_score]",` +
+		`"PARAMS",6,"cat",":cat","max_price",":max_price","vec",":vec",` +
+		`"SORTBY","__vector_score","RETURN",1,"__vector_score","LIMIT",0,10,"DIALECT",2]]`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if len(result.Commands) != 1 || result.Commands[0].Name != "FT.SEARCH" {
+		t.Fatalf("expected one FT.SEARCH command, got %+v", result.Commands)
+	}
+	if len(result.RequiredParams) != 3 {
+		t.Errorf("expected 3 params, got %v", result.RequiredParams)
+	}
+
+	var kinds []types.ArgKind
+	for _, arg := range result.Commands[0].Args {
+		if arg.Param != "" {
+			kinds = append(kinds, arg.Kind)
+		}
+	}
+	if len(kinds) != 3 || kinds[2] != types.ArgBlob {
+		t.Errorf("expected the query vector to be bound as a blob, got %v", kinds)
+	}
+}
+
+func TestRenderSearchFilters(t *testing.T) {
+	min := types.Param{Name: "lo"}
+	tests := []struct {
+		name     string
+		filter   types.FilterItem
+		expected string
+	}{
+		{
+			"numeric equality",
+			types.FilterCondition{Field: types.MetadataField{Name: "year", Type: "int"}, Operator: types.EQ, Value: types.Param{Name: "y"}},
+			"@year:[$y $y]",
+		},
+		{
+			"not equal",
+			types.FilterCondition{Field: types.MetadataField{Name: "status"}, Operator: types.NE, Value: types.Param{Name: "s"}},
+			"-@status:{$s}",
+		},
+		{
+			"range",
+			types.RangeFilter{Field: types.MetadataField{Name: "price"}, Min: &min, MinExclusive: true},
+			"@price:[($lo +inf]",
+		},
+		{
+			"or",
+			types.FilterGroup{Logic: types.OR, Conditions: []types.FilterItem{
+				types.FilterCondition{Field: types.MetadataField{Name: "a"}, Operator: types.EQ, Value: types.Param{Name: "a"}},
+				types.FilterCondition{Field: types.MetadataField{Name: "b"}, Operator: types.GE, Value: types.Param{Name: "b"}},
+			}},
+			"(@a:{$a} | @b:[$b +inf])",
+		},
+		{
+			"geo",
+			types.GeoFilter{
+				Field:  types.MetadataField{Name: "loc"},
+				Center: types.GeoPoint{Lat: types.Param{Name: "lat"}, Lon: types.Param{Name: "lon"}},
+				Radius: types.Param{Name: "r"},
+			},
+			"@loc:[$lon $lat $r m]",
+		},
+	}
+	for _, tt := range tests {
+		var params []string
+		got, err := New().renderFilter(tt.filter, &params)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestRenderSearchUnsupported(t *testing.T) {
+	topK := 5
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "idx"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "vec"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		MinScore:    &types.Param{Name: "min"},
+	}
+	if _, err := New().Render(ast); err == nil {
+		t.Error("expected an error for a score threshold")
+	}
+
+	ast.MinScore = nil
+	ast.FilterClause = types.FilterCondition{Field: types.MetadataField{Name: "tags"}, Operator: types.IN, Value: types.Param{Name: "t"}}
+	if _, err := New().Render(ast); err == nil {
+		t.Error("expected an error for an unsupported operator")
+	}
+}
+
+func TestRenderWrites(t *testing.T) {
+	upsert := &types.VectorAST{
+		Operation: types.OpUpsert,
+		Target:    types.Collection{Name: "idx"},
+		Vectors: []types.VectorRecord{{
+			ID:     types.Param{Name: "id"},
+			Vector: types.VectorValue{Param: &types.Param{Name: "v"}},
+			Metadata: map[types.MetadataField]types.Param{
+				{Name: "title"}:    {Name: "title"},
+				{Name: "category"}: {Name: "cat"},
+			},
+		}},
+	}
+	result, err := New().Render(upsert)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `[["HSET",":id","embedding",":v","category",":cat","title",":title"]]`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	del := &types.VectorAST{
+		Operation: types.OpDelete,
+		Target:    types.Collection{Name: "idx"},
+		IDs:       []types.Param{{Name: "a"}, {Name: "b"}},
+	}
+	result, err = New().Render(del)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `[["DEL",":a",":b"]]`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	fetch := &types.VectorAST{
+		Operation: types.OpFetch,
+		Target:    types.Collection{Name: "idx"},
+		IDs:       []types.Param{{Name: "a"}, {Name: "b"}},
+	}
+	result, err = New().Render(fetch)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `[["HGETALL",":a"],["HGETALL",":b"]]`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	ast := &types.VectorAST{Operation: types.OpFetch, Target: types.Collection{Name: "idx"}}
+	if _, err := New().Endpoint(ast); !errors.Is(err, types.ErrNoEndpoint) {
+		t.Errorf("expected ErrNoEndpoint, got %v", err)
+	}
+}
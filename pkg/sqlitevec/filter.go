@@ -0,0 +1,91 @@
+package sqlitevec
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/filterexpr"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// filterOperators spells the supported filter operators. The spelling is
+// only a key for comparison below, which writes the JSON1 expression.
+var filterOperators = map[types.FilterOperator]string{
+	types.EQ:               "=",
+	types.EqFold:           "= NOCASE",
+	types.NE:               "!=",
+	types.GT:               ">",
+	types.GE:               ">=",
+	types.LT:               "<",
+	types.LE:               "<=",
+	types.IN:               "IN",
+	types.NotIn:            "NOT IN",
+	types.Contains:         "CONTAINS",
+	types.StartsWith:       "STARTS WITH",
+	types.ArrayContains:    "HAS",
+	types.ArrayContainsAny: "HAS ANY",
+	types.ArrayContainsAll: "HAS ALL",
+}
+
+// renderFilter compiles a filter into a WHERE expression over the metadata
+// column and adds its arguments to s. List values are bound as JSON arrays
+// and expanded with json_each.
+func (r *Renderer) renderFilter(s *statement, f types.FilterItem) (string, error) {
+	var names []string
+	var kinds []types.ArgKind
+	dialect := &filterexpr.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			if !r.SupportsFilter(op) {
+				return "", false
+			}
+			return filterOperators[op], true
+		},
+		Field:       jsonPath,
+		Placeholder: func(string) string { return "?" },
+		Comparison: func(path, op, value string) string {
+			expr, kind := comparison(path, op, value)
+			kinds = append(kinds, kind)
+			return expr
+		},
+		And:                "AND",
+		Or:                 "OR",
+		Not:                "NOT",
+		ParenthesizeGroups: true,
+	}
+
+	expr, err := filterexpr.New(dialect, &names).Compile(f)
+	if err != nil {
+		return "", err
+	}
+	if len(names) != len(kinds) {
+		return "", fmt.Errorf("filter has %d parameters for %d comparisons", len(names), len(kinds))
+	}
+	for i, name := range names {
+		s.args = append(s.args, types.Arg{Param: name, Kind: kinds[i]})
+	}
+	return expr, nil
+}
+
+// comparison writes one condition on the field at a JSON path and reports
+// how its single placeholder is bound.
+func comparison(path, op, value string) (string, types.ArgKind) {
+	field := fmt.Sprintf("json_extract(%s, %s)", metadataColumn, path)
+	elements := fmt.Sprintf("json_each(%s, %s)", metadataColumn, path)
+	switch op {
+	case "= NOCASE":
+		return fmt.Sprintf("%s = %s COLLATE NOCASE", field, value), types.ArgScalar
+	case "IN", "NOT IN":
+		return fmt.Sprintf("%s %s (SELECT value FROM json_each(%s))", field, op, value), types.ArgJSON
+	case "CONTAINS":
+		return fmt.Sprintf("instr(%s, %s) > 0", field, value), types.ArgScalar
+	case "STARTS WITH":
+		return fmt.Sprintf("instr(%s, %s) = 1", field, value), types.ArgScalar
+	case "HAS":
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE value = %s)", elements, value), types.ArgScalar
+	case "HAS ANY":
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM %s WHERE value IN (SELECT value FROM json_each(%s)))", elements, value), types.ArgJSON
+	case "HAS ALL":
+		return fmt.Sprintf("NOT EXISTS (SELECT 1 FROM json_each(%s) AS wanted WHERE wanted.value NOT IN (SELECT value FROM %s))", value, elements), types.ArgJSON
+	default:
+		return fmt.Sprintf("%s %s %s", field, op, value), types.ArgScalar
+	}
+}
@@ -0,0 +1,484 @@
+// Package sqlitevec provides a VECTQL renderer for SQLite with the
+// sqlite-vec extension, for embedded and offline applications.
+//
+// Each collection is a table with an id column, one column per embedding,
+// and a metadata column holding a JSON object:
+//
+//	CREATE TABLE products (id TEXT PRIMARY KEY, embedding BLOB, metadata TEXT);
+//
+// In ModeVec0 the collection is a vec0 virtual table instead, with metadata
+// as an auxiliary column:
+//
+//	CREATE VIRTUAL TABLE products USING vec0(
+//	    id TEXT PRIMARY KEY, embedding float[1536] distance_metric=cosine, +metadata TEXT);
+//
+// Statements use ? placeholders; bind them with vectql.BindArgs and run them
+// with database/sql or the sqlexec package.
+package sqlitevec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/filterexpr"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// fallbackVectorField is the vector column used when neither the query nor
+// the collection names an embedding.
+const fallbackVectorField = "embedding"
+
+// Column names with a fixed meaning. They match the columns sqlexec scans.
+const (
+	idColumn       = "id"
+	metadataColumn = "metadata"
+)
+
+// Mode selects how collections are stored.
+type Mode int
+
+const (
+	// ModeTable stores collections in ordinary tables and ranks searches
+	// with vec_distance_* functions over a full scan, so metadata filters
+	// apply exactly. This is the default.
+	ModeTable Mode = iota
+
+	// ModeVec0 stores collections in vec0 virtual tables and searches with
+	// their KNN index (embedding MATCH ? AND k = ?). The distance metric is
+	// fixed by the table declaration, and vec0 cannot filter on auxiliary
+	// columns, so searches and deletes with metadata filters are rejected.
+	ModeVec0
+)
+
+// Renderer renders VectorAST to parameterized SQLite statements.
+type Renderer struct {
+	// Mode selects the table layout. The zero value is ModeTable.
+	Mode Mode
+}
+
+// New creates a new sqlite-vec renderer for ordinary tables.
+func New() *Renderer {
+	return &Renderer{}
+}
+
+// NewVec0 creates a new sqlite-vec renderer for vec0 virtual tables.
+func NewVec0() *Renderer {
+	return &Renderer{Mode: ModeVec0}
+}
+
+// statement accumulates SQL text and its positional arguments.
+type statement struct {
+	sql  strings.Builder
+	args []types.Arg
+}
+
+// param adds an argument bound to p and returns its placeholder.
+func (s *statement) param(p types.Param, kind types.ArgKind) string {
+	s.args = append(s.args, types.Arg{Param: p.Name, Kind: kind})
+	return "?"
+}
+
+// literal adds an argument with a fixed value and returns its placeholder.
+func (s *statement) literal(v interface{}, kind types.ArgKind) string {
+	s.args = append(s.args, types.Arg{Value: v, Kind: kind})
+	return "?"
+}
+
+// vector adds a dense vector argument, bound as "[x,y,...]" text.
+func (s *statement) vector(v types.VectorValue) string {
+	if v.Param != nil {
+		return s.param(*v.Param, types.ArgVector)
+	}
+	return s.literal(v.Literal, types.ArgVector)
+}
+
+// ids adds the ID arguments and returns an "id IN (?, ...)" condition.
+func (s *statement) ids(ids []types.Param) string {
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		placeholders[i] = s.param(id, types.ArgScalar)
+	}
+	return fmt.Sprintf("%s IN (%s)", idColumn, strings.Join(placeholders, ", "))
+}
+
+func (s *statement) write(parts ...string) {
+	for _, p := range parts {
+		s.sql.WriteString(p)
+	}
+}
+
+// Render converts a VectorAST to a QueryResult whose JSON holds the
+// statement and its arguments, {"sql": ..., "args": [...]}, with parameters
+// as ":name" placeholders. Use RenderSQL to execute the statement.
+func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	stmt, args, err := r.RenderSQL(ast)
+	if err != nil {
+		return nil, err
+	}
+
+	params := make([]string, 0, len(args))
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		if arg.Param != "" {
+			params = append(params, arg.Param)
+			values[i] = fmt.Sprintf(":%s", arg.Param)
+		} else {
+			values[i] = arg.Value
+		}
+	}
+
+	// Keep comparison operators of the statement readable
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(map[string]interface{}{"sql": stmt, "args": values}); err != nil {
+		return nil, fmt.Errorf("failed to serialize query: %w", err)
+	}
+	result := &types.QueryResult{
+		JSON:           strings.TrimSuffix(buf.String(), "\n"),
+		RequiredParams: params,
+	}
+
+	// SQLite reads see every committed write; there is nothing to tune
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	// Rows are ordered by distance alone
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+// RenderSQL converts a VectorAST to a SQLite statement and its arguments.
+func (r *Renderer) RenderSQL(ast *types.VectorAST) (string, []types.Arg, error) {
+	if err := ast.Validate(); err != nil {
+		return "", nil, fmt.Errorf("invalid AST: %w", err)
+	}
+	// SQLite has no namespaces; tenants are separate tables or databases
+	if ast.Namespace != nil {
+		return "", nil, fmt.Errorf("sqlite-vec does not support namespaces")
+	}
+	table, err := filterexpr.ValidateIdentifier(ast.Target.Name)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid table name: %w", err)
+	}
+
+	s := &statement{}
+	switch ast.Operation {
+	case types.OpSearch:
+		err = r.renderSearch(s, table, ast)
+	case types.OpUpsert:
+		err = r.renderUpsert(s, table, ast)
+	case types.OpDelete:
+		err = r.renderDelete(s, table, ast)
+	case types.OpFetch:
+		err = r.renderFetch(s, table, ast)
+	case types.OpUpdate:
+		err = r.renderUpdate(s, table, ast)
+	default:
+		err = fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return s.sql.String(), s.args, nil
+}
+
+// vectorField returns the vector column a query targets.
+func vectorField(ast *types.VectorAST) (string, error) {
+	field := fallbackVectorField
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
+		field = ast.QueryEmbedding.Name
+	} else if ast.Target.DefaultEmbedding != "" {
+		field = ast.Target.DefaultEmbedding
+	}
+	name, err := filterexpr.ValidateIdentifier(field)
+	if err != nil {
+		return "", fmt.Errorf("invalid vector column: %w", err)
+	}
+	return name, nil
+}
+
+// distanceFunctions maps metrics to sqlite-vec distance functions.
+var distanceFunctions = map[types.DistanceMetric]string{
+	types.Cosine:    "vec_distance_cosine",
+	types.Euclidean: "vec_distance_L2",
+}
+
+func (r *Renderer) renderSearch(s *statement, table string, ast *types.VectorAST) error {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return fmt.Errorf("sqlite-vec does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("sqlitevec"); err != nil {
+		return err
+	}
+	if ast.Quantization != nil {
+		return fmt.Errorf("sqlite-vec does not support quantization search parameters")
+	}
+	// Scores are distances, so a similarity threshold has no direct form
+	if ast.MinScore != nil {
+		return fmt.Errorf("sqlite-vec does not support score thresholds")
+	}
+	field, err := vectorField(ast)
+	if err != nil {
+		return err
+	}
+
+	if r.Mode == ModeVec0 {
+		if ast.FilterClause != nil {
+			return fmt.Errorf("sqlite-vec vec0 searches cannot filter on metadata")
+		}
+		columns, err := selectColumns(ast, field)
+		if err != nil {
+			return err
+		}
+		s.write("SELECT ", idColumn, ", distance AS score", columns, " FROM ", table)
+		s.write(" WHERE ", field, " MATCH ", s.vector(*ast.QueryVector), " AND k = ", topK(s, ast))
+		s.write(" ORDER BY distance")
+		return nil
+	}
+
+	// The metric of the searched embedding picks the distance function;
+	// cosine is assumed only when the schema does not declare one
+	metric := ast.TargetEmbedding().Metric
+	if metric == "" {
+		metric = types.Cosine
+	}
+	fn, ok := distanceFunctions[metric]
+	if !ok {
+		return fmt.Errorf("sqlite-vec does not support the %s metric", metric)
+	}
+	columns, err := selectColumns(ast, field)
+	if err != nil {
+		return err
+	}
+	s.write("SELECT ", idColumn, ", ", fn, "(", field, ", ", s.vector(*ast.QueryVector), ") AS score", columns, " FROM ", table)
+	if ast.FilterClause != nil {
+		where, err := r.renderFilter(s, ast.FilterClause)
+		if err != nil {
+			return err
+		}
+		s.write(" WHERE ", where)
+	}
+	s.write(" ORDER BY score LIMIT ", topK(s, ast))
+	return nil
+}
+
+// topK adds the result count argument and returns its placeholder.
+func topK(s *statement, ast *types.VectorAST) string {
+	if ast.TopK.Static != nil {
+		return s.literal(*ast.TopK.Static, types.ArgScalar)
+	}
+	return s.param(*ast.TopK.Param, types.ArgScalar)
+}
+
+// selectColumns lists the optional result columns: the vector and either
+// the whole metadata object or the selected fields, one column each.
+func selectColumns(ast *types.VectorAST, field string) (string, error) {
+	var b strings.Builder
+	if ast.IncludeVectors {
+		fmt.Fprintf(&b, ", %s AS vector", field)
+	}
+	if !ast.IncludeMetadata {
+		return b.String(), nil
+	}
+	if len(ast.MetadataFields) == 0 {
+		b.WriteString(", " + metadataColumn)
+		return b.String(), nil
+	}
+	for _, f := range ast.MetadataFields {
+		path, err := jsonPath(f.Name)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, ", json_extract(%s, %s) AS %s", metadataColumn, path, f.Name)
+	}
+	return b.String(), nil
+}
+
+func (r *Renderer) renderFetch(s *statement, table string, ast *types.VectorAST) error {
+	field, err := vectorField(ast)
+	if err != nil {
+		return err
+	}
+	columns, err := selectColumns(ast, field)
+	if err != nil {
+		return err
+	}
+	s.write("SELECT ", idColumn, columns, " FROM ", table, " WHERE ", s.ids(ast.IDs))
+	return nil
+}
+
+func (r *Renderer) renderDelete(s *statement, table string, ast *types.VectorAST) error {
+	s.write("DELETE FROM ", table, " WHERE ")
+	if len(ast.IDs) > 0 {
+		s.write(s.ids(ast.IDs))
+		if ast.FilterClause != nil {
+			s.write(" AND ")
+		}
+	}
+	if ast.FilterClause != nil {
+		if r.Mode == ModeVec0 {
+			return fmt.Errorf("sqlite-vec vec0 deletes cannot filter on metadata")
+		}
+		where, err := r.renderFilter(s, ast.FilterClause)
+		if err != nil {
+			return err
+		}
+		s.write(where)
+	}
+	return nil
+}
+
+// renderUpsert inserts the records, replacing rows with the same id. vec0
+// tables do not support ON CONFLICT, so in ModeVec0 the rows are deleted
+// first; the driver must execute both statements, as mattn/go-sqlite3 and
+// modernc.org/sqlite do.
+func (r *Renderer) renderUpsert(s *statement, table string, ast *types.VectorAST) error {
+	field, err := vectorField(ast)
+	if err != nil {
+		return err
+	}
+
+	if r.Mode == ModeVec0 {
+		ids := make([]types.Param, len(ast.Vectors))
+		for i, record := range ast.Vectors {
+			ids[i] = record.ID
+		}
+		s.write("DELETE FROM ", table, " WHERE ", s.ids(ids), "; ")
+	}
+
+	s.write("INSERT INTO ", table, " (", idColumn, ", ", field, ", ", metadataColumn, ") VALUES ")
+	for i, record := range ast.Vectors {
+		if record.SparseVector != nil {
+			return fmt.Errorf("sqlite-vec does not support sparse vectors")
+		}
+		if i > 0 {
+			s.write(", ")
+		}
+		s.write("(", s.param(record.ID, types.ArgScalar), ", vec_f32(", s.vector(record.Vector), "), json_object(")
+		for j, f := range sortedFields(record.Metadata) {
+			if _, err := filterexpr.ValidateIdentifier(f.Name); err != nil {
+				return err
+			}
+			if j > 0 {
+				s.write(", ")
+			}
+			s.write("'", f.Name, "', ", jsonValue(s, f, record.Metadata[f]))
+		}
+		s.write("))")
+	}
+	if r.Mode == ModeTable {
+		s.write(" ON CONFLICT (", idColumn, ") DO UPDATE SET ",
+			field, " = excluded.", field, ", ", metadataColumn, " = excluded.", metadataColumn)
+	}
+	return nil
+}
+
+// renderUpdate sets metadata fields in place with json_set, keeping the
+// other fields of each row.
+func (r *Renderer) renderUpdate(s *statement, table string, ast *types.VectorAST) error {
+	s.write("UPDATE ", table, " SET ", metadataColumn, " = json_set(coalesce(", metadataColumn, ", '{}')")
+	for _, f := range sortedFields(ast.Updates) {
+		path, err := jsonPath(f.Name)
+		if err != nil {
+			return err
+		}
+		s.write(", ", path, ", ", jsonValue(s, f, ast.Updates[f]))
+	}
+	s.write(") WHERE ", s.ids(ast.IDs))
+	return nil
+}
+
+// jsonValue adds a metadata value argument. Array fields are bound as JSON
+// text and wrapped in json() so they are stored as arrays, not strings.
+func jsonValue(s *statement, field types.MetadataField, value types.Param) string {
+	if strings.HasPrefix(field.Type, "[]") {
+		return "json(" + s.param(value, types.ArgJSON) + ")"
+	}
+	return s.param(value, types.ArgScalar)
+}
+
+// jsonPath returns the quoted JSON1 path of a metadata field.
+func jsonPath(name string) (string, error) {
+	name, err := filterexpr.ValidateIdentifier(name)
+	if err != nil {
+		return "", err
+	}
+	return "'$." + name + "'", nil
+}
+
+// sortedFields returns the fields of a metadata map in name order, so that
+// rendered statements are deterministic.
+func sortedFields(metadata map[types.MetadataField]types.Param) []types.MetadataField {
+	fields := make([]types.MetadataField, 0, len(metadata))
+	for f := range metadata {
+		fields = append(fields, f)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
+
+// SupportsOperation indicates if sqlite-vec supports an operation.
+func (r *Renderer) SupportsOperation(op types.Operation) bool {
+	switch op {
+	case types.OpSearch, types.OpUpsert, types.OpDelete, types.OpFetch, types.OpUpdate:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFilter indicates if a filter operator can be expressed with JSON1
+// functions. vec0 tables support no metadata filters.
+func (r *Renderer) SupportsFilter(op types.FilterOperator) bool {
+	if r.Mode == ModeVec0 {
+		return false
+	}
+	_, ok := filterOperators[op]
+	return ok
+}
+
+// SupportsMetric indicates if sqlite-vec has a distance function for a
+// metric.
+func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
+	_, ok := distanceFunctions[metric]
+	return ok
+}
+
+// SupportsModality indicates if sqlite-vec accepts a search input modality.
+// The extension has no embedding models, so queries carry vectors.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	return m == types.ModalityVector
+}
+
+// Capabilities describes the features supported by sqlite-vec.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("sqlitevec", r)
+}
+
+// RenderTo writes the statement and its arguments as JSON to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// Format reports that rendered statements are described as JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// Endpoint returns ErrNoEndpoint: SQLite is embedded, not called over HTTP.
+func (r *Renderer) Endpoint(_ *types.VectorAST) (types.Endpoint, error) {
+	return types.Endpoint{}, types.ErrNoEndpoint
+}
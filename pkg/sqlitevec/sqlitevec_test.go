@@ -0,0 +1,322 @@
+package sqlitevec
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderSearch(t *testing.T) {
+	topK := 10
+	min := types.Param{Name: "min_price"}
+	ast := &types.VectorAST{
+		Operation:      types.OpSearch,
+		Target:         types.Collection{Name: "products"},
+		QueryVector:    &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		QueryEmbedding: &types.EmbeddingField{Name: "image_vec", Metric: types.Euclidean},
+		TopK:           &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.EQ, Value: types.Param{Name: "color"}},
+			types.FilterGroup{Logic: types.OR, Conditions: []types.FilterItem{
+				types.FilterCondition{Field: types.MetadataField{Name: "brand"}, Operator: types.IN, Value: types.Param{Name: "brands"}},
+				types.FilterCondition{Field: types.MetadataField{Name: "tags"}, Operator: types.ArrayContains, Value: types.Param{Name: "tag"}},
+			}},
+			types.RangeFilter{Field: types.MetadataField{Name: "price"}, Min: &min},
+		}},
+		IncludeMetadata: true,
+		MetadataFields:  []types.MetadataField{{Name: "title"}},
+	}
+
+	stmt, args, err := New().RenderSQL(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "SELECT id, vec_distance_L2(image_vec, ?) AS score, json_extract(metadata, '$.title') AS title FROM products" +
+		" WHERE (json_extract(metadata, '$.color') = ?" +
+		" AND (json_extract(metadata, '$.brand') IN (SELECT value FROM json_each(?))" +
+		" OR EXISTS (SELECT 1 FROM json_each(metadata, '$.tags') WHERE value = ?))" +
+		" AND (json_extract(metadata, '$.price') >= ?))" +
+		" ORDER BY score LIMIT ?"
+	if stmt != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stmt)
+	}
+
+	want := []types.Arg{
+		{Param: "query_vec", Kind: types.ArgVector},
+		{Param: "color", Kind: types.ArgScalar},
+		{Param: "brands", Kind: types.ArgJSON},
+		{Param: "tag", Kind: types.ArgScalar},
+		{Param: "min_price", Kind: types.ArgScalar},
+		{Value: 10, Kind: types.ArgScalar},
+	}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("expected args %v, got %v", want, args)
+	}
+}
+
+func TestRenderSearchJSON(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation:      types.OpSearch,
+		Target:         types.Collection{Name: "products"},
+		QueryVector:    &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:           &types.PaginationValue{Param: &types.Param{Name: "k"}},
+		IncludeVectors: true,
+		Freshness:      &types.Freshness{Level: types.FreshnessStrong},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"args":[":query_vec",":k"],"sql":"SELECT id, vec_distance_cosine(embedding, ?) AS score, embedding AS vector FROM products ORDER BY score LIMIT ?"}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if !reflect.DeepEqual(result.RequiredParams, []string{"query_vec", "k"}) {
+		t.Errorf("unexpected params %v", result.RequiredParams)
+	}
+	if len(result.Ignored) != 1 || result.Ignored[0] != "freshness" {
+		t.Errorf("expected freshness to be ignored, got %v", result.Ignored)
+	}
+}
+
+func TestRenderSearchVec0(t *testing.T) {
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:       types.OpSearch,
+		Target:          types.Collection{Name: "products"},
+		QueryVector:     &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:            &types.PaginationValue{Static: &topK},
+		IncludeMetadata: true,
+	}
+
+	stmt, args, err := NewVec0().RenderSQL(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "SELECT id, distance AS score, metadata FROM products WHERE embedding MATCH ? AND k = ? ORDER BY distance"
+	if stmt != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stmt)
+	}
+	if len(args) != 2 {
+		t.Errorf("expected 2 args, got %v", args)
+	}
+
+	// vec0 KNN queries only take constraints on the vector and k
+	ast.FilterClause = types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.EQ, Value: types.Param{Name: "color"}}
+	if _, _, err := NewVec0().RenderSQL(ast); err == nil || !strings.Contains(err.Error(), "cannot filter on metadata") {
+		t.Errorf("expected error for a vec0 search with a filter, got %v", err)
+	}
+}
+
+func TestRenderSearchEqFold(t *testing.T) {
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:    types.OpSearch,
+		Target:       types.Collection{Name: "products"},
+		QueryVector:  &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:         &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.EqFold, Value: types.Param{Name: "color"}},
+	}
+	stmt, _, err := New().RenderSQL(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stmt, "WHERE json_extract(metadata, '$.color') = ? COLLATE NOCASE ORDER BY") {
+		t.Errorf("expected a NOCASE comparison, got %s", stmt)
+	}
+}
+
+func TestRenderSearchUnsupported(t *testing.T) {
+	topK := 10
+	vector := &types.VectorValue{Param: &types.Param{Name: "query_vec"}}
+
+	tests := []struct {
+		name   string
+		ast    *types.VectorAST
+		errMsg string
+	}{
+		{
+			// SQLite ships no REGEXP function
+			name: "regex filter",
+			ast: &types.VectorAST{
+				Operation:    types.OpSearch,
+				Target:       types.Collection{Name: "products"},
+				QueryVector:  vector,
+				TopK:         &types.PaginationValue{Static: &topK},
+				FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "title"}, Operator: types.Matches, Value: types.Param{Name: "re"}},
+			},
+			errMsg: "unsupported filter operator",
+		},
+		{
+			// sqlite-vec has distance functions for cosine and L2 only
+			name: "dot product query embedding",
+			ast: &types.VectorAST{
+				Operation:      types.OpSearch,
+				Target:         types.Collection{Name: "products"},
+				QueryVector:    vector,
+				QueryEmbedding: &types.EmbeddingField{Name: "embedding", Metric: types.DotProduct},
+				TopK:           &types.PaginationValue{Static: &topK},
+			},
+			errMsg: "metric",
+		},
+		{
+			name: "dot product collection embedding",
+			ast: &types.VectorAST{
+				Operation:   types.OpSearch,
+				Target:      types.Collection{Name: "products", Embedding: types.EmbeddingField{Name: "embedding", Metric: types.DotProduct}},
+				QueryVector: vector,
+				TopK:        &types.PaginationValue{Static: &topK},
+			},
+			errMsg: "metric",
+		},
+		{
+			// Table names are written into the statement, not bound
+			name: "table name",
+			ast: &types.VectorAST{
+				Operation:   types.OpSearch,
+				Target:      types.Collection{Name: "products; DROP TABLE x"},
+				QueryVector: vector,
+				TopK:        &types.PaginationValue{Static: &topK},
+			},
+			errMsg: "invalid table name",
+		},
+	}
+	for _, tt := range tests {
+		_, _, err := New().RenderSQL(tt.ast)
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.errMsg, err)
+		}
+	}
+}
+
+func TestRenderSearch_CollectionMetric(t *testing.T) {
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products", Embedding: types.EmbeddingField{Name: "embedding", Metric: types.Euclidean}},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+	}
+
+	stmt, _, err := New().RenderSQL(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stmt, "vec_distance_L2(embedding, ?)") {
+		t.Errorf("expected the collection embedding's L2 distance, got %s", stmt)
+	}
+}
+
+func TestRenderUpsert(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation: types.OpUpsert,
+		Target:    types.Collection{Name: "products"},
+		Vectors: []types.VectorRecord{{
+			ID:     types.Param{Name: "id"},
+			Vector: types.VectorValue{Param: &types.Param{Name: "vec"}},
+			Metadata: map[types.MetadataField]types.Param{
+				{Name: "tags", Type: "[]string"}: {Name: "tags"},
+				{Name: "color"}:                  {Name: "color"},
+			},
+		}},
+	}
+
+	stmt, args, err := New().RenderSQL(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := "INSERT INTO products (id, embedding, metadata) VALUES (?, vec_f32(?), json_object('color', ?, 'tags', json(?)))" +
+		" ON CONFLICT (id) DO UPDATE SET embedding = excluded.embedding, metadata = excluded.metadata"
+	if stmt != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, stmt)
+	}
+	if len(args) != 4 || args[3].Kind != types.ArgJSON {
+		t.Errorf("expected the tags to bind as JSON, got %v", args)
+	}
+
+	stmt, _, err = NewVec0().RenderSQL(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(stmt, "DELETE FROM products WHERE id IN (?); INSERT INTO products") || strings.Contains(stmt, "ON CONFLICT") {
+		t.Errorf("unexpected vec0 upsert: %s", stmt)
+	}
+}
+
+func TestRenderDeleteFetchUpdate(t *testing.T) {
+	ids := []types.Param{{Name: "id1"}, {Name: "id2"}}
+
+	stmt, _, err := New().RenderSQL(&types.VectorAST{Operation: types.OpDelete, Target: types.Collection{Name: "products"}, IDs: ids})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "DELETE FROM products WHERE id IN (?, ?)"; stmt != expected {
+		t.Errorf("expected %s, got %s", expected, stmt)
+	}
+
+	filtered := &types.VectorAST{
+		Operation:    types.OpDelete,
+		Target:       types.Collection{Name: "products"},
+		FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "a"}, Operator: types.StartsWith, Value: types.Param{Name: "a"}},
+		DeleteAll:    true,
+	}
+	stmt, _, err = New().RenderSQL(filtered)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "DELETE FROM products WHERE instr(json_extract(metadata, '$.a'), ?) = 1"; stmt != expected {
+		t.Errorf("expected %s, got %s", expected, stmt)
+	}
+	if _, _, err := NewVec0().RenderSQL(filtered); err == nil {
+		t.Error("expected error for a vec0 delete by filter")
+	}
+
+	stmt, _, err = New().RenderSQL(&types.VectorAST{Operation: types.OpFetch, Target: types.Collection{Name: "products"}, IDs: ids, IncludeMetadata: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "SELECT id, metadata FROM products WHERE id IN (?, ?)"; stmt != expected {
+		t.Errorf("expected %s, got %s", expected, stmt)
+	}
+
+	stmt, args, err := New().RenderSQL(&types.VectorAST{
+		Operation: types.OpUpdate,
+		Target:    types.Collection{Name: "products"},
+		IDs:       ids[:1],
+		Updates:   map[types.MetadataField]types.Param{{Name: "color"}: {Name: "color"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "UPDATE products SET metadata = json_set(coalesce(metadata, '{}'), '$.color', ?) WHERE id IN (?)"; stmt != expected {
+		t.Errorf("expected %s, got %s", expected, stmt)
+	}
+	if len(args) != 2 || args[0].Param != "color" || args[1].Param != "id1" {
+		t.Errorf("unexpected args %v", args)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	caps := New().Capabilities()
+	if !caps.SupportsFilter(types.ArrayContainsAll) || caps.SupportsFilter(types.Matches) {
+		t.Errorf("unexpected table filters: %v", caps.Filters)
+	}
+	if len(NewVec0().Capabilities().Filters) != 0 {
+		t.Error("expected vec0 tables to support no filters")
+	}
+	if !caps.SupportsMetric(types.Cosine) || caps.SupportsMetric(types.DotProduct) {
+		t.Errorf("unexpected metrics: %v", caps.Metrics)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	ast := &types.VectorAST{Operation: types.OpSearch, Target: types.Collection{Name: "products"}}
+	if _, err := New().Endpoint(ast); !errors.Is(err, types.ErrNoEndpoint) {
+		t.Errorf("expected ErrNoEndpoint, got %v", err)
+	}
+}
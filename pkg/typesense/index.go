@@ -0,0 +1,52 @@
+package typesense
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// indexFieldTypes maps index kinds to Typesense field types.
+var indexFieldTypes = map[types.IndexKind]string{
+	types.IndexKeyword: "string",
+	types.IndexText:    "string",
+	types.IndexInteger: "int64",
+	types.IndexFloat:   "float",
+	types.IndexBool:    "bool",
+	types.IndexGeo:     "geopoint",
+}
+
+// RenderFieldIndex renders a collection schema update that adds the field.
+// Keyword fields are facetable, and text fields carry their declared
+// language as the field locale, which selects Typesense's tokenizer.
+func (r *Renderer) RenderFieldIndex(collection string, field types.MetadataField) (types.Endpoint, string, error) {
+	if collection == "" || field.Name == "" {
+		return types.Endpoint{}, "", fmt.Errorf("collection and field names are required")
+	}
+	fieldType, ok := indexFieldTypes[field.Index]
+	if !ok {
+		return types.Endpoint{}, "", fmt.Errorf("unsupported index kind: %q", field.Index)
+	}
+	if strings.HasPrefix(field.Type, "[]") {
+		fieldType += "[]"
+	}
+
+	def := map[string]interface{}{"name": field.Name, "type": fieldType, "index": true}
+	switch field.Index {
+	case types.IndexKeyword:
+		def["facet"] = true
+	case types.IndexText:
+		if field.Language != "" {
+			def["locale"] = field.Language
+		}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"fields": []interface{}{def}})
+	if err != nil {
+		return types.Endpoint{}, "", fmt.Errorf("failed to serialize schema update: %w", err)
+	}
+	return types.Endpoint{Method: "PATCH", Path: "/collections/" + url.PathEscape(collection)}, string(data), nil
+}
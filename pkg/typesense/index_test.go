@@ -0,0 +1,33 @@
+package typesense
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderFieldIndex(t *testing.T) {
+	tests := []struct {
+		field types.MetadataField
+		body  string
+	}{
+		{types.MetadataField{Name: "tags", Type: "[]string", Index: types.IndexKeyword},
+			`{"fields":[{"facet":true,"index":true,"name":"tags","type":"string[]"}]}`},
+		{types.MetadataField{Name: "title", Type: "string", Index: types.IndexText, Language: "th"},
+			`{"fields":[{"index":true,"locale":"th","name":"title","type":"string"}]}`},
+		{types.MetadataField{Name: "price", Type: "float", Index: types.IndexFloat},
+			`{"fields":[{"index":true,"name":"price","type":"float"}]}`},
+	}
+	for _, tt := range tests {
+		endpoint, body, err := New().RenderFieldIndex("products", tt.field)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.field.Name, err)
+		}
+		if endpoint.Method != "PATCH" || endpoint.Path != "/collections/products" {
+			t.Errorf("%s: unexpected endpoint: %+v", tt.field.Name, endpoint)
+		}
+		if body != tt.body {
+			t.Errorf("%s: expected %s, got %s", tt.field.Name, tt.body, body)
+		}
+	}
+}
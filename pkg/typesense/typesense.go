@@ -0,0 +1,408 @@
+// Package typesense provides a VECTQL renderer for Typesense.
+package typesense
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/filterexpr"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// fallbackVectorField is the vector field used when neither the query nor
+// the collection names an embedding.
+const fallbackVectorField = "embedding"
+
+// toResult serializes a request body to JSON and returns a QueryResult.
+// Filters and vector queries are expression strings whose values Typesense
+// reads as backtick-quoted strings and [a,b] lists.
+func toResult(body interface{}, params []string) (*types.QueryResult, error) {
+	// Keep "&&", ">", and "<" of filters readable
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(body); err != nil {
+		return nil, fmt.Errorf("failed to serialize query: %w", err)
+	}
+	return &types.QueryResult{
+		JSON:           strings.TrimSuffix(buf.String(), "\n"),
+		RequiredParams: params,
+		Expressions:    types.ExpressionBacktick,
+	}, nil
+}
+
+// Renderer renders VectorAST to Typesense REST bodies. Searches and fetches
+// are multi_search requests with one search; upserts and updates are
+// documents imports, one document per line; deletes are filter_by query
+// strings. Metadata fields are top-level document fields next to the
+// vector field.
+type Renderer struct{}
+
+// New creates a new Typesense renderer.
+func New() *Renderer {
+	return &Renderer{}
+}
+
+// vectorField returns the vector field a query targets.
+func vectorField(ast *types.VectorAST) string {
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
+		return ast.QueryEmbedding.Name
+	}
+	if ast.Target.DefaultEmbedding != "" {
+		return ast.Target.DefaultEmbedding
+	}
+	return fallbackVectorField
+}
+
+// Render converts a VectorAST to a Typesense request body.
+func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	if err := ast.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid AST: %w", err)
+	}
+	// Typesense has no namespaces; tenants are separate collections or
+	// scoped API keys
+	if ast.Namespace != nil {
+		return nil, fmt.Errorf("typesense does not support namespaces")
+	}
+
+	var params []string
+
+	switch ast.Operation {
+	case types.OpSearch:
+		return r.renderSearch(ast, &params)
+	case types.OpUpsert:
+		return r.renderUpsert(ast, &params)
+	case types.OpDelete:
+		return r.renderDelete(ast, &params)
+	case types.OpFetch:
+		return r.renderFetch(ast, &params)
+	case types.OpUpdate:
+		return r.renderUpdate(ast, &params)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
+
+func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("typesense does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("typesense"); err != nil {
+		return nil, err
+	}
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("typesense does not support quantization search parameters")
+	}
+	// vector_query only takes a distance_threshold, whose direction
+	// depends on the field's distance measure
+	if ast.MinScore != nil {
+		return nil, fmt.Errorf("typesense does not support score thresholds")
+	}
+
+	field := vectorField(ast)
+	var vector string
+	if ast.QueryVector.Param != nil {
+		*params = append(*params, ast.QueryVector.Param.Name)
+		vector = fmt.Sprintf(":%s", ast.QueryVector.Param.Name)
+	} else {
+		vector = vectorLiteral(ast.QueryVector.Literal)
+	}
+
+	var k, perPage interface{}
+	if ast.TopK.Static != nil {
+		k, perPage = *ast.TopK.Static, *ast.TopK.Static
+	} else {
+		*params = append(*params, ast.TopK.Param.Name)
+		k, perPage = fmt.Sprintf(":%s", ast.TopK.Param.Name), fmt.Sprintf(":%s", ast.TopK.Param.Name)
+	}
+
+	search := map[string]interface{}{
+		"collection":   ast.Target.Name,
+		"q":            "*",
+		"vector_query": fmt.Sprintf("%s:(%s, k:%v)", field, vector, k),
+		"per_page":     perPage,
+	}
+	if ast.FilterClause != nil {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return nil, err
+		}
+		search["filter_by"] = filter
+	}
+	fields(search, ast, field)
+
+	result, err := toResult(map[string]interface{}{"searches": []interface{}{search}}, *params)
+	if err != nil {
+		return nil, err
+	}
+	// Writes are visible to searches as soon as they are acknowledged
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	// Vector queries are ranked by distance alone
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+// fields sets include_fields or exclude_fields on a search for the
+// metadata and vectors a query returns. Documents always carry their id.
+func fields(search map[string]interface{}, ast *types.VectorAST, vectorField string) {
+	switch {
+	case !ast.IncludeMetadata && !ast.IncludeVectors:
+		search["include_fields"] = "id"
+	case !ast.IncludeMetadata:
+		search["include_fields"] = "id," + vectorField
+	case len(ast.MetadataFields) > 0:
+		names := []string{"id"}
+		for _, f := range ast.MetadataFields {
+			names = append(names, f.Name)
+		}
+		if ast.IncludeVectors {
+			names = append(names, vectorField)
+		}
+		search["include_fields"] = strings.Join(names, ",")
+	case !ast.IncludeVectors:
+		search["exclude_fields"] = vectorField
+	}
+}
+
+// vectorLiteral formats a literal vector for a vector_query.
+func vectorLiteral(v []float32) string {
+	parts := make([]string, len(v))
+	for i, f := range v {
+		parts[i] = strconv.FormatFloat(float64(f), 'g', -1, 32)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	field := vectorField(ast)
+	docs := make([]interface{}, len(ast.Vectors))
+	for i, record := range ast.Vectors {
+		if record.SparseVector != nil {
+			return nil, fmt.Errorf("typesense does not support sparse vectors")
+		}
+
+		*params = append(*params, record.ID.Name)
+		doc := map[string]interface{}{"id": fmt.Sprintf(":%s", record.ID.Name)}
+		if record.Vector.Param != nil {
+			*params = append(*params, record.Vector.Param.Name)
+			doc[field] = fmt.Sprintf(":%s", record.Vector.Param.Name)
+		} else {
+			doc[field] = record.Vector.Literal
+		}
+		for f, value := range record.Metadata {
+			*params = append(*params, value.Name)
+			doc[f.Name] = fmt.Sprintf(":%s", value.Name)
+		}
+		docs[i] = doc
+	}
+	return toResult(docs, *params)
+}
+
+func (r *Renderer) renderUpdate(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	docs := make([]interface{}, len(ast.IDs))
+	for i, id := range ast.IDs {
+		*params = append(*params, id.Name)
+		doc := map[string]interface{}{"id": fmt.Sprintf(":%s", id.Name)}
+		for f, value := range ast.Updates {
+			*params = append(*params, value.Name)
+			doc[f.Name] = fmt.Sprintf(":%s", value.Name)
+		}
+		docs[i] = doc
+	}
+	return toResult(docs, *params)
+}
+
+// renderDelete renders the filter_by a delete sends as its query string.
+// Typesense deletes take no body; the filter is rendered as one so it can
+// be inspected and bound like any other query.
+func (r *Renderer) renderDelete(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	filter, err := r.deleteFilter(ast, params)
+	if err != nil {
+		return nil, err
+	}
+	return toResult(map[string]interface{}{"filter_by": filter}, *params)
+}
+
+// deleteFilter builds the filter_by matching the documents a delete removes.
+func (r *Renderer) deleteFilter(ast *types.VectorAST, params *[]string) (string, error) {
+	var parts []string
+	if len(ast.IDs) > 0 {
+		parts = append(parts, idFilter(ast.IDs, params))
+	}
+	if ast.FilterClause != nil {
+		filter, err := r.renderFilter(ast.FilterClause, params)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, filter)
+	}
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+	return "(" + strings.Join(parts, ") && (") + ")", nil
+}
+
+func (r *Renderer) renderFetch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	field := vectorField(ast)
+	search := map[string]interface{}{
+		"collection": ast.Target.Name,
+		"q":          "*",
+		"filter_by":  idFilter(ast.IDs, params),
+		"per_page":   len(ast.IDs),
+	}
+	fields(search, ast, field)
+
+	result, err := toResult(map[string]interface{}{"searches": []interface{}{search}}, *params)
+	if err != nil {
+		return nil, err
+	}
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	return result, nil
+}
+
+// idFilter builds an "id:[...]" filter over ID placeholders.
+func idFilter(ids []types.Param, params *[]string) string {
+	exprs := make([]string, len(ids))
+	for i, id := range ids {
+		*params = append(*params, id.Name)
+		exprs[i] = fmt.Sprintf(":%s", id.Name)
+	}
+	return fmt.Sprintf("id:[%s]", strings.Join(exprs, ","))
+}
+
+func (r *Renderer) renderFilter(f types.FilterItem, params *[]string) (string, error) {
+	return filterexpr.New(r.dialect(), params).Compile(f)
+}
+
+// dialect describes the Typesense filter_by language. Comparisons are
+// written field:op value without spaces, and NOT groups have no spelling.
+func (r *Renderer) dialect() *filterexpr.Dialect {
+	return &filterexpr.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			if !r.SupportsFilter(op) {
+				return "", false
+			}
+			return r.mapOperator(op), true
+		},
+		Comparison: func(field, op, value string) string {
+			return field + op + value
+		},
+		And:                "&&",
+		Or:                 "||",
+		ParenthesizeGroups: true,
+	}
+}
+
+// mapOperator spells a filter operator. IN-style operators take bound
+// lists, which are written as [a,b]; array fields match when any element
+// does.
+func (r *Renderer) mapOperator(op types.FilterOperator) string {
+	switch op {
+	case types.NE, types.NotIn:
+		return ":!="
+	case types.GT:
+		return ":>"
+	case types.GE:
+		return ":>="
+	case types.LT:
+		return ":<"
+	case types.LE:
+		return ":<="
+	default:
+		return ":="
+	}
+}
+
+// SupportsOperation indicates if Typesense supports an operation.
+func (r *Renderer) SupportsOperation(op types.Operation) bool {
+	switch op {
+	case types.OpSearch, types.OpUpsert, types.OpDelete, types.OpFetch, types.OpUpdate:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFilter indicates if Typesense filter_by expresses a filter
+// operator.
+func (r *Renderer) SupportsFilter(op types.FilterOperator) bool {
+	switch op {
+	case types.EQ, types.NE, types.GT, types.GE, types.LT, types.LE, types.IN, types.NotIn,
+		types.ArrayContains, types.ArrayContainsAny:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsMetric indicates if Typesense supports a distance metric. Vector
+// fields are indexed for cosine or inner product.
+func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
+	switch metric {
+	case types.Cosine, types.DotProduct:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsModality indicates if Typesense accepts a search input modality.
+// Query embedding is configured per field, so queries carry vectors.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	return m == types.ModalityVector
+}
+
+// Capabilities describes the features supported by Typesense.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("typesense", r)
+}
+
+// RenderTo writes the Typesense request body for ast to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// Format reports that Typesense queries are JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// Endpoint returns the Typesense REST call for ast. Deletes carry their
+// filter_by in the query string.
+func (r *Renderer) Endpoint(ast *types.VectorAST) (types.Endpoint, error) {
+	documents := "/collections/" + url.PathEscape(ast.Target.Name) + "/documents"
+	switch ast.Operation {
+	case types.OpSearch, types.OpFetch:
+		return types.Endpoint{Method: "POST", Path: "/multi_search"}, nil
+	case types.OpUpsert:
+		return types.Endpoint{Method: "POST", Path: documents + "/import?action=upsert", Lines: true}, nil
+	case types.OpUpdate:
+		return types.Endpoint{Method: "POST", Path: documents + "/import?action=update", Lines: true}, nil
+	case types.OpDelete:
+		var params []string
+		filter, err := r.deleteFilter(ast, &params)
+		if err != nil {
+			return types.Endpoint{}, err
+		}
+		return types.Endpoint{Method: "DELETE", Path: documents, Query: url.Values{"filter_by": {filter}}.Encode()}, nil
+	default:
+		return types.Endpoint{}, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
@@ -0,0 +1,237 @@
+package typesense
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderSearch(t *testing.T) {
+	topK := 10
+	min := types.Param{Name: "min_price"}
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products", DefaultEmbedding: "vec"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.EQ, Value: types.Param{Name: "color"}},
+			types.FilterGroup{Logic: types.OR, Conditions: []types.FilterItem{
+				types.FilterCondition{Field: types.MetadataField{Name: "tags"}, Operator: types.ArrayContainsAny, Value: types.Param{Name: "tags"}},
+				types.FilterCondition{Field: types.MetadataField{Name: "brand"}, Operator: types.NotIn, Value: types.Param{Name: "banned"}},
+			}},
+			types.RangeFilter{Field: types.MetadataField{Name: "price"}, Min: &min},
+		}},
+		IncludeMetadata: true,
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"searches":[{"collection":"products","exclude_fields":"vec",` +
+		`"filter_by":"(color:=:color && (tags:=:tags || brand:!=:banned) && (price:>=:min_price))",` +
+		`"per_page":10,"q":"*","vector_query":"vec:(:query_vec, k:10)"}]}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if result.Expressions != types.ExpressionBacktick {
+		t.Errorf("expected backtick expressions, got %q", result.Expressions)
+	}
+	if len(result.RequiredParams) != 5 {
+		t.Errorf("expected 5 params, got %v", result.RequiredParams)
+	}
+}
+
+func TestRenderSearchLiteralAndParamTopK(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation:       types.OpSearch,
+		Target:          types.Collection{Name: "products"},
+		QueryVector:     &types.VectorValue{Literal: []float32{0.5, -1}},
+		TopK:            &types.PaginationValue{Param: &types.Param{Name: "k"}},
+		MetadataFields:  []types.MetadataField{{Name: "title"}},
+		IncludeMetadata: true,
+		IncludeVectors:  true,
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"searches":[{"collection":"products","include_fields":"id,title,embedding",` +
+		`"per_page":":k","q":"*","vector_query":"embedding:([0.5,-1], k::k)"}]}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+}
+
+func TestRenderSearchUnsupported(t *testing.T) {
+	topK := 10
+	vector := &types.VectorValue{Param: &types.Param{Name: "query_vec"}}
+
+	tests := []struct {
+		name   string
+		ast    *types.VectorAST
+		errMsg string
+	}{
+		{
+			// filter_by has no negation of a group
+			name: "not group",
+			ast: &types.VectorAST{
+				Operation:   types.OpSearch,
+				Target:      types.Collection{Name: "products"},
+				QueryVector: vector,
+				TopK:        &types.PaginationValue{Static: &topK},
+				FilterClause: types.FilterGroup{Logic: types.NOT, Conditions: []types.FilterItem{
+					types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.EQ, Value: types.Param{Name: "color"}},
+				}},
+			},
+			errMsg: "NOT",
+		},
+		{
+			// String fields match whole tokens, not substrings
+			name: "substring",
+			ast: &types.VectorAST{
+				Operation:    types.OpSearch,
+				Target:       types.Collection{Name: "products"},
+				QueryVector:  vector,
+				TopK:         &types.PaginationValue{Static: &topK},
+				FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "title"}, Operator: types.Contains, Value: types.Param{Name: "title"}},
+			},
+			errMsg: "unsupported filter operator",
+		},
+		{
+			// distance_threshold runs in the direction of the field's
+			// distance measure, which the query does not know
+			name: "score threshold",
+			ast: &types.VectorAST{
+				Operation:   types.OpSearch,
+				Target:      types.Collection{Name: "products"},
+				QueryVector: vector,
+				TopK:        &types.PaginationValue{Static: &topK},
+				MinScore:    &types.Param{Name: "min_score"},
+			},
+			errMsg: "score thresholds",
+		},
+	}
+	for _, tt := range tests {
+		_, err := New().Render(tt.ast)
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.errMsg, err)
+		}
+	}
+}
+
+func TestRenderUpsert(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation: types.OpUpsert,
+		Target:    types.Collection{Name: "products"},
+		Vectors: []types.VectorRecord{{
+			ID:       types.Param{Name: "id"},
+			Vector:   types.VectorValue{Param: &types.Param{Name: "vec"}},
+			Metadata: map[types.MetadataField]types.Param{{Name: "color"}: {Name: "color"}},
+		}},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `[{"color":":color","embedding":":vec","id":":id"}]`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	ast.Vectors[0].SparseVector = &types.SparseVectorValue{Indices: []int{1}, Values: []float32{1}}
+	if _, err := New().Render(ast); err == nil {
+		t.Error("expected error for a sparse vector")
+	}
+}
+
+func TestRenderUpdate(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation: types.OpUpdate,
+		Target:    types.Collection{Name: "products"},
+		IDs:       []types.Param{{Name: "id1"}, {Name: "id2"}},
+		Updates:   map[types.MetadataField]types.Param{{Name: "color"}: {Name: "color"}},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `[{"color":":color","id":":id1"},{"color":":color","id":":id2"}]`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+}
+
+func TestRenderDeleteAndFetch(t *testing.T) {
+	ids := []types.Param{{Name: "id1"}, {Name: "id2"}}
+
+	result, err := New().Render(&types.VectorAST{Operation: types.OpDelete, Target: types.Collection{Name: "products"}, IDs: ids})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"filter_by":"id:[:id1,:id2]"}`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	filtered := &types.VectorAST{
+		Operation:    types.OpDelete,
+		Target:       types.Collection{Name: "products"},
+		FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "a"}, Operator: types.EQ, Value: types.Param{Name: "a"}},
+		DeleteAll:    true,
+	}
+	result, err = New().Render(filtered)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"filter_by":"a:=:a"}`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	result, err = New().Render(&types.VectorAST{Operation: types.OpFetch, Target: types.Collection{Name: "products"}, IDs: ids, IncludeMetadata: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"searches":[{"collection":"products","exclude_fields":"embedding","filter_by":"id:[:id1,:id2]","per_page":2,"q":"*"}]}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	documents := "/collections/products/documents"
+	tests := []struct {
+		op    types.Operation
+		path  string
+		lines bool
+	}{
+		{types.OpSearch, "/multi_search", false},
+		{types.OpFetch, "/multi_search", false},
+		{types.OpUpsert, documents + "/import?action=upsert", true},
+		{types.OpUpdate, documents + "/import?action=update", true},
+	}
+	for _, tt := range tests {
+		endpoint, err := New().Endpoint(&types.VectorAST{Operation: tt.op, Target: types.Collection{Name: "products"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if endpoint.Method != "POST" || endpoint.Path != tt.path || endpoint.Lines != tt.lines {
+			t.Errorf("%s: unexpected endpoint %+v", tt.op, endpoint)
+		}
+	}
+
+	endpoint, err := New().Endpoint(&types.VectorAST{
+		Operation: types.OpDelete,
+		Target:    types.Collection{Name: "products"},
+		IDs:       []types.Param{{Name: "id"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Method != "DELETE" || endpoint.Path != documents || endpoint.Query != "filter_by=id%3A%5B%3Aid%5D" {
+		t.Errorf("unexpected delete endpoint %+v", endpoint)
+	}
+}
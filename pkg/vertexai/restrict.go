@@ -0,0 +1,198 @@
+package vertexai
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// restricts collects the token and numeric restricts of a datapoint. Vertex
+// AI ANDs restricts across namespaces and ORs the allow list within one, so
+// a filter must be an AND of conditions with at most one allow list and one
+// deny list per field.
+type restricts struct {
+	tokens  []map[string]interface{}
+	byField map[string]map[string]interface{}
+	numeric []interface{}
+	params  *[]string
+}
+
+func newRestricts(params *[]string) *restricts {
+	return &restricts{byField: make(map[string]map[string]interface{}), params: params}
+}
+
+// apply sets the collected restricts on datapoint.
+func (rs *restricts) apply(datapoint map[string]interface{}) {
+	if len(rs.tokens) > 0 {
+		datapoint["restricts"] = rs.tokens
+	}
+	if len(rs.numeric) > 0 {
+		datapoint["numericRestricts"] = rs.numeric
+	}
+}
+
+// add adds the restricts a filter requires.
+func (rs *restricts) add(f types.FilterItem) error {
+	switch filter := f.(type) {
+	case types.FilterCondition:
+		return rs.condition(filter)
+
+	case types.FilterGroup:
+		if filter.Logic != types.AND {
+			return fmt.Errorf("vertex ai restricts only combine conditions with AND, got %s", filter.Logic)
+		}
+		for _, cond := range filter.Conditions {
+			if err := rs.add(cond); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case types.RangeFilter:
+		if filter.Min != nil {
+			op := "GREATER_EQUAL"
+			if filter.MinExclusive {
+				op = "GREATER"
+			}
+			rs.addNumeric(filter.Field, op, *filter.Min)
+		}
+		if filter.Max != nil {
+			op := "LESS_EQUAL"
+			if filter.MaxExclusive {
+				op = "LESS"
+			}
+			rs.addNumeric(filter.Field, op, *filter.Max)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported filter type: %T", f)
+	}
+}
+
+func (rs *restricts) condition(c types.FilterCondition) error {
+	if !supportsFilter(c.Operator) {
+		return fmt.Errorf("unsupported filter operator: %s", c.Operator)
+	}
+	if c.Field.Type == "bool" {
+		return fmt.Errorf("vertex ai restricts cannot filter bool field '%s'", c.Field.Name)
+	}
+
+	switch c.Operator {
+	case types.GT, types.GE, types.LT, types.LE:
+		rs.addNumeric(c.Field, numericOps[c.Operator], c.Value)
+		return nil
+	case types.EQ, types.NE:
+		if numeric(c.Field.Type) {
+			rs.addNumeric(c.Field, numericOps[c.Operator], c.Value)
+			return nil
+		}
+	case types.IN, types.NotIn:
+		if numeric(c.Field.Type) {
+			return fmt.Errorf("vertex ai numeric restricts do not support %s on field '%s'", c.Operator, c.Field.Name)
+		}
+	}
+
+	// Single values become one-token lists; IN-style values are bound lists
+	value := rs.param(c.Value)
+	switch c.Operator {
+	case types.EQ, types.ArrayContains:
+		return rs.addToken(c.Field.Name, "allowList", []interface{}{value})
+	case types.IN, types.ArrayContainsAny:
+		return rs.addToken(c.Field.Name, "allowList", value)
+	case types.NE:
+		return rs.addToken(c.Field.Name, "denyList", []interface{}{value})
+	default:
+		return rs.addToken(c.Field.Name, "denyList", value)
+	}
+}
+
+// value adds the restrict that stores a metadata value on a datapoint.
+func (rs *restricts) value(field types.MetadataField, value types.Param) error {
+	switch {
+	case field.Type == "bool":
+		return fmt.Errorf("vertex ai restricts cannot hold bool field '%s'", field.Name)
+	case numeric(field.Type):
+		rs.addNumeric(field, "", value)
+		return nil
+	case list(field.Type):
+		return rs.addToken(field.Name, "allowList", rs.param(value))
+	default:
+		return rs.addToken(field.Name, "allowList", []interface{}{rs.param(value)})
+	}
+}
+
+func (rs *restricts) addToken(field, kind string, value interface{}) error {
+	restrict, ok := rs.byField[field]
+	if !ok {
+		restrict = map[string]interface{}{"namespace": field}
+		rs.byField[field] = restrict
+		rs.tokens = append(rs.tokens, restrict)
+	}
+	if _, taken := restrict[kind]; taken {
+		return fmt.Errorf("vertex ai cannot combine two %s restricts on field '%s'", kind, field)
+	}
+	restrict[kind] = value
+	return nil
+}
+
+// addNumeric adds a numeric restrict. An empty op stores a value rather
+// than comparing with it.
+func (rs *restricts) addNumeric(field types.MetadataField, op string, value types.Param) {
+	restrict := map[string]interface{}{
+		"namespace":            field.Name,
+		numericKey(field.Type): rs.param(value),
+	}
+	if op != "" {
+		restrict["op"] = op
+	}
+	rs.numeric = append(rs.numeric, restrict)
+}
+
+func (rs *restricts) param(p types.Param) string {
+	*rs.params = append(*rs.params, p.Name)
+	return fmt.Sprintf(":%s", p.Name)
+}
+
+var numericOps = map[types.FilterOperator]string{
+	types.EQ: "EQUAL",
+	types.NE: "NOT_EQUAL",
+	types.GT: "GREATER",
+	types.GE: "GREATER_EQUAL",
+	types.LT: "LESS",
+	types.LE: "LESS_EQUAL",
+}
+
+// numeric reports whether a VDML metadata type is stored as a numeric
+// restrict.
+func numeric(fieldType string) bool {
+	return fieldType == "int" || fieldType == "float"
+}
+
+// list reports whether a VDML metadata type is a list, whose elements are
+// the tokens of its restrict.
+func list(fieldType string) bool {
+	return strings.HasPrefix(fieldType, "[]")
+}
+
+// numericKey returns the value field of a numeric restrict. Fields of
+// unknown type compare as doubles.
+func numericKey(fieldType string) string {
+	if fieldType == "int" {
+		return "valueInt"
+	}
+	return "valueDouble"
+}
+
+// sortedFields returns the fields of a record's metadata in name order, so
+// restricts render deterministically.
+func sortedFields(metadata map[types.MetadataField]types.Param) []types.MetadataField {
+	fields := make([]types.MetadataField, 0, len(metadata))
+	for field := range metadata {
+		fields = append(fields, field)
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields
+}
@@ -0,0 +1,309 @@
+// Package vertexai provides a VECTQL renderer for Google Vertex AI Vector
+// Search.
+package vertexai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// toResult serializes a request body to JSON and returns a QueryResult.
+func toResult(body map[string]interface{}, params []string) (*types.QueryResult, error) {
+	jsonBytes, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize query: %w", err)
+	}
+	return &types.QueryResult{
+		JSON:           string(jsonBytes),
+		RequiredParams: params,
+	}, nil
+}
+
+// Renderer renders VectorAST to Vertex AI Vector Search REST bodies.
+// Collections are indexes: writes go to the index, and searches and fetches
+// to the index endpoint it is deployed on. Metadata fields are datapoint
+// restricts: string and list fields are token restricts whose namespace is
+// the field name, and int and float fields are numeric restricts.
+type Renderer struct {
+	// Project and Location locate the index and the index endpoint, e.g.
+	// "my-project" and "us-central1".
+	Project  string
+	Location string
+
+	// IndexEndpoint is the ID of the index endpoint searches and fetches
+	// are sent to.
+	IndexEndpoint string
+
+	// DeployedIndexID is the ID the index is deployed under on
+	// IndexEndpoint. Empty uses the collection name.
+	DeployedIndexID string
+}
+
+// New creates a new Vertex AI Vector Search renderer.
+func New() *Renderer {
+	return &Renderer{}
+}
+
+// Render converts a VectorAST to a Vertex AI Vector Search request body.
+func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	if err := ast.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid AST: %w", err)
+	}
+	// Vertex AI has no namespaces; tenants are separated with restricts
+	if ast.Namespace != nil {
+		return nil, fmt.Errorf("vertex ai does not support namespaces")
+	}
+
+	var params []string
+
+	switch ast.Operation {
+	case types.OpSearch:
+		return r.renderSearch(ast, &params)
+	case types.OpUpsert:
+		return r.renderUpsert(ast, &params)
+	case types.OpDelete:
+		return r.renderDelete(ast, &params)
+	case types.OpFetch:
+		return r.renderFetch(ast, &params)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
+
+// deployedIndex returns the deployed index ID a query reads from.
+func (r *Renderer) deployedIndex(ast *types.VectorAST) string {
+	if r.DeployedIndexID != "" {
+		return r.DeployedIndexID
+	}
+	return ast.Target.Name
+}
+
+func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if m := ast.Modality(); !r.SupportsModality(m) {
+		return nil, fmt.Errorf("vertex ai does not support %s search input", m)
+	}
+	if err := ast.ValidateScoring("vertexai"); err != nil {
+		return nil, err
+	}
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("vertex ai does not support quantization search parameters")
+	}
+	// Neighbors are returned with distances, whose direction depends on the
+	// index's distance measure
+	if ast.MinScore != nil {
+		return nil, fmt.Errorf("vertex ai does not support score thresholds")
+	}
+
+	datapoint := make(map[string]interface{})
+	if ast.QueryVector.Param != nil {
+		*params = append(*params, ast.QueryVector.Param.Name)
+		datapoint["featureVector"] = fmt.Sprintf(":%s", ast.QueryVector.Param.Name)
+	} else {
+		datapoint["featureVector"] = ast.QueryVector.Literal
+	}
+
+	if ast.FilterClause != nil {
+		rs := newRestricts(params)
+		if err := rs.add(ast.FilterClause); err != nil {
+			return nil, err
+		}
+		rs.apply(datapoint)
+	}
+
+	query := map[string]interface{}{"datapoint": datapoint}
+	if ast.TopK.Static != nil {
+		query["neighborCount"] = *ast.TopK.Static
+	} else {
+		*params = append(*params, ast.TopK.Param.Name)
+		query["neighborCount"] = fmt.Sprintf(":%s", ast.TopK.Param.Name)
+	}
+
+	body := map[string]interface{}{
+		"deployedIndexId":     r.deployedIndex(ast),
+		"queries":             []interface{}{query},
+		"returnFullDatapoint": ast.IncludeMetadata || ast.IncludeVectors,
+	}
+
+	result, err := toResult(body, *params)
+	if err != nil {
+		return nil, err
+	}
+	// Deployed indexes serve from replicas updated by streaming writes
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	// Neighbors are ranked by distance alone
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	datapoints := make([]interface{}, len(ast.Vectors))
+	for i, record := range ast.Vectors {
+		*params = append(*params, record.ID.Name)
+		datapoint := map[string]interface{}{"datapointId": fmt.Sprintf(":%s", record.ID.Name)}
+
+		if record.Vector.Param != nil {
+			*params = append(*params, record.Vector.Param.Name)
+			datapoint["featureVector"] = fmt.Sprintf(":%s", record.Vector.Param.Name)
+		} else {
+			datapoint["featureVector"] = record.Vector.Literal
+		}
+
+		if record.SparseVector != nil {
+			if record.SparseVector.Param != nil {
+				*params = append(*params, record.SparseVector.Param.Name)
+				datapoint["sparseEmbedding"] = fmt.Sprintf(":%s", record.SparseVector.Param.Name)
+			} else {
+				datapoint["sparseEmbedding"] = map[string]interface{}{
+					"values":     record.SparseVector.Values,
+					"dimensions": record.SparseVector.Indices,
+				}
+			}
+		}
+
+		if len(record.Metadata) > 0 {
+			rs := newRestricts(params)
+			for _, field := range sortedFields(record.Metadata) {
+				if err := rs.value(field, record.Metadata[field]); err != nil {
+					return nil, err
+				}
+			}
+			rs.apply(datapoint)
+		}
+
+		datapoints[i] = datapoint
+	}
+	return toResult(map[string]interface{}{"datapoints": datapoints}, *params)
+}
+
+func (r *Renderer) renderDelete(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if len(ast.IDs) == 0 {
+		return nil, fmt.Errorf("vertex ai only deletes datapoints by ID")
+	}
+	return toResult(map[string]interface{}{"datapointIds": r.ids(ast, params)}, *params)
+}
+
+func (r *Renderer) renderFetch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	body := map[string]interface{}{
+		"deployedIndexId": r.deployedIndex(ast),
+		"ids":             r.ids(ast, params),
+	}
+	result, err := toResult(body, *params)
+	if err != nil {
+		return nil, err
+	}
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	return result, nil
+}
+
+func (r *Renderer) ids(ast *types.VectorAST, params *[]string) []string {
+	ids := make([]string, len(ast.IDs))
+	for i, id := range ast.IDs {
+		*params = append(*params, id.Name)
+		ids[i] = fmt.Sprintf(":%s", id.Name)
+	}
+	return ids
+}
+
+// SupportsOperation indicates if Vertex AI supports an operation. Datapoints
+// are replaced rather than updated, so UPDATE is not supported.
+func (r *Renderer) SupportsOperation(op types.Operation) bool {
+	switch op {
+	case types.OpSearch, types.OpUpsert, types.OpDelete, types.OpFetch:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsFilter indicates if Vertex AI restricts express a filter operator.
+func (r *Renderer) SupportsFilter(op types.FilterOperator) bool {
+	return supportsFilter(op)
+}
+
+func supportsFilter(op types.FilterOperator) bool {
+	switch op {
+	case types.EQ, types.NE, types.GT, types.GE, types.LT, types.LE, types.IN, types.NotIn,
+		types.ArrayContains, types.ArrayContainsAny:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsMetric indicates if Vertex AI supports a distance metric.
+// Euclidean indexes use SQUARED_L2_DISTANCE.
+func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
+	switch metric {
+	case types.Cosine, types.Euclidean, types.DotProduct, types.Manhattan:
+		return true
+	default:
+		return false
+	}
+}
+
+// SupportsModality indicates if Vertex AI accepts a search input modality.
+// Vertex AI Vector Search only searches with vectors.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	return m == types.ModalityVector
+}
+
+// Capabilities describes the features supported by Vertex AI.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("vertexai", r)
+}
+
+// RenderTo writes the Vertex AI request body for ast to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// Format reports that Vertex AI queries are JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// Endpoint returns the Vertex AI REST call for ast, relative to the regional
+// API host, e.g. https://us-central1-aiplatform.googleapis.com. Searches on
+// public endpoints are sent to the endpoint's own domain instead, with the
+// same path.
+func (r *Renderer) Endpoint(ast *types.VectorAST) (types.Endpoint, error) {
+	if r.Project == "" || r.Location == "" {
+		return types.Endpoint{}, fmt.Errorf("vertex ai endpoints require a project and location")
+	}
+	parent := "/v1/projects/" + url.PathEscape(r.Project) + "/locations/" + url.PathEscape(r.Location)
+	index := parent + "/indexes/" + url.PathEscape(ast.Target.Name)
+	switch ast.Operation {
+	case types.OpSearch, types.OpFetch:
+		if r.IndexEndpoint == "" {
+			return types.Endpoint{}, fmt.Errorf("vertex ai reads require an index endpoint")
+		}
+		method := ":findNeighbors"
+		if ast.Operation == types.OpFetch {
+			method = ":readIndexDatapoints"
+		}
+		return types.Endpoint{Method: "POST", Path: parent + "/indexEndpoints/" + url.PathEscape(r.IndexEndpoint) + method}, nil
+	case types.OpUpsert:
+		return types.Endpoint{Method: "POST", Path: index + ":upsertDatapoints"}, nil
+	case types.OpDelete:
+		return types.Endpoint{Method: "POST", Path: index + ":removeDatapoints"}, nil
+	default:
+		return types.Endpoint{}, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
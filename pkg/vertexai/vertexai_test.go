@@ -0,0 +1,230 @@
+package vertexai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderSearch(t *testing.T) {
+	topK := 10
+	min := types.Param{Name: "min_price"}
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.EQ, Value: types.Param{Name: "color"}},
+			types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.NotIn, Value: types.Param{Name: "banned"}},
+			types.FilterCondition{Field: types.MetadataField{Name: "tags", Type: "[]string"}, Operator: types.ArrayContainsAny, Value: types.Param{Name: "tags"}},
+			types.FilterCondition{Field: types.MetadataField{Name: "stock", Type: "int"}, Operator: types.NE, Value: types.Param{Name: "stock"}},
+			types.RangeFilter{Field: types.MetadataField{Name: "price", Type: "float"}, Min: &min},
+		}},
+		IncludeMetadata: true,
+	}
+
+	result, err := (&Renderer{DeployedIndexID: "products_v2"}).Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"deployedIndexId":"products_v2","queries":[{"datapoint":{"featureVector":":query_vec",` +
+		`"numericRestricts":[{"namespace":"stock","op":"NOT_EQUAL","valueInt":":stock"},{"namespace":"price","op":"GREATER_EQUAL","valueDouble":":min_price"}],` +
+		`"restricts":[{"allowList":[":color"],"denyList":":banned","namespace":"color"},{"allowList":":tags","namespace":"tags"}]},` +
+		`"neighborCount":10}],"returnFullDatapoint":true}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if len(result.RequiredParams) != 6 {
+		t.Errorf("expected 6 params, got %v", result.RequiredParams)
+	}
+}
+
+func TestRenderSearchRestricts(t *testing.T) {
+	// Restricts are a flat list of token and numeric conditions: one
+	// allowList and one denyList per namespace, all ANDed together
+	tests := []struct {
+		name   string
+		filter types.FilterItem
+		errMsg string
+	}{
+		{"or", types.FilterGroup{Logic: types.OR, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.EQ, Value: types.Param{Name: "color"}},
+		}}, "only combine conditions with AND"},
+		{"two allow lists", types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.EQ, Value: types.Param{Name: "color"}},
+			types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.IN, Value: types.Param{Name: "colors"}},
+		}}, "two allowList restricts"},
+		{"two deny lists", types.FilterGroup{Logic: types.AND, Conditions: []types.FilterItem{
+			types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.NE, Value: types.Param{Name: "color"}},
+			types.FilterCondition{Field: types.MetadataField{Name: "color"}, Operator: types.NotIn, Value: types.Param{Name: "banned"}},
+		}}, "two denyList restricts"},
+		{"numeric in", types.FilterCondition{Field: types.MetadataField{Name: "stock", Type: "int"}, Operator: types.IN, Value: types.Param{Name: "stock"}}, "numeric restricts"},
+		{"bool", types.FilterCondition{Field: types.MetadataField{Name: "active", Type: "bool"}, Operator: types.EQ, Value: types.Param{Name: "active"}}, "bool field"},
+		{"substring", types.FilterCondition{Field: types.MetadataField{Name: "title"}, Operator: types.Contains, Value: types.Param{Name: "title"}}, "unsupported filter operator"},
+	}
+	for _, tt := range tests {
+		topK := 10
+		ast := &types.VectorAST{
+			Operation:    types.OpSearch,
+			Target:       types.Collection{Name: "products"},
+			QueryVector:  &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+			TopK:         &types.PaginationValue{Static: &topK},
+			FilterClause: tt.filter,
+		}
+		_, err := New().Render(ast)
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.errMsg, err)
+		}
+	}
+}
+
+func TestRenderSearchUnsupported(t *testing.T) {
+	topK := 10
+	vector := &types.VectorValue{Param: &types.Param{Name: "query_vec"}}
+
+	tests := []struct {
+		name   string
+		ast    *types.VectorAST
+		errMsg string
+	}{
+		{
+			// Quantization is fixed when the index is built; findNeighbors
+			// has no per-query rescoring knobs
+			name: "quantization",
+			ast: &types.VectorAST{
+				Operation:    types.OpSearch,
+				Target:       types.Collection{Name: "products"},
+				QueryVector:  vector,
+				TopK:         &types.PaginationValue{Static: &topK},
+				Quantization: &types.QuantizationParams{Rescore: true},
+			},
+			errMsg: "quantization",
+		},
+		{
+			// findNeighbors returns neighborCount datapoints with no
+			// distance cutoff
+			name: "score threshold",
+			ast: &types.VectorAST{
+				Operation:   types.OpSearch,
+				Target:      types.Collection{Name: "products"},
+				QueryVector: vector,
+				TopK:        &types.PaginationValue{Static: &topK},
+				MinScore:    &types.Param{Name: "min_score"},
+			},
+			errMsg: "score thresholds",
+		},
+	}
+	for _, tt := range tests {
+		_, err := New().Render(tt.ast)
+		if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.errMsg, err)
+		}
+	}
+}
+
+func TestRenderUpsert(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation: types.OpUpsert,
+		Target:    types.Collection{Name: "products"},
+		Vectors: []types.VectorRecord{{
+			ID:     types.Param{Name: "id"},
+			Vector: types.VectorValue{Param: &types.Param{Name: "vec"}},
+			Metadata: map[types.MetadataField]types.Param{
+				{Name: "color"}:                  {Name: "color"},
+				{Name: "tags", Type: "[]string"}: {Name: "tags"},
+				{Name: "year", Type: "int"}:      {Name: "year"},
+			},
+			SparseVector: &types.SparseVectorValue{Indices: []int{1, 7}, Values: []float32{0.5, 0.25}},
+		}},
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"datapoints":[{"datapointId":":id","featureVector":":vec",` +
+		`"numericRestricts":[{"namespace":"year","valueInt":":year"}],` +
+		`"restricts":[{"allowList":[":color"],"namespace":"color"},{"allowList":":tags","namespace":"tags"}],` +
+		`"sparseEmbedding":{"dimensions":[1,7],"values":[0.5,0.25]}}]}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+	if len(result.RequiredParams) != 5 {
+		t.Errorf("expected 5 params, got %v", result.RequiredParams)
+	}
+
+	// Restricts hold tokens and numbers only
+	ast.Vectors[0].Metadata = map[types.MetadataField]types.Param{{Name: "active", Type: "bool"}: {Name: "active"}}
+	if _, err := New().Render(ast); err == nil || !strings.Contains(err.Error(), "bool field") {
+		t.Errorf("expected a bool field error, got %v", err)
+	}
+}
+
+func TestRenderDeleteAndFetch(t *testing.T) {
+	ids := []types.Param{{Name: "id1"}, {Name: "id2"}}
+
+	result, err := New().Render(&types.VectorAST{Operation: types.OpDelete, Target: types.Collection{Name: "products"}, IDs: ids})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"datapointIds":[":id1",":id2"]}`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	result, err = New().Render(&types.VectorAST{Operation: types.OpFetch, Target: types.Collection{Name: "products"}, IDs: ids})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"deployedIndexId":"products","ids":[":id1",":id2"]}`; result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	filtered := &types.VectorAST{
+		Operation:    types.OpDelete,
+		Target:       types.Collection{Name: "products"},
+		FilterClause: types.FilterCondition{Field: types.MetadataField{Name: "a"}, Operator: types.EQ, Value: types.Param{Name: "a"}},
+		DeleteAll:    true,
+	}
+	if _, err := New().Render(filtered); err == nil {
+		t.Error("expected error for delete by filter")
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	r := &Renderer{Project: "acme", Location: "us-central1", IndexEndpoint: "123"}
+	parent := "/v1/projects/acme/locations/us-central1"
+
+	tests := []struct {
+		op   types.Operation
+		path string
+	}{
+		{types.OpSearch, parent + "/indexEndpoints/123:findNeighbors"},
+		{types.OpFetch, parent + "/indexEndpoints/123:readIndexDatapoints"},
+		{types.OpUpsert, parent + "/indexes/products:upsertDatapoints"},
+		{types.OpDelete, parent + "/indexes/products:removeDatapoints"},
+	}
+	for _, tt := range tests {
+		endpoint, err := r.Endpoint(&types.VectorAST{Operation: tt.op, Target: types.Collection{Name: "products"}})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if endpoint.Method != "POST" || endpoint.Path != tt.path {
+			t.Errorf("%s: unexpected endpoint %+v", tt.op, endpoint)
+		}
+	}
+
+	search := &types.VectorAST{Operation: types.OpSearch, Target: types.Collection{Name: "products"}}
+	if _, err := New().Endpoint(search); err == nil {
+		t.Error("expected error without a project")
+	}
+	if _, err := (&Renderer{Project: "acme", Location: "us-central1"}).Endpoint(search); err == nil {
+		t.Error("expected error for a read without an index endpoint")
+	}
+	if New().SupportsOperation(types.OpUpdate) {
+		t.Error("expected UPDATE to be unsupported")
+	}
+}
@@ -0,0 +1,414 @@
+package weaviate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/filtertree"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Mode selects the request format the renderer emits.
+type Mode int
+
+const (
+	// ModeJSON emits searches and fetches as JSON descriptions of the
+	// GraphQL Get arguments. This is the default.
+	ModeJSON Mode = iota
+
+	// ModeGraphQL emits searches and fetches as GraphQL requests of the
+	// form {"query": ..., "variables": ...}. Every parameter becomes a typed
+	// GraphQL variable ($name) whose value Bind fills in, so the bound body
+	// can be posted to /v1/graphql as is. Writes keep their REST bodies.
+	ModeGraphQL
+)
+
+// NewGraphQL creates a Weaviate renderer in GraphQL variables mode.
+func NewGraphQL() *Renderer {
+	return &Renderer{Mode: ModeGraphQL}
+}
+
+// gqlVar is a reference to a GraphQL variable, written as $name.
+type gqlVar string
+
+// gqlEnum is a GraphQL enum value, written unquoted.
+type gqlEnum string
+
+// gqlArg is one key/value pair of a GraphQL input object.
+type gqlArg struct {
+	key   string
+	value interface{}
+}
+
+// gqlObject is a GraphQL input object whose arguments keep their order.
+type gqlObject []gqlArg
+
+// gqlList is a GraphQL list value.
+type gqlList []interface{}
+
+// gqlQuery collects the variables declared while rendering a GraphQL query.
+type gqlQuery struct {
+	names []string
+	types map[string]string
+	err   error
+}
+
+// variable declares name with the given GraphQL type and returns a reference
+// to it. A parameter used with two different types is an error.
+func (q *gqlQuery) variable(name, typ string) gqlVar {
+	if q.types == nil {
+		q.types = make(map[string]string)
+	}
+	if prev, ok := q.types[name]; ok {
+		if prev != typ && q.err == nil {
+			q.err = fmt.Errorf("parameter '%s' used as both %s and %s", name, prev, typ)
+		}
+		return gqlVar(name)
+	}
+	q.names = append(q.names, name)
+	q.types[name] = typ
+	return gqlVar(name)
+}
+
+// declarations renders the operation's variable definitions.
+func (q *gqlQuery) declarations() string {
+	if len(q.names) == 0 {
+		return ""
+	}
+	decls := make([]string, len(q.names))
+	for i, name := range q.names {
+		decls[i] = fmt.Sprintf("$%s: %s", name, q.types[name])
+	}
+	return "(" + strings.Join(decls, ", ") + ")"
+}
+
+// result assembles the GraphQL request body. Each variable's value is its
+// ":name" placeholder so Bind can substitute it.
+func (q *gqlQuery) result(className string, args gqlObject, selection []string) (*types.QueryResult, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+
+	var b strings.Builder
+	b.WriteString("query")
+	b.WriteString(q.declarations())
+	b.WriteString(" { Get { ")
+	b.WriteString(className)
+	if len(args) > 0 {
+		b.WriteString("(")
+		writeArgs(&b, args)
+		b.WriteString(")")
+	}
+	b.WriteString(" { ")
+	b.WriteString(strings.Join(selection, " "))
+	b.WriteString(" } } }")
+
+	variables := make(map[string]interface{}, len(q.names))
+	for _, name := range q.names {
+		variables[name] = fmt.Sprintf(":%s", name)
+	}
+
+	params := make([]string, len(q.names))
+	copy(params, q.names)
+
+	return toResult(map[string]interface{}{
+		"query":     b.String(),
+		"variables": variables,
+	}, params)
+}
+
+func (r *Renderer) renderSearchGraphQL(ast *types.VectorAST) (*types.QueryResult, error) {
+	if err := ast.ValidateScoring("weaviate"); err != nil {
+		return nil, err
+	}
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("weaviate does not support quantization search parameters")
+	}
+
+	q := &gqlQuery{}
+	var args gqlObject
+
+	var near gqlObject
+	nearKey := "nearVector"
+	if ast.TextQuery != nil {
+		nearKey = "bm25"
+		if ast.QueryVector != nil {
+			nearKey = "hybrid"
+		}
+		near = append(near, gqlArg{"query", q.variable(ast.TextQuery.Query.Name, "String")})
+		if len(ast.TextQuery.Properties) > 0 {
+			near = append(near, gqlArg{"properties", textProperties(ast.TextQuery)})
+		}
+	}
+	switch {
+	case ast.NearText != nil:
+		nearKey = "nearText"
+		near = append(near, gqlArg{"concepts", q.variable(ast.NearText.Concepts.Name, "[String]")})
+	case ast.NearImage != nil:
+		nearKey = "nearImage"
+		near = append(near, gqlArg{"image", q.variable(ast.NearImage.Image.Name, "String")})
+	case ast.QueryVector != nil:
+		if ast.QueryVector.Param != nil {
+			near = append(near, gqlArg{"vector", q.variable(ast.QueryVector.Param.Name, "[Float]")})
+		} else {
+			near = append(near, gqlArg{"vector", ast.QueryVector.Literal})
+		}
+	}
+	if ast.MinScore != nil {
+		if ast.TextQuery != nil {
+			return nil, fmt.Errorf("weaviate does not support score thresholds for %s search", ast.Modality())
+		}
+		near = append(near, gqlArg{"certainty", q.variable(ast.MinScore.Name, "Float")})
+	}
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" && nearKey != "bm25" {
+		near = append(near, gqlArg{"targetVectors", []string{ast.QueryEmbedding.Name}})
+	}
+	args = append(args, gqlArg{nearKey, near})
+
+	if ast.TopK != nil {
+		if ast.TopK.Static != nil {
+			args = append(args, gqlArg{"limit", *ast.TopK.Static})
+		} else if ast.TopK.Param != nil {
+			args = append(args, gqlArg{"limit", q.variable(ast.TopK.Param.Name, "Int")})
+		}
+	}
+
+	if ast.FilterClause != nil {
+		where, err := r.renderFilterGraphQL(ast.FilterClause, q)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, gqlArg{"where", where})
+	}
+
+	if ast.Namespace != nil {
+		args = append(args, gqlArg{"tenant", q.variable(ast.Namespace.Name, "String")})
+	}
+
+	additional := []string{"id", "distance", "certainty"}
+	if ast.TextQuery != nil {
+		additional = []string{"id", "score"}
+	}
+	if ast.IncludeVectors {
+		additional = append(additional, "vector")
+	}
+
+	return q.result(r.formatClassName(ast.Target.Name), args, r.selection(ast, additional))
+}
+
+func (r *Renderer) renderFetchGraphQL(ast *types.VectorAST) (*types.QueryResult, error) {
+	if ast.Lists() {
+		return r.renderListGraphQL(ast)
+	}
+	q := &gqlQuery{}
+
+	ids := make(gqlList, len(ast.IDs))
+	for i, id := range ast.IDs {
+		ids[i] = q.variable(id.Name, "String")
+	}
+
+	args := gqlObject{
+		{"where", gqlObject{
+			{"path", []string{"id"}},
+			{"operator", gqlEnum("ContainsAny")},
+			{"valueText", ids},
+		}},
+		{"limit", len(ast.IDs)},
+	}
+
+	if ast.Namespace != nil {
+		args = append(args, gqlArg{"tenant", q.variable(ast.Namespace.Name, "String")})
+	}
+
+	additional := []string{"id"}
+	if ast.IncludeVectors {
+		additional = append(additional, "vector")
+	}
+
+	return q.result(r.formatClassName(ast.Target.Name), args, r.selection(ast, additional))
+}
+
+// selection lists the requested properties followed by the _additional block.
+func (r *Renderer) selection(ast *types.VectorAST, additional []string) []string {
+	var fields []string
+	if ast.IncludeMetadata {
+		for _, f := range ast.MetadataFields {
+			fields = append(fields, f.Name)
+		}
+	}
+	return append(fields, "_additional { "+strings.Join(additional, " ")+" }")
+}
+
+func (r *Renderer) renderFilterGraphQL(f types.FilterItem, q *gqlQuery) (interface{}, error) {
+	// Variables are declared by the dialect as it meets them, so the
+	// compiler's own parameter list is not needed.
+	var scratch []string
+	where, err := filtertree.New(r.graphQLDialect(q), &scratch).Compile(f)
+	if err != nil {
+		return nil, err
+	}
+	return where, q.err
+}
+
+// graphQLDialect describes the where argument as a GraphQL input object
+// whose operand values are typed variables.
+func (r *Renderer) graphQLDialect(q *gqlQuery) *filtertree.Dialect {
+	return &filtertree.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			if !r.SupportsFilter(op) {
+				return "", false
+			}
+			return r.mapOperator(op), true
+		},
+		Logic:       r.mapLogic,
+		Placeholder: func(name string) interface{} { return gqlVar(name) },
+		Condition: func(c filtertree.Condition) interface{} {
+			cond := gqlObject{
+				{"path", []string{c.Field}},
+				{"operator", gqlEnum(c.Spelled)},
+			}
+			if c.Operator == types.Exists {
+				return append(cond, gqlArg{"valueBoolean", false})
+			}
+			key, typ := valueType(c.FieldType)
+			if c.Operator == types.Contains {
+				typ = "[" + typ + "]"
+			}
+			return append(cond, gqlArg{key, q.variable(string(c.Value.(gqlVar)), typ)})
+		},
+		Group: func(logic string, children []interface{}) interface{} {
+			return gqlObject{
+				{"operator", gqlEnum(logic)},
+				{"operands", gqlList(children)},
+			}
+		},
+		Range: func(field string, bounds []filtertree.Bound) interface{} {
+			operands := make(gqlList, 0, len(bounds))
+			for _, b := range bounds {
+				key, typ := "valueNumber", "Float"
+				if strings.TrimPrefix(b.FieldType, "[]") == "int" {
+					key, typ = "valueInt", "Int"
+				}
+				operands = append(operands, gqlObject{
+					{"path", []string{field}},
+					{"operator", gqlEnum(b.Spelled)},
+					{key, q.variable(string(b.Value.(gqlVar)), typ)},
+				})
+			}
+			if len(operands) == 1 {
+				return operands[0]
+			}
+			return gqlObject{
+				{"operator", gqlEnum("And")},
+				{"operands", operands},
+			}
+		},
+		Geo: func(field string, lat, lon, radius interface{}) interface{} {
+			return gqlObject{
+				{"path", []string{field}},
+				{"operator", gqlEnum("WithinGeoRange")},
+				{"valueGeoRange", gqlObject{
+					{"geoCoordinates", gqlObject{
+						{"latitude", q.variable(string(lat.(gqlVar)), "Float")},
+						{"longitude", q.variable(string(lon.(gqlVar)), "Float")},
+					}},
+					{"distance", gqlObject{
+						{"max", q.variable(string(radius.(gqlVar)), "Float")},
+					}},
+				}},
+			}
+		},
+	}
+}
+
+// valueType maps a VDML metadata type to the where operand key and the
+// GraphQL scalar type of its variable. Unknown types are treated as text.
+func valueType(fieldType string) (string, string) {
+	switch strings.TrimPrefix(fieldType, "[]") {
+	case "int":
+		return "valueInt", "Int"
+	case "float":
+		return "valueNumber", "Float"
+	case "bool":
+		return "valueBoolean", "Boolean"
+	default:
+		return "valueText", "String"
+	}
+}
+
+func writeArgs(b *strings.Builder, args gqlObject) {
+	for i, arg := range args {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(arg.key)
+		b.WriteString(": ")
+		writeValue(b, arg.value)
+	}
+}
+
+// writeValue writes v in GraphQL value syntax.
+func writeValue(b *strings.Builder, v interface{}) {
+	switch value := v.(type) {
+	case gqlVar:
+		b.WriteString("$")
+		b.WriteString(string(value))
+	case gqlEnum:
+		b.WriteString(string(value))
+	case gqlObject:
+		b.WriteString("{")
+		writeArgs(b, value)
+		b.WriteString("}")
+	case gqlList:
+		b.WriteString("[")
+		for i, item := range value {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeValue(b, item)
+		}
+		b.WriteString("]")
+	case []interface{}:
+		writeValue(b, gqlList(value))
+	case []string:
+		items := make(gqlList, len(value))
+		for i, s := range value {
+			items[i] = s
+		}
+		writeValue(b, items)
+	case []float32:
+		b.WriteString("[")
+		for i, f := range value {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+		}
+		b.WriteString("]")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		obj := make(gqlObject, len(keys))
+		for i, k := range keys {
+			obj[i] = gqlArg{k, value[k]}
+		}
+		writeValue(b, obj)
+	case string:
+		quoted, _ := json.Marshal(value)
+		b.Write(quoted)
+	case bool:
+		b.WriteString(strconv.FormatBool(value))
+	case int:
+		b.WriteString(strconv.Itoa(value))
+	case float64:
+		b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	default:
+		fmt.Fprintf(b, "%v", value)
+	}
+}
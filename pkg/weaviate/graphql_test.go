@@ -0,0 +1,360 @@
+package weaviate
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// decodeGraphQL splits a GraphQL mode result into its query and variables.
+func decodeGraphQL(t *testing.T, result *types.QueryResult) (string, map[string]interface{}) {
+	t.Helper()
+	var body struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if err := json.Unmarshal([]byte(result.JSON), &body); err != nil {
+		t.Fatalf("invalid JSON %s: %v", result.JSON, err)
+	}
+	return body.Query, body.Variables
+}
+
+func TestRenderSearchGraphQL(t *testing.T) {
+	renderer := NewGraphQL()
+
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		MinScore:    &types.Param{Name: "min_score"},
+		Namespace:   &types.Param{Name: "tenant"},
+		FilterClause: types.FilterCondition{
+			Field:    types.MetadataField{Name: "category", Type: "string"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "cat"},
+		},
+		IncludeMetadata: true,
+		MetadataFields:  []types.MetadataField{{Name: "category"}, {Name: "price"}},
+	}
+
+	result, err := renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, variables := decodeGraphQL(t, result)
+	expected := `query($query_vec: [Float], $min_score: Float, $cat: String, $tenant: String) { Get { Products(` +
+		`nearVector: {vector: $query_vec, certainty: $min_score}, limit: 10, ` +
+		`where: {path: ["category"], operator: Equal, valueText: $cat}, tenant: $tenant) ` +
+		`{ category price _additional { id distance certainty } } } }`
+	if query != expected {
+		t.Errorf("unexpected query:\n got: %s\nwant: %s", query, expected)
+	}
+
+	for _, name := range []string{"query_vec", "min_score", "cat", "tenant"} {
+		if variables[name] != ":"+name {
+			t.Errorf("expected variable %s=:%s, got %v", name, name, variables[name])
+		}
+	}
+	if len(result.RequiredParams) != 4 {
+		t.Errorf("expected 4 RequiredParams, got %v", result.RequiredParams)
+	}
+}
+
+func TestRenderSearchGraphQLValueTypes(t *testing.T) {
+	renderer := NewGraphQL()
+
+	topK := 5
+	tests := []struct {
+		name     string
+		filter   types.FilterItem
+		expected []string
+	}{
+		{
+			name: "int",
+			filter: types.FilterCondition{
+				Field: types.MetadataField{Name: "stock", Type: "int"}, Operator: types.GT, Value: types.Param{Name: "n"},
+			},
+			expected: []string{"$n: Int", "operator: GreaterThan, valueInt: $n"},
+		},
+		{
+			name: "float",
+			filter: types.FilterCondition{
+				Field: types.MetadataField{Name: "price", Type: "float"}, Operator: types.LE, Value: types.Param{Name: "p"},
+			},
+			expected: []string{"$p: Float", "valueNumber: $p"},
+		},
+		{
+			name: "bool",
+			filter: types.FilterCondition{
+				Field: types.MetadataField{Name: "active", Type: "bool"}, Operator: types.EQ, Value: types.Param{Name: "b"},
+			},
+			expected: []string{"$b: Boolean", "valueBoolean: $b"},
+		},
+		{
+			name: "contains",
+			filter: types.FilterCondition{
+				Field: types.MetadataField{Name: "tags", Type: "[]string"}, Operator: types.Contains, Value: types.Param{Name: "tags"},
+			},
+			expected: []string{"$tags: [String]", "operator: ContainsAny, valueText: $tags"},
+		},
+		{
+			name: "exists",
+			filter: types.FilterCondition{
+				Field: types.MetadataField{Name: "category"}, Operator: types.Exists,
+			},
+			expected: []string{"operator: IsNull, valueBoolean: false"},
+		},
+		{
+			name: "range",
+			filter: types.RangeFilter{
+				Field: types.MetadataField{Name: "stock", Type: "int"},
+				Min:   &types.Param{Name: "lo"},
+				Max:   &types.Param{Name: "hi"},
+			},
+			expected: []string{"$lo: Int, $hi: Int", "where: {operator: And, operands: [{path: [\"stock\"]"},
+		},
+		{
+			name: "group",
+			filter: types.FilterGroup{
+				Logic: types.OR,
+				Conditions: []types.FilterItem{
+					types.FilterCondition{Field: types.MetadataField{Name: "a"}, Operator: types.EQ, Value: types.Param{Name: "a"}},
+					types.FilterCondition{Field: types.MetadataField{Name: "b"}, Operator: types.NE, Value: types.Param{Name: "b"}},
+				},
+			},
+			expected: []string{"where: {operator: Or, operands: [", "operator: NotEqual, valueText: $b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ast := &types.VectorAST{
+				Operation:    types.OpSearch,
+				Target:       types.Collection{Name: "products"},
+				QueryVector:  &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+				TopK:         &types.PaginationValue{Static: &topK},
+				FilterClause: tt.filter,
+			}
+			result, err := renderer.Render(ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			query, _ := decodeGraphQL(t, result)
+			for _, want := range tt.expected {
+				if !strings.Contains(query, want) {
+					t.Errorf("expected %q in query: %s", want, query)
+				}
+			}
+			for _, p := range result.RequiredParams {
+				if p == "" {
+					t.Errorf("unexpected empty parameter in %v", result.RequiredParams)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderSearchGraphQLNearText(t *testing.T) {
+	renderer := NewGraphQL()
+
+	ast := &types.VectorAST{
+		Operation:      types.OpSearch,
+		Target:         types.Collection{Name: "articles"},
+		NearText:       &types.NearText{Concepts: types.Param{Name: "concepts"}},
+		TopK:           &types.PaginationValue{Param: &types.Param{Name: "k"}},
+		QueryEmbedding: &types.EmbeddingField{Name: "title"},
+		IncludeVectors: true,
+	}
+
+	result, err := renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, _ := decodeGraphQL(t, result)
+	for _, want := range []string{
+		"query($concepts: [String], $k: Int)",
+		`Articles(nearText: {concepts: $concepts, targetVectors: ["title"]}, limit: $k)`,
+		"_additional { id distance certainty vector }",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected %q in query: %s", want, query)
+		}
+	}
+}
+
+func TestRenderSearchGraphQLHybrid(t *testing.T) {
+	renderer := NewGraphQL()
+
+	topK := 10
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "articles"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "v"}},
+		TextQuery: &types.TextQuery{Query: types.Param{Name: "q"}, Properties: []types.TextProperty{
+			{Field: types.MetadataField{Name: "title"}, Boost: 1.5},
+			{Field: types.MetadataField{Name: "body"}},
+		}},
+		TopK: &types.PaginationValue{Static: &topK},
+	}
+
+	result, err := renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, _ := decodeGraphQL(t, result)
+	for _, want := range []string{
+		"query($q: String, $v: [Float])",
+		`Articles(hybrid: {query: $q, properties: ["title^1.5", "body"], vector: $v}, limit: 10)`,
+		"_additional { id score }",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected %q in query: %s", want, query)
+		}
+	}
+
+	ast.QueryVector = nil
+	result, err = renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if query, _ := decodeGraphQL(t, result); !strings.Contains(query, "Articles(bm25: {query: $q, ") {
+		t.Errorf("expected a bm25 search, got: %s", query)
+	}
+}
+
+func TestRenderSearchGraphQLLiteralVector(t *testing.T) {
+	renderer := NewGraphQL()
+
+	topK := 3
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products"},
+		QueryVector: &types.VectorValue{Literal: []float32{0.5, 0.25}},
+		TopK:        &types.PaginationValue{Static: &topK},
+	}
+
+	result, err := renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, variables := decodeGraphQL(t, result)
+	if !strings.HasPrefix(query, "query { Get { Products(nearVector: {vector: [0.5, 0.25]}, limit: 3)") {
+		t.Errorf("unexpected query: %s", query)
+	}
+	if len(variables) != 0 || len(result.RequiredParams) != 0 {
+		t.Errorf("expected no variables, got %v / %v", variables, result.RequiredParams)
+	}
+}
+
+func TestRenderSearchGraphQLConflictingTypes(t *testing.T) {
+	renderer := NewGraphQL()
+
+	topK := 5
+	ast := &types.VectorAST{
+		Operation:   types.OpSearch,
+		Target:      types.Collection{Name: "products"},
+		QueryVector: &types.VectorValue{Param: &types.Param{Name: "query_vec"}},
+		TopK:        &types.PaginationValue{Static: &topK},
+		FilterClause: types.FilterGroup{
+			Logic: types.AND,
+			Conditions: []types.FilterItem{
+				types.FilterCondition{Field: types.MetadataField{Name: "stock", Type: "int"}, Operator: types.EQ, Value: types.Param{Name: "v"}},
+				types.FilterCondition{Field: types.MetadataField{Name: "category", Type: "string"}, Operator: types.EQ, Value: types.Param{Name: "v"}},
+			},
+		},
+	}
+
+	_, err := renderer.Render(ast)
+	if err == nil || !strings.Contains(err.Error(), "parameter 'v' used as both Int and String") {
+		t.Fatalf("expected conflicting type error, got %v", err)
+	}
+}
+
+func TestRenderFetchGraphQL(t *testing.T) {
+	renderer := NewGraphQL()
+
+	ast := &types.VectorAST{
+		Operation:       types.OpFetch,
+		Target:          types.Collection{Name: "products"},
+		IDs:             []types.Param{{Name: "id1"}, {Name: "id2"}},
+		IncludeMetadata: true,
+		MetadataFields:  []types.MetadataField{{Name: "category"}},
+	}
+
+	result, err := renderer.Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	query, variables := decodeGraphQL(t, result)
+	expected := `query($id1: String, $id2: String) { Get { Products(` +
+		`where: {path: ["id"], operator: ContainsAny, valueText: [$id1, $id2]}, limit: 2) ` +
+		`{ category _additional { id } } } }`
+	if query != expected {
+		t.Errorf("unexpected query:\n got: %s\nwant: %s", query, expected)
+	}
+	if variables["id1"] != ":id1" || variables["id2"] != ":id2" {
+		t.Errorf("unexpected variables: %v", variables)
+	}
+}
+
+func TestRenderListGraphQL(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation:       types.OpFetch,
+		Target:          types.Collection{Name: "products"},
+		Page:            &types.Page{Size: 25, After: types.NewPageToken("weaviate", "5c56c793-69f3-4fbf-87e6-c4bf54c28c26")},
+		Namespace:       &types.Param{Name: "tenant"},
+		IncludeMetadata: true,
+		MetadataFields:  []types.MetadataField{{Name: "category"}},
+	}
+
+	result, err := NewGraphQL().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query, _ := decodeGraphQL(t, result)
+	expected := `query($tenant: String) { Get { Products(` +
+		`limit: 25, after: "5c56c793-69f3-4fbf-87e6-c4bf54c28c26", tenant: $tenant) ` +
+		`{ category _additional { id } } } }`
+	if query != expected {
+		t.Errorf("unexpected query:\n got: %s\nwant: %s", query, expected)
+	}
+
+	// The after cursor cannot be combined with a where filter
+	ast.FilterClause = types.FilterCondition{
+		Field:    types.MetadataField{Name: "category"},
+		Operator: types.EQ,
+		Value:    types.Param{Name: "cat"},
+	}
+	if _, err := NewGraphQL().Render(ast); err == nil || !strings.Contains(err.Error(), "without a filter") {
+		t.Errorf("expected a filter error, got %v", err)
+	}
+}
+
+func TestGraphQLModeKeepsWriteBodies(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation: types.OpDelete,
+		Target:    types.Collection{Name: "products"},
+		IDs:       []types.Param{{Name: "id"}},
+	}
+
+	want, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := NewGraphQL().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.JSON != want.JSON {
+		t.Errorf("expected REST body %s, got %s", want.JSON, got.JSON)
+	}
+}
@@ -0,0 +1,81 @@
+package weaviate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// indexDataTypes maps index kinds to Weaviate property data types.
+var indexDataTypes = map[types.IndexKind]string{
+	types.IndexKeyword: "text",
+	types.IndexText:    "text",
+	types.IndexInteger: "int",
+	types.IndexFloat:   "number",
+	types.IndexBool:    "boolean",
+	types.IndexGeo:     "geoCoordinates",
+}
+
+// textTokenization returns the tokenization of a text property in language.
+// Japanese, Korean, and Chinese use Weaviate's dictionary tokenizers; other
+// languages split on words.
+func textTokenization(language string) string {
+	switch language {
+	case "ja":
+		return "kagome_ja"
+	case "ko":
+		return "kagome_kr"
+	case "zh":
+		return "gse"
+	default:
+		return "word"
+	}
+}
+
+// RenderFieldIndex renders a property creation call carrying the field's
+// inverted index configuration. Weaviate fixes a property's indexes when it
+// is created, so the call fails for properties that already exist. Keyword
+// fields are filterable with field tokenization, text fields searchable with
+// tokenization for their language, and numbers also get range filters.
+func (r *Renderer) RenderFieldIndex(collection string, field types.MetadataField) (types.Endpoint, string, error) {
+	if collection == "" || field.Name == "" {
+		return types.Endpoint{}, "", fmt.Errorf("collection and field names are required")
+	}
+	dataType, ok := indexDataTypes[field.Index]
+	if !ok {
+		return types.Endpoint{}, "", fmt.Errorf("unsupported index kind: %q", field.Index)
+	}
+	if field.Index != types.IndexGeo && strings.HasPrefix(field.Type, "[]") {
+		dataType += "[]"
+	}
+
+	property := map[string]interface{}{
+		"name":     field.Name,
+		"dataType": []string{dataType},
+	}
+	switch field.Index {
+	case types.IndexKeyword:
+		property["indexFilterable"] = true
+		property["indexSearchable"] = false
+		property["tokenization"] = "field"
+	case types.IndexText:
+		property["indexFilterable"] = true
+		property["indexSearchable"] = true
+		property["tokenization"] = textTokenization(field.Language)
+	case types.IndexInteger, types.IndexFloat:
+		property["indexFilterable"] = true
+		property["indexRangeFilters"] = true
+	case types.IndexBool:
+		property["indexFilterable"] = true
+	}
+
+	data, err := json.Marshal(property)
+	if err != nil {
+		return types.Endpoint{}, "", fmt.Errorf("failed to serialize property: %w", err)
+	}
+	path := fmt.Sprintf("/v1/schema/%s/properties", url.PathEscape(r.formatClassName(collection)))
+	return types.Endpoint{Method: "POST", Path: path}, string(data), nil
+}
@@ -0,0 +1,37 @@
+package weaviate
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestRenderFieldIndex(t *testing.T) {
+	tests := []struct {
+		field types.MetadataField
+		body  string
+	}{
+		{types.MetadataField{Name: "tags", Type: "[]string", Index: types.IndexKeyword},
+			`{"dataType":["text[]"],"indexFilterable":true,"indexSearchable":false,"name":"tags","tokenization":"field"}`},
+		{types.MetadataField{Name: "body", Type: "string", Index: types.IndexText},
+			`{"dataType":["text"],"indexFilterable":true,"indexSearchable":true,"name":"body","tokenization":"word"}`},
+		{types.MetadataField{Name: "title_ja", Type: "string", Index: types.IndexText, Language: "ja"},
+			`{"dataType":["text"],"indexFilterable":true,"indexSearchable":true,"name":"title_ja","tokenization":"kagome_ja"}`},
+		{types.MetadataField{Name: "price", Type: "float", Index: types.IndexFloat},
+			`{"dataType":["number"],"indexFilterable":true,"indexRangeFilters":true,"name":"price"}`},
+		{types.MetadataField{Name: "location", Index: types.IndexGeo},
+			`{"dataType":["geoCoordinates"],"name":"location"}`},
+	}
+	for _, tt := range tests {
+		endpoint, body, err := New().RenderFieldIndex("products", tt.field)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.field.Name, err)
+		}
+		if endpoint.Method != "POST" || endpoint.Path != "/v1/schema/Products/properties" {
+			t.Errorf("%s: unexpected endpoint: %+v", tt.field.Name, endpoint)
+		}
+		if body != tt.body {
+			t.Errorf("%s: expected %s, got %s", tt.field.Name, tt.body, body)
+		}
+	}
+}
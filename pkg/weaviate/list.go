@@ -0,0 +1,77 @@
+package weaviate
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// SupportsList reports that listings render as a Get query resuming after
+// the ID of the previous page's last object, in either mode.
+func (r *Renderer) SupportsList() bool {
+	return true
+}
+
+// listCursor returns the object ID a listing resumes after, or the empty
+// string for the first page. Weaviate's after cursor cannot be combined
+// with a where filter, so listings are unfiltered.
+func listCursor(ast *types.VectorAST) (string, error) {
+	if ast.FilterClause != nil {
+		return "", fmt.Errorf("weaviate lists records only without a filter")
+	}
+	return ast.Page.Cursor("weaviate")
+}
+
+// renderList renders a listing as a JSON description of the Get arguments.
+func (r *Renderer) renderList(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	cursor, err := listCursor(ast)
+	if err != nil {
+		return nil, err
+	}
+	query := map[string]interface{}{
+		"class": r.formatClassName(ast.Target.Name),
+		"limit": ast.Page.Size,
+	}
+	if cursor != "" {
+		query["after"] = cursor
+	}
+	if ast.IncludeMetadata && len(ast.MetadataFields) > 0 {
+		fields := make([]string, len(ast.MetadataFields))
+		for i, f := range ast.MetadataFields {
+			fields[i] = f.Name
+		}
+		query["properties"] = fields
+	}
+	additional := []string{"id"}
+	if ast.IncludeVectors {
+		additional = append(additional, "vector")
+	}
+	query["additional"] = additional
+	if ast.Namespace != nil {
+		*params = append(*params, ast.Namespace.Name)
+		query["tenant"] = fmt.Sprintf(":%s", ast.Namespace.Name)
+	}
+	return toResult(query, *params)
+}
+
+// renderListGraphQL renders a listing as a GraphQL Get query with the
+// limit and after arguments.
+func (r *Renderer) renderListGraphQL(ast *types.VectorAST) (*types.QueryResult, error) {
+	cursor, err := listCursor(ast)
+	if err != nil {
+		return nil, err
+	}
+	q := &gqlQuery{}
+	args := gqlObject{{"limit", ast.Page.Size}}
+	if cursor != "" {
+		args = append(args, gqlArg{"after", cursor})
+	}
+	if ast.Namespace != nil {
+		args = append(args, gqlArg{"tenant", q.variable(ast.Namespace.Name, "String")})
+	}
+	additional := []string{"id"}
+	if ast.IncludeVectors {
+		additional = append(additional, "vector")
+	}
+	return q.result(r.formatClassName(ast.Target.Name), args, r.selection(ast, additional))
+}
@@ -0,0 +1,46 @@
+package weaviate
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// reservedProperties are property names Weaviate reserves for object
+// identity and additional result fields.
+var reservedProperties = map[string]bool{"id": true, "_id": true, "_additional": true}
+
+// ValidateNames reports names Weaviate cannot store: invalid class, property,
+// or named vector names, reserved property names, and collections whose
+// class names collide once capitalized.
+func (r *Renderer) ValidateNames(names types.SchemaNames) error {
+	var errs []error
+	classes := make(map[string]string)
+	for _, coll := range names.Collections {
+		if coll.Name != "" {
+			if !types.IsValidIdentifier(coll.Name) {
+				errs = append(errs, fmt.Errorf("collection '%s' is not a valid class name", coll.Name))
+			}
+			class := r.formatClassName(coll.Name)
+			if other, ok := classes[class]; ok && other != coll.Name {
+				errs = append(errs, fmt.Errorf("collections '%s' and '%s' both map to class '%s'", other, coll.Name, class))
+			}
+			classes[class] = coll.Name
+		}
+		for _, name := range coll.Embeddings {
+			if !types.IsValidIdentifier(name) {
+				errs = append(errs, fmt.Errorf("embedding '%s' in collection '%s' is not a valid vector name", name, coll.Name))
+			}
+		}
+		for _, name := range coll.Fields {
+			switch {
+			case reservedProperties[name]:
+				errs = append(errs, fmt.Errorf("field '%s' in collection '%s' is a reserved property name", name, coll.Name))
+			case !types.IsValidIdentifier(name):
+				errs = append(errs, fmt.Errorf("field '%s' in collection '%s' is not a valid property name", name, coll.Name))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,35 @@
+package weaviate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestValidateNames(t *testing.T) {
+	valid := types.SchemaNames{Collections: []types.CollectionNames{
+		{Name: "products", Embeddings: []string{"embedding"}, Fields: []string{"category", "price"}},
+	}}
+	if err := New().ValidateNames(valid); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	err := New().ValidateNames(types.SchemaNames{Collections: []types.CollectionNames{
+		{Name: "products", Fields: []string{"id", "_additional", "list-price"}},
+		{Name: "Products"},
+	}})
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		"field 'id' in collection 'products' is a reserved property name",
+		"field '_additional' in collection 'products' is a reserved property name",
+		"field 'list-price' in collection 'products' is not a valid property name",
+		"collections 'products' and 'Products' both map to class 'Products'",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+}
@@ -4,8 +4,12 @@ package weaviate
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"strconv"
 	"strings"
 
+	"github.com/zoobzio/vectql/internal/filtertree"
 	"github.com/zoobzio/vectql/internal/types"
 )
 
@@ -22,7 +26,11 @@ func toResult(query map[string]interface{}, params []string) (*types.QueryResult
 }
 
 // Renderer renders VectorAST to Weaviate GraphQL format.
-type Renderer struct{}
+type Renderer struct {
+	// Mode selects how searches and fetches are emitted. The zero value is
+	// ModeJSON.
+	Mode Mode
+}
 
 // New creates a new Weaviate renderer.
 func New() *Renderer {
@@ -31,12 +39,37 @@ func New() *Renderer {
 
 // Render converts a VectorAST to Weaviate query format.
 func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	result, err := r.render(ast)
+	if err != nil {
+		return nil, err
+	}
+	// Weaviate searches take no consistency level, so freshness is dropped
+	if ast.Freshness != nil {
+		result.Ignored = append(result.Ignored, "freshness")
+	}
+	// Near and hybrid searches have no per-filter or field-value scoring
+	if len(ast.Boosts) > 0 {
+		result.Ignored = append(result.Ignored, "boosts")
+	}
+	return result, nil
+}
+
+func (r *Renderer) render(ast *types.VectorAST) (*types.QueryResult, error) {
 	if err := ast.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid AST: %w", err)
 	}
 
 	var params []string
 
+	if r.Mode == ModeGraphQL {
+		switch ast.Operation {
+		case types.OpSearch:
+			return r.renderSearchGraphQL(ast)
+		case types.OpFetch:
+			return r.renderFetchGraphQL(ast)
+		}
+	}
+
 	switch ast.Operation {
 	case types.OpSearch:
 		return r.renderSearch(ast, &params)
@@ -54,35 +87,68 @@ func (r *Renderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
 }
 
 func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	// Weaviate configures rescoring on the class vector index, not per query
+	if err := ast.ValidateScoring("weaviate"); err != nil {
+		return nil, err
+	}
+	if ast.Quantization != nil {
+		return nil, fmt.Errorf("weaviate does not support quantization search parameters")
+	}
+
 	query := make(map[string]interface{})
 
 	// Class name (collection)
 	className := r.formatClassName(ast.Target.Name)
 	query["class"] = className
 
-	// Near vector
-	nearVector := make(map[string]interface{})
-	if ast.QueryVector != nil {
+	// Search input: a raw vector or a module-vectorized text or image, or a
+	// BM25 query on its own (bm25) or fused with a vector (hybrid)
+	near := make(map[string]interface{})
+	nearKey := "nearVector"
+	if ast.TextQuery != nil {
+		nearKey = "bm25"
+		if ast.QueryVector != nil {
+			nearKey = "hybrid"
+		}
+		*params = append(*params, ast.TextQuery.Query.Name)
+		near["query"] = fmt.Sprintf(":%s", ast.TextQuery.Query.Name)
+		if len(ast.TextQuery.Properties) > 0 {
+			near["properties"] = textProperties(ast.TextQuery)
+		}
+	}
+	switch {
+	case ast.NearText != nil:
+		nearKey = "nearText"
+		*params = append(*params, ast.NearText.Concepts.Name)
+		near["concepts"] = fmt.Sprintf(":%s", ast.NearText.Concepts.Name)
+	case ast.NearImage != nil:
+		nearKey = "nearImage"
+		*params = append(*params, ast.NearImage.Image.Name)
+		near["image"] = fmt.Sprintf(":%s", ast.NearImage.Image.Name)
+	case ast.QueryVector != nil:
 		if ast.QueryVector.Param != nil {
 			*params = append(*params, ast.QueryVector.Param.Name)
-			nearVector["vector"] = fmt.Sprintf(":%s", ast.QueryVector.Param.Name)
+			near["vector"] = fmt.Sprintf(":%s", ast.QueryVector.Param.Name)
 		} else {
-			nearVector["vector"] = ast.QueryVector.Literal
+			near["vector"] = ast.QueryVector.Literal
 		}
 	}
 
 	// Certainty threshold
 	if ast.MinScore != nil {
+		if ast.TextQuery != nil {
+			return nil, fmt.Errorf("weaviate does not support score thresholds for %s search", ast.Modality())
+		}
 		*params = append(*params, ast.MinScore.Name)
-		nearVector["certainty"] = fmt.Sprintf(":%s", ast.MinScore.Name)
+		near["certainty"] = fmt.Sprintf(":%s", ast.MinScore.Name)
 	}
 
 	// Target vectors (named vectors)
-	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" {
-		nearVector["targetVectors"] = []string{ast.QueryEmbedding.Name}
+	if ast.QueryEmbedding != nil && ast.QueryEmbedding.Name != "" && nearKey != "bm25" {
+		near["targetVectors"] = []string{ast.QueryEmbedding.Name}
 	}
 
-	query["nearVector"] = nearVector
+	query[nearKey] = near
 
 	// Limit
 	if ast.TopK != nil {
@@ -118,16 +184,32 @@ func (r *Renderer) renderSearch(ast *types.VectorAST, params *[]string) (*types.
 		query["tenant"] = fmt.Sprintf(":%s", ast.Namespace.Name)
 	}
 
-	// Additional fields for vectors
+	// Additional fields: keyword and hybrid results carry a score, not a distance
+	additional := []string{"distance", "certainty"}
+	if ast.TextQuery != nil {
+		additional = []string{"score"}
+	}
 	if ast.IncludeVectors {
-		query["additional"] = []string{"vector", "distance", "certainty"}
-	} else {
-		query["additional"] = []string{"distance", "certainty"}
+		additional = append([]string{"vector"}, additional...)
 	}
+	query["additional"] = additional
 
 	return toResult(query, *params)
 }
 
+// textProperties lists the properties a BM25 query searches, with boosts in
+// Weaviate's "name^boost" form.
+func textProperties(tq *types.TextQuery) []string {
+	props := make([]string, len(tq.Properties))
+	for i, p := range tq.Properties {
+		props[i] = p.Field.Name
+		if p.Boost != 0 {
+			props[i] += "^" + strconv.FormatFloat(p.Boost, 'g', -1, 64)
+		}
+	}
+	return props
+}
+
 func (r *Renderer) renderUpsert(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
 	className := r.formatClassName(ast.Target.Name)
 
@@ -207,6 +289,9 @@ func (r *Renderer) renderDelete(ast *types.VectorAST, params *[]string) (*types.
 }
 
 func (r *Renderer) renderFetch(ast *types.VectorAST, params *[]string) (*types.QueryResult, error) {
+	if ast.Lists() {
+		return r.renderList(ast, params)
+	}
 	className := r.formatClassName(ast.Target.Name)
 
 	ids := make([]string, len(ast.IDs))
@@ -279,83 +364,64 @@ func (r *Renderer) renderUpdate(ast *types.VectorAST, params *[]string) (*types.
 }
 
 func (r *Renderer) renderFilter(f types.FilterItem, params *[]string) (interface{}, error) {
-	switch filter := f.(type) {
-	case types.FilterCondition:
-		*params = append(*params, filter.Value.Name)
-		return map[string]interface{}{
-			"path":        []string{filter.Field.Name},
-			"operator":    r.mapOperator(filter.Operator),
-			"valueString": fmt.Sprintf(":%s", filter.Value.Name),
-		}, nil
-
-	case types.FilterGroup:
-		operands := make([]interface{}, 0, len(filter.Conditions))
-		for _, c := range filter.Conditions {
-			rendered, err := r.renderFilter(c, params)
-			if err != nil {
-				return nil, err
+	return filtertree.New(r.dialect(), params).Compile(f)
+}
+
+// dialect describes Weaviate's where filter with path/operator/operands nodes.
+func (r *Renderer) dialect() *filtertree.Dialect {
+	return &filtertree.Dialect{
+		Operator: func(op types.FilterOperator) (string, bool) {
+			if !r.SupportsFilter(op) {
+				return "", false
 			}
-			operands = append(operands, rendered)
-		}
-		return map[string]interface{}{
-			"operator": r.mapLogic(filter.Logic),
-			"operands": operands,
-		}, nil
-
-	case types.RangeFilter:
-		operands := []interface{}{}
-		if filter.Min != nil {
-			*params = append(*params, filter.Min.Name)
-			op := "GreaterThanEqual"
-			if filter.MinExclusive {
-				op = "GreaterThan"
+			return r.mapOperator(op), true
+		},
+		Logic: r.mapLogic,
+		Condition: func(c filtertree.Condition) interface{} {
+			return map[string]interface{}{
+				"path":        []string{c.Field},
+				"operator":    c.Spelled,
+				"valueString": c.Value,
 			}
-			operands = append(operands, map[string]interface{}{
-				"path":        []string{filter.Field.Name},
-				"operator":    op,
-				"valueNumber": fmt.Sprintf(":%s", filter.Min.Name),
-			})
-		}
-		if filter.Max != nil {
-			*params = append(*params, filter.Max.Name)
-			op := "LessThanEqual"
-			if filter.MaxExclusive {
-				op = "LessThan"
+		},
+		Group: func(logic string, children []interface{}) interface{} {
+			return map[string]interface{}{
+				"operator": logic,
+				"operands": children,
 			}
-			operands = append(operands, map[string]interface{}{
-				"path":        []string{filter.Field.Name},
-				"operator":    op,
-				"valueNumber": fmt.Sprintf(":%s", filter.Max.Name),
-			})
-		}
-		if len(operands) == 1 {
-			return operands[0], nil
-		}
-		return map[string]interface{}{
-			"operator": "And",
-			"operands": operands,
-		}, nil
-
-	case types.GeoFilter:
-		*params = append(*params, filter.Center.Lat.Name)
-		*params = append(*params, filter.Center.Lon.Name)
-		*params = append(*params, filter.Radius.Name)
-		return map[string]interface{}{
-			"path":     []string{filter.Field.Name},
-			"operator": "WithinGeoRange",
-			"valueGeoRange": map[string]interface{}{
-				"geoCoordinates": map[string]interface{}{
-					"latitude":  fmt.Sprintf(":%s", filter.Center.Lat.Name),
-					"longitude": fmt.Sprintf(":%s", filter.Center.Lon.Name),
-				},
-				"distance": map[string]interface{}{
-					"max": fmt.Sprintf(":%s", filter.Radius.Name),
+		},
+		Range: func(field string, bounds []filtertree.Bound) interface{} {
+			operands := make([]interface{}, 0, len(bounds))
+			for _, b := range bounds {
+				operands = append(operands, map[string]interface{}{
+					"path":        []string{field},
+					"operator":    b.Spelled,
+					"valueNumber": b.Value,
+				})
+			}
+			if len(operands) == 1 {
+				return operands[0]
+			}
+			return map[string]interface{}{
+				"operator": "And",
+				"operands": operands,
+			}
+		},
+		Geo: func(field string, lat, lon, radius interface{}) interface{} {
+			return map[string]interface{}{
+				"path":     []string{field},
+				"operator": "WithinGeoRange",
+				"valueGeoRange": map[string]interface{}{
+					"geoCoordinates": map[string]interface{}{
+						"latitude":  lat,
+						"longitude": lon,
+					},
+					"distance": map[string]interface{}{
+						"max": radius,
+					},
 				},
-			},
-		}, nil
-
-	default:
-		return nil, fmt.Errorf("unsupported filter type: %T", f)
+			}
+		},
 	}
 }
 
@@ -432,3 +498,95 @@ func (r *Renderer) SupportsMetric(metric types.DistanceMetric) bool {
 		return false
 	}
 }
+
+// SupportsModality indicates if Weaviate accepts a search input modality.
+// Text and image inputs require a matching vectorizer module on the class;
+// keyword and hybrid searches run BM25 over the class inverted index.
+func (r *Renderer) SupportsModality(m types.Modality) bool {
+	switch m {
+	case types.ModalityVector, types.ModalityText, types.ModalityImage,
+		types.ModalityKeyword, types.ModalityHybrid:
+		return true
+	default:
+		return false
+	}
+}
+
+// Capabilities describes the features supported by Weaviate.
+func (r *Renderer) Capabilities() types.Capabilities {
+	return types.ProbeCapabilities("weaviate", r)
+}
+
+// RenderTo writes the Weaviate query body for ast to w.
+func (r *Renderer) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := r.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+// TenantSetup describes the call that creates tenant in the class of a
+// multi-tenant collection, so the tenant exists before objects are written
+// to it. Weaviate answers 422 when the tenant already exists.
+func (r *Renderer) TenantSetup(collection, tenant string) (types.Endpoint, string, error) {
+	return r.RenderPartition(types.PartitionCreate, collection, tenant)
+}
+
+// RenderPartition maps partition lifecycle operations to the tenants of a
+// multi-tenant class: create adds the tenant, drop deletes it with its
+// objects, and load activates it.
+func (r *Renderer) RenderPartition(op types.PartitionOp, collection, tenant string) (types.Endpoint, string, error) {
+	if tenant == "" {
+		return types.Endpoint{}, "", fmt.Errorf("tenant name is required")
+	}
+	path := fmt.Sprintf("/v1/schema/%s/tenants", url.PathEscape(r.formatClassName(collection)))
+
+	var method string
+	var body interface{}
+	switch op {
+	case types.PartitionCreate:
+		method, body = "POST", []map[string]string{{"name": tenant}}
+	case types.PartitionDrop:
+		method, body = "DELETE", []string{tenant}
+	case types.PartitionLoad:
+		method, body = "PUT", []map[string]string{{"name": tenant, "activityStatus": "HOT"}}
+	default:
+		return types.Endpoint{}, "", fmt.Errorf("unsupported partition operation: %s", op)
+	}
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return types.Endpoint{}, "", fmt.Errorf("failed to serialize tenant: %w", err)
+	}
+	return types.Endpoint{Method: method, Path: path}, string(data), nil
+}
+
+// Format reports that Weaviate queries are JSON.
+func (r *Renderer) Format() types.Format {
+	return types.FormatJSON
+}
+
+// Endpoint returns the Weaviate REST call for ast. Searches and fetches are
+// GraphQL Get queries; updates patch a single object.
+func (r *Renderer) Endpoint(ast *types.VectorAST) (types.Endpoint, error) {
+	switch ast.Operation {
+	case types.OpSearch, types.OpFetch:
+		return types.Endpoint{Method: "POST", Path: "/v1/graphql"}, nil
+	case types.OpUpsert:
+		return types.Endpoint{Method: "POST", Path: "/v1/batch/objects"}, nil
+	case types.OpDelete:
+		return types.Endpoint{Method: "DELETE", Path: "/v1/batch/objects"}, nil
+	case types.OpUpdate:
+		if len(ast.IDs) == 0 {
+			return types.Endpoint{}, fmt.Errorf("UPDATE requires at least one ID")
+		}
+		path := fmt.Sprintf("/v1/objects/%s/:%s", url.PathEscape(r.formatClassName(ast.Target.Name)), ast.IDs[0].Name)
+		return types.Endpoint{Method: "PATCH", Path: path}, nil
+	default:
+		return types.Endpoint{}, fmt.Errorf("unsupported operation: %s", ast.Operation)
+	}
+}
@@ -173,6 +173,29 @@ func TestRenderFetch(t *testing.T) {
 	}
 }
 
+func TestRenderList(t *testing.T) {
+	ast := &types.VectorAST{
+		Operation:      types.OpFetch,
+		Target:         types.Collection{Name: "products"},
+		Page:           &types.Page{Size: 25},
+		IncludeVectors: true,
+	}
+
+	result, err := New().Render(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"additional":["id","vector"],"class":"Products","limit":25}`
+	if result.JSON != expected {
+		t.Errorf("unexpected body:\n got: %s\nwant: %s", result.JSON, expected)
+	}
+
+	ast.Page.After = types.NewPageToken("qdrant", "42")
+	if _, err := New().Render(ast); err == nil || !strings.Contains(err.Error(), "issued by 'qdrant'") {
+		t.Errorf("expected a provider error, got %v", err)
+	}
+}
+
 func TestRenderUpdate(t *testing.T) {
 	renderer := New()
 
@@ -321,3 +344,218 @@ func TestFormatClassName(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderFilterRejectsUnsupportedOperator(t *testing.T) {
+	renderer := New()
+
+	filter := types.FilterGroup{
+		Logic: types.AND,
+		Conditions: []types.FilterItem{
+			types.FilterCondition{
+				Field:    types.MetadataField{Name: "category"},
+				Operator: types.EQ,
+				Value:    types.Param{Name: "cat"},
+			},
+			types.FilterCondition{
+				Field:    types.MetadataField{Name: "name"},
+				Operator: types.Matches,
+				Value:    types.Param{Name: "pattern"},
+			},
+		},
+	}
+
+	var params []string
+	if _, err := renderer.renderFilter(filter, &params); err == nil {
+		t.Error("expected error for unsupported operator")
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	renderer := New()
+	caps := renderer.Capabilities()
+
+	if caps.Provider != "weaviate" {
+		t.Errorf("expected provider weaviate, got %q", caps.Provider)
+	}
+	for _, op := range types.FilterOperators {
+		if caps.SupportsFilter(op) != renderer.SupportsFilter(op) {
+			t.Errorf("capabilities disagree with SupportsFilter for %s", op)
+		}
+	}
+	if renderer.Format() != types.FormatJSON {
+		t.Errorf("expected JSON format, got %s", renderer.Format())
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	renderer := New()
+
+	tests := []struct {
+		op     types.Operation
+		method string
+		path   string
+	}{
+		{types.OpSearch, "POST", "/v1/graphql"},
+		{types.OpUpdate, "PATCH", "/v1/objects/Products/:id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.op), func(t *testing.T) {
+			ast := &types.VectorAST{
+				Operation: tt.op,
+				Target:    types.Collection{Name: "products"},
+				IDs:       []types.Param{{Name: "id"}},
+			}
+			endpoint, err := renderer.Endpoint(ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if endpoint.Method != tt.method || endpoint.Path != tt.path {
+				t.Errorf("expected %s %s, got %s %s", tt.method, tt.path, endpoint.Method, endpoint.Path)
+			}
+		})
+	}
+}
+
+func TestTenantSetup(t *testing.T) {
+	endpoint, body, err := New().TenantSetup("products", "tenant_a")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Method != "POST" || endpoint.Path != "/v1/schema/Products/tenants" {
+		t.Errorf("unexpected endpoint: %s %s", endpoint.Method, endpoint.Path)
+	}
+	if body != `[{"name":"tenant_a"}]` {
+		t.Errorf("unexpected body: %s", body)
+	}
+
+	if _, _, err := New().TenantSetup("products", ""); err == nil {
+		t.Error("expected an error for an empty tenant")
+	}
+}
+
+func TestRenderPartition(t *testing.T) {
+	tests := []struct {
+		op     types.PartitionOp
+		method string
+		body   string
+	}{
+		{types.PartitionCreate, "POST", `[{"name":"tenant_a"}]`},
+		{types.PartitionDrop, "DELETE", `["tenant_a"]`},
+		{types.PartitionLoad, "PUT", `[{"activityStatus":"HOT","name":"tenant_a"}]`},
+	}
+	for _, tt := range tests {
+		endpoint, body, err := New().RenderPartition(tt.op, "products", "tenant_a")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.op, err)
+		}
+		if endpoint.Method != tt.method || endpoint.Path != "/v1/schema/Products/tenants" {
+			t.Errorf("%s: unexpected endpoint: %s %s", tt.op, endpoint.Method, endpoint.Path)
+		}
+		if body != tt.body {
+			t.Errorf("%s: unexpected body: %s", tt.op, body)
+		}
+	}
+}
+
+func TestRenderSearchNearModules(t *testing.T) {
+	renderer := New()
+
+	topK := 5
+	tests := []struct {
+		name     string
+		ast      *types.VectorAST
+		expected string
+		param    string
+	}{
+		{
+			name: "nearText",
+			ast: &types.VectorAST{
+				Operation: types.OpSearch,
+				Target:    types.Collection{Name: "articles"},
+				NearText:  &types.NearText{Concepts: types.Param{Name: "concepts"}},
+				TopK:      &types.PaginationValue{Static: &topK},
+			},
+			expected: `"nearText":{"concepts":":concepts"}`,
+			param:    "concepts",
+		},
+		{
+			name: "nearImage",
+			ast: &types.VectorAST{
+				Operation: types.OpSearch,
+				Target:    types.Collection{Name: "photos"},
+				NearImage: &types.NearImage{Image: types.Param{Name: "image"}},
+				TopK:      &types.PaginationValue{Static: &topK},
+			},
+			expected: `"nearImage":{"image":":image"}`,
+			param:    "image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := renderer.Render(tt.ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.Contains(result.JSON, tt.expected) {
+				t.Errorf("expected %s in JSON: %s", tt.expected, result.JSON)
+			}
+			if strings.Contains(result.JSON, "nearVector") {
+				t.Errorf("unexpected nearVector in JSON: %s", result.JSON)
+			}
+			if len(result.RequiredParams) != 1 || result.RequiredParams[0] != tt.param {
+				t.Errorf("expected RequiredParams=[%s], got %v", tt.param, result.RequiredParams)
+			}
+		})
+	}
+}
+
+func TestRenderSearchText(t *testing.T) {
+	renderer := New()
+	topK := 5
+	properties := []types.TextProperty{
+		{Field: types.MetadataField{Name: "title"}, Boost: 2},
+		{Field: types.MetadataField{Name: "body"}},
+	}
+
+	keyword := &types.VectorAST{
+		Operation: types.OpSearch,
+		Target:    types.Collection{Name: "articles"},
+		TextQuery: &types.TextQuery{Query: types.Param{Name: "q"}, Properties: properties},
+		TopK:      &types.PaginationValue{Static: &topK},
+	}
+	result, err := renderer.Render(keyword)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"additional":["score"],"bm25":{"properties":["title^2","body"],"query":":q"},"class":"Articles","limit":5}`
+	if result.JSON != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, result.JSON)
+	}
+
+	hybrid := &types.VectorAST{
+		Operation:      types.OpSearch,
+		Target:         types.Collection{Name: "articles"},
+		QueryVector:    &types.VectorValue{Param: &types.Param{Name: "v"}},
+		TextQuery:      &types.TextQuery{Query: types.Param{Name: "q"}, Properties: properties[1:]},
+		QueryEmbedding: &types.EmbeddingField{Name: "content"},
+		TopK:           &types.PaginationValue{Static: &topK},
+	}
+	result, err = renderer.Render(hybrid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `"hybrid":{"properties":["body"],"query":":q","targetVectors":["content"],"vector":":v"}`
+	if !strings.Contains(result.JSON, want) {
+		t.Errorf("expected %s in JSON: %s", want, result.JSON)
+	}
+	if len(result.RequiredParams) != 2 {
+		t.Errorf("expected 2 params, got %v", result.RequiredParams)
+	}
+
+	hybrid.MinScore = &types.Param{Name: "min"}
+	if _, err := renderer.Render(hybrid); err == nil {
+		t.Error("expected error for a score threshold on a hybrid search")
+	}
+}
@@ -0,0 +1,210 @@
+package vectql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// DefaultPostFilterMultiplier is the TopK multiplier used when post-filtering
+// is enabled without an explicit multiplier.
+const DefaultPostFilterMultiplier = 4
+
+// renderWithPostFilter renders a SEARCH with the predicates the renderer cannot
+// express removed from the provider query. The query over-fetches by the
+// multiplier and the residual predicates are returned on the QueryResult for
+// client-side evaluation with ApplyPostFilter.
+func renderWithPostFilter(ctx context.Context, ast *types.VectorAST, renderer Renderer, multiplier int) (*types.QueryResult, error) {
+	if ast.Operation != types.OpSearch {
+		return nil, fmt.Errorf("post-filtering is only available for SEARCH")
+	}
+
+	pushdown, residual := splitFilter(ast.FilterClause, renderer)
+	if residual == nil {
+		return renderContext(ctx, renderer, ast)
+	}
+
+	if ast.TopK == nil || ast.TopK.Static == nil {
+		return nil, fmt.Errorf("post-filtering requires a static TopK")
+	}
+
+	limit := *ast.TopK.Static
+	fetch := limit * multiplier
+	if fetch > types.MaxTopK {
+		fetch = types.MaxTopK
+	}
+
+	rewritten := *ast
+	rewritten.FilterClause = pushdown
+	rewritten.TopK = &types.PaginationValue{Static: &fetch}
+	rewritten.IncludeMetadata = true
+	if len(ast.MetadataFields) > 0 {
+		rewritten.MetadataFields = appendFilterFields(ast.MetadataFields, residual)
+	}
+
+	result, err := renderContext(ctx, renderer, &rewritten)
+	if err != nil {
+		return nil, err
+	}
+	result.PostFilter = residual
+	result.PostFilterLimit = limit
+	return result, nil
+}
+
+// splitFilter separates the parts of a filter the renderer can express from
+// residual predicates that must be evaluated client-side. Only top-level AND
+// conjuncts are split; an OR or NOT containing an unsupported predicate is
+// evaluated client-side in full.
+func splitFilter(f types.FilterItem, renderer Renderer) (pushdown, residual types.FilterItem) {
+	if f == nil || filterSupported(f, renderer) {
+		return f, nil
+	}
+
+	group, ok := f.(types.FilterGroup)
+	if !ok || group.Logic != types.AND {
+		return nil, f
+	}
+
+	var pushed, rest []types.FilterItem
+	for _, c := range group.Conditions {
+		p, r := splitFilter(c, renderer)
+		if p != nil {
+			pushed = append(pushed, p)
+		}
+		if r != nil {
+			rest = append(rest, r)
+		}
+	}
+	return joinAnd(pushed), joinAnd(rest)
+}
+
+// filterSupported reports whether every predicate in f can be rendered.
+func filterSupported(f types.FilterItem, renderer Renderer) bool {
+	switch filter := f.(type) {
+	case types.FilterCondition:
+		return renderer.SupportsFilter(filter.Operator)
+
+	case types.FilterGroup:
+		for _, c := range filter.Conditions {
+			if !filterSupported(c, renderer) {
+				return false
+			}
+		}
+		return true
+
+	case types.RangeFilter:
+		if filter.Min != nil {
+			op := types.GE
+			if filter.MinExclusive {
+				op = types.GT
+			}
+			if !renderer.SupportsFilter(op) {
+				return false
+			}
+		}
+		if filter.Max != nil {
+			op := types.LE
+			if filter.MaxExclusive {
+				op = types.LT
+			}
+			if !renderer.SupportsFilter(op) {
+				return false
+			}
+		}
+		return true
+
+	default:
+		// Geo and extension filters have no capability probe; leave them to the renderer.
+		return true
+	}
+}
+
+func joinAnd(items []types.FilterItem) types.FilterItem {
+	switch len(items) {
+	case 0:
+		return nil
+	case 1:
+		return items[0]
+	default:
+		return types.FilterGroup{Logic: types.AND, Conditions: items}
+	}
+}
+
+// appendFilterFields adds the fields referenced by f to a metadata selection
+// so residual predicates can be evaluated against returned metadata.
+func appendFilterFields(fields []types.MetadataField, f types.FilterItem) []types.MetadataField {
+	selected := make(map[types.MetadataField]bool, len(fields))
+	out := make([]types.MetadataField, 0, len(fields))
+	for _, field := range fields {
+		selected[field] = true
+		out = append(out, field)
+	}
+	for _, field := range filterFields(f) {
+		if !selected[field] {
+			selected[field] = true
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+// filterFields returns the metadata fields referenced by a filter in order.
+func filterFields(f types.FilterItem) []types.MetadataField {
+	switch filter := f.(type) {
+	case types.FilterCondition:
+		return []types.MetadataField{filter.Field}
+	case types.RangeFilter:
+		return []types.MetadataField{filter.Field}
+	case types.GeoFilter:
+		return []types.MetadataField{filter.Field}
+	case types.FilterGroup:
+		var fields []types.MetadataField
+		for _, c := range filter.Conditions {
+			fields = append(fields, filterFields(c)...)
+		}
+		return fields
+	default:
+		return nil
+	}
+}
+
+// ApplyPostFilter evaluates the residual predicates of a post-filtered query
+// against each match's metadata, keeping at most PostFilterLimit matches in
+// their original order. Parameters are normalized by the query's bind
+// transforms first. Matches are returned unchanged when the result has no
+// residual predicates.
+func ApplyPostFilter(result *types.QueryResult, matches []Match, params map[string]interface{}) ([]Match, error) {
+	if result == nil || result.PostFilter == nil {
+		return matches, nil
+	}
+
+	params = deriveParams(result.Derived, params)
+	if len(result.Transforms) > 0 {
+		transformed := make(map[string]interface{}, len(params))
+		for name, value := range params {
+			transformed[name] = value
+		}
+		if err := applyBindTransforms(result.Transforms, transformed); err != nil {
+			return nil, fmt.Errorf("post-filter: %w", err)
+		}
+		params = transformed
+	}
+
+	kept := make([]Match, 0, result.PostFilterLimit)
+	patterns := patternCache{}
+	for _, m := range matches {
+		ok, err := evalFilter(result.PostFilter, m.Metadata, params, patterns)
+		if err != nil {
+			return nil, fmt.Errorf("post-filter: %w", err)
+		}
+		if !ok {
+			continue
+		}
+		kept = append(kept, m)
+		if len(kept) == result.PostFilterLimit {
+			break
+		}
+	}
+	return kept, nil
+}
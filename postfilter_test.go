@@ -0,0 +1,241 @@
+package vectql
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// stubRenderer captures the AST it renders and supports a fixed operator set.
+type stubRenderer struct {
+	filters  map[types.FilterOperator]bool
+	rendered *types.VectorAST
+}
+
+func newStubRenderer(ops ...types.FilterOperator) *stubRenderer {
+	filters := make(map[types.FilterOperator]bool, len(ops))
+	for _, op := range ops {
+		filters[op] = true
+	}
+	return &stubRenderer{filters: filters}
+}
+
+func (r *stubRenderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	r.rendered = ast
+	return &types.QueryResult{JSON: "{}"}, nil
+}
+
+func (*stubRenderer) SupportsOperation(types.Operation) bool { return true }
+
+func (r *stubRenderer) SupportsFilter(op types.FilterOperator) bool { return r.filters[op] }
+
+func (*stubRenderer) SupportsMetric(types.DistanceMetric) bool { return true }
+
+func TestPostFilter_SplitsUnsupportedConjuncts(t *testing.T) {
+	renderer := newStubRenderer(types.EQ)
+	category := types.MetadataField{Name: "category"}
+	name := types.MetadataField{Name: "name"}
+
+	result, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		Filter(Eq(category, types.Param{Name: "cat"})).
+		Filter(Matches(name, types.Param{Name: "pattern"})).
+		PostFilter(3).
+		Render(renderer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if *renderer.rendered.TopK.Static != 30 {
+		t.Errorf("expected over-fetch TopK 30, got %d", *renderer.rendered.TopK.Static)
+	}
+	pushed, ok := renderer.rendered.FilterClause.(types.FilterCondition)
+	if !ok || pushed.Operator != types.EQ {
+		t.Errorf("expected EQ condition pushed down, got %#v", renderer.rendered.FilterClause)
+	}
+	residual, ok := result.PostFilter.(types.FilterCondition)
+	if !ok || residual.Operator != types.Matches {
+		t.Errorf("expected MATCHES residual, got %#v", result.PostFilter)
+	}
+	if result.PostFilterLimit != 10 {
+		t.Errorf("expected PostFilterLimit 10, got %d", result.PostFilterLimit)
+	}
+}
+
+func TestPostFilter_SupportedFilterUnchanged(t *testing.T) {
+	renderer := newStubRenderer(types.EQ)
+
+	result, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		Filter(Eq(types.MetadataField{Name: "category"}, types.Param{Name: "cat"})).
+		PostFilter(0).
+		Render(renderer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.PostFilter != nil {
+		t.Errorf("expected no residual filter, got %#v", result.PostFilter)
+	}
+	if *renderer.rendered.TopK.Static != 10 {
+		t.Errorf("expected TopK 10, got %d", *renderer.rendered.TopK.Static)
+	}
+}
+
+func TestPostFilter_OrIsEvaluatedWhole(t *testing.T) {
+	renderer := newStubRenderer(types.EQ)
+	name := types.MetadataField{Name: "name"}
+
+	result, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(5).
+		Filter(Or(
+			Eq(name, types.Param{Name: "exact"}),
+			StartsWith(name, types.Param{Name: "prefix"}),
+		)).
+		PostFilter(2).
+		Render(renderer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if renderer.rendered.FilterClause != nil {
+		t.Errorf("expected no pushed-down filter, got %#v", renderer.rendered.FilterClause)
+	}
+	if _, ok := result.PostFilter.(types.FilterGroup); !ok {
+		t.Errorf("expected OR group residual, got %#v", result.PostFilter)
+	}
+}
+
+func TestPostFilter_CapsAtMaxTopK(t *testing.T) {
+	renderer := newStubRenderer()
+
+	_, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(types.MaxTopK).
+		Filter(Matches(types.MetadataField{Name: "name"}, types.Param{Name: "p"})).
+		PostFilter(10).
+		Render(renderer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *renderer.rendered.TopK.Static != types.MaxTopK {
+		t.Errorf("expected TopK capped at %d, got %d", types.MaxTopK, *renderer.rendered.TopK.Static)
+	}
+}
+
+func TestPostFilter_AddsResidualFieldsToSelection(t *testing.T) {
+	renderer := newStubRenderer()
+	category := types.MetadataField{Name: "category"}
+	name := types.MetadataField{Name: "name"}
+
+	_, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		SelectMetadata(category).
+		IncludeMetadata(false).
+		Filter(Matches(name, types.Param{Name: "p"})).
+		PostFilter(2).
+		Render(renderer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !renderer.rendered.IncludeMetadata {
+		t.Error("expected IncludeMetadata to be forced on")
+	}
+	if len(renderer.rendered.MetadataFields) != 2 || renderer.rendered.MetadataFields[1] != name {
+		t.Errorf("expected name appended to selection, got %v", renderer.rendered.MetadataFields)
+	}
+}
+
+func TestPostFilter_Errors(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+	pattern := Matches(types.MetadataField{Name: "name"}, types.Param{Name: "p"})
+
+	_, err := Search(coll).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopKParam(types.Param{Name: "k"}).
+		Filter(pattern).
+		PostFilter(2).
+		Render(newStubRenderer())
+	if err == nil {
+		t.Error("expected error for parameterized TopK")
+	}
+
+	_, err = Delete(coll).PostFilter(2).Build()
+	if err == nil {
+		t.Error("expected error for PostFilter() on Delete")
+	}
+
+	_, err = Search(coll).PostFilter(-1).Build()
+	if err == nil {
+		t.Error("expected error for negative multiplier")
+	}
+}
+
+func TestApplyPostFilter(t *testing.T) {
+	result := &types.QueryResult{
+		PostFilter:      Matches(types.MetadataField{Name: "name"}, types.Param{Name: "p"}),
+		PostFilterLimit: 2,
+	}
+	matches := []Match{
+		{ID: "1", Metadata: map[string]interface{}{"name": "red shoe"}},
+		{ID: "2", Metadata: map[string]interface{}{"name": "blue hat"}},
+		{ID: "3", Metadata: map[string]interface{}{"name": "red hat"}},
+		{ID: "4", Metadata: map[string]interface{}{"name": "red scarf"}},
+	}
+
+	kept, err := ApplyPostFilter(result, matches, map[string]interface{}{"p": "^red"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kept) != 2 || kept[0].ID != "1" || kept[1].ID != "3" {
+		t.Errorf("expected matches [1 3], got %v", kept)
+	}
+}
+
+func TestApplyPostFilter_Transforms(t *testing.T) {
+	result := &types.QueryResult{
+		PostFilter:      Eq(types.MetadataField{Name: "color"}, types.Param{Name: "p"}),
+		PostFilterLimit: 10,
+		Transforms:      map[string][]types.BindTransform{"p": {{Op: types.TransformLower}}},
+	}
+	matches := []Match{
+		{ID: "1", Metadata: map[string]interface{}{"color": "red"}},
+		{ID: "2", Metadata: map[string]interface{}{"color": "blue"}},
+	}
+
+	params := map[string]interface{}{"p": "RED"}
+	kept, err := ApplyPostFilter(result, matches, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kept) != 1 || kept[0].ID != "1" {
+		t.Errorf("expected match 1, got %v", kept)
+	}
+	if params["p"] != "RED" {
+		t.Errorf("expected caller params to be unchanged, got %v", params["p"])
+	}
+}
+
+func TestApplyPostFilter_NoResidual(t *testing.T) {
+	matches := []Match{{ID: "1"}, {ID: "2"}}
+	kept, err := ApplyPostFilter(&types.QueryResult{}, matches, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kept) != 2 {
+		t.Errorf("expected matches unchanged, got %v", kept)
+	}
+}
+
+func TestApplyPostFilter_MissingParam(t *testing.T) {
+	result := &types.QueryResult{
+		PostFilter:      Matches(types.MetadataField{Name: "name"}, types.Param{Name: "p"}),
+		PostFilterLimit: 1,
+	}
+	_, err := ApplyPostFilter(result, []Match{{ID: "1", Metadata: map[string]interface{}{"name": "x"}}}, nil)
+	if err == nil {
+		t.Fatal("expected error for missing parameter")
+	}
+}
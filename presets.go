@@ -0,0 +1,150 @@
+package vectql
+
+import (
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// TryDefineFilter registers a named filter preset so a common predicate is
+// defined once and reused across queries. Every field in the filter is
+// validated against the schema. Presets cannot be redefined.
+func (v *VECTQL) TryDefineFilter(name string, filter types.FilterItem) error {
+	if !isValidIdentifier(name) {
+		return fmt.Errorf("invalid filter preset name: %s", name)
+	}
+	if filter == nil {
+		return fmt.Errorf("filter preset '%s' cannot be nil", name)
+	}
+	if err := v.validateFilter(filter); err != nil {
+		return fmt.Errorf("filter preset '%s': %w", name, err)
+	}
+
+	v.presetsMu.Lock()
+	defer v.presetsMu.Unlock()
+	if _, ok := v.presets[name]; ok {
+		return fmt.Errorf("filter preset '%s' is already defined", name)
+	}
+	if v.presets == nil {
+		v.presets = make(map[string]types.FilterItem)
+	}
+	v.presets[name] = filter
+	return nil
+}
+
+// DefineFilter registers a named filter preset (panics on error).
+func (v *VECTQL) DefineFilter(name string, filter types.FilterItem) {
+	if err := v.TryDefineFilter(name, filter); err != nil {
+		panic(err)
+	}
+}
+
+// TryUseFilter returns the filter preset registered under name.
+func (v *VECTQL) TryUseFilter(name string) (types.FilterItem, error) {
+	v.presetsMu.RLock()
+	defer v.presetsMu.RUnlock()
+	filter, ok := v.presets[name]
+	if !ok {
+		return nil, fmt.Errorf("filter preset '%s' is not defined", name)
+	}
+	return filter, nil
+}
+
+// UseFilter returns the filter preset registered under name (panics on
+// error). The result composes like any other filter:
+//
+//	v.And(v.UseFilter("in_stock"), v.Lt(v.M("products", "price"), v.P("max_price")))
+func (v *VECTQL) UseFilter(name string) types.FilterItem {
+	filter, err := v.TryUseFilter(name)
+	if err != nil {
+		panic(err)
+	}
+	return filter
+}
+
+// FilterPresets returns the names of all defined filter presets.
+func (v *VECTQL) FilterPresets() []string {
+	v.presetsMu.RLock()
+	defer v.presetsMu.RUnlock()
+	names := make([]string, 0, len(v.presets))
+	for name := range v.presets {
+		names = append(names, name)
+	}
+	return names
+}
+
+// validateFilter checks that every field and parameter in a filter tree is
+// valid for the schema.
+func (v *VECTQL) validateFilter(item types.FilterItem) error {
+	switch f := item.(type) {
+	case types.FilterCondition:
+		if err := v.validateField(f.Field); err != nil {
+			return err
+		}
+		if f.Operator == types.Exists || f.Operator == types.NotExists {
+			return nil
+		}
+		return validateParam(f.Value)
+	case types.FilterGroup:
+		if len(f.Conditions) == 0 {
+			return fmt.Errorf("%s requires at least one condition", f.Logic)
+		}
+		for _, c := range f.Conditions {
+			if err := v.validateFilter(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	case types.RangeFilter:
+		if err := v.validateField(f.Field); err != nil {
+			return err
+		}
+		if f.Min == nil && f.Max == nil {
+			return fmt.Errorf("range requires at least min or max")
+		}
+		for _, p := range []*types.Param{f.Min, f.Max} {
+			if p != nil {
+				if err := validateParam(*p); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case types.GeoFilter:
+		if err := v.validateField(f.Field); err != nil {
+			return err
+		}
+		for _, p := range []types.Param{f.Center.Lat, f.Center.Lon, f.Radius} {
+			if err := validateParam(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	case types.ExtensionFilter:
+		for _, p := range f.Params {
+			if err := validateParam(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported filter type: %T", item)
+	}
+}
+
+func (v *VECTQL) validateField(field types.MetadataField) error {
+	if field.Collection == "" {
+		return fmt.Errorf("metadata field has no collection context")
+	}
+	if _, ok := v.metadata[field.Collection][field.Name]; !ok {
+		return fmt.Errorf("metadata field '%s' not found in collection '%s'", field.Name, field.Collection)
+	}
+	return nil
+}
+
+func validateParam(p types.Param) error {
+	if !isValidIdentifier(p.Name) {
+		return fmt.Errorf("invalid parameter name: %s", p.Name)
+	}
+	return nil
+}
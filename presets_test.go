@@ -0,0 +1,98 @@
+package vectql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestDefineFilter(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	v.DefineFilter("electronics", v.Eq(v.M("products", "category"), v.P("category")))
+
+	result, err := Search(v.C("products")).
+		Vector(Vec(v.P("query_vec"))).
+		TopK(10).
+		Filter(v.And(
+			v.UseFilter("electronics"),
+			v.Lt(v.M("products", "price"), v.P("max_price")),
+		)).
+		Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.JSON, `"key":"category"`) {
+		t.Errorf("expected preset condition in JSON: %s", result.JSON)
+	}
+	if names := v.FilterPresets(); len(names) != 1 || names[0] != "electronics" {
+		t.Errorf("expected [electronics], got %v", names)
+	}
+}
+
+func TestTryDefineFilter_Errors(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	valid := v.Eq(v.M("products", "category"), v.P("category"))
+
+	tests := []struct {
+		name   string
+		preset string
+		filter types.FilterItem
+		want   string
+	}{
+		{"invalid name", "in stock", valid, "invalid filter preset name"},
+		{"nil filter", "empty", nil, "cannot be nil"},
+		{"unknown field", "unknown", types.FilterCondition{
+			Field:    types.MetadataField{Name: "stock", Collection: "products"},
+			Operator: types.GT,
+			Value:    types.Param{Name: "min_stock"},
+		}, "metadata field 'stock' not found"},
+		{"invalid param", "bad_param", types.FilterCondition{
+			Field:    types.MetadataField{Name: "category", Collection: "products"},
+			Operator: types.EQ,
+			Value:    types.Param{Name: "x; drop"},
+		}, "invalid parameter name"},
+		{"empty group", "empty_group", types.FilterGroup{Logic: types.AND}, "requires at least one condition"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.TryDefineFilter(tt.preset, tt.filter)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if err := v.TryDefineFilter("electronics", valid); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := v.TryDefineFilter("electronics", valid); err == nil || !strings.Contains(err.Error(), "already defined") {
+		t.Errorf("expected redefinition error, got %v", err)
+	}
+}
+
+func TestUseFilter_Undefined(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := v.TryUseFilter("missing"); err == nil {
+		t.Error("expected error for undefined preset")
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for undefined preset")
+		}
+	}()
+	v.UseFilter("missing")
+}
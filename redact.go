@@ -0,0 +1,92 @@
+package vectql
+
+// Claims describes what the caller decoding results is permitted to see.
+type Claims interface {
+	// HasScope reports whether the caller holds scope.
+	HasScope(scope string) bool
+}
+
+// Scopes is a Claims backed by a set of scope names.
+type Scopes map[string]bool
+
+// HasScope reports whether the scope is in the set.
+func (s Scopes) HasScope(scope string) bool {
+	return s[scope]
+}
+
+// DefaultRedactionMask replaces masked values when no mask is configured.
+const DefaultRedactionMask = "[REDACTED]"
+
+type redactionRule struct {
+	field string
+	scope string
+	mask  interface{}
+	strip bool
+}
+
+// RedactionPolicy strips or masks metadata fields in results unless the
+// caller holds the scope each field requires. It lets one collection serve
+// callers with different permission levels.
+type RedactionPolicy struct {
+	rules map[string][]redactionRule
+}
+
+// NewRedactionPolicy creates an empty policy.
+func NewRedactionPolicy() *RedactionPolicy {
+	return &RedactionPolicy{rules: make(map[string][]redactionRule)}
+}
+
+// Strip removes the field from results of collection unless the caller holds
+// scope.
+func (p *RedactionPolicy) Strip(collection, field, scope string) *RedactionPolicy {
+	p.rules[collection] = append(p.rules[collection], redactionRule{field: field, scope: scope, strip: true})
+	return p
+}
+
+// Mask replaces the field's value in results of collection with mask unless
+// the caller holds scope. A nil mask uses DefaultRedactionMask.
+func (p *RedactionPolicy) Mask(collection, field, scope string, mask interface{}) *RedactionPolicy {
+	if mask == nil {
+		mask = DefaultRedactionMask
+	}
+	p.rules[collection] = append(p.rules[collection], redactionRule{field: field, scope: scope, mask: mask})
+	return p
+}
+
+// Apply redacts the metadata of matches from collection for the caller
+// described by claims. Nil claims hold no scopes. Metadata maps are replaced
+// rather than modified in place, so cached results stay intact.
+func (p *RedactionPolicy) Apply(collection string, claims Claims, matches []Match) {
+	var active []redactionRule
+	for _, rule := range p.rules[collection] {
+		if claims == nil || !claims.HasScope(rule.scope) {
+			active = append(active, rule)
+		}
+	}
+	if len(active) == 0 {
+		return
+	}
+
+	for i := range matches {
+		var redacted map[string]interface{}
+		for _, rule := range active {
+			if _, ok := matches[i].Metadata[rule.field]; !ok {
+				continue
+			}
+			if redacted == nil {
+				redacted = make(map[string]interface{}, len(matches[i].Metadata))
+				for k, v := range matches[i].Metadata {
+					redacted[k] = v
+				}
+			}
+			if rule.strip {
+				delete(redacted, rule.field)
+			} else {
+				redacted[rule.field] = rule.mask
+			}
+		}
+		if redacted != nil {
+			matches[i].Metadata = redacted
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package vectql
+
+import "testing"
+
+func TestRedactionPolicy(t *testing.T) {
+	policy := NewRedactionPolicy().
+		Strip("users", "ssn", "pii:read").
+		Mask("users", "email", "contact:read", nil).
+		Mask("users", "salary", "hr:read", 0)
+
+	original := map[string]interface{}{"name": "Ada", "ssn": "123", "email": "ada@example.com", "salary": 100}
+
+	tests := []struct {
+		name     string
+		claims   Claims
+		expected map[string]interface{}
+	}{
+		{"no claims", nil, map[string]interface{}{"name": "Ada", "email": DefaultRedactionMask, "salary": 0}},
+		{"partial scopes", Scopes{"pii:read": true}, map[string]interface{}{"name": "Ada", "ssn": "123", "email": DefaultRedactionMask, "salary": 0}},
+		{"all scopes", Scopes{"pii:read": true, "contact:read": true, "hr:read": true}, original},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := []Match{{ID: "u1", Metadata: original}}
+			policy.Apply("users", tt.claims, matches)
+
+			got := matches[0].Metadata
+			if len(got) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, got)
+			}
+			for k, v := range tt.expected {
+				if got[k] != v {
+					t.Errorf("field %s: expected %v, got %v", k, v, got[k])
+				}
+			}
+		})
+	}
+
+	if original["ssn"] != "123" || original["email"] != "ada@example.com" {
+		t.Error("expected Apply not to modify the original metadata")
+	}
+}
+
+func TestRedactionPolicy_OtherCollection(t *testing.T) {
+	policy := NewRedactionPolicy().Strip("users", "ssn", "pii:read")
+	matches := []Match{{ID: "p1", Metadata: map[string]interface{}{"ssn": "n/a"}}}
+
+	policy.Apply("products", nil, matches)
+	if matches[0].Metadata["ssn"] != "n/a" {
+		t.Error("expected rules to apply only to their collection")
+	}
+}
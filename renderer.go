@@ -1,6 +1,12 @@
 package vectql
 
-import "github.com/zoobzio/vectql/internal/types"
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
 
 // Renderer defines the interface for provider-specific query rendering.
 type Renderer interface {
@@ -16,3 +22,121 @@ type Renderer interface {
 	// SupportsMetric indicates if the provider supports a distance metric.
 	SupportsMetric(metric types.DistanceMetric) bool
 }
+
+// ErrNoEndpoint is returned by Endpoint when a renderer does not describe
+// the provider API call for a query.
+var ErrNoEndpoint = types.ErrNoEndpoint
+
+// RendererV2 is the stable plugin interface for provider renderers. It
+// replaces per-feature probes with a single Capabilities description and
+// adds the transport details executors need. Renderers shipped as external
+// modules should implement RendererV2; UpgradeRenderer adapts v1 renderers.
+type RendererV2 interface {
+	// Capabilities describes the provider and the features it supports.
+	Capabilities() Capabilities
+
+	// RenderTo writes the provider-specific query body for ast to w and
+	// returns the parameter names it requires.
+	RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error)
+
+	// Endpoint describes the provider API call for ast.
+	Endpoint(ast *types.VectorAST) (Endpoint, error)
+
+	// Format identifies the wire format written by RenderTo.
+	Format() Format
+}
+
+// UpgradeRenderer adapts a v1 Renderer to RendererV2. Capabilities are
+// probed through the Supports methods and Endpoint returns ErrNoEndpoint.
+// Renderers that already implement RendererV2 are returned unchanged.
+func UpgradeRenderer(r Renderer) RendererV2 {
+	if v2, ok := r.(RendererV2); ok {
+		return v2
+	}
+	return &v1Adapter{Renderer: r}
+}
+
+// v1Adapter implements RendererV2 on top of a v1 Renderer. The embedded
+// Renderer keeps the adapter usable wherever a v1 Renderer is expected.
+type v1Adapter struct {
+	Renderer
+}
+
+func (a *v1Adapter) Capabilities() Capabilities {
+	return types.ProbeCapabilities("", a.Renderer)
+}
+
+func (a *v1Adapter) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	result, err := a.Render(ast)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(w, result.JSON); err != nil {
+		return nil, fmt.Errorf("failed to write query: %w", err)
+	}
+	return result.RequiredParams, nil
+}
+
+func (a *v1Adapter) Endpoint(_ *types.VectorAST) (Endpoint, error) {
+	return Endpoint{}, ErrNoEndpoint
+}
+
+func (a *v1Adapter) Format() Format {
+	return types.FormatJSON
+}
+
+// DowngradeRenderer adapts a RendererV2 to the v1 Renderer interface so it
+// can be passed to Builder.Render. Renderers that already implement Renderer
+// are returned unchanged.
+func DowngradeRenderer(r RendererV2) Renderer {
+	if v1, ok := r.(Renderer); ok {
+		return v1
+	}
+	return &v2Adapter{r: r, caps: r.Capabilities()}
+}
+
+// v2Adapter implements Renderer on top of a RendererV2.
+type v2Adapter struct {
+	r    RendererV2
+	caps Capabilities
+}
+
+func (a *v2Adapter) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	if f := a.r.Format(); f != types.FormatJSON {
+		return nil, fmt.Errorf("renderer format %s cannot be represented as JSON", f)
+	}
+	var buf bytes.Buffer
+	params, err := a.r.RenderTo(&buf, ast)
+	if err != nil {
+		return nil, err
+	}
+	return &types.QueryResult{JSON: buf.String(), RequiredParams: params}, nil
+}
+
+func (a *v2Adapter) SupportsOperation(op types.Operation) bool {
+	return a.caps.SupportsOperation(op)
+}
+
+func (a *v2Adapter) SupportsFilter(op types.FilterOperator) bool {
+	return a.caps.SupportsFilter(op)
+}
+
+func (a *v2Adapter) SupportsMetric(metric types.DistanceMetric) bool {
+	return a.caps.SupportsMetric(metric)
+}
+
+func (a *v2Adapter) SupportsModality(m types.Modality) bool {
+	return a.caps.SupportsModality(m)
+}
+
+func (a *v2Adapter) SupportsVectorEncoding(e types.VectorEncoding) bool {
+	return a.caps.SupportsVectorEncoding(e)
+}
+
+func (a *v2Adapter) SupportsSnapshots() bool {
+	return a.caps.Snapshots
+}
+
+func (a *v2Adapter) SupportsContentEncoding(encoding string) bool {
+	return a.caps.SupportsContentEncoding(encoding)
+}
@@ -0,0 +1,87 @@
+package vectql
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// v2Stub is a RendererV2 that does not implement the v1 interface.
+type v2Stub struct{}
+
+func (v2Stub) Capabilities() Capabilities {
+	return Capabilities{
+		Provider:   "stub",
+		Operations: []types.Operation{types.OpSearch},
+		Filters:    []types.FilterOperator{types.EQ},
+	}
+}
+
+func (v2Stub) RenderTo(w io.Writer, _ *types.VectorAST) ([]string, error) {
+	_, err := io.WriteString(w, `{"stub":true}`)
+	return []string{"v"}, err
+}
+
+func (v2Stub) Endpoint(_ *types.VectorAST) (Endpoint, error) {
+	return Endpoint{Method: "POST", Path: "/search"}, nil
+}
+
+func (v2Stub) Format() Format { return FormatJSON }
+
+func TestUpgradeRenderer(t *testing.T) {
+	r := UpgradeRenderer(newStubRenderer(types.EQ, types.IN))
+
+	caps := r.Capabilities()
+	if len(caps.Operations) != len(types.Operations) {
+		t.Errorf("expected all operations, got %v", caps.Operations)
+	}
+	if len(caps.Filters) != 2 || !caps.SupportsFilter(types.IN) || caps.SupportsFilter(types.Matches) {
+		t.Errorf("expected probed filters [= IN], got %v", caps.Filters)
+	}
+	if r.Format() != FormatJSON {
+		t.Errorf("expected JSON format, got %s", r.Format())
+	}
+
+	var buf bytes.Buffer
+	if _, err := r.RenderTo(&buf, &types.VectorAST{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != "{}" {
+		t.Errorf("expected rendered body, got %q", buf.String())
+	}
+
+	if _, err := r.Endpoint(&types.VectorAST{}); !errors.Is(err, ErrNoEndpoint) {
+		t.Errorf("expected ErrNoEndpoint, got %v", err)
+	}
+	if _, ok := r.(Renderer); !ok {
+		t.Error("expected upgraded renderer to remain a v1 Renderer")
+	}
+}
+
+func TestDowngradeRenderer(t *testing.T) {
+	r := DowngradeRenderer(v2Stub{})
+
+	if !r.SupportsOperation(types.OpSearch) || r.SupportsOperation(types.OpDelete) {
+		t.Error("expected operation support from capabilities")
+	}
+	if !r.SupportsFilter(types.EQ) || r.SupportsFilter(types.NE) {
+		t.Error("expected filter support from capabilities")
+	}
+
+	result, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		Render(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.JSON != `{"stub":true}` {
+		t.Errorf("unexpected JSON: %s", result.JSON)
+	}
+	if len(result.RequiredParams) != 1 || result.RequiredParams[0] != "v" {
+		t.Errorf("expected [v], got %v", result.RequiredParams)
+	}
+}
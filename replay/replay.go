@@ -0,0 +1,324 @@
+// Package replay records the requests a service sends to a vector database
+// and serves the recorded responses back, for hermetic tests that need no
+// containers and for reproducible bug reports. Record once against a real
+// provider:
+//
+//	rec, err := replay.NewRecorder(executor, "testdata/fixtures")
+//	resp, err := query.Execute(rec, qdrant.New(), params)
+//
+// then replay the fixtures in tests:
+//
+//	player, err := replay.Load(os.DirFS("testdata/fixtures"))
+//	resp, err := query.Execute(player, qdrant.New(), params)
+//
+// A fixture records the request identity and bound body, the provider
+// response or error, and, when the query was attached to the context with
+// ContextWithQuery, the query AST and its parameter values.
+package replay
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// ErrNoFixture is returned by a Replayer for a request it has no fixture for.
+var ErrNoFixture = errors.New("no recorded fixture for request")
+
+// Fixture is one recorded request and its outcome.
+type Fixture struct {
+	Provider        string           `json:"provider"`
+	Operation       vectql.Operation `json:"operation"`
+	Collection      string           `json:"collection"`
+	Fingerprint     string           `json:"fingerprint"`
+	Method          string           `json:"method,omitempty"`
+	Path            string           `json:"path,omitempty"`
+	ContentEncoding string           `json:"content_encoding,omitempty"`
+	Body            string           `json:"body"`
+
+	// AST and Params are the query and its parameter values, recorded when
+	// the query was attached with ContextWithQuery. They document the
+	// fixture and are not used for matching.
+	AST    json.RawMessage        `json:"ast,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+
+	// Exactly one of Response and Error is set.
+	Response *Response `json:"response,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// Response is the serialized form of a vectql.Response.
+type Response struct {
+	Matches   []Match       `json:"matches"`
+	Stale     bool          `json:"stale,omitempty"`
+	Staleness time.Duration `json:"staleness,omitempty"`
+
+	// NextPage is the page token in its PageToken.String form.
+	NextPage string `json:"next_page,omitempty"`
+}
+
+// Match is the serialized form of a vectql.Match.
+type Match struct {
+	ID       string                 `json:"id"`
+	Score    float64                `json:"score"`
+	Vector   []float32              `json:"vector,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+func encodeResponse(resp *vectql.Response) *Response {
+	out := &Response{
+		Matches:   make([]Match, len(resp.Matches)),
+		Stale:     resp.Stale,
+		Staleness: resp.Staleness,
+		NextPage:  resp.NextPage.String(),
+	}
+	for i, m := range resp.Matches {
+		out.Matches[i] = Match(m)
+	}
+	return out
+}
+
+func (r *Response) decode() (*vectql.Response, error) {
+	token, err := vectql.ParsePageToken(r.NextPage)
+	if err != nil {
+		return nil, err
+	}
+	resp := &vectql.Response{
+		Matches:   make([]vectql.Match, len(r.Matches)),
+		Stale:     r.Stale,
+		Staleness: r.Staleness,
+		NextPage:  token,
+	}
+	for i, m := range r.Matches {
+		resp.Matches[i] = vectql.Match(m)
+	}
+	return resp, nil
+}
+
+// key identifies the requests a fixture answers: the same provider call with
+// the same bound body.
+func (f *Fixture) key() string {
+	h := sha256.New()
+	for _, part := range []string{f.Provider, string(f.Operation), f.Collection, f.Method, f.Path, f.ContentEncoding, f.Body} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func newFixture(req *vectql.Request) *Fixture {
+	return &Fixture{
+		Provider:        req.Provider,
+		Operation:       req.Operation,
+		Collection:      req.Collection,
+		Fingerprint:     req.Fingerprint,
+		Method:          req.Endpoint.Method,
+		Path:            req.Endpoint.Path,
+		ContentEncoding: req.ContentEncoding,
+		Body:            req.Body,
+	}
+}
+
+type queryKey struct{}
+
+type attachedQuery struct {
+	builder *vectql.Builder
+	params  map[string]interface{}
+}
+
+// ContextWithQuery returns a copy of ctx carrying the query and parameter
+// values, so a Recorder executing under it stores them in the fixture. With
+// Builder.Execute, attach the context to the builder:
+//
+//	query.WithContext(replay.ContextWithQuery(ctx, query, params))
+func ContextWithQuery(ctx context.Context, b *vectql.Builder, params map[string]interface{}) context.Context {
+	return context.WithValue(ctx, queryKey{}, attachedQuery{builder: b, params: params})
+}
+
+// Recorder is an Executor that forwards requests to the next executor and
+// writes each request and its outcome as a fixture file in a directory.
+// Files are numbered in execution order. It is safe for concurrent use.
+type Recorder struct {
+	next vectql.Executor
+	dir  string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewRecorder creates a Recorder writing fixtures to dir, which is created if
+// it does not exist.
+func NewRecorder(next vectql.Executor, dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+	return &Recorder{next: next, dir: dir}, nil
+}
+
+// Execute forwards req and records the outcome. A fixture that cannot be
+// written fails the call, so a recording session never silently loses
+// interactions.
+func (r *Recorder) Execute(ctx context.Context, req *vectql.Request) (*vectql.Response, error) {
+	resp, err := r.next.Execute(ctx, req)
+
+	fixture := newFixture(req)
+	if q, ok := ctx.Value(queryKey{}).(attachedQuery); ok {
+		ast, buildErr := q.builder.Build()
+		if buildErr == nil {
+			fixture.AST, buildErr = marshalAST(ast)
+		}
+		if buildErr != nil {
+			return nil, fmt.Errorf("failed to record query: %w", buildErr)
+		}
+		fixture.Params = q.params
+	}
+	if err != nil {
+		fixture.Error = err.Error()
+	} else {
+		fixture.Response = encodeResponse(resp)
+	}
+
+	if writeErr := r.write(fixture); writeErr != nil {
+		return nil, writeErr
+	}
+	return resp, err
+}
+
+func (r *Recorder) write(f *Fixture) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seq++
+	name := fmt.Sprintf("%04d-%s-%s.json", r.seq, strings.ToLower(string(f.Operation)), f.key()[:12])
+	if err := os.WriteFile(filepath.Join(r.dir, name), append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+// marshalAST encodes a query AST as JSON. Updates are keyed by field name,
+// since JSON objects cannot be keyed by field structs.
+func marshalAST(ast *types.VectorAST) (json.RawMessage, error) {
+	shallow := *ast
+	shallow.Updates = nil
+	data, err := json.Marshal(shallow)
+	if err != nil || len(ast.Updates) == 0 {
+		return data, err
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	updates := make(map[string]types.Param, len(ast.Updates))
+	for field, p := range ast.Updates {
+		updates[field.Name] = p
+	}
+	if doc["Updates"], err = json.Marshal(updates); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// Replayer is an Executor that serves recorded fixtures. Requests match a
+// fixture when their provider, operation, collection, endpoint, and bound
+// body are identical. When several fixtures match, they are served in
+// recorded order and the last is repeated, so a search recorded before and
+// after a write replays both results. It is safe for concurrent use.
+type Replayer struct {
+	mu       sync.Mutex
+	fixtures map[string][]*Fixture
+	served   map[string]int
+}
+
+// NewReplayer creates a Replayer serving fixtures in the given order.
+func NewReplayer(fixtures ...*Fixture) *Replayer {
+	r := &Replayer{
+		fixtures: make(map[string][]*Fixture),
+		served:   make(map[string]int),
+	}
+	for _, f := range fixtures {
+		key := f.key()
+		r.fixtures[key] = append(r.fixtures[key], f)
+	}
+	return r
+}
+
+// Load reads every .json fixture at the root of fsys, in file name order.
+func Load(fsys fs.FS) (*Replayer, error) {
+	names, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+
+	fixtures := make([]*Fixture, 0, len(names))
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		var f Fixture
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("%s: invalid fixture: %w", name, err)
+		}
+		if (f.Response == nil) == (f.Error == "") {
+			return nil, fmt.Errorf("%s: fixture needs exactly one of response and error", name)
+		}
+		fixtures = append(fixtures, &f)
+	}
+	return NewReplayer(fixtures...), nil
+}
+
+// Execute serves the next fixture recorded for req. Recorded errors are
+// returned as plain errors carrying the recorded message.
+func (r *Replayer) Execute(_ context.Context, req *vectql.Request) (*vectql.Response, error) {
+	key := newFixture(req).key()
+
+	r.mu.Lock()
+	fixtures := r.fixtures[key]
+	if len(fixtures) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s %s (fingerprint %s)", ErrNoFixture, req.Operation, req.Collection, req.Fingerprint)
+	}
+	i := min(r.served[key], len(fixtures)-1)
+	r.served[key]++
+	r.mu.Unlock()
+
+	f := fixtures[i]
+	if f.Error != "" {
+		return nil, errors.New(f.Error)
+	}
+	return f.Response.decode()
+}
+
+// Unused returns the fixtures that have never been served, in no particular
+// order. Tests use it to check that a replay exercised every recorded call.
+func (r *Replayer) Unused() []*Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []*Fixture
+	for key, fixtures := range r.fixtures {
+		if n := r.served[key]; n < len(fixtures) {
+			unused = append(unused, fixtures[n:]...)
+		}
+	}
+	return unused
+}
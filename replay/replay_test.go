@@ -0,0 +1,137 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+var products = types.Collection{Name: "products"}
+
+func search() *vectql.Builder {
+	return vectql.Search(products).Vector(vectql.Vec(types.Param{Name: "q"})).TopK(3)
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	calls := 0
+	live := vectql.ExecutorFunc(func(_ context.Context, req *vectql.Request) (*vectql.Response, error) {
+		calls++
+		if req.Operation == vectql.OpUpdate {
+			return nil, errors.New("provider unavailable")
+		}
+		return &vectql.Response{
+			Matches:  []vectql.Match{{ID: "a", Score: 0.9, Metadata: map[string]interface{}{"name": "lamp"}}},
+			NextPage: vectql.NewPageToken("qdrant", "cursor-1"),
+		}, nil
+	})
+	rec, err := NewRecorder(live, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	params := map[string]interface{}{"q": []float32{1, 0}}
+	ctx := ContextWithQuery(context.Background(), search(), params)
+	want, err := search().WithContext(ctx).Execute(rec, qdrant.New(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	update := vectql.Update(products).
+		IDs(types.Param{Name: "id"}).
+		Set(types.MetadataField{Name: "name", Type: "string"}, types.Param{Name: "name"})
+	updateParams := map[string]interface{}{"id": "a", "name": "desk"}
+	ctx = ContextWithQuery(context.Background(), update, updateParams)
+	if _, err := update.WithContext(ctx).Execute(rec, qdrant.New(), updateParams); err == nil {
+		t.Fatal("expected the update to fail")
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 2 {
+		t.Fatalf("expected 2 fixture files, got %v", files)
+	}
+	var recorded Fixture
+	data, _ := os.ReadFile(files[1])
+	if err := json.Unmarshal(data, &recorded); err != nil {
+		t.Fatalf("invalid fixture: %v", err)
+	}
+	var ast map[string]interface{}
+	if err := json.Unmarshal(recorded.AST, &ast); err != nil {
+		t.Fatalf("invalid AST: %v", err)
+	}
+	if _, ok := ast["Updates"].(map[string]interface{})["name"]; !ok {
+		t.Errorf("expected updates keyed by field name, got %v", ast["Updates"])
+	}
+	if recorded.Params["name"] != "desk" || recorded.Error != "provider unavailable" {
+		t.Errorf("unexpected fixture: %+v", recorded)
+	}
+
+	player, err := Load(os.DirFS(dir))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, err := search().Execute(player, qdrant.New(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+	if _, err := update.Execute(player, qdrant.New(), updateParams); err == nil || err.Error() != "provider unavailable" {
+		t.Errorf("expected the recorded error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected replay not to reach the provider, got %d calls", calls)
+	}
+	if unused := player.Unused(); len(unused) != 0 {
+		t.Errorf("expected every fixture to be served, got %d unused", len(unused))
+	}
+
+	_, err = search().Execute(player, qdrant.New(), map[string]interface{}{"q": []float32{0, 1}})
+	if !errors.Is(err, ErrNoFixture) {
+		t.Errorf("expected ErrNoFixture for different params, got %v", err)
+	}
+}
+
+func TestReplayer_Sequence(t *testing.T) {
+	req, err := vectql.Prepare(search(), qdrant.New(), map[string]interface{}{"q": []float32{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first := newFixture(req)
+	first.Response = &Response{Matches: []Match{{ID: "a"}}}
+	second := newFixture(req)
+	second.Response = &Response{Matches: []Match{{ID: "b"}}}
+	player := NewReplayer(first, second)
+
+	if len(player.Unused()) != 2 {
+		t.Fatalf("expected 2 unused fixtures")
+	}
+	for _, want := range []string{"a", "b", "b"} {
+		resp, err := player.Execute(context.Background(), req)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.Matches[0].ID != want {
+			t.Errorf("expected match %s, got %s", want, resp.Matches[0].ID)
+		}
+	}
+}
+
+func TestLoad_InvalidFixture(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "0001.json"), []byte(`{"provider":"qdrant","body":"{}"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(os.DirFS(dir)); err == nil {
+		t.Error("expected an error for a fixture without an outcome")
+	}
+}
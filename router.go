@@ -0,0 +1,311 @@
+package vectql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Access classifies operations for routing.
+type Access string
+
+// Access classes. AccessAny matches every operation.
+const (
+	AccessAny   Access = ""
+	AccessRead  Access = "read"
+	AccessWrite Access = "write"
+)
+
+// AccessOf returns the access class of an operation: SEARCH and FETCH read,
+// everything else writes.
+func AccessOf(op types.Operation) Access {
+	switch op {
+	case types.OpSearch, types.OpFetch:
+		return AccessRead
+	default:
+		return AccessWrite
+	}
+}
+
+// Route sends matching queries to a renderer and their requests to an
+// executor. Empty criteria match anything.
+type Route struct {
+	// Collections limits the route to the named collections.
+	Collections []string
+
+	// Access limits the route to reads or writes.
+	Access Access
+
+	// Tier limits the route to queries rendered for a tenant tier.
+	Tier string
+
+	// Renderer renders matching queries. Nil uses the router's fallback.
+	Renderer Renderer
+
+	// Executor sends the requests of matching queries. Nil uses the
+	// router's default executor; see Router.WithExecutor.
+	Executor Executor
+}
+
+func (r Route) matches(collection string, op types.Operation, tier string) bool {
+	if len(r.Collections) > 0 && !slices.Contains(r.Collections, collection) {
+		return false
+	}
+	if r.Access != AccessAny && r.Access != AccessOf(op) {
+		return false
+	}
+	return r.Tier == "" || r.Tier == tier
+}
+
+// Router is a Renderer and an Executor that delegates each query to the
+// first matching route, falling back to a default renderer and executor. It
+// moves provider selection for hybrid deployments, such as hot data in one
+// store and archives in another, out of application code:
+//
+//	router := vectql.NewRouter(qdrant.New(),
+//	    vectql.Route{Collections: []string{"archive"}, Renderer: milvus.New(), Executor: milvusExec},
+//	    vectql.Route{Tier: "enterprise", Renderer: pinecone.New(), Executor: pineconeExec},
+//	).WithExecutor(qdrantExec).ForTier(tenant.Tier)
+//	resp, err := query.Execute(router, router, params)
+//
+// The builder resolves the route before rendering, so the selected
+// renderer's physical field names, FETCH by key and existence support, case
+// folding, provider name, and endpoint all apply. Supports methods report a
+// feature only when every route and the fallback support it, so capability
+// checks made without a query stay correct whichever renderer is chosen.
+type Router struct {
+	routes   []Route
+	fallback Renderer
+	executor Executor
+	tier     string
+}
+
+// NewRouter creates a router. Routes are tried in order; fallback renders
+// queries no route matches and may be nil, in which case they fail.
+func NewRouter(fallback Renderer, routes ...Route) *Router {
+	return &Router{routes: routes, fallback: fallback}
+}
+
+// ForTier returns a router that matches routes against the given tenant tier.
+func (r *Router) ForTier(tier string) *Router {
+	routed := *r
+	routed.tier = tier
+	return &routed
+}
+
+// WithExecutor returns a router that sends requests to exec when the matched
+// route has no executor or no route matches.
+func (r *Router) WithExecutor(exec Executor) *Router {
+	routed := *r
+	routed.executor = exec
+	return &routed
+}
+
+// route returns the first route matching a query, or false.
+func (r *Router) route(collection string, op types.Operation) (Route, bool) {
+	for _, route := range r.routes {
+		if route.matches(collection, op, r.tier) {
+			return route, true
+		}
+	}
+	return Route{}, false
+}
+
+// Select returns the renderer a query would be routed to.
+func (r *Router) Select(ast *types.VectorAST) (Renderer, error) {
+	if route, ok := r.route(ast.Target.Name, ast.Operation); ok && route.Renderer != nil {
+		return route.Renderer, nil
+	}
+	if r.fallback == nil {
+		return nil, fmt.Errorf("no route for %s on collection '%s'", ast.Operation, ast.Target.Name)
+	}
+	return r.fallback, nil
+}
+
+// SelectExecutor returns the executor a request would be sent to.
+func (r *Router) SelectExecutor(req *Request) (Executor, error) {
+	if route, ok := r.route(req.Collection, req.Operation); ok && route.Executor != nil {
+		return route.Executor, nil
+	}
+	if r.executor == nil {
+		return nil, fmt.Errorf("no executor for %s on collection '%s'", req.Operation, req.Collection)
+	}
+	return r.executor, nil
+}
+
+// Execute sends req with the selected executor.
+func (r *Router) Execute(ctx context.Context, req *Request) (*Response, error) {
+	exec, err := r.SelectExecutor(req)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Execute(ctx, req)
+}
+
+// selectRenderer resolves routers to the renderer they select for ast.
+// Other renderers are returned unchanged.
+func selectRenderer(renderer Renderer, ast *types.VectorAST) (Renderer, error) {
+	for {
+		router, ok := renderer.(*Router)
+		if !ok {
+			return renderer, nil
+		}
+		selected, err := router.Select(ast)
+		if err != nil {
+			return nil, err
+		}
+		renderer = selected
+	}
+}
+
+// Render renders the query with the selected renderer.
+func (r *Router) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	renderer, err := r.Select(ast)
+	if err != nil {
+		return nil, err
+	}
+	return renderer.Render(ast)
+}
+
+// RenderContext renders the query with the selected renderer, passing ctx to
+// it if it is context-aware.
+func (r *Router) RenderContext(ctx context.Context, ast *types.VectorAST) (*types.QueryResult, error) {
+	renderer, err := r.Select(ast)
+	if err != nil {
+		return nil, err
+	}
+	return renderContext(ctx, renderer, ast)
+}
+
+// SupportsOperation indicates if every routed renderer supports an operation.
+func (r *Router) SupportsOperation(op types.Operation) bool {
+	return r.all(func(renderer Renderer) bool { return renderer.SupportsOperation(op) })
+}
+
+// SupportsFilter indicates if every routed renderer supports a filter operator.
+func (r *Router) SupportsFilter(op types.FilterOperator) bool {
+	return r.all(func(renderer Renderer) bool { return renderer.SupportsFilter(op) })
+}
+
+// SupportsMetric indicates if every routed renderer supports a distance metric.
+func (r *Router) SupportsMetric(metric types.DistanceMetric) bool {
+	return r.all(func(renderer Renderer) bool { return renderer.SupportsMetric(metric) })
+}
+
+// SupportsSnapshots indicates if every routed renderer supports snapshot
+// reads.
+func (r *Router) SupportsSnapshots() bool {
+	return r.all(func(renderer Renderer) bool { return UpgradeRenderer(renderer).Capabilities().Snapshots })
+}
+
+// SupportsModality indicates if every routed renderer supports a search
+// input modality.
+func (r *Router) SupportsModality(m types.Modality) bool {
+	return r.all(func(renderer Renderer) bool { return UpgradeRenderer(renderer).Capabilities().SupportsModality(m) })
+}
+
+// SupportsVectorEncoding indicates if every routed renderer accepts a
+// compact vector encoding.
+func (r *Router) SupportsVectorEncoding(e types.VectorEncoding) bool {
+	return r.all(func(renderer Renderer) bool {
+		return UpgradeRenderer(renderer).Capabilities().SupportsVectorEncoding(e)
+	})
+}
+
+// SupportsContentEncoding indicates if every routed renderer's provider
+// accepts request bodies with a Content-Encoding.
+func (r *Router) SupportsContentEncoding(encoding string) bool {
+	return r.all(func(renderer Renderer) bool {
+		return UpgradeRenderer(renderer).Capabilities().SupportsContentEncoding(encoding)
+	})
+}
+
+// SupportsFetchBy indicates if every routed renderer renders FETCH by key.
+func (r *Router) SupportsFetchBy() bool {
+	return r.all(func(renderer Renderer) bool {
+		fr, ok := renderer.(types.FetchByRenderer)
+		return ok && fr.SupportsFetchBy()
+	})
+}
+
+// SupportsExistsByFilter indicates if every routed renderer renders
+// existence checks by filter.
+func (r *Router) SupportsExistsByFilter() bool {
+	return r.all(func(renderer Renderer) bool {
+		er, ok := renderer.(types.ExistsRenderer)
+		return ok && er.SupportsExistsByFilter()
+	})
+}
+
+// SupportsList indicates if every routed renderer renders paginated
+// listings.
+func (r *Router) SupportsList() bool {
+	return r.all(func(renderer Renderer) bool {
+		lr, ok := renderer.(types.ListRenderer)
+		return ok && lr.SupportsList()
+	})
+}
+
+// Capabilities describes the features every routed renderer supports. The
+// provider is named only when every route renders for the same one.
+func (r *Router) Capabilities() Capabilities {
+	provider := ""
+	for i, renderer := range r.renderers() {
+		name := UpgradeRenderer(renderer).Capabilities().Provider
+		if i > 0 && name != provider {
+			provider = ""
+			break
+		}
+		provider = name
+	}
+	return types.ProbeCapabilities(provider, r)
+}
+
+// RenderTo writes the body of the selected renderer for ast to w.
+func (r *Router) RenderTo(w io.Writer, ast *types.VectorAST) ([]string, error) {
+	renderer, err := r.Select(ast)
+	if err != nil {
+		return nil, err
+	}
+	return UpgradeRenderer(renderer).RenderTo(w, ast)
+}
+
+// Endpoint describes the provider API call of the selected renderer for ast.
+func (r *Router) Endpoint(ast *types.VectorAST) (Endpoint, error) {
+	renderer, err := r.Select(ast)
+	if err != nil {
+		return Endpoint{}, err
+	}
+	return UpgradeRenderer(renderer).Endpoint(ast)
+}
+
+// Format returns FormatJSON; routed renderers render JSON query results.
+func (r *Router) Format() Format {
+	return types.FormatJSON
+}
+
+func (r *Router) all(supports func(Renderer) bool) bool {
+	for _, renderer := range r.renderers() {
+		if !supports(renderer) {
+			return false
+		}
+	}
+	return true
+}
+
+// renderers returns the renderers of every route and the fallback.
+func (r *Router) renderers() []Renderer {
+	var renderers []Renderer
+	for _, route := range r.routes {
+		if route.Renderer != nil {
+			renderers = append(renderers, route.Renderer)
+		}
+	}
+	if r.fallback != nil {
+		renderers = append(renderers, r.fallback)
+	}
+	return renderers
+}
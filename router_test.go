@@ -0,0 +1,194 @@
+package vectql
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/milvus"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+	"github.com/zoobzio/vectql/pkg/weaviate"
+)
+
+func TestRouter_Select(t *testing.T) {
+	hot := newStubRenderer(types.EQ)
+	archive := newStubRenderer(types.EQ)
+	writes := newStubRenderer(types.EQ)
+	premium := newStubRenderer(types.EQ)
+
+	router := NewRouter(hot,
+		Route{Collections: []string{"archive"}, Renderer: archive},
+		Route{Access: AccessWrite, Renderer: writes},
+		Route{Tier: "premium", Renderer: premium},
+	)
+
+	tests := []struct {
+		name     string
+		router   *Router
+		ast      *types.VectorAST
+		expected Renderer
+	}{
+		{"collection", router, &types.VectorAST{Operation: types.OpUpsert, Target: types.Collection{Name: "archive"}}, archive},
+		{"write", router, &types.VectorAST{Operation: types.OpDelete, Target: types.Collection{Name: "products"}}, writes},
+		{"tier", router.ForTier("premium"), &types.VectorAST{Operation: types.OpSearch, Target: types.Collection{Name: "products"}}, premium},
+		{"fallback", router.ForTier("free"), &types.VectorAST{Operation: types.OpFetch, Target: types.Collection{Name: "products"}}, hot},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.router.Select(tt.ast)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("routed to the wrong renderer")
+			}
+		})
+	}
+}
+
+func TestRouter_Render(t *testing.T) {
+	archive := newStubRenderer(types.EQ)
+	router := NewRouter(newStubRenderer(types.EQ), Route{Collections: []string{"archive"}, Renderer: archive})
+
+	_, err := Search(types.Collection{Name: "archive"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(5).
+		Render(router)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if archive.rendered == nil || archive.rendered.Target.Name != "archive" {
+		t.Error("expected the archive renderer to render the query")
+	}
+}
+
+func TestRouter_NoRoute(t *testing.T) {
+	router := NewRouter(nil, Route{Access: AccessRead, Renderer: newStubRenderer()})
+
+	_, err := router.Select(&types.VectorAST{Operation: types.OpUpsert, Target: types.Collection{Name: "products"}})
+	if err == nil || !strings.Contains(err.Error(), "no route for UPSERT") {
+		t.Errorf("expected no route error, got %v", err)
+	}
+}
+
+func TestRouter_SupportsEveryRoute(t *testing.T) {
+	router := NewRouter(newStubRenderer(types.EQ, types.IN), Route{Tier: "premium", Renderer: newStubRenderer(types.EQ)})
+
+	if !router.SupportsFilter(types.EQ) {
+		t.Error("expected EQ to be supported by every route")
+	}
+	if router.SupportsFilter(types.IN) {
+		t.Error("expected IN to be unsupported when one route lacks it")
+	}
+}
+
+func TestRouter_PrepareUsesSelectedRenderer(t *testing.T) {
+	v := uniqueKeyInstance(t)
+	named := qdrant.New()
+	named.FieldNames = FieldNames{Prefix: "app_"}
+	router := NewRouter(pinecone.New(),
+		Route{Collections: []string{"products"}, Access: AccessRead, Renderer: named},
+		Route{Collections: []string{"products"}, Renderer: milvus.NewRESTv2()},
+	)
+
+	search := Search(v.C("products")).
+		Vector(Vec(v.P("v"))).
+		TopK(5).
+		Filter(Eq(v.M("products", "price"), v.P("price")))
+	req, err := Prepare(search, router, map[string]interface{}{"v": []float32{1, 0}, "price": 10.0})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Provider != "qdrant" || req.Endpoint.Path != "/collections/products/points/search" {
+		t.Errorf("expected a qdrant search, got provider %q and endpoint %+v", req.Provider, req.Endpoint)
+	}
+	if !strings.Contains(req.Body, `"app_price"`) {
+		t.Errorf("expected the physical field name, got %s", req.Body)
+	}
+
+	// FETCH by key is rejected by the fallback but rendered by the route
+	fetch := Fetch(v.C("products")).FetchBy(v.M("products", "category"), v.P("k"))
+	if _, err := Prepare(fetch, router, map[string]interface{}{"k": "sku-1"}); err != nil {
+		t.Errorf("unexpected error for FETCH by key: %v", err)
+	}
+
+	// Vector encodings are checked against the selected renderer
+	upsert := Upsert(v.C("products")).AddVector(NewRecord(v.P("id"), Vec(v.P("v"))).Build())
+	params := map[string]interface{}{"id": "p1", "v": []float32{1, 0}}
+	req, err = Prepare(upsert, router, params, WithVectorEncoding(VectorEncodingFloat16Base64))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Provider != "milvus" {
+		t.Errorf("expected a milvus upsert, got %q", req.Provider)
+	}
+}
+
+func TestRouter_NearText(t *testing.T) {
+	router := NewRouter(qdrant.New(), Route{Collections: []string{"articles"}, Renderer: weaviate.New()})
+
+	query := Search(types.Collection{Name: "articles"}).
+		NearText(types.Param{Name: "concepts"}).
+		TopK(5)
+	if _, err := query.Render(router); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if router.SupportsModality(types.ModalityText) {
+		t.Error("expected text search to be unsupported when the fallback lacks it")
+	}
+}
+
+func TestRouter_Capabilities(t *testing.T) {
+	same := NewRouter(qdrant.New(), Route{Tier: "premium", Renderer: qdrant.NewQuery()})
+	if UpgradeRenderer(same) != RendererV2(same) {
+		t.Error("expected a router to implement RendererV2")
+	}
+	if got := same.Capabilities().Provider; got != "qdrant" {
+		t.Errorf("expected provider qdrant, got %q", got)
+	}
+
+	mixed := NewRouter(qdrant.New(), Route{Tier: "premium", Renderer: pinecone.New()})
+	if got := mixed.Capabilities().Provider; got != "" {
+		t.Errorf("expected no provider for mixed routes, got %q", got)
+	}
+	ast := &types.VectorAST{Operation: types.OpSearch, Target: types.Collection{Name: "products"}}
+	endpoint, err := mixed.ForTier("premium").Endpoint(ast)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if endpoint.Path != "/query" {
+		t.Errorf("expected the pinecone endpoint, got %+v", endpoint)
+	}
+}
+
+func TestRouter_Execute(t *testing.T) {
+	var sent []string
+	executor := func(name string) Executor {
+		return ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+			sent = append(sent, name)
+			return &Response{}, nil
+		})
+	}
+	router := NewRouter(qdrant.New(),
+		Route{Collections: []string{"archive"}, Executor: executor("archive")},
+		Route{Tier: "premium", Renderer: pinecone.New()},
+	)
+
+	ctx := context.Background()
+	archive := &Request{Operation: types.OpSearch, Collection: "archive"}
+	if _, err := router.Execute(ctx, archive); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	products := &Request{Operation: types.OpSearch, Collection: "products"}
+	if _, err := router.Execute(ctx, products); err == nil || !strings.Contains(err.Error(), "no executor for SEARCH") {
+		t.Errorf("expected no executor error, got %v", err)
+	}
+	if _, err := router.WithExecutor(executor("default")).ForTier("premium").Execute(ctx, products); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(sent, ",") != "archive,default" {
+		t.Errorf("unexpected executors: %v", sent)
+	}
+}
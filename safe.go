@@ -0,0 +1,106 @@
+package vectql
+
+import (
+	"errors"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// SafeAccessor mirrors the short accessors of a VECTQL instance without
+// panicking. Invalid names return zero values and the errors are recorded
+// and returned by Err, Build, and Render. Use one accessor per query when
+// assembling queries from user-influenced configuration; it is not safe for
+// concurrent use.
+//
+//	s := v.Safe()
+//	query := vectql.Search(s.C(cfg.Collection)).
+//	    Vector(vectql.Vec(s.P("query_vec"))).
+//	    Embedding(s.E(cfg.Collection, cfg.Embedding)).
+//	    Filter(vectql.Eq(s.M(cfg.Collection, cfg.Field), s.P("value"))).
+//	    TopK(10)
+//	result, err := s.Render(query, qdrant.New())
+//
+// Attach the accessor with Builder.WithAccessor to have every path that
+// builds the query, including Prepare and Execute, return its errors.
+//
+// Use the package-level filter constructors with a SafeAccessor; the
+// instance methods such as v.Eq panic on the zero fields it returns.
+type SafeAccessor struct {
+	v    *VECTQL
+	errs []error
+}
+
+// Safe returns a new accessor that records errors instead of panicking.
+func (v *VECTQL) Safe() *SafeAccessor {
+	return &SafeAccessor{v: v}
+}
+
+func (s *SafeAccessor) record(err error) {
+	if err != nil {
+		s.errs = append(s.errs, err)
+	}
+}
+
+// C returns a validated collection reference, or a zero value on error.
+func (s *SafeAccessor) C(name string) types.Collection {
+	c, err := s.v.TryC(name)
+	s.record(err)
+	return c
+}
+
+// E returns a validated embedding field reference, or a zero value on error.
+func (s *SafeAccessor) E(collectionName, embeddingName string) types.EmbeddingField {
+	e, err := s.v.TryE(collectionName, embeddingName)
+	s.record(err)
+	return e
+}
+
+// M returns a validated metadata field reference, or a zero value on error.
+func (s *SafeAccessor) M(collectionName, fieldName string) types.MetadataField {
+	m, err := s.v.TryM(collectionName, fieldName)
+	s.record(err)
+	return m
+}
+
+// P returns a validated parameter reference, or a zero value on error.
+func (s *SafeAccessor) P(name string) types.Param {
+	p, err := s.v.TryP(name)
+	s.record(err)
+	return p
+}
+
+// UseFilter returns a filter preset, or nil on error.
+func (s *SafeAccessor) UseFilter(name string) types.FilterItem {
+	f, err := s.v.TryUseFilter(name)
+	s.record(err)
+	return f
+}
+
+// Err returns the recorded errors joined, or nil if there are none.
+func (s *SafeAccessor) Err() error {
+	return errors.Join(s.errs...)
+}
+
+// Build returns the recorded errors if any, and otherwise builds b.
+func (s *SafeAccessor) Build(b *Builder) (*types.VectorAST, error) {
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return b.Build()
+}
+
+// Render returns the recorded errors if any, and otherwise renders b.
+func (s *SafeAccessor) Render(b *Builder, r Renderer) (*types.QueryResult, error) {
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return b.Render(r)
+}
+
+// WithAccessor makes Build return the errors recorded by s, so Render,
+// Prepare, Execute, and every other path that builds the query fails
+// instead of running a query assembled from zero values.
+func (b *Builder) WithAccessor(s *SafeAccessor) *Builder {
+	b.safe = s
+	return b
+}
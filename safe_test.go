@@ -0,0 +1,89 @@
+package vectql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestSafeAccessor(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := v.Safe()
+	query := Search(s.C("products")).
+		Vector(Vec(s.P("query_vec"))).
+		Embedding(s.E("products", "description")).
+		Filter(Eq(s.M("products", "category"), s.P("category"))).
+		TopK(10)
+	if _, err := s.Render(query, qdrant.New()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestSafeAccessor_RecordsErrors(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := v.Safe()
+	query := Search(s.C("products")).
+		Vector(Vec(s.P("query vec"))).
+		Embedding(s.E("products", "title")).
+		Filter(Eq(s.M("orders", "category"), s.P("category"))).
+		TopK(10)
+
+	_, err = s.Build(query)
+	if err == nil {
+		t.Fatal("expected errors")
+	}
+	for _, want := range []string{
+		"invalid parameter name: query vec",
+		"embedding 'title' not found",
+		"collection 'orders' not found",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected %q in %v", want, err)
+		}
+	}
+	if _, err := s.Render(query, qdrant.New()); err == nil {
+		t.Error("expected Render to return the recorded errors")
+	}
+	if v.Safe().Err() != nil {
+		t.Error("expected a new accessor to start without errors")
+	}
+}
+
+func TestBuilder_WithAccessor(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	s := v.Safe()
+	query := Search(s.C("orders")).
+		Vector(Vec(s.P("query_vec"))).
+		TopK(10).
+		WithAccessor(s)
+
+	_, err = Prepare(query, qdrant.New(), map[string]interface{}{"query_vec": []float32{1}})
+	if err == nil || !strings.Contains(err.Error(), "collection 'orders' not found") {
+		t.Errorf("expected Prepare to return the recorded error, got %v", err)
+	}
+	if _, err := query.Render(qdrant.New()); err == nil {
+		t.Error("expected Render to return the recorded error")
+	}
+
+	s = v.Safe()
+	query = Search(s.C("products")).
+		Vector(Vec(s.P("query_vec"))).
+		TopK(10).
+		WithAccessor(s)
+	if _, err := Prepare(query, qdrant.New(), map[string]interface{}{"query_vec": []float32{1}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
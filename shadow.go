@@ -0,0 +1,178 @@
+package vectql
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ShadowTarget is one side of a shadow-read comparison.
+type ShadowTarget struct {
+	Renderer Renderer
+	Executor Executor
+}
+
+// ShadowReport compares one query executed on a primary and a shadow provider.
+type ShadowReport struct {
+	Collection string
+	StartedAt  time.Time
+
+	// PrimaryProvider and ShadowProvider name the compared providers.
+	PrimaryProvider string
+	ShadowProvider  string
+
+	// K is the cutoff used for overlap: the number of primary matches.
+	K int
+
+	// Overlap is the fraction of the primary top K the shadow also returned
+	// in its top K. Two empty result sets overlap fully; an empty primary
+	// result does not overlap a shadow result with matches.
+	Overlap float64
+
+	// ScoreCorrelation is the Pearson correlation of the scores of matches
+	// both providers returned. It is nil when the correlation is undefined:
+	// with fewer than two shared matches, or when either side's scores are
+	// all equal. Providers reporting distances rather than similarities
+	// correlate negatively with those reporting similarities.
+	ScoreCorrelation *float64
+
+	// Shared and Missing list the IDs in the primary top K that the shadow
+	// did and did not return.
+	Shared  []string
+	Missing []string
+
+	PrimaryLatency time.Duration
+	ShadowLatency  time.Duration
+
+	// LatencyDelta is ShadowLatency minus PrimaryLatency.
+	LatencyDelta time.Duration
+}
+
+// CompareShadowRead executes a SEARCH on the primary and shadow providers
+// concurrently and reports how closely the shadow results match, e.g. to
+// validate a migration before switching traffic. Both requests are bound with
+// the same params and options; an error from either side fails the comparison.
+func CompareShadowRead(ctx context.Context, query *Builder, params map[string]interface{}, primary, shadow ShadowTarget, opts ...BindOption) (*ShadowReport, error) {
+	ast, err := query.Build()
+	if err != nil {
+		return nil, err
+	}
+	if ast.Operation != OpSearch {
+		return nil, fmt.Errorf("shadow reads are only available for SEARCH")
+	}
+
+	targets := [2]ShadowTarget{primary, shadow}
+	var requests [2]*Request
+	for i, target := range targets {
+		if target.Renderer == nil || target.Executor == nil {
+			return nil, fmt.Errorf("shadow target requires a renderer and an executor")
+		}
+		requests[i], err = Prepare(query, target.Renderer, params, opts...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &ShadowReport{
+		Collection:      ast.Target.Name,
+		StartedAt:       time.Now(),
+		PrimaryProvider: requests[0].Provider,
+		ShadowProvider:  requests[1].Provider,
+	}
+
+	var (
+		wg        sync.WaitGroup
+		responses [2]*Response
+		latencies [2]time.Duration
+		errs      [2]error
+	)
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := time.Now()
+			responses[i], errs[i] = targets[i].Executor.Execute(ctx, requests[i])
+			latencies[i] = time.Since(start)
+		}(i)
+	}
+	wg.Wait()
+
+	if errs[0] != nil {
+		return nil, fmt.Errorf("primary read failed: %w", errs[0])
+	}
+	if errs[1] != nil {
+		return nil, fmt.Errorf("shadow read failed: %w", errs[1])
+	}
+
+	report.PrimaryLatency = latencies[0]
+	report.ShadowLatency = latencies[1]
+	report.LatencyDelta = latencies[1] - latencies[0]
+	compareMatches(report, responses[0].Matches, responses[1].Matches)
+	return report, nil
+}
+
+// compareMatches fills the overlap and correlation fields of a report.
+func compareMatches(report *ShadowReport, primary, shadow []Match) {
+	report.K = len(primary)
+	if report.K == 0 {
+		if len(shadow) == 0 {
+			report.Overlap = 1
+		}
+		return
+	}
+	if len(shadow) > report.K {
+		shadow = shadow[:report.K]
+	}
+
+	shadowScores := make(map[string]float64, len(shadow))
+	for _, m := range shadow {
+		if _, seen := shadowScores[m.ID]; !seen {
+			shadowScores[m.ID] = m.Score
+		}
+	}
+
+	var xs, ys []float64
+	for _, m := range primary {
+		score, ok := shadowScores[m.ID]
+		if !ok {
+			report.Missing = append(report.Missing, m.ID)
+			continue
+		}
+		report.Shared = append(report.Shared, m.ID)
+		xs = append(xs, m.Score)
+		ys = append(ys, score)
+	}
+
+	report.Overlap = float64(len(report.Shared)) / float64(report.K)
+	report.ScoreCorrelation = pearson(xs, ys)
+}
+
+// pearson returns the Pearson correlation coefficient of two equal-length
+// samples, or nil when it is undefined.
+func pearson(xs, ys []float64) *float64 {
+	n := float64(len(xs))
+	if len(xs) < 2 {
+		return nil
+	}
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return nil
+	}
+	r := cov / math.Sqrt(varX*varY)
+	return &r
+}
@@ -0,0 +1,115 @@
+package vectql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func staticExecutor(matches ...Match) Executor {
+	return ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+		return &Response{Matches: matches}, nil
+	})
+}
+
+func shadowQuery() *Builder {
+	return Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(3)
+}
+
+func TestCompareShadowRead(t *testing.T) {
+	primary := ShadowTarget{Renderer: pinecone.New(), Executor: staticExecutor(
+		Match{ID: "a", Score: 0.9}, Match{ID: "b", Score: 0.8}, Match{ID: "c", Score: 0.7},
+	)}
+	shadow := ShadowTarget{Renderer: qdrant.New(), Executor: staticExecutor(
+		Match{ID: "a", Score: 0.95}, Match{ID: "b", Score: 0.85}, Match{ID: "d", Score: 0.6},
+	)}
+
+	report, err := CompareShadowRead(context.Background(), shadowQuery(), map[string]interface{}{"v": []float32{1}}, primary, shadow)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.PrimaryProvider != "pinecone" || report.ShadowProvider != "qdrant" {
+		t.Errorf("unexpected providers: %s, %s", report.PrimaryProvider, report.ShadowProvider)
+	}
+	if report.K != 3 || math.Abs(report.Overlap-2.0/3.0) > 1e-9 {
+		t.Errorf("expected overlap 2/3 at k=3, got %v at k=%d", report.Overlap, report.K)
+	}
+	if len(report.Missing) != 1 || report.Missing[0] != "c" {
+		t.Errorf("expected c to be missing, got %v", report.Missing)
+	}
+	if report.ScoreCorrelation == nil || math.Abs(*report.ScoreCorrelation-1) > 1e-9 {
+		t.Errorf("expected perfect correlation, got %v", report.ScoreCorrelation)
+	}
+	if report.LatencyDelta != report.ShadowLatency-report.PrimaryLatency {
+		t.Errorf("latency delta does not match latencies")
+	}
+}
+
+func TestCompareMatches_UndefinedCorrelation(t *testing.T) {
+	tests := []struct {
+		name            string
+		primary, shadow []Match
+	}{
+		{"one shared match", []Match{{ID: "a", Score: 0.9}}, []Match{{ID: "a", Score: 0.8}}},
+		{"constant scores",
+			[]Match{{ID: "a", Score: 0.5}, {ID: "b", Score: 0.5}},
+			[]Match{{ID: "a", Score: 0.9}, {ID: "b", Score: 0.8}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &ShadowReport{}
+			compareMatches(report, tt.primary, tt.shadow)
+			if report.ScoreCorrelation != nil {
+				t.Errorf("expected an undefined correlation, got %v", *report.ScoreCorrelation)
+			}
+			if _, err := json.Marshal(report); err != nil {
+				t.Errorf("expected the report to marshal, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCompareMatches_EmptyPrimary(t *testing.T) {
+	report := &ShadowReport{}
+	compareMatches(report, nil, []Match{{ID: "a", Score: 0.9}})
+	if report.Overlap != 0 {
+		t.Errorf("expected no overlap with an empty primary, got %v", report.Overlap)
+	}
+
+	report = &ShadowReport{}
+	compareMatches(report, nil, nil)
+	if report.Overlap != 1 {
+		t.Errorf("expected two empty results to overlap fully, got %v", report.Overlap)
+	}
+}
+
+func TestCompareShadowRead_ShadowError(t *testing.T) {
+	failing := ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+		return nil, errors.New("unavailable")
+	})
+	primary := ShadowTarget{Renderer: pinecone.New(), Executor: staticExecutor()}
+	shadow := ShadowTarget{Renderer: qdrant.New(), Executor: failing}
+
+	_, err := CompareShadowRead(context.Background(), shadowQuery(), map[string]interface{}{"v": []float32{1}}, primary, shadow)
+	if err == nil || !strings.Contains(err.Error(), "shadow read failed") {
+		t.Errorf("expected shadow read error, got %v", err)
+	}
+}
+
+func TestCompareShadowRead_RequiresSearch(t *testing.T) {
+	target := ShadowTarget{Renderer: pinecone.New(), Executor: staticExecutor()}
+	query := Delete(types.Collection{Name: "products"}).IDs(types.Param{Name: "id"})
+
+	if _, err := CompareShadowRead(context.Background(), query, nil, target, target); err == nil {
+		t.Error("expected error for non-SEARCH query")
+	}
+}
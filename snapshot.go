@@ -0,0 +1,27 @@
+package vectql
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// ErrSnapshotUnsupported is returned when a query pinned to a snapshot is
+// rendered for a provider without point-in-time reads. Reading live data
+// instead would silently break comparisons against the frozen state.
+var ErrSnapshotUnsupported = errors.New("renderer does not support snapshot reads")
+
+// checkSnapshot rejects snapshot reads for renderers that cannot honor them.
+func checkSnapshot(r Renderer, ast *types.VectorAST) error {
+	if ast.Snapshot == nil {
+		return nil
+	}
+	if caps := UpgradeRenderer(r).Capabilities(); !caps.Snapshots {
+		if caps.Provider == "" {
+			return ErrSnapshotUnsupported
+		}
+		return fmt.Errorf("%w: %s", ErrSnapshotUnsupported, caps.Provider)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package vectql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/elasticsearch"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+)
+
+func TestSnapshot(t *testing.T) {
+	coll := types.Collection{Name: "products"}
+	search := func() *Builder {
+		return Search(coll).Vector(Vec(types.Param{Name: "v"})).TopK(10)
+	}
+	pit := types.Param{Name: "pit"}
+
+	ast, err := search().Snapshot(pit, time.Minute).Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := ast.Snapshot; s == nil || s.ID.Name != "pit" || s.KeepAlive != time.Minute {
+		t.Errorf("expected snapshot, got %#v", ast.Snapshot)
+	}
+	found := false
+	for _, spec := range ast.ParamSpecs() {
+		if spec.Name == "pit" && spec.Source == SourceSnapshot && spec.Type == ParamString {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a string snapshot parameter, got %v", ast.ParamSpecs())
+	}
+
+	if _, err := Fetch(coll).IDs(types.Param{Name: "id"}).Snapshot(pit, 0).Build(); err != nil {
+		t.Errorf("unexpected error for FETCH: %v", err)
+	}
+	if _, err := search().Snapshot(pit, -time.Second).Build(); err == nil {
+		t.Error("expected error for a negative keep-alive")
+	}
+	if _, err := Delete(coll).IDs(types.Param{Name: "id"}).Snapshot(pit, 0).Build(); err == nil {
+		t.Error("expected error for a snapshot on a write")
+	}
+
+	plain, _ := search().Build()
+	if Fingerprint(plain) == Fingerprint(ast) {
+		t.Error("expected snapshots to change the fingerprint")
+	}
+}
+
+func TestSnapshot_Render(t *testing.T) {
+	query := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		Snapshot(types.Param{Name: "pit"}, time.Minute)
+
+	result, err := query.Render(elasticsearch.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.JSON, `"pit":{"id":":pit"`) {
+		t.Errorf("expected a pit clause, got %s", result.JSON)
+	}
+
+	_, err = query.Render(pinecone.New())
+	if !errors.Is(err, ErrSnapshotUnsupported) {
+		t.Fatalf("expected ErrSnapshotUnsupported, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "pinecone") {
+		t.Errorf("expected the provider in the error, got %v", err)
+	}
+
+	if _, err := query.Render(NewRouter(elasticsearch.New())); err != nil {
+		t.Errorf("unexpected error through a router: %v", err)
+	}
+	router := NewRouter(elasticsearch.New(), Route{Collections: []string{"archive"}, Renderer: pinecone.New()})
+	if _, err := query.Render(router); err != nil {
+		t.Errorf("unexpected error through a router to elasticsearch: %v", err)
+	}
+	archive := Search(types.Collection{Name: "archive"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		TopK(10).
+		Snapshot(types.Param{Name: "pit"}, time.Minute)
+	if _, err := archive.Render(router); !errors.Is(err, ErrSnapshotUnsupported) {
+		t.Errorf("expected ErrSnapshotUnsupported through a router to pinecone, got %v", err)
+	}
+}
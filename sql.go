@@ -0,0 +1,194 @@
+package vectql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// SQLRenderer is implemented by renderers for SQL-based vector stores, such
+// as pgvector or sqlite-vec. Instead of a JSON envelope they produce a
+// statement with positional placeholders in the driver's syntax and the
+// arguments for them, in placeholder order, so queries plug into
+// database/sql layers.
+type SQLRenderer interface {
+	Renderer
+
+	// RenderSQL converts a VectorAST to a statement and its arguments.
+	RenderSQL(ast *types.VectorAST) (string, []Arg, error)
+}
+
+// RenderSQL builds the AST and renders it as a SQL statement. The query
+// goes through the same checks and rewrites as Render, such as case folding
+// and physical field names.
+func (b *Builder) RenderSQL(r SQLRenderer) (string, []Arg, error) {
+	rw, err := b.rewrite(r)
+	if err != nil {
+		return "", nil, err
+	}
+	if err := checkSnapshot(r, rw.physical); err != nil {
+		return "", nil, err
+	}
+	stmt, args, err := r.RenderSQL(rw.physical)
+	if err != nil {
+		return "", nil, err
+	}
+	describeArgs(args, rw.logical, rw.derived)
+	return stmt, args, nil
+}
+
+// BindArgs resolves the arguments of a rendered statement against params,
+// returning values ready for db.QueryContext or db.ExecContext. Parameters
+// go through the same checks and transforms as Bind: classification, vector
+// model checks and normalization, bind transforms, and field transforms.
+// Vectors are written as "[x,y,...]" text, ArgBlob vectors as little-endian
+// float32 bytes, and ArgJSON values as JSON text. WithVectorEncoding does
+// not apply; the argument kind decides the encoding.
+func BindArgs(args []Arg, params map[string]interface{}, opts ...BindOption) ([]interface{}, error) {
+	var cfg bindConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	cfg.encoding = VectorEncodingJSON
+
+	result := argsResult(args)
+	if _, err := classifyParams(result, params, &cfg); err != nil {
+		return nil, err
+	}
+	bound, err := bindParams(result, deriveParams(result.Derived, params), &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		value := arg.Value
+		if arg.Param != "" {
+			v, ok := bound[arg.Param]
+			if !ok {
+				return nil, fmt.Errorf("missing parameter: %s", arg.Param)
+			}
+			value = v
+		}
+
+		switch arg.Kind {
+		case types.ArgVector:
+			text, err := vectorText(value)
+			if err != nil {
+				return nil, fmt.Errorf("argument %d: %w", i+1, err)
+			}
+			values[i] = text
+		case types.ArgBlob:
+			blob, err := vectorBlob(value)
+			if err != nil {
+				return nil, fmt.Errorf("argument %d: %w", i+1, err)
+			}
+			values[i] = blob
+		case types.ArgJSON:
+			data, err := marshalJSON(value)
+			if err != nil {
+				return nil, fmt.Errorf("argument %d: %w", i+1, err)
+			}
+			values[i] = string(data)
+		default:
+			values[i] = value
+		}
+	}
+	return values, nil
+}
+
+// describeArgs records on each argument what BindArgs needs to check and
+// transform its parameter, taken from the logical AST and the parameters
+// case folding derived.
+func describeArgs(args []types.Arg, ast *types.VectorAST, derived map[string]string) {
+	specs := make(map[string]types.ParamSpec)
+	for _, spec := range derivedSpecs(ast.ParamSpecs(), derived) {
+		specs[spec.Name] = spec
+	}
+	vectors := make(map[string]types.VectorParam)
+	for _, vp := range vectorParams(ast) {
+		vectors[vp.Param] = vp
+	}
+	fields := make(map[string][]types.FieldParam)
+	for _, fp := range fieldParams(ast) {
+		fields[fp.Param] = append(fields[fp.Param], fp)
+	}
+	for i := range args {
+		name := args[i].Param
+		if name == "" {
+			continue
+		}
+		args[i].Transforms = ast.BindTransforms[name]
+		args[i].Source = derived[name]
+		key := name
+		if args[i].Source != "" {
+			key = args[i].Source
+		}
+		if spec, ok := specs[key]; ok {
+			args[i].Spec = &spec
+		}
+		if vp, ok := vectors[name]; ok {
+			args[i].Vector = &vp
+		}
+		args[i].Fields = fields[name]
+	}
+}
+
+// argsResult collects the parameter descriptions of args into a
+// QueryResult, the input of the bind pipeline shared with Bind.
+func argsResult(args []types.Arg) *types.QueryResult {
+	result := &types.QueryResult{
+		ParamSpecs: []types.ParamSpec{},
+		Transforms: make(map[string][]types.BindTransform),
+	}
+	seen := make(map[string]bool)
+	specs := make(map[string]bool)
+	for _, arg := range args {
+		if arg.Param == "" || seen[arg.Param] {
+			continue
+		}
+		seen[arg.Param] = true
+		spec := types.ParamSpec{Name: arg.Param}
+		if arg.Spec != nil {
+			spec = *arg.Spec
+		}
+		if !specs[spec.Name] {
+			specs[spec.Name] = true
+			result.ParamSpecs = append(result.ParamSpecs, spec)
+		}
+		if arg.Source != "" {
+			if result.Derived == nil {
+				result.Derived = make(map[string]string)
+			}
+			result.Derived[arg.Param] = arg.Source
+		}
+		if arg.Vector != nil {
+			result.Vectors = append(result.Vectors, *arg.Vector)
+		}
+		if len(arg.Transforms) > 0 {
+			result.Transforms[arg.Param] = arg.Transforms
+		}
+		result.Fields = append(result.Fields, arg.Fields...)
+	}
+	return result
+}
+
+// vectorText writes a dense vector as "[x,y,...]".
+func vectorText(v interface{}) (string, error) {
+	floats, err := float32s(v, "encode")
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, f := range floats {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(f), 'g', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String(), nil
+}
@@ -0,0 +1,181 @@
+package vectql
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/sqlitevec"
+)
+
+// sqlStub renders searches as a fixed statement over the query parameters.
+type sqlStub struct {
+	*stubRenderer
+}
+
+func (sqlStub) RenderSQL(ast *types.VectorAST) (string, []Arg, error) {
+	stmt := "SELECT id FROM " + ast.Target.Name + " ORDER BY vec_distance_cosine(embedding, ?) LIMIT ?"
+	return stmt, []Arg{
+		{Param: ast.QueryVector.Param.Name, Kind: types.ArgVector},
+		{Value: *ast.TopK.Static, Kind: types.ArgScalar},
+	}, nil
+}
+
+func TestRenderSQL(t *testing.T) {
+	stmt, args, err := Search(types.Collection{Name: "docs"}).
+		Vector(Vec(types.Param{Name: "q"})).
+		TopK(5).
+		RenderSQL(sqlStub{newStubRenderer()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(stmt, "SELECT id FROM docs") {
+		t.Errorf("unexpected statement: %s", stmt)
+	}
+
+	values, err := BindArgs(args, map[string]interface{}{"q": []float32{0.5, -1, 0.25}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []interface{}{"[0.5,-1,0.25]", 5}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("expected %v, got %v", want, values)
+	}
+}
+
+func TestRenderSQL_BindTransforms(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, args, err := Search(v.C("products")).
+		Vector(Vec(v.P("q"))).
+		TopK(5).
+		Filter(v.Eq(v.M("products", "category"), v.P("cat"))).
+		Transform(v.P("cat"), TrimSpace(), Lowercase()).
+		RenderSQL(sqlitevec.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	values, err := BindArgs(args, map[string]interface{}{"q": []float32{1, 0}, "cat": "  Shoes "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found := false
+	for _, value := range values {
+		if value == "shoes" {
+			found = true
+		}
+		if value == "  Shoes " {
+			t.Errorf("expected the category to be transformed, got %v", values)
+		}
+	}
+	if !found {
+		t.Errorf("expected the transformed category among %v", values)
+	}
+}
+
+func TestBindArgs_Options(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	record := NewRecord(v.P("id"), Vec(v.P("vec"))).
+		WithMetadata(v.M("products", "category"), v.P("cat")).
+		Build()
+	_, args, err := Upsert(v.C("products")).AddVector(record).RenderSQL(sqlitevec.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	params := map[string]interface{}{"id": "a", "vec": []float32{3, 4}, "cat": "shoes"}
+
+	tokens := NewFieldTransforms().Register("products", "category", NewHMACTokenizer([]byte("secret")))
+	values, err := BindArgs(args, params, WithFieldTransforms(tokens), WithNormalization())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, value := range values {
+		if value == "shoes" {
+			t.Errorf("expected the category to be tokenized, got %v", values)
+		}
+	}
+	if !containsValue(values, "[0.6,0.8]") {
+		t.Errorf("expected a normalized vector, got %v", values)
+	}
+
+	reject := WithModelCheck(func(types.VectorParam, interface{}) error {
+		return errors.New("stale vector")
+	})
+	if _, err := BindArgs(args, params, reject); err == nil || !strings.Contains(err.Error(), "stale vector") {
+		t.Errorf("expected the model check to run, got %v", err)
+	}
+
+	classes := WithParamClassifier(ClassifyByName(map[string]ParamClass{"id": ClassUserText}), DefaultClassPolicy)
+	if _, err := BindArgs(args, params, classes); !errors.Is(err, ErrForbiddenParamClass) {
+		t.Errorf("expected the class policy to apply, got %v", err)
+	}
+}
+
+func containsValue(values []interface{}, want interface{}) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRenderSQL_Rewrites(t *testing.T) {
+	v := caseInsensitiveInstance(t)
+	query := Search(v.C("products")).
+		Vector(Vec(v.P("q"))).
+		TopK(5).
+		Filter(v.Eq(v.M("products", "category"), v.P("cat")))
+
+	stmt, _, err := query.RenderSQL(sqlitevec.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(stmt, "COLLATE NOCASE") {
+		t.Errorf("expected a case-insensitive comparison, got %s", stmt)
+	}
+	result, err := query.Render(sqlitevec.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(result.JSON, "COLLATE NOCASE") {
+		t.Errorf("expected Render and RenderSQL to agree, got %s", result.JSON)
+	}
+}
+
+func TestBindArgs(t *testing.T) {
+	args := []Arg{
+		{Param: "vec", Kind: types.ArgVector},
+		{Param: "meta", Kind: types.ArgJSON},
+		{Param: "name"},
+	}
+	params := map[string]interface{}{
+		"vec":  ModelVector{Model: "m", Values: []float32{1, 2}},
+		"meta": map[string]interface{}{"tag": "<a>"},
+		"name": "lamp",
+	}
+
+	values, err := BindArgs(args, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []interface{}{"[1,2]", `{"tag":"<a>"}`, "lamp"}
+	if !reflect.DeepEqual(values, want) {
+		t.Errorf("expected %v, got %v", want, values)
+	}
+
+	if _, err := BindArgs(args[:1], map[string]interface{}{}); err == nil || err.Error() != "missing parameter: vec" {
+		t.Errorf("expected missing parameter error, got %v", err)
+	}
+	if _, err := BindArgs(args[:1], map[string]interface{}{"vec": "nope"}); err == nil {
+		t.Error("expected an error for a non-vector value")
+	}
+}
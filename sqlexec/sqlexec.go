@@ -0,0 +1,302 @@
+// Package sqlexec runs queries rendered by a vectql.SQLRenderer through
+// database/sql and scans the rows into vectql matches, so SQL-backed
+// deployments use the same Response model as API-based providers:
+//
+//	resp, err := sqlexec.Execute(ctx, db, query, renderer, params)
+//	for _, m := range resp.Matches {
+//	    fmt.Println(m.ID, m.Score, m.Metadata["title"])
+//	}
+//
+// It does not import a driver; register pgvector's, DuckDB's, or SQLite's
+// driver as usual.
+package sqlexec
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/zoobzio/vectql"
+)
+
+// Column names with a fixed meaning in result rows. Every other column
+// becomes a metadata field of the match.
+const (
+	ColumnID       = "id"
+	ColumnScore    = "score"
+	ColumnVector   = "vector"
+	ColumnMetadata = "metadata"
+)
+
+// Querier is implemented by *sql.DB, *sql.Tx, and *sql.Conn.
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Execute renders the query with r, binds params with opts as
+// vectql.BindArgs does, and runs it on q. SEARCH and FETCH rows are scanned
+// with ScanMatches, taking the vector format from the declared column type;
+// writes return an empty response.
+func Execute(ctx context.Context, q Querier, b *vectql.Builder, r vectql.SQLRenderer, params map[string]interface{}, opts ...vectql.BindOption) (*vectql.Response, error) {
+	ast, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	stmt, args, err := b.RenderSQL(r)
+	if err != nil {
+		return nil, err
+	}
+	values, err := vectql.BindArgs(args, params, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if vectql.AccessOf(ast.Operation) == vectql.AccessWrite {
+		if _, err := q.ExecContext(ctx, stmt, values...); err != nil {
+			return nil, fmt.Errorf("%s failed: %w", ast.Operation, err)
+		}
+		return &vectql.Response{}, nil
+	}
+
+	rows, err := q.QueryContext(ctx, stmt, values...)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", ast.Operation, err)
+	}
+	defer rows.Close()
+	matches, err := ScanMatches(rows, VectorDeclared)
+	if err != nil {
+		return nil, err
+	}
+	return &vectql.Response{Matches: matches}, nil
+}
+
+// VectorFormat is the encoding of a vector column.
+type VectorFormat int
+
+// Vector formats.
+const (
+	// VectorDeclared takes the format from the column's declared database
+	// type: BLOB and BYTEA columns are blobs, other declared types are
+	// text. Byte values of a column without a declared type are rejected.
+	VectorDeclared VectorFormat = iota
+
+	// VectorText is pgvector's "[x,y,...]" text form.
+	VectorText
+
+	// VectorBlob is a packed little-endian float32 blob, as stored by
+	// sqlite-vec.
+	VectorBlob
+)
+
+// blobTypes are the declared database types of binary columns.
+var blobTypes = map[string]bool{
+	"BLOB":      true,
+	"BYTEA":     true,
+	"BINARY":    true,
+	"VARBINARY": true,
+	"LONGBLOB":  true,
+}
+
+// ScanMatches reads every row into a match. The id column is required;
+// score, vector, and metadata are optional. A metadata column holds a JSON
+// object whose keys are merged into the match metadata, and vectors are
+// parsed with ParseVector in format. Pass VectorText or VectorBlob for
+// drivers that do not report declared column types. It does not close rows.
+func ScanMatches(rows *sql.Rows, format VectorFormat) ([]vectql.Match, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	hasID := false
+	for _, c := range columns {
+		if c == ColumnID {
+			hasID = true
+		}
+	}
+	if !hasID {
+		return nil, fmt.Errorf("result has no %s column", ColumnID)
+	}
+	if format == VectorDeclared {
+		if format, err = declaredFormat(rows, columns); err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []vectql.Match
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		m, err := scanMatch(columns, values, format)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+	return matches, rows.Err()
+}
+
+// declaredFormat returns the format of the vector column from its declared
+// type, or VectorDeclared if the driver does not report one.
+func declaredFormat(rows *sql.Rows, columns []string) (VectorFormat, error) {
+	for i, c := range columns {
+		if c != ColumnVector {
+			continue
+		}
+		colTypes, err := rows.ColumnTypes()
+		if err != nil {
+			return VectorDeclared, err
+		}
+		switch name := strings.ToUpper(colTypes[i].DatabaseTypeName()); {
+		case name == "":
+			return VectorDeclared, nil
+		case blobTypes[name]:
+			return VectorBlob, nil
+		default:
+			return VectorText, nil
+		}
+	}
+	return VectorDeclared, nil
+}
+
+func scanMatch(columns []string, values []interface{}, format VectorFormat) (vectql.Match, error) {
+	var m vectql.Match
+	for i, column := range columns {
+		value := values[i]
+		switch column {
+		case ColumnID:
+			m.ID = text(value)
+		case ColumnScore:
+			score, err := number(value)
+			if err != nil {
+				return m, fmt.Errorf("column %s: %w", column, err)
+			}
+			m.Score = score
+		case ColumnVector:
+			if value == nil {
+				continue
+			}
+			vec, err := ParseVector(value, format)
+			if err != nil {
+				return m, fmt.Errorf("column %s: %w", column, err)
+			}
+			m.Vector = vec
+		case ColumnMetadata:
+			if value == nil {
+				continue
+			}
+			var fields map[string]interface{}
+			if err := json.Unmarshal([]byte(text(value)), &fields); err != nil {
+				return m, fmt.Errorf("column %s: invalid JSON object: %w", column, err)
+			}
+			for k, v := range fields {
+				setMetadata(&m, k, v)
+			}
+		default:
+			if b, ok := value.([]byte); ok {
+				value = string(b)
+			}
+			setMetadata(&m, column, value)
+		}
+	}
+	return m, nil
+}
+
+func setMetadata(m *vectql.Match, key string, value interface{}) {
+	if m.Metadata == nil {
+		m.Metadata = make(map[string]interface{})
+	}
+	m.Metadata[key] = value
+}
+
+func text(v interface{}) string {
+	switch t := v.(type) {
+	case []byte:
+		return string(t)
+	case string:
+		return t
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(t)
+	}
+}
+
+func number(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case float32:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case []byte, string:
+		return strconv.ParseFloat(text(n), 64)
+	case nil:
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("cannot read %T as a number", v)
+	}
+}
+
+// ParseVector reads a vector column value. Strings are always text; bytes
+// are text or a blob as format says, since a float32 blob can begin and end
+// with the bytes of "[" and "]". VectorDeclared cannot decide for bytes.
+func ParseVector(src interface{}, format VectorFormat) ([]float32, error) {
+	switch v := src.(type) {
+	case string:
+		return parseVectorText(v)
+	case []byte:
+		switch format {
+		case VectorText:
+			return parseVectorText(string(v))
+		case VectorBlob:
+		default:
+			return nil, fmt.Errorf("cannot tell whether %d vector bytes are text or a float32 blob: the column declares no type", len(v))
+		}
+		if len(v)%4 != 0 {
+			return nil, fmt.Errorf("vector blob of %d bytes is not packed float32", len(v))
+		}
+		vec := make([]float32, len(v)/4)
+		for i := range vec {
+			vec[i] = math.Float32frombits(binary.LittleEndian.Uint32(v[4*i:]))
+		}
+		return vec, nil
+	case []float32:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("cannot read %T as a vector", src)
+	}
+}
+
+func parseVectorText(s string) ([]float32, error) {
+	inner := strings.TrimSpace(s)
+	if !strings.HasPrefix(inner, "[") || !strings.HasSuffix(inner, "]") {
+		return nil, fmt.Errorf("invalid vector text %q", s)
+	}
+	inner = inner[1 : len(inner)-1]
+	if strings.TrimSpace(inner) == "" {
+		return []float32{}, nil
+	}
+	parts := strings.Split(inner, ",")
+	vec := make([]float32, len(parts))
+	for i, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vector component %q", part)
+		}
+		vec[i] = float32(f)
+	}
+	return vec, nil
+}
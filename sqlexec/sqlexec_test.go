@@ -0,0 +1,310 @@
+package sqlexec
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// fakeDriver answers every query with its configured rows and records the
+// last statement and arguments.
+type fakeDriver struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+	stmt    string
+	args    []driver.Value
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.d, query}, nil }
+func (fakeConn) Close() error                                { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                   { return nil, errors.New("no transactions") }
+
+type fakeStmt struct {
+	d     *fakeDriver
+	query string
+}
+
+func (fakeStmt) Close() error  { return nil }
+func (fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.stmt, s.d.args = s.query, args
+	return driver.RowsAffected(1), nil
+}
+
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.stmt, s.d.args = s.query, args
+	return &fakeRows{columns: s.d.columns, types: s.d.types, rows: s.d.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	types   []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+
+// ColumnTypeDatabaseTypeName reports the configured declared types; columns
+// without one have none, as for SQLite expressions.
+func (r *fakeRows) ColumnTypeDatabaseTypeName(i int) string {
+	if i < len(r.types) {
+		return r.types[i]
+	}
+	return ""
+}
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var fake = &fakeDriver{}
+
+func init() {
+	sql.Register("sqlexec_fake", fake)
+}
+
+// renderer renders SEARCH and DELETE as fixed statements.
+type renderer struct{}
+
+func (renderer) Render(*types.VectorAST) (*types.QueryResult, error) {
+	return nil, errors.New("JSON rendering is not supported")
+}
+func (renderer) SupportsOperation(types.Operation) bool   { return true }
+func (renderer) SupportsFilter(types.FilterOperator) bool { return true }
+func (renderer) SupportsMetric(types.DistanceMetric) bool { return true }
+
+func (renderer) RenderSQL(ast *types.VectorAST) (string, []vectql.Arg, error) {
+	if ast.Operation == types.OpDelete {
+		return "DELETE FROM docs WHERE id = ?", []vectql.Arg{{Param: ast.IDs[0].Name}}, nil
+	}
+	return "SELECT id, score, vector, metadata, title FROM docs ORDER BY embedding <=> ? LIMIT ?", []vectql.Arg{
+		{Param: ast.QueryVector.Param.Name, Kind: types.ArgVector},
+		{Value: *ast.TopK.Static},
+	}, nil
+}
+
+func TestExecute(t *testing.T) {
+	db, err := sql.Open("sqlexec_fake", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	fake.columns = []string{"id", "score", "vector", "metadata", "title"}
+	fake.types = []string{"INT8", "FLOAT8", "VECTOR", "JSONB", "TEXT"}
+	fake.rows = [][]driver.Value{
+		{int64(7), 0.91, []byte("[1,0.5]"), []byte(`{"page":3}`), []byte("Intro")},
+		{"b", "0.5", nil, nil, nil},
+	}
+
+	query := vectql.Search(types.Collection{Name: "docs"}).Vector(vectql.Vec(types.Param{Name: "q"})).TopK(2)
+	resp, err := Execute(context.Background(), db, query, renderer{}, map[string]interface{}{"q": []float32{1, 0}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(fake.args, []driver.Value{"[1,0]", int64(2)}) {
+		t.Errorf("unexpected args: %#v", fake.args)
+	}
+	want := []vectql.Match{
+		{ID: "7", Score: 0.91, Vector: []float32{1, 0.5}, Metadata: map[string]interface{}{"page": float64(3), "title": "Intro"}},
+		{ID: "b", Score: 0.5, Metadata: map[string]interface{}{"title": nil}},
+	}
+	if !reflect.DeepEqual(resp.Matches, want) {
+		t.Errorf("expected %+v, got %+v", want, resp.Matches)
+	}
+
+	del := vectql.Delete(types.Collection{Name: "docs"}).IDs(types.Param{Name: "id"})
+	resp, err = Execute(context.Background(), db, del, renderer{}, map[string]interface{}{"id": "b"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fake.stmt != "DELETE FROM docs WHERE id = ?" || len(resp.Matches) != 0 {
+		t.Errorf("unexpected delete: %s %+v", fake.stmt, resp)
+	}
+}
+
+func TestScanMatches_RequiresID(t *testing.T) {
+	db, err := sql.Open("sqlexec_fake", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	fake.columns, fake.types = []string{"score"}, nil
+	fake.rows = nil
+	rows, err := db.Query("SELECT score FROM docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer rows.Close()
+	if _, err := ScanMatches(rows, VectorDeclared); err == nil {
+		t.Error("expected an error without an id column")
+	}
+}
+
+func TestParseVector(t *testing.T) {
+	tests := []struct {
+		src  interface{}
+		want []float32
+	}{
+		{"[1,2.5,-3]", []float32{1, 2.5, -3}},
+		{" [ 1, 2 ] ", []float32{1, 2}},
+		{[]byte("[]"), []float32{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseVector(tt.src, VectorText)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.src, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v: expected %v, got %v", tt.src, tt.want, got)
+		}
+	}
+
+	for _, bad := range []interface{}{"1,2", "[1,x]", 42} {
+		if _, err := ParseVector(bad, VectorText); err == nil {
+			t.Errorf("%v: expected an error", bad)
+		}
+	}
+	if _, err := ParseVector([]byte{1, 2, 3}, VectorBlob); err == nil {
+		t.Error("expected an error for a blob that is not packed float32")
+	}
+	if _, err := ParseVector([]byte("[1]"), VectorDeclared); err == nil {
+		t.Error("expected an error for bytes of an undeclared format")
+	}
+
+	// A blob whose first and last bytes are '[' and ']' is still a blob
+	blob := []byte{'[', 0, 0, 0, 0, 0, 0, ']'}
+	got, err := ParseVector(blob, VectorBlob)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []float32{
+		math.Float32frombits(binary.LittleEndian.Uint32(blob)),
+		math.Float32frombits(binary.LittleEndian.Uint32(blob[4:])),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+func TestScanMatches_DeclaredBlob(t *testing.T) {
+	db, err := sql.Open("sqlexec_fake", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	blob := make([]byte, 8)
+	binary.LittleEndian.PutUint32(blob, math.Float32bits(0.5))
+	binary.LittleEndian.PutUint32(blob[4:], math.Float32bits(-2))
+	bracketed := []byte{'[', 0, 0, 0, 0, 0, 0, ']'}
+	fake.columns = []string{"id", "vector"}
+	fake.types = []string{"TEXT", "BLOB"}
+	fake.rows = [][]driver.Value{{"a", blob}, {"b", bracketed}}
+
+	rows, err := db.Query("SELECT id, vector FROM docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	matches, err := ScanMatches(rows, VectorDeclared)
+	rows.Close()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(matches[0].Vector, []float32{0.5, -2}) || len(matches[1].Vector) != 2 {
+		t.Errorf("expected both rows to be read as blobs, got %+v", matches)
+	}
+
+	fake.types = nil
+	fake.rows = [][]driver.Value{{"a", blob}}
+	rows, err = db.Query("SELECT id, vector FROM docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = ScanMatches(rows, VectorDeclared)
+	rows.Close()
+	if err == nil {
+		t.Error("expected an error for bytes without a declared type")
+	}
+
+	fake.rows = [][]driver.Value{{"a", blob}}
+	rows, err = db.Query("SELECT id, vector FROM docs")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	matches, err = ScanMatches(rows, VectorBlob)
+	rows.Close()
+	if err != nil || !reflect.DeepEqual(matches[0].Vector, []float32{0.5, -2}) {
+		t.Errorf("expected an explicit blob format to read the vector, got %+v, %v", matches, err)
+	}
+}
+
+func TestExecute_RenderChecks(t *testing.T) {
+	db, err := sql.Open("sqlexec_fake", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	query := vectql.Search(types.Collection{Name: "docs"}).
+		Vector(vectql.Vec(types.Param{Name: "q"})).
+		TopK(2).
+		Snapshot(types.Param{Name: "snap"}, 0)
+	_, err = Execute(context.Background(), db, query, renderer{}, map[string]interface{}{"q": []float32{1, 0}, "snap": "s"})
+	if !errors.Is(err, vectql.ErrSnapshotUnsupported) {
+		t.Errorf("expected ErrSnapshotUnsupported, got %v", err)
+	}
+}
+
+func TestExecute_BindOptions(t *testing.T) {
+	db, err := sql.Open("sqlexec_fake", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+	fake.columns, fake.types, fake.rows = []string{"id"}, nil, nil
+
+	embedding := types.EmbeddingField{Name: "body", Metric: types.Cosine}
+	query := vectql.Search(types.Collection{Name: "docs"}).
+		Vector(vectql.Vec(types.Param{Name: "q"})).
+		Embedding(embedding).
+		TopK(2)
+	params := map[string]interface{}{"q": []float32{3, 4}}
+
+	if _, err := Execute(context.Background(), db, query, renderer{}, params, vectql.WithNormalization()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(fake.args) == 0 || fake.args[0] != "[0.6,0.8]" {
+		t.Errorf("expected a normalized vector, got %#v", fake.args)
+	}
+
+	reject := vectql.WithModelCheck(func(types.VectorParam, interface{}) error {
+		return errors.New("stale vector")
+	})
+	if _, err := Execute(context.Background(), db, query, renderer{}, params, reject); err == nil {
+		t.Error("expected the model check to reject the vector")
+	}
+}
@@ -0,0 +1,273 @@
+package vectql
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// BindTo is the streaming form of Bind. It writes the bound body to w as it
+// walks the rendered query, so bound values are written straight from the
+// caller's slices instead of being copied into an intermediate tree and a
+// second serialized buffer. The output matches Bind for renderers that emit
+// sorted object keys.
+func BindTo(w io.Writer, result *types.QueryResult, params map[string]interface{}, opts ...BindOption) error {
+	var cfg bindConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if _, err := classifyParams(result, params, &cfg); err != nil {
+		return err
+	}
+
+	params = deriveParams(result.Derived, params)
+	required := make(map[string]bool, len(result.RequiredParams))
+	for _, name := range result.RequiredParams {
+		if _, ok := params[name]; !ok {
+			return fmt.Errorf("missing parameter: %s", name)
+		}
+		required[name] = true
+	}
+
+	values, err := bindParams(result, params, &cfg)
+	if err != nil {
+		return err
+	}
+
+	sw := &streamWriter{w: bufio.NewWriter(w)}
+	if err := streamJSON(sw, result.JSON, values, required, result.Expressions); err != nil {
+		return err
+	}
+	if err := sw.flush(); err != nil {
+		return fmt.Errorf("failed to write query: %w", err)
+	}
+	return nil
+}
+
+// StreamTo renders the query with r and streams the bound body to w. Literal
+// vectors in the query are lifted out of the AST before rendering and written
+// directly from their slices, so very large upsert batches are never held in
+// memory as a rendered string or a JSON tree.
+func (b *Builder) StreamTo(w io.Writer, r Renderer, params map[string]interface{}, opts ...BindOption) error {
+	ast, err := b.Build()
+	if err != nil {
+		return err
+	}
+
+	lifted, values := liftLiteralVectors(ast, params)
+	result, err := b.withAST(lifted).Render(r)
+	if err != nil {
+		return err
+	}
+	return BindTo(w, result, values, opts...)
+}
+
+// liftLiteralVectors returns a copy of ast whose literal dense vectors are
+// replaced by generated parameters, and params extended with their values.
+func liftLiteralVectors(ast *types.VectorAST, params map[string]interface{}) (*types.VectorAST, map[string]interface{}) {
+	lifted := *ast
+	values := make(map[string]interface{}, len(params)+len(ast.Vectors)+1)
+	for name, value := range params {
+		values[name] = value
+	}
+
+	next := 0
+	lift := func(literal []float32) *types.Param {
+		name := fmt.Sprintf("_vector_%d", next)
+		for _, taken := values[name]; taken; _, taken = values[name] {
+			next++
+			name = fmt.Sprintf("_vector_%d", next)
+		}
+		next++
+		values[name] = literal
+		return &types.Param{Name: name}
+	}
+
+	if ast.QueryVector != nil && ast.QueryVector.Param == nil && ast.QueryVector.Literal != nil {
+		lifted.QueryVector = &types.VectorValue{Param: lift(ast.QueryVector.Literal)}
+	}
+	if len(ast.Vectors) > 0 {
+		lifted.Vectors = make([]types.VectorRecord, len(ast.Vectors))
+		for i, record := range ast.Vectors {
+			if record.Vector.Param == nil && record.Vector.Literal != nil {
+				record.Vector = types.VectorValue{Param: lift(record.Vector.Literal)}
+			}
+			lifted.Vectors[i] = record
+		}
+	}
+	return &lifted, values
+}
+
+// withAST returns a copy of b that builds ast.
+func (b *Builder) withAST(ast *types.VectorAST) *Builder {
+	chunk := *b
+	chunk.ast = ast
+	return &chunk
+}
+
+// streamWriter buffers streamed output. The first write error sticks: later
+// writes are dropped and flush returns it.
+type streamWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (s *streamWriter) writeByte(c byte) {
+	if s.err == nil {
+		s.err = s.w.WriteByte(c)
+	}
+}
+
+func (s *streamWriter) write(b []byte) {
+	if s.err == nil {
+		_, s.err = s.w.Write(b)
+	}
+}
+
+func (s *streamWriter) writeString(str string) {
+	if s.err == nil {
+		_, s.err = s.w.WriteString(str)
+	}
+}
+
+func (s *streamWriter) flush() error {
+	if s.err == nil {
+		s.err = s.w.Flush()
+	}
+	return s.err
+}
+
+// streamFrame tracks one open JSON container while streaming.
+type streamFrame struct {
+	object bool
+	tokens int
+}
+
+// streamJSON copies the rendered query to w token by token, substituting
+// placeholders like bindValue does.
+func streamJSON(w *streamWriter, query string, values map[string]interface{}, required map[string]bool, style types.ExpressionStyle) error {
+	decoder := json.NewDecoder(strings.NewReader(query))
+	decoder.UseNumber()
+
+	var stack []streamFrame
+	for {
+		tok, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to parse query: %w", err)
+		}
+
+		if delim, ok := tok.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			w.writeByte(byte(delim))
+			continue
+		}
+
+		isKey := false
+		if n := len(stack); n > 0 {
+			frame := &stack[n-1]
+			switch {
+			case frame.object && frame.tokens%2 == 0:
+				isKey = true
+				if frame.tokens > 0 {
+					w.writeByte(',')
+				}
+			case frame.object:
+				w.writeByte(':')
+			case frame.tokens > 0:
+				w.writeByte(',')
+			}
+			frame.tokens++
+		}
+
+		switch value := tok.(type) {
+		case json.Delim:
+			w.writeByte(byte(value))
+			stack = append(stack, streamFrame{object: value == '{'})
+		case string:
+			if isKey {
+				if err := writeJSON(w, value); err != nil {
+					return err
+				}
+				continue
+			}
+			if len(value) > 1 && value[0] == ':' && required[value[1:]] {
+				if err := writeJSON(w, values[value[1:]]); err != nil {
+					return err
+				}
+				continue
+			}
+			bound, err := bindExpression(value, values, required, style)
+			if err != nil {
+				return err
+			}
+			if err := writeJSON(w, bound); err != nil {
+				return err
+			}
+		case json.Number:
+			w.writeString(value.String())
+		case bool:
+			w.writeString(strconv.FormatBool(value))
+		case nil:
+			w.writeString("null")
+		}
+	}
+}
+
+// writeJSON writes v as JSON. Dense vectors are written element by element
+// without an intermediate buffer.
+func writeJSON(w *streamWriter, v interface{}) error {
+	if vec, ok := v.([]float32); ok {
+		var scratch [32]byte
+		w.writeByte('[')
+		for i, f := range vec {
+			if i > 0 {
+				w.writeByte(',')
+			}
+			b, err := appendJSONFloat32(scratch[:0], f)
+			if err != nil {
+				return err
+			}
+			w.write(b)
+		}
+		w.writeByte(']')
+		return nil
+	}
+
+	out, err := marshalJSON(v)
+	if err != nil {
+		return fmt.Errorf("failed to serialize query: %w", err)
+	}
+	w.write(out)
+	return nil
+}
+
+// appendJSONFloat32 formats f the way encoding/json does.
+func appendJSONFloat32(b []byte, f float32) ([]byte, error) {
+	f64 := float64(f)
+	if math.IsInf(f64, 0) || math.IsNaN(f64) {
+		return nil, fmt.Errorf("failed to serialize query: unsupported value: %v", f)
+	}
+	format := byte('f')
+	if abs := math.Abs(f64); abs != 0 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+		format = 'e'
+	}
+	b = strconv.AppendFloat(b, f64, format, -1, 32)
+	if format == 'e' {
+		// Shorten e-09 to e-9, as encoding/json does
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b, nil
+}
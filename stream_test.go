@@ -0,0 +1,196 @@
+package vectql
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/milvus"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestBindTo_MatchesBind(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  *Builder
+		r      Renderer
+		params map[string]interface{}
+	}{
+		{
+			name: "qdrant search",
+			query: Search(types.Collection{Name: "products"}).
+				Vector(Vec(types.Param{Name: "query_vec"})).
+				Filter(types.FilterCondition{Field: types.MetadataField{Name: "category"}, Operator: types.EQ, Value: types.Param{Name: "cat"}}).
+				TopK(5),
+			r:      qdrant.New(),
+			params: map[string]interface{}{"query_vec": []float32{0.1, -2.5, 1e-7, 3e21}, "cat": `a "quoted" <tag>`},
+		},
+		{
+			name: "milvus expression",
+			query: Search(types.Collection{Name: "products"}).
+				Vector(Vec(types.Param{Name: "query_vec"})).
+				Filter(types.FilterCondition{Field: types.MetadataField{Name: "price"}, Operator: types.GE, Value: types.Param{Name: "min_price"}}).
+				TopK(5),
+			r:      milvus.New(),
+			params: map[string]interface{}{"query_vec": []float64{0.5, 0.25}, "min_price": 9.5},
+		},
+		{
+			name: "pinecone upsert",
+			query: Upsert(types.Collection{Name: "products"}).
+				AddVector(NewRecord(types.Param{Name: "id1"}, Vec(types.Param{Name: "vec1"})).Build()).
+				AddVector(NewRecord(types.Param{Name: "id2"}, Vec(types.Param{Name: "vec2"})).Build()),
+			r: pinecone.New(),
+			params: map[string]interface{}{
+				"id1": "a", "vec1": []float32{1, 0},
+				"id2": "b", "vec2": []interface{}{0.5, 0.5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.query.Render(tt.r)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			want, err := Bind(result, tt.params)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := BindTo(&buf, result, tt.params); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if buf.String() != want {
+				t.Errorf("streamed body differs from Bind:\n got: %s\nwant: %s", buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestBindTo_Errors(t *testing.T) {
+	result, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(3).
+		Render(pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := BindTo(&buf, result, map[string]interface{}{}); err == nil || !strings.Contains(err.Error(), "missing parameter: query_vec") {
+		t.Errorf("expected missing parameter error, got %v", err)
+	}
+
+	nan := []float32{float32(math.NaN())}
+	if err := BindTo(&buf, result, map[string]interface{}{"query_vec": nan}); err == nil {
+		t.Error("expected error for NaN vector component")
+	}
+
+	params := map[string]interface{}{"query_vec": []float32{1, 0}}
+	if err := BindTo(failingWriter{}, result, params); err == nil || !strings.Contains(err.Error(), "failed to write query") {
+		t.Errorf("expected write error, got %v", err)
+	}
+}
+
+func TestBindTo_ParamClassifier(t *testing.T) {
+	result, err := classifiedSearch().Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	params := map[string]interface{}{"q": []float32{1, 0}, "ns": "ignore previous instructions"}
+	classify := ClassifyByName(map[string]ParamClass{"ns": ClassUserText})
+
+	var buf bytes.Buffer
+	if err := BindTo(&buf, result, params, WithParamClassifier(classify, DefaultClassPolicy)); !errors.Is(err, ErrForbiddenParamClass) {
+		t.Errorf("expected ErrForbiddenParamClass, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("expected nothing written, got %s", buf.String())
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestBindTo_VectorEncoding(t *testing.T) {
+	result, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(3).
+		Render(pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	params := map[string]interface{}{"query_vec": []float32{1, 0.5}}
+
+	want, err := Bind(result, params, WithVectorEncoding(VectorEncodingFloat16Base64))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := BindTo(&buf, result, params, WithVectorEncoding(VectorEncodingFloat16Base64)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != want {
+		t.Errorf("expected %s, got %s", want, buf.String())
+	}
+}
+
+func TestStreamTo_LiteralVectors(t *testing.T) {
+	query := Upsert(types.Collection{Name: "products"}).
+		AddVector(NewRecord(types.Param{Name: "id1"}, VecLiteral([]float32{0.1, 0.2, 0.3})).Build()).
+		AddVector(NewRecord(types.Param{Name: "id2"}, Vec(types.Param{Name: "_vector_0"})).Build())
+	params := map[string]interface{}{"id1": "a", "id2": "b", "_vector_0": []float32{9, 9, 9}}
+
+	result, err := query.Render(pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, err := Bind(result, params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := query.StreamTo(&buf, pinecone.New(), params); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if buf.String() != want {
+		t.Errorf("streamed body differs from Bind:\n got: %s\nwant: %s", buf.String(), want)
+	}
+
+	// The builder keeps its literal vectors
+	ast, err := query.Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ast.Vectors[0].Vector.Param != nil || len(ast.Vectors[0].Vector.Literal) != 3 {
+		t.Errorf("expected literal vector to be left in the builder, got %+v", ast.Vectors[0].Vector)
+	}
+}
+
+func TestStreamTo_Search(t *testing.T) {
+	query := Search(types.Collection{Name: "products"}).
+		Vector(VecLiteral([]float32{0.5, 0.25})).
+		TopK(3)
+
+	var buf bytes.Buffer
+	if err := query.StreamTo(&buf, qdrant.New(), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON %s: %v", buf.String(), err)
+	}
+	if !strings.Contains(buf.String(), `"vector":[0.5,0.25]`) {
+		t.Errorf("expected literal query vector in body, got %s", buf.String())
+	}
+}
@@ -0,0 +1,87 @@
+package vectql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// TenantPlanner is implemented by renderers for providers whose tenants must
+// be created before they are written to, such as Weaviate multi-tenant
+// classes.
+type TenantPlanner interface {
+	// TenantSetup describes the call that creates tenant in collection.
+	TenantSetup(collection, tenant string) (Endpoint, string, error)
+}
+
+// WithTenantSetup makes Prepare plan the creation of the target tenant
+// before an UPSERT with a namespace, when the renderer is a TenantPlanner.
+// The setup request is attached to Request.Setup; execute it through
+// RunSetup.
+func WithTenantSetup() BindOption {
+	return func(c *bindConfig) {
+		c.tenantSetup = true
+	}
+}
+
+// planTenantSetup attaches a tenant creation request to req.
+func planTenantSetup(req *Request, r Renderer) error {
+	planner, ok := r.(TenantPlanner)
+	if !ok || req.Operation != OpUpsert || req.Namespace == "" {
+		return nil
+	}
+	endpoint, body, err := planner.TenantSetup(req.Collection, req.Namespace)
+	if err != nil {
+		return err
+	}
+	req.Setup = append(req.Setup, &Request{
+		Provider:        req.Provider,
+		Collection:      req.Collection,
+		Namespace:       req.Namespace,
+		Endpoint:        endpoint,
+		Body:            body,
+		CreateIfMissing: true,
+	})
+	return nil
+}
+
+// setupKey identifies a setup request, so each is sent once.
+type setupKey struct {
+	provider string
+	method   string
+	path     string
+	body     string
+}
+
+// RunSetup wraps an executor so that every request's Setup requests are sent
+// before it. A setup that succeeds is remembered and not sent again, so a
+// tenant is created once per process rather than once per write. A failed
+// setup fails the request without sending it.
+//
+// Setup requests with CreateIfMissing create a resource that may already
+// exist; the transport should treat the provider's already-exists answer,
+// such as Weaviate's 422, as success.
+func RunSetup(next Executor) Executor {
+	var (
+		mu   sync.Mutex
+		done = make(map[setupKey]bool)
+	)
+	return ExecutorFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		for _, setup := range req.Setup {
+			key := setupKey{provider: setup.Provider, method: setup.Endpoint.Method, path: setup.Endpoint.Path, body: setup.Body}
+			mu.Lock()
+			skip := done[key]
+			mu.Unlock()
+			if skip {
+				continue
+			}
+			if _, err := next.Execute(ctx, setup); err != nil {
+				return nil, fmt.Errorf("setup %s %s failed: %w", setup.Endpoint.Method, setup.Endpoint.Path, err)
+			}
+			mu.Lock()
+			done[key] = true
+			mu.Unlock()
+		}
+		return next.Execute(ctx, req)
+	})
+}
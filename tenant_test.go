@@ -0,0 +1,97 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+	"github.com/zoobzio/vectql/pkg/weaviate"
+)
+
+func tenantUpsert() *Builder {
+	return Upsert(types.Collection{Name: "articles"}).
+		AddVector(NewRecord(types.Param{Name: "id"}, Vec(types.Param{Name: "vec"})).Build()).
+		Namespace(types.Param{Name: "tenant"})
+}
+
+func TestPrepare_TenantSetup(t *testing.T) {
+	params := map[string]interface{}{"id": "a", "vec": []float32{1, 0}, "tenant": "acme"}
+
+	req, err := Prepare(tenantUpsert(), weaviate.New(), params, WithTenantSetup())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(req.Setup) != 1 {
+		t.Fatalf("expected one setup request, got %d", len(req.Setup))
+	}
+	setup := req.Setup[0]
+	if setup.Endpoint.Method != "POST" || setup.Endpoint.Path != "/v1/schema/Articles/tenants" ||
+		setup.Body != `[{"name":"acme"}]` || !setup.CreateIfMissing {
+		t.Errorf("unexpected setup request: %+v", setup)
+	}
+
+	req, err = Prepare(tenantUpsert(), weaviate.New(), params)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(req.Setup) != 0 {
+		t.Errorf("expected no setup without WithTenantSetup, got %d", len(req.Setup))
+	}
+
+	// Renderers that are not tenant planners need no setup
+	req, err = Prepare(tenantUpsert(), qdrant.New(), params, WithTenantSetup())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(req.Setup) != 0 {
+		t.Errorf("expected no setup for qdrant, got %d", len(req.Setup))
+	}
+}
+
+func TestRunSetup(t *testing.T) {
+	var sent []string
+	failSetup := false
+	exec := RunSetup(ExecutorFunc(func(_ context.Context, req *Request) (*Response, error) {
+		if req.CreateIfMissing && failSetup {
+			return nil, errors.New("forbidden")
+		}
+		sent = append(sent, req.Endpoint.Path)
+		return &Response{}, nil
+	}))
+
+	params := map[string]interface{}{"id": "a", "vec": []float32{1, 0}, "tenant": "acme"}
+	for range 2 {
+		req, err := Prepare(tenantUpsert(), weaviate.New(), params, WithTenantSetup())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := exec.Execute(context.Background(), req); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	want := []string{"/v1/schema/Articles/tenants", "/v1/batch/objects", "/v1/batch/objects"}
+	if len(sent) != len(want) {
+		t.Fatalf("expected %v, got %v", want, sent)
+	}
+	for i := range want {
+		if sent[i] != want[i] {
+			t.Errorf("expected %v, got %v", want, sent)
+		}
+	}
+
+	failSetup = true
+	params["tenant"] = "globex"
+	req, err := Prepare(tenantUpsert(), weaviate.New(), params, WithTenantSetup())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sent = nil
+	if _, err := exec.Execute(context.Background(), req); err == nil {
+		t.Fatal("expected the failed setup to fail the request")
+	}
+	if len(sent) != 0 {
+		t.Errorf("expected the write not to be sent, got %v", sent)
+	}
+}
@@ -0,0 +1,220 @@
+package benchmarks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+const benchmarkDimensions = 1536
+
+func benchmarkVector() []float32 {
+	vec := make([]float32, benchmarkDimensions)
+	for i := range vec {
+		vec[i] = float32(i%97) / 97
+	}
+	return vec
+}
+
+func benchmarkSearch(instance *vectql.VECTQL) *vectql.Builder {
+	return vectql.Search(instance.C("products")).
+		Vector(vectql.Vec(instance.P("query_vec"))).
+		Embedding(instance.E("products", "embedding")).
+		TopK(10).
+		Filter(instance.And(
+			instance.Eq(instance.M("products", "category"), instance.P("category")),
+			instance.Gte(instance.M("products", "price"), instance.P("min_price")),
+		))
+}
+
+func benchmarkParams() map[string]interface{} {
+	return map[string]interface{}{
+		"query_vec": benchmarkVector(),
+		"category":  "shoes",
+		"min_price": 19.99,
+	}
+}
+
+// Bind Benchmarks
+
+func BenchmarkBind_Search1536(b *testing.B) {
+	instance := createBenchmarkInstance(b)
+	result, err := benchmarkSearch(instance).Render(qdrant.New())
+	if err != nil {
+		b.Fatal(err)
+	}
+	params := benchmarkParams()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := vectql.Bind(result, params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBind_Search1536Normalized(b *testing.B) {
+	instance := createBenchmarkInstance(b)
+	result, err := benchmarkSearch(instance).Render(qdrant.New())
+	if err != nil {
+		b.Fatal(err)
+	}
+	params := benchmarkParams()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := vectql.Bind(result, params, vectql.WithNormalization()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBind_Upsert100x1536(b *testing.B) {
+	instance := createBenchmarkInstance(b)
+	query := vectql.Upsert(instance.C("products"))
+	params := make(map[string]interface{}, 200)
+	for i := 0; i < 100; i++ {
+		id, vec := fmt.Sprintf("id_%d", i), fmt.Sprintf("vec_%d", i)
+		query.AddVector(vectql.NewRecord(instance.P(id), vectql.Vec(instance.P(vec))).Build())
+		params[id] = id
+		params[vec] = benchmarkVector()
+	}
+	result, err := query.Render(qdrant.New())
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := vectql.Bind(result, params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Prepared Query Benchmarks
+
+// BenchmarkPrepared_RenderEachTime renders and binds on every iteration.
+// Compare with BenchmarkBind_Search1536, which reuses one rendered query.
+func BenchmarkPrepared_RenderEachTime(b *testing.B) {
+	instance := createBenchmarkInstance(b)
+	renderer := qdrant.New()
+	params := benchmarkParams()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		result, err := benchmarkSearch(instance).Render(renderer)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := vectql.Bind(result, params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Executor Benchmarks
+
+func BenchmarkExecutor_Prepare(b *testing.B) {
+	instance := createBenchmarkInstance(b)
+	query := benchmarkSearch(instance)
+	renderer := qdrant.New()
+	params := benchmarkParams()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := vectql.Prepare(query, renderer, params); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkExecutor_Execute(b *testing.B) {
+	instance := createBenchmarkInstance(b)
+	req, err := vectql.Prepare(benchmarkSearch(instance), qdrant.New(), benchmarkParams())
+	if err != nil {
+		b.Fatal(err)
+	}
+	response := &vectql.Response{}
+	executor := vectql.ExecutorFunc(func(_ context.Context, _ *vectql.Request) (*vectql.Response, error) {
+		return response, nil
+	})
+	ctx := context.Background()
+
+	b.ResetTimer()
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := executor.Execute(ctx, req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Result Decoding Benchmarks
+
+// benchmarkResponse builds a provider response with topK matches that include
+// 1536-dimension vectors and metadata.
+func benchmarkResponse(b *testing.B, topK int) []byte {
+	b.Helper()
+	type point struct {
+		ID      string                 `json:"id"`
+		Score   float64                `json:"score"`
+		Vector  []float32              `json:"vector"`
+		Payload map[string]interface{} `json:"payload"`
+	}
+	points := make([]point, topK)
+	for i := range points {
+		points[i] = point{
+			ID:      fmt.Sprintf("id_%d", i),
+			Score:   1 - float64(i)/float64(topK),
+			Vector:  benchmarkVector(),
+			Payload: map[string]interface{}{"category": "shoes", "price": 19.99},
+		}
+	}
+	body, err := json.Marshal(map[string]interface{}{"result": points})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return body
+}
+
+func BenchmarkDecode_Matches10x1536(b *testing.B) {
+	body := benchmarkResponse(b, 10)
+
+	b.ResetTimer()
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+
+	for i := 0; i < b.N; i++ {
+		var decoded struct {
+			Result []struct {
+				ID      string                 `json:"id"`
+				Score   float64                `json:"score"`
+				Vector  []float32              `json:"vector"`
+				Payload map[string]interface{} `json:"payload"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			b.Fatal(err)
+		}
+		matches := make([]vectql.Match, len(decoded.Result))
+		for j, p := range decoded.Result {
+			matches[j] = vectql.Match{ID: p.ID, Score: p.Score, Vector: p.Vector, Metadata: p.Payload}
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package vectql
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// FieldTransformer encrypts or tokenizes metadata values at bind time so
+// they never reach the provider in plaintext, and reverses the transformation
+// on decoded results.
+type FieldTransformer interface {
+	// Encode transforms a value before it is written into a query.
+	Encode(value interface{}) (interface{}, error)
+
+	// Decode reverses Encode on a value read from a result.
+	Decode(value interface{}) (interface{}, error)
+
+	// Deterministic reports whether Encode always returns the same output
+	// for the same value. Only deterministic transformers may encode filter
+	// values and FetchBy keys, which must match the stored encoding.
+	Deterministic() bool
+}
+
+// FieldTransforms maps collection metadata fields to transformers.
+type FieldTransforms struct {
+	fields map[string]map[string]FieldTransformer
+}
+
+// NewFieldTransforms creates an empty transform configuration.
+func NewFieldTransforms() *FieldTransforms {
+	return &FieldTransforms{fields: make(map[string]map[string]FieldTransformer)}
+}
+
+// Register transforms the named metadata field of a collection.
+func (t *FieldTransforms) Register(collection, field string, transformer FieldTransformer) *FieldTransforms {
+	if t.fields[collection] == nil {
+		t.fields[collection] = make(map[string]FieldTransformer)
+	}
+	t.fields[collection][field] = transformer
+	return t
+}
+
+func (t *FieldTransforms) lookup(field types.MetadataField) FieldTransformer {
+	return t.fields[field.Collection][field.Name]
+}
+
+// DecodeMatches reverses the transformation of registered fields in the
+// metadata of matches from collection. Metadata maps are replaced rather than
+// modified in place.
+func (t *FieldTransforms) DecodeMatches(collection string, matches []Match) error {
+	transformers := t.fields[collection]
+	if len(transformers) == 0 {
+		return nil
+	}
+	for i := range matches {
+		var decoded map[string]interface{}
+		for name, transformer := range transformers {
+			value, ok := matches[i].Metadata[name]
+			if !ok {
+				continue
+			}
+			plain, err := transformer.Decode(value)
+			if err != nil {
+				return fmt.Errorf("match %s: field %s: %w", matches[i].ID, name, err)
+			}
+			if decoded == nil {
+				decoded = make(map[string]interface{}, len(matches[i].Metadata))
+				for k, v := range matches[i].Metadata {
+					decoded[k] = v
+				}
+			}
+			decoded[name] = plain
+		}
+		if decoded != nil {
+			matches[i].Metadata = decoded
+		}
+	}
+	return nil
+}
+
+// WithFieldTransforms encodes parameters bound to registered metadata fields:
+// upserted and updated values, filter values, and FetchBy keys. Parameters
+// holding lists, such as IN filter values, are encoded element by element.
+// Binding fails if a filter value or FetchBy key is bound to a field with a
+// transformer that is not deterministic, or if a transformed field is
+// filtered with an operator other than equality or membership: range,
+// prefix, substring, and pattern comparisons cannot match encoded values.
+func WithFieldTransforms(t *FieldTransforms) BindOption {
+	return func(c *bindConfig) {
+		c.transforms = t
+	}
+}
+
+// matchOperators are the comparisons that still hold between the encodings
+// of a deterministic transformer.
+var matchOperators = map[types.FilterOperator]bool{
+	types.EQ:               true,
+	types.NE:               true,
+	types.IN:               true,
+	types.NotIn:            true,
+	types.ArrayContains:    true,
+	types.ArrayContainsAny: true,
+	types.ArrayContainsAll: true,
+}
+
+// transformFields encodes the values of parameters bound to registered fields.
+func transformFields(fields []types.FieldParam, values map[string]interface{}, t *FieldTransforms) error {
+	owner := make(map[string]types.MetadataField)
+	for _, fp := range fields {
+		transformer := t.lookup(fp.Field)
+		if transformer == nil {
+			continue
+		}
+		if fp.Match && !matchOperators[fp.Operator] {
+			return fmt.Errorf("parameter %s: field %s is transformed, so it cannot be filtered with %s", fp.Param, fp.Field.Name, fp.Operator)
+		}
+		if fp.Match && !transformer.Deterministic() {
+			return fmt.Errorf("parameter %s: field %s is matched against stored values, but its transformer is not deterministic", fp.Param, fp.Field.Name)
+		}
+		if prev, done := owner[fp.Param]; done {
+			if prev != fp.Field {
+				return fmt.Errorf("parameter %s is bound to transformed fields %s and %s", fp.Param, prev.Name, fp.Field.Name)
+			}
+			continue
+		}
+		owner[fp.Param] = fp.Field
+
+		value, ok := values[fp.Param]
+		if !ok {
+			continue
+		}
+		encoded, err := encodeField(transformer, value, fp.List)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", fp.Param, err)
+		}
+		values[fp.Param] = encoded
+	}
+	return nil
+}
+
+func encodeField(transformer FieldTransformer, value interface{}, list bool) (interface{}, error) {
+	if !list {
+		return transformer.Encode(value)
+	}
+	items, ok := toSlice(value)
+	if !ok {
+		return nil, fmt.Errorf("expected a list, got %T", value)
+	}
+	encoded := make([]interface{}, len(items))
+	for i, item := range items {
+		e, err := transformer.Encode(item)
+		if err != nil {
+			return nil, err
+		}
+		encoded[i] = e
+	}
+	return encoded, nil
+}
+
+// fieldParams lists the parameters of a query bound to metadata fields.
+func fieldParams(ast *types.VectorAST) []types.FieldParam {
+	var fields []types.FieldParam
+	add := func(field types.MetadataField, param string, list bool, op types.FilterOperator) {
+		if field.Collection == "" {
+			field.Collection = ast.Target.Name
+		}
+		fields = append(fields, types.FieldParam{Param: param, Field: field, List: list, Match: op != "", Operator: op})
+	}
+	for _, record := range ast.Vectors {
+		for field, p := range record.Metadata {
+			add(field, p.Name, false, "")
+		}
+	}
+	for field, p := range ast.Updates {
+		add(field, p.Name, false, "")
+	}
+	if ast.FetchKey != nil {
+		for _, p := range ast.FetchKey.Keys {
+			add(ast.FetchKey.Field, p.Name, false, types.EQ)
+		}
+	}
+	var walk func(types.FilterItem)
+	walk = func(f types.FilterItem) {
+		switch filter := f.(type) {
+		case types.FilterCondition:
+			if filter.Value.Name == "" {
+				return
+			}
+			switch filter.Operator {
+			case types.IN, types.NotIn, types.ArrayContainsAny, types.ArrayContainsAll:
+				add(filter.Field, filter.Value.Name, true, filter.Operator)
+			default:
+				add(filter.Field, filter.Value.Name, false, filter.Operator)
+			}
+		case types.RangeFilter:
+			if filter.Min != nil {
+				op := types.GE
+				if filter.MinExclusive {
+					op = types.GT
+				}
+				add(filter.Field, filter.Min.Name, false, op)
+			}
+			if filter.Max != nil {
+				op := types.LE
+				if filter.MaxExclusive {
+					op = types.LT
+				}
+				add(filter.Field, filter.Max.Name, false, op)
+			}
+		case types.FilterGroup:
+			for _, c := range filter.Conditions {
+				walk(c)
+			}
+		}
+	}
+	walk(ast.FilterClause)
+	for _, boost := range ast.Boosts {
+		walk(boost.Filter)
+	}
+	return fields
+}
+
+// applyBindTransforms normalizes the values of parameters with bind
+// transforms.
+func applyBindTransforms(transforms map[string][]types.BindTransform, values map[string]interface{}) error {
+	for name, chain := range transforms {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		v, err := transformValue(chain, value)
+		if err != nil {
+			return fmt.Errorf("parameter %s: %w", name, err)
+		}
+		values[name] = v
+	}
+	return nil
+}
+
+// transformValue applies chain to value. Lists, such as IN filter values,
+// are transformed element by element.
+func transformValue(chain []types.BindTransform, value interface{}) (interface{}, error) {
+	if _, isString := value.(string); !isString {
+		if items, isList := toSlice(value); isList {
+			out := make([]interface{}, len(items))
+			for i, item := range items {
+				v, err := applyTransforms(chain, item)
+				if err != nil {
+					return nil, err
+				}
+				out[i] = v
+			}
+			return out, nil
+		}
+	}
+	return applyTransforms(chain, value)
+}
+
+func applyTransforms(chain []types.BindTransform, value interface{}) (interface{}, error) {
+	for _, t := range chain {
+		var err error
+		if value, err = applyTransform(t, value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
+
+func applyTransform(t types.BindTransform, value interface{}) (interface{}, error) {
+	if t.Op == types.TransformTruncate {
+		return truncateTime(value, t.Unit)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s transform expects a string, got %T", t.Op, value)
+	}
+	switch t.Op {
+	case types.TransformLower:
+		return strings.ToLower(s), nil
+	case types.TransformTrim:
+		return strings.TrimSpace(s), nil
+	case types.TransformHash:
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:]), nil
+	default:
+		return nil, fmt.Errorf("unknown bind transform: %q", t.Op)
+	}
+}
+
+// truncateTime truncates a time.Time or RFC 3339 string to the start of
+// unit in its own location, returning a value of the same kind.
+func truncateTime(value interface{}, unit string) (interface{}, error) {
+	var tm time.Time
+	switch v := value.(type) {
+	case time.Time:
+		tm = v
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			return nil, fmt.Errorf("truncate transform expects an RFC 3339 time: %w", err)
+		}
+		tm = parsed
+	default:
+		return nil, fmt.Errorf("truncate transform expects a time, got %T", value)
+	}
+
+	year, month, day := tm.Date()
+	switch unit {
+	case "second":
+		tm = time.Date(year, month, day, tm.Hour(), tm.Minute(), tm.Second(), 0, tm.Location())
+	case "minute":
+		tm = time.Date(year, month, day, tm.Hour(), tm.Minute(), 0, 0, tm.Location())
+	case "hour":
+		tm = time.Date(year, month, day, tm.Hour(), 0, 0, 0, tm.Location())
+	case "day":
+		tm = time.Date(year, month, day, 0, 0, 0, 0, tm.Location())
+	case "month":
+		tm = time.Date(year, month, 1, 0, 0, 0, 0, tm.Location())
+	case "year":
+		tm = time.Date(year, time.January, 1, 0, 0, 0, 0, tm.Location())
+	default:
+		return nil, fmt.Errorf("invalid truncation unit: %q", unit)
+	}
+
+	if _, ok := value.(string); ok {
+		return tm.Format(time.RFC3339), nil
+	}
+	return tm, nil
+}
+
+// aesGCM encrypts string values with AES-GCM.
+type aesGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMTransformer returns a transformer that encrypts string values with
+// AES-GCM under key (16, 24, or 32 bytes) and encodes them as base64.
+// Encryption uses a random nonce, so encrypted fields cannot be filtered on;
+// use NewHMACTokenizer for fields that need equality filters.
+func NewAESGCMTransformer(key []byte) (FieldTransformer, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &aesGCM{aead: aead}, nil
+}
+
+func (t *aesGCM) Encode(value interface{}) (interface{}, error) {
+	plain, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("cannot encrypt %T, expected string", value)
+	}
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := t.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (t *aesGCM) Decode(value interface{}) (interface{}, error) {
+	encoded, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("cannot decrypt %T, expected string", value)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext encoding: %w", err)
+	}
+	size := t.aead.NonceSize()
+	if len(sealed) < size {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	plain, err := t.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	return string(plain), nil
+}
+
+// Deterministic returns false: every encryption uses a fresh nonce.
+func (t *aesGCM) Deterministic() bool {
+	return false
+}
+
+// hmacTokenizer replaces values with keyed hashes.
+type hmacTokenizer struct {
+	key []byte
+}
+
+// NewHMACTokenizer returns a transformer that replaces values with their
+// HMAC-SHA256 under key, hex encoded. Tokens are deterministic, so equality
+// and IN filters on tokenized fields work, but they cannot be reversed:
+// Decode returns tokens unchanged.
+func NewHMACTokenizer(key []byte) FieldTransformer {
+	return &hmacTokenizer{key: append([]byte(nil), key...)}
+}
+
+func (t *hmacTokenizer) Encode(value interface{}) (interface{}, error) {
+	mac := hmac.New(sha256.New, t.key)
+	fmt.Fprint(mac, value)
+	return fmt.Sprintf("%x", mac.Sum(nil)), nil
+}
+
+func (t *hmacTokenizer) Decode(value interface{}) (interface{}, error) {
+	return value, nil
+}
+
+// Deterministic returns true: a value always maps to the same token.
+func (t *hmacTokenizer) Deterministic() bool {
+	return true
+}
@@ -0,0 +1,328 @@
+package vectql
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestWithFieldTransforms_Upsert(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cipher, err := NewAESGCMTransformer(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transforms := NewFieldTransforms().Register("products", "location", cipher)
+
+	result, err := Upsert(v.C("products")).
+		AddVector(NewRecord(v.P("id"), Vec(v.P("vec"))).
+			WithMetadata(v.M("products", "location"), v.P("loc")).
+			WithMetadata(v.M("products", "category"), v.P("cat")).
+			Build()).
+		Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, err := Bind(result, map[string]interface{}{
+		"id": "p1", "vec": []float32{1}, "loc": "221B Baker Street", "cat": "books",
+	}, WithFieldTransforms(transforms))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var decoded struct {
+		Points []struct {
+			Payload map[string]interface{} `json:"payload"`
+		} `json:"points"`
+	}
+	if err := json.Unmarshal([]byte(body), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	payload := decoded.Points[0].Payload
+	if payload["category"] != "books" {
+		t.Errorf("expected untransformed category, got %v", payload["category"])
+	}
+	if payload["location"] == "221B Baker Street" {
+		t.Fatal("expected location to be encrypted")
+	}
+
+	matches := []Match{{ID: "p1", Metadata: payload}}
+	if err := transforms.DecodeMatches("products", matches); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if matches[0].Metadata["location"] != "221B Baker Street" {
+		t.Errorf("expected decrypted location, got %v", matches[0].Metadata["location"])
+	}
+	if payload["location"] == "221B Baker Street" {
+		t.Error("expected DecodeMatches not to modify the original metadata")
+	}
+}
+
+func TestWithFieldTransforms_FilterTokens(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tokenizer := NewHMACTokenizer([]byte("secret"))
+	transforms := NewFieldTransforms().Register("products", "category", tokenizer)
+
+	result, err := Search(v.C("products")).
+		Vector(Vec(v.P("vec"))).
+		TopK(5).
+		Filter(v.Or(
+			v.Eq(v.M("products", "category"), v.P("cat")),
+			v.In(v.M("products", "category"), v.P("cats")),
+		)).
+		Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, err := Bind(result, map[string]interface{}{
+		"vec": []float32{1}, "cat": "books", "cats": []string{"books", "games"},
+	}, WithFieldTransforms(transforms))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	token, _ := tokenizer.Encode("books")
+	other, _ := tokenizer.Encode("games")
+	for _, want := range []string{`"value":"` + token.(string) + `"`, `["` + token.(string) + `","` + other.(string) + `"]`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected body to contain %s, got %s", want, body)
+		}
+	}
+}
+
+func TestWithFieldTransforms_RangeBounds(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transforms := NewFieldTransforms().Register("products", "price", NewHMACTokenizer([]byte("secret")))
+
+	minPrice, maxPrice := v.P("min"), v.P("max")
+	result, err := Search(v.C("products")).
+		Vector(Vec(v.P("vec"))).
+		TopK(5).
+		Filter(v.Range(v.M("products", "price"), &minPrice, &maxPrice)).
+		Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, fp := range result.Fields {
+		if fp.Field.Name == "price" && !fp.Match {
+			t.Errorf("expected range bound %s to be matched against stored values", fp.Param)
+		}
+	}
+
+	body, err := Bind(result, map[string]interface{}{"vec": []float32{1}, "min": 10.0, "max": 20.0}, WithFieldTransforms(transforms))
+	if err == nil || !strings.Contains(err.Error(), "cannot be filtered with >=") {
+		t.Errorf("expected a range bound error, got %v (body %s)", err, body)
+	}
+}
+
+func TestWithFieldTransforms_MatchOperators(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transforms := NewFieldTransforms().Register("products", "category", NewHMACTokenizer([]byte("secret")))
+	category := v.M("products", "category")
+
+	tests := []struct {
+		name   string
+		filter FilterItem
+		valid  bool
+	}{
+		{"eq", v.Eq(category, v.P("cat")), true},
+		{"ne", v.Ne(category, v.P("cat")), true},
+		{"gt", v.Gt(category, v.P("cat")), false},
+		{"lt", v.Lt(category, v.P("cat")), false},
+		{"contains", v.Contains(category, v.P("cat")), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Search(v.C("products")).
+				Vector(Vec(v.P("vec"))).
+				TopK(5).
+				Filter(tt.filter).
+				Render(qdrant.New())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			_, err = Bind(result, map[string]interface{}{"vec": []float32{1}, "cat": "books"}, WithFieldTransforms(transforms))
+			if tt.valid && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.valid && (err == nil || !strings.Contains(err.Error(), "cannot be filtered with")) {
+				t.Errorf("expected an operator error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestWithFieldTransforms_NonDeterministicMatch(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cipher, err := NewAESGCMTransformer(make([]byte, 32))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cipher.Deterministic() || !NewHMACTokenizer([]byte("secret")).Deterministic() {
+		t.Fatal("expected AES-GCM to be randomized and HMAC tokens to be deterministic")
+	}
+	transforms := NewFieldTransforms().Register("products", "category", cipher)
+
+	result, err := Search(v.C("products")).
+		Vector(Vec(v.P("vec"))).
+		TopK(5).
+		Filter(v.Eq(v.M("products", "category"), v.P("cat"))).
+		Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = Bind(result, map[string]interface{}{"vec": []float32{1}, "cat": "books"}, WithFieldTransforms(transforms))
+	if err == nil || !strings.Contains(err.Error(), "not deterministic") {
+		t.Errorf("expected a non-deterministic filter error, got %v", err)
+	}
+
+	uv := uniqueKeyInstance(t)
+	result, err = Fetch(uv.C("products")).
+		FetchBy(uv.M("products", "category"), uv.P("key")).
+		Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, err = Bind(result, map[string]interface{}{"key": "books"}, WithFieldTransforms(transforms))
+	if err == nil || !strings.Contains(err.Error(), "not deterministic") {
+		t.Errorf("expected a non-deterministic key error, got %v", err)
+	}
+
+	result, err = Update(v.C("products")).
+		IDs(v.P("id")).
+		Set(v.M("products", "category"), v.P("cat")).
+		Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := Bind(result, map[string]interface{}{"id": "p1", "cat": "books"}, WithFieldTransforms(transforms)); err != nil {
+		t.Errorf("expected written values to allow randomized encryption, got %v", err)
+	}
+}
+
+func TestBind_Transforms(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tokenizer := NewHMACTokenizer([]byte("secret"))
+	transforms := NewFieldTransforms().Register("products", "category", tokenizer)
+
+	result, err := Search(v.C("products")).
+		Vector(Vec(v.P("vec"))).
+		TopK(5).
+		Filter(v.And(
+			v.Eq(v.M("products", "category"), v.P("cat")),
+			v.In(v.M("products", "location"), v.P("locs")),
+		)).
+		Transform(v.P("cat"), TrimSpace(), Lowercase()).
+		Transform(v.P("locs"), Hash()).
+		Render(qdrant.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, err := Bind(result, map[string]interface{}{
+		"vec": []float32{1}, "cat": " Books ", "locs": []string{"a"},
+	}, WithFieldTransforms(transforms))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Bind transforms normalize before field transforms encode
+	token, _ := tokenizer.Encode("books")
+	digest := "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"
+	for _, want := range []string{`"value":"` + token.(string) + `"`, `["` + digest + `"]`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected body to contain %s, got %s", want, body)
+		}
+	}
+
+	if _, err := Bind(result, map[string]interface{}{"vec": []float32{1}, "cat": 3, "locs": []string{}}); err == nil {
+		t.Error("expected error for a non-string value")
+	}
+}
+
+func TestTruncateTime(t *testing.T) {
+	tests := []struct {
+		unit     string
+		expected string
+	}{
+		{"second", "2024-03-15T10:42:07+02:00"},
+		{"hour", "2024-03-15T10:00:00+02:00"},
+		{"day", "2024-03-15T00:00:00+02:00"},
+		{"month", "2024-03-01T00:00:00+02:00"},
+		{"year", "2024-01-01T00:00:00+02:00"},
+	}
+	for _, tt := range tests {
+		got, err := truncateTime("2024-03-15T10:42:07.5+02:00", tt.unit)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.unit, err)
+		}
+		if got != tt.expected {
+			t.Errorf("%s: expected %s, got %v", tt.unit, tt.expected, got)
+		}
+	}
+
+	at := time.Date(2024, 3, 15, 10, 42, 7, 0, time.UTC)
+	got, err := truncateTime(at, "day")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.(time.Time).Equal(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected midnight, got %v", got)
+	}
+	if _, err := truncateTime("yesterday", "day"); err == nil {
+		t.Error("expected error for a non-RFC 3339 string")
+	}
+}
+
+func TestTransform_Validation(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	base := func() *Builder {
+		return Search(v.C("products")).
+			Vector(Vec(v.P("vec"))).
+			TopK(5).
+			Filter(v.Eq(v.M("products", "category"), v.P("cat")))
+	}
+
+	if _, err := base().Transform(v.P("other"), Lowercase()).Build(); err == nil {
+		t.Error("expected error for an unknown parameter")
+	}
+	if _, err := base().Transform(v.P("vec"), Lowercase()).Build(); err == nil {
+		t.Error("expected error for a vector parameter")
+	}
+	if _, err := base().Transform(v.P("cat"), TruncateTime("week")).Build(); err == nil {
+		t.Error("expected error for an invalid truncation unit")
+	}
+	if _, err := base().Transform(v.P("cat"), TruncateTime("day")).Build(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestNewAESGCMTransformer_InvalidKey(t *testing.T) {
+	if _, err := NewAESGCMTransformer([]byte("short")); err == nil {
+		t.Error("expected error for invalid key length")
+	}
+}
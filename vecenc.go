@@ -0,0 +1,128 @@
+package vectql
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// WithVectorEncoding writes dense vector parameters in a compact encoding
+// instead of JSON number arrays. Only use encodings the target renderer lists
+// in its capabilities; Prepare rejects the others. Encoding runs after
+// normalization.
+func WithVectorEncoding(e VectorEncoding) BindOption {
+	return func(c *bindConfig) {
+		c.encoding = e
+	}
+}
+
+// CompactVectorEncoding returns the most compact vector encoding caps
+// accepts, or VectorEncodingJSON when the provider accepts none.
+func CompactVectorEncoding(caps Capabilities) VectorEncoding {
+	for _, e := range types.VectorEncodings {
+		if caps.SupportsVectorEncoding(e) {
+			return e
+		}
+	}
+	return VectorEncodingJSON
+}
+
+// encodeVector writes a dense vector in encoding e.
+func encodeVector(v interface{}, e VectorEncoding) (interface{}, error) {
+	floats, err := float32s(v, "encode")
+	if err != nil {
+		return nil, err
+	}
+
+	switch e {
+	case VectorEncodingFloat32Base64:
+		buf := make([]byte, 4*len(floats))
+		for i, f := range floats {
+			binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+		}
+		return base64.StdEncoding.EncodeToString(buf), nil
+
+	case VectorEncodingFloat16Base64:
+		buf := make([]byte, 2*len(floats))
+		for i, f := range floats {
+			binary.LittleEndian.PutUint16(buf[2*i:], float32ToFloat16(f))
+		}
+		return base64.StdEncoding.EncodeToString(buf), nil
+
+	default:
+		return nil, fmt.Errorf("unknown vector encoding: %s", e)
+	}
+}
+
+// float32s converts a dense vector value to float32 components. verb names
+// the operation in errors.
+func float32s(v interface{}, verb string) ([]float32, error) {
+	switch vec := v.(type) {
+	case []float32:
+		return vec, nil
+	case []float64:
+		floats := make([]float32, len(vec))
+		for i, x := range vec {
+			floats[i] = float32(x)
+		}
+		return floats, nil
+	case []interface{}:
+		floats := make([]float32, len(vec))
+		for i, x := range vec {
+			f, ok := toFloat(x)
+			if !ok {
+				return nil, fmt.Errorf("cannot %s vector element of type %T", verb, x)
+			}
+			floats[i] = float32(f)
+		}
+		return floats, nil
+	default:
+		return nil, fmt.Errorf("cannot %s %T as a vector", verb, v)
+	}
+}
+
+// float32ToFloat16 converts f to IEEE 754 half precision, rounding to
+// nearest even. Values beyond the half range become infinities and values
+// below it flush through subnormals to zero.
+func float32ToFloat16(f float32) uint16 {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp := int32(bits>>23) & 0xff
+	mant := bits & 0x7fffff
+
+	switch {
+	case exp == 0xff:
+		// Inf or NaN; keep NaN quiet
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp-127 > 15:
+		return sign | 0x7c00
+	case exp-127 >= -14:
+		half := uint32(exp-127+15)<<10 | mant>>13
+		// Round to nearest even on the 13 dropped bits; a carry into the
+		// exponent is correct, including overflow to infinity
+		round := mant & 0x1fff
+		if round > 0x1000 || (round == 0x1000 && half&1 == 1) {
+			half++
+		}
+		return sign | uint16(half)
+	case exp-127 >= -25:
+		// Subnormal half: shift the mantissa with its implicit bit
+		mant |= 0x800000
+		shift := uint32(-(exp - 127) - 14 + 13)
+		half := mant >> shift
+		rem := mant & (1<<shift - 1)
+		mid := uint32(1) << (shift - 1)
+		if rem > mid || (rem == mid && half&1 == 1) {
+			half++
+		}
+		return sign | uint16(half)
+	default:
+		return sign
+	}
+}
@@ -0,0 +1,141 @@
+package vectql
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/milvus"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+)
+
+func TestFloat32ToFloat16(t *testing.T) {
+	tests := []struct {
+		in   float32
+		want uint16
+	}{
+		{0, 0x0000},
+		{float32(math.Copysign(0, -1)), 0x8000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{0.1, 0x2e66},
+		{65504, 0x7bff},
+		{65520, 0x7c00},
+		{1e6, 0x7c00},
+		{6.1035156e-05, 0x0400},
+		{5.9604645e-08, 0x0001},
+		{1e-9, 0x0000},
+		{float32(math.Inf(-1)), 0xfc00},
+		{float32(math.NaN()), 0x7e00},
+	}
+
+	for _, tt := range tests {
+		if got := float32ToFloat16(tt.in); got != tt.want {
+			t.Errorf("float32ToFloat16(%v) = %#04x, want %#04x", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBind_VectorEncoding(t *testing.T) {
+	result, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(3).
+		Render(pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	params := map[string]interface{}{"query_vec": []float32{1, -2, 0.5}}
+
+	tests := []struct {
+		encoding VectorEncoding
+		width    int
+		decode   func([]byte) float32
+	}{
+		{VectorEncodingFloat32Base64, 4, func(b []byte) float32 {
+			return math.Float32frombits(binary.LittleEndian.Uint32(b))
+		}},
+		{VectorEncodingFloat16Base64, 2, func(b []byte) float32 {
+			return map[uint16]float32{0x3c00: 1, 0xc000: -2, 0x3800: 0.5}[binary.LittleEndian.Uint16(b)]
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.encoding), func(t *testing.T) {
+			body, err := Bind(result, params, WithVectorEncoding(tt.encoding))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var decoded map[string]interface{}
+			if err := json.Unmarshal([]byte(body), &decoded); err != nil {
+				t.Fatalf("invalid JSON %s: %v", body, err)
+			}
+			encoded, ok := decoded["vector"].(string)
+			if !ok {
+				t.Fatalf("expected base64 vector string, got %s", body)
+			}
+			raw, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				t.Fatalf("invalid base64: %v", err)
+			}
+			if len(raw) != 3*tt.width {
+				t.Fatalf("expected %d bytes, got %d", 3*tt.width, len(raw))
+			}
+			for i, want := range []float32{1, -2, 0.5} {
+				if got := tt.decode(raw[i*tt.width:]); got != want {
+					t.Errorf("component %d: expected %v, got %v", i, want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestBind_VectorEncodingRejectsNonVectors(t *testing.T) {
+	result, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(3).
+		Render(pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = Bind(result, map[string]interface{}{"query_vec": "nope"}, WithVectorEncoding(VectorEncodingFloat16Base64))
+	if err == nil || !strings.Contains(err.Error(), "cannot encode string as a vector") {
+		t.Fatalf("expected encoding error, got %v", err)
+	}
+}
+
+func TestCompactVectorEncoding(t *testing.T) {
+	if e := CompactVectorEncoding(pinecone.New().Capabilities()); e != VectorEncodingJSON {
+		t.Errorf("expected pinecone to use JSON vectors, got %q", e)
+	}
+	if e := CompactVectorEncoding(milvus.New().Capabilities()); e != VectorEncodingJSON {
+		t.Errorf("expected milvus SDK mode to use JSON vectors, got %q", e)
+	}
+	if e := CompactVectorEncoding(milvus.NewRESTv2().Capabilities()); e != VectorEncodingFloat16Base64 {
+		t.Errorf("expected milvus RESTful v2 to use float16, got %q", e)
+	}
+}
+
+func TestPrepare_VectorEncodingCapability(t *testing.T) {
+	query := Upsert(types.Collection{Name: "products"}).
+		AddVector(NewRecord(types.Param{Name: "id"}, Vec(types.Param{Name: "vec"})).Build())
+	params := map[string]interface{}{"id": "a", "vec": []float32{1, 0.5}}
+
+	_, err := Prepare(query, pinecone.New(), params, WithVectorEncoding(VectorEncodingFloat16Base64))
+	if err == nil || !strings.Contains(err.Error(), "does not accept float16_base64 vectors") {
+		t.Fatalf("expected capability error, got %v", err)
+	}
+
+	req, err := Prepare(query, milvus.NewRESTv2(), params, WithVectorEncoding(VectorEncodingFloat16Base64))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(req.Body, `"embedding":"ADwAOA=="`) {
+		t.Errorf("expected base64 float16 vector in body, got %s", req.Body)
+	}
+}
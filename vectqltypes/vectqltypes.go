@@ -0,0 +1,254 @@
+// Package vectqltypes exposes VECTQL's query building blocks to code outside
+// this module, such as testing helpers, middleware, and external renderers.
+//
+// Values have unexported fields and can only be created through the
+// constructors below, which enforce the same invariants as a VECTQL
+// instance without requiring a VDML schema: parameter and field names must
+// be safe identifiers and filters must be well formed. Unwrap returns the
+// value accepted by the vectql builder:
+//
+//	coll := vectqltypes.C("products")
+//	query := vectql.Search(coll.Unwrap()).
+//	    Vector(vectql.Vec(vectqltypes.P("query_vec").Unwrap())).
+//	    TopK(10)
+package vectqltypes
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// Param is a validated parameter reference.
+type Param struct {
+	p types.Param
+}
+
+// TryP creates a parameter reference with error handling.
+func TryP(name string) (Param, error) {
+	if !types.IsValidIdentifier(name) {
+		return Param{}, fmt.Errorf("invalid parameter name: %s", name)
+	}
+	return Param{p: types.Param{Name: name}}, nil
+}
+
+// P creates a parameter reference, panicking on an invalid name.
+func P(name string) Param {
+	p, err := TryP(name)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Name returns the parameter name.
+func (p Param) Name() string { return p.p.Name }
+
+// Unwrap returns the parameter for use with the vectql builder.
+func (p Param) Unwrap() types.Param { return p.p }
+
+// Collection is a validated collection reference.
+type Collection struct {
+	c types.Collection
+}
+
+// TryC creates a collection reference with error handling. Collection names
+// may contain letters, digits, underscores, hyphens, and dots.
+func TryC(name string) (Collection, error) {
+	if !isValidCollectionName(name) {
+		return Collection{}, fmt.Errorf("invalid collection name: %s", name)
+	}
+	return Collection{c: types.Collection{Name: name}}, nil
+}
+
+// C creates a collection reference, panicking on an invalid name.
+func C(name string) Collection {
+	c, err := TryC(name)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// Name returns the collection name.
+func (c Collection) Name() string { return c.c.Name }
+
+// Unwrap returns the collection for use with the vectql builder.
+func (c Collection) Unwrap() types.Collection { return c.c }
+
+// EmbeddingField is a validated embedding field reference.
+type EmbeddingField struct {
+	e types.EmbeddingField
+}
+
+// TryE creates an embedding field reference with error handling.
+func TryE(collection Collection, name string) (EmbeddingField, error) {
+	if !types.IsValidIdentifier(name) {
+		return EmbeddingField{}, fmt.Errorf("invalid embedding name: %s", name)
+	}
+	return EmbeddingField{e: types.EmbeddingField{Name: name, Collection: collection.Name()}}, nil
+}
+
+// E creates an embedding field reference, panicking on an invalid name.
+func E(collection Collection, name string) EmbeddingField {
+	e, err := TryE(collection, name)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// Name returns the embedding field name.
+func (e EmbeddingField) Name() string { return e.e.Name }
+
+// Collection returns the name of the collection the field belongs to.
+func (e EmbeddingField) Collection() string { return e.e.Collection }
+
+// Unwrap returns the embedding field for use with the vectql builder.
+func (e EmbeddingField) Unwrap() types.EmbeddingField { return e.e }
+
+// MetadataField is a validated metadata field reference.
+type MetadataField struct {
+	m types.MetadataField
+}
+
+// TryM creates a metadata field reference with error handling.
+func TryM(collection Collection, name string) (MetadataField, error) {
+	if !types.IsValidIdentifier(name) {
+		return MetadataField{}, fmt.Errorf("invalid metadata field name: %s", name)
+	}
+	return MetadataField{m: types.MetadataField{Name: name, Collection: collection.Name()}}, nil
+}
+
+// M creates a metadata field reference, panicking on an invalid name.
+func M(collection Collection, name string) MetadataField {
+	m, err := TryM(collection, name)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Name returns the metadata field name.
+func (m MetadataField) Name() string { return m.m.Name }
+
+// Collection returns the name of the collection the field belongs to.
+func (m MetadataField) Collection() string { return m.m.Collection }
+
+// Unwrap returns the metadata field for use with the vectql builder.
+func (m MetadataField) Unwrap() types.MetadataField { return m.m }
+
+// Filter is a validated filter expression.
+type Filter struct {
+	f types.FilterItem
+}
+
+// TryF creates a filter condition with error handling. The operator must be a
+// known comparison operator; use Exists and NotExists for existence checks.
+func TryF(field MetadataField, op types.FilterOperator, value Param) (Filter, error) {
+	if !slices.Contains(types.FilterOperators, op) {
+		return Filter{}, fmt.Errorf("unknown filter operator: %s", op)
+	}
+	if op == types.Exists || op == types.NotExists {
+		return Filter{}, fmt.Errorf("%s takes no value; use Exists or NotExists", op)
+	}
+	return Filter{f: types.FilterCondition{Field: field.m, Operator: op, Value: value.p}}, nil
+}
+
+// F creates a filter condition, panicking on an invalid operator.
+func F(field MetadataField, op types.FilterOperator, value Param) Filter {
+	f, err := TryF(field, op, value)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// Exists creates an existence check filter.
+func Exists(field MetadataField) Filter {
+	return Filter{f: types.FilterCondition{Field: field.m, Operator: types.Exists}}
+}
+
+// NotExists creates a non-existence check filter.
+func NotExists(field MetadataField) Filter {
+	return Filter{f: types.FilterCondition{Field: field.m, Operator: types.NotExists}}
+}
+
+// TryRange creates a numeric range filter with error handling. At least one
+// bound is required.
+func TryRange(field MetadataField, minVal, maxVal *Param, exclusive bool) (Filter, error) {
+	if minVal == nil && maxVal == nil {
+		return Filter{}, fmt.Errorf("range on '%s' requires at least one bound", field.Name())
+	}
+	r := types.RangeFilter{Field: field.m, MinExclusive: exclusive, MaxExclusive: exclusive}
+	if minVal != nil {
+		r.Min = &minVal.p
+	}
+	if maxVal != nil {
+		r.Max = &maxVal.p
+	}
+	return Filter{f: r}, nil
+}
+
+// TryAnd creates an AND group with error handling.
+func TryAnd(filters ...Filter) (Filter, error) {
+	return group(types.AND, filters)
+}
+
+// TryOr creates an OR group with error handling.
+func TryOr(filters ...Filter) (Filter, error) {
+	return group(types.OR, filters)
+}
+
+// TryNot negates a filter with error handling.
+func TryNot(filter Filter) (Filter, error) {
+	return group(types.NOT, []Filter{filter})
+}
+
+func group(logic types.LogicOperator, filters []Filter) (Filter, error) {
+	if len(filters) == 0 {
+		return Filter{}, fmt.Errorf("%s group requires at least one condition", logic)
+	}
+	conditions := make([]types.FilterItem, len(filters))
+	for i, f := range filters {
+		if f.f == nil {
+			return Filter{}, fmt.Errorf("%s group contains an empty filter", logic)
+		}
+		conditions[i] = f.f
+	}
+	g := types.FilterGroup{Logic: logic, Conditions: conditions}
+	if depth := filterDepth(g); depth > types.MaxFilterDepth {
+		return Filter{}, fmt.Errorf("filter nesting too deep: %d > %d", depth, types.MaxFilterDepth)
+	}
+	return Filter{f: g}, nil
+}
+
+// Unwrap returns the filter for use with the vectql builder.
+func (f Filter) Unwrap() types.FilterItem { return f.f }
+
+func filterDepth(f types.FilterItem) int {
+	group, ok := f.(types.FilterGroup)
+	if !ok {
+		return 0
+	}
+	deepest := 0
+	for _, c := range group.Conditions {
+		deepest = max(deepest, filterDepth(c))
+	}
+	return deepest + 1
+}
+
+func isValidCollectionName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		valid := (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') ||
+			r == '_' || r == '-' || r == '.'
+		if !valid {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,107 @@
+package vectqltypes
+
+import (
+	"testing"
+
+	"github.com/zoobzio/vectql"
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+func TestConstructors(t *testing.T) {
+	coll := C("products-v2")
+	if coll.Name() != "products-v2" {
+		t.Errorf("expected products-v2, got %s", coll.Name())
+	}
+
+	field := M(coll, "category")
+	if field.Name() != "category" || field.Collection() != "products-v2" {
+		t.Errorf("unexpected field: %s.%s", field.Collection(), field.Name())
+	}
+
+	emb := E(coll, "embedding")
+	if emb.Unwrap() != (types.EmbeddingField{Name: "embedding", Collection: "products-v2"}) {
+		t.Errorf("unexpected embedding: %#v", emb.Unwrap())
+	}
+
+	if P("query_vec").Unwrap() != (types.Param{Name: "query_vec"}) {
+		t.Error("expected param to unwrap to its name")
+	}
+}
+
+func TestConstructorsRejectInvalidNames(t *testing.T) {
+	coll := C("products")
+
+	if _, err := TryP("id; drop"); err == nil {
+		t.Error("expected error for injected parameter name")
+	}
+	if _, err := TryC("products/../admin"); err == nil {
+		t.Error("expected error for collection name with slashes")
+	}
+	if _, err := TryC(""); err == nil {
+		t.Error("expected error for empty collection name")
+	}
+	if _, err := TryM(coll, "a or b"); err == nil {
+		t.Error("expected error for injected field name")
+	}
+	if _, err := TryE(coll, "2d"); err == nil {
+		t.Error("expected error for embedding name starting with a digit")
+	}
+}
+
+func TestFilters(t *testing.T) {
+	coll := C("products")
+	category := M(coll, "category")
+	price := M(coll, "price")
+
+	if _, err := TryF(category, "LIKE", P("cat")); err == nil {
+		t.Error("expected error for unknown operator")
+	}
+	if _, err := TryF(category, types.Exists, P("cat")); err == nil {
+		t.Error("expected error for EXISTS with a value")
+	}
+	if _, err := TryRange(price, nil, nil, false); err == nil {
+		t.Error("expected error for unbounded range")
+	}
+	if _, err := TryAnd(); err == nil {
+		t.Error("expected error for empty group")
+	}
+	if _, err := TryOr(Filter{}); err == nil {
+		t.Error("expected error for zero filter")
+	}
+
+	nested := F(category, types.EQ, P("cat"))
+	for i := 0; i < types.MaxFilterDepth; i++ {
+		var err error
+		if nested, err = TryNot(nested); err != nil {
+			t.Fatalf("unexpected error at depth %d: %v", i+1, err)
+		}
+	}
+	if _, err := TryNot(nested); err == nil {
+		t.Error("expected error for nesting beyond MaxFilterDepth")
+	}
+}
+
+func TestBuilderInterop(t *testing.T) {
+	coll := C("products")
+	minP := P("min")
+	priceRange, err := TryRange(M(coll, "price"), &minP, nil, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	filter, err := TryAnd(F(M(coll, "category"), types.EQ, P("cat")), priceRange)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ast, err := vectql.Search(coll.Unwrap()).
+		Vector(vectql.Vec(P("query_vec").Unwrap())).
+		TopK(10).
+		Filter(filter.Unwrap()).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ast.Validate(); err != nil {
+		t.Errorf("expected valid AST, got %v", err)
+	}
+}
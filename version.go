@@ -0,0 +1,79 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// ErrUnsupportedVersion is returned by CheckVersion when the provider runs a
+// version outside the renderer's supported range.
+var ErrUnsupportedVersion = errors.New("unsupported provider version")
+
+// VersionSource reports the version of the running provider, such as the
+// "version" field of Qdrant's root endpoint. Executors decode responses
+// into matches, so the transport that owns the connection supplies it.
+type VersionSource func(ctx context.Context) (string, error)
+
+// VersionCheckOption configures CheckVersion.
+type VersionCheckOption func(*versionCheck)
+
+type versionCheck struct {
+	logger *slog.Logger
+}
+
+// WarnOnVersionSkew logs an unsupported provider version as a warning to
+// logger instead of failing. A nil logger uses slog.Default.
+func WarnOnVersionSkew(logger *slog.Logger) VersionCheckOption {
+	return func(c *versionCheck) {
+		if logger == nil {
+			logger = slog.Default()
+		}
+		c.logger = logger
+	}
+}
+
+// CheckVersion compares the provider version reported by source with the
+// versions r supports, and fails with ErrUnsupportedVersion when it lies
+// outside them. Call it when an executor starts, before serving traffic.
+// Renderers that do not implement VersionRenderer, or that declare no
+// range, accept every version without querying source.
+func CheckVersion(ctx context.Context, r Renderer, source VersionSource, opts ...VersionCheckOption) error {
+	vr, ok := r.(types.VersionRenderer)
+	if !ok {
+		return nil
+	}
+	supported := vr.SupportedVersions()
+	if supported.IsZero() {
+		return nil
+	}
+	check := &versionCheck{}
+	for _, opt := range opts {
+		opt(check)
+	}
+
+	provider := UpgradeRenderer(r).Capabilities().Provider
+	version, err := source(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read %s version: %w", provider, err)
+	}
+	ok, err = supported.Contains(version)
+	if err != nil {
+		return fmt.Errorf("failed to check %s version: %w", provider, err)
+	}
+	if ok {
+		return nil
+	}
+	if check.logger != nil {
+		check.logger.LogAttrs(ctx, slog.LevelWarn, "unsupported provider version",
+			slog.String("provider", provider),
+			slog.String("version", version),
+			slog.String("supported", supported.String()),
+		)
+		return nil
+	}
+	return fmt.Errorf("%w: %s %s, renderer supports %s", ErrUnsupportedVersion, provider, version, supported)
+}
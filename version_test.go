@@ -0,0 +1,101 @@
+package vectql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/pkg/milvus"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func staticVersion(version string) VersionSource {
+	return func(context.Context) (string, error) {
+		return version, nil
+	}
+}
+
+func TestVersionRange_Contains(t *testing.T) {
+	tests := []struct {
+		r       VersionRange
+		version string
+		want    bool
+	}{
+		{VersionRange{Min: "1.10"}, "1.10.0", true},
+		{VersionRange{Min: "1.10"}, "v1.12.4", true},
+		{VersionRange{Min: "1.10"}, "1.9.7", false},
+		{VersionRange{Min: "1.10"}, "1.7", false},
+		{VersionRange{Min: "2.4", Max: "3.0"}, "2.4.0-rc1", true},
+		{VersionRange{Min: "2.4", Max: "3.0"}, "3.0.0", false},
+		{VersionRange{}, "0.1", true},
+	}
+	for _, tt := range tests {
+		got, err := tt.r.Contains(tt.version)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("%s contains %s: expected %v, got %v", tt.r, tt.version, tt.want, got)
+		}
+	}
+
+	for _, bad := range []string{"", "latest", "1.2.3.4", "1.x"} {
+		if _, err := (VersionRange{Min: "1.0"}).Contains(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	ctx := context.Background()
+
+	err := CheckVersion(ctx, qdrant.NewQuery(), staticVersion("1.7.4"))
+	if !errors.Is(err, ErrUnsupportedVersion) {
+		t.Fatalf("expected ErrUnsupportedVersion, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "qdrant 1.7.4, renderer supports >= 1.10") {
+		t.Errorf("unexpected message: %v", err)
+	}
+
+	if err := CheckVersion(ctx, qdrant.NewQuery(), staticVersion("1.11.0")); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := CheckVersion(ctx, milvus.NewRESTv2(), staticVersion("v2.3.9")); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("expected ErrUnsupportedVersion, got %v", err)
+	}
+
+	failing := func(context.Context) (string, error) {
+		return "", errors.New("connection refused")
+	}
+	if err := CheckVersion(ctx, qdrant.NewQuery(), failing); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected the source error, got %v", err)
+	}
+
+	// Renderers without a range never query the provider
+	if err := CheckVersion(ctx, qdrant.New(), failing); err != nil {
+		t.Errorf("expected no check for the legacy search mode, got %v", err)
+	}
+	if err := CheckVersion(ctx, pinecone.New(), failing); err != nil {
+		t.Errorf("expected no check for renderers without versions, got %v", err)
+	}
+}
+
+func TestCheckVersion_Warn(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+
+	err := CheckVersion(context.Background(), qdrant.NewQuery(), staticVersion("1.7.0"), WarnOnVersionSkew(logger))
+	if err != nil {
+		t.Fatalf("expected a warning only, got %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"level=WARN", "provider=qdrant", "version=1.7.0", `supported=">= 1.10"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in log output: %s", want, out)
+		}
+	}
+}
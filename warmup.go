@@ -0,0 +1,171 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// LoadRenderer is implemented by renderers for providers that serve a
+// collection only after loading it into memory, such as Milvus.
+type LoadRenderer interface {
+	// RenderLoad describes the call that loads collection.
+	RenderLoad(collection string) (Endpoint, string, error)
+}
+
+// PrepareLoad renders the call that loads a collection into a Request for
+// an Executor. It fails when the renderer does not load collections.
+func PrepareLoad(r Renderer, collection types.Collection) (*Request, error) {
+	lr, ok := r.(LoadRenderer)
+	if !ok {
+		return nil, fmt.Errorf("renderer %s does not load collections", UpgradeRenderer(r).Capabilities().Provider)
+	}
+	endpoint, body, err := lr.RenderLoad(collection.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &Request{
+		Provider:   UpgradeRenderer(r).Capabilities().Provider,
+		Collection: collection.Name,
+		Endpoint:   endpoint,
+		Body:       body,
+	}, nil
+}
+
+// WarmupOption configures a Warmup.
+type WarmupOption func(*Warmup)
+
+// WithWarmupRounds sends the warmup requests n times, so provider caches
+// see repeated traffic. The default is one round.
+func WithWarmupRounds(n int) WarmupOption {
+	return func(w *Warmup) {
+		if n > 0 {
+			w.rounds = n
+		}
+	}
+}
+
+// WithWarmupRetries retries a failed warmup request up to n times, waiting
+// backoff between attempts, to ride out providers that are still starting.
+func WithWarmupRetries(n int, backoff time.Duration) WarmupOption {
+	return func(w *Warmup) {
+		w.retries = n
+		w.backoff = backoff
+	}
+}
+
+// Warmup sends a set of representative prepared requests to a provider
+// after a deploy or scale-up, to populate provider caches and load
+// collections, and gates readiness until it completes. Prepare the requests
+// with Prepare, and put collection loads from PrepareLoad first. Warmup
+// responses are discarded.
+type Warmup struct {
+	executor Executor
+	requests []*Request
+	rounds   int
+	retries  int
+	backoff  time.Duration
+
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+// NewWarmup creates a warmup that sends requests through executor.
+func NewWarmup(executor Executor, requests []*Request, opts ...WarmupOption) *Warmup {
+	w := &Warmup{
+		executor: executor,
+		requests: requests,
+		rounds:   1,
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Run sends the warmup requests in order and marks the warmup complete. A
+// request that still fails after its retries is reported in the returned
+// error, and the remaining requests are sent regardless. Only the first
+// call runs; later calls wait for it and return its result.
+func (w *Warmup) Run(ctx context.Context) error {
+	w.once.Do(func() {
+		defer close(w.done)
+		var errs []error
+		for round := 0; round < w.rounds; round++ {
+			for _, req := range w.requests {
+				if err := w.send(ctx, req); err != nil {
+					if ctx.Err() != nil {
+						w.err = errors.Join(append(errs, ctx.Err())...)
+						return
+					}
+					errs = append(errs, fmt.Errorf("warmup %s %s: %w", req.Operation, req.Collection, err))
+				}
+			}
+		}
+		w.err = errors.Join(errs...)
+	})
+	<-w.done
+	return w.err
+}
+
+func (w *Warmup) send(ctx context.Context, req *Request) error {
+	var err error
+	for attempt := 0; attempt <= w.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(w.backoff):
+			}
+		}
+		if _, err = w.executor.Execute(ctx, req); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// Ready reports whether the warmup has completed, successfully or not.
+// Readiness probes answer not ready until it has.
+func (w *Warmup) Ready() bool {
+	select {
+	case <-w.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Done returns a channel that is closed when the warmup completes.
+func (w *Warmup) Done() <-chan struct{} {
+	return w.done
+}
+
+// Err returns the warmup failures once the warmup has completed, and nil
+// before.
+func (w *Warmup) Err() error {
+	if !w.Ready() {
+		return nil
+	}
+	return w.err
+}
+
+// Gate returns an executor that holds requests until the warmup completes
+// before sending them through next, or fails them when their context ends
+// first. The warmup itself must use an executor that is not gated.
+func (w *Warmup) Gate(next Executor) Executor {
+	return ExecutorFunc(func(ctx context.Context, req *Request) (*Response, error) {
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return next.Execute(ctx, req)
+	})
+}
@@ -0,0 +1,79 @@
+package vectql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/milvus"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestPrepareLoad(t *testing.T) {
+	r := milvus.New()
+	r.Mode = milvus.ModeRESTv2
+	req, err := PrepareLoad(r, types.Collection{Name: "docs"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Provider != "milvus" || req.Endpoint.Path != "/v2/vectordb/collections/load" || req.Body != `{"collectionName":"docs"}` {
+		t.Errorf("unexpected request: %+v", req)
+	}
+	if _, err := PrepareLoad(qdrant.New(), types.Collection{Name: "docs"}); err == nil {
+		t.Error("expected an error for a renderer that does not load collections")
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		sent []string
+		fail = 1
+	)
+	exec := ExecutorFunc(func(_ context.Context, req *Request) (*Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, req.Collection)
+		if req.Collection == "flaky" && fail > 0 {
+			fail--
+			return nil, errors.New("unavailable")
+		}
+		if req.Collection == "broken" {
+			return nil, errors.New("unavailable")
+		}
+		return &Response{}, nil
+	})
+	requests := []*Request{{Collection: "flaky"}, {Collection: "broken"}, {Collection: "docs"}}
+	w := NewWarmup(exec, requests, WithWarmupRounds(2), WithWarmupRetries(1, 0))
+
+	gated := w.Gate(ExecutorFunc(func(_ context.Context, _ *Request) (*Response, error) {
+		return &Response{}, nil
+	}))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	if _, err := gated.Execute(ctx, &Request{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected gated requests to wait for the warmup, got %v", err)
+	}
+	cancel()
+	if w.Ready() || w.Err() != nil {
+		t.Error("expected the warmup not to be ready before it runs")
+	}
+
+	err := w.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected an error for the failing request")
+	}
+	if !w.Ready() || w.Err() == nil {
+		t.Error("expected a completed warmup with its error")
+	}
+	// Round one: flaky fails and is retried, broken fails twice. Round two
+	// repeats without the flaky failure.
+	if len(sent) != 9 {
+		t.Errorf("expected 9 requests, got %d: %v", len(sent), sent)
+	}
+	if _, err := gated.Execute(context.Background(), &Request{}); err != nil {
+		t.Errorf("expected gated requests to pass after the warmup, got %v", err)
+	}
+}
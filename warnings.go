@@ -0,0 +1,229 @@
+package vectql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zoobzio/vectql/internal/types"
+)
+
+// WarningCode classifies a Warning.
+type WarningCode string
+
+// Warning codes.
+const (
+	// WarnImplicitDefault marks a default applied because the query left a
+	// setting unspecified.
+	WarnImplicitDefault WarningCode = "implicit_default"
+
+	// WarnIgnoredOption marks a query option that has no effect, either in
+	// combination with other options or for the target provider.
+	WarnIgnoredOption WarningCode = "ignored_option"
+
+	// WarnDeprecated marks a renderer mapping that will be removed or
+	// replaced in a future version.
+	WarnDeprecated WarningCode = "deprecated"
+
+	// WarnExperimental marks a renderer mapping whose output may change
+	// without notice.
+	WarnExperimental WarningCode = "experimental"
+
+	// WarnUnindexedFilter marks a filter on a schema field that declares no
+	// payload index, which most providers evaluate by scanning.
+	WarnUnindexedFilter WarningCode = "unindexed_filter"
+
+	// WarnPostFilter marks predicates the provider cannot express, which a
+	// post-filtered query leaves to ApplyPostFilter.
+	WarnPostFilter WarningCode = "post_filter"
+)
+
+// ErrExperimentalFeature is returned by Render on a strict builder when the
+// renderer used an experimental mapping.
+var ErrExperimentalFeature = errors.New("query uses an experimental renderer feature")
+
+// Warning is a non-fatal issue with a query. Warnings never stop a query
+// from building or rendering; they are meant for hooks and loggers.
+type Warning struct {
+	Code    WarningCode
+	Message string
+}
+
+// String returns "code: message".
+func (w Warning) String() string {
+	return string(w.Code) + ": " + w.Message
+}
+
+// BuildWithWarnings is Build that also reports non-fatal issues with the
+// query. Warnings are nil when Build fails.
+func (b *Builder) BuildWithWarnings() (*types.VectorAST, []Warning, error) {
+	ast, err := b.Build()
+	if err != nil {
+		return nil, nil, err
+	}
+	return ast, append(queryWarnings(ast), b.adviceWarnings(ast)...), nil
+}
+
+// RenderWithWarnings is Render that also reports non-fatal issues, including
+// parameters and options the renderer dropped because the provider does not
+// support them, deprecated or experimental renderer mappings, and the
+// collection statistics advice enabled by WithStats.
+func (b *Builder) RenderWithWarnings(r Renderer) (*types.QueryResult, []Warning, error) {
+	result, err := b.Render(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	warnings := append(queryWarnings(b.ast), b.adviceWarnings(b.ast)...)
+	provider := UpgradeRenderer(r).Capabilities().Provider
+	warnings = append(warnings, ignoredParamWarnings(result, provider)...)
+	warnings = append(warnings, ignoredOptionWarnings(result, provider)...)
+	warnings = append(warnings, postFilterWarnings(result, provider)...)
+	return result, append(warnings, featureWarnings(result)...), nil
+}
+
+// postFilterWarnings reports the residual predicates of a post-filtered
+// query, which are evaluated client-side rather than by the provider.
+func postFilterWarnings(result *types.QueryResult, provider string) []Warning {
+	if result.PostFilter == nil {
+		return nil
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, field := range filterFields(result.PostFilter) {
+		if !seen[field.Name] {
+			seen[field.Name] = true
+			names = append(names, "'"+field.Name+"'")
+		}
+	}
+	return []Warning{{
+		Code: WarnPostFilter,
+		Message: fmt.Sprintf("%s cannot filter on %s; ApplyPostFilter evaluates those predicates and keeps at most %d matches",
+			provider, strings.Join(names, ", "), result.PostFilterLimit),
+	}}
+}
+
+// Strict makes Render fail with ErrExperimentalFeature when the renderer
+// uses an experimental mapping, for services that only ship settled output.
+// Deprecated mappings are still reported as warnings only.
+func (b *Builder) Strict() *Builder {
+	b.strict = true
+	return b
+}
+
+// featureWarnings reports the deprecated and experimental mappings the
+// renderer used.
+func featureWarnings(result *types.QueryResult) []Warning {
+	var warnings []Warning
+	for _, f := range result.Features {
+		code := WarnDeprecated
+		if f.Stability == types.StabilityExperimental {
+			code = WarnExperimental
+		}
+		warnings = append(warnings, Warning{Code: code, Message: fmt.Sprintf("%s: %s", f.Feature, f.Message)})
+	}
+	return warnings
+}
+
+func queryWarnings(ast *types.VectorAST) []Warning {
+	var warnings []Warning
+	add := func(code WarningCode, format string, args ...interface{}) {
+		warnings = append(warnings, Warning{Code: code, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if ast.Operation == types.OpSearch && ast.QueryEmbedding == nil && ast.Modality() == types.ModalityVector {
+		if name := ast.Target.DefaultEmbedding; name != "" {
+			add(WarnImplicitDefault, "search uses the default embedding '%s' of collection '%s'", name, ast.Target.Name)
+		} else {
+			add(WarnImplicitDefault, "search names no embedding; the provider's default vector is used")
+		}
+	}
+	if q := ast.Quantization; q != nil && q.Rescore && q.Oversampling == 0 {
+		add(WarnImplicitDefault, "rescoring uses the provider's default oversampling")
+	}
+	if len(ast.MetadataFields) > 0 && !ast.IncludeMetadata {
+		add(WarnIgnoredOption, "selected metadata fields are ignored because metadata is not included")
+	}
+	seen := make(map[string]bool)
+	for _, field := range unindexedFields(ast.FilterClause) {
+		if !seen[field.Name] {
+			seen[field.Name] = true
+			add(WarnUnindexedFilter, "filter on field '%s' of collection '%s' has no payload index", field.Name, field.Collection)
+		}
+	}
+	return warnings
+}
+
+// unindexedFields returns the schema fields a filter references that
+// declare no index, in order. Fields of unknown type come from outside the
+// schema, and conditions hinted as indexed are trusted.
+func unindexedFields(f types.FilterItem) []types.MetadataField {
+	var field types.MetadataField
+	switch filter := f.(type) {
+	case types.FilterCondition:
+		if filter.Hint != nil && filter.Hint.Indexed {
+			return nil
+		}
+		field = filter.Field
+	case types.RangeFilter:
+		field = filter.Field
+	case types.GeoFilter:
+		field = filter.Field
+	case types.FilterGroup:
+		var fields []types.MetadataField
+		for _, c := range filter.Conditions {
+			fields = append(fields, unindexedFields(c)...)
+		}
+		return fields
+	default:
+		return nil
+	}
+	if field.Type == "" || field.Index != "" {
+		return nil
+	}
+	return []types.MetadataField{field}
+}
+
+// ignoredOptionWarnings reports the query options the renderer dropped.
+func ignoredOptionWarnings(result *types.QueryResult, provider string) []Warning {
+	if provider == "" {
+		provider = "the renderer"
+	}
+	var warnings []Warning
+	for _, option := range result.Ignored {
+		warnings = append(warnings, Warning{
+			Code:    WarnIgnoredOption,
+			Message: fmt.Sprintf("%s ignores the %s option", provider, option),
+		})
+	}
+	return warnings
+}
+
+// ignoredParamWarnings reports query parameters missing from the rendered
+// result. Parameters moved to a post-filter are still used.
+func ignoredParamWarnings(result *types.QueryResult, provider string) []Warning {
+	if provider == "" {
+		provider = "the renderer"
+	}
+	used := make(map[string]bool, len(result.RequiredParams))
+	for _, name := range result.RequiredParams {
+		used[name] = true
+	}
+	if result.PostFilter != nil {
+		residual := &types.VectorAST{FilterClause: result.PostFilter}
+		for _, spec := range residual.ParamSpecs() {
+			used[spec.Name] = true
+		}
+	}
+
+	var warnings []Warning
+	for _, spec := range result.ParamSpecs {
+		if used[spec.Name] {
+			continue
+		}
+		warnings = append(warnings, Warning{
+			Code:    WarnIgnoredOption,
+			Message: fmt.Sprintf("%s ignores the %s parameter '%s'", provider, spec.Source, spec.Name),
+		})
+	}
+	return warnings
+}
@@ -0,0 +1,209 @@
+package vectql
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zoobzio/vectql/internal/types"
+	"github.com/zoobzio/vectql/pkg/pinecone"
+	"github.com/zoobzio/vectql/pkg/qdrant"
+)
+
+func TestBuildWithWarnings(t *testing.T) {
+	_, warnings, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(10).
+		Rescore(0).
+		IncludeMetadata(false).
+		SelectMetadata(types.MetadataField{Name: "category", Collection: "products"}).
+		BuildWithWarnings()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Warning{
+		{Code: WarnImplicitDefault, Message: "search names no embedding; the provider's default vector is used"},
+		{Code: WarnImplicitDefault, Message: "rescoring uses the provider's default oversampling"},
+		{Code: WarnIgnoredOption, Message: "selected metadata fields are ignored because metadata is not included"},
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("expected %d warnings, got %v", len(want), warnings)
+	}
+	for i := range want {
+		if warnings[i] != want[i] {
+			t.Errorf("warning %d: expected %v, got %v", i, want[i], warnings[i])
+		}
+	}
+}
+
+func TestBuildWithWarnings_DefaultEmbedding(t *testing.T) {
+	_, warnings, err := Search(types.Collection{Name: "products", DefaultEmbedding: "description"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(10).
+		BuildWithWarnings()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Message, "default embedding 'description'") {
+		t.Errorf("expected default embedding warning, got %v", warnings)
+	}
+}
+
+func TestBuildWithWarnings_UnindexedFilter(t *testing.T) {
+	category := types.MetadataField{Name: "category", Collection: "products", Type: "string"}
+	price := types.MetadataField{Name: "price", Collection: "products", Type: "float", Index: types.IndexFloat}
+	tenant := types.MetadataField{Name: "tenant", Collection: "products", Type: "string"}
+	untyped := types.MetadataField{Name: "color", Collection: "products"}
+
+	_, warnings, err := Search(types.Collection{Name: "products", DefaultEmbedding: "description"}).
+		Vector(Vec(types.Param{Name: "query_vec"})).
+		TopK(10).
+		Filter(And(
+			Eq(category, types.Param{Name: "a"}),
+			Or(Eq(category, types.Param{Name: "b"}), Gt(price, types.Param{Name: "p"})),
+			Hint(Eq(tenant, types.Param{Name: "t"}), Indexed),
+			Eq(untyped, types.Param{Name: "c"}),
+		)).
+		BuildWithWarnings()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var unindexed []Warning
+	for _, w := range warnings {
+		if w.Code == WarnUnindexedFilter {
+			unindexed = append(unindexed, w)
+		}
+	}
+	if len(unindexed) != 1 || unindexed[0].Message != "filter on field 'category' of collection 'products' has no payload index" {
+		t.Errorf("expected one unindexed filter warning for category, got %v", unindexed)
+	}
+}
+
+func TestRenderWithWarnings_IgnoredParams(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	query := func() *Builder {
+		return Search(v.C("products")).
+			Vector(Vec(v.P("query_vec"))).
+			Embedding(v.E("products", "description")).
+			MinScore(v.P("min_score")).
+			TopK(10)
+	}
+
+	_, warnings, err := query().RenderWithWarnings(pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 1 || warnings[0].String() != "ignored_option: pinecone ignores the min_score parameter 'min_score'" {
+		t.Errorf("expected ignored min_score warning, got %v", warnings)
+	}
+
+	_, warnings, err = query().RenderWithWarnings(qdrant.NewQuery())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings for qdrant, got %v", warnings)
+	}
+}
+
+func TestRenderWithWarnings_PostFilterParamsUsed(t *testing.T) {
+	category := types.MetadataField{Name: "category"}
+	_, warnings, err := Search(types.Collection{Name: "products"}).
+		Vector(Vec(types.Param{Name: "v"})).
+		Embedding(types.EmbeddingField{Name: "e"}).
+		TopK(10).
+		Filter(Contains(category, types.Param{Name: "needle"})).
+		PostFilter(0).
+		RenderWithWarnings(newStubRenderer())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, w := range warnings {
+		if strings.Contains(w.Message, "needle") {
+			t.Errorf("expected post-filter param not to be reported, got %v", w)
+		}
+	}
+}
+
+func TestRenderWithWarnings_PostFilter(t *testing.T) {
+	v, err := NewFromVDML(testSchema())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, warnings, err := Search(v.C("products")).
+		Vector(Vec(v.P("v"))).
+		Embedding(v.E("products", "description")).
+		TopK(10).
+		Filter(v.And(
+			v.Eq(v.M("products", "category"), v.P("category")),
+			v.Matches(v.M("products", "location"), v.P("pattern")),
+		)).
+		PostFilter(4).
+		RenderWithWarnings(pinecone.New())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.PostFilter == nil {
+		t.Fatal("expected a residual predicate")
+	}
+	want := "post_filter: pinecone cannot filter on 'location'; ApplyPostFilter evaluates those predicates and keeps at most 10 matches"
+	var got []string
+	for _, w := range warnings {
+		if w.Code == WarnPostFilter {
+			got = append(got, w.String())
+		}
+	}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("expected a post-filter warning, got %v", warnings)
+	}
+}
+
+// featureRenderer reports a fixed set of feature notices.
+type featureRenderer struct {
+	*stubRenderer
+	features []types.FeatureNotice
+}
+
+func (r featureRenderer) Render(ast *types.VectorAST) (*types.QueryResult, error) {
+	return &types.QueryResult{JSON: `{"vector":":v"}`, RequiredParams: []string{"v"}, Features: r.features}, nil
+}
+
+func TestRenderWithWarnings_Features(t *testing.T) {
+	r := featureRenderer{stubRenderer: newStubRenderer(), features: []types.FeatureNotice{
+		{Feature: "stub.old_body", Stability: StabilityDeprecated, Message: "use the new body"},
+		{Feature: "stub.preview", Stability: StabilityExperimental, Message: "may change"},
+	}}
+	query := func() *Builder {
+		return Search(types.Collection{Name: "products"}).
+			Vector(Vec(types.Param{Name: "v"})).
+			Embedding(types.EmbeddingField{Name: "e"}).
+			TopK(10)
+	}
+
+	_, warnings, err := query().RenderWithWarnings(r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []Warning{
+		{Code: WarnDeprecated, Message: "stub.old_body: use the new body"},
+		{Code: WarnExperimental, Message: "stub.preview: may change"},
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("expected %v, got %v", want, warnings)
+	}
+
+	if _, err := query().Strict().Render(r); !errors.Is(err, ErrExperimentalFeature) {
+		t.Errorf("expected ErrExperimentalFeature in strict mode, got %v", err)
+	}
+
+	r.features = r.features[:1]
+	if _, err := query().Strict().Render(r); err != nil {
+		t.Errorf("expected deprecated features to pass strict mode, got %v", err)
+	}
+}